@@ -0,0 +1,64 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+)
+
+func CreateBackup(operatorConfig OperatorConfig, path string) (schema.BackupResponse, error) {
+	params := map[string]string{}
+	if path != "" {
+		params["path"] = path
+	}
+
+	httpRes, err := HTTPPostNoBody(operatorConfig, "/backup", params)
+	if err != nil {
+		return schema.BackupResponse{}, err
+	}
+
+	var backupRes schema.BackupResponse
+	err = json.Unmarshal(httpRes, &backupRes)
+	if err != nil {
+		return schema.BackupResponse{}, errors.Wrap(err, "/backup", string(httpRes))
+	}
+
+	return backupRes, nil
+}
+
+func RestoreBackup(operatorConfig OperatorConfig, path string, force bool) (schema.RestoreResponse, error) {
+	params := map[string]string{
+		"path":  path,
+		"force": s.Bool(force),
+	}
+
+	httpRes, err := HTTPPostNoBody(operatorConfig, "/restore", params)
+	if err != nil {
+		return schema.RestoreResponse{}, err
+	}
+
+	var restoreRes schema.RestoreResponse
+	err = json.Unmarshal(httpRes, &restoreRes)
+	if err != nil {
+		return schema.RestoreResponse{}, errors.Wrap(err, "/restore", string(httpRes))
+	}
+
+	return restoreRes, nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+)
+
+func SubmitBatchJob(operatorConfig OperatorConfig, apiName string, submission schema.BatchJobSubmission) (schema.BatchJobResponse, error) {
+	httpRes, err := HTTPPostObjAsJSON(operatorConfig, "/jobs/"+apiName, submission)
+	if err != nil {
+		return schema.BatchJobResponse{}, err
+	}
+
+	var batchJobRes schema.BatchJobResponse
+	err = json.Unmarshal(httpRes, &batchJobRes)
+	if err != nil {
+		return schema.BatchJobResponse{}, errors.Wrap(err, "/jobs/"+apiName, string(httpRes))
+	}
+
+	return batchJobRes, nil
+}
+
+// ListBatchJobs lists the batch jobs of all APIs if apiName is ""
+func ListBatchJobs(operatorConfig OperatorConfig, apiName string) (schema.BatchJobsResponse, error) {
+	endpoint := "/jobs"
+	if apiName != "" {
+		endpoint += "/" + apiName
+	}
+	httpRes, err := HTTPGet(operatorConfig, endpoint)
+	if err != nil {
+		return schema.BatchJobsResponse{}, err
+	}
+
+	var batchJobsRes schema.BatchJobsResponse
+	err = json.Unmarshal(httpRes, &batchJobsRes)
+	if err != nil {
+		return schema.BatchJobsResponse{}, errors.Wrap(err, endpoint, string(httpRes))
+	}
+
+	return batchJobsRes, nil
+}
+
+func GetBatchJob(operatorConfig OperatorConfig, apiName string, jobID string) (schema.BatchJobResponse, error) {
+	endpoint := "/jobs/" + apiName + "/" + jobID
+	httpRes, err := HTTPGet(operatorConfig, endpoint)
+	if err != nil {
+		return schema.BatchJobResponse{}, err
+	}
+
+	var batchJobRes schema.BatchJobResponse
+	err = json.Unmarshal(httpRes, &batchJobRes)
+	if err != nil {
+		return schema.BatchJobResponse{}, errors.Wrap(err, endpoint, string(httpRes))
+	}
+
+	return batchJobRes, nil
+}
+
+func CancelBatchJob(operatorConfig OperatorConfig, apiName string, jobID string) (schema.BatchJobResponse, error) {
+	endpoint := "/jobs/" + apiName + "/" + jobID
+	httpRes, err := HTTPDelete(operatorConfig, endpoint)
+	if err != nil {
+		return schema.BatchJobResponse{}, err
+	}
+
+	var batchJobRes schema.BatchJobResponse
+	err = json.Unmarshal(httpRes, &batchJobRes)
+	if err != nil {
+		return schema.BatchJobResponse{}, errors.Wrap(err, endpoint, string(httpRes))
+	}
+
+	return batchJobRes, nil
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+)
+
+func GetCosts(operatorConfig OperatorConfig) (*schema.CostsResponse, error) {
+	httpResponse, err := HTTPGet(operatorConfig, "/costs")
+	if err != nil {
+		return nil, err
+	}
+
+	var costsResponse schema.CostsResponse
+	err = json.Unmarshal(httpResponse, &costsResponse)
+	if err != nil {
+		return nil, errors.Wrap(err, "/costs", string(httpResponse))
+	}
+
+	return &costsResponse, nil
+}
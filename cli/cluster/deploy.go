@@ -23,11 +23,17 @@ import (
 	"github.com/cortexlabs/cortex/pkg/operator/schema"
 )
 
-func Deploy(operatorConfig OperatorConfig, configPath string, deploymentBytesMap map[string][]byte, force bool) (schema.DeployResponse, error) {
+func Deploy(operatorConfig OperatorConfig, configPath string, deploymentBytesMap map[string][]byte, force bool, atomic bool) (schema.DeployResponse, error) {
 	params := map[string]string{
 		"force":      s.Bool(force),
+		"atomic":     s.Bool(atomic),
 		"configPath": configPath,
 	}
+
+	deploymentBytesMap, err := incrementalDeploymentBytes(operatorConfig, deploymentBytesMap)
+	if err != nil {
+		return schema.DeployResponse{}, err
+	}
 	uploadInput := &HTTPUploadInput{
 		Bytes: deploymentBytesMap,
 	}
@@ -0,0 +1,49 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+)
+
+func Diff(operatorConfig OperatorConfig, configPath string, deploymentBytesMap map[string][]byte) (schema.DiffResponse, error) {
+	params := map[string]string{
+		"configPath": configPath,
+	}
+
+	deploymentBytesMap, err := incrementalDeploymentBytes(operatorConfig, deploymentBytesMap)
+	if err != nil {
+		return schema.DiffResponse{}, err
+	}
+	uploadInput := &HTTPUploadInput{
+		Bytes: deploymentBytesMap,
+	}
+
+	response, err := HTTPUpload(operatorConfig, "/diff", uploadInput, params)
+	if err != nil {
+		return schema.DiffResponse{}, err
+	}
+
+	var diffResponse schema.DiffResponse
+	if err := json.Unmarshal(response, &diffResponse); err != nil {
+		return schema.DiffResponse{}, errors.Wrap(err, "/diff", string(response))
+	}
+
+	return diffResponse, nil
+}
@@ -22,8 +22,9 @@ import (
 	"github.com/cortexlabs/cortex/pkg/operator/schema"
 )
 
-func GetAPIs(operatorConfig OperatorConfig) (schema.GetAPIsResponse, error) {
-	httpRes, err := HTTPGet(operatorConfig, "/get")
+// selector is a kubernetes label selector (all APIs are returned if it's empty)
+func GetAPIs(operatorConfig OperatorConfig, selector string) (schema.GetAPIsResponse, error) {
+	httpRes, err := HTTPGet(operatorConfig, "/get", map[string]string{"selector": selector})
 	if err != nil {
 		return schema.GetAPIsResponse{}, err
 	}
@@ -0,0 +1,54 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+)
+
+func StartLoadTest(operatorConfig OperatorConfig, apiName string, loadTestConfig schema.LoadTestConfig) (schema.LoadTestResponse, error) {
+	httpRes, err := HTTPPostObjAsJSON(operatorConfig, "/load-test/"+apiName, loadTestConfig)
+	if err != nil {
+		return schema.LoadTestResponse{}, err
+	}
+
+	var loadTestRes schema.LoadTestResponse
+	err = json.Unmarshal(httpRes, &loadTestRes)
+	if err != nil {
+		return schema.LoadTestResponse{}, errors.Wrap(err, "/load-test/"+apiName, string(httpRes))
+	}
+
+	return loadTestRes, nil
+}
+
+func GetLoadTest(operatorConfig OperatorConfig, apiName string, loadTestID string) (schema.LoadTestResponse, error) {
+	endpoint := "/load-test/" + apiName + "/" + loadTestID
+	httpRes, err := HTTPGet(operatorConfig, endpoint)
+	if err != nil {
+		return schema.LoadTestResponse{}, err
+	}
+
+	var loadTestRes schema.LoadTestResponse
+	err = json.Unmarshal(httpRes, &loadTestRes)
+	if err != nil {
+		return schema.LoadTestResponse{}, errors.Wrap(err, endpoint, string(httpRes))
+	}
+
+	return loadTestRes, nil
+}
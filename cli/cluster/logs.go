@@ -34,10 +34,40 @@ import (
 )
 
 func StreamLogs(operatorConfig OperatorConfig, apiName string) error {
+	return streamFromOperator(operatorConfig, "/logs/"+apiName, nil)
+}
+
+type TailOptions struct {
+	Containers []string
+	Filter     string
+	Since      string
+	Until      string
+}
+
+func TailLogs(operatorConfig OperatorConfig, apiName string, opts TailOptions) error {
+	qParams := map[string]string{}
+	if len(opts.Containers) > 0 {
+		qParams["containers"] = strings.Join(opts.Containers, ",")
+	}
+	if opts.Filter != "" {
+		qParams["filter"] = opts.Filter
+	}
+	if opts.Since != "" {
+		qParams["since"] = opts.Since
+	}
+	if opts.Until != "" {
+		qParams["until"] = opts.Until
+	}
+
+	return streamFromOperator(operatorConfig, "/logs/"+apiName+"/tail", qParams)
+}
+
+// streamFromOperator prints each message that the operator writes to the websocket at path, until either side closes it
+func streamFromOperator(operatorConfig OperatorConfig, path string, qParams map[string]string) error {
 	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, os.Interrupt)
 
-	req, err := operatorRequest(operatorConfig, "GET", "/logs/"+apiName, nil, nil)
+	req, err := operatorRequest(operatorConfig, "GET", path, nil, []map[string]string{qParams})
 	if err != nil {
 		return err
 	}
@@ -94,6 +124,9 @@ func handleConnection(connection *websocket.Conn, done chan struct{}) {
 		defer close(done)
 		for {
 			_, message, err := connection.ReadMessage()
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				return
+			}
 			if err != nil {
 				exit.Error(ErrorOperatorSocketRead(err))
 			}
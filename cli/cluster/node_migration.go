@@ -0,0 +1,57 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+)
+
+func StartNodeMigration(operatorConfig OperatorConfig, generation string) (schema.NodeMigrationResponse, error) {
+	params := map[string]string{
+		"generation": generation,
+	}
+
+	httpRes, err := HTTPPostNoBody(operatorConfig, "/node-migration", params)
+	if err != nil {
+		return schema.NodeMigrationResponse{}, err
+	}
+
+	var migrationRes schema.NodeMigrationResponse
+	err = json.Unmarshal(httpRes, &migrationRes)
+	if err != nil {
+		return schema.NodeMigrationResponse{}, errors.Wrap(err, "/node-migration", string(httpRes))
+	}
+
+	return migrationRes, nil
+}
+
+func GetNodeMigration(operatorConfig OperatorConfig) (schema.NodeMigrationResponse, error) {
+	httpRes, err := HTTPGet(operatorConfig, "/node-migration")
+	if err != nil {
+		return schema.NodeMigrationResponse{}, err
+	}
+
+	var migrationRes schema.NodeMigrationResponse
+	err = json.Unmarshal(httpRes, &migrationRes)
+	if err != nil {
+		return schema.NodeMigrationResponse{}, errors.Wrap(err, "/node-migration", string(httpRes))
+	}
+
+	return migrationRes, nil
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+)
+
+func Pause(operatorConfig OperatorConfig, apiName string) (schema.PauseResponse, error) {
+	return postPause(operatorConfig, "/pause/"+apiName)
+}
+
+func Resume(operatorConfig OperatorConfig, apiName string) (schema.PauseResponse, error) {
+	return postPause(operatorConfig, "/resume/"+apiName)
+}
+
+func postPause(operatorConfig OperatorConfig, path string) (schema.PauseResponse, error) {
+	httpRes, err := HTTPPostNoBody(operatorConfig, path)
+	if err != nil {
+		return schema.PauseResponse{}, err
+	}
+
+	var pauseRes schema.PauseResponse
+	err = json.Unmarshal(httpRes, &pauseRes)
+	if err != nil {
+		return schema.PauseResponse{}, errors.Wrap(err, path, string(httpRes))
+	}
+
+	return pauseRes, nil
+}
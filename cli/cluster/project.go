@@ -0,0 +1,89 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/hash"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/zip"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+)
+
+// incrementalDeploymentBytes replaces the zipped project in deploymentBytesMap with a manifest of the project's files
+// and a zip of the files which the cluster doesn't already have, so that unchanged files aren't uploaded on each deploy
+func incrementalDeploymentBytes(operatorConfig OperatorConfig, deploymentBytesMap map[string][]byte) (map[string][]byte, error) {
+	projectBytes, ok := deploymentBytesMap["project.zip"]
+	if !ok {
+		return deploymentBytesMap, nil
+	}
+
+	projectFiles, err := zip.UnzipMemToList(projectBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := make([]schema.ProjectFile, len(projectFiles))
+	contents := map[string][]byte{}
+	for i, projectFile := range projectFiles {
+		fileHash := hash.Bytes(projectFile.Content)
+		manifest[i] = schema.ProjectFile{
+			Path: projectFile.Dest,
+			Hash: fileHash,
+		}
+		contents[fileHash] = projectFile.Content
+	}
+
+	response, err := HTTPPostObjAsJSON(operatorConfig, "/projects/missing", manifest)
+	if err != nil {
+		return nil, err
+	}
+	var missingResponse schema.MissingProjectFilesResponse
+	if err := json.Unmarshal(response, &missingResponse); err != nil {
+		return nil, errors.Wrap(err, "/projects/missing", string(response))
+	}
+
+	manifestBytes, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	incrementalBytesMap := map[string][]byte{
+		"project_manifest.json": manifestBytes,
+	}
+	for fileName, fileBytes := range deploymentBytesMap {
+		if fileName != "project.zip" {
+			incrementalBytesMap[fileName] = fileBytes
+		}
+	}
+
+	var missingFiles []zip.BytesInput
+	for _, fileHash := range missingResponse.Hashes {
+		if content, ok := contents[fileHash]; ok {
+			missingFiles = append(missingFiles, zip.BytesInput{Content: content, Dest: fileHash})
+		}
+	}
+	if len(missingFiles) > 0 {
+		missingFilesBytes, err := zip.ToMem(&zip.Input{Bytes: missingFiles})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to zip project files")
+		}
+		incrementalBytesMap["project_files.zip"] = missingFilesBytes
+	}
+
+	return incrementalBytesMap, nil
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/cortexlabs/cortex/cli/cluster"
+	"github.com/cortexlabs/cortex/pkg/lib/console"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/print"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	_flagBackupEnv    string
+	_flagRestoreEnv   string
+	_flagRestoreForce bool
+)
+
+func backupInit() {
+	_backupCmd.Flags().SortFlags = false
+	_backupCmd.Flags().StringVarP(&_flagBackupEnv, "env", "e", getDefaultEnv(_generalCommandType), "environment to use")
+}
+
+func restoreInit() {
+	_restoreCmd.Flags().SortFlags = false
+	_restoreCmd.Flags().StringVarP(&_flagRestoreEnv, "env", "e", getDefaultEnv(_generalCommandType), "environment to use")
+	_restoreCmd.Flags().BoolVarP(&_flagRestoreForce, "force", "f", false, "override in-progress api updates")
+}
+
+var _backupCmd = &cobra.Command{
+	Use:   "backup [S3_PATH]",
+	Short: "back up the apis which are deployed in a cluster to s3",
+	Args:  cobra.RangeArgs(0, 1),
+	Run: func(cmd *cobra.Command, args []string) {
+		env, err := ReadOrConfigureEnv(_flagBackupEnv)
+		if err != nil {
+			telemetry.Event("cli.backup")
+			exit.Error(err)
+		}
+		telemetry.Event("cli.backup", map[string]interface{}{"provider": env.Provider.String(), "env_name": env.Name})
+
+		err = printEnvIfNotSpecified(_flagBackupEnv)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		if env.Provider != types.AWSProviderType {
+			exit.Error(ErrorNotSupportedInLocalEnvironment())
+		}
+
+		var path string
+		if len(args) == 1 {
+			path = args[0]
+		}
+
+		backupResponse, err := cluster.CreateBackup(MustGetOperatorConfig(env.Name), path)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		backup := backupResponse.Backup
+		fmt.Println(console.Bold(fmt.Sprintf("backed up %d %s to %s", len(backup.APIs), s.PluralS("api", len(backup.APIs)), backup.Path)))
+		fmt.Printf("\nto restore the backup, run `cortex restore %s`\n", backup.Path)
+	},
+}
+
+var _restoreCmd = &cobra.Command{
+	Use:   "restore S3_PATH",
+	Short: "deploy the apis in a backup (created by `cortex backup`) to a cluster",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		env, err := ReadOrConfigureEnv(_flagRestoreEnv)
+		if err != nil {
+			telemetry.Event("cli.restore")
+			exit.Error(err)
+		}
+		telemetry.Event("cli.restore", map[string]interface{}{"provider": env.Provider.String(), "env_name": env.Name})
+
+		err = printEnvIfNotSpecified(_flagRestoreEnv)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		if env.Provider != types.AWSProviderType {
+			exit.Error(ErrorNotSupportedInLocalEnvironment())
+		}
+
+		restoreResponse, err := cluster.RestoreBackup(MustGetOperatorConfig(env.Name), args[0], _flagRestoreForce)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		if len(restoreResponse.Results) == 0 {
+			print.BoldFirstLine("the backup does not contain any apis")
+			return
+		}
+		print.BoldFirstBlock(deployMessage(restoreResponse.Results, env.Name))
+	},
+}
@@ -0,0 +1,318 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cortexlabs/cortex/cli/cluster"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/table"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	libtime "github.com/cortexlabs/cortex/pkg/lib/time"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	_flagJobEnv              string
+	_flagJobWorkers          int32
+	_flagJobPriority         string
+	_flagJobMaxRetries       int32
+	_flagJobPartitionTimeout time.Duration
+	_flagJobDeadLetterPrefix string
+	_flagJobCallbackURL      string
+	_flagJobCallbackSNSTopic string
+	_flagJobCallbackKeyEnv   string
+	_flagJobCallbackRetries  int32
+)
+
+func jobInit() {
+	_jobSubmitCmd.Flags().SortFlags = false
+	_jobSubmitCmd.Flags().StringVarP(&_flagJobEnv, "env", "e", getDefaultEnv(_generalCommandType), "environment to use")
+	_jobSubmitCmd.Flags().Int32VarP(&_flagJobWorkers, "workers", "w", 1, "number of workers which the items are split between")
+	_jobSubmitCmd.Flags().StringVarP(&_flagJobPriority, "priority", "p", "", fmt.Sprintf("priority of the job in the queue (%s; defaults to the api's %s)", s.StrsOr(userconfig.PriorityTypeStrings()), userconfig.PriorityKey))
+	_jobSubmitCmd.Flags().Int32Var(&_flagJobMaxRetries, "max-retries", 0, "number of times a worker's partition of the items is retried if the worker fails")
+	_jobSubmitCmd.Flags().DurationVar(&_flagJobPartitionTimeout, "partition-timeout", 0, "how long each attempt of a worker's partition may run for (e.g. 30m; unlimited by default)")
+	_jobSubmitCmd.Flags().StringVar(&_flagJobDeadLetterPrefix, "dead-letter-prefix", "", "S3 path which the items of partitions which fail after their retries, and the items which fail, are written to (e.g. s3://my-bucket/dead-letters/)")
+	_jobSubmitCmd.Flags().StringVar(&_flagJobCallbackURL, "callback-url", "", "url which the job's status and results are posted to once it has finished")
+	_jobSubmitCmd.Flags().StringVar(&_flagJobCallbackSNSTopic, "callback-sns-topic", "", "arn of an sns topic which the job's status and results are published to once it has finished")
+	_jobSubmitCmd.Flags().StringVar(&_flagJobCallbackKeyEnv, "callback-signing-key-env", "", "variable in the api's predictor.env which contains the key that the callback to --callback-url is signed with")
+	_jobSubmitCmd.Flags().Int32Var(&_flagJobCallbackRetries, "callback-max-retries", 3, "number of times the callback is retried if it fails")
+	_jobCmd.AddCommand(_jobSubmitCmd)
+
+	_jobListCmd.Flags().SortFlags = false
+	_jobListCmd.Flags().StringVarP(&_flagJobEnv, "env", "e", getDefaultEnv(_generalCommandType), "environment to use")
+	_jobCmd.AddCommand(_jobListCmd)
+
+	_jobGetCmd.Flags().SortFlags = false
+	_jobGetCmd.Flags().StringVarP(&_flagJobEnv, "env", "e", getDefaultEnv(_generalCommandType), "environment to use")
+	_jobCmd.AddCommand(_jobGetCmd)
+
+	_jobCancelCmd.Flags().SortFlags = false
+	_jobCancelCmd.Flags().StringVarP(&_flagJobEnv, "env", "e", getDefaultEnv(_generalCommandType), "environment to use")
+	_jobCmd.AddCommand(_jobCancelCmd)
+}
+
+var _jobCmd = &cobra.Command{
+	Use:   "job",
+	Short: "run batch jobs on apis",
+}
+
+var _jobSubmitCmd = &cobra.Command{
+	Use:   "submit API_NAME ITEMS_FILE",
+	Short: "add a batch job to the queue (the file contains a json list of items for the api's predictor)",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		operatorConfig := jobOperatorConfig("cli.job.submit")
+		apiName := args[0]
+
+		itemsBytes, err := files.ReadFileBytes(args[1])
+		if err != nil {
+			exit.Error(err)
+		}
+		var items []interface{}
+		if err := json.Unmarshal(itemsBytes, &items); err != nil {
+			exit.Error(ErrorInvalidBatchJobItems(args[1]))
+		}
+
+		submission := schema.BatchJobSubmission{
+			Items:      items,
+			Workers:    _flagJobWorkers,
+			MaxRetries: _flagJobMaxRetries,
+		}
+		if _flagJobPartitionTimeout != 0 {
+			partitionTimeoutSeconds := int64(math.Ceil(_flagJobPartitionTimeout.Seconds()))
+			submission.PartitionTimeoutSeconds = &partitionTimeoutSeconds
+		}
+		if _flagJobDeadLetterPrefix != "" {
+			submission.DeadLetterPrefix = &_flagJobDeadLetterPrefix
+		}
+		if _flagJobCallbackURL != "" || _flagJobCallbackSNSTopic != "" {
+			submission.Callback = &schema.BatchJobCallback{
+				MaxRetries: _flagJobCallbackRetries,
+			}
+			if _flagJobCallbackURL != "" {
+				submission.Callback.URL = &_flagJobCallbackURL
+			}
+			if _flagJobCallbackSNSTopic != "" {
+				submission.Callback.SNSTopic = &_flagJobCallbackSNSTopic
+			}
+			if _flagJobCallbackKeyEnv != "" {
+				submission.Callback.SigningKeyEnv = &_flagJobCallbackKeyEnv
+			}
+		}
+		if _flagJobPriority != "" {
+			priority := userconfig.PriorityTypeFromString(_flagJobPriority)
+			submission.Priority = &priority
+		}
+
+		batchJobResponse, err := cluster.SubmitBatchJob(operatorConfig, apiName, submission)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		fmt.Printf("submitted batch job %s (run `cortex job get %s %s` to check its status)\n", batchJobResponse.BatchJob.ID, apiName, batchJobResponse.BatchJob.ID)
+	},
+}
+
+var _jobListCmd = &cobra.Command{
+	Use:   "list [API_NAME]",
+	Short: "list the batch jobs of all apis, or of an api",
+	Args:  cobra.RangeArgs(0, 1),
+	Run: func(cmd *cobra.Command, args []string) {
+		operatorConfig := jobOperatorConfig("cli.job.list")
+		apiName := ""
+		if len(args) == 1 {
+			apiName = args[0]
+		}
+
+		batchJobsResponse, err := cluster.ListBatchJobs(operatorConfig, apiName)
+		if err != nil {
+			exit.Error(err)
+		}
+		if len(batchJobsResponse.BatchJobs) == 0 {
+			fmt.Println("no batch jobs have been submitted")
+			return
+		}
+
+		t := batchJobsTable(batchJobsResponse.BatchJobs)
+		fmt.Println(t.MustFormat())
+	},
+}
+
+var _jobGetCmd = &cobra.Command{
+	Use:   "get API_NAME JOB_ID",
+	Short: "get the status of a batch job",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		operatorConfig := jobOperatorConfig("cli.job.get")
+
+		batchJobResponse, err := cluster.GetBatchJob(operatorConfig, args[0], args[1])
+		if err != nil {
+			exit.Error(err)
+		}
+
+		fmt.Print(batchJobMessage(batchJobResponse.BatchJob))
+	},
+}
+
+var _jobCancelCmd = &cobra.Command{
+	Use:   "cancel API_NAME JOB_ID",
+	Short: "remove a batch job from the queue, or stop its workers if it's running",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		operatorConfig := jobOperatorConfig("cli.job.cancel")
+
+		batchJobResponse, err := cluster.CancelBatchJob(operatorConfig, args[0], args[1])
+		if err != nil {
+			exit.Error(err)
+		}
+
+		fmt.Printf("batch job %s is %s\n", batchJobResponse.BatchJob.ID, batchJobResponse.BatchJob.Status)
+	},
+}
+
+func jobOperatorConfig(telemetryEvent string) cluster.OperatorConfig {
+	env, err := ReadOrConfigureEnv(_flagJobEnv)
+	if err != nil {
+		telemetry.Event(telemetryEvent)
+		exit.Error(err)
+	}
+	telemetry.Event(telemetryEvent, map[string]interface{}{"provider": env.Provider.String(), "env_name": env.Name})
+
+	err = printEnvIfNotSpecified(_flagJobEnv)
+	if err != nil {
+		exit.Error(err)
+	}
+
+	if env.Provider != types.AWSProviderType {
+		exit.Error(ErrorNotSupportedInLocalEnvironment())
+	}
+
+	return MustGetOperatorConfig(env.Name)
+}
+
+func batchJobsTable(batchJobs []schema.BatchJob) table.Table {
+	rows := make([][]interface{}, len(batchJobs))
+	hasGPUs := false
+	for i, batchJob := range batchJobs {
+		submittedAt := time.Unix(batchJob.SubmittedAt, 0)
+		status := batchJob.Status
+		if batchJob.QueuedReason != "" {
+			status += " (" + batchJob.QueuedReason + ")"
+		}
+		rows[i] = []interface{}{
+			batchJob.APIName,
+			batchJob.ID,
+			batchJob.Priority,
+			batchJob.Items,
+			batchJob.Workers,
+			batchJob.GPUs,
+			libtime.SinceStr(&submittedAt),
+			status,
+		}
+		if batchJob.GPUs > 0 {
+			hasGPUs = true
+		}
+	}
+
+	return table.Table{
+		Headers: []table.Header{
+			{Title: "api"},
+			{Title: "job id"},
+			{Title: "priority"},
+			{Title: "items"},
+			{Title: "workers"},
+			{Title: "gpus", Hidden: !hasGPUs},
+			{Title: "submitted"},
+			{Title: "status"},
+		},
+		Rows: rows,
+	}
+}
+
+func batchJobMessage(batchJob schema.BatchJob) string {
+	t := batchJobsTable([]schema.BatchJob{batchJob})
+	out := t.MustFormat() + "\n"
+
+	if batchJob.Status == schema.BatchJobStatusRunning {
+		out += fmt.Sprintf("%d of %d workers have finished\n", batchJob.SucceededWorkers, batchJob.Workers)
+	}
+	if batchJob.Source != "" {
+		out += fmt.Sprintf("submitted by the api's %s for %s\n", userconfig.BatchTriggerKey, batchJob.Source)
+	}
+	if batchJob.StartedAt != nil && len(batchJob.Partitions) > 0 {
+		partitionsTable := batchJobPartitionsTable(batchJob.Partitions)
+		out += "\n" + partitionsTable.MustFormat() + "\n"
+	}
+	if batchJob.Error != "" {
+		out += fmt.Sprintf("error: %s\n", batchJob.Error)
+	}
+	if batchJob.CallbackStatus != "" {
+		out += fmt.Sprintf("callback: %s", batchJob.CallbackStatus)
+		if batchJob.CallbackError != "" {
+			out += fmt.Sprintf(" (%s)", batchJob.CallbackError)
+		}
+		out += "\n"
+	}
+	if batchJob.Status == schema.BatchJobStatusSucceeded {
+		out += fmt.Sprintf("results: %s (one file per worker; each result records the index of its item)\n", batchJob.ResultsPath)
+	}
+
+	return out
+}
+
+func batchJobPartitionsTable(partitions []schema.BatchJobPartition) table.Table {
+	rows := make([][]interface{}, len(partitions))
+	hasErrors := false
+	for i, partition := range partitions {
+		errMessage := partition.Error
+		if partition.DeadLetterPath != "" {
+			errMessage += " (items written to " + partition.DeadLetterPath + ")"
+		}
+		rows[i] = []interface{}{
+			partition.Index,
+			partition.Items,
+			partition.Status,
+			partition.Attempts,
+			partition.FailedItems,
+			errMessage,
+		}
+		if errMessage != "" {
+			hasErrors = true
+		}
+	}
+	return table.Table{
+		Headers: []table.Header{
+			{Title: "partition"},
+			{Title: "items"},
+			{Title: "status"},
+			{Title: "attempts"},
+			{Title: "failed items"},
+			{Title: "error", Hidden: !hasErrors},
+		},
+		Rows: rows,
+	}
+}
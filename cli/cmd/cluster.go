@@ -44,6 +44,8 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const _nodeMigrationPollPeriod = 10 * time.Second
+
 var (
 	_flagClusterEnv            string
 	_flagClusterConfig         string
@@ -73,6 +75,12 @@ func clusterInit() {
 	_configureCmd.Flags().BoolVarP(&_flagClusterDisallowPrompt, "yes", "y", false, "skip prompts")
 	_clusterCmd.AddCommand(_configureCmd)
 
+	_upgradeCmd.Flags().SortFlags = false
+	addClusterConfigFlag(_upgradeCmd)
+	_upgradeCmd.Flags().StringVarP(&_flagClusterEnv, "env", "e", defaultEnv, "environment to configure")
+	_upgradeCmd.Flags().BoolVarP(&_flagClusterDisallowPrompt, "yes", "y", false, "skip prompts")
+	_clusterCmd.AddCommand(_upgradeCmd)
+
 	_downCmd.Flags().SortFlags = false
 	addClusterConfigFlag(_downCmd)
 	_downCmd.Flags().BoolVarP(&_flagClusterDisallowPrompt, "yes", "y", false, "skip prompts")
@@ -138,6 +146,9 @@ var _upCmd = &cobra.Command{
 			exit.Error(err)
 		}
 
+		if clusterConfig.S3Endpoint != nil {
+			awsClient.SetS3Endpoint(*clusterConfig.S3Endpoint)
+		}
 		err = CreateBucketIfNotFound(awsClient, clusterConfig.Bucket)
 		if err != nil {
 			exit.Error(err)
@@ -240,6 +251,80 @@ var _configureCmd = &cobra.Command{
 	},
 }
 
+var _upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "replace a cluster's instances with instances which run the latest kubernetes version and ami (without downtime)",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		telemetry.Event("cli.cluster.upgrade")
+
+		if _flagClusterEnv == "local" {
+			exit.Error(ErrorNotSupportedInLocalEnvironment())
+		}
+
+		if _, err := docker.GetDockerClient(); err != nil {
+			exit.Error(err)
+		}
+
+		awsCreds, err := getAWSCredentials(_flagClusterConfig, _flagClusterEnv, _flagClusterDisallowPrompt)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		accessConfig, err := getClusterAccessConfig(_flagClusterDisallowPrompt)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		awsClient, err := newAWSClient(*accessConfig.Region, awsCreds)
+		if err != nil {
+			exit.Error(err)
+		}
+		warnIfNotAdmin(awsClient)
+
+		clusterState, err := clusterstate.GetClusterState(awsClient, accessConfig)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		err = assertClusterStatus(accessConfig, clusterState.Status, clusterstate.StatusCreateComplete)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		operatorConfig := MustGetOperatorConfig(_flagClusterEnv)
+
+		if !_flagClusterDisallowPrompt {
+			prompt.YesOrExit(fmt.Sprintf("new instances will be added to your cluster named \"%s\" in %s, your apis will be moved onto them, and then the current instances will be deleted (this will take about 30 minutes); would you like to continue?", *accessConfig.ClusterName, *accessConfig.Region), "", "")
+		}
+
+		clusterConfig := refreshCachedClusterConfig(awsCreds, accessConfig, _flagClusterDisallowPrompt)
+
+		// the new node groups are suffixed with the generation (see manager/upgrade.sh)
+		generation := time.Now().UTC().Format("20060102150405")
+
+		out, exitCode, err := runManagerUpdateCommand("/root/upgrade.sh --create-node-groups "+generation, &clusterConfig, awsCreds, _flagClusterEnv)
+		if err != nil {
+			exit.Error(err)
+		}
+		if exitCode == nil || *exitCode != 0 {
+			exit.Error(ErrorClusterUpgrade(out))
+		}
+
+		migrateNodes(operatorConfig, generation)
+
+		out, exitCode, err = runManagerUpdateCommand("/root/upgrade.sh --delete-node-groups "+generation, &clusterConfig, awsCreds, _flagClusterEnv)
+		if err != nil {
+			exit.Error(err)
+		}
+		if exitCode == nil || *exitCode != 0 {
+			exit.Error(ErrorClusterUpgrade(out))
+		}
+
+		fmt.Println("your cluster has been upgraded")
+	},
+}
+
 var _infoCmd = &cobra.Command{
 	Use:   "info",
 	Short: "get information about a cluster",
@@ -336,6 +421,13 @@ var _downCmd = &cobra.Command{
 			fmt.Println()
 		}
 
+		// the endpoint service would prevent the api load balancer from being deleted
+		if _, err := awsClient.DeleteVPCEndpointServiceByTag(clusterconfig.ClusterNameTag, *accessConfig.ClusterName); err != nil {
+			fmt.Print("\nunable to delete the api load balancer's vpc endpoint service (see error below); if it still exists after the cluster has been deleted, please delete it manually via the vpc console: https://console.aws.amazon.com/vpc/home#EndpointServices:\n")
+			errors.PrintError(err)
+			fmt.Println()
+		}
+
 		fmt.Print("￮ deleting dashboard ")
 		err = awsClient.DeleteDashboard(*accessConfig.ClusterName)
 		if err != nil {
@@ -666,6 +758,38 @@ func cmdDebug(awsCreds AWSCredentials, accessConfig *clusterconfig.AccessConfig)
 	return
 }
 
+// migrateNodes moves the cluster's apis onto the nodes of the generation, and waits for the migration to finish
+func migrateNodes(operatorConfig cluster.OperatorConfig, generation string) {
+	fmt.Print("￮ moving apis to the new instances ")
+
+	if _, err := cluster.StartNodeMigration(operatorConfig, generation); err != nil {
+		fmt.Print("\n\n")
+		exit.Error(err)
+	}
+
+	for {
+		time.Sleep(_nodeMigrationPollPeriod)
+
+		migrationResponse, err := cluster.GetNodeMigration(operatorConfig)
+		if err != nil {
+			fmt.Print("\n\n")
+			exit.Error(err)
+		}
+		migration := migrationResponse.NodeMigration
+
+		switch migration.Status {
+		case schema.NodeMigrationStatusRunning:
+			fmt.Print(".")
+		case schema.NodeMigrationStatusSucceeded:
+			fmt.Printf(" ✓ (moved %d %s)\n\n", migration.MigratedAPIs, s.PluralS("api", migration.MigratedAPIs))
+			return
+		default:
+			fmt.Print("\n\n")
+			exit.Error(ErrorNodeMigrationFailed(migration.Error))
+		}
+	}
+}
+
 func refreshCachedClusterConfig(awsCreds AWSCredentials, accessConfig *clusterconfig.AccessConfig, disallowPrompt bool) clusterconfig.Config {
 	// add empty file if cached cluster doesn't exist so that the file output by manager container maintains current user permissions
 	cachedConfigPath := cachedClusterConfigPath(*accessConfig.ClusterName, *accessConfig.Region)
@@ -0,0 +1,167 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/cortexlabs/cortex/cli/cluster"
+	"github.com/cortexlabs/cortex/pkg/lib/console"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/table"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	libtime "github.com/cortexlabs/cortex/pkg/lib/time"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var _flagCostsEnv string
+
+func costsInit() {
+	_costsCmd.Flags().SortFlags = false
+	_costsCmd.Flags().StringVarP(&_flagCostsEnv, "env", "e", getDefaultEnv(_generalCommandType), "environment to use")
+}
+
+var _costsCmd = &cobra.Command{
+	Use:   "costs",
+	Short: "estimate the hourly and daily cost of each api (and team)",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		env, err := ReadOrConfigureEnv(_flagCostsEnv)
+		if err != nil {
+			telemetry.Event("cli.costs")
+			exit.Error(err)
+		}
+		telemetry.Event("cli.costs", map[string]interface{}{"provider": env.Provider.String(), "env_name": env.Name})
+
+		err = printEnvIfNotSpecified(_flagCostsEnv)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		if env.Provider != types.AWSProviderType {
+			exit.Error(ErrorNotSupportedInLocalEnvironment())
+		}
+
+		costsResponse, err := cluster.GetCosts(MustGetOperatorConfig(env.Name))
+		if err != nil {
+			exit.Error(err)
+		}
+
+		fmt.Print(costsStr(costsResponse))
+	},
+}
+
+func costsStr(costsResponse *schema.CostsResponse) string {
+	if len(costsResponse.APIs) == 0 {
+		return console.Bold("no apis are deployed") + "\n"
+	}
+
+	var apisHourly float64
+	for _, apiCost := range costsResponse.APIs {
+		apisHourly += apiCost.Hourly
+	}
+
+	apisTable := apiCostsTable(costsResponse.APIs)
+	out := apisTable.MustFormat(&table.Opts{Sort: pointer.Bool(false)})
+
+	if len(costsResponse.Teams) > 0 {
+		teamsTable := teamCostsTable(costsResponse.Teams)
+		out += "\n" + teamsTable.MustFormat(&table.Opts{Sort: pointer.Bool(false)})
+	}
+
+	out += fmt.Sprintf("\napis: %s per hour\n", s.DollarsAndCents(apisHourly))
+	out += fmt.Sprintf("idle instance capacity: %s per hour\n", s.DollarsAndCents(costsResponse.UnallocatedHourly))
+	out += fmt.Sprintf("cluster infrastructure (eks, operator, load balancers, nat): %s per hour\n", s.DollarsAndCents(costsResponse.FixedHourly))
+
+	out += fmt.Sprintf("\ncosts are estimated from compute requests and instance prices; daily costs are averaged over the past 24 hours of samples (recorded since %s)\n", libtime.LocalTimestampHuman(&costsResponse.TrackedSince))
+
+	return out
+}
+
+func apiCostsTable(apiCosts []schema.APICost) table.Table {
+	var hasTeams bool
+	for _, apiCost := range apiCosts {
+		if apiCost.Team != "" {
+			hasTeams = true
+		}
+	}
+
+	rows := make([][]interface{}, 0, len(apiCosts))
+	for _, apiCost := range apiCosts {
+		row := []interface{}{apiCost.APIName}
+		if hasTeams {
+			row = append(row, teamStr(apiCost.Team))
+		}
+		row = append(row,
+			apiCost.Replicas,
+			s.Round(apiCost.ReplicaHours, 1, 0),
+			s.DollarsAndTenthsOfCents(apiCost.Hourly),
+			s.DollarsAndCents(apiCost.Daily),
+		)
+		rows = append(rows, row)
+	}
+
+	headers := []table.Header{{Title: "api"}}
+	if hasTeams {
+		headers = append(headers, table.Header{Title: "team"})
+	}
+	headers = append(headers,
+		table.Header{Title: "replicas"},
+		table.Header{Title: "replica hours (24h)"},
+		table.Header{Title: "cost per hour"},
+		table.Header{Title: "cost per day"},
+	)
+
+	return table.Table{
+		Headers: headers,
+		Rows:    rows,
+	}
+}
+
+func teamCostsTable(teamCosts []schema.TeamCost) table.Table {
+	rows := make([][]interface{}, 0, len(teamCosts))
+	for _, teamCost := range teamCosts {
+		rows = append(rows, []interface{}{
+			teamStr(teamCost.Team),
+			teamCost.APIs,
+			s.DollarsAndTenthsOfCents(teamCost.Hourly),
+			s.DollarsAndCents(teamCost.Daily),
+		})
+	}
+
+	return table.Table{
+		Headers: []table.Header{
+			{Title: "team"},
+			{Title: "apis"},
+			{Title: "cost per hour"},
+			{Title: "cost per day"},
+		},
+		Rows: rows,
+	}
+}
+
+// APIs which were deployed before teams were configured don't have a team
+func teamStr(team string) string {
+	if team == "" {
+		return "-"
+	}
+	return team
+}
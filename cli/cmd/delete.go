@@ -28,14 +28,16 @@ import (
 )
 
 var (
-	_flagDeleteEnv       string
-	_flagDeleteKeepCache bool
-	_flagDeleteForce     bool
+	_flagDeleteEnv        string
+	_flagDeleteFederation string
+	_flagDeleteKeepCache  bool
+	_flagDeleteForce      bool
 )
 
 func deleteInit() {
 	_deleteCmd.Flags().SortFlags = false
 	_deleteCmd.Flags().StringVarP(&_flagDeleteEnv, "env", "e", getDefaultEnv(_generalCommandType), "environment to use")
+	_deleteCmd.Flags().StringVar(&_flagDeleteFederation, "federation", "", "federation to delete from (deletes the api from each of its environments)")
 
 	// only applies to aws provider because local doesn't support multiple replicas
 	_deleteCmd.Flags().BoolVarP(&_flagDeleteForce, "force", "f", false, "delete the api without confirmation")
@@ -47,6 +49,13 @@ var _deleteCmd = &cobra.Command{
 	Short: "delete an api",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		if _flagDeleteFederation != "" {
+			federation := mustReadFederation(_flagDeleteFederation, wasEnvFlagProvided())
+			telemetry.Event("cli.delete", map[string]interface{}{"provider": types.AWSProviderType.String(), "federated": true})
+			federatedDelete(federation, args[0])
+			return
+		}
+
 		env, err := ReadOrConfigureEnv(_flagDeleteEnv)
 		if err != nil {
 			telemetry.Event("cli.delete")
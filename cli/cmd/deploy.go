@@ -24,6 +24,7 @@ import (
 
 	"github.com/cortexlabs/cortex/cli/cluster"
 	"github.com/cortexlabs/cortex/cli/local"
+	"github.com/cortexlabs/cortex/pkg/lib/console"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/exit"
 	"github.com/cortexlabs/cortex/pkg/lib/files"
@@ -45,15 +46,25 @@ var (
 	_warningFileCount    = 1000
 
 	_flagDeployEnv            string
+	_flagDeployFederation     string
 	_flagDeployForce          bool
 	_flagDeployDisallowPrompt bool
+	_flagDeployDryRun         bool
+	_flagDeployAtomic         bool
+	_flagDeployRender         bool
+	_flagDeployRecommend      bool
 )
 
 func deployInit() {
 	_deployCmd.Flags().SortFlags = false
 	_deployCmd.Flags().StringVarP(&_flagDeployEnv, "env", "e", getDefaultEnv(_generalCommandType), "environment to use")
+	_deployCmd.Flags().StringVar(&_flagDeployFederation, "federation", "", "federation to deploy to (deploys to each of its environments)")
 	_deployCmd.Flags().BoolVarP(&_flagDeployForce, "force", "f", false, "override the in-progress api update")
 	_deployCmd.Flags().BoolVarP(&_flagDeployDisallowPrompt, "yes", "y", false, "skip prompts")
+	_deployCmd.Flags().BoolVar(&_flagDeployDryRun, "dry-run", false, "show the changes that would be made to the cluster without applying them")
+	_deployCmd.Flags().BoolVar(&_flagDeployRender, "render", false, "print the kubernetes resources that would be applied to the cluster without applying them")
+	_deployCmd.Flags().BoolVar(&_flagDeployRecommend, "recommend", false, "suggest compute requests which would pack the apis' replicas onto instances more efficiently, without deploying")
+	_deployCmd.Flags().BoolVar(&_flagDeployAtomic, "atomic", false, "roll back all of the apis if any of them fails to deploy")
 }
 
 var _deployCmd = &cobra.Command{
@@ -61,6 +72,13 @@ var _deployCmd = &cobra.Command{
 	Short: "create or update apis",
 	Args:  cobra.RangeArgs(0, 1),
 	Run: func(cmd *cobra.Command, args []string) {
+		if _flagDeployFederation != "" {
+			federation := mustReadFederation(_flagDeployFederation, wasEnvFlagProvided())
+			telemetry.Event("cli.deploy", map[string]interface{}{"provider": types.AWSProviderType.String(), "federated": true})
+			federatedDeploy(federation, getConfigPath(args))
+			return
+		}
+
 		env, err := ReadOrConfigureEnv(_flagDeployEnv)
 		if err != nil {
 			telemetry.Event("cli.deploy")
@@ -74,6 +92,69 @@ var _deployCmd = &cobra.Command{
 		}
 
 		configPath := getConfigPath(args)
+
+		if _flagDeployAtomic && env.Provider != types.AWSProviderType {
+			exit.Error(ErrorNotSupportedInLocalEnvironment())
+		}
+
+		if _flagDeployRender {
+			if env.Provider != types.AWSProviderType {
+				exit.Error(ErrorNotSupportedInLocalEnvironment())
+			}
+
+			deploymentBytes, err := getDeploymentBytes(env.Provider, configPath)
+			if err != nil {
+				exit.Error(err)
+			}
+
+			renderResponse, err := cluster.Render(MustGetOperatorConfig(env.Name), configPath, deploymentBytes)
+			if err != nil {
+				exit.Error(err)
+			}
+			manifest, err := renderMessage(renderResponse.Results)
+			if err != nil {
+				exit.Error(err)
+			}
+			fmt.Print(manifest)
+			return
+		}
+
+		if _flagDeployRecommend {
+			if env.Provider != types.AWSProviderType {
+				exit.Error(ErrorNotSupportedInLocalEnvironment())
+			}
+
+			deploymentBytes, err := getDeploymentBytes(env.Provider, configPath)
+			if err != nil {
+				exit.Error(err)
+			}
+
+			recommendationsResponse, err := cluster.RecommendCompute(MustGetOperatorConfig(env.Name), configPath, deploymentBytes)
+			if err != nil {
+				exit.Error(err)
+			}
+			fmt.Print(recommendMessage(recommendationsResponse.Results))
+			return
+		}
+
+		if _flagDeployDryRun {
+			if env.Provider != types.AWSProviderType {
+				exit.Error(ErrorNotSupportedInLocalEnvironment())
+			}
+
+			deploymentBytes, err := getDeploymentBytes(env.Provider, configPath)
+			if err != nil {
+				exit.Error(err)
+			}
+
+			diffResponse, err := cluster.Diff(MustGetOperatorConfig(env.Name), configPath, deploymentBytes)
+			if err != nil {
+				exit.Error(err)
+			}
+			fmt.Print(diffMessage(diffResponse.Results))
+			return
+		}
+
 		var deployResponse schema.DeployResponse
 		if env.Provider == types.AWSProviderType {
 			deploymentBytes, err := getDeploymentBytes(env.Provider, configPath)
@@ -81,7 +162,7 @@ var _deployCmd = &cobra.Command{
 				exit.Error(err)
 			}
 
-			deployResponse, err = cluster.Deploy(MustGetOperatorConfig(env.Name), configPath, deploymentBytes, _flagDeployForce)
+			deployResponse, err = cluster.Deploy(MustGetOperatorConfig(env.Name), configPath, deploymentBytes, _flagDeployForce, _flagDeployAtomic)
 			if err != nil {
 				exit.Error(err)
 			}
@@ -140,6 +221,10 @@ func findProjectFiles(provider types.ProviderType, configPath string) ([]string,
 		ignoreFns = append(ignoreFns, cortexIgnore)
 	}
 
+	if provider != types.LocalProviderType {
+		ignoreFns = append(ignoreFns, files.RejectEnvironmentsAndDatasets(projectRoot, _warningFileBytes))
+	}
+
 	if !_flagDeployDisallowPrompt && provider != types.LocalProviderType {
 		ignoreFns = append(ignoreFns, files.PromptForFilesAboveSize(_warningFileBytes, "do you want to upload %s (%s)?"))
 	}
@@ -215,6 +300,80 @@ func deployMessage(results []schema.DeployResult, envName string) string {
 	return statusMessage + "\n\n" + apiCommandsMessage
 }
 
+func diffMessage(results []schema.DiffResult) string {
+	var sb strings.Builder
+
+	for _, result := range results {
+		if result.Error != "" {
+			sb.WriteString(result.Error + "\n\n")
+			continue
+		}
+
+		sb.WriteString(console.Bold(result.Message) + "\n")
+		for _, resource := range result.Resources {
+			switch {
+			case resource.Created:
+				sb.WriteString(fmt.Sprintf("\n  + %s %s\n", resource.Kind, resource.Name))
+			case len(resource.Changes) == 0:
+				sb.WriteString(fmt.Sprintf("\n  %s %s (no changes)\n", resource.Kind, resource.Name))
+			default:
+				sb.WriteString(fmt.Sprintf("\n  %s %s\n", resource.Kind, resource.Name))
+				for _, change := range resource.Changes {
+					sb.WriteString("    " + change.String() + "\n")
+				}
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+func recommendMessage(results []schema.ComputeRecommendationsResult) string {
+	var sb strings.Builder
+
+	for _, result := range results {
+		if result.Error != "" {
+			sb.WriteString(result.Error + "\n\n")
+			continue
+		}
+
+		if result.ReplicasPerInstance == -1 {
+			sb.WriteString(console.Bold(result.APIName) + " doesn't request any compute resources, so there are no recommendations for it\n\n")
+			continue
+		}
+
+		sb.WriteString(console.Bold(result.APIName) + fmt.Sprintf(" fits %d %s on each %s instance\n", result.ReplicasPerInstance, s.PluralS("replica", result.ReplicasPerInstance), result.InstanceType))
+		if len(result.Recommendations) == 0 {
+			sb.WriteString("\n  its compute request is packed efficiently\n")
+		}
+		for _, recommendation := range result.Recommendations {
+			sb.WriteString("\n  " + recommendation.Message + "\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// renderMessage joins the APIs' manifests into a single YAML document stream; if any API couldn't be rendered, nothing
+// is returned (since a partial manifest could be mistaken for the complete one)
+func renderMessage(results []schema.RenderResult) (string, error) {
+	var manifests []string
+	var errMessages []string
+	for _, result := range results {
+		if result.Error != "" {
+			errMessages = append(errMessages, result.Error)
+			continue
+		}
+		manifests = append(manifests, result.Manifest)
+	}
+	if len(errMessages) > 0 {
+		return "", ErrorRenderFailed(errMessages)
+	}
+	return strings.Join(manifests, "---\n"), nil
+}
+
 func mergeResultMessages(results []schema.DeployResult) string {
 	var okMessages []string
 	var errMessages []string
@@ -229,6 +388,11 @@ func mergeResultMessages(results []schema.DeployResult) string {
 
 	messages := append(okMessages, errMessages...)
 
+	// summarize deployments of many apis which partially failed, since the failures are easy to miss
+	if len(okMessages) > 0 && len(errMessages) > 0 {
+		messages = append(messages, "", fmt.Sprintf("%d %s deployed, %d failed", len(okMessages), s.PluralS("api", len(okMessages)), len(errMessages)))
+	}
+
 	return strings.Join(messages, "\n")
 }
 
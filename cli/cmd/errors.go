@@ -65,6 +65,10 @@ const (
 	ErrClusterDebug                         = "cli.cluster_debug"
 	ErrClusterRefresh                       = "cli.cluster_refresh"
 	ErrClusterDown                          = "cli.cluster_down"
+	ErrClusterUpgrade                       = "cli.cluster_upgrade"
+	ErrNodeMigrationFailed                  = "cli.node_migration_failed"
+	ErrLoadTestPayloadRequired              = "cli.load_test_payload_required"
+	ErrLoadTestFailed                       = "cli.load_test_failed"
 	ErrDuplicateCLIEnvNames                 = "cli.duplicate_cli_env_names"
 	ErrClusterUpInProgress                  = "cli.cluster_up_in_progress"
 	ErrClusterAlreadyCreated                = "cli.cluster_already_created"
@@ -76,6 +80,10 @@ const (
 	ErrClusterConfigOrPromptsRequired       = "cli.cluster_config_or_prompts_required"
 	ErrClusterAccessConfigOrPromptsRequired = "cli.cluster_access_config_or_prompts_required"
 	ErrShellCompletionNotSupported          = "cli.shell_completion_not_supported"
+	ErrEnvAndFederationFlagsSpecified       = "cli.env_and_federation_flags_specified"
+	ErrFederatedCommandFailed               = "cli.federated_command_failed"
+	ErrRenderFailed                         = "cli.render_failed"
+	ErrInvalidBatchJobItems                 = "cli.invalid_batch_job_items"
 )
 
 func ErrorInvalidProvider(providerStr string) error {
@@ -219,6 +227,35 @@ func ErrorClusterConfigure(out string) error {
 	})
 }
 
+func ErrorClusterUpgrade(out string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrClusterUpgrade,
+		Message: out,
+		NoPrint: true,
+	})
+}
+
+func ErrorNodeMigrationFailed(message string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrNodeMigrationFailed,
+		Message: fmt.Sprintf("%s\n\nyour apis are still running on the cluster's current instances; the new instances will be replaced when you run `cortex cluster upgrade` again", message),
+	})
+}
+
+func ErrorLoadTestPayloadRequired() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrLoadTestPayloadRequired,
+		Message: "please provide the file which contains the payload of the load test's requests (or use --id to show the results of a previous load test)",
+	})
+}
+
+func ErrorLoadTestFailed(message string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrLoadTestFailed,
+		Message: "the load test failed: " + message,
+	})
+}
+
 func ErrorClusterInfo(out string) error {
 	return errors.WithStack(&errors.Error{
 		Kind:    ErrClusterInfo,
@@ -320,3 +357,31 @@ func ErrorShellCompletionNotSupported(shell string) error {
 		Message: fmt.Sprintf("shell completion for %s is not supported", shell),
 	})
 }
+
+func ErrorEnvAndFederationFlagsSpecified() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrEnvAndFederationFlagsSpecified,
+		Message: "only one of --env and --federation can be specified",
+	})
+}
+
+func ErrorRenderFailed(errMessages []string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrRenderFailed,
+		Message: strings.Join(errMessages, "\n"),
+	})
+}
+
+func ErrorFederatedCommandFailed(action string, envNames []string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrFederatedCommandFailed,
+		Message: fmt.Sprintf("failed to %s in the %s %s (see above)", action, s.StrsAnd(envNames), s.PluralS("environment", len(envNames))),
+	})
+}
+
+func ErrorInvalidBatchJobItems(filePath string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidBatchJobItems,
+		Message: fmt.Sprintf("%s must contain a json list of the items which will be passed to the predictor", filePath),
+	})
+}
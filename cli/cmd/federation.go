@@ -0,0 +1,431 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cortexlabs/cortex/cli/cluster"
+	"github.com/cortexlabs/cortex/cli/types/cliconfig"
+	"github.com/cortexlabs/cortex/pkg/lib/console"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/parallel"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/lib/print"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/table"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/lib/urls"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types"
+	"github.com/cortexlabs/cortex/pkg/types/metrics"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/status"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/spf13/cobra"
+)
+
+const (
+	_titleFederatedStatus = "federated status"
+)
+
+func federationInit() {
+	_federationConfigureCmd.Flags().SortFlags = false
+	_federationCmd.AddCommand(_federationConfigureCmd)
+
+	_federationListCmd.Flags().SortFlags = false
+	_federationCmd.AddCommand(_federationListCmd)
+
+	_federationDeleteCmd.Flags().SortFlags = false
+	_federationCmd.AddCommand(_federationDeleteCmd)
+}
+
+var _federationCmd = &cobra.Command{
+	Use:   "federation",
+	Short: "manage federations (groups of environments which can be deployed to together)",
+}
+
+var _federationConfigureCmd = &cobra.Command{
+	Use:   "configure FEDERATION_NAME ENVIRONMENT_NAME...",
+	Short: "create or update a federation",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		telemetry.Event("cli.federation.configure")
+
+		federation := cliconfig.Federation{
+			Name:         args[0],
+			Environments: args[1:],
+		}
+
+		if err := addFederationToCLIConfig(federation); err != nil {
+			exit.Error(err)
+		}
+
+		print.BoldFirstLine(fmt.Sprintf("configured %s federation (%s)", federation.Name, strings.Join(federation.Environments, ", ")))
+	},
+}
+
+var _federationListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list all configured federations",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		telemetry.Event("cli.federation.list")
+
+		cliConfig, err := readCLIConfig()
+		if err != nil {
+			exit.Error(err)
+		}
+
+		if len(cliConfig.Federations) == 0 {
+			print.BoldFirstLine("no federations are configured")
+			return
+		}
+
+		for i, federation := range cliConfig.Federations {
+			fmt.Print(federation.String())
+			if i+1 < len(cliConfig.Federations) {
+				fmt.Println()
+			}
+		}
+	},
+}
+
+var _federationDeleteCmd = &cobra.Command{
+	Use:   "delete FEDERATION_NAME",
+	Short: "delete a federation configuration (its environments are not affected)",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		telemetry.Event("cli.federation.delete")
+
+		if err := removeFederationFromCLIConfig(args[0]); err != nil {
+			exit.Error(err)
+		}
+
+		print.BoldFirstLine(fmt.Sprintf("deleted the %s federation configuration", args[0]))
+	},
+}
+
+func mustReadFederation(federationName string, envFlagProvided bool) *cliconfig.Federation {
+	if envFlagProvided {
+		exit.Error(ErrorEnvAndFederationFlagsSpecified())
+	}
+
+	federation, err := readFederation(federationName)
+	if err != nil {
+		exit.Error(err)
+	}
+
+	return federation
+}
+
+// runInFederation calls fn for each of the federation's environments in parallel; the returned errors are ordered like federation.Environments
+func runInFederation(federation *cliconfig.Federation, fn func(i int, operatorConfig cluster.OperatorConfig) error) []error {
+	fns := make([]func() error, len(federation.Environments))
+	errs := make([]error, len(federation.Environments))
+
+	for i := range federation.Environments {
+		i := i
+		operatorConfig := MustGetOperatorConfig(federation.Environments[i])
+		fns[i] = func() error {
+			errs[i] = fn(i, operatorConfig)
+			return errs[i]
+		}
+	}
+
+	parallel.Run(fns[0], fns[1:]...)
+
+	return errs
+}
+
+func federatedDeploy(federation *cliconfig.Federation, configPath string) {
+	deploymentBytes, err := getDeploymentBytes(types.AWSProviderType, configPath)
+	if err != nil {
+		exit.Error(err)
+	}
+
+	if _flagDeployDryRun {
+		for _, envName := range federation.Environments {
+			diffResponse, err := cluster.Diff(MustGetOperatorConfig(envName), configPath, deploymentBytes)
+			if err != nil {
+				exit.Error(errors.Wrap(err, envName))
+			}
+			fmt.Print(console.Bold(envName+" environment") + "\n\n" + diffMessage(diffResponse.Results))
+		}
+		return
+	}
+
+	deployResponses := make([]schema.DeployResponse, len(federation.Environments))
+	errs := runInFederation(federation, func(i int, operatorConfig cluster.OperatorConfig) error {
+		deployResponse, err := cluster.Deploy(operatorConfig, configPath, deploymentBytes, _flagDeployForce, _flagDeployAtomic)
+		if err != nil {
+			return err
+		}
+		deployResponses[i] = deployResponse
+		return nil
+	})
+
+	var failedEnvNames []string
+	var results []schema.DeployResult
+	for i, envName := range federation.Environments {
+		fmt.Println(console.Bold(envName + " environment"))
+		if errs[i] != nil {
+			fmt.Println(s.Indent(errors.Message(errs[i]), "  ") + "\n")
+			failedEnvNames = append(failedEnvNames, envName)
+			continue
+		}
+		fmt.Println(s.Indent(mergeResultMessages(deployResponses[i].Results), "  ") + "\n")
+		if didAllResultsError(deployResponses[i].Results) {
+			failedEnvNames = append(failedEnvNames, envName)
+		}
+		if len(deployResponses[i].Results) > len(results) {
+			results = deployResponses[i].Results
+		}
+	}
+
+	if len(failedEnvNames) > 0 {
+		exit.Error(ErrorFederatedCommandFailed("deploy", failedEnvNames))
+	}
+
+	print.BoldFirstBlock(getFederatedAPICommandsMessage(results, federation.Name))
+}
+
+func federatedDelete(federation *cliconfig.Federation, apiName string) {
+	var failedEnvNames []string
+
+	// environments are deleted from one at a time since deleting may prompt for confirmation
+	for _, envName := range federation.Environments {
+		fmt.Println(console.Bold(envName + " environment"))
+		deleteResponse, err := cluster.Delete(MustGetOperatorConfig(envName), apiName, _flagDeleteKeepCache, _flagDeleteForce)
+		if err != nil {
+			fmt.Println(s.Indent(errors.Message(err), "  ") + "\n")
+			failedEnvNames = append(failedEnvNames, envName)
+			continue
+		}
+		fmt.Println(s.Indent(deleteResponse.Message, "  ") + "\n")
+	}
+
+	if len(failedEnvNames) > 0 {
+		exit.Error(ErrorFederatedCommandFailed("delete", failedEnvNames))
+	}
+}
+
+func getFederatedAPIs(federation *cliconfig.Federation) (string, error) {
+	apisResponses := make([]schema.GetAPIsResponse, len(federation.Environments))
+	errs := runInFederation(federation, func(i int, operatorConfig cluster.OperatorConfig) error {
+		apisRes, err := cluster.GetAPIs(operatorConfig, _flagGetSelector)
+		if err != nil {
+			return err
+		}
+		apisResponses[i] = apisRes
+		return nil
+	})
+
+	var allAPIs []spec.API
+	var allAPIStatuses []status.Status
+	var allMetrics []metrics.Metrics
+	var allEnvs []string
+	var apiNames []string
+	numFailed := 0
+	apiStatusCodes := map[string][]status.Code{}
+
+	for i, envName := range federation.Environments {
+		if errs[i] != nil {
+			numFailed++
+			continue
+		}
+		apisRes := apisResponses[i]
+		for j, api := range apisRes.APIs {
+			allEnvs = append(allEnvs, envName)
+			if _, ok := apiStatusCodes[api.Name]; !ok {
+				apiNames = append(apiNames, api.Name)
+			}
+			apiStatusCodes[api.Name] = append(apiStatusCodes[api.Name], apisRes.Statuses[j].Code)
+		}
+		allAPIs = append(allAPIs, apisRes.APIs...)
+		allAPIStatuses = append(allAPIStatuses, apisRes.Statuses...)
+		allMetrics = append(allMetrics, apisRes.AllMetrics...)
+	}
+
+	out := ""
+
+	if len(allAPIs) == 0 {
+		// if all envs errored, skip "no apis are deployed" since it's misleading
+		if numFailed != len(federation.Environments) {
+			out += console.Bold(noAPIsStr()) + "\n"
+		}
+	} else {
+		t := apiTable(allAPIs, allAPIStatuses, allMetrics, allEnvs)
+		out += t.MustFormat()
+
+		rows := make([][]interface{}, 0, len(apiNames))
+		for _, apiName := range apiNames {
+			rows = append(rows, []interface{}{apiName, federatedStatusStr(apiStatusCodes[apiName], len(federation.Environments))})
+		}
+		federatedTable := table.Table{
+			Headers: []table.Header{
+				{Title: _titleAPI},
+				{Title: _titleFederatedStatus},
+			},
+			Rows: rows,
+		}
+		out += "\n" + federatedTable.MustFormat()
+	}
+
+	out += federationErrorsStr(federation, errs)
+
+	return out, nil
+}
+
+func getFederatedAPI(federation *cliconfig.Federation, apiName string) (string, error) {
+	apiResponses := make([]*schema.GetAPIResponse, len(federation.Environments))
+	errs := runInFederation(federation, func(i int, operatorConfig cluster.OperatorConfig) error {
+		apiRes, err := cluster.GetAPI(operatorConfig, apiName)
+		if err != nil {
+			// note: if modifying this string, search the codebase for it and change all occurrences
+			if strings.HasSuffix(errors.Message(err), "is not deployed") {
+				return nil
+			}
+			return err
+		}
+		apiResponses[i] = &apiRes
+		return nil
+	})
+
+	var apis []spec.API
+	var statuses []status.Status
+	var allMetrics []metrics.Metrics
+	var envNames []string
+	var notDeployedEnvNames []string
+	var statusCodes []status.Code
+	var endpoints table.KeyValuePairs
+
+	for i, envName := range federation.Environments {
+		if errs[i] != nil {
+			continue
+		}
+		apiRes := apiResponses[i]
+		if apiRes == nil {
+			notDeployedEnvNames = append(notDeployedEnvNames, envName)
+			continue
+		}
+
+		apis = append(apis, apiRes.API)
+		statuses = append(statuses, apiRes.Status)
+		allMetrics = append(allMetrics, apiRes.Metrics)
+		envNames = append(envNames, envName)
+		statusCodes = append(statusCodes, apiRes.Status.Code)
+
+		apiEndpoint := urls.Join(apiRes.BaseURL, *apiRes.API.Endpoint)
+		if apiRes.API.Networking.APIGateway == userconfig.NoneAPIGatewayType {
+			apiEndpoint = strings.Replace(apiEndpoint, "https://", "http://", 1)
+		}
+		endpoints.Add(envName, apiEndpoint)
+	}
+
+	if len(apis) == 0 && len(notDeployedEnvNames) == len(federation.Environments) {
+		// note: if modifying this string, search the codebase for it and change all occurrences
+		return console.Bold(fmt.Sprintf("%s is not deployed", apiName)), nil
+	}
+
+	out := ""
+
+	if len(apis) > 0 {
+		t := apiTable(apis, statuses, allMetrics, envNames)
+		t.FindHeaderByTitle(_titleAPI).Hidden = true
+		out += t.MustFormat()
+	}
+
+	if len(apis) > 0 || len(notDeployedEnvNames) > 0 {
+		out = s.EnsureBlankLineIfNotEmpty(out)
+		out += console.Bold(_titleFederatedStatus+": ") + federatedStatusStr(statusCodes, len(federation.Environments)) + "\n"
+	}
+
+	if len(notDeployedEnvNames) > 0 {
+		out += fmt.Sprintf("%s is not deployed in the %s %s\n", apiName, s.StrsAnd(notDeployedEnvNames), s.PluralS("environment", len(notDeployedEnvNames)))
+	}
+
+	if len(envNames) > 0 {
+		out += titleStr("endpoints") + endpoints.String()
+	}
+
+	out += federationErrorsStr(federation, errs)
+
+	return out, nil
+}
+
+// federatedStatusStr summarizes the status of an api across a federation's environments
+func federatedStatusStr(statusCodes []status.Code, numEnvs int) string {
+	numLive := 0
+	for _, code := range statusCodes {
+		if code == status.Live {
+			numLive++
+		}
+	}
+
+	if numLive == numEnvs {
+		return status.Live.Message()
+	}
+
+	if numLive == 0 && len(statusCodes) == numEnvs {
+		allSame := true
+		for _, code := range statusCodes {
+			if code != statusCodes[0] {
+				allSame = false
+			}
+		}
+		if allSame {
+			return statusCodes[0].Message()
+		}
+	}
+
+	return fmt.Sprintf("live in %d of %d environments", numLive, numEnvs)
+}
+
+func federationErrorsStr(federation *cliconfig.Federation, errs []error) string {
+	if errors.FirstError(errs...) == nil {
+		return ""
+	}
+
+	out := ""
+	for i, err := range errs {
+		if err != nil {
+			out += fmt.Sprintf("\nunable to reach the %s environment: %s\n", federation.Environments[i], errors.Message(err))
+		}
+	}
+	return out
+}
+
+func getFederatedAPICommandsMessage(results []schema.DeployResult, federationName string) string {
+	apiName := "<api_name>"
+	if len(results) == 1 {
+		apiName = results[0].API.Name
+	}
+
+	federationArg := " --federation " + federationName
+
+	var items table.KeyValuePairs
+	items.Add("cortex get"+federationArg, "(show api statuses in each environment)")
+	items.Add(fmt.Sprintf("cortex get %s%s", apiName, federationArg), "(show api info in each environment)")
+
+	return strings.TrimSpace(items.String(&table.KeyValuePairOpts{
+		Delimiter: pointer.String(""),
+		NumSpaces: pointer.Int(2),
+	}))
+}
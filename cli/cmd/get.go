@@ -62,16 +62,21 @@ const (
 	_title2XX         = "2XX"
 	_title4XX         = "4XX"
 	_title5XX         = "5XX"
+	_titleModel       = "model"
 )
 
 var (
-	_flagGetEnv string
-	_flagWatch  bool
+	_flagGetEnv        string
+	_flagGetFederation string
+	_flagGetSelector   string
+	_flagWatch         bool
 )
 
 func getInit() {
 	_getCmd.Flags().SortFlags = false
 	_getCmd.Flags().StringVarP(&_flagGetEnv, "env", "e", getDefaultEnv(_generalCommandType), "environment to use")
+	_getCmd.Flags().StringVar(&_flagGetFederation, "federation", "", "federation to use (shows apis in each of its environments)")
+	_getCmd.Flags().StringVarP(&_flagGetSelector, "selector", "l", "", "only show apis whose labels match the selector (e.g. -l team=search,tier!=canary)")
 	_getCmd.Flags().BoolVarP(&_flagWatch, "watch", "w", false, "re-run the command every second")
 }
 
@@ -80,6 +85,18 @@ var _getCmd = &cobra.Command{
 	Short: "get information about apis",
 	Args:  cobra.RangeArgs(0, 1),
 	Run: func(cmd *cobra.Command, args []string) {
+		if _flagGetFederation != "" {
+			federation := mustReadFederation(_flagGetFederation, wasEnvFlagProvided())
+			telemetry.Event("cli.get", map[string]interface{}{"provider": types.AWSProviderType.String(), "federated": true})
+			rerun(func() (string, error) {
+				if len(args) == 1 {
+					return getFederatedAPI(federation, args[0])
+				}
+				return getFederatedAPIs(federation)
+			})
+			return
+		}
+
 		// if API_NAME is specified or env name is provided then the provider is known, otherwise provider isn't because all apis from all environments will be fetched
 		if len(args) == 1 || wasEnvFlagProvided() {
 			env, err := ReadOrConfigureEnv(_flagGetEnv)
@@ -155,9 +172,12 @@ func getAPIsInAllEnvironments() (string, error) {
 		var apisRes schema.GetAPIsResponse
 		var err error
 		if env.Provider == types.AWSProviderType {
-			apisRes, err = cluster.GetAPIs(MustGetOperatorConfig(env.Name))
+			apisRes, err = cluster.GetAPIs(MustGetOperatorConfig(env.Name), _flagGetSelector)
 		} else {
 			apisRes, err = local.GetAPIs()
+			if err == nil {
+				apisRes, err = filterAPIsBySelector(apisRes, _flagGetSelector)
+			}
 		}
 
 		if err == nil {
@@ -182,7 +202,7 @@ func getAPIsInAllEnvironments() (string, error) {
 		}
 		// if all envs errored, skip it "no apis are deployed" since it's misleading
 		if len(errorsMap) != len(cliConfig.Environments) {
-			out += console.Bold("no apis are deployed") + "\n"
+			out += console.Bold(noAPIsStr()) + "\n"
 		}
 	} else {
 		t := apiTable(allAPIs, allAPIStatuses, allMetrics, allEnvs)
@@ -211,6 +231,35 @@ func getAPIsInAllEnvironments() (string, error) {
 	return out, nil
 }
 
+// the operator filters APIs by their labels, so this is only necessary for local environments
+func filterAPIsBySelector(apisRes schema.GetAPIsResponse, selector string) (schema.GetAPIsResponse, error) {
+	if selector == "" {
+		return apisRes, nil
+	}
+
+	labelSelector, err := spec.ParseLabelSelector(selector)
+	if err != nil {
+		return schema.GetAPIsResponse{}, err
+	}
+
+	var filtered schema.GetAPIsResponse
+	for i := range apisRes.APIs {
+		if apisRes.APIs[i].MatchesLabelSelector(labelSelector) {
+			filtered.APIs = append(filtered.APIs, apisRes.APIs[i])
+			filtered.Statuses = append(filtered.Statuses, apisRes.Statuses[i])
+			filtered.AllMetrics = append(filtered.AllMetrics, apisRes.AllMetrics[i])
+		}
+	}
+	return filtered, nil
+}
+
+func noAPIsStr() string {
+	if _flagGetSelector != "" {
+		return fmt.Sprintf("no deployed apis match the selector %s", _flagGetSelector)
+	}
+	return "no apis are deployed"
+}
+
 func hideReplicaCountColumns(t *table.Table) {
 	t.FindHeaderByTitle(_titleUpToDate).Hidden = true
 	t.FindHeaderByTitle(_titleStale).Hidden = true
@@ -223,7 +272,7 @@ func getAPIs(env cliconfig.Environment, printEnv bool) (string, error) {
 	var err error
 
 	if env.Provider == types.AWSProviderType {
-		apisRes, err = cluster.GetAPIs(MustGetOperatorConfig(env.Name))
+		apisRes, err = cluster.GetAPIs(MustGetOperatorConfig(env.Name), _flagGetSelector)
 		if err != nil {
 			return "", err
 		}
@@ -232,10 +281,14 @@ func getAPIs(env cliconfig.Environment, printEnv bool) (string, error) {
 		if err != nil {
 			return "", err
 		}
+		apisRes, err = filterAPIsBySelector(apisRes, _flagGetSelector)
+		if err != nil {
+			return "", err
+		}
 	}
 
 	if len(apisRes.APIs) == 0 {
-		return console.Bold("no apis are deployed"), nil
+		return console.Bold(noAPIsStr()), nil
 	}
 
 	envNames := []string{}
@@ -306,6 +359,18 @@ func getAPI(env cliconfig.Environment, apiName string) (string, error) {
 
 	out += t.MustFormat()
 
+	if len(apiRes.Status.Replicas) > 0 {
+		replicasTable := replicaTable(apiRes.Status.Replicas)
+		out += titleStr("replicas") + replicasTable.MustFormat()
+	}
+
+	if len(apiRes.Status.Warnings) > 0 {
+		out += titleStr("warnings")
+		for _, warning := range apiRes.Status.Warnings {
+			out += fmt.Sprintf("- %s (for %s)\n", warning.Message, libtime.SinceStr(&warning.Since))
+		}
+	}
+
 	api := apiRes.API
 
 	if env.Provider != types.LocalProviderType && api.Monitoring != nil {
@@ -317,6 +382,26 @@ func getAPI(env cliconfig.Environment, apiName string) (string, error) {
 		}
 	}
 
+	if len(apiRes.Metrics.ModelStats) > 0 {
+		out += "\n" + modelMetricsStr(&apiRes.Metrics)
+	}
+
+	if apiRes.Metrics.StreamStats != nil {
+		out += "\n" + streamMetricsStr(apiRes.Metrics.StreamStats)
+	}
+
+	if len(apiRes.Metrics.GPUStats) > 0 {
+		out += "\n" + gpuMetricsStr(apiRes.Metrics.GPUStats)
+	}
+
+	if len(apiRes.Metrics.InfStats) > 0 {
+		out += "\n" + infMetricsStr(apiRes.Metrics.InfStats)
+	}
+
+	if len(apiRes.Metrics.CustomStats) > 0 {
+		out += "\n" + customMetricsStr(apiRes.Metrics.CustomStats)
+	}
+
 	apiEndpoint := apiRes.BaseURL
 	if env.Provider == types.AWSProviderType {
 		apiEndpoint = urls.Join(apiRes.BaseURL, *api.Endpoint)
@@ -428,6 +513,213 @@ func code5XXStr(metrics *metrics.Metrics) string {
 	return s.Int(metrics.NetworkStats.Code5XX)
 }
 
+func modelMetricsStr(apiMetrics *metrics.Metrics) string {
+	modelNames := make([]string, 0, len(apiMetrics.ModelStats))
+	for modelName := range apiMetrics.ModelStats {
+		modelNames = append(modelNames, modelName)
+	}
+	sort.Strings(modelNames)
+
+	var total4XX int
+	var total5XX int
+
+	rows := make([][]interface{}, len(modelNames))
+	for i, modelName := range modelNames {
+		modelMetrics := metrics.Metrics{NetworkStats: apiMetrics.ModelStats[modelName]}
+		rows[i] = []interface{}{
+			modelName,
+			latencyStr(&modelMetrics),
+			code2XXStr(&modelMetrics),
+			code4XXStr(&modelMetrics),
+			code5XXStr(&modelMetrics),
+		}
+
+		if modelMetrics.NetworkStats != nil {
+			total4XX += modelMetrics.NetworkStats.Code4XX
+			total5XX += modelMetrics.NetworkStats.Code5XX
+		}
+	}
+
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: _titleModel, MaxWidth: 40},
+			{Title: _titleAvgRequest},
+			{Title: _title2XX},
+			{Title: _title4XX, Hidden: total4XX == 0},
+			{Title: _title5XX, Hidden: total5XX == 0},
+		},
+		Rows: rows,
+	}
+
+	return t.MustFormat()
+}
+
+func streamMetricsStr(streamStats *metrics.StreamStats) string {
+	timeToFirstTokenStr := "-"
+	if streamStats.TimeToFirstToken != nil {
+		if *streamStats.TimeToFirstToken < 1000 {
+			timeToFirstTokenStr = fmt.Sprintf("%.6g ms", *streamStats.TimeToFirstToken)
+		} else {
+			timeToFirstTokenStr = fmt.Sprintf("%.6g s", *streamStats.TimeToFirstToken/1000)
+		}
+	}
+
+	tokensPerSecondStr := "-"
+	if streamStats.TokensPerSecond != nil {
+		tokensPerSecondStr = fmt.Sprintf("%.6g", *streamStats.TokensPerSecond)
+	}
+
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "streams"},
+			{Title: "avg time to first token"},
+			{Title: "avg tokens/sec"},
+		},
+		Rows: [][]interface{}{{s.Int(streamStats.Total), timeToFirstTokenStr, tokensPerSecondStr}},
+	}
+
+	return t.MustFormat()
+}
+
+func gpuMetricsStr(gpuStats map[string]*metrics.GPUStats) string {
+	podNames := make([]string, 0, len(gpuStats))
+	for podName := range gpuStats {
+		podNames = append(podNames, podName)
+	}
+	sort.Strings(podNames)
+
+	rows := make([][]interface{}, len(podNames))
+	for i, podName := range podNames {
+		stats := gpuStats[podName]
+
+		utilizationStr := "-"
+		if stats.Utilization != nil {
+			utilizationStr = fmt.Sprintf("%.3g%%", *stats.Utilization)
+		}
+
+		memoryStr := "-"
+		if stats.MemoryUsed != nil {
+			memoryStr = fmt.Sprintf("%.0f MiB", *stats.MemoryUsed)
+			if stats.MemoryTotal != nil {
+				memoryStr += fmt.Sprintf(" / %.0f MiB", *stats.MemoryTotal)
+			}
+		}
+
+		temperatureStr := "-"
+		if stats.Temperature != nil {
+			temperatureStr = fmt.Sprintf("%.0f°C", *stats.Temperature)
+		}
+
+		rows[i] = []interface{}{podName, s.Int(stats.GPUs), utilizationStr, memoryStr, temperatureStr}
+	}
+
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "pod", MaxWidth: 60},
+			{Title: "gpus"},
+			{Title: "gpu utilization"},
+			{Title: "gpu memory"},
+			{Title: "max gpu temperature"},
+		},
+		Rows: rows,
+	}
+
+	return t.MustFormat()
+}
+
+func infMetricsStr(infStats map[string]*metrics.InfStats) string {
+	podNames := make([]string, 0, len(infStats))
+	for podName := range infStats {
+		podNames = append(podNames, podName)
+	}
+	sort.Strings(podNames)
+
+	rows := make([][]interface{}, len(podNames))
+	for i, podName := range podNames {
+		stats := infStats[podName]
+
+		utilizationStr := "-"
+		if stats.Utilization != nil {
+			utilizationStr = fmt.Sprintf("%.3g%%", *stats.Utilization)
+		}
+
+		deviceMemoryStr := "-"
+		if stats.DeviceMemoryUsed != nil {
+			deviceMemoryStr = fmt.Sprintf("%.0f MiB", *stats.DeviceMemoryUsed)
+		}
+
+		hostMemoryStr := "-"
+		if stats.HostMemoryUsed != nil {
+			hostMemoryStr = fmt.Sprintf("%.0f MiB", *stats.HostMemoryUsed)
+		}
+
+		rows[i] = []interface{}{podName, s.Int(stats.NeuronCores), utilizationStr, deviceMemoryStr, hostMemoryStr}
+	}
+
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "pod", MaxWidth: 60},
+			{Title: "neuron cores"},
+			{Title: "neuron core utilization"},
+			{Title: "device memory"},
+			{Title: "runtime host memory"},
+		},
+		Rows: rows,
+	}
+
+	return t.MustFormat()
+}
+
+func customMetricsStr(customStats map[string]*metrics.CustomStats) string {
+	metricNames := make([]string, 0, len(customStats))
+	for metricName := range customStats {
+		metricNames = append(metricNames, metricName)
+	}
+	sort.Strings(metricNames)
+
+	rows := make([][]interface{}, len(metricNames))
+	for i, metricName := range metricNames {
+		stats := customStats[metricName]
+
+		totalStr := "-"
+		avgStr := "-"
+		minStr := "-"
+		maxStr := "-"
+		if stats.Type == metrics.CounterCustomMetricType {
+			if stats.Sum != nil {
+				totalStr = fmt.Sprintf("%.9g", *stats.Sum)
+			}
+		} else {
+			totalStr = s.Int(stats.Count)
+			if stats.Avg != nil {
+				avgStr = fmt.Sprintf("%.9g", *stats.Avg)
+			}
+			if stats.Min != nil {
+				minStr = fmt.Sprintf("%.9g", *stats.Min)
+			}
+			if stats.Max != nil {
+				maxStr = fmt.Sprintf("%.9g", *stats.Max)
+			}
+		}
+
+		rows[i] = []interface{}{metricName, string(stats.Type), totalStr, avgStr, minStr, maxStr}
+	}
+
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "custom metric", MaxWidth: 40},
+			{Title: "type"},
+			{Title: "total"}, // the counter's sum, or the histogram's number of values
+			{Title: "avg"},
+			{Title: "min"},
+			{Title: "max"},
+		},
+		Rows: rows,
+	}
+
+	return t.MustFormat()
+}
+
 func regressionMetricsStr(metrics *metrics.Metrics) string {
 	minStr := "-"
 	maxStr := "-"
@@ -606,6 +898,58 @@ func getAPISummary(apiEndpoint string) (*schema.APISummary, error) {
 	return &apiSummary, nil
 }
 
+func replicaTable(replicas []status.ReplicaStatus) table.Table {
+	rows := make([][]interface{}, 0, len(replicas))
+
+	var totalStale int
+	var totalRestarts int32
+
+	for _, replica := range replicas {
+		version := "up-to-date"
+		if !replica.Updated {
+			version = "stale"
+			totalStale++
+		}
+
+		lastTermination := "-"
+		if replica.LastTerminationReason != "" && replica.LastTerminationTime != nil {
+			lastTermination = fmt.Sprintf("%s (%s ago)", replica.LastTerminationReason, libtime.SinceStr(replica.LastTerminationTime))
+		}
+
+		modelStatus := "-"
+		if replica.ModelStatus != "" {
+			modelStatus = replica.ModelStatus
+		}
+
+		rows = append(rows, []interface{}{
+			replica.Name,
+			replica.Phase,
+			version,
+			replica.Restarts,
+			lastTermination,
+			modelStatus,
+			replica.Node,
+			libtime.SinceStr(&replica.CreatedAt),
+		})
+
+		totalRestarts += replica.Restarts
+	}
+
+	return table.Table{
+		Headers: []table.Header{
+			{Title: "replica"},
+			{Title: _titleStatus},
+			{Title: "version", Hidden: totalStale == 0},
+			{Title: "restarts"},
+			{Title: "last termination", Hidden: totalRestarts == 0},
+			{Title: _titleModel},
+			{Title: "node"},
+			{Title: "age"},
+		},
+		Rows: rows,
+	}
+}
+
 func titleStr(title string) string {
 	return "\n" + console.Bold(title) + "\n"
 }
@@ -113,6 +113,30 @@ var _cliConfigValidation = &cr.StructValidation{
 				},
 			},
 		},
+		{
+			StructField: "Federations",
+			StructListValidation: &cr.StructListValidation{
+				AllowExplicitNull: true,
+				StructValidation: &cr.StructValidation{
+					StructFieldValidations: []*cr.StructFieldValidation{
+						{
+							StructField: "Name",
+							StringValidation: &cr.StringValidation{
+								Required:  true,
+								MaxLength: 63,
+							},
+						},
+						{
+							StructField: "Environments",
+							StringListValidation: &cr.StringListValidation{
+								Required:  true,
+								MinLength: 1,
+							},
+						},
+					},
+				},
+			},
+		},
 	},
 }
 
@@ -877,6 +901,12 @@ func removeEnvFromCLIConfig(envName string) error {
 		return cliconfig.ErrorEnvironmentNotConfigured(envName)
 	}
 
+	for _, federation := range cliConfig.Federations {
+		if slices.HasString(federation.Environments, envName) {
+			return cliconfig.ErrorEnvironmentInFederation(envName, federation.Name)
+		}
+	}
+
 	cliConfig.Environments = updatedEnvs
 
 	if envName == prevDefault {
@@ -890,6 +920,75 @@ func removeEnvFromCLIConfig(envName string) error {
 	return nil
 }
 
+func readFederation(federationName string) (*cliconfig.Federation, error) {
+	cliConfig, err := readCLIConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	federation := cliConfig.FindFederation(federationName)
+	if federation == nil {
+		return nil, cliconfig.ErrorFederationNotConfigured(federationName)
+	}
+
+	return federation, nil
+}
+
+func addFederationToCLIConfig(newFederation cliconfig.Federation) error {
+	cliConfig, err := readCLIConfig()
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i, prevFederation := range cliConfig.Federations {
+		if prevFederation.Name == newFederation.Name {
+			cliConfig.Federations[i] = &newFederation
+			replaced = true
+			break
+		}
+	}
+
+	if !replaced {
+		cliConfig.Federations = append(cliConfig.Federations, &newFederation)
+	}
+
+	if err := writeCLIConfig(cliConfig); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func removeFederationFromCLIConfig(federationName string) error {
+	cliConfig, err := readCLIConfig()
+	if err != nil {
+		return err
+	}
+
+	var updatedFederations []*cliconfig.Federation
+	deleted := false
+	for _, federation := range cliConfig.Federations {
+		if federation.Name == federationName {
+			deleted = true
+			continue
+		}
+		updatedFederations = append(updatedFederations, federation)
+	}
+
+	if !deleted {
+		return cliconfig.ErrorFederationNotConfigured(federationName)
+	}
+
+	cliConfig.Federations = updatedFederations
+
+	if err := writeCLIConfig(cliConfig); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func readCLIConfig() (cliconfig.CLIConfig, error) {
 	if _cachedCLIConfig != nil {
 		return *_cachedCLIConfig, nil
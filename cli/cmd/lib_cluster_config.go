@@ -251,6 +251,11 @@ func setConfigFieldsFromCached(userClusterConfig *clusterconfig.Config, cachedCl
 	}
 	userClusterConfig.Bucket = cachedClusterConfig.Bucket
 
+	if userClusterConfig.S3Endpoint != nil && (cachedClusterConfig.S3Endpoint == nil || *userClusterConfig.S3Endpoint != *cachedClusterConfig.S3Endpoint) {
+		return clusterconfig.ErrorConfigCannotBeChangedOnUpdate(clusterconfig.S3EndpointKey, cachedClusterConfig.S3Endpoint)
+	}
+	userClusterConfig.S3Endpoint = cachedClusterConfig.S3Endpoint
+
 	if userClusterConfig.LogGroup != "" && userClusterConfig.LogGroup != cachedClusterConfig.LogGroup {
 		return clusterconfig.ErrorConfigCannotBeChangedOnUpdate(clusterconfig.LogGroupKey, cachedClusterConfig.LogGroup)
 	}
@@ -273,6 +278,10 @@ func setConfigFieldsFromCached(userClusterConfig *clusterconfig.Config, cachedCl
 	}
 	userClusterConfig.AvailabilityZones = cachedClusterConfig.AvailabilityZones
 
+	if (len(userClusterConfig.NodeGroups) > 0 || len(cachedClusterConfig.NodeGroups) > 0) && !reflect.DeepEqual(userClusterConfig.NodeGroups, cachedClusterConfig.NodeGroups) {
+		return clusterconfig.ErrorConfigCannotBeChangedOnUpdate(clusterconfig.NodeGroupsKey, s.ObjFlat(cachedClusterConfig.NodeGroups))
+	}
+
 	if s.Obj(cachedClusterConfig.SSLCertificateARN) != s.Obj(userClusterConfig.SSLCertificateARN) {
 		return clusterconfig.ErrorConfigCannotBeChangedOnUpdate(clusterconfig.SSLCertificateARNKey, cachedClusterConfig.SSLCertificateARN)
 	}
@@ -308,11 +317,42 @@ func setConfigFieldsFromCached(userClusterConfig *clusterconfig.Config, cachedCl
 	}
 	userClusterConfig.APILoadBalancerScheme = cachedClusterConfig.APILoadBalancerScheme
 
+	if !strset.New(userClusterConfig.APILoadBalancerEIPs...).IsEqual(strset.New(cachedClusterConfig.APILoadBalancerEIPs...)) {
+		return clusterconfig.ErrorConfigCannotBeChangedOnUpdate(clusterconfig.APILoadBalancerEIPsKey, cachedClusterConfig.APILoadBalancerEIPs)
+	}
+	userClusterConfig.APILoadBalancerEIPs = cachedClusterConfig.APILoadBalancerEIPs
+
 	if userClusterConfig.OperatorLoadBalancerScheme != cachedClusterConfig.OperatorLoadBalancerScheme {
 		return clusterconfig.ErrorConfigCannotBeChangedOnUpdate(clusterconfig.OperatorLoadBalancerSchemeKey, cachedClusterConfig.OperatorLoadBalancerScheme)
 	}
 	userClusterConfig.OperatorLoadBalancerScheme = cachedClusterConfig.OperatorLoadBalancerScheme
 
+	if userClusterConfig.NetworkingBackend != cachedClusterConfig.NetworkingBackend {
+		return clusterconfig.ErrorConfigCannotBeChangedOnUpdate(clusterconfig.NetworkingBackendKey, cachedClusterConfig.NetworkingBackend)
+	}
+	userClusterConfig.NetworkingBackend = cachedClusterConfig.NetworkingBackend
+
+	if userClusterConfig.APIMTLS != cachedClusterConfig.APIMTLS {
+		return clusterconfig.ErrorConfigCannotBeChangedOnUpdate(clusterconfig.APIMTLSKey, cachedClusterConfig.APIMTLS)
+	}
+	userClusterConfig.APIMTLS = cachedClusterConfig.APIMTLS
+
+	if userClusterConfig.RequestMonitor != cachedClusterConfig.RequestMonitor {
+		return clusterconfig.ErrorConfigCannotBeChangedOnUpdate(clusterconfig.RequestMonitorKey, cachedClusterConfig.RequestMonitor)
+	}
+	userClusterConfig.RequestMonitor = cachedClusterConfig.RequestMonitor
+
+	// running pods would keep publishing at the old interval, which the autoscaler can't reconcile
+	if userClusterConfig.RequestMonitorFlushInterval != cachedClusterConfig.RequestMonitorFlushInterval {
+		return clusterconfig.ErrorConfigCannotBeChangedOnUpdate(clusterconfig.RequestMonitorFlushIntervalKey, cachedClusterConfig.RequestMonitorFlushInterval)
+	}
+	userClusterConfig.RequestMonitorFlushInterval = cachedClusterConfig.RequestMonitorFlushInterval
+
+	if !strset.New(userClusterConfig.MetricSinks...).IsEqual(strset.New(cachedClusterConfig.MetricSinks...)) {
+		return clusterconfig.ErrorConfigCannotBeChangedOnUpdate(clusterconfig.MetricSinksKey, cachedClusterConfig.MetricSinks)
+	}
+	userClusterConfig.MetricSinks = cachedClusterConfig.MetricSinks
+
 	if userClusterConfig.Spot != nil && *userClusterConfig.Spot != *cachedClusterConfig.Spot {
 		return clusterconfig.ErrorConfigCannotBeChangedOnUpdate(clusterconfig.SpotKey, *cachedClusterConfig.Spot)
 	}
@@ -419,6 +459,20 @@ func confirmInstallClusterConfig(clusterConfig *clusterconfig.Config, awsCreds A
 
 	rows = append(rows, []interface{}{workerInstanceStr, workerPriceStr})
 	rows = append(rows, []interface{}{ebsInstanceStr, s.DollarsAndTenthsOfCents(apiEBSPrice) + " each"})
+
+	for _, nodeGroup := range clusterConfig.NodeGroups {
+		nodeGroupInstancePrice := aws.InstanceMetadatas[*clusterConfig.Region][nodeGroup.InstanceType].Price
+		totalMinPrice += float64(nodeGroup.MinInstances) * (nodeGroupInstancePrice + apiEBSPrice)
+		totalMaxPrice += float64(nodeGroup.MaxInstances) * (nodeGroupInstancePrice + apiEBSPrice)
+
+		nodeGroupPriceStr := s.DollarsMaxPrecision(nodeGroupInstancePrice+apiEBSPrice) + " each"
+		if nodeGroup.Spot {
+			nodeGroupPriceStr = "up to " + nodeGroupPriceStr + " (varies based on spot price)"
+		}
+		nodeGroupInstanceStr := fmt.Sprintf("%d - %d %s instances (including ebs volumes) for the %s node group", nodeGroup.MinInstances, nodeGroup.MaxInstances, nodeGroup.InstanceType, nodeGroup.Name)
+		rows = append(rows, []interface{}{nodeGroupInstanceStr, nodeGroupPriceStr})
+	}
+
 	rows = append(rows, []interface{}{"1 t3.medium instance for the operator", s.DollarsMaxPrecision(operatorInstancePrice)})
 	rows = append(rows, []interface{}{"1 20gb ebs volume for the operator", s.DollarsAndTenthsOfCents(operatorEBSPrice)})
 	rows = append(rows, []interface{}{"2 network load balancers", s.DollarsMaxPrecision(nlbPrice) + " each"})
@@ -498,6 +552,9 @@ func clusterConfigConfirmationStr(clusterConfig clusterconfig.Config, awsCreds A
 		items.Add(clusterconfig.AvailabilityZonesUserKey, clusterConfig.AvailabilityZones)
 	}
 	items.Add(clusterconfig.BucketUserKey, clusterConfig.Bucket)
+	if clusterConfig.S3Endpoint != nil {
+		items.Add(clusterconfig.S3EndpointUserKey, *clusterConfig.S3Endpoint)
+	}
 	items.Add(clusterconfig.ClusterNameUserKey, clusterConfig.ClusterName)
 	if clusterConfig.LogGroup != defaultConfig.LogGroup {
 		items.Add(clusterconfig.LogGroupUserKey, clusterConfig.LogGroup)
@@ -533,6 +590,45 @@ func clusterConfigConfirmationStr(clusterConfig clusterconfig.Config, awsCreds A
 	if clusterConfig.OperatorLoadBalancerScheme != defaultConfig.OperatorLoadBalancerScheme {
 		items.Add(clusterconfig.OperatorLoadBalancerSchemeUserKey, clusterConfig.OperatorLoadBalancerScheme)
 	}
+	if len(clusterConfig.APILoadBalancerEIPs) > 0 {
+		items.Add(clusterconfig.APILoadBalancerEIPsUserKey, clusterConfig.APILoadBalancerEIPs)
+	}
+	if clusterConfig.APILoadBalancerPrivateLink != nil {
+		items.Add(clusterconfig.PrivateLinkPrincipalsUserKey, clusterConfig.APILoadBalancerPrivateLink.AllowedPrincipals)
+		items.Add(clusterconfig.PrivateLinkAcceptanceUserKey, s.YesNo(clusterConfig.APILoadBalancerPrivateLink.AcceptanceRequired))
+	}
+	if clusterConfig.OperatorReplicas != defaultConfig.OperatorReplicas {
+		items.Add(clusterconfig.OperatorReplicasUserKey, clusterConfig.OperatorReplicas)
+	}
+	if clusterConfig.NetworkingBackend != defaultConfig.NetworkingBackend {
+		items.Add(clusterconfig.NetworkingBackendUserKey, clusterConfig.NetworkingBackend)
+		items.Add(clusterconfig.IngressClassUserKey, clusterConfig.IngressClass)
+		items.Add(clusterconfig.IngressControllerServiceUserKey, clusterConfig.IngressControllerService)
+	}
+	if clusterConfig.APIMTLS != defaultConfig.APIMTLS {
+		items.Add(clusterconfig.APIMTLSUserKey, s.YesNo(clusterConfig.APIMTLS))
+	}
+	if clusterConfig.RequestMonitor != defaultConfig.RequestMonitor {
+		items.Add(clusterconfig.RequestMonitorUserKey, clusterConfig.RequestMonitor)
+	}
+	if clusterConfig.RequestMonitorCPU != defaultConfig.RequestMonitorCPU {
+		items.Add(clusterconfig.RequestMonitorCPUUserKey, clusterConfig.RequestMonitorCPU)
+	}
+	if clusterConfig.RequestMonitorMem != defaultConfig.RequestMonitorMem {
+		items.Add(clusterconfig.RequestMonitorMemUserKey, clusterConfig.RequestMonitorMem)
+	}
+	if clusterConfig.RequestMonitorFlushInterval != defaultConfig.RequestMonitorFlushInterval {
+		items.Add(clusterconfig.RequestMonitorFlushIntervalUserKey, s.Int64(clusterConfig.RequestMonitorFlushInterval)+"s")
+	}
+	if !strset.New(clusterConfig.MetricSinks...).IsEqual(strset.New(defaultConfig.MetricSinks...)) {
+		items.Add(clusterconfig.MetricSinksUserKey, s.StrsAnd(clusterConfig.MetricSinks))
+	}
+	if clusterConfig.HasMetricSink(clusterconfig.StatsDMetricSink) {
+		if clusterConfig.StatsDHost != nil {
+			items.Add(clusterconfig.StatsDHostUserKey, *clusterConfig.StatsDHost)
+		}
+		items.Add(clusterconfig.StatsDPortUserKey, clusterConfig.StatsDPort)
+	}
 
 	if clusterConfig.Spot != nil && *clusterConfig.Spot != *defaultConfig.Spot {
 		items.Add(clusterconfig.SpotUserKey, s.YesNo(clusterConfig.Spot != nil && *clusterConfig.Spot))
@@ -567,6 +663,33 @@ func clusterConfigConfirmationStr(clusterConfig clusterconfig.Config, awsCreds A
 		}
 	}
 
+	if len(clusterConfig.APIEnvConfigMaps) > 0 {
+		items.Add(clusterconfig.APIEnvConfigMapsUserKey, clusterConfig.APIEnvConfigMaps)
+	}
+	if len(clusterConfig.APIEnvSecrets) > 0 {
+		items.Add(clusterconfig.APIEnvSecretsUserKey, clusterConfig.APIEnvSecrets)
+	}
+	if clusterConfig.Notifications != nil {
+		items.Add(clusterconfig.NotificationsUserKey, clusterConfig.Notifications.UserStr())
+	}
+
+	if clusterConfig.Overprovisioning != nil && clusterConfig.Overprovisioning.Replicas > 0 {
+		items.Add(clusterconfig.OverprovisioningUserKey, clusterConfig.Overprovisioning.UserStr())
+	}
+
+	if clusterConfig.MaxProjectSize != defaultConfig.MaxProjectSize {
+		items.Add(clusterconfig.MaxProjectSizeUserKey, clusterConfig.MaxProjectSize)
+	}
+	if clusterConfig.P2PModelDistribution != defaultConfig.P2PModelDistribution {
+		items.Add(clusterconfig.P2PModelDistributionUserKey, s.YesNo(clusterConfig.P2PModelDistribution))
+	}
+	if clusterConfig.Environment != nil {
+		items.Add(clusterconfig.EnvironmentUserKey, *clusterConfig.Environment)
+	}
+	if len(clusterConfig.Variables) > 0 {
+		items.Add(clusterconfig.VariablesUserKey, s.ObjFlat(clusterConfig.Variables))
+	}
+
 	if clusterConfig.Telemetry != defaultConfig.Telemetry {
 		items.Add(clusterconfig.TelemetryUserKey, clusterConfig.Telemetry)
 	}
@@ -582,6 +705,15 @@ func clusterConfigConfirmationStr(clusterConfig clusterconfig.Config, awsCreds A
 	if clusterConfig.ImageRequestMonitor != defaultConfig.ImageRequestMonitor {
 		items.Add(clusterconfig.ImageRequestMonitorUserKey, clusterConfig.ImageRequestMonitor)
 	}
+	if clusterConfig.ImageModelOptimizer != defaultConfig.ImageModelOptimizer {
+		items.Add(clusterconfig.ImageModelOptimizerUserKey, clusterConfig.ImageModelOptimizer)
+	}
+	if clusterConfig.ImageLoadTester != defaultConfig.ImageLoadTester {
+		items.Add(clusterconfig.ImageLoadTesterUserKey, clusterConfig.ImageLoadTester)
+	}
+	if clusterConfig.ImageKaniko != defaultConfig.ImageKaniko {
+		items.Add(clusterconfig.ImageKanikoUserKey, clusterConfig.ImageKaniko)
+	}
 	if clusterConfig.ImageClusterAutoscaler != defaultConfig.ImageClusterAutoscaler {
 		items.Add(clusterconfig.ImageClusterAutoscalerUserKey, clusterConfig.ImageClusterAutoscaler)
 	}
@@ -594,15 +726,24 @@ func clusterConfigConfirmationStr(clusterConfig clusterconfig.Config, awsCreds A
 	if clusterConfig.ImageNeuronRTD != defaultConfig.ImageNeuronRTD {
 		items.Add(clusterconfig.ImageNeuronRTDUserKey, clusterConfig.ImageNeuronRTD)
 	}
+	if clusterConfig.ImageNeuronMonitor != defaultConfig.ImageNeuronMonitor {
+		items.Add(clusterconfig.ImageNeuronMonitorUserKey, clusterConfig.ImageNeuronMonitor)
+	}
 	if clusterConfig.ImageNvidia != defaultConfig.ImageNvidia {
 		items.Add(clusterconfig.ImageNvidiaUserKey, clusterConfig.ImageNvidia)
 	}
+	if clusterConfig.ImageDCGMExporter != defaultConfig.ImageDCGMExporter {
+		items.Add(clusterconfig.ImageDCGMExporterUserKey, clusterConfig.ImageDCGMExporter)
+	}
 	if clusterConfig.ImageFluentd != defaultConfig.ImageFluentd {
 		items.Add(clusterconfig.ImageFluentdUserKey, clusterConfig.ImageFluentd)
 	}
 	if clusterConfig.ImageStatsd != defaultConfig.ImageStatsd {
 		items.Add(clusterconfig.ImageStatsdUserKey, clusterConfig.ImageStatsd)
 	}
+	if clusterConfig.ImagePause != defaultConfig.ImagePause {
+		items.Add(clusterconfig.ImagePauseUserKey, clusterConfig.ImagePause)
+	}
 	if clusterConfig.ImageIstioProxy != defaultConfig.ImageIstioProxy {
 		items.Add(clusterconfig.ImageIstioProxyUserKey, clusterConfig.ImageIstioProxy)
 	}
@@ -0,0 +1,202 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cortexlabs/cortex/cli/cluster"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/files"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/table"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/spf13/cobra"
+)
+
+const _loadTestPollPeriod = 5 * time.Second
+
+var (
+	_flagLoadTestEnv             string
+	_flagLoadTestMinConcurrency  int
+	_flagLoadTestMaxConcurrency  int
+	_flagLoadTestConcurrencyStep int
+	_flagLoadTestStepDuration    time.Duration
+	_flagLoadTestMaxLatency      time.Duration
+	_flagLoadTestContentType     string
+	_flagLoadTestID              string
+)
+
+func loadTestInit() {
+	_loadTestCmd.Flags().SortFlags = false
+	_loadTestCmd.Flags().StringVarP(&_flagLoadTestEnv, "env", "e", getDefaultEnv(_generalCommandType), "environment to use")
+	_loadTestCmd.Flags().IntVar(&_flagLoadTestMinConcurrency, "min-concurrency", 1, "number of concurrent requests in the first step of the ramp")
+	_loadTestCmd.Flags().IntVar(&_flagLoadTestMaxConcurrency, "max-concurrency", 10, "number of concurrent requests in the last step of the ramp")
+	_loadTestCmd.Flags().IntVar(&_flagLoadTestConcurrencyStep, "concurrency-step", 1, "increase in concurrent requests between steps of the ramp")
+	_loadTestCmd.Flags().DurationVar(&_flagLoadTestStepDuration, "step-duration", 30*time.Second, "duration of each step of the ramp")
+	_loadTestCmd.Flags().DurationVar(&_flagLoadTestMaxLatency, "max-latency", 0, "p99 latency above which a step isn't considered sustainable (e.g. 500ms)")
+	_loadTestCmd.Flags().StringVar(&_flagLoadTestContentType, "content-type", "application/json", "content type of the requests")
+	_loadTestCmd.Flags().StringVar(&_flagLoadTestID, "id", "", "show the results of a previous load test instead of starting one")
+}
+
+var _loadTestCmd = &cobra.Command{
+	Use:   "load-test API_NAME [PAYLOAD_FILE]",
+	Short: "measure an api's latency and throughput under increasing load",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		env, err := ReadOrConfigureEnv(_flagLoadTestEnv)
+		if err != nil {
+			telemetry.Event("cli.load-test")
+			exit.Error(err)
+		}
+		telemetry.Event("cli.load-test", map[string]interface{}{"provider": env.Provider.String(), "env_name": env.Name})
+
+		err = printEnvIfNotSpecified(_flagLoadTestEnv)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		if env.Provider != types.AWSProviderType {
+			exit.Error(ErrorNotSupportedInLocalEnvironment())
+		}
+
+		operatorConfig := MustGetOperatorConfig(env.Name)
+		apiName := args[0]
+
+		loadTestID := _flagLoadTestID
+		if loadTestID == "" {
+			if len(args) < 2 {
+				exit.Error(ErrorLoadTestPayloadRequired())
+			}
+			payload, err := files.ReadFileBytes(args[1])
+			if err != nil {
+				exit.Error(err)
+			}
+
+			loadTestResponse, err := cluster.StartLoadTest(operatorConfig, apiName, schema.LoadTestConfig{
+				Payload:         string(payload),
+				ContentType:     _flagLoadTestContentType,
+				MinConcurrency:  _flagLoadTestMinConcurrency,
+				MaxConcurrency:  _flagLoadTestMaxConcurrency,
+				ConcurrencyStep: _flagLoadTestConcurrencyStep,
+				StepDuration:    int64(_flagLoadTestStepDuration.Seconds()),
+				MaxLatency:      float64(_flagLoadTestMaxLatency) / float64(time.Millisecond),
+			})
+			if err != nil {
+				exit.Error(err)
+			}
+			loadTestID = loadTestResponse.LoadTest.ID
+
+			fmt.Printf("started load test %s (if this command is interrupted, the load test keeps running; run `cortex load-test %s --id %s` to see its results)\n\n", loadTestID, apiName, loadTestID)
+		}
+
+		loadTest := waitForLoadTest(operatorConfig, apiName, loadTestID)
+		fmt.Print(loadTestMessage(loadTest))
+	},
+}
+
+func waitForLoadTest(operatorConfig cluster.OperatorConfig, apiName string, loadTestID string) schema.LoadTest {
+	printedProgress := false
+	for {
+		loadTestResponse, err := cluster.GetLoadTest(operatorConfig, apiName, loadTestID)
+		if err != nil {
+			exit.Error(err)
+		}
+		loadTest := loadTestResponse.LoadTest
+
+		if loadTest.Status != schema.LoadTestStatusRunning {
+			if printedProgress {
+				fmt.Print("\n\n")
+			}
+			if loadTest.Status != schema.LoadTestStatusCompleted {
+				message := loadTest.Error
+				if loadTest.Status == schema.LoadTestStatusInterrupted {
+					message = "the operator restarted while the load test was running"
+				}
+				exit.Error(ErrorLoadTestFailed(message))
+			}
+			return loadTest
+		}
+
+		if !printedProgress {
+			fmt.Print("￮ sending requests ")
+			printedProgress = true
+		} else {
+			fmt.Print(".")
+		}
+		time.Sleep(_loadTestPollPeriod)
+	}
+}
+
+func loadTestMessage(loadTest schema.LoadTest) string {
+	rows := make([][]interface{}, len(loadTest.Steps))
+	for i, step := range loadTest.Steps {
+		sustainable := "no"
+		if step.Sustainable {
+			sustainable = "yes"
+		}
+		rows[i] = []interface{}{
+			step.Concurrency,
+			step.Requests,
+			step.Errors,
+			s.Round(step.QPS, 1, 0),
+			s.Round(step.P50, 0, 0) + " ms",
+			s.Round(step.P90, 0, 0) + " ms",
+			s.Round(step.P99, 0, 0) + " ms",
+			step.Replicas,
+			step.GPUs,
+			sustainable,
+		}
+	}
+
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "concurrency"},
+			{Title: "requests"},
+			{Title: "errors"},
+			{Title: "qps"},
+			{Title: "p50"},
+			{Title: "p90"},
+			{Title: "p99"},
+			{Title: "replicas"},
+			{Title: "gpus", Hidden: loadTest.MaxGPUs == 0},
+			{Title: "sustainable"},
+		},
+		Rows: rows,
+	}
+
+	out := t.MustFormat() + "\n"
+	if loadTest.MaxSustainableQPS > 0 {
+		out += fmt.Sprintf("max sustainable qps: %s\n", s.Round(loadTest.MaxSustainableQPS, 1, 0))
+	} else {
+		out += "none of the steps were sustainable (more than 1% of their requests failed, or their p99 latency exceeded the maximum latency)\n"
+	}
+	out += fmt.Sprintf("max replicas: %d", loadTest.MaxReplicas)
+	if loadTest.MaxGPUs > 0 {
+		out += fmt.Sprintf(" (%d %s)", loadTest.MaxGPUs, s.PluralS("gpu", loadTest.MaxGPUs))
+	}
+	out += "\n"
+	if loadTest.RecommendedTargetReplicaConcurrency != nil {
+		out += fmt.Sprintf("recommended %s: %s\n", userconfig.TargetReplicaConcurrencyKey, s.Float64(*loadTest.RecommendedTargetReplicaConcurrency))
+	}
+
+	return out
+}
@@ -30,11 +30,21 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var _flagLogsEnv string
+var (
+	_flagLogsEnv        string
+	_flagLogsContainers []string
+	_flagLogsFilter     string
+	_flagLogsSince      string
+	_flagLogsUntil      string
+)
 
 func logsInit() {
 	_logsCmd.Flags().SortFlags = false
 	_logsCmd.Flags().StringVarP(&_flagLogsEnv, "env", "e", getDefaultEnv(_generalCommandType), "environment to use")
+	_logsCmd.Flags().StringSliceVarP(&_flagLogsContainers, "container", "c", nil, "only show logs from these containers (api, serve, downloader, request-monitor, or explainer)")
+	_logsCmd.Flags().StringVar(&_flagLogsFilter, "filter", "", "only show lines which match this regular expression")
+	_logsCmd.Flags().StringVar(&_flagLogsSince, "since", "", "only show logs after this time (an RFC 3339 timestamp, or a duration such as 1h30m)")
+	_logsCmd.Flags().StringVar(&_flagLogsUntil, "until", "", "only show logs before this time, and exit instead of following new logs (an RFC 3339 timestamp, or a duration such as 10m)")
 }
 
 var _logsCmd = &cobra.Command{
@@ -55,8 +65,26 @@ var _logsCmd = &cobra.Command{
 		}
 
 		apiName := args[0]
+
+		tailOpts := cluster.TailOptions{
+			Containers: _flagLogsContainers,
+			Filter:     _flagLogsFilter,
+			Since:      _flagLogsSince,
+			Until:      _flagLogsUntil,
+		}
+		isTail := len(tailOpts.Containers) > 0 || tailOpts.Filter != "" || tailOpts.Since != "" || tailOpts.Until != ""
+
+		if isTail && env.Provider != types.AWSProviderType {
+			exit.Error(ErrorNotSupportedInLocalEnvironment())
+		}
+
 		if env.Provider == types.AWSProviderType {
-			err := cluster.StreamLogs(MustGetOperatorConfig(env.Name), apiName)
+			var err error
+			if isTail {
+				err = cluster.TailLogs(MustGetOperatorConfig(env.Name), apiName, tailOpts)
+			} else {
+				err = cluster.StreamLogs(MustGetOperatorConfig(env.Name), apiName)
+			}
 			if err != nil {
 				// note: if modifying this string, search the codebase for it and change all occurrences
 				if strings.HasSuffix(errors.Message(err), "is not deployed") {
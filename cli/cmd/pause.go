@@ -0,0 +1,62 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/cortexlabs/cortex/cli/cluster"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/print"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var _flagPauseEnv string
+
+func pauseInit() {
+	_pauseCmd.Flags().SortFlags = false
+	_pauseCmd.Flags().StringVarP(&_flagPauseEnv, "env", "e", getDefaultEnv(_generalCommandType), "environment to use")
+}
+
+var _pauseCmd = &cobra.Command{
+	Use:   "pause API_NAME",
+	Short: "scale an api to 0 replicas (its configuration is kept)",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		env, err := ReadOrConfigureEnv(_flagPauseEnv)
+		if err != nil {
+			telemetry.Event("cli.pause")
+			exit.Error(err)
+		}
+		telemetry.Event("cli.pause", map[string]interface{}{"provider": env.Provider.String(), "env_name": env.Name})
+
+		err = printEnvIfNotSpecified(_flagPauseEnv)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		if env.Provider != types.AWSProviderType {
+			exit.Error(ErrorNotSupportedInLocalEnvironment())
+		}
+
+		pauseResponse, err := cluster.Pause(MustGetOperatorConfig(env.Name), args[0])
+		if err != nil {
+			exit.Error(err)
+		}
+		print.BoldFirstLine(pauseResponse.Message)
+	},
+}
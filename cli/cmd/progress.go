@@ -0,0 +1,70 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cortexlabs/cortex/cli/cluster"
+	"github.com/cortexlabs/cortex/pkg/lib/console"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var _flagProgressEnv string
+
+func progressInit() {
+	_progressCmd.Flags().SortFlags = false
+	_progressCmd.Flags().StringVarP(&_flagProgressEnv, "env", "e", getDefaultEnv(_generalCommandType), "environment to use")
+}
+
+var _progressCmd = &cobra.Command{
+	Use:   "progress API_NAME",
+	Short: "stream the progress of an api's rollout",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		env, err := ReadOrConfigureEnv(_flagProgressEnv)
+		if err != nil {
+			telemetry.Event("cli.progress")
+			exit.Error(err)
+		}
+		telemetry.Event("cli.progress", map[string]interface{}{"provider": env.Provider.String(), "env_name": env.Name})
+
+		err = printEnvIfNotSpecified(_flagProgressEnv)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		if env.Provider != types.AWSProviderType {
+			exit.Error(ErrorNotSupportedInLocalEnvironment())
+		}
+
+		err = cluster.StreamProgress(MustGetOperatorConfig(env.Name), args[0])
+		if err != nil {
+			// note: if modifying this string, search the codebase for it and change all occurrences
+			if strings.HasSuffix(errors.Message(err), "is not deployed") {
+				fmt.Println(console.Bold(errors.Message(err)))
+				return
+			}
+			exit.Error(err)
+		}
+	},
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/cortexlabs/cortex/cli/cluster"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/print"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var _flagResumeEnv string
+
+func resumeInit() {
+	_resumeCmd.Flags().SortFlags = false
+	_resumeCmd.Flags().StringVarP(&_flagResumeEnv, "env", "e", getDefaultEnv(_generalCommandType), "environment to use")
+}
+
+var _resumeCmd = &cobra.Command{
+	Use:   "resume API_NAME",
+	Short: "scale a paused api back up",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		env, err := ReadOrConfigureEnv(_flagResumeEnv)
+		if err != nil {
+			telemetry.Event("cli.resume")
+			exit.Error(err)
+		}
+		telemetry.Event("cli.resume", map[string]interface{}{"provider": env.Provider.String(), "env_name": env.Name})
+
+		err = printEnvIfNotSpecified(_flagResumeEnv)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		if env.Provider != types.AWSProviderType {
+			exit.Error(ErrorNotSupportedInLocalEnvironment())
+		}
+
+		resumeResponse, err := cluster.Resume(MustGetOperatorConfig(env.Name), args[0])
+		if err != nil {
+			exit.Error(err)
+		}
+		print.BoldFirstLine(resumeResponse.Message)
+	},
+}
@@ -94,15 +94,24 @@ func init() {
 		initTelemetry()
 	}
 
+	backupInit()
 	clusterInit()
 	completionInit()
+	costsInit()
 	deleteInit()
 	deployInit()
 	envInit()
+	federationInit()
 	getInit()
+	jobInit()
+	loadTestInit()
 	logsInit()
+	pauseInit()
 	predictInit()
+	progressInit()
 	refreshInit()
+	restoreInit()
+	resumeInit()
 	versionInit()
 }
 
@@ -133,15 +142,24 @@ func Execute() {
 
 	_rootCmd.AddCommand(_deployCmd)
 	_rootCmd.AddCommand(_refreshCmd)
+	_rootCmd.AddCommand(_pauseCmd)
+	_rootCmd.AddCommand(_resumeCmd)
 	_rootCmd.AddCommand(_getCmd)
 	_rootCmd.AddCommand(_logsCmd)
+	_rootCmd.AddCommand(_progressCmd)
+	_rootCmd.AddCommand(_costsCmd)
 	_rootCmd.AddCommand(_predictCmd)
+	_rootCmd.AddCommand(_loadTestCmd)
+	_rootCmd.AddCommand(_jobCmd)
 	_rootCmd.AddCommand(_deleteCmd)
 
 	_rootCmd.AddCommand(_clusterCmd)
+	_rootCmd.AddCommand(_backupCmd)
+	_rootCmd.AddCommand(_restoreCmd)
 	_rootCmd.AddCommand(_versionCmd)
 
 	_rootCmd.AddCommand(_envCmd)
+	_rootCmd.AddCommand(_federationCmd)
 	_rootCmd.AddCommand(_completionCmd)
 
 	updateRootUsage()
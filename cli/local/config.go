@@ -22,23 +22,14 @@ import (
 
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/exit"
-	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/mitchellh/go-homedir"
 )
 
-var _cwd string
 var _localDir string
 var _localWorkspaceDir string
 var _modelCacheDir string
 
 func init() {
-	cwd, err := os.Getwd()
-	if err != nil {
-		err := errors.Wrap(err, "unable to determine current working directory")
-		exit.Error(err)
-	}
-	_cwd = s.EnsureSuffix(cwd, "/")
-
 	homeDir, err := homedir.Dir()
 	if err != nil {
 		err := errors.Wrap(err, "unable to determine home directory")
@@ -17,7 +17,9 @@ limitations under the License.
 package local
 
 import (
+	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/cortexlabs/cortex/cli/types/cliconfig"
 	"github.com/cortexlabs/cortex/pkg/lib/aws"
@@ -58,7 +60,7 @@ func Deploy(env cliconfig.Environment, absoluteConfigPath string, projectFileLis
 		}
 	}
 
-	apiConfigs, err := spec.ExtractAPIConfigs(configBytes, types.LocalProviderType, projectFiles, absoluteConfigPath)
+	apiConfigs, err := spec.ExtractAPIConfigs(configBytes, types.LocalProviderType, projectFiles, absoluteConfigPath, localEnvironment(env))
 	if err != nil {
 		return schema.DeployResponse{}, err
 	}
@@ -88,3 +90,19 @@ func Deploy(env cliconfig.Environment, absoluteConfigPath string, projectFileLis
 		Results: results,
 	}, nil
 }
+
+// localEnvironment selects the APIs' overrides for the CLI environment (e.g. `overrides.local`); since there is no
+// cluster configuration, variables are read from the CLI's environment variables
+func localEnvironment(env cliconfig.Environment) spec.Environment {
+	variables := map[string]string{}
+	for _, envVar := range os.Environ() {
+		if split := strings.SplitN(envVar, "=", 2); len(split) == 2 {
+			variables[split[0]] = split[1]
+		}
+	}
+
+	return spec.Environment{
+		Name:      env.Name,
+		Variables: variables,
+	}
+}
@@ -19,6 +19,7 @@ package local
 import (
 	"context"
 	"fmt"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -26,10 +27,11 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/aws"
 	"github.com/cortexlabs/cortex/pkg/lib/docker"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/maps"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/types"
 	"github.com/cortexlabs/cortex/pkg/types/spec"
 	"github.com/cortexlabs/cortex/pkg/types/userconfig"
-	"github.com/docker/docker/api/types"
 	dockertypes "github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
@@ -72,29 +74,62 @@ func DeployContainers(api *spec.API, awsClient *aws.Client) error {
 	}
 }
 
-func getAPIEnv(api *spec.API, awsClient *aws.Client) []string {
+// the environment of the API's containers, which mirrors the one the operator gives them in the cluster (see getEnvVars() in pkg/operator/operator/k8s_specs.go)
+func getAPIEnv(api *spec.API, containerName string, awsClient *aws.Client) []string {
 	envs := []string{}
 
-	for envName, envVal := range api.Predictor.Env {
-		envs = append(envs, fmt.Sprintf("%s=%s", envName, envVal))
+	for _, envName := range maps.StrMapSortedKeys(api.Predictor.Env) {
+		envs = append(envs, envName+"="+api.Predictor.Env[envName])
+	}
+
+	envs = append(envs, "CORTEX_PROVIDER="+types.LocalProviderType.String())
+
+	if containerName != _apiContainerName {
+		return envs
 	}
 
 	envs = append(envs,
 		"CORTEX_VERSION="+consts.CortexVersion,
-		"CORTEX_SERVING_PORT="+_defaultPortStr,
-		"CORTEX_PROVIDER="+"local",
-		"CORTEX_CACHE_DIR="+_cacheDir,
-		"CORTEX_MODEL_DIR="+_modelDir,
-		"CORTEX_MODELS="+strings.Join(api.ModelNames(), ","),
-		"CORTEX_API_SPEC="+filepath.Join("/mnt/workspace", filepath.Base(api.Key)),
-		"CORTEX_PROJECT_DIR="+_projectDir,
 		"CORTEX_WORKERS_PER_REPLICA=1",
 		"CORTEX_THREADS_PER_WORKER=1",
 		"CORTEX_MAX_WORKER_CONCURRENCY=1000",
 		"CORTEX_SO_MAX_CONN=1000",
+		"CORTEX_SERVING_PORT="+_defaultPortStr,
+		"CORTEX_API_SPEC="+filepath.Join(_workspaceDir, filepath.Base(api.Key)),
+		"CORTEX_CACHE_DIR="+_cacheDir,
+		"CORTEX_PROJECT_DIR="+_projectDir,
 		"AWS_REGION="+awsClient.Region,
 	)
 
+	if api.Predictor.PythonPath != nil {
+		envs = append(envs, "PYTHON_PATH="+path.Join(_projectDir, *api.Predictor.PythonPath))
+	}
+
+	if api.Predictor.Type == userconfig.ONNXPredictorType || api.Predictor.Type == userconfig.TensorFlowPredictorType {
+		envs = append(envs,
+			"CORTEX_MODEL_DIR="+_modelDir,
+			"CORTEX_MODELS="+strings.Join(api.ModelNames(), ","),
+		)
+	}
+
+	if onnxConfig := api.Predictor.ONNXRuntimeConfig; onnxConfig != nil && api.Predictor.Type == userconfig.ONNXPredictorType {
+		if len(onnxConfig.ExecutionProviders) > 0 {
+			envs = append(envs, "CORTEX_ONNX_EXECUTION_PROVIDERS="+strings.Join(onnxConfig.ExecutionProviders, ","))
+		}
+		envs = append(envs,
+			"CORTEX_ONNX_INTRA_OP_NUM_THREADS="+s.Int32(onnxConfig.IntraOpNumThreads),
+			"CORTEX_ONNX_INTER_OP_NUM_THREADS="+s.Int32(onnxConfig.InterOpNumThreads),
+			"CORTEX_ONNX_GRAPH_OPTIMIZATION_LEVEL="+onnxConfig.GraphOptimizationLevel.String(),
+		)
+	}
+
+	if api.Predictor.Type == userconfig.TensorFlowPredictorType {
+		envs = append(envs, "CORTEX_TF_BASE_SERVING_PORT="+_tfServingPortStr)
+		if api.Predictor.TensorFlowServingConfig != nil && api.Predictor.TensorFlowServingConfig.ModelConfig != nil {
+			envs = append(envs, "CORTEX_TF_MODEL_CONFIG="+*api.Predictor.TensorFlowServingConfig.ModelConfig)
+		}
+	}
+
 	if awsAccessKeyID := awsClient.AccessKeyID(); awsAccessKeyID != nil {
 		envs = append(envs, "AWS_ACCESS_KEY_ID="+*awsAccessKeyID)
 	}
@@ -135,6 +170,7 @@ func deployPythonContainer(api *spec.API, awsClient *aws.Client) error {
 		},
 		Runtime:   runtime,
 		Resources: resources,
+		ShmSize:   shmSize(api),
 		Mounts: []mount.Mount{
 			{
 				Type:   mount.TypeBind,
@@ -152,9 +188,7 @@ func deployPythonContainer(api *spec.API, awsClient *aws.Client) error {
 	containerConfig := &container.Config{
 		Image: api.Predictor.Image,
 		Tty:   true,
-		Env: append(
-			getAPIEnv(api, awsClient),
-		),
+		Env:   getAPIEnv(api, _apiContainerName, awsClient),
 		ExposedPorts: nat.PortSet{
 			_defaultPortStr + "/tcp": struct{}{},
 		},
@@ -224,15 +258,14 @@ func deployONNXContainer(api *spec.API, awsClient *aws.Client) error {
 		},
 		Runtime:   runtime,
 		Resources: resources,
+		ShmSize:   shmSize(api),
 		Mounts:    mounts,
 	}
 
 	containerConfig := &container.Config{
 		Image: api.Predictor.Image,
 		Tty:   true,
-		Env: append(
-			getAPIEnv(api, awsClient),
-		),
+		Env:   getAPIEnv(api, _apiContainerName, awsClient),
 		ExposedPorts: nat.PortSet{
 			_defaultPortStr + "/tcp": struct{}{},
 		},
@@ -293,13 +326,19 @@ func deployTensorFlowContainers(api *spec.API, awsClient *aws.Client) error {
 		Mounts:    mounts,
 	}
 
+	serveCmd := strslice.StrSlice{
+		"--port=" + _tfServingPortStr,
+		"--model_config_file=" + _tfServingEmptyModelConfig,
+	}
+	if api.Predictor.TensorFlowServingConfig != nil {
+		serveCmd = append(serveCmd, api.Predictor.TensorFlowServingConfig.FlagArgs()...)
+	}
+
 	serveContainerConfig := &container.Config{
 		Image: api.Predictor.TensorFlowServingImage,
 		Tty:   true,
-		Cmd: strslice.StrSlice{
-			"--port=" + _tfServingPortStr,
-			"--model_config_file=" + _tfServingEmptyModelConfig,
-		},
+		Cmd:   serveCmd,
+		Env:   getAPIEnv(api, _tfServingContainerName, awsClient),
 		ExposedPorts: nat.PortSet{
 			_tfServingPortStr + "/tcp": struct{}{},
 		},
@@ -338,10 +377,11 @@ func deployTensorFlowContainers(api *spec.API, awsClient *aws.Client) error {
 			_defaultPortStr + "/tcp": []nat.PortBinding{portBinding},
 		},
 		Resources: apiResources,
+		ShmSize:   shmSize(api),
 		Mounts: append([]mount.Mount{
 			{
 				Type:   mount.TypeBind,
-				Source: _cwd,
+				Source: api.LocalProjectDir,
 				Target: _projectDir,
 			},
 			{
@@ -352,14 +392,13 @@ func deployTensorFlowContainers(api *spec.API, awsClient *aws.Client) error {
 		}, mounts...),
 	}
 
+	// the serving container's address is only known once it has started
+	apiEnv := append(getAPIEnv(api, _apiContainerName, awsClient), "CORTEX_TF_SERVING_HOST="+tfContainerHost)
+
 	apiContainerConfig := &container.Config{
 		Image: api.Predictor.Image,
 		Tty:   true,
-		Env: append(
-			getAPIEnv(api, awsClient),
-			"CORTEX_TF_BASE_SERVING_PORT="+_tfServingPortStr,
-			"CORTEX_TF_SERVING_HOST="+tfContainerHost,
-		),
+		Env:   apiEnv,
 		ExposedPorts: nat.PortSet{
 			_defaultPortStr + "/tcp": struct{}{},
 		},
@@ -384,12 +423,20 @@ func deployTensorFlowContainers(api *spec.API, awsClient *aws.Client) error {
 	return nil
 }
 
+// the size of the API container's /dev/shm (0 uses docker's default)
+func shmSize(api *spec.API) int64 {
+	if api.Compute == nil || api.Compute.ShmSize == nil {
+		return 0
+	}
+	return api.Compute.ShmSize.Value()
+}
+
 func GetContainersByAPI(apiName string) ([]dockertypes.Container, error) {
 	dargs := filters.NewArgs()
 	dargs.Add("label", "cortex=true")
 	dargs.Add("label", "apiName="+apiName)
 
-	containers, err := docker.MustDockerClient().ContainerList(context.Background(), types.ContainerListOptions{
+	containers, err := docker.MustDockerClient().ContainerList(context.Background(), dockertypes.ContainerListOptions{
 		All:     true,
 		Filters: dargs,
 	})
@@ -404,7 +451,7 @@ func GetAllRunningContainers() ([]dockertypes.Container, error) {
 	dargs := filters.NewArgs()
 	dargs.Add("label", "cortex=true")
 
-	containers, err := docker.MustDockerClient().ContainerList(context.Background(), types.ContainerListOptions{
+	containers, err := docker.MustDockerClient().ContainerList(context.Background(), dockertypes.ContainerListOptions{
 		Filters: dargs,
 	})
 	if err != nil {
@@ -147,16 +147,29 @@ func ValidateLocalAPIs(apis []userconfig.API, projectFiles ProjectFiles, awsClie
 		return spec.ErrorDuplicateName(dups)
 	}
 
-	imageSet := strset.New()
+	// an image is pulled if any api that uses it requires a pull
+	imagePullPolicies := map[string]userconfig.ImagePullPolicyType{}
 	for _, api := range apis {
-		imageSet.Add(api.Predictor.Image)
+		images := []string{api.Predictor.Image}
 		if api.Predictor.Type == userconfig.TensorFlowPredictorType {
-			imageSet.Add(api.Predictor.TensorFlowServingImage)
+			images = append(images, api.Predictor.TensorFlowServingImage)
+		}
+		for _, image := range images {
+			if policy, ok := imagePullPolicies[image]; !ok || api.Predictor.ImagePullPolicy < policy {
+				imagePullPolicies[image] = api.Predictor.ImagePullPolicy
+			}
 		}
 	}
 
 	pulledImage := false
-	for image := range imageSet {
+	for image, policy := range imagePullPolicies {
+		if policy == userconfig.NeverImagePullPolicyType {
+			continue
+		}
+		if policy == userconfig.IfNotPresentImagePullPolicyType && docker.CheckLocalImageAccessible(docker.MustDockerClient(), image) == nil {
+			continue
+		}
+
 		var err error
 		dockerAuth := docker.NoAuth
 		if regex.IsValidECRURL(image) && !awsClient.IsAnonymous {
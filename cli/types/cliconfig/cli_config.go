@@ -26,6 +26,7 @@ type CLIConfig struct {
 	Telemetry          *bool          `json:"telemetry,omitempty" yaml:"telemetry,omitempty"`
 	DefaultEnvironment string         `json:"default_environment" yaml:"default_environment"`
 	Environments       []*Environment `json:"environments" yaml:"environments"`
+	Federations        []*Federation  `json:"federations,omitempty" yaml:"federations,omitempty"`
 }
 
 func (cliConfig *CLIConfig) Validate() error {
@@ -58,5 +59,60 @@ func (cliConfig *CLIConfig) Validate() error {
 		cliConfig.DefaultEnvironment = types.LocalProviderType.String()
 	}
 
+	federationNames := strset.New()
+
+	for _, federation := range cliConfig.Federations {
+		if federationNames.Has(federation.Name) {
+			return errors.Wrap(ErrorDuplicateFederationNames(federation.Name), FederationsKey)
+		}
+
+		federationNames.Add(federation.Name)
+
+		if err := cliConfig.validateFederation(federation); err != nil {
+			return errors.Wrap(err, FederationsKey, federation.Name)
+		}
+	}
+
+	return nil
+}
+
+func (cliConfig *CLIConfig) validateFederation(federation *Federation) error {
+	envNames := strset.New()
+
+	for _, envName := range federation.Environments {
+		if envNames.Has(envName) {
+			return errors.Wrap(ErrorDuplicateFederationEnvironments(envName), EnvironmentsKey)
+		}
+
+		envNames.Add(envName)
+
+		env := cliConfig.FindEnvironment(envName)
+		if env == nil {
+			return errors.Wrap(ErrorEnvironmentNotConfigured(envName), EnvironmentsKey)
+		}
+
+		if env.Provider != types.AWSProviderType {
+			return errors.Wrap(ErrorFederationEnvironmentNotAWS(envName), EnvironmentsKey)
+		}
+	}
+
+	return nil
+}
+
+func (cliConfig *CLIConfig) FindEnvironment(envName string) *Environment {
+	for _, env := range cliConfig.Environments {
+		if env.Name == envName {
+			return env
+		}
+	}
+	return nil
+}
+
+func (cliConfig *CLIConfig) FindFederation(federationName string) *Federation {
+	for _, federation := range cliConfig.Federations {
+		if federation.Name == federationName {
+			return federation
+		}
+	}
 	return nil
 }
@@ -18,6 +18,7 @@ package cliconfig
 
 const (
 	EnvironmentsKey       = "environments"
+	FederationsKey        = "federations"
 	DefaultEnvironmentKey = "default_environment"
 	NameKey               = "name"
 	ProviderKey           = "provider"
@@ -30,6 +30,11 @@ const (
 	ErrEnvironmentProviderNameConflict    = "cliconfig.environment_provider_name_conflict"
 	ErrDuplicateEnvironmentNames          = "cliconfig.duplicate_environment_names"
 	ErrOperatorEndpointInLocalEnvironment = "cliconfig.operator_endpoint_in_local_environment"
+	ErrFederationNotConfigured            = "cliconfig.federation_not_configured"
+	ErrDuplicateFederationNames           = "cliconfig.duplicate_federation_names"
+	ErrDuplicateFederationEnvironments    = "cliconfig.duplicate_federation_environments"
+	ErrFederationEnvironmentNotAWS        = "cliconfig.federation_environment_not_aws"
+	ErrEnvironmentInFederation            = "cliconfig.environment_in_federation"
 )
 
 func ErrorEnvironmentNotConfigured(envName string) error {
@@ -66,3 +71,38 @@ func ErrorOperatorEndpointInLocalEnvironment() error {
 		Message: fmt.Sprintf("operator_endpoint should not be specified (it's not used in the local environment)"),
 	})
 }
+
+func ErrorFederationNotConfigured(federationName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrFederationNotConfigured,
+		Message: fmt.Sprintf("%s federation is not configured", federationName),
+	})
+}
+
+func ErrorDuplicateFederationNames(federationName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDuplicateFederationNames,
+		Message: fmt.Sprintf("duplicate federation names (%s is defined more than once)", s.UserStr(federationName)),
+	})
+}
+
+func ErrorDuplicateFederationEnvironments(envName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDuplicateFederationEnvironments,
+		Message: fmt.Sprintf("duplicate environments (%s is listed more than once)", s.UserStr(envName)),
+	})
+}
+
+func ErrorFederationEnvironmentNotAWS(envName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrFederationEnvironmentNotAWS,
+		Message: fmt.Sprintf("%s environment cannot be part of a federation (only environments which use the %s provider can be federated)", envName, types.AWSProviderType.String()),
+	})
+}
+
+func ErrorEnvironmentInFederation(envName string, federationName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrEnvironmentInFederation,
+		Message: fmt.Sprintf("%s environment is part of the %s federation; delete the federation first (via `cortex federation delete %s`) or reconfigure it without this environment", envName, federationName, federationName),
+	})
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cliconfig
+
+import (
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/lib/table"
+)
+
+type Federation struct {
+	Name         string   `json:"name" yaml:"name"`
+	Environments []string `json:"environments" yaml:"environments"`
+}
+
+func (federation Federation) String() string {
+	var items table.KeyValuePairs
+
+	items.Add("name", federation.Name)
+	items.Add("environments", strings.Join(federation.Environments, ", "))
+
+	return items.String(&table.KeyValuePairOpts{
+		BoldFirstLine: pointer.Bool(true),
+	})
+}
@@ -0,0 +1,118 @@
+#!/bin/bash
+
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# installs a cortex cluster on gcp (a cluster configuration with `provider: gcp`) onto an existing GKE cluster which has
+# Workload Identity enabled (e.g. created with `gcloud container clusters create <cluster_name> --workload-pool=<project>.svc.id.goog`),
+# and configures a cli environment to connect to it; the google service account (gcp.service_account) must be able to
+# read and write the bucket, and to push to gcp.image_registry (if it's set)
+
+set -euo pipefail
+
+ROOT="$(cd "$(dirname "${BASH_SOURCE[0]}")"/.. >/dev/null && pwd)"
+
+config_file=${1:-"$ROOT/dev/config/cluster.yaml"}
+env_name=${2:-"gcp"}
+
+eval $(python3 $ROOT/manager/cluster_config_env.py "$config_file")
+
+if [ "${CORTEX_PROVIDER:-}" != "gcp" ]; then
+  echo "error: $config_file must contain \`provider: gcp\`"
+  exit 1
+fi
+
+export CORTEX_VERSION=master
+export CORTEX_IMAGE_OPERATOR=${CORTEX_IMAGE_OPERATOR:-"cortexlabs/operator:$CORTEX_VERSION"}
+
+gcloud container clusters get-credentials "$CORTEX_CLUSTER_NAME" --project="$CORTEX_GCP_PROJECT" --zone="$CORTEX_GCP_ZONE" >/dev/null
+
+echo "installing cortex onto $(kubectl config current-context)"
+
+# the node pools have the labels and taints of cortex's worker nodes and node groups
+existing_node_pools=$(gcloud container node-pools list --cluster="$CORTEX_CLUSTER_NAME" --project="$CORTEX_GCP_PROJECT" --zone="$CORTEX_GCP_ZONE" --format="value(name)")
+python3 $ROOT/manager/generate_gke.py "$config_file" | while read -r create_node_pool; do
+  node_pool_name=$(echo "$create_node_pool" | awk '{print $5}')
+  if ! echo "$existing_node_pools" | grep -qx "$node_pool_name"; then
+    echo "creating node pool $node_pool_name"
+    eval "$create_node_pool" >/dev/null
+  fi
+done
+
+# GKE's GPU nodes don't come with NVIDIA's drivers
+if python3 $ROOT/manager/generate_gke.py "$config_file" | grep -q -- "--accelerator="; then
+  kubectl apply -f https://raw.githubusercontent.com/GoogleCloudPlatform/container-engine-accelerators/master/nvidia-driver-installer/cos/daemonset-preloaded.yaml >/dev/null
+fi
+
+# allow the operator's and the APIs' kubernetes service accounts to act as the google service account (APIs which are
+# deployed to other namespaces must also be bound, as $CORTEX_GCP_PROJECT.svc.id.goog[<namespace>/cortex-workload])
+for kubernetes_service_account in operator cortex-workload; do
+  gcloud iam service-accounts add-iam-policy-binding "$CORTEX_GCP_SERVICE_ACCOUNT" \
+    --project="$CORTEX_GCP_PROJECT" \
+    --role=roles/iam.workloadIdentityUser \
+    --member="serviceAccount:$CORTEX_GCP_PROJECT.svc.id.goog[default/$kubernetes_service_account]" >/dev/null
+done
+
+# the cli signs its requests with these credentials (they aren't AWS credentials), so they're kept across reinstalls
+operator_access_key_id=$(kubectl -n=default get secret operator-credentials -o jsonpath='{.data.AWS_ACCESS_KEY_ID}' 2>/dev/null | base64 --decode || true)
+operator_secret_access_key=$(kubectl -n=default get secret operator-credentials -o jsonpath='{.data.AWS_SECRET_ACCESS_KEY}' 2>/dev/null | base64 --decode || true)
+if [ -z "$operator_access_key_id" ] || [ -z "$operator_secret_access_key" ]; then
+  operator_access_key_id=$(head -c 16 /dev/urandom | od -An -tx1 | tr -d ' \n')
+  operator_secret_access_key=$(head -c 32 /dev/urandom | od -An -tx1 | tr -d ' \n')
+fi
+
+kubectl -n=default create configmap 'cluster-config' \
+  --from-file='cluster.yaml'=$config_file \
+  -o yaml --dry-run=client | kubectl apply -f - >/dev/null
+
+kubectl -n=default create configmap 'env-vars' \
+  --from-literal='CORTEX_VERSION'=$CORTEX_VERSION \
+  --from-literal='CORTEX_REGION'=$CORTEX_REGION \
+  --from-literal='AWS_REGION'=$CORTEX_REGION \
+  --from-literal='CORTEX_BUCKET'=$CORTEX_BUCKET \
+  --from-literal='GOOGLE_CLOUD_PROJECT'=$CORTEX_GCP_PROJECT \
+  --from-literal='CORTEX_TELEMETRY_DISABLE'=true \
+  -o yaml --dry-run=client | kubectl apply -f - >/dev/null
+
+kubectl -n=default create secret generic 'operator-credentials' \
+  --from-literal='AWS_ACCESS_KEY_ID'=$operator_access_key_id \
+  --from-literal='AWS_SECRET_ACCESS_KEY'=$operator_secret_access_key \
+  -o yaml --dry-run=client | kubectl apply -f - >/dev/null
+
+if [ "${CORTEX_METADATA_STORE:-}" = "postgres" ]; then
+  if [ -z "${CORTEX_METADATA_POSTGRES_URL:-}" ]; then
+    echo "error: metadata_store is \"postgres\", but the CORTEX_METADATA_POSTGRES_URL environment variable is not set"
+    exit 1
+  fi
+  kubectl -n=default create secret generic 'metadata-postgres' \
+    --from-literal='CORTEX_METADATA_POSTGRES_URL'=$CORTEX_METADATA_POSTGRES_URL \
+    -o yaml --dry-run=client | kubectl apply -f - >/dev/null
+fi
+
+kubectl apply -f $ROOT/manager/manifests/cortex-apis.yaml >/dev/null
+kubectl apply -f $ROOT/manager/manifests/api-priority-classes.yaml >/dev/null
+envsubst < $ROOT/manager/manifests/operator-gke.yaml | kubectl apply -f - >/dev/null
+kubectl -n=default rollout restart deployment/operator >/dev/null
+kubectl -n=default rollout status deployment/operator --timeout=5m >/dev/null
+
+# the operator is reached through its service's load balancer
+operator_ip=$(kubectl -n=default get service operator -o jsonpath='{.status.loadBalancer.ingress[0].ip}')
+while [ -z "$operator_ip" ]; do
+  sleep 5
+  operator_ip=$(kubectl -n=default get service operator -o jsonpath='{.status.loadBalancer.ingress[0].ip}')
+done
+
+python3 $ROOT/manager/update_cli_config.py "$HOME/.cortex/cli.yaml" "$env_name" "http://$operator_ip:8888" "$operator_access_key_id" "$operator_secret_access_key"
+
+echo "cortex is ready! append \`--env $env_name\` to cortex commands"
@@ -0,0 +1,85 @@
+#!/bin/bash
+
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+# installs a lightweight cortex cluster (a cluster configuration with `lightweight: true`) onto the cluster of the current
+# kubectl context (e.g. kind or minikube), and configures a cli environment to connect to it
+
+set -euo pipefail
+
+ROOT="$(cd "$(dirname "${BASH_SOURCE[0]}")"/.. >/dev/null && pwd)"
+
+config_file=${1:-"$ROOT/dev/config/cluster.yaml"}
+env_name=${2:-"lightweight"}
+
+eval $(python3 $ROOT/manager/cluster_config_env.py "$config_file")
+
+if [ "${CORTEX_LIGHTWEIGHT:-}" != "True" ]; then
+  echo "error: $config_file must contain \`lightweight: true\`"
+  exit 1
+fi
+
+export CORTEX_VERSION=master
+export CORTEX_REGION=${CORTEX_REGION:-"us-east-1"}
+export CORTEX_IMAGE_OPERATOR=${CORTEX_IMAGE_OPERATOR:-"cortexlabs/operator:$CORTEX_VERSION"}
+export CORTEX_AWS_ACCESS_KEY_ID=${CORTEX_AWS_ACCESS_KEY_ID:-${AWS_ACCESS_KEY_ID:-}}
+export CORTEX_AWS_SECRET_ACCESS_KEY=${CORTEX_AWS_SECRET_ACCESS_KEY:-${AWS_SECRET_ACCESS_KEY:-}}
+
+if [ -z "${CORTEX_S3_ENDPOINT:-}" ] || [ -z "${CORTEX_BUCKET:-}" ]; then
+  echo "error: $config_file must specify s3_endpoint and bucket (e.g. \`s3_endpoint: http://minio.default:9000\`)"
+  exit 1
+fi
+
+if [ -z "$CORTEX_AWS_ACCESS_KEY_ID" ] || [ -z "$CORTEX_AWS_SECRET_ACCESS_KEY" ]; then
+  echo "error: the object store's credentials must be specified as aws_access_key_id and aws_secret_access_key in $config_file (or as the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables)"
+  exit 1
+fi
+
+echo "installing cortex onto $(kubectl config current-context)"
+
+kubectl -n=default create configmap 'cluster-config' \
+  --from-file='cluster.yaml'=$config_file \
+  -o yaml --dry-run=client | kubectl apply -f - >/dev/null
+
+kubectl -n=default create configmap 'env-vars' \
+  --from-literal='CORTEX_VERSION'=$CORTEX_VERSION \
+  --from-literal='CORTEX_REGION'=$CORTEX_REGION \
+  --from-literal='AWS_REGION'=$CORTEX_REGION \
+  --from-literal='CORTEX_BUCKET'=$CORTEX_BUCKET \
+  --from-literal='CORTEX_S3_ENDPOINT'=$CORTEX_S3_ENDPOINT \
+  --from-literal='CORTEX_TELEMETRY_DISABLE'=true \
+  -o yaml --dry-run=client | kubectl apply -f - >/dev/null
+
+kubectl -n=default create secret generic 'aws-credentials' \
+  --from-literal='AWS_ACCESS_KEY_ID'=$CORTEX_AWS_ACCESS_KEY_ID \
+  --from-literal='AWS_SECRET_ACCESS_KEY'=$CORTEX_AWS_SECRET_ACCESS_KEY \
+  -o yaml --dry-run=client | kubectl apply -f - >/dev/null
+
+# the bucket is served by minio unless s3_endpoint points elsewhere
+if [[ "$CORTEX_S3_ENDPOINT" == *"minio.default"* ]]; then
+  envsubst '$CORTEX_BUCKET' < $ROOT/manager/manifests/minio.yaml | kubectl apply -f - >/dev/null
+  kubectl -n=default rollout status deployment/minio --timeout=5m >/dev/null
+fi
+
+kubectl apply -f $ROOT/manager/manifests/cortex-apis.yaml >/dev/null
+kubectl apply -f $ROOT/manager/manifests/api-priority-classes.yaml >/dev/null
+envsubst < $ROOT/manager/manifests/operator-lightweight.yaml | kubectl apply -f - >/dev/null
+kubectl -n=default rollout restart deployment/operator >/dev/null
+kubectl -n=default rollout status deployment/operator --timeout=5m >/dev/null
+
+python3 $ROOT/manager/update_cli_config.py "$HOME/.cortex/cli.yaml" "$env_name" "http://localhost:8888" "$CORTEX_AWS_ACCESS_KEY_ID" "$CORTEX_AWS_SECRET_ACCESS_KEY"
+
+echo "cortex is ready! run \`kubectl -n=default port-forward service/operator 8888\` to connect to the operator, and append \`--env $env_name\` to cortex commands"
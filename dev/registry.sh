@@ -81,14 +81,20 @@ function create_registry() {
   aws ecr create-repository --repository-name=cortexlabs/metrics-server --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/inferentia --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/neuron-rtd --region=$REGISTRY_REGION || true
+  aws ecr create-repository --repository-name=cortexlabs/neuron-monitor --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/nvidia --region=$REGISTRY_REGION || true
+  aws ecr create-repository --repository-name=cortexlabs/dcgm-exporter --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/fluentd --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/statsd --region=$REGISTRY_REGION || true
+  aws ecr create-repository --repository-name=cortexlabs/pause --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/istio-proxy --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/istio-pilot --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/istio-citadel --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/istio-galley --region=$REGISTRY_REGION || true
   aws ecr create-repository --repository-name=cortexlabs/request-monitor --region=$REGISTRY_REGION || true
+  aws ecr create-repository --repository-name=cortexlabs/model-optimizer --region=$REGISTRY_REGION || true
+  aws ecr create-repository --repository-name=cortexlabs/load-tester --region=$REGISTRY_REGION || true
+  aws ecr create-repository --repository-name=cortexlabs/kaniko --region=$REGISTRY_REGION || true
 }
 
 ### HELPERS ###
@@ -205,13 +211,19 @@ elif [ "$cmd" = "update" ]; then
     build_and_push $ROOT/images/metrics-server metrics-server latest
     build_and_push $ROOT/images/inferentia inferentia latest
     build_and_push $ROOT/images/neuron-rtd neuron-rtd latest
+    build_and_push $ROOT/images/neuron-monitor neuron-monitor latest
     build_and_push $ROOT/images/nvidia nvidia latest
+    build_and_push $ROOT/images/dcgm-exporter dcgm-exporter latest
     build_and_push $ROOT/images/fluentd fluentd latest
     build_and_push $ROOT/images/statsd statsd latest
+    build_and_push $ROOT/images/pause pause latest
     build_and_push $ROOT/images/istio-proxy istio-proxy latest
     build_and_push $ROOT/images/istio-pilot istio-pilot latest
     build_and_push $ROOT/images/istio-citadel istio-citadel latest
     build_and_push $ROOT/images/istio-galley istio-galley latest
+    build_and_push $ROOT/images/model-optimizer model-optimizer latest
+    build_and_push $ROOT/images/load-tester load-tester latest
+    build_and_push $ROOT/images/kaniko kaniko latest
   fi
 
   if [[ "$sub_cmd" == "all" || "$sub_cmd" == "dev" ]]; then
@@ -0,0 +1,3 @@
+module github.com/cortexlabs/cortex/images/load-tester
+
+go 1.14
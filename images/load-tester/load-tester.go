@@ -0,0 +1,198 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// the ramp stops once more than this fraction of a step's requests fail, since the API is overloaded
+const _maxErrorRate = 0.5
+
+// the load test's configuration, which is passed in by the operator (see load_testing.go)
+type config struct {
+	URL             string  `json:"url"`
+	Payload         []byte  `json:"payload"`
+	ContentType     string  `json:"content_type"`
+	MinConcurrency  int     `json:"min_concurrency"`
+	MaxConcurrency  int     `json:"max_concurrency"`
+	ConcurrencyStep int     `json:"concurrency_step"`
+	StepDuration    float64 `json:"step_duration"`   // seconds
+	RequestTimeout  float64 `json:"request_timeout"` // seconds
+}
+
+type stepResult struct {
+	Concurrency int     `json:"concurrency"`
+	Start       int64   `json:"start"` // unix time
+	End         int64   `json:"end"`
+	Requests    int64   `json:"requests"`
+	Errors      int64   `json:"errors"`
+	QPS         float64 `json:"qps"` // successful responses per second
+	P50         float64 `json:"p50"` // milliseconds (of successful requests)
+	P90         float64 `json:"p90"`
+	P99         float64 `json:"p99"`
+}
+
+type result struct {
+	Steps []stepResult `json:"steps"`
+}
+
+func main() {
+	encodedConfig := flag.String("config", "", "base64-encoded load test configuration")
+	flag.Parse()
+
+	cfg, err := decodeConfig(*encodedConfig)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	client := &http.Client{
+		Timeout: time.Duration(cfg.RequestTimeout * float64(time.Second)),
+		Transport: &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			MaxIdleConnsPerHost: cfg.MaxConcurrency,
+		},
+	}
+
+	var res result
+	for concurrency := cfg.MinConcurrency; concurrency <= cfg.MaxConcurrency; concurrency += cfg.ConcurrencyStep {
+		step := runStep(client, cfg, concurrency)
+		res.Steps = append(res.Steps, step)
+
+		fmt.Printf("concurrency %d: %d requests, %d errors, %.1f qps, p50 %.0fms, p90 %.0fms, p99 %.0fms\n",
+			step.Concurrency, step.Requests, step.Errors, step.QPS, step.P50, step.P90, step.P99)
+
+		if float64(step.Errors) > _maxErrorRate*float64(step.Requests) {
+			fmt.Println("stopping the load test because most requests failed")
+			break
+		}
+	}
+
+	// the operator reads the results from the last line of the logs
+	resultBytes, err := json.Marshal(res)
+	if err != nil {
+		exitWithError(err)
+	}
+	fmt.Println(string(resultBytes))
+}
+
+func decodeConfig(encodedConfig string) (config, error) {
+	var cfg config
+
+	configBytes, err := base64.URLEncoding.DecodeString(encodedConfig)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(configBytes, &cfg); err != nil {
+		return cfg, err
+	}
+	if cfg.MinConcurrency < 1 || cfg.ConcurrencyStep < 1 || cfg.MaxConcurrency < cfg.MinConcurrency {
+		return cfg, fmt.Errorf("invalid concurrency ramp (%d to %d in steps of %d)", cfg.MinConcurrency, cfg.MaxConcurrency, cfg.ConcurrencyStep)
+	}
+
+	return cfg, nil
+}
+
+// runStep sends requests from the given number of workers (each of which sends its next request as soon as it
+// receives a response) for the step's duration
+func runStep(client *http.Client, cfg config, concurrency int) stepResult {
+	start := time.Now()
+	deadline := start.Add(time.Duration(cfg.StepDuration * float64(time.Second)))
+
+	var mutex sync.Mutex
+	var latencies []float64
+	var requests, errors int64
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for time.Now().Before(deadline) {
+				requestStart := time.Now()
+				ok := sendRequest(client, cfg)
+				latency := float64(time.Since(requestStart)) / float64(time.Millisecond)
+
+				mutex.Lock()
+				requests++
+				if ok {
+					latencies = append(latencies, latency)
+				} else {
+					errors++
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	end := time.Now()
+	sort.Float64s(latencies)
+
+	return stepResult{
+		Concurrency: concurrency,
+		Start:       start.Unix(),
+		End:         end.Unix(),
+		Requests:    requests,
+		Errors:      errors,
+		QPS:         float64(len(latencies)) / end.Sub(start).Seconds(),
+		P50:         percentile(latencies, 50),
+		P90:         percentile(latencies, 90),
+		P99:         percentile(latencies, 99),
+	}
+}
+
+func sendRequest(client *http.Client, cfg config) bool {
+	response, err := client.Post(cfg.URL, cfg.ContentType, bytes.NewReader(cfg.Payload))
+	if err != nil {
+		return false
+	}
+	defer response.Body.Close()
+
+	// read the response so that the connection is reused
+	io.Copy(ioutil.Discard, response.Body)
+	return response.StatusCode >= 200 && response.StatusCode < 300
+}
+
+// percentile uses the nearest-rank method (sorted must be sorted in ascending order)
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	return sorted[index]
+}
+
+func exitWithError(err error) {
+	fmt.Println("error: " + err.Error())
+	os.Exit(1)
+}
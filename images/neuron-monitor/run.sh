@@ -0,0 +1,20 @@
+#!/bin/bash
+
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+set -eo pipefail
+
+# neuron-monitor connects to the pod's neuron runtime at $NEURON_RTD_ADDRESS, and its json output is served in the prometheus format
+neuron-monitor -c /etc/neuron-monitor/monitor.json | neuron-monitor-prometheus.py --port "${NEURON_MONITOR_PORT:-9401}"
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"sync"
 	"time"
 
@@ -29,10 +30,11 @@ import (
 )
 
 const _tickOffset = 1 * time.Second
-const _tickInterval = 10 * time.Second
 const _requestSampleInterval = 1 * time.Second
 
 var (
+	_tickInterval = 10 * time.Second // the metric's flush interval (the period which the operator queries it at)
+
 	client      *cloudwatch.CloudWatch
 	apiName     string
 	region      string
@@ -59,12 +61,20 @@ func (c *Counter) GetAllAndDelete() []int {
 	return output
 }
 
-// ./request-monitor api_name cluster_name
+// ./request-monitor api_name cluster_name [flush_interval_seconds]
 func main() {
 	apiName = os.Args[1]
 	clusterName = os.Args[2]
 	region = os.Getenv("CORTEX_REGION")
 
+	if len(os.Args) > 3 {
+		flushIntervalSeconds, err := strconv.Atoi(os.Args[3])
+		if err != nil || flushIntervalSeconds <= 0 {
+			panic(fmt.Sprintf("invalid flush interval: %s", os.Args[3]))
+		}
+		_tickInterval = time.Duration(flushIntervalSeconds) * time.Second
+	}
+
 	sess, err := session.NewSession(&aws.Config{
 		Credentials: nil,
 		Region:      aws.String(region),
@@ -22,6 +22,11 @@ def export(base_key, value):
         print(f"export CORTEX_TAGS={inlined_tags}")
         return
 
+    if base_key.lower() == "cortex_node_groups":
+        instance_types = " ".join([node_group["instance_type"] for node_group in value or []])
+        print(f'export CORTEX_NODE_GROUP_INSTANCE_TYPES="{instance_types}"')
+        return
+
     if value is None:
         return
     elif type(value) is list:
@@ -0,0 +1,89 @@
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import os
+import sys
+
+import boto3
+import yaml
+
+from create_gateway_integration import get_istio_api_gateway_elb_arn
+
+CLUSTER_NAME_TAG = "cortex.dev/cluster-name"
+
+
+def get_vpc_endpoint_service(client_ec2, cluster_name):
+    response = client_ec2.describe_vpc_endpoint_service_configurations(
+        Filters=[{"Name": f"tag:{CLUSTER_NAME_TAG}", "Values": [cluster_name]}]
+    )
+    service_configurations = response["ServiceConfigurations"]
+    if len(service_configurations) == 0:
+        return None
+    return service_configurations[0]
+
+
+def update_allowed_principals(client_ec2, service_id, allowed_principals):
+    response = client_ec2.describe_vpc_endpoint_service_permissions(ServiceId=service_id)
+    current_principals = {p["Principal"] for p in response["AllowedPrincipals"]}
+    desired_principals = set(allowed_principals)
+
+    principals_to_add = sorted(desired_principals - current_principals)
+    principals_to_remove = sorted(current_principals - desired_principals)
+    if len(principals_to_add) == 0 and len(principals_to_remove) == 0:
+        return
+
+    client_ec2.modify_vpc_endpoint_service_permissions(
+        ServiceId=service_id,
+        AddAllowedPrincipals=principals_to_add,
+        RemoveAllowedPrincipals=principals_to_remove,
+    )
+
+
+# creates, updates, or deletes the api load balancer's vpc endpoint service to match the cluster config
+# (and prints the service's name if it exists)
+def configure_vpc_endpoint_service(cluster_config):
+    client_ec2 = boto3.client("ec2", region_name=os.environ["CORTEX_REGION"])
+    client_elb = boto3.client("elbv2", region_name=os.environ["CORTEX_REGION"])
+
+    private_link = cluster_config.get("api_load_balancer_private_link")
+    service = get_vpc_endpoint_service(client_ec2, cluster_config["cluster_name"])
+
+    if private_link is None:
+        if service is not None:
+            client_ec2.delete_vpc_endpoint_service_configurations(ServiceIds=[service["ServiceId"]])
+        return
+
+    if service is None:
+        tags = [{"Key": key, "Value": value} for key, value in cluster_config["tags"].items()]
+        service = client_ec2.create_vpc_endpoint_service_configuration(
+            AcceptanceRequired=private_link["acceptance_required"],
+            NetworkLoadBalancerArns=[get_istio_api_gateway_elb_arn(client_elb)],
+            TagSpecifications=[{"ResourceType": "vpc-endpoint-service", "Tags": tags}],
+        )["ServiceConfiguration"]
+    elif service["AcceptanceRequired"] != private_link["acceptance_required"]:
+        client_ec2.modify_vpc_endpoint_service_configuration(
+            ServiceId=service["ServiceId"], AcceptanceRequired=private_link["acceptance_required"]
+        )
+
+    update_allowed_principals(
+        client_ec2, service["ServiceId"], private_link.get("allowed_principals") or []
+    )
+
+    print(service["ServiceName"])
+
+
+if __name__ == "__main__":
+    with open(sys.argv[1], "r") as f:
+        cluster_config = yaml.safe_load(f)
+    configure_vpc_endpoint_service(cluster_config)
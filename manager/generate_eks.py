@@ -91,11 +91,39 @@ def apply_spot_settings(nodegroup, config):
             "spotInstancePools": config["spot_config"]["instance_pools"],
         },
         "labels": {"lifecycle": "Ec2Spot"},
+        "tags": {"k8s.io/cluster-autoscaler/node-template/label/lifecycle": "Ec2Spot"},
     }
 
     return merge_override(nodegroup, spot_settings)
 
 
+def apply_node_group_settings(nodegroup, node_group):
+    name = node_group["name"]
+    node_group_settings = {
+        "name": "ng-cortex-group-" + name,
+        "labels": {"cortex.dev/node-group": name},
+        "taints": {"cortex.dev/node-group": f"{name}:NoSchedule"},
+        "tags": {
+            "k8s.io/cluster-autoscaler/node-template/label/cortex.dev/node-group": name,
+            "k8s.io/cluster-autoscaler/node-template/taint/cortex.dev/node-group": f"{name}:NoSchedule",
+        },
+    }
+
+    if node_group.get("spot", False):
+        node_group_settings["instanceType"] = "mixed"
+        node_group_settings["instancesDistribution"] = {
+            "instanceTypes": [node_group["instance_type"]],
+            "onDemandBaseCapacity": 0,
+            "onDemandPercentageAboveBaseCapacity": 0,
+        }
+        node_group_settings["labels"]["lifecycle"] = "Ec2Spot"
+        node_group_settings["tags"][
+            "k8s.io/cluster-autoscaler/node-template/label/lifecycle"
+        ] = "Ec2Spot"
+
+    return merge_override(nodegroup, node_group_settings)
+
+
 def apply_gpu_settings(nodegroup):
     gpu_settings = {
         "tags": {
@@ -160,7 +188,18 @@ def get_ami_image(region):
     raise RuntimeError(f"ami image is in region {region} instead of 'us-east-1' or 'us-west-2'")
 
 
-def generate_eks(cluster_config_path):
+# node groups which are created by a cluster upgrade are suffixed with their generation, so that
+# they can be created alongside the node groups which they replace (see upgrade.sh)
+def apply_generation(nodegroup, generation):
+    generation_settings = {
+        "name": nodegroup["name"] + "-" + generation,
+        "labels": {"cortex.dev/node-generation": generation},
+    }
+
+    return merge_override(nodegroup, generation_settings)
+
+
+def generate_eks(cluster_config_path, generation=None):
     with open(cluster_config_path, "r") as f:
         cluster_config = yaml.safe_load(f)
 
@@ -225,6 +264,29 @@ def generate_eks(cluster_config_path):
 
         eks["nodeGroups"].append(backup_nodegroup)
 
+    for node_group in cluster_config.get("node_groups") or []:
+        node_group_config = dict(
+            cluster_config,
+            instance_type=node_group["instance_type"],
+            min_instances=node_group["min_instances"],
+            max_instances=node_group["max_instances"],
+        )
+
+        group_nodegroup = default_nodegroup(cluster_config)
+        apply_worker_settings(group_nodegroup)
+        apply_clusterconfig(group_nodegroup, node_group_config)
+        if is_gpu(node_group["instance_type"]):
+            apply_gpu_settings(group_nodegroup)
+        if is_inf(node_group["instance_type"]):
+            apply_inf_settings(group_nodegroup, node_group_config)
+        apply_node_group_settings(group_nodegroup, node_group)
+
+        eks["nodeGroups"].append(group_nodegroup)
+
+    if generation is not None:
+        for nodegroup in eks["nodeGroups"]:
+            apply_generation(nodegroup, generation)
+
     print(yaml.dump(eks, Dumper=IgnoreAliases, default_flow_style=False, default_style=""))
 
 
@@ -239,4 +301,6 @@ class IgnoreAliases(yaml.Dumper):
 
 
 if __name__ == "__main__":
-    generate_eks(cluster_config_path=sys.argv[1])
+    generate_eks(
+        cluster_config_path=sys.argv[1], generation=sys.argv[2] if len(sys.argv) > 2 else None
+    )
@@ -0,0 +1,77 @@
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import shlex
+import sys
+import yaml
+
+
+# prints the gcloud commands which create the GKE node pools of a cluster on gcp (see dev/gke.sh):
+# the default worker node pool, and one node pool per node group; the node pools have the same
+# labels and taints as the corresponding eksctl node groups (see generate_eks.py), and GPU node
+# pools are tainted by GKE with nvidia.com/gpu (which APIs that request GPUs tolerate)
+def node_pool_args(cluster_config, machine_type, accelerator, min_instances, max_instances):
+    return [
+        "--cluster=" + cluster_config["cluster_name"],
+        "--project=" + cluster_config["gcp"]["project"],
+        "--zone=" + cluster_config["gcp"]["zone"],
+        "--machine-type=" + machine_type,
+        "--disk-size=" + str(cluster_config.get("instance_volume_size", 50)),
+        "--enable-autoscaling",
+        "--num-nodes=" + str(1 if min_instances == 0 else min_instances),
+        "--min-nodes=" + str(min_instances),
+        "--max-nodes=" + str(max_instances),
+        # pods authenticate as their kubernetes service account's google service account
+        "--workload-metadata=GKE_METADATA",
+    ] + (["--accelerator=" + accelerator] if accelerator else [])
+
+
+def generate_gke(cluster_config_path):
+    with open(cluster_config_path, "r") as f:
+        cluster_config = yaml.safe_load(f)
+
+    worker_args = node_pool_args(
+        cluster_config,
+        cluster_config["gcp"]["machine_type"],
+        cluster_config["gcp"].get("accelerator"),
+        cluster_config.get("min_instances") or 1,
+        cluster_config.get("max_instances") or 5,
+    )
+    worker_args += ["--node-labels=workload=true", "--node-taints=workload=true:NoSchedule"]
+    node_pools = [("cortex-worker", worker_args)]
+
+    for node_group in cluster_config.get("node_groups") or []:
+        name = node_group["name"]
+        group_args = node_pool_args(
+            cluster_config,
+            node_group["gcp_machine_type"],
+            node_group.get("gcp_accelerator"),
+            node_group.get("min_instances", 0),
+            node_group["max_instances"],
+        )
+        group_args += [
+            f"--node-labels=workload=true,cortex.dev/node-group={name}",
+            f"--node-taints=workload=true:NoSchedule,cortex.dev/node-group={name}:NoSchedule",
+        ]
+        if node_group.get("spot", False):
+            group_args.append("--preemptible")
+        node_pools.append(("cortex-group-" + name, group_args))
+
+    for name, args in node_pools:
+        command = ["gcloud", "container", "node-pools", "create", name] + args
+        print(" ".join(shlex.quote(arg) for arg in command))
+
+
+if __name__ == "__main__":
+    generate_gke(cluster_config_path=sys.argv[1])
@@ -78,15 +78,15 @@ function ensure_eks() {
     exit 1
   fi
 
-  # Check for change in min/max instances
-  asg_on_demand_info=$(aws autoscaling describe-auto-scaling-groups --region $CORTEX_REGION --query "AutoScalingGroups[?contains(Tags[?Key==\`alpha.eksctl.io/cluster-name\`].Value, \`$CORTEX_CLUSTER_NAME\`)]|[?contains(Tags[?Key==\`alpha.eksctl.io/nodegroup-name\`].Value, \`ng-cortex-worker-on-demand\`)]")
+  # Check for change in min/max instances (node groups which were created by an upgrade are suffixed with their generation)
+  asg_on_demand_info=$(aws autoscaling describe-auto-scaling-groups --region $CORTEX_REGION --query "AutoScalingGroups[?contains(Tags[?Key==\`alpha.eksctl.io/cluster-name\`].Value, \`$CORTEX_CLUSTER_NAME\`)]|[?Tags[?Key==\`alpha.eksctl.io/nodegroup-name\` && starts_with(Value, \`ng-cortex-worker-on-demand\`)]]")
   asg_on_demand_length=$(echo "$asg_on_demand_info" | jq -r 'length')
   asg_on_demand_name=""
   if (( "$asg_on_demand_length" > "0" )); then
     asg_on_demand_name=$(echo "$asg_on_demand_info" | jq -r 'first | .AutoScalingGroupName')
   fi
 
-  asg_spot_info=$(aws autoscaling describe-auto-scaling-groups --region $CORTEX_REGION --query "AutoScalingGroups[?contains(Tags[?Key==\`alpha.eksctl.io/cluster-name\`].Value, \`$CORTEX_CLUSTER_NAME\`)]|[?contains(Tags[?Key==\`alpha.eksctl.io/nodegroup-name\`].Value, \`ng-cortex-worker-spot\`)]")
+  asg_spot_info=$(aws autoscaling describe-auto-scaling-groups --region $CORTEX_REGION --query "AutoScalingGroups[?contains(Tags[?Key==\`alpha.eksctl.io/cluster-name\`].Value, \`$CORTEX_CLUSTER_NAME\`)]|[?Tags[?Key==\`alpha.eksctl.io/nodegroup-name\` && starts_with(Value, \`ng-cortex-worker-spot\`)]]")
   asg_spot_length=$(echo "$asg_spot_info" | jq -r 'length')
   asg_spot_name=""
   if (( "$asg_spot_length" > "0" )); then
@@ -235,6 +235,9 @@ function main() {
   echo -n "￮ configuring autoscaling "
   python render_template.py $CORTEX_CLUSTER_CONFIG_FILE manifests/cluster-autoscaler.yaml.j2 > $CORTEX_CLUSTER_WORKSPACE/cluster-autoscaler.yaml
   kubectl apply -f $CORTEX_CLUSTER_WORKSPACE/cluster-autoscaler.yaml >/dev/null
+  python render_template.py $CORTEX_CLUSTER_CONFIG_FILE manifests/overprovisioning.yaml.j2 > $CORTEX_CLUSTER_WORKSPACE/overprovisioning.yaml
+  kubectl apply -f $CORTEX_CLUSTER_WORKSPACE/overprovisioning.yaml >/dev/null
+  kubectl apply -f manifests/api-priority-classes.yaml >/dev/null
   echo "✓"
 
   echo -n "￮ configuring logging "
@@ -243,16 +246,27 @@ function main() {
 
   echo -n "￮ configuring metrics "
   envsubst < manifests/metrics-server.yaml | kubectl apply -f - >/dev/null
-  envsubst < manifests/statsd.yaml | kubectl apply -f - >/dev/null
+  if [[ "$CORTEX_METRIC_SINKS" == *cloudwatch* ]]; then
+    envsubst < manifests/statsd.yaml | kubectl apply -f - >/dev/null
+  fi
   echo "✓"
 
-  if [[ "$CORTEX_INSTANCE_TYPE" == p* ]] || [[ "$CORTEX_INSTANCE_TYPE" == g* ]]; then
+  python render_template.py $CORTEX_CLUSTER_CONFIG_FILE manifests/model-seeder.yaml.j2 > $CORTEX_CLUSTER_WORKSPACE/model-seeder.yaml
+  if grep -q "kind:" $CORTEX_CLUSTER_WORKSPACE/model-seeder.yaml; then
+    echo -n "￮ configuring p2p model distribution "
+    kubectl apply -f $CORTEX_CLUSTER_WORKSPACE/model-seeder.yaml >/dev/null
+    echo "✓"
+  else
+    kubectl -n=default delete --ignore-not-found=true daemonset/model-seeder service/model-seeder >/dev/null
+  fi
+
+  if has_instance_family p g; then
     echo -n "￮ configuring gpu support "
     envsubst < manifests/nvidia.yaml | kubectl apply -f - >/dev/null
     echo "✓"
   fi
 
-  if [[ "$CORTEX_INSTANCE_TYPE" == inf* ]]; then
+  if has_instance_family inf; then
     echo -n "￮ configuring inf support "
     envsubst < manifests/inferentia.yaml | kubectl apply -f - >/dev/null
     echo "✓"
@@ -269,6 +283,7 @@ function main() {
   fi
 
   echo -n "￮ starting operator "
+  kubectl apply -f manifests/cortex-apis.yaml >/dev/null
   kubectl -n=default delete --ignore-not-found=true --grace-period=10 deployment operator >/dev/null 2>&1
   printed_dot="false"
   until [ "$(kubectl -n=default get pods -l workloadID=operator -o json | jq -j '.items | length')" -eq "0" ]; do echo -n "."; printed_dot="true"; sleep 2; done
@@ -292,6 +307,16 @@ function main() {
     if [ "$printed_dot" == "true" ]; then echo " ✓"; else echo "✓"; fi
   fi
 
+  # the api load balancer must exist before its vpc endpoint service can be created (the script also deletes the service
+  # if api_load_balancer_private_link has been removed from the cluster configuration)
+  vpc_endpoint_service_name=""
+  if [ "$CORTEX_NETWORKING_BACKEND" == "istio" ]; then
+    vpc_endpoint_service_name=$(python configure_vpc_endpoint_service.py $CORTEX_CLUSTER_CONFIG_FILE)
+  fi
+  if [ "$vpc_endpoint_service_name" != "" ]; then
+    echo "￮ api load balancer vpc endpoint service: $vpc_endpoint_service_name ✓"
+  fi
+
   echo -n "￮ configuring cli "
   python update_cli_config.py "/.cortex/cli.yaml" "$CORTEX_ENV_NAME" "$operator_endpoint" "$CORTEX_AWS_ACCESS_KEY_ID" "$CORTEX_AWS_SECRET_ACCESS_KEY"
   echo "✓"
@@ -303,6 +328,18 @@ function main() {
   fi
 }
 
+# returns success if the cluster's instance type or any node group's instance type belongs to one of the given instance families
+function has_instance_family() {
+  for instance_type in $CORTEX_INSTANCE_TYPE $CORTEX_NODE_GROUP_INSTANCE_TYPES; do
+    for family in "$@"; do
+      if [[ "$instance_type" == $family* ]]; then
+        return 0
+      fi
+    done
+  done
+  return 1
+}
+
 function setup_configmap() {
   kubectl -n=default create configmap 'cluster-config' \
     --from-file='cluster.yaml'=$CORTEX_CLUSTER_CONFIG_FILE \
@@ -313,6 +350,7 @@ function setup_configmap() {
     --from-literal='CORTEX_REGION'=$CORTEX_REGION \
     --from-literal='AWS_REGION'=$CORTEX_REGION \
     --from-literal='CORTEX_BUCKET'=$CORTEX_BUCKET \
+    --from-literal='CORTEX_S3_ENDPOINT'=$CORTEX_S3_ENDPOINT \
     --from-literal='CORTEX_TELEMETRY_DISABLE'=$CORTEX_TELEMETRY_DISABLE \
     --from-literal='CORTEX_TELEMETRY_SENTRY_DSN'=$CORTEX_TELEMETRY_SENTRY_DSN \
     --from-literal='CORTEX_TELEMETRY_SEGMENT_WRITE_KEY'=$CORTEX_TELEMETRY_SEGMENT_WRITE_KEY \
@@ -325,6 +363,17 @@ function setup_secrets() {
     --from-literal='AWS_ACCESS_KEY_ID'=$CORTEX_AWS_ACCESS_KEY_ID \
     --from-literal='AWS_SECRET_ACCESS_KEY'=$CORTEX_AWS_SECRET_ACCESS_KEY \
     -o yaml --dry-run=client | kubectl apply -f - >/dev/null
+
+  # the postgres metadata store's connection URL typically contains a password, so it's kept in a secret rather than the cluster config
+  if [ "${CORTEX_METADATA_STORE:-}" = "postgres" ]; then
+    if [ -z "${CORTEX_METADATA_POSTGRES_URL:-}" ]; then
+      echo -e "\nerror: metadata_store is \"postgres\", but the CORTEX_METADATA_POSTGRES_URL environment variable is not set"
+      exit 1
+    fi
+    kubectl -n=default create secret generic 'metadata-postgres' \
+      --from-literal='CORTEX_METADATA_POSTGRES_URL'=$CORTEX_METADATA_POSTGRES_URL \
+      -o yaml --dry-run=client | kubectl apply -f - >/dev/null
+  fi
 }
 
 function setup_istio() {
@@ -356,6 +405,12 @@ function setup_istio() {
   if [ "$CORTEX_API_LOAD_BALANCER_SCHEME" == "internal" ]; then
     export CORTEX_API_LOAD_BALANCER_ANNOTATION='service.beta.kubernetes.io/aws-load-balancer-internal: "true"'
   fi
+  # the cluster config is exported as a flow-style list (e.g. "[eipalloc-1, eipalloc-2]")
+  export CORTEX_API_LOAD_BALANCER_EIP_ANNOTATION=""
+  api_load_balancer_eips=$(echo "${CORTEX_API_LOAD_BALANCER_EIP_ALLOCATIONS:-}" | tr -d '[] ')
+  if [ "$api_load_balancer_eips" != "" ]; then
+    export CORTEX_API_LOAD_BALANCER_EIP_ANNOTATION="service.beta.kubernetes.io/aws-load-balancer-eip-allocations: $api_load_balancer_eips"
+  fi
   export CORTEX_OPERATOR_LOAD_BALANCER_ANNOTATION=""
   if [ "$CORTEX_OPERATOR_LOAD_BALANCER_SCHEME" == "internal" ]; then
     export CORTEX_OPERATOR_LOAD_BALANCER_ANNOTATION='service.beta.kubernetes.io/aws-load-balancer-internal: "true"'
@@ -30,7 +30,12 @@ def get_autoscaling_group():
             "k8s.io/cluster-autoscaler/node-template/label/workload",
         )
     )
-    asgs = list(filtered_asgs)
+    # node groups configured via node_groups cannot be modified after the cluster is created
+    asgs = [
+        asg
+        for asg in filtered_asgs
+        if not extract_nodegroup_name(asg).startswith("ng-cortex-group-")
+    ]
     if len(asgs) == 0:
         raise Exception(
             "unable to find autoscaling groups belong to cluster "
@@ -76,16 +81,16 @@ def refresh_yaml(configmap_yaml_path, output_yaml_path):
         asg_names = set()
         for group in asgs:
             nodegroup_name = extract_nodegroup_name(group)
-            if nodegroup_name == "ng-cortex-worker-spot":
+            if nodegroup_name.startswith("ng-cortex-worker-spot"):
                 asg = group
             asg_names.add(nodegroup_name)
-        if "ng-cortex-worker-on-demand" not in asg_names:
+        if not any(name.startswith("ng-cortex-worker-on-demand") for name in asg_names):
             raise Exception(
                 "expected autoscaling group with tag eksctl.io/v1alpha2/nodegroup-name={}".format(
                     "ng-cortex-worker-on-demand"
                 )
             )
-        if "ng-cortex-worker-spot" not in asg_names:
+        if not any(name.startswith("ng-cortex-worker-spot") for name in asg_names):
             raise Exception(
                 "expected autoscaling group with tag eksctl.io/v1alpha2/nodegroup-name={}".format(
                     "ng-cortex-worker-spot"
@@ -0,0 +1,97 @@
+#!/bin/bash
+
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+set -eo pipefail
+
+EKSCTL_TIMEOUT=45m
+
+# a cluster upgrade replaces all of the cluster's node groups with new node groups (which use the latest EKS-optimized
+# AMI), whose names are suffixed with the upgrade's generation:
+#   1. `upgrade.sh --create-node-groups <generation>` upgrades the control plane (if needed) and creates the new node groups
+#   2. the operator migrates the cluster's pods onto the new nodes (see node_migration.go)
+#   3. `upgrade.sh --delete-node-groups <generation>` deletes the old node groups
+
+arg1="$1"
+generation="$2"
+
+if [ "$generation" = "" ]; then
+  echo "error: the node generation must be specified"
+  exit 1
+fi
+
+# prints the name and desired capacity of the autoscaling groups which don't belong to the generation
+function old_asgs() {
+  aws autoscaling describe-auto-scaling-groups --region $CORTEX_REGION --query "AutoScalingGroups[?contains(Tags[?Key==\`alpha.eksctl.io/cluster-name\`].Value, \`$CORTEX_CLUSTER_NAME\`)]" \
+    | jq -r --arg suffix "-$generation" '.[] | select([.Tags[] | select(.Key == "alpha.eksctl.io/nodegroup-name") | .Value | endswith($suffix)] | any | not) | "\(.AutoScalingGroupName) \(.DesiredCapacity)"'
+}
+
+function create_node_groups() {
+  python generate_eks.py $CORTEX_CLUSTER_CONFIG_FILE $generation > $CORTEX_CLUSTER_WORKSPACE/eks.yaml
+
+  echo -e "￮ upgrading the control plane (if a newer kubernetes version is available) ...\n"
+  eksctl upgrade cluster --timeout=$EKSCTL_TIMEOUT -f $CORTEX_CLUSTER_WORKSPACE/eks.yaml --approve
+  eksctl utils update-kube-proxy -f $CORTEX_CLUSTER_WORKSPACE/eks.yaml --approve
+  eksctl utils update-aws-node -f $CORTEX_CLUSTER_WORKSPACE/eks.yaml --approve
+  eksctl utils update-coredns -f $CORTEX_CLUSTER_WORKSPACE/eks.yaml --approve
+  echo
+
+  # the old node groups shouldn't be scaled up while their nodes are being drained
+  echo -n "￮ disabling scale-up of the old node groups "
+  old_asgs | while read -r asg_name desired_capacity; do
+    aws autoscaling update-auto-scaling-group --region $CORTEX_REGION --auto-scaling-group-name $asg_name --min-size 0 --max-size $desired_capacity
+  done
+  echo "✓"
+
+  echo -e "￮ creating the new node groups ... (this will take about 10 minutes)\n"
+  eksctl create nodegroup --timeout=$EKSCTL_TIMEOUT -f $CORTEX_CLUSTER_WORKSPACE/eks.yaml --include="*-$generation"
+
+  if [ "$CORTEX_SPOT" == "True" ]; then
+    asg_info=$(aws autoscaling describe-auto-scaling-groups --region $CORTEX_REGION --query "AutoScalingGroups[?contains(Tags[?Key==\`alpha.eksctl.io/cluster-name\`].Value, \`$CORTEX_CLUSTER_NAME\`)]|[?contains(Tags[?Key==\`alpha.eksctl.io/nodegroup-name\`].Value, \`ng-cortex-worker-spot-$generation\`)]")
+    asg_name=$(echo "$asg_info" | jq -r 'first | .AutoScalingGroupName')
+    if [ "$asg_name" = "" ] || [ "$asg_name" = "null" ]; then
+      echo -e "unable to find autoscaling group name from info:\n$asg_info"
+      exit 1
+    fi
+    aws autoscaling suspend-processes --region $CORTEX_REGION --auto-scaling-group-name $asg_name --scaling-processes AZRebalance
+  fi
+
+  echo -n "￮ waiting for the new nodes "
+  until [ "$(kubectl get nodes -l cortex.dev/node-generation=$generation -o json | jq -j '[.items[] | select(.status.conditions[] | select(.type == "Ready" and .status == "True"))] | length')" -gt "0" ]; do echo -n "."; sleep 5; done
+  echo " ✓"
+}
+
+function delete_node_groups() {
+  nodegroups=$(eksctl get nodegroup --cluster=$CORTEX_CLUSTER_NAME --region=$CORTEX_REGION -o json | jq -r '.[].Name')
+  for nodegroup in $nodegroups; do
+    if [[ "$nodegroup" == ng-cortex-* ]] && [[ "$nodegroup" != *-$generation ]]; then
+      echo -e "￮ deleting node group $nodegroup ...\n"
+      eksctl delete nodegroup --cluster=$CORTEX_CLUSTER_NAME --region=$CORTEX_REGION --name=$nodegroup --timeout=$EKSCTL_TIMEOUT --wait
+      echo
+    fi
+  done
+}
+
+eksctl utils write-kubeconfig --cluster=$CORTEX_CLUSTER_NAME --region=$CORTEX_REGION | grep -v "saved kubeconfig as" | grep -v "using region" | grep -v "eksctl version" || true
+
+if [ "$arg1" = "--create-node-groups" ]; then
+  mkdir -p $CORTEX_CLUSTER_WORKSPACE
+  create_node_groups
+elif [ "$arg1" = "--delete-node-groups" ]; then
+  delete_node_groups
+else
+  echo "error: unexpected argument $arg1"
+  exit 1
+fi
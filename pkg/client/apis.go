@@ -0,0 +1,155 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/files"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+)
+
+// Deploy deploys the APIs in the configuration file at configPath, uploading the files in its directory as the
+// project (in the same way as `cortex deploy`)
+func (c *Client) Deploy(configPath string, force bool) (schema.DeployResponse, error) {
+	configBytes, err := files.ReadFileBytes(configPath)
+	if err != nil {
+		return schema.DeployResponse{}, err
+	}
+
+	projectZipBytes, err := ZipProject(configPath)
+	if err != nil {
+		return schema.DeployResponse{}, err
+	}
+
+	return c.DeployBytes(filepath.Base(configPath), configBytes, projectZipBytes, force)
+}
+
+// DeployBytes deploys the APIs in configBytes, with projectZipBytes as the project (see ZipProject); configFileName is
+// only used in messages
+func (c *Client) DeployBytes(configFileName string, configBytes []byte, projectZipBytes []byte, force bool) (schema.DeployResponse, error) {
+	params := map[string]string{
+		"force":      s.Bool(force),
+		"configPath": configFileName,
+	}
+	uploadBytes := map[string][]byte{
+		"config":      configBytes,
+		"project.zip": projectZipBytes,
+	}
+
+	var deployRes schema.DeployResponse
+	if err := c.postFiles("/deploy", params, uploadBytes, &deployRes); err != nil {
+		return schema.DeployResponse{}, err
+	}
+	return deployRes, nil
+}
+
+func (c *Client) GetAPIs() (schema.GetAPIsResponse, error) {
+	var apisRes schema.GetAPIsResponse
+	if err := c.get("/get", nil, &apisRes); err != nil {
+		return schema.GetAPIsResponse{}, err
+	}
+	return apisRes, nil
+}
+
+func (c *Client) GetAPI(apiName string) (schema.GetAPIResponse, error) {
+	var apiRes schema.GetAPIResponse
+	if err := c.get("/get/"+apiName, nil, &apiRes); err != nil {
+		return schema.GetAPIResponse{}, err
+	}
+	return apiRes, nil
+}
+
+func (c *Client) Refresh(apiName string, force bool) (schema.RefreshResponse, error) {
+	params := map[string]string{
+		"force": s.Bool(force),
+	}
+
+	var refreshRes schema.RefreshResponse
+	if err := c.postNoBody("/refresh/"+apiName, params, &refreshRes); err != nil {
+		return schema.RefreshResponse{}, err
+	}
+	return refreshRes, nil
+}
+
+func (c *Client) Delete(apiName string, keepCache bool) (schema.DeleteResponse, error) {
+	params := map[string]string{
+		"apiName":   apiName,
+		"keepCache": s.Bool(keepCache),
+	}
+
+	var deleteRes schema.DeleteResponse
+	if err := c.delete("/delete/"+apiName, params, &deleteRes); err != nil {
+		return schema.DeleteResponse{}, err
+	}
+	return deleteRes, nil
+}
+
+type MetricsOptions struct {
+	Start  *time.Time // default: one hour before End
+	End    *time.Time // default: now
+	Period int64      // seconds per datapoint (default: chosen based on the time range)
+	APIID  string     // only include requests which were served by this version of the API
+	Model  string     // only include requests for this model (for multi-model APIs)
+}
+
+func (c *Client) GetMetrics(apiName string, opts MetricsOptions) (schema.GetMetricsResponse, error) {
+	params := map[string]string{}
+	if opts.Start != nil {
+		params["start"] = opts.Start.Format(time.RFC3339)
+	}
+	if opts.End != nil {
+		params["end"] = opts.End.Format(time.RFC3339)
+	}
+	if opts.Period > 0 {
+		params["period"] = s.Int64(opts.Period)
+	}
+	if opts.APIID != "" {
+		params["apiID"] = opts.APIID
+	}
+	if opts.Model != "" {
+		params["model"] = opts.Model
+	}
+
+	var metricsRes schema.GetMetricsResponse
+	if err := c.get("/metrics/"+apiName, params, &metricsRes); err != nil {
+		return schema.GetMetricsResponse{}, err
+	}
+	return metricsRes, nil
+}
+
+// GetAuditLog returns the audit events of an API (or of all APIs if apiName is empty) which were recorded between start and end (either may be nil)
+func (c *Client) GetAuditLog(apiName string, start *time.Time, end *time.Time) (schema.GetAuditLogResponse, error) {
+	params := map[string]string{}
+	if apiName != "" {
+		params["apiName"] = apiName
+	}
+	if start != nil {
+		params["start"] = start.Format(time.RFC3339)
+	}
+	if end != nil {
+		params["end"] = end.Format(time.RFC3339)
+	}
+
+	var auditRes schema.GetAuditLogResponse
+	if err := c.get("/audit", params, &auditRes); err != nil {
+		return schema.GetAuditLogResponse{}, err
+	}
+	return auditRes, nil
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package client is a Go client for the Cortex operator, for services which manage APIs without the CLI
+package client
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+)
+
+const (
+	_defaultTimeout       = 600 * time.Second
+	_defaultMaxRetries    = 3
+	_defaultRetryInterval = 2 * time.Second
+)
+
+type Config struct {
+	OperatorEndpoint   string        // e.g. https://a1b2c3d4-1234567890.us-west-2.elb.amazonaws.com (see `cortex cluster info`)
+	AWSAccessKeyID     string        // credentials of an IAM identity in the cluster's account (which is a member of a team, if teams are configured)
+	AWSSecretAccessKey string        //
+	Timeout            time.Duration // timeout of each request (default: 10 minutes, since deploys upload the project)
+	MaxRetries         int           // number of times that idempotent requests are retried if the operator can't be reached or is unavailable (default: 3; -1 disables retries)
+	RetryInterval      time.Duration // time to wait before the first retry, which doubles with each subsequent retry (default: 2 seconds)
+}
+
+type Client struct {
+	config     Config
+	httpClient *http.Client
+}
+
+func New(config Config) (*Client, error) {
+	if config.OperatorEndpoint == "" {
+		return nil, ErrorMissingConfigField("OperatorEndpoint")
+	}
+	if config.AWSAccessKeyID == "" {
+		return nil, ErrorMissingConfigField("AWSAccessKeyID")
+	}
+	if config.AWSSecretAccessKey == "" {
+		return nil, ErrorMissingConfigField("AWSSecretAccessKey")
+	}
+
+	config.OperatorEndpoint = strings.TrimSuffix(config.OperatorEndpoint, "/")
+	if config.Timeout == 0 {
+		config.Timeout = _defaultTimeout
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = _defaultMaxRetries
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.RetryInterval == 0 {
+		config.RetryInterval = _defaultRetryInterval
+	}
+
+	return &Client{
+		config: config,
+		httpClient: &http.Client{
+			Timeout: config.Timeout,
+			Transport: &http.Transport{
+				// the operator's load balancer uses a self-signed certificate (as in the CLI)
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		},
+	}, nil
+}
+
+func (c *Client) authHeader() string {
+	return fmt.Sprintf("CortexAWS %s|%s", c.config.AWSAccessKeyID, c.config.AWSSecretAccessKey)
+}
+
+func (c *Client) get(path string, qParams map[string]string, response interface{}) error {
+	return c.request(http.MethodGet, path, qParams, nil, "", response)
+}
+
+func (c *Client) delete(path string, qParams map[string]string, response interface{}) error {
+	return c.request(http.MethodDelete, path, qParams, nil, "", response)
+}
+
+func (c *Client) postNoBody(path string, qParams map[string]string, response interface{}) error {
+	return c.request(http.MethodPost, path, qParams, nil, "", response)
+}
+
+// postFiles uploads files as a multipart form (which is how the operator receives deployments)
+func (c *Client) postFiles(path string, qParams map[string]string, fileBytes map[string][]byte, response interface{}) error {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	for fileName, bytes := range fileBytes {
+		part, err := writer.CreateFormFile(fileName, fileName)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if _, err := part.Write(bytes); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return c.request(http.MethodPost, path, qParams, body.Bytes(), writer.FormDataContentType(), response)
+}
+
+// request sends a request to the operator and decodes its JSON response into response; GET and DELETE requests are
+// retried if the operator can't be reached or responds that it is temporarily unavailable
+func (c *Client) request(method string, path string, qParams map[string]string, body []byte, contentType string, response interface{}) error {
+	maxRetries := 0
+	if method == http.MethodGet || method == http.MethodDelete {
+		maxRetries = c.config.MaxRetries
+	}
+
+	retryInterval := c.config.RetryInterval
+	for attempt := 0; ; attempt++ {
+		responseBytes, retryable, err := c.requestOnce(method, path, qParams, body, contentType)
+		if err != nil {
+			if retryable && attempt < maxRetries {
+				time.Sleep(retryInterval)
+				retryInterval *= 2
+				continue
+			}
+			return err
+		}
+
+		if response == nil {
+			return nil
+		}
+		if err := json.Unmarshal(responseBytes, response); err != nil {
+			return errors.Wrap(err, path, string(responseBytes))
+		}
+		return nil
+	}
+}
+
+func (c *Client) requestOnce(method string, path string, qParams map[string]string, body []byte, contentType string) ([]byte, bool, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, c.config.OperatorEndpoint+path, bodyReader)
+	if err != nil {
+		return nil, false, errors.WithStack(err)
+	}
+
+	values := req.URL.Query()
+	for key, value := range qParams {
+		values.Set(key, value)
+	}
+	req.URL.RawQuery = values.Encode()
+
+	req.Header.Set("Authorization", c.authHeader())
+	req.Header.Set("CortexAPIVersion", consts.CortexVersion)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, true, ErrorFailedToConnectOperator(err, c.config.OperatorEndpoint)
+	}
+	defer res.Body.Close()
+
+	resBytes, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, true, errors.WithStack(err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		retryable := res.StatusCode == http.StatusBadGateway || res.StatusCode == http.StatusServiceUnavailable || res.StatusCode == http.StatusGatewayTimeout
+		return nil, retryable, responseError(resBytes, res.StatusCode)
+	}
+
+	return resBytes, false, nil
+}
+
+// responseError converts an error response from the operator into an error with the same kind and message
+func responseError(body []byte, statusCode int) error {
+	var errorResponse schema.ErrorResponse
+	if err := json.Unmarshal(body, &errorResponse); err != nil || errorResponse.Message == "" {
+		return ErrorOperatorResponseUnknown(string(body), statusCode)
+	}
+	return errors.WithStack(&errors.Error{
+		Kind:        errorResponse.Kind,
+		Message:     errorResponse.Message,
+		NoTelemetry: true,
+	})
+}
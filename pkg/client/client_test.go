@@ -0,0 +1,100 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func testClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := New(Config{
+		OperatorEndpoint:   server.URL + "/",
+		AWSAccessKeyID:     "key",
+		AWSSecretAccessKey: "secret",
+		RetryInterval:      time.Millisecond,
+	})
+	require.NoError(t, err)
+	return client
+}
+
+func writeJSON(w http.ResponseWriter, obj interface{}) {
+	jsonBytes, _ := json.Marshal(obj)
+	w.Write(jsonBytes)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{OperatorEndpoint: "https://operator", AWSAccessKeyID: "key"})
+	require.Error(t, err)
+	require.Equal(t, ErrMissingConfigField, errors.GetKind(err))
+}
+
+func TestGetAPI(t *testing.T) {
+	client := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/get/iris-classifier", r.URL.Path)
+		require.Equal(t, "CortexAWS key|secret", r.Header.Get("Authorization"))
+		writeJSON(w, schema.GetAPIResponse{BaseURL: "https://apis"})
+	})
+
+	apiRes, err := client.GetAPI("iris-classifier")
+	require.NoError(t, err)
+	require.Equal(t, "https://apis", apiRes.BaseURL)
+}
+
+func TestErrorResponse(t *testing.T) {
+	client := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		writeJSON(w, schema.ErrorResponse{Kind: "operator.api_not_deployed", Message: "iris-classifier is not deployed"})
+	})
+
+	_, err := client.GetAPI("iris-classifier")
+	require.Error(t, err)
+	require.Equal(t, "operator.api_not_deployed", errors.GetKind(err))
+	require.Equal(t, "iris-classifier is not deployed", errors.Message(err))
+}
+
+func TestRetries(t *testing.T) {
+	numRequests := 0
+	client := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		numRequests++
+		if numRequests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		writeJSON(w, schema.GetAPIsResponse{})
+	})
+
+	_, err := client.GetAPIs()
+	require.NoError(t, err)
+	require.Equal(t, 3, numRequests)
+
+	// requests which aren't idempotent are not retried
+	numRequests = 0
+	_, err = client.Refresh("iris-classifier", false)
+	require.Error(t, err)
+	require.Equal(t, 1, numRequests)
+}
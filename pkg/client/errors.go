@@ -0,0 +1,65 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/urls"
+)
+
+const (
+	ErrMissingConfigField      = "client.missing_config_field"
+	ErrFailedToConnectOperator = "client.failed_to_connect_operator"
+	ErrOperatorResponseUnknown = "client.operator_response_unknown"
+	ErrOperatorSocketRead      = "client.operator_socket_read"
+)
+
+func ErrorMissingConfigField(field string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrMissingConfigField,
+		Message: fmt.Sprintf("%s must be specified in the client's config", field),
+	})
+}
+
+func ErrorFailedToConnectOperator(originalError error, operatorEndpoint string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrFailedToConnectOperator,
+		Message: fmt.Sprintf("%s\n\nunable to connect to the operator (operator endpoint: %s)", urls.TrimQueryParamsStr(errors.Message(originalError)), operatorEndpoint),
+	})
+}
+
+func ErrorOperatorResponseUnknown(body string, statusCode int) error {
+	msg := body
+	if strings.TrimSpace(body) == "" {
+		msg = fmt.Sprintf("empty response (status code %d)", statusCode)
+	}
+
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrOperatorResponseUnknown,
+		Message: msg,
+	})
+}
+
+func ErrorOperatorSocketRead(err error) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrOperatorSocketRead,
+		Message: err.Error(),
+	})
+}
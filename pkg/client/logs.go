@@ -0,0 +1,117 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/gorilla/websocket"
+)
+
+type TailOptions struct {
+	Containers []string // default: the API container
+	Filter     string   // only return lines which contain this string
+	Since      string   // RFC 3339 timestamp or duration (e.g. 1h)
+	Until      string   // RFC 3339 timestamp or duration
+}
+
+// StreamLogs calls onLine with each line that the API's replicas log (like `cortex logs`) until ctx is cancelled or the
+// operator closes the stream
+func (c *Client) StreamLogs(ctx context.Context, apiName string, onLine func(line string)) error {
+	return c.stream(ctx, "/logs/"+apiName, nil, onLine)
+}
+
+// TailLogs calls onLine with each line in the API's logs which match opts (like `cortex logs --tail`)
+func (c *Client) TailLogs(ctx context.Context, apiName string, opts TailOptions, onLine func(line string)) error {
+	params := map[string]string{}
+	if len(opts.Containers) > 0 {
+		params["containers"] = strings.Join(opts.Containers, ",")
+	}
+	if opts.Filter != "" {
+		params["filter"] = opts.Filter
+	}
+	if opts.Since != "" {
+		params["since"] = opts.Since
+	}
+	if opts.Until != "" {
+		params["until"] = opts.Until
+	}
+
+	return c.stream(ctx, "/logs/"+apiName+"/tail", params, onLine)
+}
+
+// stream reads the messages that the operator writes to the websocket at path, until either side closes it
+func (c *Client) stream(ctx context.Context, path string, qParams map[string]string, onMessage func(message string)) error {
+	wsURL, err := url.Parse(c.config.OperatorEndpoint + path)
+	if err != nil {
+		return ErrorFailedToConnectOperator(err, c.config.OperatorEndpoint)
+	}
+	wsURL.Scheme = strings.Replace(wsURL.Scheme, "http", "ws", 1)
+	values := wsURL.Query()
+	for key, value := range qParams {
+		values.Set(key, value)
+	}
+	wsURL.RawQuery = values.Encode()
+
+	header := http.Header{}
+	header.Set("Authorization", c.authHeader())
+	header.Set("CortexAPIVersion", consts.CortexVersion)
+
+	dialer := websocket.Dialer{
+		TLSClientConfig:  &tls.Config{InsecureSkipVerify: true},
+		HandshakeTimeout: c.config.Timeout,
+	}
+
+	connection, res, err := dialer.DialContext(ctx, wsURL.String(), header)
+	if err != nil {
+		if res == nil {
+			return ErrorFailedToConnectOperator(err, c.config.OperatorEndpoint)
+		}
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		return responseError(body, res.StatusCode)
+	}
+	defer connection.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			connection.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			connection.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		_, message, err := connection.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil || websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				return nil
+			}
+			return ErrorOperatorSocketRead(err)
+		}
+		onMessage(string(message))
+	}
+}
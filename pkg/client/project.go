@@ -0,0 +1,76 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"path/filepath"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/cortexlabs/cortex/pkg/lib/zip"
+)
+
+// files with dataset extensions which are larger than this are rejected (the same threshold as `cortex deploy`)
+const _maxDatasetBytes = 1024 * 1024 * 10
+
+// ZipProject zips the project directory of the configuration file at configPath (i.e. the directory which contains it),
+// excluding the same files as `cortex deploy`: the configuration file, hidden files and folders, Python bytecode
+// files, and files which match the project's .cortexignore (python environments and datasets are rejected)
+func ZipProject(configPath string) ([]byte, error) {
+	absConfigPath, err := filepath.Abs(configPath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	projectRoot := filepath.Dir(absConfigPath)
+
+	ignoreFns := []files.IgnoreFn{
+		files.IgnoreSpecificFiles(absConfigPath),
+		files.IgnoreCortexDebug,
+		files.IgnoreHiddenFiles,
+		files.IgnoreHiddenFolders,
+		files.IgnorePythonGeneratedFiles,
+	}
+
+	cortexIgnorePath := filepath.Join(projectRoot, ".cortexignore")
+	if files.IsFile(cortexIgnorePath) {
+		cortexIgnore, err := files.GitIgnoreFn(cortexIgnorePath)
+		if err != nil {
+			return nil, err
+		}
+		ignoreFns = append(ignoreFns, cortexIgnore)
+	}
+	ignoreFns = append(ignoreFns, files.RejectEnvironmentsAndDatasets(projectRoot, _maxDatasetBytes))
+
+	projectPaths, err := files.ListDirRecursive(projectRoot, false, ignoreFns...)
+	if err != nil {
+		return nil, err
+	}
+
+	projectZipBytes, err := zip.ToMem(&zip.Input{
+		FileLists: []zip.FileListInput{
+			{
+				Sources:      projectPaths,
+				RemovePrefix: projectRoot,
+			},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to zip project folder")
+	}
+
+	return projectZipBytes, nil
+}
@@ -50,7 +50,16 @@ var (
 		DefaultImageONNXPredictorGPU,
 	)
 
+	// third-party LLM servers, which are run as-is
+	DefaultImageVLLM = "vllm/vllm-openai:v0.4.0"
+	DefaultImageTGI  = "ghcr.io/huggingface/text-generation-inference:1.4"
+
+	// the feature store cache, which is run as-is
+	DefaultImageRedis = "redis:6.0.9-alpine"
+
 	MaxClassesPerMonitoringRequest = 20 // cloudwatch.GeMetricData can get up to 100 metrics per request, avoid multiple requests and have room for other stats
+	MaxModelsPerMetricsRequest     = 20 // each model's network stats use 5 of the 100 metrics that cloudwatch.GetMetricData can get per request
+	MaxCustomMetricsPerAPI         = 20 // each custom histogram uses 4 of the 100 metrics that cloudwatch.GetMetricData can get per request
 	DashboardTitle                 = "# cortex monitoring dashboard"
 	NeuronCoresPerInf              = int64(4)
 )
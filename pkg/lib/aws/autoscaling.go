@@ -17,6 +17,7 @@ limitations under the License.
 package aws
 
 import (
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 )
@@ -59,3 +60,15 @@ func (c *Client) AutoscalingGroups(tags map[string]string) ([]*autoscaling.Group
 
 	return asgs, nil
 }
+
+func (c *Client) SetAutoscalingGroupDesiredCapacity(asgName string, desiredCapacity int64) error {
+	_, err := c.Autoscaling().SetDesiredCapacity(&autoscaling.SetDesiredCapacityInput{
+		AutoScalingGroupName: aws.String(asgName),
+		DesiredCapacity:      aws.Int64(desiredCapacity),
+		HonorCooldown:        aws.Bool(false),
+	})
+	if err != nil {
+		return errors.Wrap(err, asgName)
+	}
+	return nil
+}
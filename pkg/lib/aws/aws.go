@@ -29,10 +29,12 @@ import (
 type Client struct {
 	Region          string
 	sess            *session.Session
+	s3Endpoint      *string
 	IsAnonymous     bool
 	clients         clients
 	accountID       *string
 	hashedAccountID *string
+	callerARN       *string
 }
 
 func NewFromEnv(region string) (*Client, error) {
@@ -45,6 +47,11 @@ func NewFromCreds(region string, accessKeyID string, secretAccessKey string) (*C
 }
 
 func NewFromClientS3Path(s3Path string, awsClient *Client) (*Client, error) {
+	if awsClient.s3Endpoint != nil {
+		// buckets in S3-compatible storage don't have an AWS region
+		return awsClient, nil
+	}
+
 	if !awsClient.IsAnonymous {
 		if awsClient.AccessKeyID() == nil || awsClient.SecretAccessKey() == nil {
 			return nil, ErrorUnexpectedMissingCredentials(awsClient.AccessKeyID(), awsClient.SecretAccessKey())
@@ -129,3 +136,21 @@ func NewAnonymousClientWithRegion(region string) (*Client, error) {
 		IsAnonymous: true,
 	}, nil
 }
+
+// SetS3Endpoint sends all subsequent S3 requests to an S3-compatible object storage endpoint (e.g. MinIO or Ceph) instead of AWS
+func (c *Client) SetS3Endpoint(endpoint string) {
+	c.s3Endpoint = &endpoint
+	c.clients.s3 = nil
+	c.clients.s3Uploader = nil
+	c.clients.s3Downloader = nil
+}
+
+func (c *Client) s3Config() *aws.Config {
+	if c.s3Endpoint == nil {
+		return &aws.Config{}
+	}
+	return &aws.Config{
+		Endpoint:         c.s3Endpoint,
+		S3ForcePathStyle: aws.Bool(true), // S3-compatible storage generally doesn't support virtual-hosted-style bucket urls
+	}
+}
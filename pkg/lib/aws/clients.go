@@ -23,12 +23,15 @@ import (
 	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ecr"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/aws/aws-sdk-go/service/servicequotas"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/aws/aws-sdk-go/service/sts"
 )
 
@@ -47,25 +50,28 @@ type clients struct {
 	serviceQuotas  *servicequotas.ServiceQuotas
 	cloudFormation *cloudformation.CloudFormation
 	iam            *iam.IAM
+	dynamoDB       *dynamodb.DynamoDB
+	sns            *sns.SNS
+	sqs            *sqs.SQS
 }
 
 func (c *Client) S3() *s3.S3 {
 	if c.clients.s3 == nil {
-		c.clients.s3 = s3.New(c.sess)
+		c.clients.s3 = s3.New(c.sess, c.s3Config())
 	}
 	return c.clients.s3
 }
 
 func (c *Client) S3Uploader() *s3manager.Uploader {
 	if c.clients.s3Uploader == nil {
-		c.clients.s3Uploader = s3manager.NewUploader(c.sess)
+		c.clients.s3Uploader = s3manager.NewUploaderWithClient(c.S3())
 	}
 	return c.clients.s3Uploader
 }
 
 func (c *Client) S3Downloader() *s3manager.Downloader {
 	if c.clients.s3Downloader == nil {
-		c.clients.s3Downloader = s3manager.NewDownloader(c.sess)
+		c.clients.s3Downloader = s3manager.NewDownloaderWithClient(c.S3())
 	}
 	return c.clients.s3Downloader
 }
@@ -146,3 +152,24 @@ func (c *Client) IAM() *iam.IAM {
 	}
 	return c.clients.iam
 }
+
+func (c *Client) DynamoDB() *dynamodb.DynamoDB {
+	if c.clients.dynamoDB == nil {
+		c.clients.dynamoDB = dynamodb.New(c.sess)
+	}
+	return c.clients.dynamoDB
+}
+
+func (c *Client) SNS() *sns.SNS {
+	if c.clients.sns == nil {
+		c.clients.sns = sns.New(c.sess)
+	}
+	return c.clients.sns
+}
+
+func (c *Client) SQS() *sqs.SQS {
+	if c.clients.sqs == nil {
+		c.clients.sqs = sqs.New(c.sess)
+	}
+	return c.clients.sqs
+}
@@ -17,11 +17,15 @@ limitations under the License.
 package aws
 
 import (
+	"strings"
+
 	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
 )
 
+// ListEKSStacks lists the control plane stack and the node group stacks; node group stacks whose names are suffixed
+// with a generation (i.e. which were created by a cluster upgrade) are included
 func (c *Client) ListEKSStacks(controlPlaneStackName string, nodegroupStackNames strset.Set) ([]*cloudformation.StackSummary, error) {
 	var stackSummaries []*cloudformation.StackSummary
 	stackSet := strset.Union(nodegroupStackNames, strset.New(controlPlaneStackName))
@@ -29,7 +33,7 @@ func (c *Client) ListEKSStacks(controlPlaneStackName string, nodegroupStackNames
 		&cloudformation.ListStacksInput{},
 		func(listStackOutput *cloudformation.ListStacksOutput, lastPage bool) bool {
 			for _, stackSummary := range listStackOutput.StackSummaries {
-				if stackSet.Has(*stackSummary.StackName) {
+				if stackSet.Has(*stackSummary.StackName) || NodeGroupStackName(*stackSummary.StackName, nodegroupStackNames) != "" {
 					stackSummaries = append(stackSummaries, stackSummary)
 				}
 
@@ -47,3 +51,13 @@ func (c *Client) ListEKSStacks(controlPlaneStackName string, nodegroupStackNames
 
 	return stackSummaries, nil
 }
+
+// NodeGroupStackName returns the name in nodegroupStackNames which stackName is a generation of (or "" if none)
+func NodeGroupStackName(stackName string, nodegroupStackNames strset.Set) string {
+	for nodegroupStackName := range nodegroupStackNames {
+		if stackName == nodegroupStackName || strings.HasPrefix(stackName, nodegroupStackName+"-") {
+			return nodegroupStackName
+		}
+	}
+	return ""
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+func (c *Client) DoesDynamoDBTableExist(table string) (bool, error) {
+	_, err := c.DynamoDB().DescribeTable(&dynamodb.DescribeTableInput{
+		TableName: aws.String(table),
+	})
+	if err != nil {
+		if IsErrCode(err, dynamodb.ErrCodeResourceNotFoundException) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "dynamodb table "+table)
+	}
+
+	return true, nil
+}
+
+// CreateDynamoDBTable creates an on-demand table with string hash and range keys, and waits for it to become active
+func (c *Client) CreateDynamoDBTable(table string, hashKey string, rangeKey string, tagMap map[string]string) error {
+	var tags []*dynamodb.Tag
+	for key, value := range tagMap {
+		tags = append(tags, &dynamodb.Tag{
+			Key:   aws.String(key),
+			Value: aws.String(value),
+		})
+	}
+
+	_, err := c.DynamoDB().CreateTable(&dynamodb.CreateTableInput{
+		TableName:   aws.String(table),
+		BillingMode: aws.String(dynamodb.BillingModePayPerRequest),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String(hashKey),
+				AttributeType: aws.String(dynamodb.ScalarAttributeTypeS),
+			},
+			{
+				AttributeName: aws.String(rangeKey),
+				AttributeType: aws.String(dynamodb.ScalarAttributeTypeS),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String(hashKey),
+				KeyType:       aws.String(dynamodb.KeyTypeHash),
+			},
+			{
+				AttributeName: aws.String(rangeKey),
+				KeyType:       aws.String(dynamodb.KeyTypeRange),
+			},
+		},
+		Tags: tags,
+	})
+	if err != nil {
+		return errors.Wrap(err, "creating dynamodb table "+table)
+	}
+
+	err = c.DynamoDB().WaitUntilTableExists(&dynamodb.DescribeTableInput{
+		TableName: aws.String(table),
+	})
+	if err != nil {
+		return errors.Wrap(err, "creating dynamodb table "+table)
+	}
+
+	return nil
+}
+
+// PutDynamoDBItem writes an item in which all attributes are strings
+func (c *Client) PutDynamoDBItem(table string, item map[string]string) error {
+	_, err := c.DynamoDB().PutItem(&dynamodb.PutItemInput{
+		TableName: aws.String(table),
+		Item:      toStringAttributeValues(item),
+	})
+	if err != nil {
+		return errors.Wrap(err, "dynamodb table "+table)
+	}
+	return nil
+}
+
+// GetDynamoDBItem returns nil if the item does not exist
+func (c *Client) GetDynamoDBItem(table string, key map[string]string) (map[string]string, error) {
+	output, err := c.DynamoDB().GetItem(&dynamodb.GetItemInput{
+		TableName:      aws.String(table),
+		Key:            toStringAttributeValues(key),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "dynamodb table "+table)
+	}
+	if len(output.Item) == 0 {
+		return nil, nil
+	}
+	return fromStringAttributeValues(output.Item), nil
+}
+
+// QueryDynamoDBPrefix returns all items with the given hash key whose range key begins with rangePrefix, in ascending order of range key
+func (c *Client) QueryDynamoDBPrefix(table string, hashKey string, hashValue string, rangeKey string, rangePrefix string) ([]map[string]string, error) {
+	input := &dynamodb.QueryInput{
+		TableName:      aws.String(table),
+		ConsistentRead: aws.Bool(true),
+		ExpressionAttributeNames: map[string]*string{
+			"#h": aws.String(hashKey),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":h": {S: aws.String(hashValue)},
+		},
+		KeyConditionExpression: aws.String("#h = :h"),
+	}
+
+	if rangePrefix != "" {
+		input.ExpressionAttributeNames["#r"] = aws.String(rangeKey)
+		input.ExpressionAttributeValues[":r"] = &dynamodb.AttributeValue{S: aws.String(rangePrefix)}
+		input.KeyConditionExpression = aws.String("#h = :h AND begins_with(#r, :r)")
+	}
+
+	var items []map[string]string
+	err := c.DynamoDB().QueryPages(input, func(output *dynamodb.QueryOutput, lastPage bool) bool {
+		for _, item := range output.Items {
+			items = append(items, fromStringAttributeValues(item))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "dynamodb table "+table)
+	}
+
+	return items, nil
+}
+
+func (c *Client) DeleteDynamoDBItem(table string, key map[string]string) error {
+	_, err := c.DynamoDB().DeleteItem(&dynamodb.DeleteItemInput{
+		TableName: aws.String(table),
+		Key:       toStringAttributeValues(key),
+	})
+	if err != nil {
+		return errors.Wrap(err, "dynamodb table "+table)
+	}
+	return nil
+}
+
+func toStringAttributeValues(item map[string]string) map[string]*dynamodb.AttributeValue {
+	attributeValues := make(map[string]*dynamodb.AttributeValue, len(item))
+	for key, value := range item {
+		attributeValues[key] = &dynamodb.AttributeValue{S: aws.String(value)}
+	}
+	return attributeValues
+}
+
+func fromStringAttributeValues(attributeValues map[string]*dynamodb.AttributeValue) map[string]string {
+	item := make(map[string]string, len(attributeValues))
+	for key, value := range attributeValues {
+		if value.S != nil {
+			item[key] = *value.S
+		}
+	}
+	return item
+}
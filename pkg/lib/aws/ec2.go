@@ -28,6 +28,9 @@ import (
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 )
 
+// spot request status codes which indicate that the instance will be interrupted within two minutes
+var _interruptionStatusCodes = strset.New("marked-for-termination", "marked-for-stop", "marked-for-hibernation")
+
 func (c *Client) SpotInstancePrice(region string, instanceType string) (float64, error) {
 	result, err := c.EC2().DescribeSpotPriceHistory(&ec2.DescribeSpotPriceHistoryInput{
 		InstanceTypes:       []*string{aws.String(instanceType)},
@@ -66,6 +69,37 @@ func (c *Client) SpotInstancePrice(region string, instanceType string) (float64,
 	return min, nil
 }
 
+// ListInterruptedSpotInstances returns the IDs of the spot instances which have received an interruption notice
+func (c *Client) ListInterruptedSpotInstances(instanceIDs []string) (strset.Set, error) {
+	interruptedInstanceIDs := strset.New()
+	if len(instanceIDs) == 0 {
+		return interruptedInstanceIDs, nil
+	}
+
+	result, err := c.EC2().DescribeSpotInstanceRequests(&ec2.DescribeSpotInstanceRequestsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("instance-id"),
+				Values: aws.StringSlice(instanceIDs),
+			},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "checking for spot instance interruptions")
+	}
+
+	for _, request := range result.SpotInstanceRequests {
+		if request.InstanceId == nil || request.Status == nil || request.Status.Code == nil {
+			continue
+		}
+		if _interruptionStatusCodes.Has(*request.Status.Code) {
+			interruptedInstanceIDs.Add(*request.InstanceId)
+		}
+	}
+
+	return interruptedInstanceIDs, nil
+}
+
 func (c *Client) ListAllRegions() (strset.Set, error) {
 	result, err := c.EC2().DescribeRegions(&ec2.DescribeRegionsInput{
 		AllRegions: aws.Bool(true),
@@ -157,6 +191,32 @@ func (c *Client) ListAvailabilityZonesInRegion() (strset.Set, error) {
 	return zones, nil
 }
 
+// ListElasticIPAllocationIDs returns which of the given elastic ip allocation IDs exist in the region
+func (c *Client) ListElasticIPAllocationIDs(allocationIDs ...string) (strset.Set, error) {
+	input := &ec2.DescribeAddressesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("allocation-id"),
+				Values: aws.StringSlice(allocationIDs),
+			},
+		},
+	}
+
+	result, err := c.EC2().DescribeAddresses(input)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	existingAllocationIDs := strset.New()
+	for _, address := range result.Addresses {
+		if address.AllocationId != nil {
+			existingAllocationIDs.Add(*address.AllocationId)
+		}
+	}
+
+	return existingAllocationIDs, nil
+}
+
 func (c *Client) listSupportedAvailabilityZonesSingle(instanceType string) (strset.Set, error) {
 	input := &ec2.DescribeReservedInstancesOfferingsInput{
 		InstanceType:       &instanceType,
@@ -210,3 +270,31 @@ func (c *Client) ListSupportedAvailabilityZones(instanceType string, instanceTyp
 
 	return strset.Intersection(zoneSets...), nil
 }
+
+// DeleteVPCEndpointServiceByTag deletes a VPC endpoint service by tag (returns the deleted service's ID, or nil if it was not found)
+func (c *Client) DeleteVPCEndpointServiceByTag(tagName string, tagValue string) (*string, error) {
+	result, err := c.EC2().DescribeVpcEndpointServiceConfigurations(&ec2.DescribeVpcEndpointServiceConfigurationsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("tag:" + tagName),
+				Values: []*string{aws.String(tagValue)},
+			},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get vpc endpoint services")
+	}
+	if len(result.ServiceConfigurations) == 0 {
+		return nil, nil
+	}
+
+	serviceID := result.ServiceConfigurations[0].ServiceId
+	_, err = c.EC2().DeleteVpcEndpointServiceConfigurations(&ec2.DeleteVpcEndpointServiceConfigurationsInput{
+		ServiceIds: []*string{serviceID},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to delete vpc endpoint service "+*serviceID)
+	}
+
+	return serviceID, nil
+}
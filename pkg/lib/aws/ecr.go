@@ -21,6 +21,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ecr"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/regex"
@@ -69,6 +70,30 @@ func (c *Client) GetECRAuthConfig() (ECRAuthConfig, error) {
 	}, nil
 }
 
+// EnsureECRRepository creates the repository if it doesn't exist, and returns its URI
+func (c *Client) EnsureECRRepository(repositoryName string) (string, error) {
+	createOutput, err := c.ECR().CreateRepository(&ecr.CreateRepositoryInput{
+		RepositoryName: aws.String(repositoryName),
+	})
+	if err == nil {
+		return *createOutput.Repository.RepositoryUri, nil
+	}
+	if !IsErrCode(err, ecr.ErrCodeRepositoryAlreadyExistsException) {
+		return "", errors.Wrap(err, "failed to create ECR repository", repositoryName)
+	}
+
+	describeOutput, err := c.ECR().DescribeRepositories(&ecr.DescribeRepositoriesInput{
+		RepositoryNames: []*string{aws.String(repositoryName)},
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to describe ECR repository", repositoryName)
+	}
+	if len(describeOutput.Repositories) == 0 {
+		return "", errors.ErrorUnexpected("ECR repository not found", repositoryName)
+	}
+	return *describeOutput.Repositories[0].RepositoryUri, nil
+}
+
 func GetAccountIDFromECRURL(path string) string {
 	if regex.IsValidECRURL(path) {
 		return strings.Split(path, ".")[0]
@@ -180,6 +180,28 @@ func (c *Client) IsS3File(bucket string, fileKey string, fileKeys ...string) (bo
 	return true, nil
 }
 
+// GetS3PathVersionID returns the ID of the current version of the file, or "" if versioning is not enabled for its bucket
+func (c *Client) GetS3PathVersionID(s3Path string) (string, error) {
+	bucket, key, err := SplitS3Path(s3Path)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := c.S3().HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, s3Path)
+	}
+
+	// objects which were uploaded while versioning was disabled have a version ID of "null"
+	if response.VersionId == nil || *response.VersionId == "null" {
+		return "", nil
+	}
+	return *response.VersionId, nil
+}
+
 func (c *Client) IsS3PathPrefix(s3Path string, s3Paths ...string) (bool, error) {
 	allS3Paths := append(s3Paths, s3Path)
 	for _, s3Path := range allS3Paths {
@@ -567,6 +589,17 @@ func (c *Client) ListS3PathPrefix(s3Path string, includeDirObjects bool, maxResu
 	return c.ListS3Prefix(bucket, prefix, includeDirObjects, maxResults)
 }
 
+func (c *Client) DeleteS3File(bucket string, key string) error {
+	_, err := c.S3().DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return errors.Wrap(err, S3Path(bucket, key))
+	}
+	return nil
+}
+
 func (c *Client) DeleteS3Dir(bucket string, s3Dir string, continueIfFailure bool) error {
 	prefix := s.EnsureSuffix(s3Dir, "/")
 	return c.DeleteS3Prefix(bucket, prefix, continueIfFailure)
@@ -732,3 +765,53 @@ func (c *Client) TagBucket(bucket string, tagMap map[string]string) error {
 
 	return nil
 }
+
+// EnsureS3QueueNotification configures the bucket to send the ObjectCreated events of keys which match prefix and suffix (either may be empty)
+// to the queue; the bucket's other notification configurations are preserved, and the queue configuration with the same ID is replaced
+func (c *Client) EnsureS3QueueNotification(bucket string, id string, queueARN string, prefix string, suffix string) error {
+	current, err := c.S3().GetBucketNotificationConfiguration(&s3.GetBucketNotificationConfigurationRequest{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to get notification configuration of S3 bucket", bucket)
+	}
+
+	queueConfigs := []*s3.QueueConfiguration{}
+	for _, queueConfig := range current.QueueConfigurations {
+		if queueConfig.Id == nil || *queueConfig.Id != id {
+			queueConfigs = append(queueConfigs, queueConfig)
+		}
+	}
+
+	var filterRules []*s3.FilterRule
+	if prefix != "" {
+		filterRules = append(filterRules, &s3.FilterRule{Name: aws.String(s3.FilterRuleNamePrefix), Value: aws.String(prefix)})
+	}
+	if suffix != "" {
+		filterRules = append(filterRules, &s3.FilterRule{Name: aws.String(s3.FilterRuleNameSuffix), Value: aws.String(suffix)})
+	}
+
+	queueConfig := &s3.QueueConfiguration{
+		Id:       aws.String(id),
+		QueueArn: aws.String(queueARN),
+		Events:   aws.StringSlice([]string{s3.EventS3ObjectCreated}),
+	}
+	if len(filterRules) > 0 {
+		queueConfig.Filter = &s3.NotificationConfigurationFilter{
+			Key: &s3.KeyFilter{FilterRules: filterRules},
+		}
+	}
+
+	_, err = c.S3().PutBucketNotificationConfiguration(&s3.PutBucketNotificationConfigurationInput{
+		Bucket: aws.String(bucket),
+		NotificationConfiguration: &s3.NotificationConfiguration{
+			QueueConfigurations:          append(queueConfigs, queueConfig),
+			TopicConfigurations:          current.TopicConfigurations,
+			LambdaFunctionConfigurations: current.LambdaFunctionConfigurations,
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to set notification configuration of S3 bucket", bucket)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+// PublishSNSMessage publishes a message to an SNS topic; the subject is only used for email subscriptions, and is limited to 100 characters
+func (c *Client) PublishSNSMessage(topicARN string, subject string, message string) error {
+	if len(subject) > 100 {
+		subject = subject[:100]
+	}
+
+	_, err := c.SNS().Publish(&sns.PublishInput{
+		TopicArn: aws.String(topicARN),
+		Subject:  aws.String(subject),
+		Message:  aws.String(message),
+	})
+	if err != nil {
+		return errors.Wrap(err, "sns topic "+topicARN)
+	}
+
+	return nil
+}
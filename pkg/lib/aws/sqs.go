@@ -0,0 +1,117 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+// EnsureSQSQueue creates the queue if it doesn't exist (existing queues are used as-is), and returns its URL
+func (c *Client) EnsureSQSQueue(queueName string, tags map[string]string) (string, error) {
+	getOutput, err := c.SQS().GetQueueUrl(&sqs.GetQueueUrlInput{
+		QueueName: aws.String(queueName),
+	})
+	if err == nil {
+		return *getOutput.QueueUrl, nil
+	}
+	if !IsErrCode(err, sqs.ErrCodeQueueDoesNotExist) {
+		return "", errors.Wrap(err, "failed to get SQS queue", queueName)
+	}
+
+	createOutput, err := c.SQS().CreateQueue(&sqs.CreateQueueInput{
+		QueueName: aws.String(queueName),
+		Tags:      aws.StringMap(tags),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create SQS queue", queueName)
+	}
+	return *createOutput.QueueUrl, nil
+}
+
+func (c *Client) GetSQSQueueURL(queueName string) (string, error) {
+	output, err := c.SQS().GetQueueUrl(&sqs.GetQueueUrlInput{
+		QueueName: aws.String(queueName),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get SQS queue", queueName)
+	}
+	return *output.QueueUrl, nil
+}
+
+func (c *Client) GetSQSQueueARN(queueURL string) (string, error) {
+	output, err := c.SQS().GetQueueAttributes(&sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(queueURL),
+		AttributeNames: aws.StringSlice([]string{sqs.QueueAttributeNameQueueArn}),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get SQS queue attributes", queueURL)
+	}
+
+	queueARN, ok := output.Attributes[sqs.QueueAttributeNameQueueArn]
+	if !ok || queueARN == nil {
+		return "", errors.ErrorUnexpected("SQS queue ARN not found", queueURL)
+	}
+	return *queueARN, nil
+}
+
+func (c *Client) SetSQSQueueAttributes(queueURL string, attributes map[string]string) error {
+	_, err := c.SQS().SetQueueAttributes(&sqs.SetQueueAttributesInput{
+		QueueUrl:   aws.String(queueURL),
+		Attributes: aws.StringMap(attributes),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to set SQS queue attributes", queueURL)
+	}
+	return nil
+}
+
+// GetSQSQueueAttribute returns the value of the queue's attribute, or nil if it isn't set
+func (c *Client) GetSQSQueueAttribute(queueURL string, attributeName string) (*string, error) {
+	output, err := c.SQS().GetQueueAttributes(&sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(queueURL),
+		AttributeNames: aws.StringSlice([]string{attributeName}),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get SQS queue attributes", queueURL)
+	}
+	return output.Attributes[attributeName], nil
+}
+
+// ReceiveSQSMessages returns up to maxMessages (at most 10) of the queue's messages, without waiting for messages to arrive
+func (c *Client) ReceiveSQSMessages(queueURL string, maxMessages int64) ([]*sqs.Message, error) {
+	output, err := c.SQS().ReceiveMessage(&sqs.ReceiveMessageInput{
+		QueueUrl:            aws.String(queueURL),
+		MaxNumberOfMessages: aws.Int64(maxMessages),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to receive SQS messages", queueURL)
+	}
+	return output.Messages, nil
+}
+
+func (c *Client) DeleteSQSMessage(queueURL string, receiptHandle string) error {
+	_, err := c.SQS().DeleteMessage(&sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(queueURL),
+		ReceiptHandle: aws.String(receiptHandle),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to delete SQS message", queueURL)
+	}
+	return nil
+}
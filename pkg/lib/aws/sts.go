@@ -31,6 +31,7 @@ func (c *Client) CheckCredentials() (string, string, error) {
 
 	c.accountID = response.Account
 	c.hashedAccountID = pointer.String(hash.String(*c.accountID))
+	c.callerARN = response.Arn
 
 	return *c.accountID, *c.hashedAccountID, nil
 }
@@ -44,3 +45,13 @@ func (c *Client) GetCachedAccountID() (string, string, error) {
 	}
 	return *c.accountID, *c.hashedAccountID, nil
 }
+
+// Returns the ARN of the IAM identity that the credentials belong to
+func (c *Client) GetCachedCallerARN() (string, error) {
+	if c.callerARN == nil {
+		if _, _, err := c.CheckCredentials(); err != nil {
+			return "", err
+		}
+	}
+	return *c.callerARN, nil
+}
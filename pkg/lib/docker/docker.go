@@ -31,6 +31,7 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/cron"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/gcp"
 	"github.com/cortexlabs/cortex/pkg/lib/parallel"
 	"github.com/cortexlabs/cortex/pkg/lib/print"
 	"github.com/cortexlabs/cortex/pkg/lib/slices"
@@ -117,6 +118,28 @@ func AWSAuthConfig(awsClient *aws.Client) (string, error) {
 	return authConfig, nil
 }
 
+// GCPAuthConfig authenticates with the Container Registry or Artifact Registry host of dockerImage, using the access
+// token of the GCP client's credentials
+func GCPAuthConfig(gcpClient *gcp.Client, dockerImage string) (string, error) {
+	accessToken, err := gcpClient.AccessToken()
+	if err != nil {
+		return "", err
+	}
+
+	auth := dockertypes.AuthConfig{
+		Username:      "oauth2accesstoken",
+		Password:      accessToken,
+		ServerAddress: "https://" + strings.Split(dockerImage, "/")[0],
+	}
+
+	authConfig, err := EncodeAuthConfig(auth)
+	if err != nil {
+		return "", err
+	}
+
+	return authConfig, nil
+}
+
 func WrapDockerError(err error) error {
 	if dockerclient.IsErrConnectionFailed(err) {
 		return ErrorConnectToDockerDaemon()
@@ -238,6 +261,26 @@ func CheckImageAccessible(dockerClient *Client, dockerImage, authConfig string)
 	return nil
 }
 
+// GetImageDigest returns the digest of the image's manifest in its registry (e.g. sha256:...)
+func GetImageDigest(dockerClient *Client, dockerImage, authConfig string) (string, error) {
+	distributionInspect, err := dockerClient.DistributionInspect(context.Background(), dockerImage, authConfig)
+	if err != nil {
+		return "", ErrorImageInaccessible(dockerImage, err)
+	}
+	return distributionInspect.Descriptor.Digest.String(), nil
+}
+
+// ImageWithDigest replaces the image's tag (if it has one) with the digest, e.g. repo:tag -> repo@sha256:...
+func ImageWithDigest(dockerImage string, digest string) string {
+	if atIndex := strings.Index(dockerImage, "@"); atIndex != -1 {
+		dockerImage = dockerImage[:atIndex]
+	}
+	if ExtractImageTag(dockerImage) != "" {
+		dockerImage = dockerImage[:strings.LastIndex(dockerImage, ":")]
+	}
+	return dockerImage + "@" + digest
+}
+
 func CheckLocalImageAccessible(dockerClient *Client, dockerImage string) error {
 	images, err := dockerClient.ImageList(context.Background(), dockertypes.ImageListOptions{})
 	if err != nil {
@@ -258,7 +301,8 @@ func CheckLocalImageAccessible(dockerClient *Client, dockerImage string) error {
 }
 
 func ExtractImageTag(dockerImage string) string {
-	if colonIndex := strings.LastIndex(dockerImage, ":"); colonIndex != -1 {
+	// a colon before the last slash separates a registry's host from its port
+	if colonIndex := strings.LastIndex(dockerImage, ":"); colonIndex > strings.LastIndex(dockerImage, "/") {
 		return dockerImage[colonIndex+1:]
 	}
 	return ""
@@ -0,0 +1,56 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/regex"
+)
+
+// OCI paths reference artifacts in a container registry (e.g. pushed with ORAS), e.g. oci://ghcr.io/my-org/my-model:v1
+const _ociScheme = "oci://"
+
+func IsOCIPath(path string) bool {
+	return strings.HasPrefix(path, _ociScheme)
+}
+
+// IsValidOCIPath returns true if the path's reference includes the registry's host (which isn't assumed to be Docker Hub)
+func IsValidOCIPath(ociPath string) bool {
+	if !IsOCIPath(ociPath) {
+		return false
+	}
+	reference := OCIReference(ociPath)
+	if !regex.IsValidDockerImage(reference) {
+		return false
+	}
+	slashIndex := strings.Index(reference, "/")
+	if slashIndex == -1 {
+		return false
+	}
+	host := reference[:slashIndex]
+	return strings.ContainsAny(host, ".:") || host == "localhost"
+}
+
+// OCIReference returns the artifact's reference in its registry (i.e. the path without the oci:// scheme)
+func OCIReference(ociPath string) string {
+	return strings.TrimPrefix(ociPath, _ociScheme)
+}
+
+func OCIPath(reference string) string {
+	return _ociScheme + reference
+}
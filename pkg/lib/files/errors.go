@@ -24,18 +24,20 @@ import (
 )
 
 const (
-	ErrCreateDir         = "files.create_dir"
-	ErrDeleteDir         = "files.delete_dir"
-	ErrReadFormFile      = "files.read_form_file"
-	ErrCreateFile        = "files.create_file"
-	ErrReadDir           = "files.read_dir"
-	ErrReadFile          = "files.read_file"
-	ErrFileAlreadyExists = "files.file_already_exists"
-	ErrUnexpected        = "files.unexpected"
-	ErrFileDoesNotExist  = "files.file_does_not_exist"
-	ErrDirDoesNotExist   = "files.dir_does_not_exist"
-	ErrNotAFile          = "files.not_a_file"
-	ErrNotADir           = "files.not_a_dir"
+	ErrCreateDir           = "files.create_dir"
+	ErrDeleteDir           = "files.delete_dir"
+	ErrReadFormFile        = "files.read_form_file"
+	ErrCreateFile          = "files.create_file"
+	ErrReadDir             = "files.read_dir"
+	ErrReadFile            = "files.read_file"
+	ErrFileAlreadyExists   = "files.file_already_exists"
+	ErrUnexpected          = "files.unexpected"
+	ErrFileDoesNotExist    = "files.file_does_not_exist"
+	ErrDirDoesNotExist     = "files.dir_does_not_exist"
+	ErrNotAFile            = "files.not_a_file"
+	ErrNotADir             = "files.not_a_dir"
+	ErrContainsEnvironment = "files.contains_environment"
+	ErrContainsDataset     = "files.contains_dataset"
 )
 
 func ErrorCreateDir(path string) error {
@@ -121,3 +123,17 @@ func ErrorNotADir(path string) error {
 		Message: fmt.Sprintf("%s: not a directory path", path),
 	})
 }
+
+func ErrorContainsEnvironment(contents string, ignorePattern string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrContainsEnvironment,
+		Message: fmt.Sprintf("this directory contains %s, which should not be uploaded with your project; list your api's dependencies in requirements.txt (or conda-packages.txt) so that they are installed in its containers, and add %s to your .cortexignore file", contents, ignorePattern),
+	})
+}
+
+func ErrorContainsDataset(size int64, ignorePattern string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrContainsDataset,
+		Message: fmt.Sprintf("this file (%s) appears to be a dataset, which would be uploaded on every deploy; upload it to s3 and read it from your predictor instead, and add %s to your .cortexignore file", s.IntToBase2Byte(int(size)), ignorePattern),
+	})
+}
@@ -533,6 +533,38 @@ func GitIgnoreFn(gitIgnorePath string) (IgnoreFn, error) {
 }
 
 // promptMsgTemplate should have two placeholders: the first is for the file path and the second is for the file size
+// files with these extensions are assumed to be datasets if they are large
+var _datasetExtensions = strset.New(".csv", ".tsv", ".json", ".jsonl", ".parquet", ".avro", ".orc", ".arrow", ".feather", ".tfrecord", ".tfrecords", ".h5", ".hdf5", ".npy", ".npz")
+
+// RejectEnvironmentsAndDatasets returns an error for files in a project directory which almost certainly shouldn't be
+// uploaded with it: python (or node) environments, and datasets which are larger than maxDatasetSize. Since the error
+// suggests adding the files to .cortexignore, this should be applied after the .cortexignore IgnoreFn
+func RejectEnvironmentsAndDatasets(projectRoot string, maxDatasetSize int) IgnoreFn {
+	return func(path string, fi os.FileInfo) (bool, error) {
+		ignorePattern := strings.TrimPrefix(path, s.EnsureSuffix(projectRoot, "/"))
+
+		if fi.IsDir() {
+			if path == projectRoot {
+				return false, nil
+			}
+			switch {
+			case IsFile(filepath.Join(path, "pyvenv.cfg")) || IsDir(filepath.Join(path, "conda-meta")):
+				return false, ErrorContainsEnvironment("a python environment", ignorePattern+"/")
+			case fi.Name() == "site-packages":
+				return false, ErrorContainsEnvironment("installed python packages", ignorePattern+"/")
+			case fi.Name() == "node_modules":
+				return false, ErrorContainsEnvironment("installed node packages", ignorePattern+"/")
+			}
+			return false, nil
+		}
+
+		if fi.Size() > int64(maxDatasetSize) && _datasetExtensions.Has(strings.ToLower(filepath.Ext(path))) {
+			return false, ErrorContainsDataset(fi.Size(), ignorePattern)
+		}
+		return false, nil
+	}
+}
+
 func PromptForFilesAboveSize(size int, promptMsgTemplate string) IgnoreFn {
 	if promptMsgTemplate == "" {
 		promptMsgTemplate = "do you want to zip %s (%s)?"
@@ -0,0 +1,66 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcp
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+const (
+	ErrReadCredentials = "gcp.read_credentials"
+	ErrInvalidGCSPath  = "gcp.invalid_gcs_path"
+	ErrGCSRequest      = "gcp.gcs_request"
+	ErrGCSFileNotFound = "gcp.gcs_file_not_found"
+)
+
+func IsGCSFileNotFoundErr(err error) bool {
+	return errors.GetKind(err) == ErrGCSFileNotFound
+}
+
+func ErrorReadCredentials(err error) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrReadCredentials,
+		Message: "unable to read GCP credentials (on GKE, the pod's kubernetes service account must be bound to a google service account with Workload Identity)\n" + errors.Message(err),
+		Cause:   err,
+	})
+}
+
+func ErrorInvalidGCSPath(provided string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidGCSPath,
+		Message: fmt.Sprintf("%s is not a valid gcs path (e.g. gs://cortex-examples/pytorch/iris-classifier/weights.pth is a valid gcs path)", s.UserStr(provided)),
+	})
+}
+
+func ErrorGCSRequest(gcsPath string, statusCode int, body string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrGCSRequest,
+		Message: fmt.Sprintf("%s: gcs request failed with status %d %s: %s", gcsPath, statusCode, http.StatusText(statusCode), strings.TrimSpace(body)),
+	})
+}
+
+func ErrorGCSFileNotFound(gcsPath string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrGCSFileNotFound,
+		Message: fmt.Sprintf("%s: file not found", gcsPath),
+	})
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+var _scopes = []string{"https://www.googleapis.com/auth/cloud-platform"}
+
+type Client struct {
+	ProjectID   string
+	tokenSource oauth2.TokenSource
+	httpClient  *http.Client
+}
+
+// NewFromEnv authenticates with the application default credentials, which on GKE are the credentials of the google
+// service account that the pod's kubernetes service account is bound to (via Workload Identity)
+func NewFromEnv(projectID string) (*Client, error) {
+	creds, err := google.FindDefaultCredentials(context.Background(), _scopes...)
+	if err != nil {
+		return nil, ErrorReadCredentials(err)
+	}
+
+	if projectID == "" {
+		projectID = creds.ProjectID
+	}
+
+	return &Client{
+		ProjectID:   projectID,
+		tokenSource: creds.TokenSource,
+		httpClient:  oauth2.NewClient(context.Background(), creds.TokenSource),
+	}, nil
+}
+
+// AccessToken returns an OAuth2 access token of the client's credentials (e.g. to authenticate with GCR and Artifact Registry)
+func (c *Client) AccessToken() (string, error) {
+	token, err := c.tokenSource.Token()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return token.AccessToken, nil
+}
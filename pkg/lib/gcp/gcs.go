@@ -0,0 +1,210 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/msgpack"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+// objects are read and written with the GCS JSON API (https://cloud.google.com/storage/docs/json_api)
+const (
+	_gcsAPIURL       = "https://storage.googleapis.com/storage/v1"
+	_gcsUploadAPIURL = "https://storage.googleapis.com/upload/storage/v1"
+)
+
+func GCSPath(bucket string, key string) string {
+	return "gs://" + filepath.Join(bucket, key)
+}
+
+func SplitGCSPath(gcsPath string) (string, string, error) {
+	if !IsValidGCSPath(gcsPath) {
+		return "", "", ErrorInvalidGCSPath(gcsPath)
+	}
+	fullPath := gcsPath[len("gs://"):]
+	slashIndex := strings.Index(fullPath, "/")
+	bucket := fullPath[0:slashIndex]
+	key := fullPath[slashIndex+1:]
+
+	return bucket, key, nil
+}
+
+func IsValidGCSPath(gcsPath string) bool {
+	if !strings.HasPrefix(gcsPath, "gs://") {
+		return false
+	}
+	parts := strings.Split(gcsPath[5:], "/")
+	if len(parts) < 2 {
+		return false
+	}
+	if parts[0] == "" || parts[1] == "" {
+		return false
+	}
+	return true
+}
+
+func objectURL(bucket string, key string) string {
+	return _gcsAPIURL + "/b/" + url.PathEscape(bucket) + "/o/" + url.PathEscape(key)
+}
+
+// doGCSRequest returns the response's body; ErrorGCSFileNotFound is returned for 404 responses
+func (c *Client) doGCSRequest(method string, reqURL string, body io.Reader, contentType string, gcsPath string) ([]byte, error) {
+	req, err := http.NewRequest(method, reqURL, body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, gcsPath)
+	}
+	defer resp.Body.Close()
+
+	respBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, gcsPath)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrorGCSFileNotFound(gcsPath)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, ErrorGCSRequest(gcsPath, resp.StatusCode, string(respBytes))
+	}
+
+	return respBytes, nil
+}
+
+func (c *Client) UploadBytesToGCS(data []byte, bucket string, key string) error {
+	reqURL := _gcsUploadAPIURL + "/b/" + url.PathEscape(bucket) + "/o?uploadType=media&name=" + url.QueryEscape(key)
+	_, err := c.doGCSRequest(http.MethodPost, reqURL, bytes.NewReader(data), "application/octet-stream", GCSPath(bucket, key))
+	return err
+}
+
+func (c *Client) UploadStringToGCS(str string, bucket string, key string) error {
+	return c.UploadBytesToGCS([]byte(str), bucket, key)
+}
+
+func (c *Client) UploadMsgpackToGCS(obj interface{}, bucket string, key string) error {
+	msgpackBytes, err := msgpack.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return c.UploadBytesToGCS(msgpackBytes, bucket, key)
+}
+
+func (c *Client) ReadBytesFromGCS(bucket string, key string) ([]byte, error) {
+	return c.doGCSRequest(http.MethodGet, objectURL(bucket, key)+"?alt=media", nil, "", GCSPath(bucket, key))
+}
+
+func (c *Client) ReadMsgpackFromGCS(objPtr interface{}, bucket string, key string) error {
+	msgpackBytes, err := c.ReadBytesFromGCS(bucket, key)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(msgpack.Unmarshal(msgpackBytes, objPtr), GCSPath(bucket, key))
+}
+
+func (c *Client) IsGCSFile(bucket string, key string) (bool, error) {
+	_, err := c.doGCSRequest(http.MethodGet, objectURL(bucket, key), nil, "", GCSPath(bucket, key))
+	if err != nil {
+		if IsGCSFileNotFoundErr(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+type gcsObjectList struct {
+	Items []struct {
+		Name string `json:"name"`
+	} `json:"items"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// ListGCSPrefix returns the keys of the objects which begin with prefix (if maxResults is not nil, at most maxResults keys are returned)
+func (c *Client) ListGCSPrefix(bucket string, prefix string, maxResults *int64) ([]string, error) {
+	var keys []string
+	pageToken := ""
+
+	for {
+		query := url.Values{}
+		query.Set("prefix", prefix)
+		query.Set("fields", "items(name),nextPageToken")
+		if maxResults != nil {
+			query.Set("maxResults", s.Int64(*maxResults-int64(len(keys))))
+		}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+
+		reqURL := _gcsAPIURL + "/b/" + url.PathEscape(bucket) + "/o?" + query.Encode()
+		respBytes, err := c.doGCSRequest(http.MethodGet, reqURL, nil, "", GCSPath(bucket, prefix))
+		if err != nil {
+			return nil, err
+		}
+
+		var objectList gcsObjectList
+		if err := json.Unmarshal(respBytes, &objectList); err != nil {
+			return nil, errors.Wrap(err, GCSPath(bucket, prefix))
+		}
+		for _, item := range objectList.Items {
+			keys = append(keys, item.Name)
+		}
+
+		if objectList.NextPageToken == "" || (maxResults != nil && int64(len(keys)) >= *maxResults) {
+			return keys, nil
+		}
+		pageToken = objectList.NextPageToken
+	}
+}
+
+// DeleteGCSFile does not return an error if the file does not exist
+func (c *Client) DeleteGCSFile(bucket string, key string) error {
+	_, err := c.doGCSRequest(http.MethodDelete, objectURL(bucket, key), nil, "", GCSPath(bucket, key))
+	if err != nil && !IsGCSFileNotFoundErr(err) {
+		return err
+	}
+	return nil
+}
+
+func (c *Client) DeleteGCSPrefix(bucket string, prefix string) error {
+	keys, err := c.ListGCSPrefix(bucket, prefix, nil)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := c.DeleteGCSFile(bucket, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	istioauthentication "istio.io/api/authentication/v1alpha1"
+	istioclientauthentication "istio.io/client-go/pkg/apis/authentication/v1alpha1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _authenticationPolicyTypeMeta = kmeta.TypeMeta{
+	APIVersion: "v1alpha1",
+	Kind:       "Policy",
+}
+
+type AuthenticationPolicySpec struct {
+	Name        string
+	ServiceName string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// AuthenticationPolicy requires strict mutual TLS for all requests to the service
+func AuthenticationPolicy(spec *AuthenticationPolicySpec) *istioclientauthentication.Policy {
+	return &istioclientauthentication.Policy{
+		TypeMeta: _authenticationPolicyTypeMeta,
+		ObjectMeta: kmeta.ObjectMeta{
+			Name:        spec.Name,
+			Labels:      spec.Labels,
+			Annotations: spec.Annotations,
+		},
+		Spec: istioauthentication.Policy{
+			Targets: []*istioauthentication.TargetSelector{
+				{
+					Name: spec.ServiceName,
+				},
+			},
+			Peers: []*istioauthentication.PeerAuthenticationMethod{
+				{
+					Params: &istioauthentication.PeerAuthenticationMethod_Mtls{
+						Mtls: &istioauthentication.MutualTls{
+							Mode: istioauthentication.MutualTls_STRICT,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (c *Client) CreateAuthenticationPolicy(policy *istioclientauthentication.Policy) (*istioclientauthentication.Policy, error) {
+	policy.TypeMeta = _authenticationPolicyTypeMeta
+	policy, err := c.authenticationPolicyClient.Create(policy)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return policy, nil
+}
+
+func (c *Client) UpdateAuthenticationPolicy(existing, updated *istioclientauthentication.Policy) (*istioclientauthentication.Policy, error) {
+	updated.TypeMeta = _authenticationPolicyTypeMeta
+	updated.ResourceVersion = existing.ResourceVersion
+
+	policy, err := c.authenticationPolicyClient.Update(updated)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return policy, nil
+}
+
+func (c *Client) ApplyAuthenticationPolicy(policy *istioclientauthentication.Policy) (*istioclientauthentication.Policy, error) {
+	existing, err := c.GetAuthenticationPolicy(policy.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreateAuthenticationPolicy(policy)
+	}
+	return c.UpdateAuthenticationPolicy(existing, policy)
+}
+
+func (c *Client) GetAuthenticationPolicy(name string) (*istioclientauthentication.Policy, error) {
+	policy, err := c.authenticationPolicyClient.Get(name, kmeta.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	policy.TypeMeta = _authenticationPolicyTypeMeta
+	return policy, nil
+}
+
+func (c *Client) DeleteAuthenticationPolicy(name string) (bool, error) {
+	err := c.authenticationPolicyClient.Delete(name, _deleteOpts)
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	istiosecurity "istio.io/api/security/v1beta1"
+	istiotype "istio.io/api/type/v1beta1"
+	istioclientsecurity "istio.io/client-go/pkg/apis/security/v1beta1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _authorizationPolicyTypeMeta = kmeta.TypeMeta{
+	APIVersion: "v1beta1",
+	Kind:       "AuthorizationPolicy",
+}
+
+type AuthorizationPolicySpec struct {
+	Name        string
+	Selector    map[string]string
+	Principals  []string // the workload identities which may send requests to the selected pods
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// AuthorizationPolicy only allows requests to the selected pods from the principals
+func AuthorizationPolicy(spec *AuthorizationPolicySpec) *istioclientsecurity.AuthorizationPolicy {
+	return &istioclientsecurity.AuthorizationPolicy{
+		TypeMeta: _authorizationPolicyTypeMeta,
+		ObjectMeta: kmeta.ObjectMeta{
+			Name:        spec.Name,
+			Labels:      spec.Labels,
+			Annotations: spec.Annotations,
+		},
+		Spec: istiosecurity.AuthorizationPolicy{
+			Selector: &istiotype.WorkloadSelector{
+				MatchLabels: spec.Selector,
+			},
+			Rules: []*istiosecurity.Rule{
+				{
+					From: []*istiosecurity.Rule_From{
+						{
+							Source: &istiosecurity.Source{
+								Principals: spec.Principals,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (c *Client) CreateAuthorizationPolicy(policy *istioclientsecurity.AuthorizationPolicy) (*istioclientsecurity.AuthorizationPolicy, error) {
+	policy.TypeMeta = _authorizationPolicyTypeMeta
+	policy, err := c.authorizationPolicyClient.Create(policy)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return policy, nil
+}
+
+func (c *Client) UpdateAuthorizationPolicy(existing, updated *istioclientsecurity.AuthorizationPolicy) (*istioclientsecurity.AuthorizationPolicy, error) {
+	updated.TypeMeta = _authorizationPolicyTypeMeta
+	updated.ResourceVersion = existing.ResourceVersion
+
+	policy, err := c.authorizationPolicyClient.Update(updated)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return policy, nil
+}
+
+func (c *Client) ApplyAuthorizationPolicy(policy *istioclientsecurity.AuthorizationPolicy) (*istioclientsecurity.AuthorizationPolicy, error) {
+	existing, err := c.GetAuthorizationPolicy(policy.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreateAuthorizationPolicy(policy)
+	}
+	return c.UpdateAuthorizationPolicy(existing, policy)
+}
+
+func (c *Client) GetAuthorizationPolicy(name string) (*istioclientsecurity.AuthorizationPolicy, error) {
+	policy, err := c.authorizationPolicyClient.Get(name, kmeta.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	policy.TypeMeta = _authorizationPolicyTypeMeta
+	return policy, nil
+}
+
+func (c *Client) DeleteAuthorizationPolicy(name string) (bool, error) {
+	err := c.authorizationPolicyClient.Delete(name, _deleteOpts)
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
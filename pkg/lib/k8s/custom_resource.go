@@ -0,0 +1,117 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kruntime "k8s.io/apimachinery/pkg/runtime"
+	kschema "k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// custom resources are accessed via the dynamic client; use FromUnstructured and ToUnstructured to convert to and from typed structs
+
+func FromUnstructured(obj *kunstructured.Unstructured, objPtr interface{}) error {
+	if err := kruntime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, objPtr); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func ToUnstructured(obj interface{}) (*kunstructured.Unstructured, error) {
+	objMap, err := kruntime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &kunstructured.Unstructured{Object: objMap}, nil
+}
+
+func (c *Client) CreateCustomResource(resource kschema.GroupVersionResource, obj *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	created, err := c.dynamicClient.Resource(resource).Namespace(c.Namespace).Create(obj, kmeta.CreateOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return created, nil
+}
+
+func (c *Client) UpdateCustomResource(resource kschema.GroupVersionResource, obj *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	updated, err := c.dynamicClient.Resource(resource).Namespace(c.Namespace).Update(obj, kmeta.UpdateOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return updated, nil
+}
+
+// the custom resource definition must enable the status subresource
+func (c *Client) UpdateCustomResourceStatus(resource kschema.GroupVersionResource, obj *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	updated, err := c.dynamicClient.Resource(resource).Namespace(c.Namespace).UpdateStatus(obj, kmeta.UpdateOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return updated, nil
+}
+
+// ApplyCustomResource creates the resource, or replaces the spec of the existing resource (its status is not modified)
+func (c *Client) ApplyCustomResource(resource kschema.GroupVersionResource, obj *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	existing, err := c.GetCustomResource(resource, obj.GetName())
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreateCustomResource(resource, obj)
+	}
+
+	existing.Object["spec"] = obj.Object["spec"]
+	existing.SetLabels(obj.GetLabels())
+	existing.SetAnnotations(obj.GetAnnotations())
+	return c.UpdateCustomResource(resource, existing)
+}
+
+func (c *Client) GetCustomResource(resource kschema.GroupVersionResource, name string) (*kunstructured.Unstructured, error) {
+	obj, err := c.dynamicClient.Resource(resource).Namespace(c.Namespace).Get(name, kmeta.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return obj, nil
+}
+
+func (c *Client) DeleteCustomResource(resource kschema.GroupVersionResource, name string) (bool, error) {
+	err := c.dynamicClient.Resource(resource).Namespace(c.Namespace).Delete(name, _deleteOpts)
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+func (c *Client) ListCustomResources(resource kschema.GroupVersionResource, opts *kmeta.ListOptions) ([]kunstructured.Unstructured, error) {
+	if opts == nil {
+		opts = &kmeta.ListOptions{}
+	}
+	objList, err := c.dynamicClient.Resource(resource).Namespace(c.Namespace).List(*opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return objList.Items, nil
+}
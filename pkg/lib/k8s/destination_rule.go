@@ -0,0 +1,107 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	istionetworking "istio.io/api/networking/v1alpha3"
+	istioclientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _destinationRuleTypeMeta = kmeta.TypeMeta{
+	APIVersion: "v1alpha3",
+	Kind:       "DestinationRule",
+}
+
+type DestinationRuleSpec struct {
+	Name          string
+	ServiceName   string
+	TrafficPolicy *istionetworking.TrafficPolicy
+	Labels        map[string]string
+	Annotations   map[string]string
+}
+
+func DestinationRule(spec *DestinationRuleSpec) *istioclientnetworking.DestinationRule {
+	return &istioclientnetworking.DestinationRule{
+		TypeMeta: _destinationRuleTypeMeta,
+		ObjectMeta: kmeta.ObjectMeta{
+			Name:        spec.Name,
+			Labels:      spec.Labels,
+			Annotations: spec.Annotations,
+		},
+		Spec: istionetworking.DestinationRule{
+			Host:          spec.ServiceName,
+			TrafficPolicy: spec.TrafficPolicy,
+		},
+	}
+}
+
+func (c *Client) CreateDestinationRule(destinationRule *istioclientnetworking.DestinationRule) (*istioclientnetworking.DestinationRule, error) {
+	destinationRule.TypeMeta = _destinationRuleTypeMeta
+	destinationRule, err := c.destinationRuleClient.Create(destinationRule)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return destinationRule, nil
+}
+
+func (c *Client) UpdateDestinationRule(existing, updated *istioclientnetworking.DestinationRule) (*istioclientnetworking.DestinationRule, error) {
+	updated.TypeMeta = _destinationRuleTypeMeta
+	updated.ResourceVersion = existing.ResourceVersion
+
+	destinationRule, err := c.destinationRuleClient.Update(updated)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return destinationRule, nil
+}
+
+func (c *Client) ApplyDestinationRule(destinationRule *istioclientnetworking.DestinationRule) (*istioclientnetworking.DestinationRule, error) {
+	existing, err := c.GetDestinationRule(destinationRule.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreateDestinationRule(destinationRule)
+	}
+	return c.UpdateDestinationRule(existing, destinationRule)
+}
+
+func (c *Client) GetDestinationRule(name string) (*istioclientnetworking.DestinationRule, error) {
+	destinationRule, err := c.destinationRuleClient.Get(name, kmeta.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	destinationRule.TypeMeta = _destinationRuleTypeMeta
+	return destinationRule, nil
+}
+
+func (c *Client) DeleteDestinationRule(name string) (bool, error) {
+	err := c.destinationRuleClient.Delete(name, _deleteOpts)
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+// FieldDiff is a field whose value in the cluster differs from the value which would be applied
+type FieldDiff struct {
+	Path    string      `json:"path"`
+	Current interface{} `json:"current"` // nil if the field is not set in the cluster
+	Desired interface{} `json:"desired"` // nil if the field would be removed
+}
+
+func (fieldDiff FieldDiff) String() string {
+	switch {
+	case fieldDiff.Current == nil:
+		return fmt.Sprintf("+ %s: %s", fieldDiff.Path, s.ObjFlat(fieldDiff.Desired))
+	case fieldDiff.Desired == nil:
+		return fmt.Sprintf("- %s: %s", fieldDiff.Path, s.ObjFlat(fieldDiff.Current))
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", fieldDiff.Path, s.ObjFlat(fieldDiff.Current), s.ObjFlat(fieldDiff.Desired))
+	}
+}
+
+// Diff compares the fields which are set in desired against their values in current (either may be nil).
+// Fields which are only set in current are ignored, since they are populated by kubernetes (e.g. defaults and status),
+// except for list items and the entries of labels, annotations, and selectors, which are replaced on update
+func Diff(current interface{}, desired interface{}) ([]FieldDiff, error) {
+	currentObj, err := toDiffObj(current)
+	if err != nil {
+		return nil, err
+	}
+	desiredObj, err := toDiffObj(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []FieldDiff
+	diffValues("", currentObj, desiredObj, &diffs)
+	return diffs, nil
+}
+
+func toDiffObj(obj interface{}) (interface{}, error) {
+	if obj == nil || (reflect.ValueOf(obj).Kind() == reflect.Ptr && reflect.ValueOf(obj).IsNil()) {
+		return nil, nil
+	}
+
+	objBytes, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffObj interface{}
+	if err := json.Unmarshal(objBytes, &diffObj); err != nil {
+		return nil, err
+	}
+	return diffObj, nil
+}
+
+func diffValues(path string, current interface{}, desired interface{}, diffs *[]FieldDiff) {
+	if desired == nil {
+		return
+	}
+
+	switch desiredVal := desired.(type) {
+	case map[string]interface{}:
+		currentVal, _ := current.(map[string]interface{})
+		for _, key := range sortedKeys(desiredVal) {
+			diffValues(diffFieldPath(path, key), currentVal[key], desiredVal[key], diffs)
+		}
+		if _replacedMapFields[lastPathKey(path)] {
+			for _, key := range sortedKeys(currentVal) {
+				if _, ok := desiredVal[key]; !ok {
+					*diffs = append(*diffs, FieldDiff{Path: diffFieldPath(path, key), Current: currentVal[key]})
+				}
+			}
+		}
+
+	case []interface{}:
+		currentVal, _ := current.([]interface{})
+		for i := range desiredVal {
+			var currentItem interface{}
+			if i < len(currentVal) {
+				currentItem = currentVal[i]
+			}
+			diffValues(fmt.Sprintf("%s[%d]", path, i), currentItem, desiredVal[i], diffs)
+		}
+		for i := len(desiredVal); i < len(currentVal); i++ {
+			*diffs = append(*diffs, FieldDiff{Path: fmt.Sprintf("%s[%d]", path, i), Current: currentVal[i]})
+		}
+
+	default:
+		if !reflect.DeepEqual(current, desired) {
+			*diffs = append(*diffs, FieldDiff{Path: path, Current: current, Desired: desired})
+		}
+	}
+}
+
+// keys which contain dots or slashes (e.g. annotations) are quoted
+func diffFieldPath(path string, key string) string {
+	if strings.ContainsAny(key, "./") {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+var _replacedMapFields = map[string]bool{
+	"labels":       true,
+	"annotations":  true,
+	"selector":     true,
+	"matchLabels":  true,
+	"nodeSelector": true,
+}
+
+func lastPathKey(path string) string {
+	return path[strings.LastIndexAny(path, ".")+1:]
+}
+
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
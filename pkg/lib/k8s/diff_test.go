@@ -0,0 +1,81 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	current := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":            "api-test",
+			"resourceVersion": "12345",
+			"labels": map[string]interface{}{
+				"apiName": "test",
+				"apiID":   "a",
+			},
+			"annotations": map[string]interface{}{
+				"networking.cortex.dev/maintenance-message": "down",
+			},
+		},
+		"spec": map[string]interface{}{
+			"replicas": 2,
+			"containers": []interface{}{
+				map[string]interface{}{"name": "api", "image": "a:1", "terminationMessagePath": "/dev/termination-log"},
+				map[string]interface{}{"name": "request-monitor"},
+			},
+		},
+	}
+
+	desired := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name": "api-test",
+			"labels": map[string]interface{}{
+				"apiName": "test",
+				"apiID":   "b",
+			},
+			"annotations":       map[string]interface{}{},
+			"creationTimestamp": nil,
+		},
+		"spec": map[string]interface{}{
+			"replicas": 2,
+			"containers": []interface{}{
+				map[string]interface{}{"name": "api", "image": "a:2"},
+			},
+		},
+	}
+
+	diffs, err := Diff(current, desired)
+	require.NoError(t, err)
+	require.Equal(t, []FieldDiff{
+		{Path: `metadata.annotations["networking.cortex.dev/maintenance-message"]`, Current: "down"},
+		{Path: "metadata.labels.apiID", Current: "a", Desired: "b"},
+		{Path: "spec.containers[0].image", Current: "a:1", Desired: "a:2"},
+		{Path: "spec.containers[1]", Current: map[string]interface{}{"name": "request-monitor"}},
+	}, diffs)
+
+	diffs, err = Diff(nil, map[string]interface{}{"metadata": map[string]interface{}{"name": "api-test"}})
+	require.NoError(t, err)
+	require.Equal(t, []FieldDiff{{Path: "metadata.name", Desired: "api-test"}}, diffs)
+
+	diffs, err = Diff(current, current)
+	require.NoError(t, err)
+	require.Empty(t, diffs)
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"bytes"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/types"
+	istionetworking "istio.io/api/networking/v1alpha3"
+	istioclientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+)
+
+var _envoyFilterTypeMeta = kmeta.TypeMeta{
+	APIVersion: "v1alpha3",
+	Kind:       "EnvoyFilter",
+}
+
+type EnvoyFilterSpec struct {
+	Name           string
+	WorkloadLabels map[string]string
+	ConfigPatches  []*istionetworking.EnvoyFilter_EnvoyConfigObjectPatch
+	Labels         map[string]string
+	Annotations    map[string]string
+}
+
+func EnvoyFilter(spec *EnvoyFilterSpec) *istioclientnetworking.EnvoyFilter {
+	return &istioclientnetworking.EnvoyFilter{
+		TypeMeta: _envoyFilterTypeMeta,
+		ObjectMeta: kmeta.ObjectMeta{
+			Name:        spec.Name,
+			Labels:      spec.Labels,
+			Annotations: spec.Annotations,
+		},
+		Spec: istionetworking.EnvoyFilter{
+			WorkloadSelector: &istionetworking.WorkloadSelector{
+				Labels: spec.WorkloadLabels,
+			},
+			ConfigPatches: spec.ConfigPatches,
+		},
+	}
+}
+
+// EnvoyFilterPatchValue converts an arbitrary json-serializable object into the struct used for envoy filter patch values
+func EnvoyFilterPatchValue(obj interface{}) (*types.Struct, error) {
+	jsonBytes, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	value := &types.Struct{}
+	if err := jsonpb.Unmarshal(bytes.NewReader(jsonBytes), value); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return value, nil
+}
+
+func (c *Client) CreateEnvoyFilter(envoyFilter *istioclientnetworking.EnvoyFilter) (*istioclientnetworking.EnvoyFilter, error) {
+	envoyFilter.TypeMeta = _envoyFilterTypeMeta
+	envoyFilter, err := c.envoyFilterClient.Create(envoyFilter)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return envoyFilter, nil
+}
+
+func (c *Client) UpdateEnvoyFilter(existing, updated *istioclientnetworking.EnvoyFilter) (*istioclientnetworking.EnvoyFilter, error) {
+	updated.TypeMeta = _envoyFilterTypeMeta
+	updated.ResourceVersion = existing.ResourceVersion
+
+	envoyFilter, err := c.envoyFilterClient.Update(updated)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return envoyFilter, nil
+}
+
+func (c *Client) ApplyEnvoyFilter(envoyFilter *istioclientnetworking.EnvoyFilter) (*istioclientnetworking.EnvoyFilter, error) {
+	existing, err := c.GetEnvoyFilter(envoyFilter.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreateEnvoyFilter(envoyFilter)
+	}
+	return c.UpdateEnvoyFilter(existing, envoyFilter)
+}
+
+func (c *Client) GetEnvoyFilter(name string) (*istioclientnetworking.EnvoyFilter, error) {
+	envoyFilter, err := c.envoyFilterClient.Get(name, kmeta.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	envoyFilter.TypeMeta = _envoyFilterTypeMeta
+	return envoyFilter, nil
+}
+
+func (c *Client) DeleteEnvoyFilter(name string) (bool, error) {
+	err := c.envoyFilterClient.Delete(name, _deleteOpts)
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+func (c *Client) ListEnvoyFilters(opts *kmeta.ListOptions) ([]istioclientnetworking.EnvoyFilter, error) {
+	if opts == nil {
+		opts = &kmeta.ListOptions{}
+	}
+	envoyFilterList, err := c.envoyFilterClient.List(*opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i := range envoyFilterList.Items {
+		envoyFilterList.Items[i].TypeMeta = _envoyFilterTypeMeta
+	}
+	return envoyFilterList.Items, nil
+}
+
+func (c *Client) ListEnvoyFiltersByLabels(labels map[string]string) ([]istioclientnetworking.EnvoyFilter, error) {
+	opts := &kmeta.ListOptions{
+		LabelSelector: klabels.SelectorFromSet(labels).String(),
+	}
+	return c.ListEnvoyFilters(opts)
+}
+
+func (c *Client) ListEnvoyFiltersByLabel(labelKey string, labelValue string) ([]istioclientnetworking.EnvoyFilter, error) {
+	return c.ListEnvoyFiltersByLabels(map[string]string{labelKey: labelValue})
+}
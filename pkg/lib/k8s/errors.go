@@ -18,6 +18,7 @@ package k8s
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/cortexlabs/cortex/pkg/consts"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
@@ -30,6 +31,8 @@ const (
 	ErrParseLabel         = "k8s.parse_label"
 	ErrParseAnnotation    = "k8s.parse_annotation"
 	ErrParseQuantity      = "k8s.parse_quantity"
+
+	ErrInformerCacheSyncTimeout = "k8s.informer_cache_sync_timeout"
 )
 
 func ErrorLabelNotFound(labelName string) error {
@@ -60,6 +63,13 @@ func ErrorParseAnnotation(annotationName string, annotationVal string, desiredTy
 	})
 }
 
+func ErrorInformerCacheSyncTimeout(timeout time.Duration) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInformerCacheSyncTimeout,
+		Message: fmt.Sprintf("the kubernetes resources could not be loaded within %s", timeout),
+	})
+}
+
 func ErrorParseQuantity(qtyStr string) error {
 	return errors.WithStack(&errors.Error{
 		Kind:    ErrParseQuantity,
@@ -0,0 +1,81 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"sort"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	kcore "k8s.io/api/core/v1"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kfields "k8s.io/apimachinery/pkg/fields"
+)
+
+var _eventTypeMeta = kmeta.TypeMeta{
+	APIVersion: "v1",
+	Kind:       "Event",
+}
+
+func (c *Client) ListEvents(opts *kmeta.ListOptions) ([]kcore.Event, error) {
+	if opts == nil {
+		opts = &kmeta.ListOptions{}
+	}
+	eventList, err := c.eventClient.List(*opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i := range eventList.Items {
+		eventList.Items[i].TypeMeta = _eventTypeMeta
+	}
+	return eventList.Items, nil
+}
+
+func (c *Client) ListEventsByFields(fields map[string]string) ([]kcore.Event, error) {
+	opts := &kmeta.ListOptions{
+		FieldSelector: kfields.SelectorFromSet(fields).String(),
+	}
+	return c.ListEvents(opts)
+}
+
+// ListEventsByInvolvedObjectKind returns the events in the namespace which involve an object of the given kind, sorted from oldest to newest
+func (c *Client) ListEventsByInvolvedObjectKind(kind string) ([]kcore.Event, error) {
+	events, err := c.ListEventsByFields(map[string]string{"involvedObject.kind": kind})
+	if err != nil {
+		return nil, err
+	}
+	SortEvents(events)
+	return events, nil
+}
+
+// EventTime returns the last time that an event occurred
+func EventTime(event *kcore.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// SortEvents sorts events from oldest to newest
+func SortEvents(events []kcore.Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return EventTime(&events[i]).Before(EventTime(&events[j]))
+	})
+}
@@ -31,10 +31,11 @@ var _jobTypeMeta = kmeta.TypeMeta{
 }
 
 type JobSpec struct {
-	Name        string
-	PodSpec     PodSpec
-	Labels      map[string]string
-	Annotations map[string]string
+	Name         string
+	PodSpec      PodSpec
+	Labels       map[string]string
+	Annotations  map[string]string
+	BackoffLimit int32 // the number of times the pod is retried after it fails
 }
 
 func Job(spec *JobSpec) *kbatch.Job {
@@ -43,7 +44,7 @@ func Job(spec *JobSpec) *kbatch.Job {
 	}
 
 	parallelism := int32(1)
-	backoffLimit := int32(0)
+	backoffLimit := spec.BackoffLimit
 	completions := int32(1)
 
 	job := &kbatch.Job{
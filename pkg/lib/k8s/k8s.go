@@ -24,7 +24,9 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/random"
 	istioclient "istio.io/client-go/pkg/clientset/versioned"
+	istioauthenticationclient "istio.io/client-go/pkg/clientset/versioned/typed/authentication/v1alpha1"
 	istionetworkingclient "istio.io/client-go/pkg/clientset/versioned/typed/networking/v1alpha3"
+	istiosecurityclient "istio.io/client-go/pkg/clientset/versioned/typed/security/v1beta1"
 	kresource "k8s.io/apimachinery/pkg/api/resource"
 	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kclientdynamic "k8s.io/client-go/dynamic"
@@ -49,19 +51,30 @@ var (
 )
 
 type Client struct {
-	RestConfig           *kclientrest.Config
-	clientset            *kclientset.Clientset
-	dynamicClient        kclientdynamic.Interface
-	podClient            kclientcore.PodInterface
-	nodeClient           kclientcore.NodeInterface
-	serviceClient        kclientcore.ServiceInterface
-	configMapClient      kclientcore.ConfigMapInterface
-	deploymentClient     kclientapps.DeploymentInterface
-	jobClient            kclientbatch.JobInterface
-	ingressClient        kclientextensions.IngressInterface
-	hpaClient            kclientautoscaling.HorizontalPodAutoscalerInterface
-	virtualServiceClient istionetworkingclient.VirtualServiceInterface
-	Namespace            string
+	RestConfig                 *kclientrest.Config
+	clientset                  *kclientset.Clientset
+	dynamicClient              kclientdynamic.Interface
+	istioClient                *istioclient.Clientset
+	namespaceClient            kclientcore.NamespaceInterface
+	podClient                  kclientcore.PodInterface
+	nodeClient                 kclientcore.NodeInterface
+	serviceClient              kclientcore.ServiceInterface
+	configMapClient            kclientcore.ConfigMapInterface
+	secretClient               kclientcore.SecretInterface
+	serviceAccountClient       kclientcore.ServiceAccountInterface
+	pvcClient                  kclientcore.PersistentVolumeClaimInterface
+	eventClient                kclientcore.EventInterface
+	deploymentClient           kclientapps.DeploymentInterface
+	statefulSetClient          kclientapps.StatefulSetInterface
+	jobClient                  kclientbatch.JobInterface
+	ingressClient              kclientextensions.IngressInterface
+	hpaClient                  kclientautoscaling.HorizontalPodAutoscalerInterface
+	virtualServiceClient       istionetworkingclient.VirtualServiceInterface
+	envoyFilterClient          istionetworkingclient.EnvoyFilterInterface
+	destinationRuleClient      istionetworkingclient.DestinationRuleInterface
+	authenticationPolicyClient istioauthenticationclient.PolicyInterface
+	authorizationPolicyClient  istiosecurityclient.AuthorizationPolicyInterface
+	Namespace                  string
 }
 
 func New(namespace string, inCluster bool) (*Client, error) {
@@ -90,23 +103,51 @@ func New(namespace string, inCluster bool) (*Client, error) {
 		return nil, errors.Wrap(err, "kubeconfig")
 	}
 
-	istioClient, err := istioclient.NewForConfig(client.RestConfig)
+	client.istioClient, err = istioclient.NewForConfig(client.RestConfig)
 	if err != nil {
 		return nil, errors.Wrap(err, "kubeconfig")
 	}
-	client.virtualServiceClient = istioClient.NetworkingV1alpha3().VirtualServices(namespace)
-
-	client.podClient = client.clientset.CoreV1().Pods(namespace)
-	client.nodeClient = client.clientset.CoreV1().Nodes()
-	client.serviceClient = client.clientset.CoreV1().Services(namespace)
-	client.configMapClient = client.clientset.CoreV1().ConfigMaps(namespace)
-	client.deploymentClient = client.clientset.AppsV1().Deployments(namespace)
-	client.jobClient = client.clientset.BatchV1().Jobs(namespace)
-	client.ingressClient = client.clientset.ExtensionsV1beta1().Ingresses(namespace)
-	client.hpaClient = client.clientset.AutoscalingV2beta2().HorizontalPodAutoscalers(namespace)
+
+	client.initNamespacedClients()
 	return client, nil
 }
 
+// WithNamespace returns a client which shares this client's connection, but operates in a different namespace
+func (c *Client) WithNamespace(namespace string) *Client {
+	client := &Client{
+		RestConfig:    c.RestConfig,
+		clientset:     c.clientset,
+		dynamicClient: c.dynamicClient,
+		istioClient:   c.istioClient,
+		Namespace:     namespace,
+	}
+	client.initNamespacedClients()
+	return client
+}
+
+func (c *Client) initNamespacedClients() {
+	c.virtualServiceClient = c.istioClient.NetworkingV1alpha3().VirtualServices(c.Namespace)
+	c.envoyFilterClient = c.istioClient.NetworkingV1alpha3().EnvoyFilters(c.Namespace)
+	c.destinationRuleClient = c.istioClient.NetworkingV1alpha3().DestinationRules(c.Namespace)
+	c.authenticationPolicyClient = c.istioClient.AuthenticationV1alpha1().Policies(c.Namespace)
+	c.authorizationPolicyClient = c.istioClient.SecurityV1beta1().AuthorizationPolicies(c.Namespace)
+
+	c.namespaceClient = c.clientset.CoreV1().Namespaces()
+	c.podClient = c.clientset.CoreV1().Pods(c.Namespace)
+	c.nodeClient = c.clientset.CoreV1().Nodes()
+	c.serviceClient = c.clientset.CoreV1().Services(c.Namespace)
+	c.configMapClient = c.clientset.CoreV1().ConfigMaps(c.Namespace)
+	c.secretClient = c.clientset.CoreV1().Secrets(c.Namespace)
+	c.serviceAccountClient = c.clientset.CoreV1().ServiceAccounts(c.Namespace)
+	c.pvcClient = c.clientset.CoreV1().PersistentVolumeClaims(c.Namespace)
+	c.eventClient = c.clientset.CoreV1().Events(c.Namespace)
+	c.deploymentClient = c.clientset.AppsV1().Deployments(c.Namespace)
+	c.statefulSetClient = c.clientset.AppsV1().StatefulSets(c.Namespace)
+	c.jobClient = c.clientset.BatchV1().Jobs(c.Namespace)
+	c.ingressClient = c.clientset.ExtensionsV1beta1().Ingresses(c.Namespace)
+	c.hpaClient = c.clientset.AutoscalingV2beta2().HorizontalPodAutoscalers(c.Namespace)
+}
+
 // to be safe, k8s sometimes needs all characters to be lower case, and the first to be a letter
 func RandomName() string {
 	return random.LowercaseLetters(1) + random.LowercaseString(62)
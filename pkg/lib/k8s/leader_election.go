@@ -0,0 +1,65 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+const (
+	_leaseDuration = 15 * time.Second
+	_renewDeadline = 10 * time.Second
+	_retryPeriod   = 2 * time.Second
+)
+
+// RunLeaderElection campaigns (as identity) for the lease named name in the client's namespace in the background;
+// onStartedLeading is called once the lease is acquired, and onStoppedLeading is called if it is lost
+func (c *Client) RunLeaderElection(name string, identity string, onStartedLeading func(), onStoppedLeading func()) (*leaderelection.LeaderElector, error) {
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.LeaseLock{
+			LeaseMeta: kmeta.ObjectMeta{
+				Name:      name,
+				Namespace: c.Namespace,
+			},
+			Client: c.clientset.CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{
+				Identity: identity,
+			},
+		},
+		LeaseDuration: _leaseDuration,
+		RenewDeadline: _renewDeadline,
+		RetryPeriod:   _retryPeriod,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(context.Context) { onStartedLeading() },
+			OnStoppedLeading: onStoppedLeading,
+		},
+		Name: name,
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	go elector.Run(context.Background())
+
+	return elector, nil
+}
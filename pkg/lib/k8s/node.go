@@ -28,6 +28,15 @@ var _nodeTypeMeta = kmeta.TypeMeta{
 	Kind:       "Node",
 }
 
+func (c *Client) UpdateNode(node *kcore.Node) (*kcore.Node, error) {
+	node.TypeMeta = _nodeTypeMeta
+	node, err := c.nodeClient.Update(node)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return node, nil
+}
+
 func (c *Client) ListNodes(opts *kmeta.ListOptions) ([]kcore.Node, error) {
 	if opts == nil {
 		opts = &kmeta.ListOptions{}
@@ -18,12 +18,15 @@ package k8s
 
 import (
 	"bytes"
+	"io"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
 	kcore "k8s.io/api/core/v1"
+	kpolicy "k8s.io/api/policy/v1beta1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	klabels "k8s.io/apimachinery/pkg/labels"
@@ -287,6 +290,36 @@ func (c *Client) DeletePod(name string) (bool, error) {
 	return true, nil
 }
 
+// EvictPod evicts the pod through the eviction API, which respects pod disruption budgets; returns false if the
+// eviction was blocked by a disruption budget (in which case it can be retried later)
+func (c *Client) EvictPod(name string) (bool, error) {
+	err := c.podClient.Evict(&kpolicy.Eviction{
+		ObjectMeta: kmeta.ObjectMeta{
+			Name:      name,
+			Namespace: c.Namespace,
+		},
+	})
+	if kerrors.IsNotFound(err) {
+		return true, nil
+	}
+	if kerrors.IsTooManyRequests(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+// StreamPodLogs returns the logs of one of the pod's containers; the caller must close the stream
+func (c *Client) StreamPodLogs(podName string, opts *kcore.PodLogOptions) (io.ReadCloser, error) {
+	stream, err := c.podClient.GetLogs(podName, opts).Stream()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return stream, nil
+}
+
 func (c *Client) ListPods(opts *kmeta.ListOptions) ([]kcore.Pod, error) {
 	if opts == nil {
 		opts = &kmeta.ListOptions{}
@@ -391,3 +424,22 @@ func (c *Client) Exec(podName string, containerName string, command []string) (s
 	return buf.String(), nil
 
 }
+
+// PodContainersEqual returns true if both pod specs have the same containers, with the same images, commands, and args
+func PodContainersEqual(podSpec1, podSpec2 *kcore.PodSpec) bool {
+	if len(podSpec1.Containers) != len(podSpec2.Containers) {
+		return false
+	}
+
+	for i := range podSpec1.Containers {
+		container1, container2 := podSpec1.Containers[i], podSpec2.Containers[i]
+		if container1.Name != container2.Name ||
+			container1.Image != container2.Image ||
+			!slices.StrSlicesEqual(container1.Command, container2.Command) ||
+			!slices.StrSlicesEqual(container1.Args, container2.Args) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	kcore "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _pvcTypeMeta = kmeta.TypeMeta{
+	APIVersion: "v1",
+	Kind:       "PersistentVolumeClaim",
+}
+
+type PVCSpec struct {
+	Name             string
+	StorageClassName *string // if nil, the cluster's default storage class is used
+	AccessMode       kcore.PersistentVolumeAccessMode
+	Size             kresource.Quantity
+	Labels           map[string]string
+	Annotations      map[string]string
+}
+
+func PVC(spec *PVCSpec) *kcore.PersistentVolumeClaim {
+	pvc := &kcore.PersistentVolumeClaim{
+		TypeMeta: _pvcTypeMeta,
+		ObjectMeta: kmeta.ObjectMeta{
+			Name:        spec.Name,
+			Labels:      spec.Labels,
+			Annotations: spec.Annotations,
+		},
+		Spec: kcore.PersistentVolumeClaimSpec{
+			AccessModes:      []kcore.PersistentVolumeAccessMode{spec.AccessMode},
+			StorageClassName: spec.StorageClassName,
+			Resources: kcore.ResourceRequirements{
+				Requests: kcore.ResourceList{
+					kcore.ResourceStorage: spec.Size,
+				},
+			},
+		},
+	}
+	return pvc
+}
+
+func (c *Client) CreatePVC(pvc *kcore.PersistentVolumeClaim) (*kcore.PersistentVolumeClaim, error) {
+	pvc.TypeMeta = _pvcTypeMeta
+	pvc, err := c.pvcClient.Create(pvc)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return pvc, nil
+}
+
+func (c *Client) UpdatePVC(pvc *kcore.PersistentVolumeClaim) (*kcore.PersistentVolumeClaim, error) {
+	pvc.TypeMeta = _pvcTypeMeta
+	pvc, err := c.pvcClient.Update(pvc)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return pvc, nil
+}
+
+func (c *Client) GetPVC(name string) (*kcore.PersistentVolumeClaim, error) {
+	pvc, err := c.pvcClient.Get(name, kmeta.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	pvc.TypeMeta = _pvcTypeMeta
+	return pvc, nil
+}
+
+func (c *Client) DeletePVC(name string) (bool, error) {
+	err := c.pvcClient.Delete(name, _deleteOpts)
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
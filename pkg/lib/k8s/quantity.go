@@ -127,6 +127,18 @@ func SplitInThree(quantity *kresource.Quantity) (*kresource.Quantity, *kresource
 	return q1, q2, q3
 }
 
+// SplitInN splits the quantity into n parts (n must be at least 1); the first part receives any remainder
+func SplitInN(quantity *kresource.Quantity, n int) []*kresource.Quantity {
+	milliValue := quantity.MilliValue()
+	partMilliValue := milliValue / int64(n)
+	quantities := make([]*kresource.Quantity, n)
+	quantities[0] = kresource.NewMilliQuantity(milliValue-int64(n-1)*partMilliValue, kresource.DecimalSI)
+	for i := 1; i < n; i++ {
+		quantities[i] = kresource.NewMilliQuantity(partMilliValue, kresource.DecimalSI)
+	}
+	return quantities
+}
+
 func (quantity *Quantity) Sub(q2 kresource.Quantity) {
 	quantity.Quantity.Sub(q2)
 	quantity.UserString = ""
@@ -37,6 +37,9 @@ type ServiceSpec struct {
 	Selector    map[string]string
 	Labels      map[string]string
 	Annotations map[string]string
+
+	// a headless service resolves to the addresses of its pods (including pods which aren't ready), for peer discovery
+	Headless bool
 }
 
 func Service(spec *ServiceSpec) *kcore.Service {
@@ -61,6 +64,10 @@ func Service(spec *ServiceSpec) *kcore.Service {
 			},
 		},
 	}
+	if spec.Headless {
+		service.Spec.ClusterIP = kcore.ClusterIPNone
+		service.Spec.PublishNotReadyAddresses = true
+	}
 	return service
 }
 
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	kcore "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _serviceAccountTypeMeta = kmeta.TypeMeta{
+	APIVersion: "v1",
+	Kind:       "ServiceAccount",
+}
+
+type ServiceAccountSpec struct {
+	Name        string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+func ServiceAccount(spec *ServiceAccountSpec) *kcore.ServiceAccount {
+	return &kcore.ServiceAccount{
+		TypeMeta: _serviceAccountTypeMeta,
+		ObjectMeta: kmeta.ObjectMeta{
+			Name:        spec.Name,
+			Labels:      spec.Labels,
+			Annotations: spec.Annotations,
+		},
+	}
+}
+
+func (c *Client) CreateServiceAccount(serviceAccount *kcore.ServiceAccount) (*kcore.ServiceAccount, error) {
+	serviceAccount.TypeMeta = _serviceAccountTypeMeta
+	serviceAccount, err := c.serviceAccountClient.Create(serviceAccount)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return serviceAccount, nil
+}
+
+func (c *Client) UpdateServiceAccount(serviceAccount *kcore.ServiceAccount) (*kcore.ServiceAccount, error) {
+	serviceAccount.TypeMeta = _serviceAccountTypeMeta
+	serviceAccount, err := c.serviceAccountClient.Update(serviceAccount)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return serviceAccount, nil
+}
+
+// ApplyServiceAccount keeps the existing service account's secrets (which are populated by kubernetes)
+func (c *Client) ApplyServiceAccount(serviceAccount *kcore.ServiceAccount) (*kcore.ServiceAccount, error) {
+	existing, err := c.GetServiceAccount(serviceAccount.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreateServiceAccount(serviceAccount)
+	}
+	existing.Labels = serviceAccount.Labels
+	existing.Annotations = serviceAccount.Annotations
+	return c.UpdateServiceAccount(existing)
+}
+
+func (c *Client) GetServiceAccount(name string) (*kcore.ServiceAccount, error) {
+	serviceAccount, err := c.serviceAccountClient.Get(name, kmeta.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	serviceAccount.TypeMeta = _serviceAccountTypeMeta
+	return serviceAccount, nil
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	kapps "k8s.io/api/apps/v1"
+	kcore "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+)
+
+var _statefulSetTypeMeta = kmeta.TypeMeta{
+	APIVersion: "apps/v1",
+	Kind:       "StatefulSet",
+}
+
+type StatefulSetSpec struct {
+	Name        string
+	Replicas    int32
+	ServiceName string // the headless service which gives the pods their network identities
+	PodSpec     PodSpec
+	Selector    map[string]string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// StatefulSet creates a stateful set whose pods are started and stopped in parallel (rather than in order)
+func StatefulSet(spec *StatefulSetSpec) *kapps.StatefulSet {
+	if spec.PodSpec.Name == "" {
+		spec.PodSpec.Name = spec.Name
+	}
+	if spec.Selector == nil {
+		spec.Selector = spec.PodSpec.Labels
+	}
+
+	statefulSet := &kapps.StatefulSet{
+		TypeMeta: _statefulSetTypeMeta,
+		ObjectMeta: kmeta.ObjectMeta{
+			Name:        spec.Name,
+			Labels:      spec.Labels,
+			Annotations: spec.Annotations,
+		},
+		Spec: kapps.StatefulSetSpec{
+			Replicas:            &spec.Replicas,
+			ServiceName:         spec.ServiceName,
+			PodManagementPolicy: kapps.ParallelPodManagement,
+			UpdateStrategy: kapps.StatefulSetUpdateStrategy{
+				Type: kapps.RollingUpdateStatefulSetStrategyType,
+			},
+			Template: kcore.PodTemplateSpec{
+				ObjectMeta: kmeta.ObjectMeta{
+					Name:        spec.PodSpec.Name,
+					Labels:      spec.PodSpec.Labels,
+					Annotations: spec.PodSpec.Annotations,
+				},
+				Spec: spec.PodSpec.K8sPodSpec,
+			},
+			Selector: &kmeta.LabelSelector{
+				MatchLabels: spec.Selector,
+			},
+		},
+	}
+	return statefulSet
+}
+
+func (c *Client) CreateStatefulSet(statefulSet *kapps.StatefulSet) (*kapps.StatefulSet, error) {
+	statefulSet.TypeMeta = _statefulSetTypeMeta
+	statefulSet, err := c.statefulSetClient.Create(statefulSet)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return statefulSet, nil
+}
+
+func (c *Client) UpdateStatefulSet(statefulSet *kapps.StatefulSet) (*kapps.StatefulSet, error) {
+	statefulSet.TypeMeta = _statefulSetTypeMeta
+	statefulSet, err := c.statefulSetClient.Update(statefulSet)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return statefulSet, nil
+}
+
+func (c *Client) ApplyStatefulSet(statefulSet *kapps.StatefulSet) (*kapps.StatefulSet, error) {
+	existing, err := c.GetStatefulSet(statefulSet.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreateStatefulSet(statefulSet)
+	}
+	statefulSet.ResourceVersion = existing.ResourceVersion
+	return c.UpdateStatefulSet(statefulSet)
+}
+
+func (c *Client) GetStatefulSet(name string) (*kapps.StatefulSet, error) {
+	statefulSet, err := c.statefulSetClient.Get(name, kmeta.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	statefulSet.TypeMeta = _statefulSetTypeMeta
+	return statefulSet, nil
+}
+
+func (c *Client) DeleteStatefulSet(name string) (bool, error) {
+	err := c.statefulSetClient.Delete(name, _deleteOpts)
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+func (c *Client) ListStatefulSets(opts *kmeta.ListOptions) ([]kapps.StatefulSet, error) {
+	if opts == nil {
+		opts = &kmeta.ListOptions{}
+	}
+	statefulSetList, err := c.statefulSetClient.List(*opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i := range statefulSetList.Items {
+		statefulSetList.Items[i].TypeMeta = _statefulSetTypeMeta
+	}
+	return statefulSetList.Items, nil
+}
+
+func (c *Client) ListStatefulSetsByLabel(labelKey string, labelValue string) ([]kapps.StatefulSet, error) {
+	opts := &kmeta.ListOptions{
+		LabelSelector: klabels.SelectorFromSet(map[string]string{labelKey: labelValue}).String(),
+	}
+	return c.ListStatefulSets(opts)
+}
@@ -17,9 +17,14 @@ limitations under the License.
 package k8s
 
 import (
+	"strings"
+	"time"
+
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/lib/urls"
+	gogotypes "github.com/gogo/protobuf/types"
 	istionetworking "istio.io/api/networking/v1alpha3"
 	istioclientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
@@ -41,6 +46,38 @@ type VirtualServiceSpec struct {
 	Rewrite     *string
 	Labels      map[string]string
 	Annotations map[string]string
+	// if set, the traffic is split by weight between ServiceName (which receives the remainder) and these services
+	WeightedDestinations []WeightedDestination
+	CORS                 *CORSPolicy
+	RequestHeaders       *HeaderOperations // applied to requests before they are sent to the destinations
+	ResponseHeaders      *HeaderOperations // applied to responses before they are returned to the client
+	// routes for endpoints other than Path, which send requests to the same destinations as Path
+	AdditionalRoutes []VirtualServiceRoute
+}
+
+type VirtualServiceRoute struct {
+	Path    string
+	Methods []string // all methods are matched if empty
+	Rewrite *string
+}
+
+type CORSPolicy struct {
+	AllowOrigins []string
+	AllowMethods []string
+	AllowHeaders []string
+	MaxAge       time.Duration
+}
+
+type HeaderOperations struct {
+	Set    map[string]string
+	Add    map[string]string
+	Remove []string
+}
+
+type WeightedDestination struct {
+	ServiceName string
+	ServicePort int32
+	Weight      int32 // percentage
 }
 
 func VirtualService(spec *VirtualServiceSpec) *istioclientnetworking.VirtualService {
@@ -80,15 +117,112 @@ func VirtualService(spec *VirtualServiceSpec) *istioclientnetworking.VirtualServ
 		},
 	}
 
+	if len(spec.WeightedDestinations) > 0 {
+		remainingWeight := int32(100)
+		for _, destination := range spec.WeightedDestinations {
+			virtualService.Spec.Http[0].Route = append(virtualService.Spec.Http[0].Route, &istionetworking.HTTPRouteDestination{
+				Destination: &istionetworking.Destination{
+					Host: destination.ServiceName,
+					Port: &istionetworking.PortSelector{
+						Number: uint32(destination.ServicePort),
+					},
+				},
+				Weight: destination.Weight,
+			})
+			remainingWeight -= destination.Weight
+		}
+		virtualService.Spec.Http[0].Route[0].Weight = remainingWeight
+	}
+
+	if spec.CORS != nil {
+		virtualService.Spec.Http[0].CorsPolicy = &istionetworking.CorsPolicy{
+			AllowOrigin:  spec.CORS.AllowOrigins,
+			AllowMethods: spec.CORS.AllowMethods,
+			AllowHeaders: spec.CORS.AllowHeaders,
+			MaxAge:       gogotypes.DurationProto(spec.CORS.MaxAge),
+		}
+	}
+
+	if spec.RequestHeaders != nil || spec.ResponseHeaders != nil {
+		virtualService.Spec.Http[0].Headers = &istionetworking.Headers{
+			Request:  headerOperations(spec.RequestHeaders),
+			Response: headerOperations(spec.ResponseHeaders),
+		}
+	}
+
 	if spec.Rewrite != nil && urls.CanonicalizeEndpoint(*spec.Rewrite) != urls.CanonicalizeEndpoint(spec.Path) {
 		virtualService.Spec.Http[0].Rewrite = &istionetworking.HTTPRewrite{
 			Uri: urls.CanonicalizeEndpoint(*spec.Rewrite),
 		}
 	}
 
+	// the route for Path is kept last, so that it can be found regardless of how many additional routes there are
+	if len(spec.AdditionalRoutes) > 0 {
+		var httpRoutes []*istionetworking.HTTPRoute
+		for i, route := range spec.AdditionalRoutes {
+			httpRoutes = append(httpRoutes, additionalHTTPRoute(i, route, virtualService.Spec.Http[0]))
+		}
+		virtualService.Spec.Http = append(httpRoutes, virtualService.Spec.Http[0])
+	}
+
 	return virtualService
 }
 
+func headerOperations(operations *HeaderOperations) *istionetworking.Headers_HeaderOperations {
+	if operations == nil {
+		return nil
+	}
+	return &istionetworking.Headers_HeaderOperations{
+		Set:    operations.Set,
+		Add:    operations.Add,
+		Remove: operations.Remove,
+	}
+}
+
+const _additionalRoutePrefix = "additional-endpoint-"
+
+func additionalHTTPRoute(index int, route VirtualServiceRoute, primaryRoute *istionetworking.HTTPRoute) *istionetworking.HTTPRoute {
+	uri := &istionetworking.StringMatch{
+		MatchType: &istionetworking.StringMatch_Exact{
+			Exact: urls.CanonicalizeEndpoint(route.Path),
+		},
+	}
+
+	var matches []*istionetworking.HTTPMatchRequest
+	if len(route.Methods) == 0 {
+		matches = append(matches, &istionetworking.HTTPMatchRequest{Uri: uri})
+	}
+	for _, method := range route.Methods {
+		matches = append(matches, &istionetworking.HTTPMatchRequest{
+			Uri: uri,
+			Method: &istionetworking.StringMatch{
+				MatchType: &istionetworking.StringMatch_Exact{Exact: method},
+			},
+		})
+	}
+
+	httpRoute := &istionetworking.HTTPRoute{
+		Name:       _additionalRoutePrefix + s.Int(index),
+		Match:      matches,
+		Route:      primaryRoute.Route,
+		CorsPolicy: primaryRoute.CorsPolicy,
+		Headers:    primaryRoute.Headers,
+	}
+
+	if route.Rewrite != nil && urls.CanonicalizeEndpoint(*route.Rewrite) != urls.CanonicalizeEndpoint(route.Path) {
+		httpRoute.Rewrite = &istionetworking.HTTPRewrite{
+			Uri: urls.CanonicalizeEndpoint(*route.Rewrite),
+		}
+	}
+
+	return httpRoute
+}
+
+// IsAdditionalVirtualServiceRoute returns whether the route was created from the spec's AdditionalRoutes
+func IsAdditionalVirtualServiceRoute(httpRoute *istionetworking.HTTPRoute) bool {
+	return strings.HasPrefix(httpRoute.Name, _additionalRoutePrefix)
+}
+
 func (c *Client) CreateVirtualService(virtualService *istioclientnetworking.VirtualService) (*istioclientnetworking.VirtualService, error) {
 	virtualService.TypeMeta = _virtualServiceTypeMeta
 	virtualService, err := c.virtualServiceClient.Create(virtualService)
@@ -18,6 +18,7 @@ package k8s
 
 import (
 	kcore "k8s.io/api/core/v1"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
 )
 
 func EmptyDirVolume(volumeName string) kcore.Volume {
@@ -29,9 +30,109 @@ func EmptyDirVolume(volumeName string) kcore.Volume {
 	}
 }
 
+// MemoryEmptyDirVolume returns a tmpfs-backed emptyDir volume, which counts towards the memory usage of the containers which write to it
+func MemoryEmptyDirVolume(volumeName string, sizeLimit *kresource.Quantity) kcore.Volume {
+	return kcore.Volume{
+		Name: volumeName,
+		VolumeSource: kcore.VolumeSource{
+			EmptyDir: &kcore.EmptyDirVolumeSource{
+				Medium:    kcore.StorageMediumMemory,
+				SizeLimit: sizeLimit,
+			},
+		},
+	}
+}
+
 func EmptyDirVolumeMount(volumeName string, mountPath string) kcore.VolumeMount {
 	return kcore.VolumeMount{
 		Name:      volumeName,
 		MountPath: mountPath,
 	}
 }
+
+func ConfigMapVolume(volumeName string, configMapName string) kcore.Volume {
+	return kcore.Volume{
+		Name: volumeName,
+		VolumeSource: kcore.VolumeSource{
+			ConfigMap: &kcore.ConfigMapVolumeSource{
+				LocalObjectReference: kcore.LocalObjectReference{
+					Name: configMapName,
+				},
+			},
+		},
+	}
+}
+
+func ConfigMapVolumeMount(volumeName string, mountPath string) kcore.VolumeMount {
+	return kcore.VolumeMount{
+		Name:      volumeName,
+		MountPath: mountPath,
+		ReadOnly:  true,
+	}
+}
+
+func PVCVolume(volumeName string, claimName string) kcore.Volume {
+	return kcore.Volume{
+		Name: volumeName,
+		VolumeSource: kcore.VolumeSource{
+			PersistentVolumeClaim: &kcore.PersistentVolumeClaimVolumeSource{
+				ClaimName: claimName,
+			},
+		},
+	}
+}
+
+func PVCVolumeMount(volumeName string, mountPath string) kcore.VolumeMount {
+	return kcore.VolumeMount{
+		Name:      volumeName,
+		MountPath: mountPath,
+	}
+}
+
+// HostPathVolume returns a volume of a directory on the node (which is created if it doesn't exist), which outlives the pod
+func HostPathVolume(volumeName string, hostPath string) kcore.Volume {
+	hostPathType := kcore.HostPathDirectoryOrCreate
+	return kcore.Volume{
+		Name: volumeName,
+		VolumeSource: kcore.VolumeSource{
+			HostPath: &kcore.HostPathVolumeSource{
+				Path: hostPath,
+				Type: &hostPathType,
+			},
+		},
+	}
+}
+
+func HostPathVolumeMount(volumeName string, mountPath string) kcore.VolumeMount {
+	return kcore.VolumeMount{
+		Name:      volumeName,
+		MountPath: mountPath,
+	}
+}
+
+// PodLabelsVolume returns a volume which contains a file (fileName) with the pod's labels, which is updated when they change
+func PodLabelsVolume(volumeName string, fileName string) kcore.Volume {
+	return kcore.Volume{
+		Name: volumeName,
+		VolumeSource: kcore.VolumeSource{
+			DownwardAPI: &kcore.DownwardAPIVolumeSource{
+				Items: []kcore.DownwardAPIVolumeFile{
+					{
+						Path: fileName,
+						FieldRef: &kcore.ObjectFieldSelector{
+							FieldPath: "metadata.labels",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func PodLabelsVolumeMount(volumeName string, mountPath string) kcore.VolumeMount {
+	return kcore.VolumeMount{
+		Name:      volumeName,
+		MountPath: mountPath,
+		ReadOnly:  true,
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prometheus
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+// Sample is a single sample of a metric in the prometheus text exposition format
+type Sample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+// ParseText parses metrics in the prometheus text exposition format (comments and timestamps are ignored)
+func ParseText(text string) ([]Sample, error) {
+	var samples []Sample
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		sample, ok := parseLine(line)
+		if !ok {
+			return nil, ErrorInvalidLine(lineNum, line)
+		}
+		samples = append(samples, sample)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return samples, nil
+}
+
+func parseLine(line string) (Sample, bool) {
+	sample := Sample{Labels: map[string]string{}}
+
+	var rest string
+	if openIdx := strings.Index(line, "{"); openIdx != -1 {
+		closeIdx := strings.LastIndex(line, "}")
+		if closeIdx < openIdx {
+			return sample, false
+		}
+		sample.Name = line[:openIdx]
+		labels, ok := parseLabels(line[openIdx+1 : closeIdx])
+		if !ok {
+			return sample, false
+		}
+		sample.Labels = labels
+		rest = line[closeIdx+1:]
+	} else {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return sample, false
+		}
+		sample.Name = fields[0]
+		rest = strings.Join(fields[1:], " ")
+	}
+
+	fields := strings.Fields(rest)
+	if sample.Name == "" || len(fields) == 0 {
+		return sample, false
+	}
+
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return sample, false
+	}
+	sample.Value = value
+
+	return sample, true
+}
+
+func parseLabels(str string) (map[string]string, bool) {
+	labels := map[string]string{}
+
+	for {
+		str = strings.TrimLeft(str, " ,")
+		if str == "" {
+			return labels, true
+		}
+
+		eqIdx := strings.Index(str, "=")
+		if eqIdx == -1 || len(str) < eqIdx+2 || str[eqIdx+1] != '"' {
+			return nil, false
+		}
+		key := strings.TrimSpace(str[:eqIdx])
+
+		var value strings.Builder
+		i := eqIdx + 2
+		for ; i < len(str) && str[i] != '"'; i++ {
+			if str[i] == '\\' && i+1 < len(str) {
+				i++
+				switch str[i] {
+				case 'n':
+					value.WriteByte('\n')
+				default:
+					value.WriteByte(str[i])
+				}
+				continue
+			}
+			value.WriteByte(str[i])
+		}
+		if i >= len(str) {
+			return nil, false
+		}
+
+		labels[key] = value.String()
+		str = str[i+1:]
+	}
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseText(t *testing.T) {
+	text := `
+# HELP DCGM_FI_DEV_GPU_UTIL GPU utilization (in %).
+# TYPE DCGM_FI_DEV_GPU_UTIL gauge
+DCGM_FI_DEV_GPU_UTIL{gpu="0",UUID="GPU-1",pod="api-1",namespace="default"} 87
+DCGM_FI_DEV_GPU_TEMP{gpu="0", pod="a\"b,c}"} 61.5 1600000000000
+up 1
+`
+	samples, err := ParseText(text)
+	require.NoError(t, err)
+	require.Equal(t, []Sample{
+		{Name: "DCGM_FI_DEV_GPU_UTIL", Labels: map[string]string{"gpu": "0", "UUID": "GPU-1", "pod": "api-1", "namespace": "default"}, Value: 87},
+		{Name: "DCGM_FI_DEV_GPU_TEMP", Labels: map[string]string{"gpu": "0", "pod": `a"b,c}`}, Value: 61.5},
+		{Name: "up", Labels: map[string]string{}, Value: 1},
+	}, samples)
+
+	_, err = ParseText("up")
+	require.Error(t, err)
+
+	_, err = ParseText(`up{a="b} 1`)
+	require.Error(t, err)
+
+	_, err = ParseText("up one")
+	require.Error(t, err)
+}
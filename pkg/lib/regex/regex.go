@@ -64,3 +64,12 @@ var _ecrPattern = regexp.MustCompile(
 func IsValidECRURL(s string) bool {
 	return _ecrPattern.MatchString(s)
 }
+
+// matches Container Registry (e.g. gcr.io, us.gcr.io) and Artifact Registry (e.g. us-central1-docker.pkg.dev) images
+var _gcrPattern = regexp.MustCompile(
+	`^((?:[a-z]+\.)?gcr\.io|[a-z0-9][a-z0-9-]*-docker\.pkg\.dev)(/|$)`,
+)
+
+func IsValidGCRURL(s string) bool {
+	return _gcrPattern.MatchString(s)
+}
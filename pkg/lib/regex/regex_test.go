@@ -491,3 +491,43 @@ func TestValidECR(t *testing.T) {
 		}
 	}
 }
+
+func TestValidGCR(t *testing.T) {
+	testcases := []regexpMatch{
+		{
+			input: "",
+			match: false,
+		},
+		{
+			input: "library/ubuntu:latest",
+			match: false,
+		},
+		{
+			input: "gcr.io/my-project/python-predictor-cpu:latest",
+			match: true,
+		},
+		{
+			input: "eu.gcr.io/my-project/image",
+			match: true,
+		},
+		{
+			input: "us-central1-docker.pkg.dev/my-project/cortex/image:123",
+			match: true,
+		},
+		{
+			input: "gcr.io.example.com/image",
+			match: false,
+		},
+		{
+			input: "registry.com/gcr.io/image",
+			match: false,
+		},
+	}
+
+	for i := range testcases {
+		match := _gcrPattern.MatchString(testcases[i].input)
+		if match != testcases[i].match {
+			t.Errorf("No match for %q", testcases[i].input)
+		}
+	}
+}
@@ -27,6 +27,20 @@ func Float64PtrSumInt(floats ...*float64) int {
 	return sum
 }
 
+// Float64PtrSum returns nil if all of the floats are nil
+func Float64PtrSum(floats ...*float64) *float64 {
+	var sum *float64
+	for _, num := range floats {
+		if num != nil {
+			if sum == nil {
+				sum = new(float64)
+			}
+			*sum += *num
+		}
+	}
+	return sum
+}
+
 func Float64PtrMin(floats ...*float64) *float64 {
 	var min *float64
 
@@ -321,6 +321,33 @@ func UnzipMemToMem(zipBytes []byte) (map[string][]byte, error) {
 	return unzipReaderToMem(r)
 }
 
+// UnzipMemToList returns the files in the zip in the order in which they were added (with their names as they were
+// added, so that zipping the list with ToMem produces the same zip, if it was created by ToMem)
+func UnzipMemToList(zipBytes []byte) ([]BytesInput, error) {
+	r, err := zip.NewReader(bytes.NewReader(zipBytes), int64(len(zipBytes)))
+	if err != nil {
+		return nil, errors.Wrap(err, _errStrUnzip)
+	}
+
+	var contents []BytesInput
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, errors.Wrap(err, _errStrUnzip)
+		}
+		bytes, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, errors.Wrap(err, _errStrUnzip)
+		}
+		contents = append(contents, BytesInput{Content: bytes, Dest: f.Name})
+	}
+	return contents, nil
+}
+
 func UnzipFileToMem(src string) (map[string][]byte, error) {
 	cleanSrc, err := files.EscapeTilde(src)
 	if err != nil {
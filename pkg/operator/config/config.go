@@ -25,9 +25,13 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/aws"
 	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/gcp"
 	"github.com/cortexlabs/cortex/pkg/lib/hash"
 	"github.com/cortexlabs/cortex/pkg/lib/k8s"
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/operator/metadata"
+	"github.com/cortexlabs/cortex/pkg/operator/storage"
+	"github.com/cortexlabs/cortex/pkg/types"
 	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
 )
 
@@ -36,9 +40,12 @@ const _clusterConfigPath = "/configs/cluster/cluster.yaml"
 var (
 	Cluster         *clusterconfig.InternalConfig
 	AWS             *aws.Client
+	GCP             *gcp.Client // nil unless the cluster is on gcp
 	K8s             *k8s.Client
 	K8sIstio        *k8s.Client
 	K8sAllNamspaces *k8s.Client
+	Metadata        metadata.Store
+	Bucket          storage.Bucket
 )
 
 func Init() error {
@@ -59,15 +66,31 @@ func Init() error {
 		return errors.FirstError(errs...)
 	}
 
-	AWS, err = aws.NewFromEnv(*Cluster.Region)
-	if err != nil {
+	if err := Cluster.ValidateLightweight(); err != nil {
+		return err
+	}
+
+	if err := Cluster.ValidateGCP(); err != nil {
 		return err
 	}
 
-	_, hashedAccountID, err := AWS.CheckCredentials()
+	AWS, err = aws.NewFromEnv(*Cluster.Region)
 	if err != nil {
 		return err
 	}
+	if Cluster.S3Endpoint != nil {
+		AWS.SetS3Endpoint(*Cluster.S3Endpoint)
+	}
+
+	// lightweight clusters' credentials are for their object store, and gcp clusters' credentials are only used to
+	// authenticate the cli, so they don't belong to an AWS account
+	hashedAccountID := hash.String(Cluster.ClusterName)
+	if Cluster.HasAWSResources() {
+		_, hashedAccountID, err = AWS.CheckCredentials()
+		if err != nil {
+			return err
+		}
+	}
 
 	Cluster.ID = hash.String(Cluster.ClusterName + *Cluster.Region + hashedAccountID)
 
@@ -86,6 +109,46 @@ func Init() error {
 		fmt.Println(errors.Message(err))
 	}
 
+	if err := initAPIGateway(); err != nil {
+		return err
+	}
+
+	// on gcp, the operator authenticates as the google service account which its kubernetes service account is bound to
+	if Cluster.Provider == types.GCPProviderType {
+		if GCP, err = gcp.NewFromEnv(Cluster.GCP.Project); err != nil {
+			return err
+		}
+	}
+
+	Bucket = storage.New(&Cluster.Config, AWS, GCP)
+
+	if Metadata, err = metadata.New(&Cluster.Config, AWS, Bucket); err != nil {
+		return err
+	}
+
+	Cluster.InstanceMetadata = aws.InstanceMetadatas[*Cluster.Region][*Cluster.InstanceType]
+
+	if K8s, err = k8s.New("default", Cluster.OperatorInCluster); err != nil {
+		return err
+	}
+
+	if K8sIstio, err = k8s.New("istio-system", Cluster.OperatorInCluster); err != nil {
+		return err
+	}
+
+	if K8sAllNamspaces, err = k8s.New("", Cluster.OperatorInCluster); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// lightweight and gcp clusters don't have an API Gateway, so their APIs are only served by the cluster's ingress controller
+func initAPIGateway() error {
+	if !Cluster.HasAWSResources() {
+		return nil
+	}
+
 	apiGateway, err := AWS.GetAPIGatewayByTag(clusterconfig.ClusterNameTag, Cluster.ClusterName)
 	if err != nil {
 		return err
@@ -112,19 +175,13 @@ func Init() error {
 		Cluster.VPCLinkIntegration = integration
 	}
 
-	Cluster.InstanceMetadata = aws.InstanceMetadatas[*Cluster.Region][*Cluster.InstanceType]
-
-	if K8s, err = k8s.New("default", Cluster.OperatorInCluster); err != nil {
-		return err
-	}
-
-	if K8sIstio, err = k8s.New("istio-system", Cluster.OperatorInCluster); err != nil {
-		return err
-	}
+	return nil
+}
 
-	if K8sAllNamspaces, err = k8s.New("", Cluster.OperatorInCluster); err != nil {
-		return err
+// K8sNamespace returns a client for the given namespace which shares the default client's connection
+func K8sNamespace(namespace string) *k8s.Client {
+	if namespace == K8s.Namespace {
+		return K8s
 	}
-
-	return nil
+	return K8s.WithNamespace(namespace)
 }
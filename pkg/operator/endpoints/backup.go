@@ -0,0 +1,78 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+)
+
+func CreateBackup(w http.ResponseWriter, r *http.Request) {
+	if !getPrincipal(r).IsAdmin() {
+		respondErrorCode(w, r, http.StatusForbidden, operator.ErrorAdminOnly("create backups"))
+		return
+	}
+
+	backup, err := operator.CreateBackup(getOptionalQParam("path", r))
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respond(w, schema.BackupResponse{
+		Backup: *backup,
+	})
+}
+
+func RestoreBackup(w http.ResponseWriter, r *http.Request) {
+	path, err := getRequiredQueryParam("path", r)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	force := getOptionalBoolQParam("force", false, r)
+
+	if !getPrincipal(r).IsAdmin() {
+		respondErrorCode(w, r, http.StatusForbidden, operator.ErrorAdminOnly("restore backups"))
+		return
+	}
+
+	backup, results, err := operator.RestoreBackup(path, force)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	// the results are in the order of the backup's APIs
+	for i, result := range results {
+		operator.RecordAuditEvent(schema.AuditEvent{
+			Caller:  getCaller(r),
+			Action:  "restore",
+			APIName: backup.APIs[i],
+			APIID:   result.API.ID,
+			Message: result.Message,
+			Error:   result.Error,
+		}, nil)
+	}
+
+	respond(w, schema.RestoreResponse{
+		Backup:  *backup,
+		Results: results,
+	})
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/gorilla/mux"
+)
+
+func SubmitBatchJob(w http.ResponseWriter, r *http.Request) {
+	apiName := mux.Vars(r)["apiName"]
+
+	if err := operator.AuthorizeAPI(getPrincipal(r), apiName); err != nil {
+		respondErrorCode(w, r, http.StatusForbidden, err)
+		return
+	}
+
+	submissionBytes, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		respondError(w, r, errors.WithStack(err))
+		return
+	}
+
+	var submission schema.BatchJobSubmission
+	if err := json.Unmarshal(submissionBytes, &submission); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	batchJob, err := operator.SubmitBatchJob(apiName, submission)
+	operator.RecordAuditEvent(schema.AuditEvent{Caller: getCaller(r), Action: "submit batch job", APIName: apiName, Message: fmt.Sprintf("items: %d, workers: %d", len(submission.Items), submission.Workers)}, err)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respond(w, schema.BatchJobResponse{
+		BatchJob: *batchJob,
+	})
+}
+
+// ListBatchJobs lists the batch jobs of all APIs if the apiName path variable isn't set
+func ListBatchJobs(w http.ResponseWriter, r *http.Request) {
+	batchJobs, err := operator.ListBatchJobs(mux.Vars(r)["apiName"])
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respond(w, schema.BatchJobsResponse{
+		BatchJobs: batchJobs,
+	})
+}
+
+func GetBatchJob(w http.ResponseWriter, r *http.Request) {
+	apiName := mux.Vars(r)["apiName"]
+	jobID := mux.Vars(r)["jobID"]
+
+	batchJob, err := operator.GetBatchJob(apiName, jobID)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respond(w, schema.BatchJobResponse{
+		BatchJob: *batchJob,
+	})
+}
+
+func CancelBatchJob(w http.ResponseWriter, r *http.Request) {
+	apiName := mux.Vars(r)["apiName"]
+	jobID := mux.Vars(r)["jobID"]
+
+	if err := operator.AuthorizeAPI(getPrincipal(r), apiName); err != nil {
+		respondErrorCode(w, r, http.StatusForbidden, err)
+		return
+	}
+
+	batchJob, err := operator.CancelBatchJob(apiName, jobID)
+	operator.RecordAuditEvent(schema.AuditEvent{Caller: getCaller(r), Action: "cancel batch job", APIName: apiName, Message: jobID}, err)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respond(w, schema.BatchJobResponse{
+		BatchJob: *batchJob,
+	})
+}
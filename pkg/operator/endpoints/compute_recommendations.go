@@ -0,0 +1,55 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+)
+
+// RecommendCompute responds with adjustments to the APIs' compute requests which would pack their replicas onto the
+// cluster's instances more efficiently, without deploying anything
+func RecommendCompute(w http.ResponseWriter, r *http.Request) {
+	_, _, apiConfigs, err := readDeployRequest(r)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	if err := authorizeDeploy(getPrincipal(r), apiConfigs); err != nil {
+		respondErrorCode(w, r, http.StatusForbidden, err)
+		return
+	}
+
+	results := make([]schema.ComputeRecommendationsResult, len(apiConfigs))
+	for i := range apiConfigs {
+		result, err := operator.RecommendCompute(&apiConfigs[i])
+		if err != nil {
+			results[i].APIName = apiConfigs[i].Name
+			results[i].Error = errors.Message(err)
+		} else {
+			results[i] = *result
+		}
+	}
+
+	respond(w, schema.ComputeRecommendationsResponse{
+		Results: results,
+	})
+}
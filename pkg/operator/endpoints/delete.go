@@ -30,6 +30,11 @@ func Delete(w http.ResponseWriter, r *http.Request) {
 	apiName := mux.Vars(r)["apiName"]
 	keepCache := getOptionalBoolQParam("keepCache", false, r)
 
+	if err := operator.AuthorizeAPI(getPrincipal(r), apiName); err != nil {
+		respondErrorCode(w, r, http.StatusForbidden, err)
+		return
+	}
+
 	isDeployed, err := operator.IsAPIDeployed(apiName)
 	if err != nil {
 		respondError(w, r, err)
@@ -43,6 +48,10 @@ func Delete(w http.ResponseWriter, r *http.Request) {
 			if err != nil {
 				telemetry.Error(err)
 			}
+			err = operator.DeleteCortexAPI(apiName)
+			if err != nil {
+				telemetry.Error(err)
+			}
 		}()
 
 		respondErrorCode(w, r, http.StatusNotFound, operator.ErrorAPINotDeployed(apiName))
@@ -50,6 +59,13 @@ func Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	err = operator.DeleteAPI(apiName, keepCache)
+	operator.RecordAuditEvent(schema.AuditEvent{Caller: getCaller(r), Action: "delete", APIName: apiName}, err)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	err = operator.DeleteCortexAPI(apiName)
 	if err != nil {
 		respondError(w, r, err)
 		return
@@ -17,90 +17,222 @@ limitations under the License.
 package endpoints
 
 import (
+	"fmt"
 	"net/http"
+	"sync"
 
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/files"
-	"github.com/cortexlabs/cortex/pkg/lib/hash"
 	"github.com/cortexlabs/cortex/pkg/lib/zip"
 	"github.com/cortexlabs/cortex/pkg/operator/config"
 	"github.com/cortexlabs/cortex/pkg/operator/operator"
 	"github.com/cortexlabs/cortex/pkg/operator/schema"
-	"github.com/cortexlabs/cortex/pkg/types"
 	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
 )
 
 func Deploy(w http.ResponseWriter, r *http.Request) {
 	force := getOptionalBoolQParam("force", false, r)
+	atomic := getOptionalBoolQParam("atomic", false, r)
 
-	configPath, err := getRequiredQueryParam("configPath", r)
+	configBytes, projectBytes, apiConfigs, err := readDeployRequest(r)
 	if err != nil {
-		respondError(w, r, errors.WithStack(err))
+		respondError(w, r, err)
 		return
 	}
 
-	configBytes, err := files.ReadReqFile(r, "config")
-	if err != nil {
-		respondError(w, r, errors.WithStack(err))
-		return
-	} else if len(configBytes) == 0 {
-		respondError(w, r, ErrorFormFileMustBeProvided("config"))
+	principal := getPrincipal(r)
+	if err := authorizeDeploy(principal, apiConfigs); err != nil {
+		respondErrorCode(w, r, http.StatusForbidden, err)
 		return
 	}
 
-	projectBytes, err := files.ReadReqFile(r, "project.zip")
+	projectID, err := operator.UploadProject(projectBytes)
 	if err != nil {
 		respondError(w, r, err)
 		return
 	}
-	projectID := hash.Bytes(projectBytes)
-	projectKey := spec.ProjectKey(projectID)
+
+	// APIs are deployed in dependency order (the APIs within each stage are deployed concurrently), but results are
+	// reported in the order the APIs are listed
+	stages := operator.DeployStages(apiConfigs)
+
+	prevAPIs := make([]*spec.API, len(apiConfigs))
+	for i := range apiConfigs {
+		prevAPI, err := operator.GetDeployedAPISpec(apiConfigs[i].Name)
+		if err != nil {
+			if atomic {
+				// without the previous spec, the API couldn't be reverted
+				respondError(w, r, err)
+				return
+			}
+			errors.PrintError(err, "failed to get the deployed api spec")
+		}
+		prevAPIs[i] = prevAPI
+	}
+
+	results := make([]schema.DeployResult, len(apiConfigs))
+	var deployed []int
+	for s, stage := range stages {
+		apis, msgs, errs := deployStage(apiConfigs, stage, projectID, force)
+
+		var failedAPIName string
+		for n, i := range stage {
+			auditDeploy(r, apiConfigs[i].Name, prevAPIs[i], apis[n], msgs[n], errs[n])
+			results[i].Message = msgs[n]
+			if errs[n] != nil {
+				results[i].Error = errors.Message(errs[n])
+				if failedAPIName == "" {
+					failedAPIName = apiConfigs[i].Name
+				}
+				continue
+			}
+
+			results[i].API = *apis[n]
+			deployed = append(deployed, i)
+			if !atomic {
+				recordDeployedAPI(principal, configBytes, apis[n])
+			}
+		}
+
+		if atomic && failedAPIName != "" {
+			var remaining []int
+			for _, laterStage := range stages[s+1:] {
+				remaining = append(remaining, laterStage...)
+			}
+			revertDeploy(apiConfigs, prevAPIs, deployed, remaining, results, failedAPIName)
+			break
+		}
+	}
+
+	if atomic && len(deployed) == len(apiConfigs) {
+		for _, i := range deployed {
+			recordDeployedAPI(principal, configBytes, &results[i].API)
+		}
+	}
+
+	respond(w, schema.DeployResponse{
+		Results: results,
+	})
+}
+
+// deployStage deploys the APIs in a stage concurrently (operator.UpdateAPI bounds the number of APIs which are deployed
+// at once); the results are in the order of the stage's indexes
+func deployStage(apiConfigs []userconfig.API, stage []int, projectID string, force bool) ([]*spec.API, []string, []error) {
+	apis := make([]*spec.API, len(stage))
+	msgs := make([]string, len(stage))
+	errs := make([]error, len(stage))
+
+	var wg sync.WaitGroup
+	for n, i := range stage {
+		wg.Add(1)
+		go func(n int, apiConfig userconfig.API) {
+			defer wg.Done()
+			apis[n], msgs[n], errs[n] = operator.UpdateAPI(&apiConfig, projectID, force)
+		}(n, apiConfigs[i])
+	}
+	wg.Wait()
+
+	return apis, msgs, errs
+}
+
+// revertDeploy reverts the APIs which were deployed by an atomic deploy which failed (in the reverse order of their
+// deployment), and marks the APIs which weren't attempted as not deployed
+func revertDeploy(apiConfigs []userconfig.API, prevAPIs []*spec.API, deployed []int, remaining []int, results []schema.DeployResult, failedAPIName string) {
+	for n := len(deployed) - 1; n >= 0; n-- {
+		i := deployed[n]
+		apiName := apiConfigs[i].Name
+		results[i].API = spec.API{}
+		if err := operator.RevertAPI(apiName, prevAPIs[i]); err != nil {
+			results[i].Error = fmt.Sprintf("%s: failed to roll back after %s failed to deploy: %s", apiName, failedAPIName, errors.Message(err))
+			continue
+		}
+		results[i].Error = fmt.Sprintf("%s: rolled back because %s failed to deploy", apiName, failedAPIName)
+	}
+
+	for _, i := range remaining {
+		results[i].Error = fmt.Sprintf("%s: not deployed because %s failed to deploy", apiConfigs[i].Name, failedAPIName)
+	}
+}
+
+func recordDeployedAPI(principal *operator.Principal, configBytes []byte, api *spec.API) {
+	if err := operator.SetAPIOwner(principal, api.Name); err != nil {
+		errors.PrintError(err, "failed to set api owner")
+	}
+	if err := operator.ApplyCortexAPI(configBytes, api); err != nil {
+		errors.PrintError(err, "failed to record api resource")
+	}
+}
+
+func auditDeploy(r *http.Request, apiName string, prevAPI *spec.API, api *spec.API, msg string, deployErr error) {
+	event := schema.AuditEvent{
+		Caller:  getCaller(r),
+		Action:  "deploy",
+		APIName: apiName,
+		Message: msg,
+	}
+
+	if api != nil {
+		event.APIID = api.ID
+		changes, err := operator.DiffAPIConfigs(prevAPI, api)
+		if err != nil {
+			errors.PrintError(err, "failed to diff api configs")
+		}
+		event.Changes = changes
+	}
+
+	operator.RecordAuditEvent(event, deployErr)
+}
+
+// readDeployRequest reads the API configuration and project from a deploy request, and validates the APIs
+func readDeployRequest(r *http.Request) ([]byte, []byte, []userconfig.API, error) {
+	configPath, err := getRequiredQueryParam("configPath", r)
+	if err != nil {
+		return nil, nil, nil, errors.WithStack(err)
+	}
+
+	configBytes, err := files.ReadReqFile(r, "config")
+	if err != nil {
+		return nil, nil, nil, errors.WithStack(err)
+	} else if len(configBytes) == 0 {
+		return nil, nil, nil, ErrorFormFileMustBeProvided("config")
+	}
+
+	projectBytes, err := readProject(r)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := operator.ValidateProjectSize(projectBytes); err != nil {
+		return nil, nil, nil, err
+	}
 	projectFileMap, err := zip.UnzipMemToMem(projectBytes)
 	if err != nil {
-		respondError(w, r, err)
-		return
+		return nil, nil, nil, err
 	}
 
 	projectFiles := operator.ProjectFiles{
 		ProjectByteMap: projectFileMap,
 		ConfigFilePath: configPath,
 	}
-	apiConfigs, err := spec.ExtractAPIConfigs(configBytes, types.AWSProviderType, projectFiles, configPath)
+	apiConfigs, err := spec.ExtractAPIConfigs(configBytes, config.Cluster.Provider, projectFiles, configPath, operator.DeployEnvironment())
 	if err != nil {
-		respondError(w, r, err)
-		return
+		return nil, nil, nil, err
 	}
 
 	err = operator.ValidateClusterAPIs(apiConfigs, projectFiles)
 	if err != nil {
-		respondError(w, r, err)
-		return
+		return nil, nil, nil, err
 	}
 
-	isProjectUploaded, err := config.AWS.IsS3File(config.Cluster.Bucket, projectKey)
-	if err != nil {
-		respondError(w, r, err)
-		return
-	}
-	if !isProjectUploaded {
-		if err = config.AWS.UploadBytesToS3(projectBytes, config.Cluster.Bucket, projectKey); err != nil {
-			respondError(w, r, err)
-			return
-		}
-	}
+	return configBytes, projectBytes, apiConfigs, nil
+}
 
-	results := make([]schema.DeployResult, len(apiConfigs))
-	for i, apiConfig := range apiConfigs {
-		api, msg, err := operator.UpdateAPI(&apiConfig, projectID, force)
-		results[i].Message = msg
-		if err != nil {
-			results[i].Error = errors.Message(err)
-		} else {
-			results[i].API = *api
+func authorizeDeploy(principal *operator.Principal, apiConfigs []userconfig.API) error {
+	for _, apiConfig := range apiConfigs {
+		if err := operator.AuthorizeAPI(principal, apiConfig.Name); err != nil {
+			return err
 		}
 	}
 
-	respond(w, schema.DeployResponse{
-		Results: results,
-	})
+	return operator.ValidateTeamQuota(principal, apiConfigs)
 }
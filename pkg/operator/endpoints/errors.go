@@ -35,6 +35,7 @@ const (
 	ErrPathParamRequired      = "endpoints.path_param_required"
 	ErrAnyQueryParamRequired  = "endpoints.any_query_param_required"
 	ErrAnyPathParamRequired   = "endpoints.any_path_param_required"
+	ErrQueryParamInvalid      = "endpoints.query_param_invalid"
 )
 
 func ErrorAPIVersionMismatch(operatorVersion string, clientVersion string) error {
@@ -114,3 +115,10 @@ func ErrorAnyPathParamRequired(param string, params ...string) error {
 		Message: fmt.Sprintf("path params required: %s", s.UserStrsOr(allParams)),
 	})
 }
+
+func ErrorQueryParamInvalid(param string, value string, expected string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrQueryParamInvalid,
+		Message: fmt.Sprintf("invalid value for query param %s (%s): expected %s", param, s.UserStr(value), expected),
+	})
+}
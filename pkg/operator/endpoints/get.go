@@ -21,12 +21,19 @@ import (
 
 	"github.com/cortexlabs/cortex/pkg/operator/operator"
 	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
 	"github.com/cortexlabs/cortex/pkg/types/status"
 	"github.com/gorilla/mux"
 )
 
 func GetAPIs(w http.ResponseWriter, r *http.Request) {
-	statuses, err := operator.GetAllStatuses()
+	selector, err := spec.ParseLabelSelector(getOptionalQParam("selector", r))
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	statuses, err := operator.GetAllStatuses(selector)
 	if err != nil {
 		respondError(w, r, err)
 		return
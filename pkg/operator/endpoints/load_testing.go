@@ -0,0 +1,76 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/gorilla/mux"
+)
+
+func StartLoadTest(w http.ResponseWriter, r *http.Request) {
+	apiName := mux.Vars(r)["apiName"]
+
+	if err := operator.AuthorizeAPI(getPrincipal(r), apiName); err != nil {
+		respondErrorCode(w, r, http.StatusForbidden, err)
+		return
+	}
+
+	configBytes, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		respondError(w, r, errors.WithStack(err))
+		return
+	}
+
+	var loadTestConfig schema.LoadTestConfig
+	if err := json.Unmarshal(configBytes, &loadTestConfig); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	loadTest, err := operator.StartLoadTest(apiName, loadTestConfig)
+	operator.RecordAuditEvent(schema.AuditEvent{Caller: getCaller(r), Action: "load test", APIName: apiName, Message: fmt.Sprintf("concurrency: %d-%d", loadTestConfig.MinConcurrency, loadTestConfig.MaxConcurrency)}, err)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respond(w, schema.LoadTestResponse{
+		LoadTest: *loadTest,
+	})
+}
+
+func GetLoadTest(w http.ResponseWriter, r *http.Request) {
+	apiName := mux.Vars(r)["apiName"]
+	loadTestID := mux.Vars(r)["loadTestID"]
+
+	loadTest, err := operator.GetLoadTest(apiName, loadTestID)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respond(w, schema.LoadTestResponse{
+		LoadTest: *loadTest,
+	})
+}
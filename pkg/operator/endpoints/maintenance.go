@@ -0,0 +1,67 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/gorilla/mux"
+)
+
+func EnableMaintenance(w http.ResponseWriter, r *http.Request) {
+	apiName := mux.Vars(r)["apiName"]
+	message := getOptionalQParam("message", r)
+
+	if err := operator.AuthorizeAPI(getPrincipal(r), apiName); err != nil {
+		respondErrorCode(w, r, http.StatusForbidden, err)
+		return
+	}
+
+	message, err := operator.EnableMaintenanceMode(apiName, message)
+	operator.RecordAuditEvent(schema.AuditEvent{Caller: getCaller(r), Action: "enable_maintenance", APIName: apiName, Message: message}, err)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respond(w, schema.MaintenanceResponse{
+		Message: fmt.Sprintf("%s is in maintenance mode; requests will receive status code 503 with the message %q", apiName, message),
+	})
+}
+
+func DisableMaintenance(w http.ResponseWriter, r *http.Request) {
+	apiName := mux.Vars(r)["apiName"]
+
+	if err := operator.AuthorizeAPI(getPrincipal(r), apiName); err != nil {
+		respondErrorCode(w, r, http.StatusForbidden, err)
+		return
+	}
+
+	err := operator.DisableMaintenanceMode(apiName)
+	operator.RecordAuditEvent(schema.AuditEvent{Caller: getCaller(r), Action: "disable_maintenance", APIName: apiName}, err)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respond(w, schema.MaintenanceResponse{
+		Message: fmt.Sprintf("%s is no longer in maintenance mode", apiName),
+	})
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/gorilla/mux"
+)
+
+func GetMetrics(w http.ResponseWriter, r *http.Request) {
+	apiName := mux.Vars(r)["apiName"]
+
+	endTime, err := getOptionalTimeQParam("end", r)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	if endTime == nil {
+		now := time.Now()
+		endTime = &now
+	}
+
+	startTime, err := getOptionalTimeQParam("start", r)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	if startTime == nil {
+		start := endTime.Add(-operator.DefaultMetricsTimeRange)
+		startTime = &start
+	}
+
+	period, err := getOptionalInt64QParam("period", 0, r)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	timeSeries, err := operator.GetMetricsTimeSeries(apiName, getOptionalQParam("apiID", r), getOptionalQParam("model", r), *startTime, *endTime, period)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respond(w, schema.GetMetricsResponse{
+		TimeSeries: *timeSeries,
+	})
+}
@@ -18,14 +18,18 @@ package endpoints
 
 import (
 	"context"
+	"crypto/subtle"
 	"net/http"
+	"net/http/httputil"
 	"strings"
 
 	"github.com/cortexlabs/cortex/pkg/consts"
 	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
 	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/operator"
 )
 
 var _cachedClientIDs = strset.New()
@@ -35,6 +39,8 @@ type ctxKey int
 const (
 	ctxKeyUnknown ctxKey = iota
 	ctxKeyClient
+	ctxKeyPrincipal
+	ctxKeyCaller
 )
 
 func PanicMiddleware(next http.Handler) http.Handler {
@@ -84,6 +90,17 @@ func AuthMiddleware(next http.Handler) http.Handler {
 		}
 
 		accessKeyID, secretAccessKey := parts[0], parts[1]
+
+		if !config.Cluster.HasAWSResources() {
+			if !isOperatorCredentials(accessKeyID, secretAccessKey) {
+				respondErrorCode(w, r, http.StatusForbidden, ErrorAuthInvalid())
+				return
+			}
+			ctx := context.WithValue(r.Context(), ctxKeyCaller, accessKeyID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
 		awsClient, err := aws.NewFromCreds(*config.Cluster.Region, accessKeyID, secretAccessKey)
 		if err != nil {
 			respondError(w, r, ErrorAuthAPIError())
@@ -107,10 +124,87 @@ func AuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
+		callerARN, err := awsClient.GetCachedCallerARN()
+		if err != nil {
+			respondError(w, r, ErrorAuthAPIError())
+			return
+		}
+
+		// the caller is recorded in the audit log
+		ctx := context.WithValue(r.Context(), ctxKeyCaller, callerARN)
+
+		if config.Cluster.IsMultiTenant() {
+			principal, err := operator.GetPrincipal(callerARN)
+			if err != nil {
+				respondErrorCode(w, r, http.StatusForbidden, err)
+				return
+			}
+
+			ctx = context.WithValue(ctx, ctxKeyPrincipal, principal)
+		}
+
+		r = r.WithContext(ctx)
+
 		next.ServeHTTP(w, r)
 	})
 }
 
+// lightweight clusters' credentials aren't AWS credentials (they are for the cluster's object store), and gcp clusters'
+// credentials are only a shared secret with the cli (see dev/gke.sh), so only requests which are signed with the
+// operator's own credentials are accepted
+func isOperatorCredentials(accessKeyID string, secretAccessKey string) bool {
+	operatorAccessKeyID, operatorSecretAccessKey := config.AWS.AccessKeyID(), config.AWS.SecretAccessKey()
+	if operatorAccessKeyID == nil || operatorSecretAccessKey == nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(accessKeyID), []byte(*operatorAccessKeyID)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(secretAccessKey), []byte(*operatorSecretAccessKey)) == 1
+}
+
+// returns nil if teams are not configured
+func getPrincipal(r *http.Request) *operator.Principal {
+	principal, _ := r.Context().Value(ctxKeyPrincipal).(*operator.Principal)
+	return principal
+}
+
+// returns the ARN of the IAM identity which made the request
+func getCaller(r *http.Request) string {
+	caller, _ := r.Context().Value(ctxKeyCaller).(string)
+	return caller
+}
+
+// the header which is set on requests which a replica forwards to the leader
+const _forwardedToLeaderHeader = "CortexForwardedToLeader"
+
+// LeaderMiddleware forwards requests to the operator replica which is the leader (see operator/leader.go)
+func LeaderMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if operator.IsLeader() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// the leader changed after the request was forwarded
+		if r.Header.Get(_forwardedToLeaderHeader) != "" {
+			respondErrorCode(w, r, http.StatusServiceUnavailable, operator.ErrorNoOperatorLeader())
+			return
+		}
+
+		leaderURL, err := operator.LeaderURL()
+		if err != nil {
+			respondErrorCode(w, r, http.StatusServiceUnavailable, err)
+			return
+		}
+
+		r.Header.Set(_forwardedToLeaderHeader, "true")
+		proxy := httputil.NewSingleHostReverseProxy(leaderURL)
+		proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+			respondErrorCode(w, r, http.StatusBadGateway, errors.WithStack(err))
+		}
+		proxy.ServeHTTP(w, r)
+	})
+}
+
 func APIVersionCheckMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/info" {
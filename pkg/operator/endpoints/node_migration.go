@@ -0,0 +1,59 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+)
+
+func StartNodeMigration(w http.ResponseWriter, r *http.Request) {
+	generation, err := getRequiredQueryParam("generation", r)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	if !getPrincipal(r).IsAdmin() {
+		respondErrorCode(w, r, http.StatusForbidden, operator.ErrorAdminOnly("upgrade the cluster"))
+		return
+	}
+
+	migration, err := operator.StartNodeMigration(generation)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respond(w, schema.NodeMigrationResponse{
+		NodeMigration: *migration,
+	})
+}
+
+func GetNodeMigration(w http.ResponseWriter, r *http.Request) {
+	migration, err := operator.GetNodeMigration()
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respond(w, schema.NodeMigrationResponse{
+		NodeMigration: *migration,
+	})
+}
@@ -18,6 +18,7 @@ package endpoints
 
 import (
 	"net/http"
+	"time"
 
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/gorilla/mux"
@@ -51,3 +52,46 @@ func getOptionalBoolQParam(paramName string, defaultVal bool, r *http.Request) b
 	}
 	return defaultVal
 }
+
+func getOptionalInt64QParam(paramName string, defaultVal int64, r *http.Request) (int64, error) {
+	param := r.URL.Query().Get(paramName)
+	if param == "" {
+		return defaultVal, nil
+	}
+	paramInt64, ok := s.ParseInt64(param)
+	if !ok {
+		return 0, ErrorQueryParamInvalid(paramName, param, "an integer")
+	}
+	return paramInt64, nil
+}
+
+func getOptionalFloat64QParam(paramName string, defaultVal float64, r *http.Request) (float64, error) {
+	param := r.URL.Query().Get(paramName)
+	if param == "" {
+		return defaultVal, nil
+	}
+	paramFloat64, ok := s.ParseFloat64(param)
+	if !ok {
+		return 0, ErrorQueryParamInvalid(paramName, param, "a number")
+	}
+	return paramFloat64, nil
+}
+
+// accepts either an RFC 3339 timestamp, or a duration (e.g. "1h30m") which is interpreted as that long ago
+func getOptionalTimeQParam(paramName string, r *http.Request) (*time.Time, error) {
+	param := r.URL.Query().Get(paramName)
+	if param == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, param); err == nil {
+		return &t, nil
+	}
+
+	if duration, err := time.ParseDuration(param); err == nil {
+		t := time.Now().Add(-duration)
+		return &t, nil
+	}
+
+	return nil, ErrorQueryParamInvalid(paramName, param, "an RFC 3339 timestamp (e.g. 2020-06-01T15:04:05Z) or a duration (e.g. 1h30m)")
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/gorilla/mux"
+)
+
+func Pause(w http.ResponseWriter, r *http.Request) {
+	apiName := mux.Vars(r)["apiName"]
+
+	if err := operator.AuthorizeAPI(getPrincipal(r), apiName); err != nil {
+		respondErrorCode(w, r, http.StatusForbidden, err)
+		return
+	}
+
+	msg, err := operator.PauseAPI(apiName)
+	operator.RecordAuditEvent(schema.AuditEvent{Caller: getCaller(r), Action: "pause", APIName: apiName, Message: msg}, err)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respond(w, schema.PauseResponse{
+		Message: msg,
+	})
+}
+
+func Resume(w http.ResponseWriter, r *http.Request) {
+	apiName := mux.Vars(r)["apiName"]
+
+	if err := operator.AuthorizeAPI(getPrincipal(r), apiName); err != nil {
+		respondErrorCode(w, r, http.StatusForbidden, err)
+		return
+	}
+
+	msg, err := operator.ResumeAPI(apiName)
+	operator.RecordAuditEvent(schema.AuditEvent{Caller: getCaller(r), Action: "resume", APIName: apiName, Message: msg}, err)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respond(w, schema.PauseResponse{
+		Message: msg,
+	})
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/zip"
+	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+)
+
+// MissingProjectFiles responds with the hashes of the files in a project manifest which the cluster doesn't have, so
+// that only those files need to be uploaded with the deploy request
+func MissingProjectFiles(w http.ResponseWriter, r *http.Request) {
+	manifestBytes, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		respondError(w, r, errors.WithStack(err))
+		return
+	}
+
+	var manifest []schema.ProjectFile
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	hashes, err := operator.MissingProjectFiles(manifest)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respond(w, schema.MissingProjectFilesResponse{
+		Hashes: hashes,
+	})
+}
+
+// readProject reads the zipped project from a deploy request; the project is either uploaded in full (project.zip), or
+// incrementally (project_manifest.json, and project_files.zip, which contains the files the cluster didn't have)
+func readProject(r *http.Request) ([]byte, error) {
+	projectBytes, err := files.ReadReqFile(r, "project.zip")
+	if err != nil {
+		return nil, err
+	}
+	if len(projectBytes) > 0 {
+		return projectBytes, nil
+	}
+
+	manifestBytes, err := files.ReadReqFile(r, "project_manifest.json")
+	if err != nil {
+		return nil, err
+	} else if len(manifestBytes) == 0 {
+		return nil, ErrorFormFileMustBeProvided("project.zip")
+	}
+
+	var manifest []schema.ProjectFile
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, err
+	}
+
+	uploadedFiles := map[string][]byte{}
+	projectFilesBytes, err := files.ReadReqFile(r, "project_files.zip")
+	if err != nil {
+		return nil, err
+	}
+	if len(projectFilesBytes) > 0 {
+		uploadedFiles, err = zip.UnzipMemToMem(projectFilesBytes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return operator.AssembleProject(manifest, uploadedFiles)
+}
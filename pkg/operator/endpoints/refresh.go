@@ -28,7 +28,13 @@ func Refresh(w http.ResponseWriter, r *http.Request) {
 	apiName := mux.Vars(r)["apiName"]
 	force := getOptionalBoolQParam("force", false, r)
 
+	if err := operator.AuthorizeAPI(getPrincipal(r), apiName); err != nil {
+		respondErrorCode(w, r, http.StatusForbidden, err)
+		return
+	}
+
 	msg, err := operator.RefreshAPI(apiName, force)
+	operator.RecordAuditEvent(schema.AuditEvent{Caller: getCaller(r), Action: "refresh", APIName: apiName, Message: msg}, err)
 	if err != nil {
 		respondError(w, r, err)
 		return
@@ -0,0 +1,56 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+)
+
+// Render responds with the kubernetes resources which Deploy would apply to the cluster, without applying them
+func Render(w http.ResponseWriter, r *http.Request) {
+	_, projectBytes, apiConfigs, err := readDeployRequest(r)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	if err := authorizeDeploy(getPrincipal(r), apiConfigs); err != nil {
+		respondErrorCode(w, r, http.StatusForbidden, err)
+		return
+	}
+
+	projectID := operator.ProjectID(projectBytes)
+
+	results := make([]schema.RenderResult, len(apiConfigs))
+	for i := range apiConfigs {
+		result, err := operator.RenderAPI(&apiConfigs[i], projectID)
+		if err != nil {
+			results[i].APIName = apiConfigs[i].Name
+			results[i].Error = errors.Message(err)
+		} else {
+			results[i] = *result
+		}
+	}
+
+	respond(w, schema.RenderResponse{
+		Results: results,
+	})
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/gorilla/mux"
+)
+
+func StartReplay(w http.ResponseWriter, r *http.Request) {
+	apiName := mux.Vars(r)["apiName"]
+
+	targetAPIName := getOptionalQParam("target", r)
+	if targetAPIName == "" {
+		targetAPIName = apiName
+	}
+
+	startTime, err := getOptionalTimeQParam("start", r)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	if startTime == nil {
+		respondError(w, r, ErrorQueryParamRequired("start"))
+		return
+	}
+
+	endTime, err := getOptionalTimeQParam("end", r)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	if endTime == nil {
+		now := time.Now()
+		endTime = &now
+	}
+
+	speed, err := getOptionalFloat64QParam("speed", 1, r)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	if speed < 0 {
+		respondError(w, r, ErrorQueryParamInvalid("speed", fmt.Sprint(speed), "a non-negative number"))
+		return
+	}
+
+	// the captured requests are read from apiName, and sent to the target
+	for _, name := range []string{apiName, targetAPIName} {
+		if err := operator.AuthorizeAPI(getPrincipal(r), name); err != nil {
+			respondErrorCode(w, r, http.StatusForbidden, err)
+			return
+		}
+	}
+
+	replay, err := operator.StartReplay(apiName, targetAPIName, *startTime, *endTime, speed)
+	operator.RecordAuditEvent(schema.AuditEvent{Caller: getCaller(r), Action: "replay", APIName: apiName, Message: "target: " + targetAPIName}, err)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respond(w, schema.ReplayResponse{
+		Replay: *replay,
+	})
+}
+
+func GetReplay(w http.ResponseWriter, r *http.Request) {
+	apiName := mux.Vars(r)["apiName"]
+	replayID := mux.Vars(r)["replayID"]
+
+	if err := operator.AuthorizeAPI(getPrincipal(r), apiName); err != nil {
+		respondErrorCode(w, r, http.StatusForbidden, err)
+		return
+	}
+
+	replay, err := operator.GetReplay(apiName, replayID)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respond(w, schema.ReplayResponse{
+		Replay: *replay,
+	})
+}
+
+func ListReplays(w http.ResponseWriter, r *http.Request) {
+	apiName := mux.Vars(r)["apiName"]
+
+	if err := operator.AuthorizeAPI(getPrincipal(r), apiName); err != nil {
+		respondErrorCode(w, r, http.StatusForbidden, err)
+		return
+	}
+
+	replays, err := operator.ListReplays(apiName)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respond(w, schema.ListReplaysResponse{
+		Replays: replays,
+	})
+}
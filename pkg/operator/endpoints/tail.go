@@ -0,0 +1,87 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+func TailLogs(w http.ResponseWriter, r *http.Request) {
+	apiName := mux.Vars(r)["apiName"]
+
+	opts, err := tailOptions(r)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	isDeployed, err := operator.IsAPIDeployed(apiName)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	} else if !isDeployed {
+		respondError(w, r, operator.ErrorAPINotDeployed(apiName))
+		return
+	}
+
+	upgrader := websocket.Upgrader{}
+	socket, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	defer socket.Close()
+
+	operator.TailLogs(apiName, *opts, socket)
+}
+
+func tailOptions(r *http.Request) (*operator.TailOptions, error) {
+	opts := &operator.TailOptions{}
+
+	if containers := getOptionalQParam("containers", r); containers != "" {
+		opts.Containers = strings.Split(containers, ",")
+		if err := operator.ValidateLogContainers(opts.Containers); err != nil {
+			return nil, err
+		}
+	}
+
+	if filter := getOptionalQParam("filter", r); filter != "" {
+		var err error
+		opts.Filter, err = regexp.Compile(filter)
+		if err != nil {
+			return nil, ErrorQueryParamInvalid("filter", filter, "a valid regular expression")
+		}
+	}
+
+	var err error
+	opts.Since, err = getOptionalTimeQParam("since", r)
+	if err != nil {
+		return nil, err
+	}
+	opts.Until, err = getOptionalTimeQParam("until", r)
+	if err != nil {
+		return nil, err
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+)
+
+// Validate runs all of the checks that Deploy would run (including compute capacity and model paths), without
+// deploying anything
+func Validate(w http.ResponseWriter, r *http.Request) {
+	_, _, apiConfigs, err := readDeployRequest(r)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	if err := authorizeDeploy(getPrincipal(r), apiConfigs); err != nil {
+		respondErrorCode(w, r, http.StatusForbidden, err)
+		return
+	}
+
+	apiNames := make([]string, len(apiConfigs))
+	for i, apiConfig := range apiConfigs {
+		apiNames[i] = apiConfig.Name
+	}
+
+	respond(w, schema.ValidateResponse{
+		APINames: apiNames,
+		Message:  fmt.Sprintf("%s %s valid", s.StrsAnd(apiNames), s.PluralCustom("is", "are", len(apiNames))),
+	})
+}
@@ -52,12 +52,49 @@ func main() {
 	routerWithAuth.Use(endpoints.AuthMiddleware)
 
 	routerWithAuth.HandleFunc("/info", endpoints.Info).Methods("GET")
-	routerWithAuth.HandleFunc("/deploy", endpoints.Deploy).Methods("POST")
-	routerWithAuth.HandleFunc("/refresh/{apiName}", endpoints.Refresh).Methods("POST")
-	routerWithAuth.HandleFunc("/delete/{apiName}", endpoints.Delete).Methods("DELETE")
+	routerWithAuth.HandleFunc("/projects/missing", endpoints.MissingProjectFiles).Methods("POST")
+	routerWithAuth.HandleFunc("/validate", endpoints.Validate).Methods("POST")
+	routerWithAuth.HandleFunc("/diff", endpoints.Diff).Methods("POST")
+	routerWithAuth.HandleFunc("/render", endpoints.Render).Methods("POST")
+	routerWithAuth.HandleFunc("/recommend", endpoints.RecommendCompute).Methods("POST")
 	routerWithAuth.HandleFunc("/get", endpoints.GetAPIs).Methods("GET")
 	routerWithAuth.HandleFunc("/get/{apiName}", endpoints.GetAPI).Methods("GET")
+	routerWithAuth.HandleFunc("/history/{apiName}", endpoints.GetHistory).Methods("GET")
+	routerWithAuth.HandleFunc("/audit", endpoints.GetAuditLog).Methods("GET")
+	routerWithAuth.HandleFunc("/metrics/{apiName}", endpoints.GetMetrics).Methods("GET")
+	routerWithAuth.HandleFunc("/experiments/{apiName}", endpoints.GetExperiment).Methods("GET")
+	routerWithAuth.HandleFunc("/slo/{apiName}", endpoints.GetSLO).Methods("GET")
+	routerWithAuth.HandleFunc("/dead-letters/{apiName}", endpoints.GetDeadLetters).Methods("GET")
 	routerWithAuth.HandleFunc("/logs/{apiName}", endpoints.ReadLogs)
+	routerWithAuth.HandleFunc("/logs/{apiName}/tail", endpoints.TailLogs)
+	routerWithAuth.HandleFunc("/progress/{apiName}", endpoints.StreamProgress)
+	routerWithAuth.HandleFunc("/node-migration", endpoints.GetNodeMigration).Methods("GET")
+
+	// these requests change the cluster's state, or read state which the leader keeps in memory
+	routerWithLeader := routerWithAuth.NewRoute().Subrouter()
+	routerWithLeader.Use(endpoints.LeaderMiddleware)
+
+	routerWithLeader.HandleFunc("/costs", endpoints.GetCosts).Methods("GET")
+	routerWithLeader.HandleFunc("/deploy", endpoints.Deploy).Methods("POST")
+	routerWithLeader.HandleFunc("/refresh/{apiName}", endpoints.Refresh).Methods("POST")
+	routerWithLeader.HandleFunc("/delete/{apiName}", endpoints.Delete).Methods("DELETE")
+	routerWithLeader.HandleFunc("/replay/{apiName}", endpoints.StartReplay).Methods("POST")
+	routerWithLeader.HandleFunc("/replay/{apiName}", endpoints.ListReplays).Methods("GET")
+	routerWithLeader.HandleFunc("/replay/{apiName}/{replayID}", endpoints.GetReplay).Methods("GET")
+	routerWithLeader.HandleFunc("/load-test/{apiName}", endpoints.StartLoadTest).Methods("POST")
+	routerWithLeader.HandleFunc("/load-test/{apiName}/{loadTestID}", endpoints.GetLoadTest).Methods("GET")
+	routerWithLeader.HandleFunc("/jobs", endpoints.ListBatchJobs).Methods("GET")
+	routerWithLeader.HandleFunc("/jobs/{apiName}", endpoints.SubmitBatchJob).Methods("POST")
+	routerWithLeader.HandleFunc("/jobs/{apiName}", endpoints.ListBatchJobs).Methods("GET")
+	routerWithLeader.HandleFunc("/jobs/{apiName}/{jobID}", endpoints.GetBatchJob).Methods("GET")
+	routerWithLeader.HandleFunc("/jobs/{apiName}/{jobID}", endpoints.CancelBatchJob).Methods("DELETE")
+	routerWithLeader.HandleFunc("/maintenance/{apiName}", endpoints.EnableMaintenance).Methods("POST")
+	routerWithLeader.HandleFunc("/maintenance/{apiName}", endpoints.DisableMaintenance).Methods("DELETE")
+	routerWithLeader.HandleFunc("/pause/{apiName}", endpoints.Pause).Methods("POST")
+	routerWithLeader.HandleFunc("/resume/{apiName}", endpoints.Resume).Methods("POST")
+	routerWithLeader.HandleFunc("/backup", endpoints.CreateBackup).Methods("POST")
+	routerWithLeader.HandleFunc("/restore", endpoints.RestoreBackup).Methods("POST")
+	routerWithLeader.HandleFunc("/node-migration", endpoints.StartNodeMigration).Methods("POST")
 
 	log.Print("Running on port " + _operatorPortStr)
 	log.Fatal(http.ListenAndServe(":"+_operatorPortStr, router))
@@ -0,0 +1,98 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metadata
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/operator/storage"
+)
+
+// bucketStore stores each item as a json file in the cluster's bucket (in the same layout as s3Store), for buckets which
+// aren't in S3 (e.g. GCS)
+type bucketStore struct {
+	bucket storage.Bucket
+}
+
+func newBucketStore(bucket storage.Bucket) *bucketStore {
+	return &bucketStore{
+		bucket: bucket,
+	}
+}
+
+func (store *bucketStore) key(kind string, key string) string {
+	return filepath.Join(_s3Prefix, kind, key) + ".json"
+}
+
+func (store *bucketStore) Put(kind string, key string, obj interface{}) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return errors.Wrap(err, "metadata", key)
+	}
+	return store.bucket.UploadBytes(data, store.key(kind, key))
+}
+
+func (store *bucketStore) Get(kind string, key string, objPtr interface{}) (bool, error) {
+	exists, err := store.bucket.Exists(store.key(kind, key))
+	if err != nil || !exists {
+		return false, err
+	}
+
+	data, err := store.bucket.ReadBytes(store.key(kind, key))
+	if err != nil {
+		return false, err
+	}
+	if err := (Item{Key: key, Data: data}).Unmarshal(objPtr); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (store *bucketStore) List(kind string, keyPrefix string) ([]Item, error) {
+	kindPrefix := filepath.Join(_s3Prefix, kind) + "/"
+
+	keys, err := store.bucket.ListKeys(kindPrefix+keyPrefix, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, 0, len(keys))
+	for _, fullKey := range keys {
+		data, err := store.bucket.ReadBytes(fullKey)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, Item{
+			Key:  strings.TrimSuffix(strings.TrimPrefix(fullKey, kindPrefix), ".json"),
+			Data: data,
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Key < items[j].Key
+	})
+
+	return items, nil
+}
+
+func (store *bucketStore) Delete(kind string, key string) error {
+	return store.bucket.DeleteFile(store.key(kind, key))
+}
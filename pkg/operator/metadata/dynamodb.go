@@ -0,0 +1,108 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metadata
+
+import (
+	"encoding/json"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+const (
+	_kindAttribute = "kind"
+	_keyAttribute  = "key"
+	_dataAttribute = "data"
+)
+
+type dynamoDBStore struct {
+	aws   *aws.Client
+	table string
+}
+
+func newDynamoDBStore(awsClient *aws.Client, table string, tags map[string]string) (*dynamoDBStore, error) {
+	exists, err := awsClient.DoesDynamoDBTableExist(table)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		if err := awsClient.CreateDynamoDBTable(table, _kindAttribute, _keyAttribute, tags); err != nil {
+			return nil, err
+		}
+	}
+
+	return &dynamoDBStore{
+		aws:   awsClient,
+		table: table,
+	}, nil
+}
+
+func (store *dynamoDBStore) Put(kind string, key string, obj interface{}) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return store.aws.PutDynamoDBItem(store.table, map[string]string{
+		_kindAttribute: kind,
+		_keyAttribute:  key,
+		_dataAttribute: string(data),
+	})
+}
+
+func (store *dynamoDBStore) Get(kind string, key string, objPtr interface{}) (bool, error) {
+	item, err := store.aws.GetDynamoDBItem(store.table, map[string]string{
+		_kindAttribute: kind,
+		_keyAttribute:  key,
+	})
+	if err != nil {
+		return false, err
+	}
+	if item == nil {
+		return false, nil
+	}
+
+	if err := (Item{Key: key, Data: []byte(item[_dataAttribute])}).Unmarshal(objPtr); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (store *dynamoDBStore) List(kind string, keyPrefix string) ([]Item, error) {
+	dynamoItems, err := store.aws.QueryDynamoDBPrefix(store.table, _kindAttribute, kind, _keyAttribute, keyPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, len(dynamoItems))
+	for i, dynamoItem := range dynamoItems {
+		items[i] = Item{
+			Key:  dynamoItem[_keyAttribute],
+			Data: []byte(dynamoItem[_dataAttribute]),
+		}
+	}
+
+	return items, nil
+}
+
+func (store *dynamoDBStore) Delete(kind string, key string) error {
+	return store.aws.DeleteDynamoDBItem(store.table, map[string]string{
+		_kindAttribute: kind,
+		_keyAttribute:  key,
+	})
+}
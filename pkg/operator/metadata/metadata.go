@@ -0,0 +1,64 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metadata
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/operator/storage"
+	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
+)
+
+// Store persists operator metadata (e.g. deployment history) which can't be derived from the current state of the cluster
+type Store interface {
+	Put(kind string, key string, obj interface{}) error
+	// Get returns false if the key does not exist
+	Get(kind string, key string, objPtr interface{}) (bool, error)
+	// List returns all items of the given kind whose key begins with keyPrefix, sorted by key
+	List(kind string, keyPrefix string) ([]Item, error)
+	Delete(kind string, key string) error
+}
+
+type Item struct {
+	Key  string
+	Data []byte
+}
+
+func (item Item) Unmarshal(objPtr interface{}) error {
+	if err := json.Unmarshal(item.Data, objPtr); err != nil {
+		return errors.Wrap(err, "metadata", item.Key)
+	}
+	return nil
+}
+
+func New(clusterConfig *clusterconfig.Config, awsClient *aws.Client, bucket storage.Bucket) (Store, error) {
+	switch clusterConfig.MetadataStore {
+	case clusterconfig.S3MetadataStoreType:
+		return newS3Store(awsClient, clusterConfig.Bucket), nil
+	case clusterconfig.DynamoDBMetadataStoreType:
+		return newDynamoDBStore(awsClient, clusterConfig.MetadataTable, clusterConfig.Tags)
+	case clusterconfig.GCSMetadataStoreType:
+		return newBucketStore(bucket), nil
+	case clusterconfig.PostgresMetadataStoreType:
+		return newPostgresStore(os.Getenv(PostgresURLEnvVar), clusterConfig.MetadataTable)
+	}
+
+	return nil, ErrorUnsupportedStore(clusterConfig.MetadataStore)
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metadata
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/lib/pq"
+)
+
+// PostgresURLEnvVar is the environment variable which holds the connection URL of the postgres metadata store (it is
+// populated from the metadata-postgres secret, since the URL typically contains the database's password)
+const PostgresURLEnvVar = "CORTEX_METADATA_POSTGRES_URL"
+
+type postgresStore struct {
+	db    *sql.DB
+	table string
+}
+
+func newPostgresStore(url string, table string) (*postgresStore, error) {
+	if url == "" {
+		return nil, ErrorPostgresURLNotSet()
+	}
+
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "unable to connect to the postgres metadata store")
+	}
+
+	store := &postgresStore{
+		db:    db,
+		table: pq.QuoteIdentifier(table),
+	}
+
+	_, err = db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		%s text NOT NULL,
+		%s text NOT NULL,
+		%s text NOT NULL,
+		PRIMARY KEY (%s, %s)
+	)`, store.table, _kindAttribute, _keyAttribute, _dataAttribute, _kindAttribute, _keyAttribute))
+	if err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "unable to create the postgres metadata table", table)
+	}
+
+	return store, nil
+}
+
+func (store *postgresStore) Put(kind string, key string, obj interface{}) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = store.db.Exec(fmt.Sprintf(
+		"INSERT INTO %s (%s, %s, %s) VALUES ($1, $2, $3) ON CONFLICT (%s, %s) DO UPDATE SET %s = EXCLUDED.%s",
+		store.table, _kindAttribute, _keyAttribute, _dataAttribute, _kindAttribute, _keyAttribute, _dataAttribute, _dataAttribute,
+	), kind, key, string(data))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func (store *postgresStore) Get(kind string, key string, objPtr interface{}) (bool, error) {
+	var data string
+	err := store.db.QueryRow(fmt.Sprintf(
+		"SELECT %s FROM %s WHERE %s = $1 AND %s = $2",
+		_dataAttribute, store.table, _kindAttribute, _keyAttribute,
+	), kind, key).Scan(&data)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	if err := (Item{Key: key, Data: []byte(data)}).Unmarshal(objPtr); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (store *postgresStore) List(kind string, keyPrefix string) ([]Item, error) {
+	// keys are compared bytewise (COLLATE "C") so that the order matches the other stores
+	rows, err := store.db.Query(fmt.Sprintf(
+		`SELECT %s, %s FROM %s WHERE %s = $1 AND %s LIKE $2 ESCAPE '\' ORDER BY %s COLLATE "C"`,
+		_keyAttribute, _dataAttribute, store.table, _kindAttribute, _keyAttribute, _keyAttribute,
+	), kind, likePrefixPattern(keyPrefix))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+
+	var items []Item
+	for rows.Next() {
+		var key, data string
+		if err := rows.Scan(&key, &data); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		items = append(items, Item{
+			Key:  key,
+			Data: []byte(data),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return items, nil
+}
+
+func (store *postgresStore) Delete(kind string, key string) error {
+	_, err := store.db.Exec(fmt.Sprintf(
+		"DELETE FROM %s WHERE %s = $1 AND %s = $2",
+		store.table, _kindAttribute, _keyAttribute,
+	), kind, key)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// likePrefixPattern returns a LIKE pattern (with '\' as the escape character) which matches strings beginning with prefix
+func likePrefixPattern(prefix string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(prefix) + "%"
+}
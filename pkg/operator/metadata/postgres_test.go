@@ -0,0 +1,30 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metadata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLikePrefixPattern(t *testing.T) {
+	require.Equal(t, "%", likePrefixPattern(""))
+	require.Equal(t, "my-api/%", likePrefixPattern("my-api/"))
+	require.Equal(t, `my\_api\%/%`, likePrefixPattern("my_api%/"))
+	require.Equal(t, `a\\b%`, likePrefixPattern(`a\b`))
+}
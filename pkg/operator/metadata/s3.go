@@ -0,0 +1,89 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metadata
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+)
+
+const _s3Prefix = "metadata"
+
+type s3Store struct {
+	aws    *aws.Client
+	bucket string
+}
+
+func newS3Store(awsClient *aws.Client, bucket string) *s3Store {
+	return &s3Store{
+		aws:    awsClient,
+		bucket: bucket,
+	}
+}
+
+func (store *s3Store) s3Key(kind string, key string) string {
+	return filepath.Join(_s3Prefix, kind, key) + ".json"
+}
+
+func (store *s3Store) Put(kind string, key string, obj interface{}) error {
+	return store.aws.UploadJSONToS3(obj, store.bucket, store.s3Key(kind, key))
+}
+
+func (store *s3Store) Get(kind string, key string, objPtr interface{}) (bool, error) {
+	err := store.aws.ReadJSONFromS3(objPtr, store.bucket, store.s3Key(kind, key))
+	if err != nil {
+		if aws.IsNoSuchKeyErr(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (store *s3Store) List(kind string, keyPrefix string) ([]Item, error) {
+	kindPrefix := filepath.Join(_s3Prefix, kind) + "/"
+
+	objects, err := store.aws.ListS3Prefix(store.bucket, kindPrefix+keyPrefix, false, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, 0, len(objects))
+	for _, object := range objects {
+		data, err := store.aws.ReadBytesFromS3(store.bucket, *object.Key)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, Item{
+			Key:  strings.TrimSuffix(strings.TrimPrefix(*object.Key, kindPrefix), ".json"),
+			Data: data,
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Key < items[j].Key
+	})
+
+	return items, nil
+}
+
+func (store *s3Store) Delete(kind string, key string) error {
+	return store.aws.DeleteS3File(store.bucket, store.s3Key(kind, key))
+}
@@ -0,0 +1,367 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	kcore "k8s.io/api/core/v1"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+// accelerator configures the pods of APIs which use a type of inference accelerator; to support a new type of
+// accelerator, implement this interface and add it to _accelerators
+type accelerator interface {
+	// isRequested returns true if the API's compute configuration requests this accelerator
+	isRequested(api *spec.API) bool
+
+	// resources returns the device resources which are requested (and limited) by the container which runs inference
+	// (TensorFlow Serving for the TensorFlow predictor, the LLM server for the LLM predictor, otherwise the API container)
+	resources(api *spec.API) kcore.ResourceList
+
+	// runtimeContainer returns a container which runs the accelerator's runtime alongside the API, or nil if none is
+	// needed; the API's CPU and memory requests are split evenly between it and the other containers
+	runtimeContainer(api *spec.API) *kcore.Container
+
+	// monitorContainer returns a container which exports the accelerator's utilization metrics from within the pod, or nil
+	// if they are exported by a daemonset on each node; its requests are in addition to the API's compute request
+	monitorContainer(api *spec.API) *kcore.Container
+
+	// volumes and volumeMounts are added to the pod and all of its containers (e.g. to communicate with the runtime)
+	volumes() []kcore.Volume
+	volumeMounts() []kcore.VolumeMount
+
+	// envVars returns the environment variables to add to the container (e.g. to configure the runtime's client)
+	envVars(api *spec.API, container string) []kcore.EnvVar
+
+	// modelServerPerWorker returns true if TensorFlow Serving runs a separate server (on consecutive ports) for each
+	// worker, in which case the accelerator's TensorFlow Serving image provides its own entrypoint
+	modelServerPerWorker() bool
+
+	// toleration allows API pods to be scheduled on nodes which have this accelerator
+	toleration() kcore.Toleration
+}
+
+var _accelerators = []accelerator{
+	&nvidiaGPU{},
+	&awsInferentia{},
+}
+
+// returns nil if the API doesn't use an accelerator
+func getAccelerator(api *spec.API) accelerator {
+	for _, acc := range _accelerators {
+		if acc.isRequested(api) {
+			return acc
+		}
+	}
+	return nil
+}
+
+func acceleratorTolerations() []kcore.Toleration {
+	tolerations := make([]kcore.Toleration, len(_accelerators))
+	for i, acc := range _accelerators {
+		tolerations[i] = acc.toleration()
+	}
+	return tolerations
+}
+
+const _nvidiaGPUResource = "nvidia.com/gpu"
+
+type nvidiaGPU struct{}
+
+func (*nvidiaGPU) isRequested(api *spec.API) bool {
+	return api.Compute.GPU > 0
+}
+
+func (*nvidiaGPU) resources(api *spec.API) kcore.ResourceList {
+	return kcore.ResourceList{
+		_nvidiaGPUResource: *kresource.NewQuantity(api.Compute.GPU, kresource.DecimalSI),
+	}
+}
+
+func (*nvidiaGPU) runtimeContainer(api *spec.API) *kcore.Container {
+	return nil
+}
+
+// GPU metrics are exported by the dcgm exporter daemonset (see gpu_telemetry.go)
+func (*nvidiaGPU) monitorContainer(api *spec.API) *kcore.Container {
+	return nil
+}
+
+func (*nvidiaGPU) volumes() []kcore.Volume {
+	return nil
+}
+
+func (*nvidiaGPU) volumeMounts() []kcore.VolumeMount {
+	return nil
+}
+
+func (*nvidiaGPU) envVars(api *spec.API, container string) []kcore.EnvVar {
+	return nil
+}
+
+func (*nvidiaGPU) modelServerPerWorker() bool {
+	return false
+}
+
+func (*nvidiaGPU) toleration() kcore.Toleration {
+	return kcore.Toleration{
+		Key:      _nvidiaGPUResource,
+		Operator: kcore.TolerationOpEqual,
+		Value:    "true",
+		Effect:   kcore.TaintEffectNoSchedule,
+	}
+}
+
+const (
+	_inferentiaResource         = "aws.amazon.com/infa"
+	_neuronRTDContainerName     = "neuron-rtd"
+	_neuronRTDSocket            = "/sock/neuron.sock"
+	_neuronSockVolumeName       = "neuron-sock"
+	_neuronMonitorContainerName = "neuron-monitor"
+	_neuronMonitorPortInt32     = int32(9401)
+	_neuronMonitorPortStr       = "9401"
+)
+
+var (
+	// each Inferentia chip requires 128 HugePages with each HugePage having a size of 2Mi
+	_hugePagesMemPerInf = int64(128 * 2 * 1024 * 1024) // bytes
+
+	_neuronMonitorCPURequest = kresource.MustParse("20m")
+	_neuronMonitorMemRequest = kresource.MustParse("50Mi")
+)
+
+type awsInferentia struct{}
+
+func (*awsInferentia) isRequested(api *spec.API) bool {
+	return api.Compute.Inf > 0
+}
+
+// Inferentia chips are requested by the neuron runtime container
+func (*awsInferentia) resources(api *spec.API) kcore.ResourceList {
+	return nil
+}
+
+func (inf *awsInferentia) runtimeContainer(api *spec.API) *kcore.Container {
+	totalHugePages := api.Compute.Inf * _hugePagesMemPerInf
+	return &kcore.Container{
+		Name:            _neuronRTDContainerName,
+		Image:           config.Cluster.ImageNeuronRTD,
+		ImagePullPolicy: kcore.PullAlways,
+		SecurityContext: &kcore.SecurityContext{
+			Capabilities: &kcore.Capabilities{
+				Add: []kcore.Capability{
+					"SYS_ADMIN",
+					"IPC_LOCK",
+				},
+			},
+		},
+		VolumeMounts:   inf.volumeMounts(),
+		ReadinessProbe: socketExistsProbe(_neuronRTDSocket),
+		Resources: kcore.ResourceRequirements{
+			Requests: kcore.ResourceList{
+				"hugepages-2Mi":     *kresource.NewQuantity(totalHugePages, kresource.BinarySI),
+				_inferentiaResource: *kresource.NewQuantity(api.Compute.Inf, kresource.DecimalSI),
+			},
+			Limits: kcore.ResourceList{
+				"hugepages-2Mi":     *kresource.NewQuantity(totalHugePages, kresource.BinarySI),
+				_inferentiaResource: *kresource.NewQuantity(api.Compute.Inf, kresource.DecimalSI),
+			},
+		},
+	}
+}
+
+// neuron-monitor reads the utilization of the pod's NeuronCores from the neuron runtime, and exports it in the prometheus
+// format for the operator to scrape (see neuron_telemetry.go)
+func (inf *awsInferentia) monitorContainer(api *spec.API) *kcore.Container {
+	return &kcore.Container{
+		Name:            _neuronMonitorContainerName,
+		Image:           config.Cluster.ImageNeuronMonitor,
+		ImagePullPolicy: kcore.PullAlways,
+		Env: []kcore.EnvVar{
+			{
+				Name:  "NEURON_RTD_ADDRESS",
+				Value: fmt.Sprintf("unix:%s", _neuronRTDSocket),
+			},
+			{
+				Name:  "NEURON_MONITOR_PORT",
+				Value: _neuronMonitorPortStr,
+			},
+		},
+		Ports: []kcore.ContainerPort{
+			{ContainerPort: _neuronMonitorPortInt32},
+		},
+		VolumeMounts: inf.volumeMounts(),
+		Resources: kcore.ResourceRequirements{
+			Requests: neuronMonitorRequests(),
+		},
+	}
+}
+
+func neuronMonitorRequests() kcore.ResourceList {
+	return kcore.ResourceList{
+		kcore.ResourceCPU:    _neuronMonitorCPURequest,
+		kcore.ResourceMemory: _neuronMonitorMemRequest,
+	}
+}
+
+func (*awsInferentia) volumes() []kcore.Volume {
+	return []kcore.Volume{
+		{
+			Name: _neuronSockVolumeName,
+		},
+	}
+}
+
+func (*awsInferentia) volumeMounts() []kcore.VolumeMount {
+	return []kcore.VolumeMount{
+		{
+			Name:      _neuronSockVolumeName,
+			MountPath: path.Dir(_neuronRTDSocket),
+		},
+	}
+}
+
+func (*awsInferentia) envVars(api *spec.API, container string) []kcore.EnvVar {
+	var envVars []kcore.EnvVar
+
+	if (api.Predictor.Type == userconfig.PythonPredictorType && container == _apiContainerName) ||
+		(api.Predictor.Type == userconfig.TensorFlowPredictorType && container == _tfServingContainerName) {
+		envVars = append(envVars,
+			kcore.EnvVar{
+				Name:  "NEURONCORE_GROUP_SIZES",
+				Value: s.Int64(api.Compute.Inf * consts.NeuronCoresPerInf / int64(api.Autoscaling.WorkersPerReplica)),
+			},
+			kcore.EnvVar{
+				Name:  "NEURON_RTD_ADDRESS",
+				Value: fmt.Sprintf("unix:%s", _neuronRTDSocket),
+			},
+		)
+	}
+
+	if api.Predictor.Type == userconfig.TensorFlowPredictorType {
+		if container == _tfServingContainerName {
+			envVars = append(envVars,
+				kcore.EnvVar{
+					Name:  "TF_WORKERS",
+					Value: s.Int32(api.Autoscaling.WorkersPerReplica),
+				},
+				kcore.EnvVar{
+					Name:  "CORTEX_TF_BASE_SERVING_PORT",
+					Value: _tfBaseServingPortStr,
+				},
+				kcore.EnvVar{
+					Name:  "CORTEX_MODEL_DIR",
+					Value: path.Join(_emptyDirMountPath, "model"),
+				},
+				kcore.EnvVar{
+					Name:  "TF_EMPTY_MODEL_CONFIG",
+					Value: _tfServingEmptyModelConfig,
+				},
+			)
+			if extraArgs := tfServingExtraArgs(api); len(extraArgs) > 0 {
+				envVars = append(envVars, kcore.EnvVar{
+					Name:  "TF_EXTRA_ARGS",
+					Value: strings.Join(extraArgs, " "),
+				})
+			}
+		}
+		if container == _apiContainerName {
+			envVars = append(envVars,
+				kcore.EnvVar{
+					Name:  "CORTEX_MULTIPLE_TF_SERVERS",
+					Value: "yes",
+				},
+				kcore.EnvVar{
+					Name:  "CORTEX_ACTIVE_NEURON",
+					Value: "yes",
+				},
+			)
+		}
+	}
+
+	return envVars
+}
+
+func (*awsInferentia) modelServerPerWorker() bool {
+	return true
+}
+
+func (*awsInferentia) toleration() kcore.Toleration {
+	return kcore.Toleration{
+		Key:      _inferentiaResource,
+		Operator: kcore.TolerationOpEqual,
+		Value:    "true",
+		Effect:   kcore.TaintEffectNoSchedule,
+	}
+}
+
+// acceleratorVolumes returns the pod's volumes and the volume mounts for its containers
+func acceleratorVolumes(api *spec.API, acc accelerator) ([]kcore.Volume, []kcore.VolumeMount) {
+	volumes := []kcore.Volume{emptyDirVolume(api)}
+	volumeMounts := _defaultVolumeMounts
+	if acc != nil {
+		volumes = append(volumes, acc.volumes()...)
+		volumeMounts = append(volumeMounts, acc.volumeMounts()...)
+	}
+	return volumes, volumeMounts
+}
+
+// splitUserCompute splits the API's CPU and memory requests (less the request monitor sidecar's requests, if the pod has
+// one) between numContainers containers; the first container receives any remainder. Nil is returned for resources
+// which aren't requested
+func splitUserCompute(api *spec.API, numContainers int) ([]kresource.Quantity, []kresource.Quantity) {
+	var cpus, mems []kresource.Quantity
+
+	if api.Compute.CPU != nil {
+		userPodCPURequest := k8s.QuantityPtr(api.Compute.CPU.Quantity.DeepCopy())
+		if hasRequestMonitorSidecar() {
+			userPodCPURequest.Sub(requestMonitorRequests()[kcore.ResourceCPU])
+		}
+		cpus = splitQuantity(userPodCPURequest, numContainers)
+	}
+
+	if api.Compute.Mem != nil {
+		userPodMemRequest := k8s.QuantityPtr(api.Compute.Mem.Quantity.DeepCopy())
+		if hasRequestMonitorSidecar() {
+			userPodMemRequest.Sub(requestMonitorRequests()[kcore.ResourceMemory])
+		}
+		mems = splitQuantity(userPodMemRequest, numContainers)
+	}
+
+	return cpus, mems
+}
+
+// the quantity is not modified if there is only one container, so that it is formatted the same way as the user's request
+func splitQuantity(quantity *kresource.Quantity, n int) []kresource.Quantity {
+	if n == 1 {
+		return []kresource.Quantity{*quantity}
+	}
+	quantities := make([]kresource.Quantity, n)
+	for i, q := range k8s.SplitInN(quantity, n) {
+		quantities[i] = *q
+	}
+	return quantities
+}
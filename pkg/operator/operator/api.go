@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/cortexlabs/cortex/pkg/lib/cron"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
@@ -29,16 +30,25 @@ import (
 	"github.com/cortexlabs/cortex/pkg/operator/config"
 	"github.com/cortexlabs/cortex/pkg/types/spec"
 	"github.com/cortexlabs/cortex/pkg/types/userconfig"
-	istioclientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
 	kapps "k8s.io/api/apps/v1"
 	kcore "k8s.io/api/core/v1"
 	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
 )
 
-var _autoscalerCrons = make(map[string]cron.Cron) // apiName -> cron
+var (
+	_autoscalerCrons      = make(map[string]cron.Cron) // apiName -> cron
+	_autoscalerCronsMutex sync.Mutex
+)
 
+// UpdateAPI creates or updates an API; multiple APIs can be updated concurrently (up to _maxConcurrentAPIUpdates at once)
 func UpdateAPI(apiConfig *userconfig.API, projectID string, force bool) (*spec.API, string, error) {
-	prevDeployment, prevService, prevVirtualService, err := getK8sResources(apiConfig)
+	unlock := lockAPI(apiConfig.Name)
+	defer unlock()
+	release := acquireAPIUpdateSlot()
+	defer release()
+
+	prevDeployment, prevService, prevRoute, err := getK8sResources(apiConfig)
 	if err != nil {
 		return nil, "", err
 	}
@@ -49,24 +59,56 @@ func UpdateAPI(apiConfig *userconfig.API, projectID string, force bool) (*spec.A
 	}
 
 	api := spec.GetAPISpec(apiConfig, projectID, deploymentID)
+	if err := pinArtifacts(api); err != nil {
+		return nil, "", err
+	}
+	if err := optimizeModels(api); err != nil {
+		return nil, "", err
+	}
+	if err := prebuildDependencies(api); err != nil {
+		return nil, "", err
+	}
+	if err := validateDownloadConfig(apiDownloadConfig(api)); err != nil {
+		return nil, "", errors.Wrap(err, api.Identify())
+	}
+
+	if err := ensureSQSQueues(api); err != nil {
+		return nil, "", err
+	}
+	if err := ensureKinesisLeaseTable(api); err != nil {
+		return nil, "", err
+	}
+	if err := ensureBatchTriggerQueue(api); err != nil {
+		return nil, "", err
+	}
 
 	if prevDeployment == nil {
-		if err := config.AWS.UploadMsgpackToS3(api, config.Cluster.Bucket, api.Key); err != nil {
+		if err := ensureNamespace(api.Namespace); err != nil {
+			return nil, "", err
+		}
+		if err := config.Bucket.UploadMsgpack(api, api.Key); err != nil {
 			return nil, "", errors.Wrap(err, "upload api spec")
 		}
-		if err := applyK8sResources(api, prevDeployment, prevService, prevVirtualService); err != nil {
-			go deleteK8sResources(api.Name)
+		if err := applyK8sResources(api, prevDeployment, prevService, prevRoute); err != nil {
+			go deleteK8sResources(api.Name, api.Namespace)
 			return nil, "", err
 		}
-		err = addAPIToAPIGateway(*api.Endpoint, api.Networking.APIGateway)
+		err = addAPIToAPIGateway(api.Endpoints(), api.Networking.APIGateway)
 		if err != nil {
-			go deleteK8sResources(api.Name)
+			go deleteK8sResources(api.Name, api.Namespace)
+			return nil, "", err
+		}
+		if err := updateCompressionEnvoyFilter(); err != nil {
+			go deleteK8sResources(api.Name, api.Namespace)
 			return nil, "", err
 		}
 		err = addAPIToDashboard(config.Cluster.ClusterName, api.Name)
 		if err != nil {
 			errors.PrintError(err)
 		}
+		recordDeploymentEvent(api.Name, api, "create")
+		notifyDeployStarted(api, fmt.Sprintf("creating %s", api.Name))
+		watchRollout(api, "")
 		return api, fmt.Sprintf("creating %s", api.Name), nil
 	}
 
@@ -78,19 +120,32 @@ func UpdateAPI(apiConfig *userconfig.API, projectID string, force bool) (*spec.A
 		if isUpdating && !force {
 			return nil, "", ErrorAPIUpdating(api.Name)
 		}
-		if err := config.AWS.UploadMsgpackToS3(api, config.Cluster.Bucket, api.Key); err != nil {
+		if err := config.Bucket.UploadMsgpack(api, api.Key); err != nil {
 			return nil, "", errors.Wrap(err, "upload api spec")
 		}
-		if err := applyK8sResources(api, prevDeployment, prevService, prevVirtualService); err != nil {
+		if err := applyK8sResources(api, prevDeployment, prevService, prevRoute); err != nil {
+			return nil, "", err
+		}
+		if err := updateAPIGatewayK8s(prevRoute, api); err != nil {
 			return nil, "", err
 		}
-		if err := updateAPIGatewayK8s(prevVirtualService, api); err != nil {
+		if err := updateCompressionEnvoyFilter(); err != nil {
 			return nil, "", err
 		}
+		recordDeploymentEvent(api.Name, api, "update")
+		notifyDeployStarted(api, fmt.Sprintf("updating %s", api.Name))
+		watchRollout(api, prevDeployment.Labels["apiID"])
 		return api, fmt.Sprintf("updating %s", api.Name), nil
 	}
 
 	// deployment didn't change
+	if isAPIPaused(prevDeployment) {
+		if err := resumeDeployment(prevDeployment); err != nil {
+			return nil, "", err
+		}
+		return api, fmt.Sprintf("resuming %s", api.Name), nil
+	}
+
 	isUpdating, err := isAPIUpdating(prevDeployment)
 	if err != nil {
 		return nil, "", err
@@ -101,8 +156,51 @@ func UpdateAPI(apiConfig *userconfig.API, projectID string, force bool) (*spec.A
 	return api, fmt.Sprintf("%s is up to date", api.Name), nil
 }
 
+// RevertAPI restores an API which was deployed as part of an atomic deploy to prevAPI (the spec which was deployed
+// before), or deletes it if it wasn't deployed before
+func RevertAPI(apiName string, prevAPI *spec.API) error {
+	forgetRolloutWatch(apiName)
+
+	if prevAPI == nil {
+		return DeleteAPI(apiName, false)
+	}
+
+	unlock := lockAPI(apiName)
+	defer unlock()
+
+	if err := reapplyAPI(prevAPI); err != nil {
+		return err
+	}
+	recordDeploymentEvent(apiName, prevAPI, "revert")
+	return nil
+}
+
+// reapplyAPI re-applies a previous version of the API's spec (which is still in the bucket)
+func reapplyAPI(prevAPI *spec.API) error {
+	prevDeployment, prevService, prevRoute, err := getK8sResources(prevAPI.API)
+	if err != nil {
+		return err
+	}
+	if prevDeployment == nil {
+		return ErrorAPINotDeployed(prevAPI.Name)
+	}
+
+	if err := applyK8sResources(prevAPI, prevDeployment, prevService, prevRoute); err != nil {
+		return err
+	}
+	if prevRoute != nil {
+		if err := updateAPIGatewayK8s(prevRoute, prevAPI); err != nil {
+			return err
+		}
+	}
+	return updateCompressionEnvoyFilter()
+}
+
 func RefreshAPI(apiName string, force bool) (string, error) {
-	prevDeployment, err := config.K8s.GetDeployment(k8sName(apiName))
+	unlock := lockAPI(apiName)
+	defer unlock()
+
+	prevDeployment, err := getAPIDeployment(apiName)
 	if err != nil {
 		return "", err
 	} else if prevDeployment == nil {
@@ -129,8 +227,17 @@ func RefreshAPI(apiName string, force bool) (string, error) {
 	}
 
 	api = spec.GetAPISpec(api.API, api.ProjectID, k8s.RandomName())
+	if err := pinArtifacts(api); err != nil {
+		return "", err
+	}
+	if err := optimizeModels(api); err != nil {
+		return "", err
+	}
+	if err := prebuildDependencies(api); err != nil {
+		return "", err
+	}
 
-	if err := config.AWS.UploadMsgpackToS3(api, config.Cluster.Bucket, api.Key); err != nil {
+	if err := config.Bucket.UploadMsgpack(api, api.Key); err != nil {
 		return "", errors.Wrap(err, "upload api spec")
 	}
 
@@ -138,19 +245,30 @@ func RefreshAPI(apiName string, force bool) (string, error) {
 		return "", err
 	}
 
+	recordDeploymentEvent(api.Name, api, "refresh")
+	notifyDeployStarted(api, fmt.Sprintf("refreshing %s", api.Name))
+
 	return fmt.Sprintf("updating %s", api.Name), nil
 }
 
 func DeleteAPI(apiName string, keepCache bool) error {
+	unlock := lockAPI(apiName)
+	defer unlock()
+
+	namespace, err := getAPINamespace(apiName)
+	if err != nil {
+		return err
+	}
+
 	// best effort deletion, so don't handle error yet
-	virtualService, vsErr := config.K8s.GetVirtualService(k8sName(apiName))
+	route, routeErr := router().getRoute(apiName, namespace)
 
-	err := parallel.RunFirstErr(
+	err = parallel.RunFirstErr(
 		func() error {
-			return vsErr
+			return routeErr
 		},
 		func() error {
-			return deleteK8sResources(apiName)
+			return deleteK8sResources(apiName, namespace)
 		},
 		func() error {
 			if keepCache {
@@ -162,7 +280,7 @@ func DeleteAPI(apiName string, keepCache bool) error {
 		},
 		// delete API from API Gateway
 		func() error {
-			err := removeAPIFromAPIGatewayK8s(virtualService)
+			err := removeAPIFromAPIGatewayK8s(route)
 			if err != nil {
 				return err
 			}
@@ -170,7 +288,7 @@ func DeleteAPI(apiName string, keepCache bool) error {
 		},
 		// delete api from cloudwatch
 		func() error {
-			statuses, err := GetAllStatuses()
+			statuses, err := GetAllStatuses(klabels.Everything())
 			if err != nil {
 				return errors.Wrap(err, "failed to get API Statuses")
 			}
@@ -191,36 +309,59 @@ func DeleteAPI(apiName string, keepCache bool) error {
 		return err
 	}
 
+	if err := updateCompressionEnvoyFilter(); err != nil {
+		return err
+	}
+
+	deleteMaintenanceMode(apiName)
+	if err := updateMaintenanceEnvoyFilter(); err != nil {
+		return err
+	}
+
+	recordDeploymentEvent(apiName, nil, "delete")
+	deleteAPIOwner(apiName)
+	deleteAPIActivity(apiName)
+	forgetAPINotifications(apiName)
+	forgetRolloutWatch(apiName)
+
 	return nil
 }
 
-func getK8sResources(apiConfig *userconfig.API) (*kapps.Deployment, *kcore.Service, *istioclientnetworking.VirtualService, error) {
+func getK8sResources(apiConfig *userconfig.API) (*kapps.Deployment, *kcore.Service, *apiRoute, error) {
 	var deployment *kapps.Deployment
 	var service *kcore.Service
-	var virtualService *istioclientnetworking.VirtualService
+	var route *apiRoute
+
+	k8sNamespace := config.K8sNamespace(apiConfig.Namespace)
 
 	err := parallel.RunFirstErr(
 		func() error {
 			var err error
-			deployment, err = config.K8s.GetDeployment(k8sName(apiConfig.Name))
-			return err
+			deployment, err = getAPIDeployment(apiConfig.Name)
+			if err != nil {
+				return err
+			}
+			if deployment != nil && deployment.Namespace != apiConfig.Namespace {
+				return ErrorCannotChangeNamespace(apiConfig.Name, deployment.Namespace, apiConfig.Namespace)
+			}
+			return nil
 		},
 		func() error {
 			var err error
-			service, err = config.K8s.GetService(k8sName(apiConfig.Name))
+			service, err = k8sNamespace.GetService(k8sName(apiConfig.Name))
 			return err
 		},
 		func() error {
 			var err error
-			virtualService, err = config.K8s.GetVirtualService(k8sName(apiConfig.Name))
+			route, err = router().getRoute(apiConfig.Name, apiConfig.Namespace)
 			return err
 		},
 	)
 
-	return deployment, service, virtualService, err
+	return deployment, service, route, err
 }
 
-func applyK8sResources(api *spec.API, prevDeployment *kapps.Deployment, prevService *kcore.Service, prevVirtualService *istioclientnetworking.VirtualService) error {
+func applyK8sResources(api *spec.API, prevDeployment *kapps.Deployment, prevService *kcore.Service, prevRoute *apiRoute) error {
 	return parallel.RunFirstErr(
 		func() error {
 			return applyK8sDeployment(api, prevDeployment)
@@ -229,28 +370,48 @@ func applyK8sResources(api *spec.API, prevDeployment *kapps.Deployment, prevServ
 			return applyK8sService(api, prevService)
 		},
 		func() error {
-			return applyK8sVirtualService(api, prevVirtualService)
+			return router().applyRoute(api, prevRoute)
+		},
+		func() error {
+			return applyK8sDestinationRule(api)
+		},
+		func() error {
+			return applyK8sMTLSPolicies(api)
+		},
+		func() error {
+			return applyK8sScaledObject(api)
+		},
+		func() error {
+			return applyK8sBatchingConfigMap(api)
+		},
+		func() error {
+			return applyK8sScratchVolume(api)
+		},
+		func() error {
+			return applyK8sParallelWorkers(api)
 		},
 	)
 }
 
 func applyK8sDeployment(api *spec.API, prevDeployment *kapps.Deployment) error {
 	newDeployment := deploymentSpec(api, prevDeployment)
+	newDeployment.Namespace = api.Namespace
+	k8sNamespace := config.K8sNamespace(api.Namespace)
 
 	if prevDeployment == nil {
-		_, err := config.K8s.CreateDeployment(newDeployment)
+		_, err := k8sNamespace.CreateDeployment(newDeployment)
 		if err != nil {
 			return err
 		}
 	} else if prevDeployment.Status.ReadyReplicas == 0 {
 		// Delete deployment if it never became ready
-		config.K8s.DeleteDeployment(k8sName(api.Name))
-		_, err := config.K8s.CreateDeployment(newDeployment)
+		k8sNamespace.DeleteDeployment(k8sName(api.Name))
+		_, err := k8sNamespace.CreateDeployment(newDeployment)
 		if err != nil {
 			return err
 		}
 	} else {
-		_, err := config.K8s.UpdateDeployment(newDeployment)
+		_, err := k8sNamespace.UpdateDeployment(newDeployment)
 		if err != nil {
 			return err
 		}
@@ -260,14 +421,26 @@ func applyK8sDeployment(api *spec.API, prevDeployment *kapps.Deployment) error {
 		return err
 	}
 
+	recordAPIActivity(api.Name)
+	prescaleInBackground(newDeployment)
+
 	return nil
 }
 
 func updateAutoscalerCron(deployment *kapps.Deployment) error {
 	apiName := deployment.Labels["apiName"]
 
-	if prevAutoscalerCron, ok := _autoscalerCrons[apiName]; ok {
-		prevAutoscalerCron.Cancel()
+	cancelAutoscalerCron(apiName)
+
+	autoscalingSpec, err := userconfig.AutoscalingFromAnnotations(deployment)
+	if err != nil {
+		return err
+	}
+	if autoscalingSpec.Autoscaler == userconfig.KEDAAutoscalerType {
+		return nil // the API's replicas are managed by KEDA (see applyK8sScaledObject)
+	}
+	if !config.Cluster.HasAWSResources() {
+		return nil // without the request monitor, the API keeps its minimum number of replicas
 	}
 
 	autoscaler, err := autoscaleFn(deployment)
@@ -275,65 +448,181 @@ func updateAutoscalerCron(deployment *kapps.Deployment) error {
 		return err
 	}
 
+	_autoscalerCronsMutex.Lock()
 	_autoscalerCrons[apiName] = cron.Run(autoscaler, cronErrHandler(apiName+" autoscaler"), spec.AutoscalingTickInterval)
+	_autoscalerCronsMutex.Unlock()
 
 	return nil
 }
 
+func cancelAutoscalerCron(apiName string) {
+	_autoscalerCronsMutex.Lock()
+	defer _autoscalerCronsMutex.Unlock()
+
+	if autoscalerCron, ok := _autoscalerCrons[apiName]; ok {
+		autoscalerCron.Cancel()
+		delete(_autoscalerCrons, apiName)
+	}
+}
+
 func applyK8sService(api *spec.API, prevService *kcore.Service) error {
 	newService := serviceSpec(api)
 
+	k8sNamespace := config.K8sNamespace(api.Namespace)
+
 	if prevService == nil {
-		_, err := config.K8s.CreateService(newService)
+		_, err := k8sNamespace.CreateService(newService)
+		return err
+	}
+
+	_, err := k8sNamespace.UpdateService(prevService, newService)
+	return err
+}
+
+func applyK8sDestinationRule(api *spec.API) error {
+	if !isIstioNetworking() {
+		return nil
+	}
+
+	k8sNamespace := config.K8sNamespace(api.Namespace)
+
+	if !needsDestinationRule(api) {
+		_, err := k8sNamespace.DeleteDestinationRule(k8sName(api.Name))
 		return err
 	}
 
-	_, err := config.K8s.UpdateService(prevService, newService)
+	_, err := k8sNamespace.ApplyDestinationRule(destinationRuleSpec(api))
 	return err
 }
 
-func applyK8sVirtualService(api *spec.API, prevVirtualService *istioclientnetworking.VirtualService) error {
-	newVirtualService := virtualServiceSpec(api)
+func applyK8sMTLSPolicies(api *spec.API) error {
+	if !isIstioNetworking() {
+		return nil
+	}
+
+	k8sNamespace := config.K8sNamespace(api.Namespace)
+
+	if !apiMTLS(api.API) {
+		if _, err := k8sNamespace.DeleteAuthenticationPolicy(k8sName(api.Name)); err != nil {
+			return err
+		}
+		_, err := k8sNamespace.DeleteAuthorizationPolicy(k8sName(api.Name))
+		return err
+	}
+
+	if _, err := k8sNamespace.ApplyAuthenticationPolicy(authenticationPolicySpec(api)); err != nil {
+		return err
+	}
+	_, err := k8sNamespace.ApplyAuthorizationPolicy(authorizationPolicySpec(api))
+	return err
+}
+
+// TensorFlow Serving reads its batching parameters from a config map; other predictor types are configured with env vars
+func applyK8sBatchingConfigMap(api *spec.API) error {
+	k8sNamespace := config.K8sNamespace(api.Namespace)
 
-	if prevVirtualService == nil {
-		_, err := config.K8s.CreateVirtualService(newVirtualService)
+	if api.Predictor.Type != userconfig.TensorFlowPredictorType || api.Predictor.Batching == nil {
+		_, err := k8sNamespace.DeleteConfigMap(k8sName(api.Name))
 		return err
 	}
 
-	_, err := config.K8s.UpdateVirtualService(prevVirtualService, newVirtualService)
+	_, err := k8sNamespace.ApplyConfigMap(tfServingBatchingConfigMap(api))
 	return err
 }
 
-func deleteK8sResources(apiName string) error {
+// the replicas wait for the claim to be bound before they start, so it doesn't need to be created before the deployment
+func applyK8sScratchVolume(api *spec.API) error {
+	k8sNamespace := config.K8sNamespace(api.Namespace)
+
+	if api.Compute.ScratchVolume == nil {
+		// the volume isn't deleted until the previous replicas have stopped using it
+		_, err := k8sNamespace.DeletePVC(scratchVolumeClaimName(api.Name))
+		return err
+	}
+
+	prevClaim, err := k8sNamespace.GetPVC(scratchVolumeClaimName(api.Name))
+	if err != nil {
+		return err
+	}
+	if prevClaim == nil {
+		_, err := k8sNamespace.CreatePVC(scratchVolumeClaimSpec(api))
+		return err
+	}
+
+	// only the size of an existing claim can be changed (see validateK8sScratchVolume); the volume is expanded if its storage class allows it
+	size := api.Compute.ScratchVolume.Size.Quantity
+	if size.Cmp(prevClaim.Spec.Resources.Requests[kcore.ResourceStorage]) <= 0 {
+		return nil
+	}
+	prevClaim.Spec.Resources.Requests[kcore.ResourceStorage] = size
+	_, err = k8sNamespace.UpdatePVC(prevClaim)
+	return err
+}
+
+func deleteK8sResources(apiName string, namespace string) error {
+	k8sNamespace := config.K8sNamespace(namespace)
+
 	return parallel.RunFirstErr(
 		func() error {
-			if autoscalerCron, ok := _autoscalerCrons[apiName]; ok {
-				autoscalerCron.Cancel()
-				delete(_autoscalerCrons, apiName)
-			}
+			cancelAutoscalerCron(apiName)
 
-			_, err := config.K8s.DeleteDeployment(k8sName(apiName))
+			_, err := k8sNamespace.DeleteDeployment(k8sName(apiName))
+			return err
+		},
+		func() error {
+			_, err := k8sNamespace.DeleteService(k8sName(apiName))
+			return err
+		},
+		func() error {
+			return router().deleteRoute(apiName, namespace)
+		},
+		func() error {
+			if !isIstioNetworking() {
+				return nil
+			}
+			_, err := k8sNamespace.DeleteDestinationRule(k8sName(apiName))
 			return err
 		},
 		func() error {
-			_, err := config.K8s.DeleteService(k8sName(apiName))
+			if !isIstioNetworking() {
+				return nil
+			}
+			if _, err := k8sNamespace.DeleteAuthenticationPolicy(k8sName(apiName)); err != nil {
+				return err
+			}
+			_, err := k8sNamespace.DeleteAuthorizationPolicy(k8sName(apiName))
 			return err
 		},
 		func() error {
-			_, err := config.K8s.DeleteVirtualService(k8sName(apiName))
+			_, err := k8sNamespace.DeleteCustomResource(_scaledObjectResource, k8sName(apiName))
 			return err
 		},
+		func() error {
+			_, err := k8sNamespace.DeleteConfigMap(k8sName(apiName))
+			return err
+		},
+		func() error {
+			_, err := k8sNamespace.DeletePVC(scratchVolumeClaimName(apiName))
+			return err
+		},
+		func() error {
+			return deleteParallelWorkers(apiName, namespace)
+		},
 	)
 }
 
 func deleteS3Resources(apiName string) error {
 	prefix := filepath.Join("apis", apiName)
-	return config.AWS.DeleteS3Dir(config.Cluster.Bucket, prefix, true)
+	return config.Bucket.DeleteDir(prefix)
 }
 
 // returns true if min_replicas are not ready and no updated replicas have errored
 func isAPIUpdating(deployment *kapps.Deployment) (bool, error) {
-	pods, err := config.K8s.ListPodsByLabel("apiName", deployment.Labels["apiName"])
+	if isAPIPaused(deployment) {
+		return false, nil
+	}
+
+	pods, err := config.K8sNamespace(deployment.Namespace).ListPodsByLabel("apiName", deployment.Labels["apiName"])
 	if err != nil {
 		return false, err
 	}
@@ -365,7 +654,18 @@ func areAPIsEqual(d1, d2 *kapps.Deployment) bool {
 		d1.Labels["apiName"] == d2.Labels["apiName"] &&
 		d1.Labels["apiID"] == d2.Labels["apiID"] &&
 		d1.Labels["deploymentID"] == d2.Labels["deploymentID"] &&
-		doCortexAnnotationsMatch(d1, d2)
+		doCortexAnnotationsMatch(d1, d2) &&
+		doSpecHashesMatch(d1, d2)
+}
+
+// deployments which were created before the spec hash was recorded don't have one, in which case they are compared
+// using the other checks in areAPIsEqual (rather than rolling out every API when the operator is upgraded)
+func doSpecHashesMatch(d1, d2 *kapps.Deployment) bool {
+	hash1, hash2 := d1.Annotations[_specHashAnnotationKey], d2.Annotations[_specHashAnnotationKey]
+	if hash1 == "" || hash2 == "" {
+		return true
+	}
+	return hash1 == hash2
 }
 
 func doCortexAnnotationsMatch(obj1, obj2 kmeta.Object) bool {
@@ -377,6 +677,10 @@ func doCortexAnnotationsMatch(obj1, obj2 kmeta.Object) bool {
 func extractCortexAnnotations(obj kmeta.Object) map[string]string {
 	cortexAnnotations := make(map[string]string)
 	for key, value := range obj.GetAnnotations() {
+		// the spec hash is compared separately, and the time at which the API was paused isn't part of its spec
+		if key == _specHashAnnotationKey || key == _pausedAnnotationKey {
+			continue
+		}
 		if strings.Contains(key, "cortex.dev/") {
 			cortexAnnotations[key] = value
 		}
@@ -385,7 +689,7 @@ func extractCortexAnnotations(obj kmeta.Object) map[string]string {
 }
 
 func IsAPIDeployed(apiName string) (bool, error) {
-	deployment, err := config.K8s.GetDeployment(k8sName(apiName))
+	deployment, err := getAPIDeployment(apiName)
 	if err != nil {
 		return false, err
 	}
@@ -394,7 +698,7 @@ func IsAPIDeployed(apiName string) (bool, error) {
 
 // APIBaseURL returns BaseURL of the API without resource endpoint
 func APIBaseURL(api *spec.API) (string, error) {
-	if api.Networking.APIGateway == userconfig.PublicAPIGatewayType {
+	if usesAPIGateway(api.Networking.APIGateway) {
 		return *config.Cluster.APIGateway.ApiEndpoint, nil
 	}
 	return APILoadBalancerURL()
@@ -402,24 +706,14 @@ func APIBaseURL(api *spec.API) (string, error) {
 
 // APILoadBalancerURL returns http endpoint of cluster ingress elb
 func APILoadBalancerURL() (string, error) {
-	service, err := config.K8sIstio.GetService("ingressgateway-apis")
-	if err != nil {
-		return "", err
-	}
-	if service == nil {
-		return "", ErrorCortexInstallationBroken()
-	}
-	if len(service.Status.LoadBalancer.Ingress) == 0 {
-		return "", ErrorLoadBalancerInitializing()
-	}
-	return "http://" + service.Status.LoadBalancer.Ingress[0].Hostname, nil
+	return router().loadBalancerURL()
 }
 
 func DownloadAPISpec(apiName string, apiID string) (*spec.API, error) {
 	s3Key := spec.Key(apiName, apiID)
 	var api spec.API
 
-	if err := config.AWS.ReadMsgpackFromS3(&api, config.Cluster.Bucket, s3Key); err != nil {
+	if err := config.Bucket.ReadMsgpack(&api, s3Key); err != nil {
 		return nil, err
 	}
 
@@ -451,11 +745,3 @@ func DownloadAPISpecs(apiNames []string, apiIDs []string) ([]spec.API, error) {
 
 	return apis, nil
 }
-
-func GetEndpointFromVirtualService(virtualService *istioclientnetworking.VirtualService) (string, error) {
-	endpoints := k8s.ExtractVirtualServiceEndpoints(virtualService)
-	if len(endpoints) != 1 {
-		return "", errors.ErrorUnexpected("expected 1 endpoint, but got", endpoints)
-	}
-	return endpoints.GetOne(), nil
-}
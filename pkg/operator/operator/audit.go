@@ -0,0 +1,143 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+)
+
+const (
+	// audit events are never deleted (not even when their API is deleted)
+	_auditLogKind = "audit"
+	// the caller of actions which the operator takes on its own (e.g. pausing idle APIs)
+	_operatorAuditCaller = "operator"
+)
+
+// RecordAuditEvent is best effort; failures are printed but don't affect the action. actionErr is the error which the
+// action returned (if any), since failed attempts are recorded as well
+func RecordAuditEvent(event schema.AuditEvent, actionErr error) {
+	now := time.Now()
+	event.Timestamp = now.Unix()
+	if actionErr != nil {
+		event.Error = errors.Message(actionErr)
+	}
+
+	// zero-padded so that keys sort chronologically
+	key := fmt.Sprintf("%s/%020d", event.APIName, now.UnixNano())
+	if err := config.Metadata.Put(_auditLogKind, key, event); err != nil {
+		errors.PrintError(err, "failed to record audit event")
+	}
+}
+
+// GetDeployedAPISpec returns nil if the API is not deployed
+func GetDeployedAPISpec(apiName string) (*spec.API, error) {
+	deployment, err := getAPIDeployment(apiName)
+	if err != nil || deployment == nil {
+		return nil, err
+	}
+
+	apiID, err := k8s.GetLabel(deployment, "apiID")
+	if err != nil {
+		return nil, err
+	}
+
+	return DownloadAPISpec(apiName, apiID)
+}
+
+// DiffAPIConfigs returns the fields of the API's configuration which differ between prevAPI and api (either may be nil)
+func DiffAPIConfigs(prevAPI *spec.API, api *spec.API) ([]k8s.FieldDiff, error) {
+	prevAPIConfig := auditableAPIConfig(prevAPI)
+	apiConfig := auditableAPIConfig(api)
+
+	changes, err := k8s.Diff(prevAPIConfig, apiConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// k8s.Diff ignores fields which are only set in the first object, so diff in the other direction to find removed fields
+	removals, err := k8s.Diff(apiConfig, prevAPIConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	changedPaths := make(map[string]bool, len(changes))
+	for _, change := range changes {
+		changedPaths[change.Path] = true
+	}
+	for _, removal := range removals {
+		if removal.Current == nil && !changedPaths[removal.Path] {
+			changes = append(changes, k8s.FieldDiff{Path: removal.Path, Current: removal.Desired, Desired: nil})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes, nil
+}
+
+// the index and file path of an API depend on how its configuration file was organized, so they are not audited
+func auditableAPIConfig(api *spec.API) *userconfig.API {
+	if api == nil || api.API == nil {
+		return nil
+	}
+	apiConfig := *api.API
+	apiConfig.Index = 0
+	apiConfig.FilePath = ""
+	return &apiConfig
+}
+
+// GetAuditLog returns the audit events of the API (or all APIs if apiName is empty) which were recorded between startTime and endTime, in chronological order
+func GetAuditLog(apiName string, startTime time.Time, endTime time.Time) ([]schema.AuditEvent, error) {
+	keyPrefix := ""
+	if apiName != "" {
+		keyPrefix = apiName + "/"
+	}
+
+	items, err := config.Metadata.List(_auditLogKind, keyPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []schema.AuditEvent{}
+	for _, item := range items {
+		var event schema.AuditEvent
+		if err := item.Unmarshal(&event); err != nil {
+			return nil, err
+		}
+		if event.Timestamp < startTime.Unix() || event.Timestamp > endTime.Unix() {
+			continue
+		}
+		events = append(events, event)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp < events[j].Timestamp
+	})
+
+	return events, nil
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"testing"
+
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffAPIConfigs(t *testing.T) {
+	prevAPI := testAPI(userconfig.PythonPredictorType, userconfig.Compute{})
+	prevAPI.Predictor.Env["DEBUG"] = "true"
+	prevAPI.Index = 1
+
+	api := testAPI(userconfig.PythonPredictorType, userconfig.Compute{})
+	api.Predictor.Env["LOG_LEVEL"] = "debug"
+	api.Autoscaling.MaxReplicas = 20
+
+	changes, err := DiffAPIConfigs(prevAPI, api)
+	require.NoError(t, err)
+	require.Equal(t, []k8s.FieldDiff{
+		{Path: "autoscaling.max_replicas", Current: float64(10), Desired: float64(20)},
+		{Path: "predictor.env.DEBUG", Current: "true", Desired: nil},
+		{Path: "predictor.env.LOG_LEVEL", Current: "info", Desired: "debug"},
+	}, changes)
+
+	changes, err = DiffAPIConfigs(nil, api)
+	require.NoError(t, err)
+	require.NotEmpty(t, changes)
+	for _, change := range changes {
+		require.Nil(t, change.Current)
+	}
+}
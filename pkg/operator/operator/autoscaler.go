@@ -17,6 +17,7 @@ limitations under the License.
 package operator
 
 import (
+	"fmt"
 	"log"
 	"math"
 	"time"
@@ -27,7 +28,6 @@ import (
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	libtime "github.com/cortexlabs/cortex/pkg/lib/time"
 	"github.com/cortexlabs/cortex/pkg/operator/config"
-	"github.com/cortexlabs/cortex/pkg/types/spec"
 	"github.com/cortexlabs/cortex/pkg/types/userconfig"
 	kapps "k8s.io/api/apps/v1"
 )
@@ -96,6 +96,7 @@ func autoscaleFn(initialDeployment *kapps.Deployment) (func() error, error) {
 	log.Printf("%s autoscaler init", apiName)
 
 	var startTime time.Time
+	var lastScalingEventTime time.Time
 	recs := make(recommendations)
 
 	return func() error {
@@ -135,6 +136,14 @@ func autoscaleFn(initialDeployment *kapps.Deployment) (func() error, error) {
 			recommendation = upscaleFactorCeil
 		}
 
+		if autoscalingSpec.MaxDownscaleStep != nil && recommendation < currentReplicas-*autoscalingSpec.MaxDownscaleStep {
+			recommendation = currentReplicas - *autoscalingSpec.MaxDownscaleStep
+		}
+
+		if autoscalingSpec.MaxUpscaleStep != nil && recommendation > currentReplicas+*autoscalingSpec.MaxUpscaleStep {
+			recommendation = currentReplicas + *autoscalingSpec.MaxUpscaleStep
+		}
+
 		if recommendation < 1 {
 			recommendation = 1
 		}
@@ -174,23 +183,45 @@ func autoscaleFn(initialDeployment *kapps.Deployment) (func() error, error) {
 			request = *upscaleStabilizationCeil
 		}
 
-		log.Printf("%s autoscaler tick: avg_in_flight=%s, target_replica_concurrency=%s, raw_recommendation=%s, current_replicas=%d, downscale_tolerance=%s, upscale_tolerance=%s, max_downscale_factor=%s, downscale_factor_floor=%d, max_upscale_factor=%s, upscale_factor_ceil=%d, min_replicas=%d, max_replicas=%d, recommendation=%d, downscale_stabilization_period=%s, downscale_stabilization_floor=%s, upscale_stabilization_period=%s, upscale_stabilization_ceil=%s, request=%d", apiName, s.Round(*avgInFlight, 2, 0), s.Float64(*autoscalingSpec.TargetReplicaConcurrency), s.Round(rawRecommendation, 2, 0), currentReplicas, s.Float64(autoscalingSpec.DownscaleTolerance), s.Float64(autoscalingSpec.UpscaleTolerance), s.Float64(autoscalingSpec.MaxDownscaleFactor), downscaleFactorFloor, s.Float64(autoscalingSpec.MaxUpscaleFactor), upscaleFactorCeil, autoscalingSpec.MinReplicas, autoscalingSpec.MaxReplicas, recommendation, autoscalingSpec.DownscaleStabilizationPeriod, s.ObjFlatNoQuotes(downscaleStabilizationFloor), autoscalingSpec.UpscaleStabilizationPeriod, s.ObjFlatNoQuotes(upscaleStabilizationCeil), request)
+		// cooldowns are measured from the most recent scaling event in either direction
+		if !lastScalingEventTime.IsZero() {
+			if request < currentReplicas && time.Since(lastScalingEventTime) < autoscalingSpec.DownscaleCooldown {
+				request = currentReplicas
+			}
+			if request > currentReplicas && time.Since(lastScalingEventTime) < autoscalingSpec.UpscaleCooldown {
+				request = currentReplicas
+			}
+		}
+
+		log.Printf("%s autoscaler tick: avg_in_flight=%s, target_replica_concurrency=%s, raw_recommendation=%s, current_replicas=%d, downscale_tolerance=%s, upscale_tolerance=%s, max_downscale_factor=%s, downscale_factor_floor=%d, max_upscale_factor=%s, upscale_factor_ceil=%d, min_replicas=%d, max_replicas=%d, recommendation=%d, downscale_stabilization_period=%s, downscale_stabilization_floor=%s, upscale_stabilization_period=%s, upscale_stabilization_ceil=%s, max_downscale_step=%s, max_upscale_step=%s, downscale_cooldown=%s, upscale_cooldown=%s, request=%d", apiName, s.Round(*avgInFlight, 2, 0), s.Float64(*autoscalingSpec.TargetReplicaConcurrency), s.Round(rawRecommendation, 2, 0), currentReplicas, s.Float64(autoscalingSpec.DownscaleTolerance), s.Float64(autoscalingSpec.UpscaleTolerance), s.Float64(autoscalingSpec.MaxDownscaleFactor), downscaleFactorFloor, s.Float64(autoscalingSpec.MaxUpscaleFactor), upscaleFactorCeil, autoscalingSpec.MinReplicas, autoscalingSpec.MaxReplicas, recommendation, autoscalingSpec.DownscaleStabilizationPeriod, s.ObjFlatNoQuotes(downscaleStabilizationFloor), autoscalingSpec.UpscaleStabilizationPeriod, s.ObjFlatNoQuotes(upscaleStabilizationCeil), s.ObjFlatNoQuotes(autoscalingSpec.MaxDownscaleStep), s.ObjFlatNoQuotes(autoscalingSpec.MaxUpscaleStep), autoscalingSpec.DownscaleCooldown, autoscalingSpec.UpscaleCooldown, request)
 
 		if currentReplicas != request {
 			log.Printf("%s autoscaling event: %d -> %d", apiName, currentReplicas, request)
 
-			deployment, err := config.K8s.GetDeployment(initialDeployment.Name)
+			k8sNamespace := config.K8sNamespace(initialDeployment.Namespace)
+
+			deployment, err := k8sNamespace.GetDeployment(initialDeployment.Name)
 			if err != nil {
 				return err
 			}
 
 			deployment.Spec.Replicas = &request
 
-			if _, err := config.K8s.UpdateDeployment(deployment); err != nil {
+			updatedDeployment, err := k8sNamespace.UpdateDeployment(deployment)
+			if err != nil {
 				return err
 			}
 
+			if request > currentReplicas {
+				prescaleInBackground(updatedDeployment)
+			}
+
+			if request == autoscalingSpec.MaxReplicas && request > currentReplicas {
+				notify(apiName, initialDeployment.Labels["apiID"], _scaledToMaxEvent, fmt.Sprintf("%s scaled up to its max_replicas (%d); if traffic keeps increasing, requests may be queued or rejected", apiName, request))
+			}
+
 			currentReplicas = request
+			lastScalingEventTime = time.Now()
 		}
 
 		return nil
@@ -219,7 +250,7 @@ func getInflightRequests(apiName string, window time.Duration) (*float64, error)
 						},
 					},
 					Stat:   aws.String("Sum"),
-					Period: aws.Int64(10),
+					Period: aws.Int64(config.Cluster.RequestMonitorFlushInterval),
 				},
 			},
 		},
@@ -235,7 +266,7 @@ func getInflightRequests(apiName string, window time.Duration) (*float64, error)
 
 	timestampCounter := -1
 	for i, timeStamp := range output.MetricDataResults[0].Timestamps {
-		if endTime.Sub(*timeStamp) < 20*time.Second {
+		if endTime.Sub(*timeStamp) < 2*requestMonitorFlushInterval() {
 			timestampCounter = i
 		} else {
 			break
@@ -243,10 +274,10 @@ func getInflightRequests(apiName string, window time.Duration) (*float64, error)
 	}
 
 	if timestampCounter == -1 {
-		return nil, nil // no metrics were available in the last 2 tick intervals
+		return nil, nil // no metrics were available in the last 2 flush intervals
 	}
 
-	steps := int(window.Nanoseconds() / spec.AutoscalingTickInterval.Nanoseconds())
+	steps := int(window.Nanoseconds() / requestMonitorFlushInterval().Nanoseconds())
 
 	endTimeStampCounter := libmath.MinInt(timestampCounter+steps, len(output.MetricDataResults[0].Timestamps))
 
@@ -0,0 +1,369 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	klabels "k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	_backupManifestFile = "backup.json"
+	_backupProjectsDir  = "projects"
+)
+
+// backupManifest is stored at the root of a backup's directory; each API's project is stored in the projects directory
+type backupManifest struct {
+	Backup schema.Backup `json:"backup"`
+	APIs   []backupAPI   `json:"apis"`
+}
+
+type backupAPI struct {
+	Spec            spec.API         `json:"spec"`
+	CortexAPI       *cortexAPISpec   `json:"cortex_api,omitempty"`
+	Owner           *apiOwner        `json:"owner,omitempty"`
+	Maintenance     *maintenanceMode `json:"maintenance,omitempty"`
+	Paused          bool             `json:"paused"`
+	RouteWeights    map[string]int32 `json:"route_weights,omitempty"` // service name -> weight (only for experiments)
+	PromotedVariant string           `json:"promoted_variant,omitempty"`
+}
+
+// backupStorage reads and writes the files in a backup's directory: backups in the cluster's bucket are accessed through
+// config.Bucket (so they work with any provider), and backups at other S3 paths are accessed with the AWS client
+type backupStorage struct {
+	s3Bucket string // empty if the backup is in the cluster's bucket
+	prefix   string
+}
+
+func getBackupStorage(path string) (*backupStorage, error) {
+	if key, ok := clusterBucketKey(path); ok {
+		return &backupStorage{
+			prefix: strings.Trim(key, "/"),
+		}, nil
+	}
+
+	bucket, prefix, err := aws.SplitS3Path(path)
+	if err != nil {
+		return nil, err
+	}
+	return &backupStorage{
+		s3Bucket: bucket,
+		prefix:   prefix,
+	}, nil
+}
+
+func (store *backupStorage) uploadBytes(data []byte, fileName string) error {
+	key := filepath.Join(store.prefix, fileName)
+	if store.s3Bucket == "" {
+		return config.Bucket.UploadBytes(data, key)
+	}
+	return config.AWS.UploadBytesToS3(data, store.s3Bucket, key)
+}
+
+func (store *backupStorage) readBytes(fileName string) ([]byte, error) {
+	key := filepath.Join(store.prefix, fileName)
+	if store.s3Bucket == "" {
+		return config.Bucket.ReadBytes(key)
+	}
+	return config.AWS.ReadBytesFromS3(store.s3Bucket, key)
+}
+
+func (store *backupStorage) exists(fileName string) (bool, error) {
+	key := filepath.Join(store.prefix, fileName)
+	if store.s3Bucket == "" {
+		return config.Bucket.Exists(key)
+	}
+	return config.AWS.IsS3File(store.s3Bucket, key)
+}
+
+// CreateBackup writes the specs, projects, and state of the cluster's APIs to the directory at path, which is in the
+// cluster's bucket or another S3 bucket (if path is empty, a new directory in the cluster's bucket is used)
+func CreateBackup(path string) (*schema.Backup, error) {
+	createdAt := time.Now().UTC()
+	if path == "" {
+		path = config.Bucket.Path(filepath.Join("backups", createdAt.Format("2006-01-02-15-04-05")))
+	}
+	store, err := getBackupStorage(path)
+	if err != nil {
+		return nil, err
+	}
+
+	deployments, err := listAPIDeployments(klabels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	cortexAPIs, err := getCortexAPIs()
+	if err != nil {
+		return nil, err
+	}
+	cortexAPISpecs := make(map[string]cortexAPISpec, len(cortexAPIs)) // apiName -> spec
+	for _, capi := range cortexAPIs {
+		// the API is backed up without its CortexAPI if the CortexAPI's configuration can't be reconciled
+		if referencesTemplate(capi.Spec.Config) {
+			continue
+		}
+		cortexAPISpecs[capi.Name] = capi.Spec
+	}
+
+	manifest := backupManifest{
+		Backup: schema.Backup{
+			Path:          path,
+			ClusterName:   config.Cluster.ClusterName,
+			CortexVersion: consts.CortexVersion,
+			CreatedAt:     createdAt,
+		},
+	}
+
+	backedUpProjects := strset.New()
+	for i := range deployments {
+		deployment := &deployments[i]
+		apiName := deployment.Labels["apiName"]
+
+		api, err := DownloadAPISpec(apiName, deployment.Labels["apiID"])
+		if err != nil {
+			return nil, errors.Wrap(err, apiName)
+		}
+
+		apiBackup, err := getAPIBackup(api)
+		if err != nil {
+			return nil, errors.Wrap(err, apiName)
+		}
+		apiBackup.Paused = isAPIPaused(deployment)
+		if capiSpec, ok := cortexAPISpecs[apiName]; ok {
+			apiBackup.CortexAPI = &capiSpec
+		}
+
+		if !backedUpProjects.Has(api.ProjectID) {
+			projectBytes, err := config.Bucket.ReadBytes(api.ProjectKey)
+			if err != nil {
+				return nil, errors.Wrap(err, apiName, "project")
+			}
+			if err := store.uploadBytes(projectBytes, backupProjectFile(api.ProjectID)); err != nil {
+				return nil, err
+			}
+			backedUpProjects.Add(api.ProjectID)
+		}
+
+		manifest.APIs = append(manifest.APIs, *apiBackup)
+		manifest.Backup.APIs = append(manifest.Backup.APIs, apiName)
+	}
+
+	// the manifest is written last, so that incomplete backups can't be restored
+	manifestBytes, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.uploadBytes(manifestBytes, _backupManifestFile); err != nil {
+		return nil, err
+	}
+
+	return &manifest.Backup, nil
+}
+
+func getAPIBackup(api *spec.API) (*backupAPI, error) {
+	apiBackup := backupAPI{
+		Spec: *api,
+	}
+
+	var owner apiOwner
+	exists, err := config.Metadata.Get(_apiOwnersKind, api.Name, &owner)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		apiBackup.Owner = &owner
+	}
+
+	var mode maintenanceMode
+	exists, err = config.Metadata.Get(_maintenanceKind, api.Name, &mode)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		apiBackup.Maintenance = &mode
+	}
+
+	// the experiment's weights may have been changed by its bandit, or by promoting its winner
+	if api.Experiment != nil && isIstioNetworking() {
+		virtualService, err := config.K8sNamespace(api.Namespace).GetVirtualService(k8sName(api.Name))
+		if err != nil {
+			return nil, err
+		}
+		if virtualService != nil {
+			apiBackup.RouteWeights = routeWeights(virtualService)
+			apiBackup.PromotedVariant = virtualService.Annotations[_experimentPromotedAnnotationKey]
+		}
+	}
+
+	return &apiBackup, nil
+}
+
+// RestoreBackup deploys the APIs in the backup at path, and restores their state; the results are in the order of the
+// backup's APIs
+func RestoreBackup(path string, force bool) (*schema.Backup, []schema.DeployResult, error) {
+	store, err := getBackupStorage(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	exists, err := store.exists(_backupManifestFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !exists {
+		return nil, nil, ErrorInvalidBackup(path)
+	}
+
+	manifestBytes, err := store.readBytes(_backupManifestFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	var manifest backupManifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, nil, err
+	}
+
+	restoredProjects := strset.New()
+	apiConfigs := make([]userconfig.API, len(manifest.APIs))
+	for i, apiBackup := range manifest.APIs {
+		apiConfigs[i] = *apiBackup.Spec.API
+
+		if restoredProjects.Has(apiBackup.Spec.ProjectID) {
+			continue
+		}
+		projectBytes, err := store.readBytes(backupProjectFile(apiBackup.Spec.ProjectID))
+		if err != nil {
+			return nil, nil, errors.Wrap(err, apiBackup.Spec.Name, "project")
+		}
+		if _, err := UploadProject(projectBytes); err != nil {
+			return nil, nil, err
+		}
+		restoredProjects.Add(apiBackup.Spec.ProjectID)
+	}
+
+	results := make([]schema.DeployResult, len(apiConfigs))
+	for _, stage := range DeployStages(apiConfigs) {
+		var wg sync.WaitGroup
+		for _, i := range stage {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i] = restoreAPI(&apiConfigs[i], &manifest.APIs[i], force)
+			}(i)
+		}
+		wg.Wait()
+	}
+
+	if err := updateMaintenanceEnvoyFilter(); err != nil {
+		return nil, nil, err
+	}
+
+	return &manifest.Backup, results, nil
+}
+
+func restoreAPI(apiConfig *userconfig.API, apiBackup *backupAPI, force bool) schema.DeployResult {
+	api, msg, err := UpdateAPI(apiConfig, apiBackup.Spec.ProjectID, force)
+	if err != nil {
+		return schema.DeployResult{Message: msg, Error: errors.Message(err)}
+	}
+	result := schema.DeployResult{API: *api, Message: msg}
+
+	// the API is deployed even if some of its state couldn't be restored
+	if err := restoreAPIState(api, apiBackup); err != nil {
+		result.Error = fmt.Sprintf("%s: failed to restore state: %s", api.Name, errors.Message(err))
+	}
+
+	return result
+}
+
+func restoreAPIState(api *spec.API, apiBackup *backupAPI) error {
+	// backups which were created before the CortexAPIs' templates were resolved may contain configurations which can't be
+	// reconciled, in which case the API is restored without its CortexAPI
+	if apiBackup.CortexAPI != nil && !referencesTemplate(apiBackup.CortexAPI.Config) {
+		if err := applyCortexAPISpec(*apiBackup.CortexAPI, api); err != nil {
+			return err
+		}
+	}
+
+	if apiBackup.Owner != nil {
+		if err := config.Metadata.Put(_apiOwnersKind, api.Name, *apiBackup.Owner); err != nil {
+			return err
+		}
+	}
+
+	if apiBackup.Maintenance != nil {
+		if err := config.Metadata.Put(_maintenanceKind, api.Name, *apiBackup.Maintenance); err != nil {
+			return err
+		}
+	}
+
+	if len(apiBackup.RouteWeights) > 0 && isIstioNetworking() {
+		if err := restoreRouteWeights(api, apiBackup); err != nil {
+			return err
+		}
+	}
+
+	if apiBackup.Paused {
+		if _, err := PauseAPI(api.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func restoreRouteWeights(api *spec.API, apiBackup *backupAPI) error {
+	virtualService, err := config.K8sNamespace(api.Namespace).GetVirtualService(k8sName(api.Name))
+	if err != nil || virtualService == nil || apiHTTPRoute(virtualService) == nil {
+		return err
+	}
+
+	for _, route := range apiHTTPRoute(virtualService).Route {
+		if weight, ok := apiBackup.RouteWeights[route.Destination.Host]; ok {
+			route.Weight = weight
+		}
+	}
+	if apiBackup.PromotedVariant != "" {
+		if virtualService.Annotations == nil {
+			virtualService.Annotations = map[string]string{}
+		}
+		virtualService.Annotations[_experimentPromotedAnnotationKey] = apiBackup.PromotedVariant
+	}
+
+	mirrorAdditionalRoutes(virtualService)
+	_, err = config.K8sNamespace(virtualService.Namespace).UpdateVirtualService(virtualService, virtualService)
+	return err
+}
+
+func backupProjectFile(projectID string) string {
+	return filepath.Join(_backupProjectsDir, projectID+".zip")
+}
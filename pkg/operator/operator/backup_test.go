@@ -0,0 +1,52 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"testing"
+
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/storage"
+	"github.com/cortexlabs/cortex/pkg/types"
+	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBackupStorage(t *testing.T) {
+	clusterConfig := &clusterconfig.Config{}
+	clusterConfig.Provider = types.AWSProviderType
+	clusterConfig.Bucket = "cortex-bucket"
+	config.Bucket = storage.New(clusterConfig, nil, nil)
+
+	store, err := getBackupStorage("s3://cortex-bucket/backups/2020-01-01-00-00-00")
+	require.NoError(t, err)
+	require.Equal(t, &backupStorage{prefix: "backups/2020-01-01-00-00-00"}, store)
+
+	store, err = getBackupStorage("s3://cortex-bucket-backups/my-cluster/")
+	require.NoError(t, err)
+	require.Equal(t, &backupStorage{s3Bucket: "cortex-bucket-backups", prefix: "my-cluster/"}, store)
+
+	clusterConfig.Provider = types.GCPProviderType
+	config.Bucket = storage.New(clusterConfig, nil, nil)
+
+	store, err = getBackupStorage("gs://cortex-bucket/backups/2020-01-01-00-00-00")
+	require.NoError(t, err)
+	require.Equal(t, &backupStorage{prefix: "backups/2020-01-01-00-00-00"}, store)
+
+	_, err = getBackupStorage("gs://my-bucket/backups")
+	require.Error(t, err)
+}
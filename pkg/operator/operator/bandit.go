@@ -0,0 +1,204 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	istioclientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+)
+
+const _banditSamples = 10000
+
+var (
+	_banditUpdatesMutex sync.Mutex
+	_banditUpdates      = make(map[string]time.Time) // apiName -> when the experiment's weights were last checked
+)
+
+func isBanditUpdateDue(apiName string, bandit *userconfig.ExperimentBandit) bool {
+	_banditUpdatesMutex.Lock()
+	defer _banditUpdatesMutex.Unlock()
+	return time.Since(_banditUpdates[apiName]) >= bandit.UpdateInterval
+}
+
+// updateBanditWeights routes traffic to the experiment's APIs in proportion to their probability of performing best on the
+// experiment's metric (Thompson sampling), within the bandit's min_weight and max_weight; the configured weights are kept
+// until each API has received the experiment's min_requests
+func updateBanditWeights(api *spec.API, stats []experimentStats, virtualService *istioclientnetworking.VirtualService) error {
+	_banditUpdatesMutex.Lock()
+	_banditUpdates[api.Name] = time.Now()
+	_banditUpdatesMutex.Unlock()
+
+	for i := range stats {
+		if stats[i].samples(api.Experiment.Metric) < api.Experiment.MinRequests {
+			return nil
+		}
+	}
+
+	probabilities := banditProbabilities(stats, api.Experiment.Metric)
+	weights := banditWeights(probabilities, api.Experiment.Bandit.MinWeight, api.Experiment.Bandit.MaxWeight)
+
+	apiNames := []string{api.Name}
+	for _, variant := range api.Experiment.Variants {
+		apiNames = append(apiNames, variant.API)
+	}
+
+	serviceWeights := make(map[string]int32, len(apiNames))
+	for i, apiName := range apiNames {
+		serviceWeights[k8sName(apiName)] = weights[i]
+	}
+
+	changed := false
+	for _, route := range apiHTTPRoute(virtualService).Route {
+		weight, ok := serviceWeights[route.Destination.Host]
+		if !ok {
+			return nil // e.g. the API is being routed to its fallback API
+		}
+		if route.Weight != weight {
+			route.Weight = weight
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	mirrorAdditionalRoutes(virtualService)
+	if _, err := config.K8sNamespace(virtualService.Namespace).UpdateVirtualService(virtualService, virtualService); err != nil {
+		return err
+	}
+
+	weightStrs := make([]string, len(apiNames))
+	for i, apiName := range apiNames {
+		weightStrs[i] = fmt.Sprintf("%s: %d%%", apiName, weights[i])
+	}
+	log.Printf("updated the experiment weights of %s (%s)", api.Name, strings.Join(weightStrs, ", "))
+
+	return nil
+}
+
+// banditProbabilities estimates the probability that each API performs best on the metric, by sampling from a normal
+// approximation of each API's posterior distribution
+func banditProbabilities(stats []experimentStats, metric userconfig.ExperimentMetricType) []float64 {
+	means := make([]float64, len(stats))
+	stdDevs := make([]float64, len(stats))
+	for i := range stats {
+		if metric == userconfig.RewardExperimentMetricType {
+			means[i] = *stats[i].rewardAvg()
+			stdDevs[i] = math.Sqrt(stats[i].rewardVariance() / float64(stats[i].rewardCount))
+		} else {
+			// the error rate is smoothed with a uniform prior, and negated so that higher is better
+			errorRate := float64(stats[i].errors+1) / float64(stats[i].requests+2)
+			means[i] = -errorRate
+			stdDevs[i] = math.Sqrt(errorRate * (1 - errorRate) / float64(stats[i].requests+2))
+		}
+	}
+
+	wins := make([]int, len(stats))
+	for sample := 0; sample < _banditSamples; sample++ {
+		best := 0
+		bestValue := math.Inf(-1)
+		for i := range stats {
+			value := means[i] + rand.NormFloat64()*stdDevs[i]
+			if value > bestValue {
+				best = i
+				bestValue = value
+			}
+		}
+		wins[best]++
+	}
+
+	probabilities := make([]float64, len(stats))
+	for i := range wins {
+		probabilities[i] = float64(wins[i]) / _banditSamples
+	}
+	return probabilities
+}
+
+// banditWeights converts the probabilities into percentages which add up to 100, each between minWeight and maxWeight
+// (which must allow a total of 100)
+func banditWeights(probabilities []float64, minWeight int32, maxWeight int32) []int32 {
+	numAPIs := len(probabilities)
+
+	weights := make([]float64, numAPIs)
+	for i, probability := range probabilities {
+		weights[i] = float64(minWeight) + probability*float64(100-int32(numAPIs)*minWeight)
+	}
+
+	// redistribute the weight above maxWeight to the other APIs, in proportion to their probabilities
+	for {
+		var excess, uncappedProbability float64
+		var numUncapped int
+		for i := range weights {
+			if weights[i] > float64(maxWeight) {
+				excess += weights[i] - float64(maxWeight)
+				weights[i] = float64(maxWeight)
+			} else if weights[i] < float64(maxWeight) {
+				uncappedProbability += probabilities[i]
+				numUncapped++
+			}
+		}
+		if excess < 1e-9 || numUncapped == 0 {
+			break
+		}
+		for i := range weights {
+			if weights[i] < float64(maxWeight) {
+				if uncappedProbability > 0 {
+					weights[i] += excess * probabilities[i] / uncappedProbability
+				} else {
+					weights[i] += excess / float64(numUncapped)
+				}
+			}
+		}
+	}
+
+	// round down, and then give the remaining percentage points to the APIs with the largest remainders
+	intWeights := make([]int32, numAPIs)
+	remaining := int32(100)
+	for i := range weights {
+		intWeights[i] = int32(math.Floor(weights[i] + 1e-9))
+		remaining -= intWeights[i]
+	}
+	indexes := make([]int, numAPIs)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(a, b int) bool {
+		return weights[indexes[a]]-math.Floor(weights[indexes[a]]) > weights[indexes[b]]-math.Floor(weights[indexes[b]])
+	})
+	for _, i := range indexes {
+		if remaining <= 0 {
+			break
+		}
+		if intWeights[i] < maxWeight {
+			intWeights[i]++
+			remaining--
+		}
+	}
+
+	return intWeights
+}
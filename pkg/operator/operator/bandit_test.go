@@ -0,0 +1,31 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBanditWeights(t *testing.T) {
+	require.Equal(t, []int32{50, 50}, banditWeights([]float64{0.5, 0.5}, 5, 100))
+	require.Equal(t, []int32{5, 95}, banditWeights([]float64{0, 1}, 5, 100))
+	require.Equal(t, []int32{10, 90}, banditWeights([]float64{0, 1}, 5, 90))
+	require.Equal(t, []int32{34, 33, 33}, banditWeights([]float64{1.0 / 3, 1.0 / 3, 1.0 / 3}, 0, 100))
+	require.Equal(t, []int32{10, 40, 50}, banditWeights([]float64{0, 0.2, 0.8}, 10, 50))
+}
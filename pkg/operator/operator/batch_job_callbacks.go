@@ -0,0 +1,223 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+)
+
+const (
+	_maxBatchJobCallbackRetries = 10
+	_batchJobCallbackBackoff    = 10 * time.Second // doubled after each failed attempt
+)
+
+// the body of a batch job's callback; URL callbacks are signed with the X-Cortex-Timestamp and X-Cortex-Signature
+// headers in the same way as the callbacks of stream APIs
+type batchJobCallbackBody struct {
+	APIName     string                     `json:"api_name"`
+	JobID       string                     `json:"job_id"`
+	Status      string                     `json:"status"`
+	Error       string                     `json:"error,omitempty"`
+	ResultsPath string                     `json:"results_path"`
+	Items       int                        `json:"items"`
+	Partitions  []schema.BatchJobPartition `json:"partitions"`
+	FinishedAt  *int64                     `json:"finished_at"`
+}
+
+func validateBatchJobCallback(callback *schema.BatchJobCallback) error {
+	if callback == nil {
+		return nil
+	}
+	if callback.URL == nil && callback.SNSTopic == nil {
+		return ErrorInvalidBatchJob("the callback must have a url or an sns topic")
+	}
+	if callback.URL != nil {
+		u, err := url.Parse(*callback.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return ErrorInvalidBatchJob(fmt.Sprintf("the callback url (%s) must be an http or https url", *callback.URL))
+		}
+	}
+	if callback.SNSTopic != nil && !strings.HasPrefix(*callback.SNSTopic, "arn:aws:sns:") {
+		return ErrorInvalidBatchJob(fmt.Sprintf("the callback sns topic (%s) must be the arn of an sns topic", *callback.SNSTopic))
+	}
+	if callback.SigningKeyEnv != nil && callback.URL == nil {
+		return ErrorInvalidBatchJob("the callback's signing key only applies to url callbacks")
+	}
+	if callback.MaxRetries < 0 || callback.MaxRetries > _maxBatchJobCallbackRetries {
+		return ErrorInvalidBatchJob(fmt.Sprintf("the number of callback retries must be between 0 and %d", _maxBatchJobCallbackRetries))
+	}
+	return nil
+}
+
+// batchJobCallbackSigningKey returns the key which the job's URL callback is signed with, which is read from the API's
+// predictor.env (so that the key doesn't have to be stored with the job)
+func batchJobCallbackSigningKey(callback *schema.BatchJobCallback, api *spec.API) ([]byte, error) {
+	if callback == nil || callback.SigningKeyEnv == nil {
+		return nil, nil
+	}
+	signingKey, ok := api.Predictor.Env[*callback.SigningKeyEnv]
+	if !ok || signingKey == "" {
+		return nil, ErrorInvalidBatchJob(fmt.Sprintf("the callback's signing key environment variable (%s) is not set in the predictor.env of %s", *callback.SigningKeyEnv, api.Name))
+	}
+	return []byte(signingKey), nil
+}
+
+// signBatchJobCallback returns the signature of the body at the timestamp; the timestamp is signed along with the body
+// so that receivers can reject old (e.g. replayed) callbacks
+func signBatchJobCallback(signingKey []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, signingKey)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// sendBatchJobCallbacks sends the callbacks of the batch jobs which have finished; a callback which fails is retried
+// with exponential backoff, up to its max_retries times. The callbacks are sent without holding _batchJobsMutex (the
+// finished jobs aren't modified by anything else).
+func sendBatchJobCallbacks() error {
+	jobs, err := ListBatchJobs("")
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	now := time.Now()
+	for i := range jobs {
+		job := &jobs[i]
+		if job.CallbackStatus != schema.BatchJobCallbackStatusPending || job.NextCallbackAt > now.Unix() {
+			continue
+		}
+
+		retryable, err := sendBatchJobCallback(job)
+		job.CallbackAttempts++
+		switch {
+		case err == nil:
+			job.CallbackStatus = schema.BatchJobCallbackStatusSent
+			job.CallbackError = ""
+			job.NextCallbackAt = 0
+		case !retryable || job.CallbackAttempts > job.Callback.MaxRetries:
+			job.CallbackStatus = schema.BatchJobCallbackStatusFailed
+			job.CallbackError = errors.Message(err)
+			job.NextCallbackAt = 0
+			errs = append(errs, errors.Wrap(err, fmt.Sprintf("the callback of batch job %s of %s", job.ID, job.APIName)))
+		default:
+			job.CallbackError = errors.Message(err)
+			job.NextCallbackAt = now.Add(_batchJobCallbackBackoff * time.Duration(1<<uint(job.CallbackAttempts-1))).Unix()
+		}
+
+		_batchJobsMutex.Lock()
+		err = saveBatchJob(job)
+		_batchJobsMutex.Unlock()
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if errors.HasError(errs) {
+		return errors.FirstError(errs...)
+	}
+	return nil
+}
+
+// sendBatchJobCallback sends the job's callback to its URL and SNS topic, and returns whether it should be retried if it fails
+func sendBatchJobCallback(job *schema.BatchJob) (bool, error) {
+	body, err := libjson.Marshal(batchJobCallbackBody{
+		APIName:     job.APIName,
+		JobID:       job.ID,
+		Status:      job.Status,
+		Error:       job.Error,
+		ResultsPath: job.ResultsPath,
+		Items:       job.Items,
+		Partitions:  job.Partitions,
+		FinishedAt:  job.FinishedAt,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if job.Callback.URL != nil {
+		var signingKey []byte
+		if job.Callback.SigningKeyEnv != nil {
+			api, err := DownloadAPISpec(job.APIName, job.APIID)
+			if err != nil {
+				return true, err
+			}
+			if signingKey, err = batchJobCallbackSigningKey(job.Callback, api); err != nil {
+				return false, err
+			}
+		}
+		if retryable, err := postBatchJobCallback(*job.Callback.URL, body, signingKey); err != nil {
+			return retryable, err
+		}
+	}
+
+	if job.Callback.SNSTopic != nil {
+		subject := fmt.Sprintf("cortex batch job %s of %s %s", job.ID, job.APIName, job.Status)
+		if err := config.AWS.PublishSNSMessage(*job.Callback.SNSTopic, subject, string(body)); err != nil {
+			return true, err
+		}
+	}
+
+	return false, nil
+}
+
+// postBatchJobCallback posts the body to the callback url; client errors (other than rate limiting) aren't retried,
+// since they won't succeed if they are
+func postBatchJobCallback(callbackURL string, body []byte, signingKey []byte) (bool, error) {
+	request, err := http.NewRequest(http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if signingKey != nil {
+		timestamp := fmt.Sprintf("%d", time.Now().Unix())
+		request.Header.Set("X-Cortex-Timestamp", timestamp)
+		request.Header.Set("X-Cortex-Signature", signBatchJobCallback(signingKey, timestamp, body))
+	}
+
+	response, err := _notificationHTTPClient.Do(request)
+	if err != nil {
+		// the url isn't included in the error since it may embed a secret token
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		return true, errors.Wrap(err, "callback url")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		responseBytes, _ := ioutil.ReadAll(response.Body)
+		retryable := response.StatusCode >= 500 || response.StatusCode == http.StatusTooManyRequests
+		return retryable, ErrorNotificationFailed("callback url", response.StatusCode, string(responseBytes))
+	}
+	return false, nil
+}
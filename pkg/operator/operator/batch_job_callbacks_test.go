@@ -0,0 +1,81 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateBatchJobCallback(t *testing.T) {
+	require.NoError(t, validateBatchJobCallback(nil))
+	require.NoError(t, validateBatchJobCallback(&schema.BatchJobCallback{
+		URL:           pointer.String("https://example.com/callback"),
+		SNSTopic:      pointer.String("arn:aws:sns:us-west-2:123456789012:jobs"),
+		SigningKeyEnv: pointer.String("CALLBACK_KEY"),
+		MaxRetries:    3,
+	}))
+
+	for _, callback := range []schema.BatchJobCallback{
+		{},
+		{URL: pointer.String("example.com/callback")},
+		{SNSTopic: pointer.String("jobs")},
+		{SNSTopic: pointer.String("arn:aws:sns:us-west-2:123456789012:jobs"), SigningKeyEnv: pointer.String("CALLBACK_KEY")},
+		{URL: pointer.String("https://example.com/callback"), MaxRetries: _maxBatchJobCallbackRetries + 1},
+	} {
+		err := validateBatchJobCallback(&callback)
+		require.Error(t, err)
+		require.Equal(t, ErrInvalidBatchJob, errors.GetKind(err))
+	}
+}
+
+func TestSignBatchJobCallback(t *testing.T) {
+	// matches the signatures of stream API callbacks (hmac-sha256 of "<timestamp>.<body>")
+	require.Equal(t, "sha256=31809093a123d444a3d7bb59940aa11befe074187e492b896741dd33282e2daa", signBatchJobCallback([]byte("key"), "1600000000", []byte(`{"a":1}`)))
+}
+
+func TestPostBatchJobCallback(t *testing.T) {
+	statusCode := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Header.Get("X-Cortex-Signature") != signBatchJobCallback([]byte("key"), r.Header.Get("X-Cortex-Timestamp"), body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(statusCode)
+	}))
+	defer server.Close()
+
+	_, err := postBatchJobCallback(server.URL, []byte(`{"a":1}`), []byte("key"))
+	require.NoError(t, err)
+
+	retryable, err := postBatchJobCallback(server.URL, []byte(`{"a":1}`), []byte("other key"))
+	require.Error(t, err)
+	require.False(t, retryable)
+
+	statusCode = http.StatusServiceUnavailable
+	retryable, err = postBatchJobCallback(server.URL, []byte(`{"a":1}`), []byte("key"))
+	require.Error(t, err)
+	require.True(t, retryable)
+}
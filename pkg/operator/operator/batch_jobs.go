@@ -0,0 +1,884 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	kbatch "k8s.io/api/batch/v1"
+	kcore "k8s.io/api/core/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	_batchJobKind           = "batch_jobs"
+	_batchJobLabelKey       = "batchJobID"
+	_batchWorkerLabelKey    = "batchWorker"
+	_batchJobsBucketPrefix  = "batch_jobs"
+	_batchJobSchedulePeriod = 10 * time.Second
+	_maxBatchJobItems       = 100000
+	_maxBatchJobWorkers     = 100
+	_maxBatchJobRetries     = 10
+	_unlimitedBatchJobGPUs  = -1
+)
+
+// held while batch jobs are modified, so that the scheduler doesn't overwrite changes made by API requests (and vice versa)
+var _batchJobsMutex sync.Mutex
+
+// SubmitBatchJob uploads the submission's items to the cluster's bucket and adds the job to the queue; the job is started
+// by scheduleBatchJobs once the API's and its team's concurrency limits and the cluster's GPUs allow it
+func SubmitBatchJob(apiName string, submission schema.BatchJobSubmission) (*schema.BatchJob, error) {
+	return submitBatchJob(apiName, submission, "")
+}
+
+// submitBatchJob submits the job; source is the S3 object which the job's items were read from, if it was submitted by
+// the API's batch_trigger
+func submitBatchJob(apiName string, submission schema.BatchJobSubmission, source string) (*schema.BatchJob, error) {
+	if submission.Workers == 0 {
+		submission.Workers = 1
+	}
+	if err := validateBatchJobSubmission(&submission); err != nil {
+		return nil, err
+	}
+
+	deployment, err := getAPIDeployment(apiName)
+	if err != nil {
+		return nil, err
+	}
+	if deployment == nil {
+		return nil, ErrorAPINotDeployed(apiName)
+	}
+
+	api, err := DownloadAPISpec(apiName, deployment.Labels["apiID"])
+	if err != nil {
+		return nil, err
+	}
+	if err := validateBatchJobAPI(api); err != nil {
+		return nil, err
+	}
+	if _, err := batchJobCallbackSigningKey(submission.Callback, api); err != nil {
+		return nil, err
+	}
+
+	priority := api.Compute.Priority
+	if submission.Priority != nil {
+		priority = *submission.Priority
+	}
+
+	gpus := api.Compute.GPU * int64(submission.Workers)
+	if gpuCapacity := clusterGPUCapacity(); gpuCapacity != _unlimitedBatchJobGPUs && gpus > gpuCapacity {
+		return nil, ErrorInvalidBatchJob(fmt.Sprintf("the job's workers request %d GPUs, but the cluster only has %d GPUs at its maximum size", gpus, gpuCapacity))
+	}
+
+	team, err := apiOwnerTeam(apiName)
+	if err != nil {
+		return nil, err
+	}
+
+	id := fmt.Sprintf("%x", time.Now().UnixNano()) // sorts chronologically
+	job := &schema.BatchJob{
+		ID:          id,
+		APIName:     apiName,
+		APIID:       api.ID,
+		Namespace:   deployment.Namespace,
+		Team:        team,
+		Priority:    priority,
+		Items:       len(submission.Items),
+		Workers:     submission.Workers,
+		GPUs:        gpus,
+		Status:      schema.BatchJobStatusQueued,
+		SubmittedAt: time.Now().Unix(),
+		ResultsPath: config.Bucket.Path(batchJobResultsDir(apiName, id)),
+
+		MaxRetries:              submission.MaxRetries,
+		PartitionTimeoutSeconds: submission.PartitionTimeoutSeconds,
+		DeadLetterPrefix:        submission.DeadLetterPrefix,
+		Partitions:              make([]schema.BatchJobPartition, submission.Workers),
+		Callback:                submission.Callback,
+		Source:                  source,
+	}
+
+	// the items are dealt to the workers in turn, so each result records the index of its item in the submission
+	partitions := make([][]map[string]interface{}, submission.Workers)
+	for i, item := range submission.Items {
+		worker := i % int(submission.Workers)
+		partitions[worker] = append(partitions[worker], map[string]interface{}{"index": i, "item": item})
+	}
+	for worker, partition := range partitions {
+		job.Partitions[worker] = schema.BatchJobPartition{
+			Index:  int32(worker),
+			Items:  len(partition),
+			Status: schema.BatchJobPartitionStatusPending,
+		}
+		partitionBytes, err := json.Marshal(partition)
+		if err != nil {
+			return nil, err
+		}
+		if err := config.Bucket.UploadBytes(partitionBytes, batchJobItemsKey(apiName, id, int32(worker))); err != nil {
+			return nil, err
+		}
+	}
+
+	_batchJobsMutex.Lock()
+	defer _batchJobsMutex.Unlock()
+	if err := saveBatchJob(job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func GetBatchJob(apiName string, jobID string) (*schema.BatchJob, error) {
+	var job schema.BatchJob
+	exists, err := config.Metadata.Get(_batchJobKind, batchJobKey(apiName, jobID), &job)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrorBatchJobNotFound(apiName, jobID)
+	}
+	return &job, nil
+}
+
+// ListBatchJobs returns the batch jobs of the API, or of all APIs if apiName is ""
+func ListBatchJobs(apiName string) ([]schema.BatchJob, error) {
+	keyPrefix := ""
+	if apiName != "" {
+		keyPrefix = apiName + "/"
+	}
+
+	items, err := config.Metadata.List(_batchJobKind, keyPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]schema.BatchJob, len(items))
+	for i, item := range items {
+		if err := item.Unmarshal(&jobs[i]); err != nil {
+			return nil, err
+		}
+	}
+	return jobs, nil
+}
+
+// CancelBatchJob removes a queued job from the queue, or deletes the workers of a running job
+func CancelBatchJob(apiName string, jobID string) (*schema.BatchJob, error) {
+	_batchJobsMutex.Lock()
+	defer _batchJobsMutex.Unlock()
+
+	job, err := GetBatchJob(apiName, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != schema.BatchJobStatusQueued && job.Status != schema.BatchJobStatusRunning {
+		return job, nil
+	}
+
+	if job.Status == schema.BatchJobStatusRunning {
+		if err := deleteBatchWorkerJobs(job); err != nil {
+			return nil, err
+		}
+	}
+
+	finishBatchJob(job, schema.BatchJobStatusCancelled, "")
+	if err := saveBatchJob(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func validateBatchJobSubmission(submission *schema.BatchJobSubmission) error {
+	switch {
+	case len(submission.Items) == 0:
+		return ErrorInvalidBatchJob("the job must have at least one item")
+	case len(submission.Items) > _maxBatchJobItems:
+		return ErrorInvalidBatchJob(fmt.Sprintf("the job must not have more than %d items", _maxBatchJobItems))
+	case submission.Workers < 1 || submission.Workers > _maxBatchJobWorkers:
+		return ErrorInvalidBatchJob(fmt.Sprintf("the number of workers must be between 1 and %d", _maxBatchJobWorkers))
+	case int(submission.Workers) > len(submission.Items):
+		return ErrorInvalidBatchJob(fmt.Sprintf("the number of workers (%d) must not be greater than the number of items (%d)", submission.Workers, len(submission.Items)))
+	case submission.Priority != nil && *submission.Priority == userconfig.UnknownPriorityType:
+		return ErrorInvalidBatchJob(fmt.Sprintf("the priority must be one of %s", s.StrsOr(userconfig.PriorityTypeStrings())))
+	case submission.MaxRetries < 0 || submission.MaxRetries > _maxBatchJobRetries:
+		return ErrorInvalidBatchJob(fmt.Sprintf("the number of retries must be between 0 and %d", _maxBatchJobRetries))
+	case submission.PartitionTimeoutSeconds != nil && *submission.PartitionTimeoutSeconds <= 0:
+		return ErrorInvalidBatchJob("the partition timeout must be greater than 0")
+	case submission.DeadLetterPrefix != nil && !aws.IsValidS3Path(*submission.DeadLetterPrefix):
+		return ErrorInvalidBatchJob(fmt.Sprintf("the dead letter prefix (%s) must be an S3 path (e.g. s3://my-bucket/dead-letters/)", *submission.DeadLetterPrefix))
+	}
+	return validateBatchJobCallback(submission.Callback)
+}
+
+// the workers run the API container on its own, and exit once they have processed their items; the predictors which
+// rely on other containers in the API's pods can't run batch jobs
+func validateBatchJobAPI(api *spec.API) error {
+	if api.Stream != nil {
+		return ErrorBatchJobUnsupportedAPI(api.Name, "it is a stream API")
+	}
+	if api.Predictor.Type != userconfig.PythonPredictorType && api.Predictor.Type != userconfig.ONNXPredictorType {
+		return ErrorBatchJobUnsupportedAPI(api.Name, fmt.Sprintf("its predictor type is %s (batch jobs support the %s and %s predictor types)", api.Predictor.Type, userconfig.PythonPredictorType, userconfig.ONNXPredictorType))
+	}
+	if acc := getAccelerator(api); acc != nil && acc.runtimeContainer(api) != nil {
+		return ErrorBatchJobUnsupportedAPI(api.Name, fmt.Sprintf("it uses %s", userconfig.InfKey))
+	}
+	if api.Compute.Parallelism.IsMultiNode() {
+		return ErrorBatchJobUnsupportedAPI(api.Name, fmt.Sprintf("it is sharded across multiple nodes (%s: %d)", userconfig.NodesParallelismKey, api.Compute.Parallelism.Nodes))
+	}
+	return nil
+}
+
+// apiOwnerTeam returns the team which owns the API ("" if the cluster isn't multi-tenant, or the API doesn't belong to a team)
+func apiOwnerTeam(apiName string) (string, error) {
+	if !config.Cluster.IsMultiTenant() {
+		return "", nil
+	}
+	var owner apiOwner
+	if _, err := config.Metadata.Get(_apiOwnersKind, apiName, &owner); err != nil {
+		return "", err
+	}
+	return owner.Team, nil
+}
+
+func saveBatchJob(job *schema.BatchJob) error {
+	return config.Metadata.Put(_batchJobKind, batchJobKey(job.APIName, job.ID), job)
+}
+
+func finishBatchJob(job *schema.BatchJob, status string, errMessage string) {
+	job.Status = status
+	job.Error = errMessage
+	job.QueuedReason = ""
+	finishedAt := time.Now().Unix()
+	job.FinishedAt = &finishedAt
+	if job.Callback != nil {
+		job.CallbackStatus = schema.BatchJobCallbackStatusPending
+	}
+}
+
+func batchJobKey(apiName string, jobID string) string {
+	return apiName + "/" + jobID
+}
+
+func batchJobItemsKey(apiName string, jobID string, worker int32) string {
+	return fmt.Sprintf("%s/%s/%s/items/%d.json", _batchJobsBucketPrefix, apiName, jobID, worker)
+}
+
+func batchJobResultsDir(apiName string, jobID string) string {
+	return fmt.Sprintf("%s/%s/%s/results", _batchJobsBucketPrefix, apiName, jobID)
+}
+
+func batchJobResultsKey(apiName string, jobID string, worker int32) string {
+	return fmt.Sprintf("%s/%d.json", batchJobResultsDir(apiName, jobID), worker)
+}
+
+// batchJobDeadLetterPath returns the S3 path of a file in the job's directory under its dead letter prefix
+func batchJobDeadLetterPath(job *schema.BatchJob, fileName string) string {
+	return fmt.Sprintf("%s/%s/%s/%s", strings.TrimSuffix(*job.DeadLetterPrefix, "/"), job.APIName, job.ID, fileName)
+}
+
+func batchWorkerJobName(jobID string, worker int32) string {
+	return fmt.Sprintf("batch-%s-%d", jobID, worker)
+}
+
+// batchWorkerJobSpec runs one of the batch job's workers with the API's pod spec; only the API container is kept (the
+// sidecars serve requests and would keep the pod running), and the pod has the job's priority rather than the API's.
+// The pod is retried up to the job's max_retries times, and each attempt is stopped after the partition timeout.
+func batchWorkerJobSpec(api *spec.API, job *schema.BatchJob, worker int32) *kbatch.Job {
+	pod := newAPIPod(api)
+	switch api.Predictor.Type {
+	case userconfig.ONNXPredictorType:
+		pod.onnxPredictor()
+	case userconfig.PythonPredictorType:
+		pod.pythonPredictor()
+	}
+	podSpec := pod.build()
+
+	var containers []kcore.Container
+	for _, container := range podSpec.Containers {
+		if container.Name != _apiContainerName {
+			continue
+		}
+		container.ReadinessProbe = nil
+		container.LivenessProbe = nil
+		container.Lifecycle = nil
+		container.Ports = nil
+		container.Env = append(container.Env,
+			kcore.EnvVar{Name: "CORTEX_BATCH_JOB_ID", Value: job.ID},
+			kcore.EnvVar{Name: "CORTEX_BATCH_ITEMS_KEY", Value: batchJobItemsKey(job.APIName, job.ID, worker)},
+			kcore.EnvVar{Name: "CORTEX_BATCH_RESULTS_KEY", Value: batchJobResultsKey(job.APIName, job.ID, worker)},
+			kcore.EnvVar{Name: "CORTEX_BATCH_PARTITION", Value: s.Int32(worker)},
+		)
+		if job.DeadLetterPrefix != nil {
+			container.Env = append(container.Env, kcore.EnvVar{
+				Name:  "CORTEX_BATCH_DEAD_LETTER_PATH",
+				Value: batchJobDeadLetterPath(job, fmt.Sprintf("items-%d.json", worker)),
+			})
+		}
+		containers = append(containers, container)
+	}
+	podSpec.Containers = containers
+	podSpec.RestartPolicy = "Never"
+	podSpec.PriorityClassName = priorityTypeClassName(job.Priority)
+	podSpec.ActiveDeadlineSeconds = job.PartitionTimeoutSeconds
+
+	// the pods don't have the apiName label, so that they aren't selected by the API's service
+	labels := map[string]string{
+		_batchJobLabelKey:    job.ID,
+		_batchWorkerLabelKey: s.Int32(worker),
+	}
+
+	return k8s.Job(&k8s.JobSpec{
+		Name:         batchWorkerJobName(job.ID, worker),
+		Labels:       labels,
+		BackoffLimit: job.MaxRetries,
+		PodSpec: k8s.PodSpec{
+			Labels: labels,
+			Annotations: map[string]string{
+				"sidecar.istio.io/inject": "false", // the proxy would keep the pod running after the worker exits
+			},
+			K8sPodSpec: podSpec,
+		},
+	})
+}
+
+func deleteBatchWorkerJobs(job *schema.BatchJob) error {
+	k8sNamespace := config.K8sNamespace(job.Namespace)
+	var errs []error
+	for worker := int32(0); worker < job.Workers; worker++ {
+		if _, err := k8sNamespace.DeleteJob(batchWorkerJobName(job.ID, worker)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if errors.HasError(errs) {
+		return errors.FirstError(errs...)
+	}
+	return nil
+}
+
+// scheduleBatchJobs updates the status of the running batch jobs from their workers, and starts the queued jobs which
+// the scheduler admits
+func scheduleBatchJobs() error {
+	_batchJobsMutex.Lock()
+	defer _batchJobsMutex.Unlock()
+
+	jobs, err := ListBatchJobs("")
+	if err != nil {
+		return err
+	}
+
+	var running []*schema.BatchJob
+	var queued []*schema.BatchJob
+	var errs []error
+	for i := range jobs {
+		job := &jobs[i]
+		switch job.Status {
+		case schema.BatchJobStatusRunning:
+			if err := updateRunningBatchJob(job); err != nil {
+				errs = append(errs, err)
+			}
+			if job.Status == schema.BatchJobStatusRunning {
+				running = append(running, job)
+			}
+		case schema.BatchJobStatusQueued:
+			queued = append(queued, job)
+		}
+	}
+
+	// the queued jobs are scheduled with their APIs' current configurations
+	apis := map[string]*spec.API{}
+	var schedulable []*schema.BatchJob
+	for _, job := range queued {
+		api, ok := apis[job.APIName]
+		if !ok {
+			deployment, err := getAPIDeployment(job.APIName)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if deployment != nil {
+				if api, err = DownloadAPISpec(job.APIName, deployment.Labels["apiID"]); err != nil {
+					errs = append(errs, err)
+					continue
+				}
+			}
+			apis[job.APIName] = api
+		}
+
+		if api == nil {
+			finishBatchJob(job, schema.BatchJobStatusFailed, errors.Message(ErrorAPINotDeployed(job.APIName)))
+		} else if err := validateBatchJobAPI(api); err != nil {
+			finishBatchJob(job, schema.BatchJobStatusFailed, errors.Message(err))
+		} else {
+			job.GPUs = api.Compute.GPU * int64(job.Workers)
+			schedulable = append(schedulable, job)
+			continue
+		}
+		if err := saveBatchJob(job); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	gpuCapacity, err := batchJobGPUCapacity()
+	if err != nil {
+		return err
+	}
+
+	scheduler := &batchScheduler{
+		gpuCapacity: gpuCapacity,
+		apiLimits:   map[string]int64{},
+		teamLimits:  map[string]int64{},
+	}
+	for apiName, api := range apis {
+		if api != nil {
+			scheduler.apiLimits[apiName] = api.Compute.MaxConcurrentJobs
+		}
+	}
+	for _, team := range config.Cluster.Teams {
+		if team.MaxConcurrentJobs != nil {
+			scheduler.teamLimits[team.Name] = *team.MaxConcurrentJobs
+		}
+	}
+
+	admitted := scheduler.schedule(running, schedulable)
+
+	for _, job := range schedulable {
+		if admitted[job.ID] {
+			if err := startBatchJob(apis[job.APIName], job); err != nil {
+				errs = append(errs, err)
+				finishBatchJob(job, schema.BatchJobStatusFailed, errors.Message(err))
+			}
+		}
+		if err := saveBatchJob(job); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if errors.HasError(errs) {
+		return errors.FirstError(errs...)
+	}
+	return nil
+}
+
+func startBatchJob(api *spec.API, job *schema.BatchJob) error {
+	k8sNamespace := config.K8sNamespace(job.Namespace)
+	for worker := int32(0); worker < job.Workers; worker++ {
+		if _, err := k8sNamespace.CreateJob(batchWorkerJobSpec(api, job, worker)); err != nil {
+			deleteBatchWorkerJobs(job)
+			return err
+		}
+	}
+
+	startedAt := time.Now().Unix()
+	job.StartedAt = &startedAt
+	job.APIID = api.ID
+	job.Status = schema.BatchJobStatusRunning
+	job.QueuedReason = ""
+	return nil
+}
+
+// updateRunningBatchJob updates the status of the job's partitions from their workers. A partition which fails (after
+// its retries) fails the job and deletes the remaining workers, unless the job has a dead letter prefix, in which case
+// the partition's items are written to the prefix and the job fails once all of its partitions have finished.
+func updateRunningBatchJob(job *schema.BatchJob) error {
+	isDeployed, err := IsAPIDeployed(job.APIName)
+	if err != nil {
+		return err
+	}
+	if !isDeployed {
+		finishBatchJob(job, schema.BatchJobStatusFailed, errors.Message(ErrorAPINotDeployed(job.APIName)))
+		deleteBatchWorkerJobs(job)
+		return saveBatchJob(job)
+	}
+
+	workerJobs, err := config.K8sNamespace(job.Namespace).ListJobsByLabel(_batchJobLabelKey, job.ID)
+	if err != nil {
+		return err
+	}
+	workerJobMap := k8s.JobMap(workerJobs)
+
+	// jobs which were submitted before partitions were recorded don't know their partitions' sizes
+	if len(job.Partitions) != int(job.Workers) {
+		job.Partitions = make([]schema.BatchJobPartition, job.Workers)
+		for worker := range job.Partitions {
+			job.Partitions[worker] = schema.BatchJobPartition{Index: int32(worker), Status: schema.BatchJobPartitionStatusPending}
+		}
+	}
+
+	var errs []error
+	for i := range job.Partitions {
+		partition := &job.Partitions[i]
+		if isBatchJobPartitionFinished(partition) {
+			continue
+		}
+
+		if workerJob, ok := workerJobMap[batchWorkerJobName(job.ID, partition.Index)]; ok {
+			updateBatchJobPartition(job, partition, &workerJob)
+		} else {
+			partition.Status = schema.BatchJobPartitionStatusFailed
+			partition.Error = fmt.Sprintf("the job of worker %d was deleted", partition.Index)
+		}
+
+		if partition.Status == schema.BatchJobPartitionStatusSucceeded {
+			failedItems, err := countFailedBatchJobItems(job, partition.Index)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			partition.FailedItems = failedItems
+		}
+
+		if partition.Status != schema.BatchJobPartitionStatusFailed {
+			continue
+		}
+		if job.DeadLetterPrefix == nil {
+			finishBatchJob(job, schema.BatchJobStatusFailed, partition.Error)
+			break
+		}
+		if err := writeBatchJobPartitionDeadLetter(job, partition); err != nil {
+			finishBatchJob(job, schema.BatchJobStatusFailed, fmt.Sprintf("%s, and its items could not be written to the dead letter prefix: %s", partition.Error, errors.Message(err)))
+			break
+		}
+	}
+
+	var succeededWorkers int32
+	var failedPartitions int
+	for i := range job.Partitions {
+		switch job.Partitions[i].Status {
+		case schema.BatchJobPartitionStatusSucceeded:
+			succeededWorkers++
+		case schema.BatchJobPartitionStatusFailed:
+			failedPartitions++
+		}
+	}
+
+	job.SucceededWorkers = succeededWorkers
+	if job.Status == schema.BatchJobStatusFailed {
+		deleteBatchWorkerJobs(job)
+	} else if int(succeededWorkers)+failedPartitions == len(job.Partitions) {
+		if failedPartitions > 0 {
+			finishBatchJob(job, schema.BatchJobStatusFailed, fmt.Sprintf("%d of %d partitions failed (their items were written to %s)", failedPartitions, len(job.Partitions), batchJobDeadLetterPath(job, "")))
+		} else {
+			finishBatchJob(job, schema.BatchJobStatusSucceeded, "")
+		}
+		deleteBatchWorkerJobs(job)
+	}
+
+	if err := saveBatchJob(job); err != nil {
+		errs = append(errs, err)
+	}
+	if errors.HasError(errs) {
+		return errors.FirstError(errs...)
+	}
+	return nil
+}
+
+func isBatchJobPartitionFinished(partition *schema.BatchJobPartition) bool {
+	return partition.Status == schema.BatchJobPartitionStatusSucceeded || partition.Status == schema.BatchJobPartitionStatusFailed
+}
+
+// updateBatchJobPartition sets the status of the partition from its worker's job; a partition is pending while its
+// worker's pod is waiting to be (re)started
+func updateBatchJobPartition(job *schema.BatchJob, partition *schema.BatchJobPartition, workerJob *kbatch.Job) {
+	partition.Attempts = workerJob.Status.Active + workerJob.Status.Succeeded + workerJob.Status.Failed
+
+	switch {
+	case workerJob.Status.Succeeded > 0:
+		partition.Status = schema.BatchJobPartitionStatusSucceeded
+	case isBatchWorkerJobFailed(workerJob):
+		partition.Status = schema.BatchJobPartitionStatusFailed
+		timeoutReason := ""
+		if job.PartitionTimeoutSeconds != nil {
+			timeoutReason = fmt.Sprintf(" or runs for longer than %ds", *job.PartitionTimeoutSeconds)
+		}
+		partition.Error = fmt.Sprintf("worker %d failed after %d %s (an attempt fails if the worker exits with an error%s; see `cortex logs %s` for details)", partition.Index, partition.Attempts, s.PluralS("attempt", partition.Attempts), timeoutReason, job.APIName)
+	case workerJob.Status.Active > 0:
+		partition.Status = schema.BatchJobPartitionStatusRunning
+	default:
+		partition.Status = schema.BatchJobPartitionStatusPending
+	}
+}
+
+// the worker's job has failed once its pod has failed more than its backoff limit allows
+func isBatchWorkerJobFailed(workerJob *kbatch.Job) bool {
+	for _, condition := range workerJob.Status.Conditions {
+		if condition.Type == kbatch.JobFailed && condition.Status == kcore.ConditionTrue {
+			return true
+		}
+	}
+	return workerJob.Spec.BackoffLimit != nil && workerJob.Status.Failed > *workerJob.Spec.BackoffLimit
+}
+
+// countFailedBatchJobItems returns the number of items in the worker's results for which the predictor raised an exception
+func countFailedBatchJobItems(job *schema.BatchJob, worker int32) (int, error) {
+	resultsBytes, err := config.Bucket.ReadBytes(batchJobResultsKey(job.APIName, job.ID, worker))
+	if err != nil {
+		return 0, err
+	}
+	var results []map[string]interface{}
+	if err := json.Unmarshal(resultsBytes, &results); err != nil {
+		return 0, err
+	}
+
+	failedItems := 0
+	for _, result := range results {
+		if _, ok := result["error"]; ok {
+			failedItems++
+		}
+	}
+	return failedItems, nil
+}
+
+// writeBatchJobPartitionDeadLetter writes the items of a failed partition to the job's dead letter prefix, along with
+// the context of its failure
+func writeBatchJobPartitionDeadLetter(job *schema.BatchJob, partition *schema.BatchJobPartition) error {
+	itemsBytes, err := config.Bucket.ReadBytes(batchJobItemsKey(job.APIName, job.ID, partition.Index))
+	if err != nil {
+		return err
+	}
+	var items []interface{}
+	if err := json.Unmarshal(itemsBytes, &items); err != nil {
+		return err
+	}
+
+	deadLetterBytes, err := json.Marshal(map[string]interface{}{
+		"api_name":  job.APIName,
+		"job_id":    job.ID,
+		"partition": partition.Index,
+		"attempts":  partition.Attempts,
+		"error":     partition.Error,
+		"timestamp": time.Now().Unix(),
+		"items":     items,
+	})
+	if err != nil {
+		return err
+	}
+
+	path := batchJobDeadLetterPath(job, fmt.Sprintf("partition-%d.json", partition.Index))
+	if err := uploadBytesToS3Path(deadLetterBytes, path); err != nil {
+		return err
+	}
+	partition.DeadLetterPath = path
+	return nil
+}
+
+// uploadBytesToS3Path uploads to the cluster's bucket if the path is in it, otherwise to the path's S3 bucket
+func uploadBytesToS3Path(data []byte, path string) error {
+	if key, ok := clusterBucketKey(path); ok {
+		return config.Bucket.UploadBytes(data, key)
+	}
+
+	awsClient, err := aws.NewFromClientS3Path(path, config.AWS)
+	if err != nil {
+		return err
+	}
+	bucket, key, err := aws.SplitS3Path(path)
+	if err != nil {
+		return err
+	}
+	return awsClient.UploadBytesToS3(data, bucket, key)
+}
+
+// clusterGPUCapacity returns the GPUs of the cluster's instances at their maximum counts, or _unlimitedBatchJobGPUs if
+// the cluster's size isn't known
+func clusterGPUCapacity() int64 {
+	if config.Cluster.MaxInstances == nil {
+		return _unlimitedBatchJobGPUs
+	}
+
+	gpus := config.Cluster.InstanceMetadata.GPU * *config.Cluster.MaxInstances
+	for _, nodeGroup := range config.Cluster.NodeGroups {
+		gpus += aws.InstanceMetadatas[*config.Cluster.Region][nodeGroup.InstanceType].GPU * nodeGroup.MaxInstances
+	}
+	return gpus
+}
+
+// batchJobGPUCapacity returns the GPUs which are available to batch jobs: the cluster's GPUs, less those requested by
+// the APIs' replicas
+func batchJobGPUCapacity() (int64, error) {
+	gpus := clusterGPUCapacity()
+	if gpus == _unlimitedBatchJobGPUs {
+		return gpus, nil
+	}
+
+	deployments, err := listAPIDeployments(klabels.Everything())
+	if err != nil {
+		return 0, err
+	}
+	for i := range deployments {
+		replicas := int64(1)
+		if deployments[i].Spec.Replicas != nil {
+			replicas = int64(*deployments[i].Spec.Replicas)
+		}
+		gpus -= deploymentGPUs(&deployments[i]) * replicas
+	}
+
+	if gpus < 0 {
+		return 0, nil
+	}
+	return gpus, nil
+}
+
+// batchScheduler decides which queued batch jobs may start; it holds the limits which the jobs are scheduled against
+type batchScheduler struct {
+	gpuCapacity int64            // the GPUs available to batch jobs (_unlimitedBatchJobGPUs if not known)
+	apiLimits   map[string]int64 // compute.max_concurrent_jobs of each API
+	teamLimits  map[string]int64 // max_concurrent_jobs of each team which has a limit
+}
+
+// the unit of fair GPU sharing: the team which owns the API if there is one, otherwise the API
+func batchJobTenant(job *schema.BatchJob) string {
+	if job.Team != "" {
+		return "team/" + job.Team
+	}
+	return "api/" + job.APIName
+}
+
+// schedule returns the IDs of the queued jobs which may start, and sets the queued reason of the others. The queued jobs
+// are considered in order of priority, then (within a priority) the tenant which is using the fewest GPUs, then
+// submission time. Jobs which request GPUs are only admitted if they fit in the free GPUs, and won't take a tenant past
+// its fair share of the GPUs (the capacity divided evenly between the tenants which want GPUs) while another tenant
+// which is waiting for GPUs is below its share (so a team can't take more than its share by raising the priority of its
+// jobs). Once a GPU job is waiting for GPUs to free up, lower priority GPU jobs can't start ahead of it.
+func (scheduler *batchScheduler) schedule(running []*schema.BatchJob, queued []*schema.BatchJob) map[string]bool {
+	runningByAPI := map[string]int64{}
+	runningByTeam := map[string]int64{}
+	gpusByTenant := map[string]int64{}
+	var usedGPUs int64
+	for _, job := range running {
+		runningByAPI[job.APIName]++
+		if job.Team != "" {
+			runningByTeam[job.Team]++
+		}
+		gpusByTenant[batchJobTenant(job)] += job.GPUs
+		usedGPUs += job.GPUs
+	}
+
+	// the number of each tenant's GPU jobs which haven't been considered yet (a tenant whose jobs were all held back by
+	// the concurrency limits in this round isn't waiting for GPUs)
+	waitingGPUJobs := map[string]int{}
+	for _, job := range queued {
+		if job.GPUs > 0 {
+			waitingGPUJobs[batchJobTenant(job)]++
+		}
+	}
+
+	var fairShare int64
+	if scheduler.gpuCapacity != _unlimitedBatchJobGPUs {
+		gpuTenants := map[string]bool{}
+		for tenant, gpus := range gpusByTenant {
+			if gpus > 0 {
+				gpuTenants[tenant] = true
+			}
+		}
+		for tenant := range waitingGPUJobs {
+			gpuTenants[tenant] = true
+		}
+		if len(gpuTenants) > 0 {
+			fairShare = scheduler.gpuCapacity / int64(len(gpuTenants))
+		}
+	}
+
+	// another tenant which is waiting for GPUs is below its fair share
+	otherTenantBelowShare := func(tenant string) bool {
+		for otherTenant, numJobs := range waitingGPUJobs {
+			if otherTenant != tenant && numJobs > 0 && gpusByTenant[otherTenant] < fairShare {
+				return true
+			}
+		}
+		return false
+	}
+
+	pending := append([]*schema.BatchJob{}, queued...)
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].SubmittedAt < pending[j].SubmittedAt
+	})
+
+	admitted := map[string]bool{}
+	blockedGPUPriority := userconfig.UnknownPriorityType
+
+	for len(pending) > 0 {
+		// the next job is chosen after each admission, since the tenants' GPU usage changes
+		next := 0
+		for i, job := range pending[1:] {
+			best := pending[next]
+			if job.Priority != best.Priority {
+				if job.Priority > best.Priority {
+					next = i + 1
+				}
+				continue
+			}
+			if gpusByTenant[batchJobTenant(job)] < gpusByTenant[batchJobTenant(best)] {
+				next = i + 1
+			}
+		}
+		job := pending[next]
+		pending = append(pending[:next], pending[next+1:]...)
+		tenant := batchJobTenant(job)
+		if job.GPUs > 0 {
+			waitingGPUJobs[tenant]--
+		}
+
+		if limit, ok := scheduler.apiLimits[job.APIName]; ok && runningByAPI[job.APIName] >= limit {
+			job.QueuedReason = fmt.Sprintf("%s has %d running jobs (%s: %d)", job.APIName, runningByAPI[job.APIName], userconfig.MaxConcurrentJobsKey, limit)
+			continue
+		}
+		if limit, ok := scheduler.teamLimits[job.Team]; ok && job.Team != "" && runningByTeam[job.Team] >= limit {
+			job.QueuedReason = fmt.Sprintf("team %s has %d running jobs (%s: %d)", job.Team, runningByTeam[job.Team], userconfig.MaxConcurrentJobsKey, limit)
+			continue
+		}
+
+		if job.GPUs > 0 && scheduler.gpuCapacity != _unlimitedBatchJobGPUs {
+			if job.Priority < blockedGPUPriority {
+				job.QueuedReason = "waiting for higher priority jobs to get GPUs"
+				continue
+			}
+			if freeGPUs := scheduler.gpuCapacity - usedGPUs; job.GPUs > freeGPUs {
+				job.QueuedReason = fmt.Sprintf("waiting for GPUs (the job requests %d GPUs, and %d are available)", job.GPUs, freeGPUs)
+				if job.Priority > blockedGPUPriority {
+					blockedGPUPriority = job.Priority
+				}
+				continue
+			}
+			if gpusByTenant[tenant]+job.GPUs > fairShare && otherTenantBelowShare(tenant) {
+				job.QueuedReason = fmt.Sprintf("waiting for other tenants to get their share of the GPUs (%d GPUs each)", fairShare)
+				continue
+			}
+		}
+
+		admitted[job.ID] = true
+		job.QueuedReason = ""
+		runningByAPI[job.APIName]++
+		if job.Team != "" {
+			runningByTeam[job.Team]++
+		}
+		gpusByTenant[tenant] += job.GPUs
+		usedGPUs += job.GPUs
+	}
+
+	return admitted
+}
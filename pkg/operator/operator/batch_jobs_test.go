@@ -0,0 +1,207 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"testing"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/stretchr/testify/require"
+	kbatch "k8s.io/api/batch/v1"
+	kcore "k8s.io/api/core/v1"
+)
+
+func testBatchJob(id string, apiName string, team string, priority userconfig.PriorityType, gpus int64, submittedAt int64) *schema.BatchJob {
+	return &schema.BatchJob{
+		ID:          id,
+		APIName:     apiName,
+		Team:        team,
+		Priority:    priority,
+		Workers:     1,
+		GPUs:        gpus,
+		SubmittedAt: submittedAt,
+	}
+}
+
+func TestBatchSchedulerConcurrencyLimits(t *testing.T) {
+	scheduler := &batchScheduler{
+		gpuCapacity: _unlimitedBatchJobGPUs,
+		apiLimits:   map[string]int64{"a": 1, "b": 2, "c": 5},
+		teamLimits:  map[string]int64{"team": 2},
+	}
+	running := []*schema.BatchJob{
+		testBatchJob("r1", "a", "", userconfig.DefaultPriorityType, 0, 0),
+	}
+	queued := []*schema.BatchJob{
+		testBatchJob("q1", "a", "", userconfig.DefaultPriorityType, 0, 1),
+		testBatchJob("q2", "b", "", userconfig.DefaultPriorityType, 0, 2),
+		testBatchJob("q3", "b", "", userconfig.DefaultPriorityType, 0, 3),
+		testBatchJob("q4", "b", "", userconfig.DefaultPriorityType, 0, 4),
+		testBatchJob("q5", "c", "team", userconfig.DefaultPriorityType, 0, 5),
+		testBatchJob("q6", "c", "team", userconfig.DefaultPriorityType, 0, 6),
+		testBatchJob("q7", "c", "team", userconfig.DefaultPriorityType, 0, 7),
+	}
+
+	admitted := scheduler.schedule(running, queued)
+	require.Equal(t, map[string]bool{"q2": true, "q3": true, "q5": true, "q6": true}, admitted)
+	require.Contains(t, queued[0].QueuedReason, userconfig.MaxConcurrentJobsKey)
+	require.Contains(t, queued[3].QueuedReason, userconfig.MaxConcurrentJobsKey)
+	require.Contains(t, queued[6].QueuedReason, "team team")
+	require.Empty(t, queued[1].QueuedReason)
+}
+
+func TestBatchSchedulerPriority(t *testing.T) {
+	scheduler := &batchScheduler{
+		gpuCapacity: 4,
+		apiLimits:   map[string]int64{"a": 10},
+		teamLimits:  map[string]int64{},
+	}
+	queued := []*schema.BatchJob{
+		testBatchJob("low", "a", "", userconfig.LowPriorityType, 2, 1),
+		testBatchJob("default", "a", "", userconfig.DefaultPriorityType, 2, 2),
+		testBatchJob("high", "a", "", userconfig.HighPriorityType, 2, 3),
+	}
+
+	admitted := scheduler.schedule(nil, queued)
+	require.Equal(t, map[string]bool{"high": true, "default": true}, admitted)
+	require.Contains(t, queued[0].QueuedReason, "waiting for GPUs")
+}
+
+func TestBatchSchedulerBlockedGPUJob(t *testing.T) {
+	scheduler := &batchScheduler{
+		gpuCapacity: 4,
+		apiLimits:   map[string]int64{"a": 10, "b": 10},
+		teamLimits:  map[string]int64{},
+	}
+	running := []*schema.BatchJob{
+		testBatchJob("r1", "a", "", userconfig.DefaultPriorityType, 2, 0),
+	}
+	queued := []*schema.BatchJob{
+		testBatchJob("big", "b", "", userconfig.HighPriorityType, 4, 1),
+		testBatchJob("small", "b", "", userconfig.DefaultPriorityType, 1, 2),
+		testBatchJob("cpu", "b", "", userconfig.LowPriorityType, 0, 3),
+	}
+
+	// the small GPU job would delay the high priority job, but jobs which don't request GPUs can still start
+	admitted := scheduler.schedule(running, queued)
+	require.Equal(t, map[string]bool{"cpu": true}, admitted)
+	require.Contains(t, queued[0].QueuedReason, "waiting for GPUs")
+	require.Contains(t, queued[1].QueuedReason, "higher priority")
+}
+
+func TestBatchSchedulerFairShare(t *testing.T) {
+	scheduler := &batchScheduler{
+		gpuCapacity: 8,
+		apiLimits:   map[string]int64{"a": 10, "b": 10, "c": 10},
+		teamLimits:  map[string]int64{},
+	}
+
+	// team x is using 4 GPUs (its fair share, with 2 teams), so its high priority job waits for team y's job
+	running := []*schema.BatchJob{
+		testBatchJob("r1", "a", "x", userconfig.DefaultPriorityType, 4, 0),
+	}
+	queued := []*schema.BatchJob{
+		testBatchJob("x1", "b", "x", userconfig.HighPriorityType, 2, 1),
+		testBatchJob("y1", "c", "y", userconfig.LowPriorityType, 2, 2),
+	}
+
+	admitted := scheduler.schedule(running, queued)
+	require.Equal(t, map[string]bool{"y1": true}, admitted)
+	require.Contains(t, queued[0].QueuedReason, "share")
+
+	// once the other team isn't waiting, a team may use more than its share
+	queued = []*schema.BatchJob{
+		testBatchJob("x1", "b", "x", userconfig.DefaultPriorityType, 2, 1),
+	}
+	admitted = scheduler.schedule(running, queued)
+	require.Equal(t, map[string]bool{"x1": true}, admitted)
+
+	// within a priority, the team which is using fewer GPUs goes first
+	running = []*schema.BatchJob{
+		testBatchJob("r1", "a", "x", userconfig.DefaultPriorityType, 4, 0),
+		testBatchJob("r2", "a", "y", userconfig.DefaultPriorityType, 3, 0),
+	}
+	queued = []*schema.BatchJob{
+		testBatchJob("x1", "b", "x", userconfig.DefaultPriorityType, 1, 1),
+		testBatchJob("y1", "c", "y", userconfig.DefaultPriorityType, 1, 2),
+	}
+	admitted = scheduler.schedule(running, queued)
+	require.Equal(t, map[string]bool{"y1": true}, admitted)
+	require.Contains(t, queued[0].QueuedReason, "waiting for GPUs")
+}
+
+func TestValidateBatchJobSubmission(t *testing.T) {
+	items := []interface{}{1, 2, 3}
+
+	require.NoError(t, validateBatchJobSubmission(&schema.BatchJobSubmission{
+		Items:                   items,
+		Workers:                 3,
+		MaxRetries:              2,
+		PartitionTimeoutSeconds: pointer.Int64(60),
+		DeadLetterPrefix:        pointer.String("s3://bucket/dead-letters/"),
+	}))
+
+	for _, submission := range []schema.BatchJobSubmission{
+		{Items: items, Workers: 1, MaxRetries: -1},
+		{Items: items, Workers: 1, MaxRetries: _maxBatchJobRetries + 1},
+		{Items: items, Workers: 1, PartitionTimeoutSeconds: pointer.Int64(0)},
+		{Items: items, Workers: 1, DeadLetterPrefix: pointer.String("gs://bucket/dead-letters/")},
+	} {
+		err := validateBatchJobSubmission(&submission)
+		require.Error(t, err)
+		require.Equal(t, ErrInvalidBatchJob, errors.GetKind(err))
+	}
+}
+
+func TestUpdateBatchJobPartition(t *testing.T) {
+	job := testBatchJob("j", "a", "", userconfig.DefaultPriorityType, 0, 0)
+	job.MaxRetries = 2
+	job.PartitionTimeoutSeconds = pointer.Int64(60)
+
+	workerJob := &kbatch.Job{Spec: kbatch.JobSpec{BackoffLimit: pointer.Int32(2)}}
+	partition := &schema.BatchJobPartition{Index: 0, Status: schema.BatchJobPartitionStatusPending}
+
+	updateBatchJobPartition(job, partition, workerJob)
+	require.Equal(t, schema.BatchJobPartitionStatusPending, partition.Status)
+
+	// the first attempt failed, and the second is running
+	workerJob.Status = kbatch.JobStatus{Active: 1, Failed: 1}
+	updateBatchJobPartition(job, partition, workerJob)
+	require.Equal(t, schema.BatchJobPartitionStatusRunning, partition.Status)
+	require.Equal(t, int32(2), partition.Attempts)
+
+	// waiting to be retried
+	workerJob.Status = kbatch.JobStatus{Failed: 2}
+	updateBatchJobPartition(job, partition, workerJob)
+	require.Equal(t, schema.BatchJobPartitionStatusPending, partition.Status)
+
+	workerJob.Status = kbatch.JobStatus{Failed: 3, Conditions: []kbatch.JobCondition{{Type: kbatch.JobFailed, Status: kcore.ConditionTrue}}}
+	updateBatchJobPartition(job, partition, workerJob)
+	require.Equal(t, schema.BatchJobPartitionStatusFailed, partition.Status)
+	require.Equal(t, int32(3), partition.Attempts)
+	require.Contains(t, partition.Error, "after 3 attempts")
+	require.Contains(t, partition.Error, "longer than 60s")
+
+	partition = &schema.BatchJobPartition{Index: 1, Status: schema.BatchJobPartitionStatusRunning}
+	workerJob.Status = kbatch.JobStatus{Failed: 1, Succeeded: 1}
+	updateBatchJobPartition(job, partition, workerJob)
+	require.Equal(t, schema.BatchJobPartitionStatusSucceeded, partition.Status)
+	require.Empty(t, partition.Error)
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	klabels "k8s.io/apimachinery/pkg/labels"
+)
+
+const _maxBatchTriggerMessages = 10 // per API, each time the queues are checked
+
+var (
+	_batchTriggersMutex sync.Mutex
+	_batchTriggers      = make(map[string]*userconfig.BatchTrigger) // apiID -> the api's batch_trigger (nil if it has none)
+)
+
+// the parts of an S3 event notification which are used to submit jobs (https://docs.aws.amazon.com/AmazonS3/latest/dev/notification-content-structure.html)
+type s3EventNotification struct {
+	Records []struct {
+		EventName string `json:"eventName"`
+		S3        struct {
+			Bucket struct {
+				Name string `json:"name"`
+			} `json:"bucket"`
+			Object struct {
+				Key string `json:"key"` // URL-encoded
+			} `json:"object"`
+		} `json:"s3"`
+	} `json:"Records"`
+}
+
+// processBatchTriggers submits a batch job for each object which was created in the bucket of an API's batch_trigger. A
+// message is deleted from the queue once its objects' jobs have been submitted, or if its objects can't be submitted as
+// jobs (e.g. they aren't JSON lists of items); otherwise it is received again once its visibility timeout expires
+func processBatchTriggers() error {
+	deployments, err := listAPIDeployments(klabels.Everything())
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := range deployments {
+		apiName := deployments[i].Labels["apiName"]
+		batchTrigger, err := getAPIBatchTrigger(apiName, deployments[i].Labels["apiID"])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if batchTrigger == nil {
+			continue
+		}
+		if err := processBatchTrigger(apiName, batchTrigger); err != nil {
+			errs = append(errs, errors.Wrap(err, apiName, userconfig.BatchTriggerKey))
+		}
+	}
+
+	if errors.HasError(errs) {
+		return errors.FirstError(errs...)
+	}
+	return nil
+}
+
+// the api's batch_trigger is read from its spec once per api id
+func getAPIBatchTrigger(apiName string, apiID string) (*userconfig.BatchTrigger, error) {
+	_batchTriggersMutex.Lock()
+	batchTrigger, ok := _batchTriggers[apiID]
+	_batchTriggersMutex.Unlock()
+	if ok {
+		return batchTrigger, nil
+	}
+
+	api, err := DownloadAPISpec(apiName, apiID)
+	if err != nil {
+		return nil, err
+	}
+
+	_batchTriggersMutex.Lock()
+	_batchTriggers[apiID] = api.BatchTrigger
+	_batchTriggersMutex.Unlock()
+
+	return api.BatchTrigger, nil
+}
+
+func processBatchTrigger(apiName string, batchTrigger *userconfig.BatchTrigger) error {
+	queueURL, err := config.AWS.GetSQSQueueURL(batchTrigger.Queue)
+	if err != nil {
+		return err
+	}
+
+	messages, err := config.AWS.ReceiveSQSMessages(queueURL, _maxBatchTriggerMessages)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, message := range messages {
+		if message.Body == nil || message.ReceiptHandle == nil {
+			continue
+		}
+
+		if err := submitBatchJobsForS3Event(apiName, batchTrigger, *message.Body); err != nil {
+			if !isPermanentBatchTriggerError(err) {
+				errs = append(errs, err)
+				continue
+			}
+			errors.PrintError(err, fmt.Sprintf("failed to submit a batch job for %s from an S3 event (the event is discarded)", apiName))
+		}
+
+		if err := config.AWS.DeleteSQSMessage(queueURL, *message.ReceiptHandle); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if errors.HasError(errs) {
+		return errors.FirstError(errs...)
+	}
+	return nil
+}
+
+// submitBatchJobsForS3Event submits a job for each of the objects which were created in the event (S3's test event has no
+// objects); a message which fails is received again, so its objects which were submitted are submitted again
+func submitBatchJobsForS3Event(apiName string, batchTrigger *userconfig.BatchTrigger, messageBody string) error {
+	var event s3EventNotification
+	if err := json.Unmarshal([]byte(messageBody), &event); err != nil {
+		return ErrorInvalidBatchTriggerObject("the S3 event", "it could not be parsed")
+	}
+
+	for _, record := range event.Records {
+		key, err := url.QueryUnescape(record.S3.Object.Key)
+		if err != nil {
+			return ErrorInvalidBatchTriggerObject(record.S3.Object.Key, "its key could not be decoded")
+		}
+		s3Path := aws.S3Path(record.S3.Bucket.Name, key)
+
+		if _, err := submitBatchJobForS3Object(apiName, batchTrigger, s3Path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// submitBatchJobForS3Object submits a job whose items are the contents of the object (a JSON list of items)
+func submitBatchJobForS3Object(apiName string, batchTrigger *userconfig.BatchTrigger, s3Path string) (*schema.BatchJob, error) {
+	awsClient, err := aws.NewFromClientS3Path(s3Path, config.AWS)
+	if err != nil {
+		return nil, err
+	}
+	bucket, key, err := aws.SplitS3Path(s3Path)
+	if err != nil {
+		return nil, err
+	}
+	objectBytes, err := awsClient.ReadBytesFromS3(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []interface{}
+	if err := json.Unmarshal(objectBytes, &items); err != nil {
+		return nil, ErrorInvalidBatchTriggerObject(s3Path, "it must contain a JSON list of items")
+	}
+
+	workers := batchTrigger.Workers
+	if len(items) > 0 && int(workers) > len(items) {
+		workers = int32(len(items))
+	}
+
+	job, err := submitBatchJob(apiName, schema.BatchJobSubmission{
+		Items:   items,
+		Workers: workers,
+	}, s3Path)
+	if err != nil {
+		return nil, errors.Wrap(err, s3Path)
+	}
+	return job, nil
+}
+
+// objects which can't be submitted won't succeed if they are received again
+func isPermanentBatchTriggerError(err error) bool {
+	switch errors.GetKind(err) {
+	case ErrInvalidBatchTriggerObject, ErrInvalidBatchJob, ErrBatchJobUnsupportedAPI:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"testing"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmitBatchJobsForS3Event(t *testing.T) {
+	batchTrigger := &userconfig.BatchTrigger{Queue: "queue", Bucket: "bucket", Workers: 1}
+
+	// S3 sends a test event (without records) when the notification is configured
+	require.NoError(t, submitBatchJobsForS3Event("my-api", batchTrigger, `{"Service":"Amazon S3","Event":"s3:TestEvent","Bucket":"bucket"}`))
+
+	err := submitBatchJobsForS3Event("my-api", batchTrigger, "not json")
+	require.Error(t, err)
+	require.True(t, isPermanentBatchTriggerError(err))
+
+	err = submitBatchJobsForS3Event("my-api", batchTrigger, `{"Records":[{"eventName":"ObjectCreated:Put","s3":{"bucket":{"name":"bucket"},"object":{"key":"items%zz.json"}}}]}`)
+	require.Error(t, err)
+	require.Equal(t, ErrInvalidBatchTriggerObject, errors.GetKind(err))
+
+	require.True(t, isPermanentBatchTriggerError(ErrorInvalidBatchJob("the job must have at least one item")))
+	require.False(t, isPermanentBatchTriggerError(ErrorAPINotDeployed("my-api")))
+}
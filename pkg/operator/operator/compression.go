@@ -0,0 +1,64 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"sync"
+
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+)
+
+// serializes updates to the compression envoy filter, since APIs can be deployed concurrently
+var _compressionEnvoyFilterMutex sync.Mutex
+
+// the compression envoy filter is shared by all APIs, so it is regenerated from the virtual services whenever an API changes
+func updateCompressionEnvoyFilter() error {
+	if !isIstioNetworking() {
+		return nil
+	}
+
+	_compressionEnvoyFilterMutex.Lock()
+	defer _compressionEnvoyFilterMutex.Unlock()
+
+	virtualServices, err := config.K8sAllNamspaces.ListVirtualServicesWithLabelKeys("apiName")
+	if err != nil {
+		return err
+	}
+
+	var compressedEndpoints []string
+	for _, virtualService := range virtualServices {
+		if virtualService.Annotations[userconfig.CompressionAnnotationKey] != userconfig.GzipCompressionType.String() {
+			continue
+		}
+		compressedEndpoints = append(compressedEndpoints, k8s.ExtractVirtualServiceEndpoints(&virtualService).SliceSorted()...)
+	}
+
+	if len(compressedEndpoints) == 0 {
+		_, err := config.K8sIstio.DeleteEnvoyFilter(_compressionEnvoyFilterName)
+		return err
+	}
+
+	envoyFilter, err := compressionEnvoyFilterSpec(compressedEndpoints)
+	if err != nil {
+		return err
+	}
+
+	_, err = config.K8sIstio.ApplyEnvoyFilter(envoyFilter)
+	return err
+}
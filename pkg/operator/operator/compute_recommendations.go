@@ -0,0 +1,172 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"fmt"
+
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	kcore "k8s.io/api/core/v1"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	_maxRecommendedDecrease = 0.25 // a request isn't recommended to be reduced by more than this fraction to fit another replica on each instance
+	_minRecommendedIncrease = 0.1  // unused capacity is only reported if the request could be increased by at least this fraction
+)
+
+// resourceFit describes how many of an API's replicas fit on a single instance with respect to one of the resources
+// which the API requests (CPU is in millicores and memory is in bytes)
+type resourceFit struct {
+	key       string // the compute field which requests the resource
+	available int64  // the instance's capacity which is available to APIs
+	overhead  int64  // the amount which each replica requests in addition to its compute request (see sidecarRequests)
+	requested int64
+	minimum   int64 // the smallest request which is allowed
+	step      int64 // requests are recommended in multiples of this amount
+}
+
+func (fit resourceFit) replicas() int64 {
+	return fit.available / (fit.requested + fit.overhead)
+}
+
+// maxRequest returns the largest request which fits the given number of replicas on an instance
+func (fit resourceFit) maxRequest(replicas int64) int64 {
+	request := fit.available/replicas - fit.overhead
+	return request - request%fit.step
+}
+
+func (fit resourceFit) format(request int64) string {
+	switch fit.key {
+	case userconfig.CPUKey:
+		return kresource.NewMilliQuantity(request, kresource.DecimalSI).String()
+	case userconfig.MemKey:
+		return kresource.NewQuantity(request, kresource.BinarySI).String()
+	}
+	return s.Int64(request)
+}
+
+// resourceFits returns the fits of each of the resources which the API requests
+func resourceFits(api *userconfig.API, maxMem *kresource.Quantity) []resourceFit {
+	compute := api.Compute
+	maxCPU, maxMemAvailable, maxGPU, maxInf := instanceCapacity(compute, maxMem)
+
+	sidecarRequests := sidecarRequests(api)
+	sidecarCPU := sidecarRequests[kcore.ResourceCPU]
+	sidecarMem := sidecarRequests[kcore.ResourceMemory]
+
+	var fits []resourceFit
+	if compute.CPU != nil {
+		fits = append(fits, resourceFit{
+			key:       userconfig.CPUKey,
+			available: maxCPU.MilliValue(),
+			overhead:  sidecarCPU.MilliValue(),
+			requested: compute.CPU.MilliValue(),
+			minimum:   20,
+			step:      10,
+		})
+	}
+	if compute.Mem != nil {
+		fits = append(fits, resourceFit{
+			key:       userconfig.MemKey,
+			available: maxMemAvailable.Value(),
+			overhead:  sidecarMem.Value(),
+			requested: compute.Mem.Value(),
+			minimum:   20 * 1024 * 1024,
+			step:      1024 * 1024,
+		})
+	}
+	if compute.GPU > 0 {
+		fits = append(fits, resourceFit{key: userconfig.GPUKey, available: maxGPU, requested: compute.GPU, minimum: 1, step: 1})
+	}
+	if compute.Inf > 0 {
+		fits = append(fits, resourceFit{key: userconfig.InfKey, available: maxInf, requested: compute.Inf, minimum: 1, step: 1})
+	}
+	return fits
+}
+
+// replicasPerInstance returns the number of replicas which fit on a single instance (-1 if no resources are requested)
+func replicasPerInstance(fits []resourceFit) int64 {
+	replicas := int64(-1)
+	for _, fit := range fits {
+		if fitReplicas := fit.replicas(); replicas == -1 || fitReplicas < replicas {
+			replicas = fitReplicas
+		}
+	}
+	return replicas
+}
+
+// RecommendCompute suggests adjustments to the API's compute request which would pack its replicas onto instances more
+// efficiently: reducing a request slightly if that would fit another replica on each instance, or increasing a request
+// if the instance's capacity would otherwise go unused (because another resource limits the number of replicas)
+func RecommendCompute(apiConfig *userconfig.API) (*schema.ComputeRecommendationsResult, error) {
+	maxMem, err := updateMemoryCapacityConfigMap()
+	if err != nil {
+		return nil, err
+	}
+
+	instanceType := targetInstanceType(apiConfig.Compute)
+	fits := resourceFits(apiConfig, maxMem)
+	replicas := replicasPerInstance(fits)
+
+	result := &schema.ComputeRecommendationsResult{
+		APIName:             apiConfig.Name,
+		InstanceType:        instanceType,
+		ReplicasPerInstance: replicas,
+	}
+	if replicas <= 0 {
+		return result, nil
+	}
+
+	for i, fit := range fits {
+		// the number of replicas which would fit if only the other resources were considered
+		otherFits := append(append([]resourceFit{}, fits[:i]...), fits[i+1:]...)
+		otherReplicas := replicasPerInstance(otherFits)
+
+		if otherReplicas == -1 || otherReplicas > replicas {
+			decreased := fit.maxRequest(replicas + 1)
+			if decreased >= fit.minimum && float64(decreased) >= float64(fit.requested)*(1-_maxRecommendedDecrease) {
+				result.Recommendations = append(result.Recommendations, schema.ComputeRecommendation{
+					Resource:            fit.key,
+					Requested:           fit.format(fit.requested),
+					Recommended:         fit.format(decreased),
+					ReplicasPerInstance: replicas + 1,
+					Message: fmt.Sprintf("requesting %s %s fits %d %s on each %s instance; request %s %s to fit %d",
+						fit.format(fit.requested), fit.key, replicas, s.PluralS("replica", replicas), instanceType, fit.format(decreased), fit.key, replicas+1),
+				})
+				continue
+			}
+		}
+
+		increased := fit.maxRequest(replicas)
+		if float64(increased) >= float64(fit.requested)*(1+_minRecommendedIncrease) {
+			unused := fit.available - replicas*(fit.requested+fit.overhead)
+			result.Recommendations = append(result.Recommendations, schema.ComputeRecommendation{
+				Resource:            fit.key,
+				Requested:           fit.format(fit.requested),
+				Recommended:         fit.format(increased),
+				ReplicasPerInstance: replicas,
+				Message: fmt.Sprintf("requesting %s %s leaves %s %s unused on each %s instance; up to %s %s can be requested while still fitting %d %s on each instance",
+					fit.format(fit.requested), fit.key, fit.format(unused), fit.key, instanceType, fit.format(increased), fit.key, replicas, s.PluralS("replica", replicas)),
+			})
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kschema "k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	_cortexAPIFinalizer         = "cortex.dev/api"
+	_cortexAPIDefaultConfigPath = "cortex.yaml"
+)
+
+var _cortexAPIResource = kschema.GroupVersionResource{
+	Group:    "cortex.dev",
+	Version:  "v1alpha1",
+	Resource: "cortexapis",
+}
+
+var _cortexAPITypeMeta = kmeta.TypeMeta{
+	APIVersion: "cortex.dev/v1alpha1",
+	Kind:       "CortexAPI",
+}
+
+// cortexAPI is the CortexAPI custom resource (see manager/manifests/cortex-apis.yaml)
+type cortexAPI struct {
+	kmeta.TypeMeta   `json:",inline"`
+	kmeta.ObjectMeta `json:"metadata,omitempty"`
+	Spec             cortexAPISpec   `json:"spec"`
+	Status           cortexAPIStatus `json:"status,omitempty"`
+}
+
+type cortexAPISpec struct {
+	Config     map[string]interface{} `json:"config"`
+	ConfigPath string                 `json:"configPath,omitempty"`
+	Project    string                 `json:"project,omitempty"`
+	ProjectID  string                 `json:"projectID,omitempty"`
+}
+
+type cortexAPIStatus struct {
+	ObservedGeneration int64       `json:"observedGeneration,omitempty"`
+	ProjectID          string      `json:"projectID,omitempty"`
+	APIID              string      `json:"apiID,omitempty"`
+	Error              string      `json:"error,omitempty"`
+	LastReconcileTime  *kmeta.Time `json:"lastReconcileTime,omitempty"`
+}
+
+func getCortexAPIs() ([]cortexAPI, error) {
+	objs, err := config.K8s.ListCustomResources(_cortexAPIResource, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	cortexAPIs := make([]cortexAPI, len(objs))
+	for i := range objs {
+		if err := k8s.FromUnstructured(&objs[i], &cortexAPIs[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return cortexAPIs, nil
+}
+
+func getCortexAPI(apiName string) (*cortexAPI, error) {
+	obj, err := config.K8s.GetCustomResource(_cortexAPIResource, apiName)
+	if err != nil || obj == nil {
+		return nil, err
+	}
+
+	var capi cortexAPI
+	if err := k8s.FromUnstructured(obj, &capi); err != nil {
+		return nil, err
+	}
+	return &capi, nil
+}
+
+func updateCortexAPI(capi *cortexAPI) (*cortexAPI, error) {
+	obj, err := k8s.ToUnstructured(capi)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err = config.K8s.UpdateCustomResource(_cortexAPIResource, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated cortexAPI
+	if err := k8s.FromUnstructured(obj, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func updateCortexAPIStatus(capi *cortexAPI) error {
+	now := kmeta.NewTime(time.Now())
+	capi.Status.LastReconcileTime = &now
+
+	obj, err := k8s.ToUnstructured(capi)
+	if err != nil {
+		return err
+	}
+
+	_, err = config.K8s.UpdateCustomResourceStatus(_cortexAPIResource, obj)
+	return err
+}
+
+// ApplyCortexAPI records an API which was deployed via the operator's API as a CortexAPI resource, so that its
+// resources are kept in sync with it; configBytes is the user's configuration file, which contains the API at api.Index
+func ApplyCortexAPI(configBytes []byte, api *spec.API) error {
+	apiConfig, err := cortexAPIConfig(configBytes, api.FilePath, api.Index)
+	if err != nil {
+		return err
+	}
+
+	return applyCortexAPISpec(cortexAPISpec{
+		Config:     apiConfig,
+		ConfigPath: api.FilePath,
+		ProjectID:  api.ProjectID,
+	}, api)
+}
+
+// cortexAPIConfig returns the configuration of the API at index in the user's configuration file, with its templates
+// applied (the CortexAPI resource is reconciled without the rest of the file, so it can't reference the file's templates)
+func cortexAPIConfig(configBytes []byte, filePath string, index int) (map[string]interface{}, error) {
+	jsonBytes, err := yaml.YAMLToJSON(configBytes)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var configs []map[string]interface{}
+	if err := json.Unmarshal(jsonBytes, &configs); err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(configs) {
+		return nil, spec.ErrorMalformedConfig()
+	}
+
+	return spec.ResolveAPIConfig(configs, filePath, index)
+}
+
+// referencesTemplate returns true if a CortexAPI's configuration references a template (which is only possible for
+// CortexAPIs which were recorded before their templates were resolved), in which case it can't be reconciled
+func referencesTemplate(apiConfig map[string]interface{}) bool {
+	_, hasBase := apiConfig[userconfig.BaseKey]
+	_, hasTemplate := apiConfig[userconfig.TemplateKey]
+	return hasBase || hasTemplate
+}
+
+func applyCortexAPISpec(capiSpec cortexAPISpec, api *spec.API) error {
+	capi := cortexAPI{
+		TypeMeta: _cortexAPITypeMeta,
+		ObjectMeta: kmeta.ObjectMeta{
+			Name:       api.Name,
+			Namespace:  config.K8s.Namespace,
+			Finalizers: []string{_cortexAPIFinalizer},
+		},
+		Spec: capiSpec,
+	}
+
+	obj, err := k8s.ToUnstructured(&capi)
+	if err != nil {
+		return err
+	}
+	obj, err = config.K8s.ApplyCustomResource(_cortexAPIResource, obj)
+	if err != nil {
+		return err
+	}
+
+	var applied cortexAPI
+	if err := k8s.FromUnstructured(obj, &applied); err != nil {
+		return err
+	}
+
+	// the API has already been deployed, so the controller doesn't need to deploy it again
+	applied.Status = cortexAPIStatus{
+		ObservedGeneration: applied.Generation,
+		ProjectID:          api.ProjectID,
+		APIID:              api.ID,
+	}
+	return updateCortexAPIStatus(&applied)
+}
+
+// DeleteCortexAPI deletes the API's CortexAPI resource (if it exists) without triggering the controller to delete the API
+func DeleteCortexAPI(apiName string) error {
+	capi, err := getCortexAPI(apiName)
+	if err != nil || capi == nil {
+		return err
+	}
+
+	if slices.HasString(capi.Finalizers, _cortexAPIFinalizer) {
+		capi.Finalizers = slices.SubtractStrSlice(capi.Finalizers, []string{_cortexAPIFinalizer})
+		if _, err := updateCortexAPI(capi); err != nil {
+			return err
+		}
+	}
+
+	_, err = config.K8s.DeleteCustomResource(_cortexAPIResource, apiName)
+	return err
+}
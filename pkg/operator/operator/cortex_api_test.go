@@ -0,0 +1,74 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"testing"
+
+	"github.com/cortexlabs/cortex/pkg/types"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/stretchr/testify/require"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCortexAPITemplates(t *testing.T) {
+	configBytes := []byte(`
+- template: python
+  predictor:
+    type: python
+    path: predictor.py
+  compute:
+    cpu: 2
+
+- name: my-api
+  base: python
+  compute:
+    mem: 1G
+`)
+
+	// the API is deployed from the file, and recorded as a CortexAPI
+	apiConfig, err := cortexAPIConfig(configBytes, "cortex.yaml", 1)
+	require.NoError(t, err)
+	require.False(t, referencesTemplate(apiConfig))
+
+	// the API is reconciled from its CortexAPI, without the rest of the file
+	capi := &cortexAPI{
+		ObjectMeta: kmeta.ObjectMeta{Name: "my-api"},
+		Spec: cortexAPISpec{
+			Config:     apiConfig,
+			ConfigPath: "cortex.yaml",
+		},
+	}
+	reconcileConfigBytes, configPath, err := cortexAPIConfigFile(capi)
+	require.NoError(t, err)
+
+	projectFiles := ProjectFiles{
+		ProjectByteMap: map[string][]byte{"predictor.py": []byte("class PythonPredictor:\n    pass\n")},
+		ConfigFilePath: configPath,
+	}
+	apis, err := spec.ExtractAPIConfigs(reconcileConfigBytes, types.AWSProviderType, projectFiles, configPath, spec.Environment{})
+	require.NoError(t, err)
+	require.Len(t, apis, 1)
+	require.Equal(t, "my-api", apis[0].Name)
+	require.Equal(t, userconfig.PythonPredictorType, apis[0].Predictor.Type)
+	require.Equal(t, "2", apis[0].Compute.CPU.String())
+	require.Equal(t, "1G", apis[0].Compute.Mem.String())
+
+	_, err = cortexAPIConfig(configBytes, "cortex.yaml", 2)
+	require.Error(t, err)
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/parallel"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	kcore "k8s.io/api/core/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	_costSamplePeriod = time.Minute
+	_costWindow       = 24 * time.Hour
+)
+
+type costSample struct {
+	time     time.Time
+	replicas int
+	hourly   float64
+}
+
+// costs are sampled in memory, so the history restarts when the operator does
+var _costHistory = struct {
+	sync.Mutex
+	since   time.Time
+	samples map[string][]costSample // apiName -> samples from the past _costWindow, oldest first
+}{
+	since:   time.Now(),
+	samples: map[string][]costSample{},
+}
+
+type currentCosts struct {
+	apis              map[string]*costSample
+	instancesHourly   float64
+	unallocatedHourly float64
+}
+
+func recordCosts() error {
+	costs, err := getCurrentCosts()
+	if err != nil {
+		return err
+	}
+
+	_costHistory.Lock()
+	defer _costHistory.Unlock()
+
+	for apiName, sample := range costs.apis {
+		_costHistory.samples[apiName] = append(_costHistory.samples[apiName], *sample)
+	}
+
+	for apiName, samples := range _costHistory.samples {
+		i := 0
+		for i < len(samples) && time.Since(samples[i].time) > _costWindow {
+			i++
+		}
+		if i == len(samples) {
+			delete(_costHistory.samples, apiName)
+		} else {
+			_costHistory.samples[apiName] = samples[i:]
+		}
+	}
+
+	return nil
+}
+
+// getCurrentCosts splits the hourly price of each instance between the API replicas running on it, according to each replica's dominant share of the instance's allocatable resources
+func getCurrentCosts() (*currentCosts, error) {
+	var nodes []kcore.Node
+	var pods []kcore.Pod
+
+	err := parallel.RunFirstErr(
+		func() error {
+			var err error
+			nodes, err = config.K8s.ListNodesByLabel("workload", "true")
+			return err
+		},
+		func() error {
+			var err error
+			pods, err = listAPIPods(klabels.Everything())
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	costs := &currentCosts{
+		apis: map[string]*costSample{},
+	}
+
+	nodeMap := make(map[string]*kcore.Node, len(nodes))
+	nodePrices := make(map[string]float64, len(nodes))
+	spotPriceCache := map[string]float64{}
+	for i := range nodes {
+		nodeMap[nodes[i].Name] = &nodes[i]
+		nodePrices[nodes[i].Name] = nodePrice(&nodes[i], spotPriceCache)
+		costs.instancesHourly += nodePrices[nodes[i].Name]
+	}
+
+	now := time.Now()
+	var allocatedHourly float64
+
+	for i := range pods {
+		apiName := pods[i].Labels["apiName"]
+		if _, ok := costs.apis[apiName]; !ok {
+			costs.apis[apiName] = &costSample{time: now}
+		}
+
+		node, ok := nodeMap[pods[i].Spec.NodeName]
+		if !ok || k8s.GetPodStatus(&pods[i]) == k8s.PodStatusTerminating {
+			continue
+		}
+
+		podHourly := nodePrices[node.Name] * dominantResourceShare(&pods[i], node)
+		costs.apis[apiName].replicas++
+		costs.apis[apiName].hourly += podHourly
+		allocatedHourly += podHourly
+	}
+
+	costs.unallocatedHourly = math.Max(costs.instancesHourly-allocatedHourly, 0)
+
+	return costs, nil
+}
+
+// nodePrice includes the instance's volume; spotPriceCache maps instance type -> spot price, to avoid requesting it for every node
+func nodePrice(node *kcore.Node, spotPriceCache map[string]float64) float64 {
+	region := *config.Cluster.Region
+	instanceType := node.Labels["beta.kubernetes.io/instance-type"]
+
+	price := aws.InstanceMetadatas[region][instanceType].Price
+	if strings.Contains(strings.ToLower(node.Labels["lifecycle"]), "spot") {
+		spotPrice, ok := spotPriceCache[instanceType]
+		if !ok {
+			var err error
+			spotPrice, err = config.AWS.SpotInstancePrice(region, instanceType)
+			if err != nil {
+				spotPrice = 0
+			}
+			spotPriceCache[instanceType] = spotPrice
+		}
+		if spotPrice != 0 {
+			price = spotPrice
+		}
+	}
+
+	volumeType := config.Cluster.InstanceVolumeType.String()
+	price += aws.EBSMetadatas[region][volumeType].PriceGB * float64(config.Cluster.InstanceVolumeSize) / 30 / 24
+	if volumeType == "io1" && config.Cluster.InstanceVolumeIOPS != nil {
+		price += aws.EBSMetadatas[region][volumeType].PriceIOPS * float64(*config.Cluster.InstanceVolumeIOPS) / 30 / 24
+	}
+
+	return price
+}
+
+// dominantResourceShare is the largest fraction of any of the node's allocatable resources (cpu, memory, or gpu) which the pod requests
+func dominantResourceShare(pod *kcore.Pod, node *kcore.Node) float64 {
+	cpu, mem, gpu := k8s.TotalPodCompute(&pod.Spec)
+
+	var share float64
+	if allocatable := node.Status.Allocatable.Cpu(); !allocatable.IsZero() {
+		share = math.Max(share, float64(cpu.MilliValue())/float64(allocatable.MilliValue()))
+	}
+	if allocatable := node.Status.Allocatable.Memory(); !allocatable.IsZero() {
+		share = math.Max(share, float64(mem.Value())/float64(allocatable.Value()))
+	}
+	if allocatable := node.Status.Allocatable["nvidia.com/gpu"]; !allocatable.IsZero() {
+		share = math.Max(share, float64(gpu)/float64(allocatable.Value()))
+	}
+
+	return math.Min(share, 1)
+}
+
+func GetCosts() (*schema.CostsResponse, error) {
+	current, err := getCurrentCosts()
+	if err != nil {
+		return nil, err
+	}
+
+	_costHistory.Lock()
+	defer _costHistory.Unlock()
+
+	response := &schema.CostsResponse{
+		InstancesHourly:   current.instancesHourly,
+		UnallocatedHourly: current.unallocatedHourly,
+		FixedHourly:       clusterFixedPrice(),
+		TrackedSince:      _costHistory.since,
+	}
+
+	teamCosts := map[string]*schema.TeamCost{}
+
+	for apiName, sample := range current.apis {
+		apiCost := schema.APICost{
+			APIName:  apiName,
+			Replicas: sample.replicas,
+			Hourly:   sample.hourly,
+			Daily:    sample.hourly * 24,
+		}
+
+		if samples := _costHistory.samples[apiName]; len(samples) > 0 {
+			var hourlySum float64
+			for _, sample := range samples {
+				hourlySum += sample.hourly
+				apiCost.ReplicaHours += float64(sample.replicas) * _costSamplePeriod.Hours()
+			}
+			// if fewer than 24 hours of samples have been recorded, the average is extrapolated
+			apiCost.Daily = hourlySum / float64(len(samples)) * 24
+		}
+
+		if config.Cluster.IsMultiTenant() {
+			var owner apiOwner
+			if _, err := config.Metadata.Get(_apiOwnersKind, apiName, &owner); err != nil {
+				return nil, err
+			}
+			apiCost.Team = owner.Team
+
+			if _, ok := teamCosts[owner.Team]; !ok {
+				teamCosts[owner.Team] = &schema.TeamCost{Team: owner.Team}
+			}
+			teamCosts[owner.Team].APIs++
+			teamCosts[owner.Team].Hourly += apiCost.Hourly
+			teamCosts[owner.Team].Daily += apiCost.Daily
+		}
+
+		response.APIs = append(response.APIs, apiCost)
+	}
+
+	sort.Slice(response.APIs, func(i, j int) bool {
+		return response.APIs[i].APIName < response.APIs[j].APIName
+	})
+
+	for _, teamCost := range teamCosts {
+		response.Teams = append(response.Teams, *teamCost)
+	}
+	sort.Slice(response.Teams, func(i, j int) bool {
+		return response.Teams[i].Team < response.Teams[j].Team
+	})
+
+	return response, nil
+}
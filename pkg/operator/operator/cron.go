@@ -29,7 +29,7 @@ import (
 )
 
 func deleteEvictedPods() error {
-	failedPods, err := config.K8s.ListPods(&kmeta.ListOptions{
+	failedPods, err := config.K8sAllNamspaces.ListPods(&kmeta.ListOptions{
 		FieldSelector: "status.phase=Failed",
 	})
 	if err != nil {
@@ -39,7 +39,7 @@ func deleteEvictedPods() error {
 	var errs []error
 	for _, pod := range failedPods {
 		if pod.Status.Reason == k8s.ReasonEvicted {
-			_, err := config.K8s.DeletePod(pod.Name)
+			_, err := config.K8sNamespace(pod.Namespace).DeletePod(pod.Name)
 			if err != nil {
 				errs = append(errs, err)
 			}
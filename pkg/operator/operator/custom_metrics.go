@@ -0,0 +1,184 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/metrics"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+)
+
+// the prefix of the names of the metrics which predictors emit with their metrics client (see cortex/lib/client/metrics.py)
+const _customMetricPrefix = "custom."
+
+type customMetric struct {
+	Name string // without the prefix
+	Type metrics.CustomMetricType
+}
+
+// listCustomMetrics returns the custom metrics which the API's predictor has emitted in the past two weeks (cloudwatch
+// only lists metrics which have received data within that time); at most consts.MaxCustomMetricsPerAPI are returned
+func listCustomMetrics(api *spec.API) ([]customMetric, error) {
+	seen := map[string]bool{}
+	var customMetrics []customMetric
+
+	err := config.AWS.CloudWatch().ListMetricsPages(&cloudwatch.ListMetricsInput{
+		Namespace:  aws.String(config.Cluster.ClusterName),
+		Dimensions: []*cloudwatch.DimensionFilter{{Name: aws.String("APIName"), Value: aws.String(api.Name)}},
+	}, func(output *cloudwatch.ListMetricsOutput, lastPage bool) bool {
+		for _, metric := range output.Metrics {
+			metricName := aws.StringValue(metric.MetricName)
+			if !strings.HasPrefix(metricName, _customMetricPrefix) || seen[metricName] {
+				continue
+			}
+			// custom metrics are published with the APIName and metric_type dimensions
+			if len(metric.Dimensions) != 2 {
+				continue
+			}
+			for _, dimension := range metric.Dimensions {
+				if aws.StringValue(dimension.Name) != "metric_type" {
+					continue
+				}
+				metricType := metrics.CustomMetricType(aws.StringValue(dimension.Value))
+				if metricType == metrics.CounterCustomMetricType || metricType == metrics.HistogramCustomMetricType {
+					seen[metricName] = true
+					customMetrics = append(customMetrics, customMetric{
+						Name: metricName[len(_customMetricPrefix):],
+						Type: metricType,
+					})
+				}
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(customMetrics, func(i, j int) bool {
+		return customMetrics[i].Name < customMetrics[j].Name
+	})
+	if len(customMetrics) > consts.MaxCustomMetricsPerAPI {
+		customMetrics = customMetrics[:consts.MaxCustomMetricsPerAPI]
+	}
+
+	return customMetrics, nil
+}
+
+func getCustomStats(api *spec.API, customMetrics []customMetric, period int64, startTime *time.Time, endTime *time.Time) (map[string]*metrics.CustomStats, error) {
+	if len(customMetrics) == 0 {
+		return nil, nil
+	}
+
+	output, err := config.AWS.CloudWatch().GetMetricData(&cloudwatch.GetMetricDataInput{
+		StartTime:         startTime,
+		EndTime:           endTime,
+		MetricDataQueries: customMetricsDefs(api, customMetrics, period),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return extractCustomStats(customMetrics, output.MetricDataResults)
+}
+
+// query IDs are derived from the metrics' indexes, since metric names can't be used in them
+func customMetricsDefs(api *spec.API, customMetrics []customMetric, period int64) []*cloudwatch.MetricDataQuery {
+	var queries []*cloudwatch.MetricDataQuery
+
+	for i, customMetric := range customMetrics {
+		dimensions := getAPIDimensionsCounter(api)
+		stats := []string{"Sum"}
+		if customMetric.Type == metrics.HistogramCustomMetricType {
+			dimensions = getAPIDimensionsHistogram(api)
+			stats = []string{"SampleCount", "Average", "Minimum", "Maximum"}
+		}
+
+		for _, stat := range stats {
+			queries = append(queries, &cloudwatch.MetricDataQuery{
+				Id:    aws.String(fmt.Sprintf("custom_%d_%s", i, strings.ToLower(stat))),
+				Label: aws.String(customMetricLabel(i, stat)),
+				MetricStat: &cloudwatch.MetricStat{
+					Metric: &cloudwatch.Metric{
+						Namespace:  aws.String(config.Cluster.ClusterName),
+						MetricName: aws.String(_customMetricPrefix + customMetric.Name),
+						Dimensions: dimensions,
+					},
+					Stat:   aws.String(stat),
+					Period: aws.Int64(period),
+				},
+			})
+		}
+	}
+
+	return queries
+}
+
+func customMetricLabel(index int, stat string) string {
+	return fmt.Sprintf("custom_%d_%s", index, stat)
+}
+
+func extractCustomStats(customMetrics []customMetric, metricDataResults []*cloudwatch.MetricDataResult) (map[string]*metrics.CustomStats, error) {
+	results := map[string][]*float64{}
+	for _, metricData := range metricDataResults {
+		if metricData.Values != nil {
+			results[aws.StringValue(metricData.Label)] = metricData.Values
+		}
+	}
+
+	customStats := map[string]*metrics.CustomStats{}
+	for i, customMetric := range customMetrics {
+		stats := metrics.CustomStats{Type: customMetric.Type}
+
+		if customMetric.Type == metrics.CounterCustomMetricType {
+			stats.Sum = slices.Float64PtrSum(results[customMetricLabel(i, "Sum")]...)
+			if stats.Sum == nil {
+				continue
+			}
+		} else {
+			sampleCounts := results[customMetricLabel(i, "SampleCount")]
+			stats.Count = slices.Float64PtrSumInt(sampleCounts...)
+			if stats.Count == 0 {
+				continue
+			}
+
+			avg, err := slices.Float64PtrAvg(results[customMetricLabel(i, "Average")], sampleCounts)
+			if err != nil {
+				return nil, err
+			}
+			stats.Avg = avg
+			stats.Min = slices.Float64PtrMin(results[customMetricLabel(i, "Minimum")]...)
+			stats.Max = slices.Float64PtrMax(results[customMetricLabel(i, "Maximum")]...)
+		}
+
+		customStats[customMetric.Name] = &stats
+	}
+
+	if len(customStats) == 0 {
+		return nil, nil
+	}
+	return customStats, nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/types/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractCustomStats(t *testing.T) {
+	customMetrics := []customMetric{
+		{Name: "confidence", Type: metrics.HistogramCustomMetricType},
+		{Name: "low_confidence", Type: metrics.CounterCustomMetricType},
+		{Name: "unused", Type: metrics.CounterCustomMetricType},
+	}
+
+	result := func(label string, values ...float64) *cloudwatch.MetricDataResult {
+		return &cloudwatch.MetricDataResult{Label: aws.String(label), Values: aws.Float64Slice(values)}
+	}
+
+	customStats, err := extractCustomStats(customMetrics, []*cloudwatch.MetricDataResult{
+		result("custom_0_SampleCount", 3, 1),
+		result("custom_0_Average", 0.5, 0.9),
+		result("custom_0_Minimum", 0.2, 0.9),
+		result("custom_0_Maximum", 0.7, 0.9),
+		result("custom_1_Sum", 2, 5),
+		{Label: aws.String("custom_2_Sum")},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]*metrics.CustomStats{
+		"confidence": {
+			Type:  metrics.HistogramCustomMetricType,
+			Count: 4,
+			Avg:   pointer.Float64(0.6),
+			Min:   pointer.Float64(0.2),
+			Max:   pointer.Float64(0.9),
+		},
+		"low_confidence": {
+			Type: metrics.CounterCustomMetricType,
+			Sum:  pointer.Float64(7),
+		},
+	}, customStats)
+
+	customStats, err = extractCustomStats(customMetrics, nil)
+	require.NoError(t, err)
+	require.Nil(t, customStats)
+}
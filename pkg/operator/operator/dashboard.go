@@ -18,6 +18,7 @@ package operator
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/cortexlabs/cortex/pkg/consts"
 	"github.com/cortexlabs/cortex/pkg/lib/aws"
@@ -25,7 +26,17 @@ import (
 	"github.com/cortexlabs/cortex/pkg/operator/config"
 )
 
+// the dashboard is shared by all APIs, and is updated by reading and then writing it, so updates are serialized
+var _dashboardMutex sync.Mutex
+
 func addAPIToDashboard(dashboardName string, apiName string) error {
+	if !config.Cluster.HasAWSResources() {
+		return nil // lightweight and gcp clusters don't report metrics to cloudwatch
+	}
+
+	_dashboardMutex.Lock()
+	defer _dashboardMutex.Unlock()
+
 	// get current dashboard from cloudwatch (or a new dashboard if it was deleted)
 	dashboard, err := config.AWS.GetDashboardOrEmpty(dashboardName, consts.DashboardTitle)
 	if err != nil {
@@ -46,6 +57,13 @@ func addAPIToDashboard(dashboardName string, apiName string) error {
 }
 
 func removeAPIFromDashboard(allAPINames []string, dashboardName string, apiToRemove string) error {
+	if !config.Cluster.HasAWSResources() {
+		return nil
+	}
+
+	_dashboardMutex.Lock()
+	defer _dashboardMutex.Unlock()
+
 	// create a new base dashboard
 	dashboard := config.AWS.NewDashboard(consts.DashboardTitle)
 
@@ -80,7 +98,7 @@ func addAPIToDashboardObject(dashboard *aws.CloudWatchDashboard, dashboardName s
 	// top left widget
 	statCodeWidget := aws.MetricWidget(1, highestY+2, 11, 6, statusCodeMetric(dashboardName, apiName), "responses per minute", "Sum", 60, config.AWS.Region)
 	// top right widget
-	inFlightWidget := aws.MetricWidget(12, highestY+2, 11, 6, inFlightMetric(dashboardName, apiName), "total in-flight requests", "Sum", 10, config.AWS.Region)
+	inFlightWidget := aws.MetricWidget(12, highestY+2, 11, 6, inFlightMetric(dashboardName, apiName), "total in-flight requests", "Sum", int(config.Cluster.RequestMonitorFlushInterval), config.AWS.Region)
 	// bottem left widget
 	latencyWidgetP50 := aws.MetricWidget(1, highestY+8, 11, 6, latencyMetric(dashboardName, apiName), "median response time (ms)", "p50", 60, config.AWS.Region)
 	// bottom right widget
@@ -0,0 +1,113 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+)
+
+const (
+	_deadLettersDays      = 7 // the number of days (including today) which are summarized
+	_maxRecentDeadLetters = 50
+)
+
+// GetDeadLetters summarizes the records which a stream API wrote to its dead letter prefix (see DeadLetters in stream.py):
+// the number of records per day, and the most recent records with the context of their failure
+func GetDeadLetters(apiName string) (*schema.DeadLetters, error) {
+	deployment, err := getAPIDeployment(apiName)
+	if err != nil {
+		return nil, err
+	}
+	if deployment == nil {
+		return nil, ErrorAPINotDeployed(apiName)
+	}
+
+	api, err := DownloadAPISpec(apiName, deployment.Labels["apiID"])
+	if err != nil {
+		return nil, err
+	}
+	if api.Stream == nil || api.Stream.DeadLetterPrefix == nil {
+		return nil, ErrorDeadLetterPrefixNotConfigured(apiName)
+	}
+
+	awsClient, err := aws.NewFromClientS3Path(*api.Stream.DeadLetterPrefix, config.AWS)
+	if err != nil {
+		return nil, err
+	}
+	bucket, prefix, err := aws.SplitS3Path(*api.Stream.DeadLetterPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	deadLetters := &schema.DeadLetters{
+		APIName: apiName,
+		Prefix:  aws.JoinS3Path(*api.Stream.DeadLetterPrefix, apiName) + "/",
+		Counts:  map[string]int{},
+		Recent:  []schema.DeadLetter{},
+	}
+
+	var keys []string
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	for i := 0; i < _deadLettersDays; i++ {
+		day := today.AddDate(0, 0, -i).Format("2006-01-02")
+		objects, err := awsClient.ListS3Prefix(bucket, filepath.Join(prefix, apiName, day)+"/", false, nil)
+		if err != nil {
+			return nil, err
+		}
+		deadLetters.Counts[day] = len(objects)
+		keys = append(keys, objectKeys(objects)...)
+	}
+
+	// keys start with the record's timestamp, so they sort chronologically
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	if len(keys) > _maxRecentDeadLetters {
+		keys = keys[:_maxRecentDeadLetters]
+	}
+
+	for _, key := range keys {
+		data, err := awsClient.ReadBytesFromS3(bucket, key)
+		if err != nil {
+			return nil, err
+		}
+
+		var deadLetter schema.DeadLetter
+		if err := json.Unmarshal(data, &deadLetter); err != nil {
+			return nil, errors.Wrap(err, key)
+		}
+		deadLetter.S3Path = aws.S3Path(bucket, key)
+		deadLetters.Recent = append(deadLetters.Recent, deadLetter)
+	}
+
+	return deadLetters, nil
+}
+
+func objectKeys(objects []*s3.Object) []string {
+	keys := make([]string, 0, len(objects))
+	for _, object := range objects {
+		keys = append(keys, *object.Key)
+	}
+	return keys
+}
@@ -0,0 +1,286 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/hash"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/zip"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	kbatch "k8s.io/api/batch/v1"
+	kcore "k8s.io/api/core/v1"
+)
+
+const (
+	_dependencyBuildContainerName = "kaniko"
+	_dependencyBuildLabelKey      = "dependencyBuild"
+	_dependencyImagesDir          = "dependency_images"
+	_dependencyBuildContextFile   = "context.tar.gz"
+	_dependenciesInstalledFile    = "/src/cortex/dependencies_installed.txt" // run.sh skips installing the dependencies if this file exists
+)
+
+// the files which run.sh installs the project's dependencies from (see install_dependencies.sh)
+var _dependencyFiles = []string{"dependencies.sh", "environment.yml", "environment.yaml", "conda-packages.txt", "requirements.txt"}
+
+// prebuildDependencies starts a job which builds an image with the project's dependencies installed on top of the
+// predictor image (unless the image has already been built), and records it as the image for the API's replicas; images
+// are cached by the predictor image and the contents of the project's dependency files
+func prebuildDependencies(api *spec.API) error {
+	if !api.Predictor.PrebuildDependencies {
+		return nil
+	}
+
+	projectBytes, err := config.Bucket.ReadBytes(api.ProjectKey)
+	if err != nil {
+		return err
+	}
+	projectFiles, err := zip.UnzipMemToMem(projectBytes)
+	if err != nil {
+		return err
+	}
+
+	baseImage := api.PinnedImage(api.Predictor.Image)
+
+	var buf bytes.Buffer
+	buf.WriteString(baseImage)
+	buf.WriteString(consts.CortexVersion)
+	hasDependencies := false
+	for _, fileName := range _dependencyFiles {
+		if fileBytes, ok := projectFiles[fileName]; ok {
+			hasDependencies = true
+			buf.WriteString(fileName)
+			buf.Write(fileBytes)
+		}
+	}
+	if !hasDependencies {
+		return nil
+	}
+	buildID := hash.Bytes(buf.Bytes())
+
+	repositoryURI, err := dependencyImageRepositoryURI()
+	if err != nil {
+		return err
+	}
+	api.DependencyImage = repositoryURI + ":" + buildID
+
+	if err := ensureDependencyImageBuilt(api, baseImage, buildID, projectFiles); err != nil {
+		return errors.Wrap(err, "build dependency image")
+	}
+	return nil
+}
+
+func ensureDependencyImageBuilt(api *spec.API, baseImage string, buildID string, projectFiles map[string][]byte) error {
+	buildDir := dependencyBuildDir(buildID)
+	jobName := "dependency-build-" + buildID[:20]
+
+	isBuilt, err := config.Bucket.Exists(path.Join(buildDir, _successMarkerFile))
+	if err != nil {
+		return err
+	}
+	if isBuilt {
+		return nil
+	}
+
+	job, err := config.K8s.GetJob(jobName)
+	if err != nil {
+		return err
+	}
+	if job != nil && job.Status.CompletionTime == nil && job.Status.Failed == 0 {
+		return nil // the image is being built (possibly for another API with the same dependencies)
+	}
+
+	// retry images which could not be built
+	if job != nil {
+		if _, err := config.K8s.DeleteJob(jobName); err != nil {
+			return err
+		}
+	}
+	if err := config.Bucket.DeleteFile(path.Join(buildDir, _failureMarkerFile)); err != nil {
+		return err
+	}
+
+	buildContext, err := dependencyBuildContext(baseImage, projectFiles)
+	if err != nil {
+		return err
+	}
+	buildContextKey := path.Join(buildDir, _dependencyBuildContextFile)
+	if err := config.Bucket.UploadBytes(buildContext, buildContextKey); err != nil {
+		return err
+	}
+
+	_, err = config.K8s.CreateJob(dependencyBuildJobSpec(jobName, buildID, config.Bucket.Path(buildContextKey), api.DependencyImage))
+	return err
+}
+
+func dependencyImageRepository() string {
+	return "cortex-" + config.Cluster.ClusterName + "-dependencies"
+}
+
+// the dependency image is tagged with its build ID
+func dependencyBuildID(dependencyImage string) string {
+	return dependencyImage[strings.LastIndex(dependencyImage, ":")+1:]
+}
+
+func dependencyBuildDir(buildID string) string {
+	return path.Join(_dependencyImagesDir, buildID)
+}
+
+// the build context contains the project directory and a Dockerfile which installs its dependencies on top of the predictor image
+func dependencyBuildContext(baseImage string, projectFiles map[string][]byte) ([]byte, error) {
+	dockerfile := fmt.Sprintf(`FROM %s
+COPY project /mnt/project
+RUN bash -e /src/cortex/serve/install_dependencies.sh && touch %s && rm -rf /mnt/project
+`, baseImage, _dependenciesInstalledFile)
+
+	contextFiles := make(map[string][]byte, len(projectFiles)+1)
+	for fileName, fileBytes := range projectFiles {
+		contextFiles[path.Join("project", fileName)] = fileBytes
+	}
+	contextFiles["Dockerfile"] = []byte(dockerfile)
+
+	fileNames := make([]string, 0, len(contextFiles))
+	for fileName := range contextFiles {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, fileName := range fileNames {
+		header := &tar.Header{
+			Name: fileName,
+			Mode: 0755,
+			Size: int64(len(contextFiles[fileName])),
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if _, err := tarWriter.Write(contextFiles[fileName]); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func dependencyBuildJobSpec(jobName string, buildID string, buildContextPath string, destinationImage string) *kbatch.Job {
+	// on gcp, kaniko reads the build context from GCS and pushes to the registry as the workload's google service account
+	var envVars []kcore.EnvVar
+	if !isGCP() {
+		envVars = append(envVars, kcore.EnvVar{
+			Name:  "AWS_REGION",
+			Value: *config.Cluster.Region,
+		})
+	}
+
+	return k8s.Job(&k8s.JobSpec{
+		Name: jobName,
+		Labels: map[string]string{
+			_dependencyBuildLabelKey: "true",
+			"buildID":                buildID,
+		},
+		PodSpec: k8s.PodSpec{
+			Labels: map[string]string{
+				_dependencyBuildLabelKey: "true",
+			},
+			K8sPodSpec: kcore.PodSpec{
+				RestartPolicy: "Never",
+				Containers: []kcore.Container{
+					{
+						Name:            _dependencyBuildContainerName,
+						Image:           config.Cluster.ImageKaniko,
+						ImagePullPolicy: "Always",
+						Args: []string{
+							"--context=" + buildContextPath,
+							"--destination=" + destinationImage,
+						},
+						Env:     envVars,
+						EnvFrom: baseEnvFrom(),
+					},
+				},
+				NodeSelector: map[string]string{
+					"workload": "true",
+				},
+				Tolerations:        _tolerations,
+				ServiceAccountName: workloadServiceAccountName(),
+			},
+		},
+	})
+}
+
+// updateDependencyBuilds records the outcome of each finished dependency build in S3 (where the downloaders of the
+// APIs which use the image are waiting for it), and cleans up the jobs of images which were built successfully
+func updateDependencyBuilds() error {
+	jobs, err := config.K8s.ListJobsByLabel(_dependencyBuildLabelKey, "true")
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, job := range jobs {
+		buildDir := dependencyBuildDir(job.Labels["buildID"])
+
+		if job.Status.Succeeded > 0 {
+			if err := config.Bucket.UploadString("", path.Join(buildDir, _successMarkerFile)); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if _, err := config.K8s.DeleteJob(job.Name); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		if job.Status.Failed > 0 {
+			// failed jobs are kept so that their logs can be inspected, and are replaced when the API is redeployed
+			isRecorded, err := config.Bucket.Exists(path.Join(buildDir, _failureMarkerFile))
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if !isRecorded {
+				errStr := fmt.Sprintf("the image with the project's dependencies could not be built (the logs of the %s job in the cluster's default namespace contain the error; you can also deploy the API without prebuild_dependencies to see the error in the API's logs)", job.Name)
+				if err := config.Bucket.UploadString(errStr, path.Join(buildDir, _failureMarkerFile)); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+
+	if errors.HasError(errs) {
+		return errors.FirstError(errs...)
+	}
+	return nil
+}
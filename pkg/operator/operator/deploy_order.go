@@ -0,0 +1,85 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+)
+
+// DeployStages groups the indexes of the APIs into the stages in which they should be deployed: the APIs which an API
+// references (its experiment's variants and its fallback API) are deployed in an earlier stage than it, so that they
+// exist when its traffic is routed to them. The APIs within a stage don't reference each other, so they can be deployed
+// concurrently. APIs which reference each other are treated as if the reference which closes the cycle didn't exist;
+// within each stage, APIs are in the order they are listed.
+func DeployStages(apiConfigs []userconfig.API) [][]int {
+	indexes := make(map[string]int, len(apiConfigs))
+	for i, apiConfig := range apiConfigs {
+		indexes[apiConfig.Name] = i
+	}
+
+	const (
+		unvisited = -2
+		visiting  = -1
+	)
+	stageNums := make([]int, len(apiConfigs))
+	for i := range stageNums {
+		stageNums[i] = unvisited
+	}
+
+	var visit func(i int) int
+	visit = func(i int) int {
+		if stageNums[i] != unvisited {
+			return stageNums[i] // already visited (or -1 if it's part of a cycle)
+		}
+		stageNums[i] = visiting
+		stageNum := 0
+		for _, dependency := range apiDependencies(&apiConfigs[i]) {
+			if j, ok := indexes[dependency]; ok && j != i {
+				if dependencyStageNum := visit(j); dependencyStageNum+1 > stageNum {
+					stageNum = dependencyStageNum + 1
+				}
+			}
+		}
+		stageNums[i] = stageNum
+		return stageNum
+	}
+
+	var stages [][]int
+	for i := range apiConfigs {
+		stageNum := visit(i)
+		for len(stages) <= stageNum {
+			stages = append(stages, nil)
+		}
+		stages[stageNum] = append(stages[stageNum], i)
+	}
+
+	return stages
+}
+
+// apiDependencies returns the names of the APIs which the API routes traffic to
+func apiDependencies(apiConfig *userconfig.API) []string {
+	var dependencies []string
+	if apiConfig.Experiment != nil {
+		for _, variant := range apiConfig.Experiment.Variants {
+			dependencies = append(dependencies, variant.API)
+		}
+	}
+	if apiConfig.Networking.FallbackAPI != nil {
+		dependencies = append(dependencies, *apiConfig.Networking.FallbackAPI)
+	}
+	return dependencies
+}
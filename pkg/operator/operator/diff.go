@@ -0,0 +1,149 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	kapps "k8s.io/api/apps/v1"
+	"sigs.k8s.io/yaml"
+)
+
+type k8sResource struct {
+	kind    string
+	name    string
+	exists  bool
+	current interface{}
+	desired interface{}
+}
+
+// DiffAPI renders the kubernetes resources which UpdateAPI would apply for the API, and compares them against the
+// resources which are currently in the cluster; nothing is applied
+func DiffAPI(apiConfig *userconfig.API, projectID string) (*schema.DiffResult, error) {
+	api, prevDeployment, resources, err := desiredK8sResources(apiConfig, projectID)
+	if err != nil {
+		return nil, err
+	}
+	deployment := resources[0].desired.(*kapps.Deployment)
+
+	result := &schema.DiffResult{
+		APIName: api.Name,
+	}
+
+	if prevDeployment != nil && areAPIsEqual(prevDeployment, deployment) {
+		result.Message = fmt.Sprintf("%s is up to date", api.Name)
+		return result, nil
+	}
+
+	if prevDeployment == nil {
+		result.Message = fmt.Sprintf("%s will be created", api.Name)
+	} else {
+		result.Message = fmt.Sprintf("%s will be updated", api.Name)
+	}
+
+	for _, resource := range resources {
+		resourceDiff, err := diffResource(resource.kind, resource.name, resource.exists, resource.current, resource.desired)
+		if err != nil {
+			return nil, err
+		}
+		result.Resources = append(result.Resources, *resourceDiff)
+	}
+
+	return result, nil
+}
+
+// RenderAPI returns the kubernetes resources which UpdateAPI would apply for the API (regardless of whether they differ
+// from the resources which are currently in the cluster), as a multi-document YAML manifest; nothing is applied
+func RenderAPI(apiConfig *userconfig.API, projectID string) (*schema.RenderResult, error) {
+	api, _, resources, err := desiredK8sResources(apiConfig, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := make([]string, len(resources))
+	for i, resource := range resources {
+		manifest, err := yaml.Marshal(resource.desired)
+		if err != nil {
+			return nil, err
+		}
+		manifests[i] = fmt.Sprintf("# %s %s\n%s", resource.kind, resource.name, manifest)
+	}
+
+	return &schema.RenderResult{
+		APIName:  api.Name,
+		Manifest: strings.Join(manifests, "---\n"),
+	}, nil
+}
+
+// desiredK8sResources returns the API's spec, its current deployment (nil if it isn't deployed), and the resources
+// which UpdateAPI would apply for it (the deployment is first)
+func desiredK8sResources(apiConfig *userconfig.API, projectID string) (*spec.API, *kapps.Deployment, []k8sResource, error) {
+	prevDeployment, prevService, prevRoute, err := getK8sResources(apiConfig)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	deploymentID := k8s.RandomName()
+	if prevDeployment != nil && prevDeployment.Labels["deploymentID"] != "" {
+		deploymentID = prevDeployment.Labels["deploymentID"]
+	}
+
+	api := spec.GetAPISpec(apiConfig, projectID, deploymentID)
+	if err := pinArtifacts(api); err != nil {
+		return nil, nil, nil, err
+	}
+
+	deployment := deploymentSpec(api, prevDeployment)
+	deployment.Namespace = api.Namespace
+	service := serviceSpec(api)
+	service.Namespace = api.Namespace
+	route := router().routeSpec(api)
+	route.SetNamespace(api.Namespace)
+
+	resources := []k8sResource{
+		{"Deployment", deployment.Name, prevDeployment != nil, prevDeployment, deployment},
+		{"Service", service.Name, prevService != nil, prevService, service},
+		{router().routeKind(), route.GetName(), prevRoute != nil, routeObject(prevRoute), route},
+	}
+
+	return api, prevDeployment, resources, nil
+}
+
+func diffResource(kind string, name string, exists bool, current interface{}, desired interface{}) (*schema.ResourceDiff, error) {
+	changes, err := k8s.Diff(current, desired)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := yaml.Marshal(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema.ResourceDiff{
+		Kind:     kind,
+		Name:     name,
+		Created:  !exists,
+		Changes:  changes,
+		Manifest: string(manifest),
+	}, nil
+}
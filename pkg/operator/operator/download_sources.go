@@ -0,0 +1,194 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"encoding/base64"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/docker"
+	"github.com/cortexlabs/cortex/pkg/lib/gcp"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+)
+
+// downloadSource is a type of source which the downloader can fetch files from; each source type must also be
+// registered in the downloader (see pkg/workloads/cortex/downloader/download.py)
+type downloadSource struct {
+	options strset.Set // the options which the source type accepts (in downloadContainerArg.Options)
+
+	// validate returns an error if the downloader would not be able to fetch arg.From
+	validate func(arg downloadContainerArg) error
+}
+
+const (
+	_s3DownloadSourceType  = "s3"
+	_ociDownloadSourceType = "oci"
+	_gcsDownloadSourceType = "gcs"
+
+	// models are cached on the node by the hash of their contents, so that replicas on the same node share them (the
+	// model seeders of p2p_model_distribution serve the same directory to the other nodes)
+	_modelCacheVolumeName = "model-cache"
+	_modelCacheHostPath   = "/var/lib/cortex/model-cache"
+	_modelCacheMountPath  = "/model-cache"
+
+	// the headless service of the model-seeder daemonset (see manager/manifests/model-seeder.yaml.j2)
+	_modelSeederPeers = "model-seeder.default.svc.cluster.local:8890"
+)
+
+var _downloadSources = map[string]downloadSource{
+	_s3DownloadSourceType: {
+		options:  strset.New("cache_dir", "cache_max_bytes", "peers"),
+		validate: validateS3DownloadArg,
+	},
+	_ociDownloadSourceType: {
+		options:  strset.New("cache_dir", "cache_max_bytes"),
+		validate: validateOCIDownloadArg,
+	},
+	_gcsDownloadSourceType: {
+		options:  strset.New("cache_dir", "cache_max_bytes"),
+		validate: validateGCSDownloadArg,
+	},
+}
+
+func validateS3DownloadArg(arg downloadContainerArg) error {
+	if !aws.IsValidS3Path(arg.From) {
+		return ErrorInvalidDownloadSource(arg.From, _s3DownloadSourceType, "expected an s3 path (e.g. s3://my-bucket/my-model)")
+	}
+	return nil
+}
+
+func validateOCIDownloadArg(arg downloadContainerArg) error {
+	if !docker.IsValidOCIPath(arg.From) {
+		return ErrorInvalidDownloadSource(arg.From, _ociDownloadSourceType, "expected an OCI artifact path which includes the registry's host (e.g. oci://ghcr.io/my-org/my-model:v1)")
+	}
+	return nil
+}
+
+func validateGCSDownloadArg(arg downloadContainerArg) error {
+	if !gcp.IsValidGCSPath(arg.From) {
+		return ErrorInvalidDownloadSource(arg.From, _gcsDownloadSourceType, "expected a gcs path (e.g. gs://my-bucket/my-model)")
+	}
+	return nil
+}
+
+// bucketDownloadSourceType is the source type of files in the cluster's bucket
+func bucketDownloadSourceType() string {
+	if isGCP() {
+		return _gcsDownloadSourceType
+	}
+	return ""
+}
+
+// ociDownloadArg downloads the files of an OCI artifact (e.g. pushed with ORAS) into the to directory
+func ociDownloadArg(api *spec.API, ociPath string, to string, itemName string) downloadContainerArg {
+	return downloadContainerArg{
+		Type:     _ociDownloadSourceType,
+		Options:  modelCacheOptions(),
+		From:     docker.OCIPath(api.PinnedImage(docker.OCIReference(ociPath))),
+		To:       to,
+		ItemName: itemName,
+	}
+}
+
+// s3ModelDownloadArg caches the model in the node's model cache, so that other replicas on the node copy it rather
+// than downloading it again; when p2p_model_distribution is enabled, the model's files are downloaded from the
+// cluster's model seeders, so that each file is only downloaded from S3 once when many replicas start at the same time
+func s3ModelDownloadArg(arg downloadContainerArg) downloadContainerArg {
+	arg.Options = modelCacheOptions()
+	if config.Cluster.P2PModelDistribution {
+		arg.Options["peers"] = _modelSeederPeers
+	}
+	return arg
+}
+
+func modelCacheOptions() map[string]string {
+	return map[string]string{
+		"cache_dir": _modelCacheMountPath,
+		// the cache shares the instance's volume with the container images and the replicas' downloaded files
+		"cache_max_bytes": s.Int64(config.Cluster.InstanceVolumeSize * 1024 * 1024 * 1024 / 4),
+	}
+}
+
+func usesModelCache(downloadConfig downloadContainerConfig) bool {
+	for _, arg := range downloadConfig.DownloadArgs {
+		if _, ok := arg.Options["cache_dir"]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func downloadSourceType(arg downloadContainerArg) string {
+	if arg.Type == "" {
+		return _s3DownloadSourceType
+	}
+	return arg.Type
+}
+
+// apiDownloadConfig returns the files which the API's downloader fetches when a replica starts
+func apiDownloadConfig(api *spec.API) downloadContainerConfig {
+	switch api.Predictor.Type {
+	case userconfig.TensorFlowPredictorType:
+		return tfDownloadConfig(api)
+	case userconfig.ONNXPredictorType:
+		return onnxDownloadConfig(api)
+	case userconfig.LLMPredictorType:
+		return llmDownloadConfig(api)
+	default:
+		return pythonDownloadConfig(api)
+	}
+}
+
+// validateDownloadConfig ensures that the downloader supports each of the sources, so that the API fails to deploy
+// rather than its replicas failing to start
+func validateDownloadConfig(downloadConfig downloadContainerConfig) error {
+	for _, arg := range downloadConfig.DownloadArgs {
+		sourceType := downloadSourceType(arg)
+
+		source, ok := _downloadSources[sourceType]
+		if !ok {
+			return ErrorUnsupportedDownloadSource(arg.From, sourceType)
+		}
+
+		for option := range arg.Options {
+			if !source.options.Has(option) {
+				return ErrorUnsupportedDownloadOption(arg.From, sourceType, option)
+			}
+		}
+
+		// version IDs are specific to s3
+		if arg.VersionID != "" && sourceType != _s3DownloadSourceType {
+			return ErrorUnsupportedDownloadOption(arg.From, sourceType, "version_id")
+		}
+
+		if err := source.validate(arg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func encodeDownloadConfig(downloadConfig downloadContainerConfig) string {
+	downloadConfigBytes, _ := json.Marshal(downloadConfig)
+	return base64.URLEncoding.EncodeToString(downloadConfigBytes)
+}
@@ -18,18 +18,90 @@ package operator
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/types"
+	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
 )
 
 const (
-	ErrCortexInstallationBroken    = "operator.cortex_installation_broken"
-	ErrLoadBalancerInitializing    = "operator.load_balancer_initializing"
-	ErrMalformedConfig             = "operator.malformed_config"
-	ErrNoAPIs                      = "operator.no_apis"
-	ErrAPIUpdating                 = "operator.api_updating"
-	ErrAPINotDeployed              = "operator.api_not_deployed"
-	ErrNoAvailableNodeComputeLimit = "operator.no_available_node_compute_limit"
+	ErrCortexInstallationBroken      = "operator.cortex_installation_broken"
+	ErrLoadBalancerInitializing      = "operator.load_balancer_initializing"
+	ErrMalformedConfig               = "operator.malformed_config"
+	ErrNoAPIs                        = "operator.no_apis"
+	ErrAPIUpdating                   = "operator.api_updating"
+	ErrAPINotDeployed                = "operator.api_not_deployed"
+	ErrNoAvailableNodeComputeLimit   = "operator.no_available_node_compute_limit"
+	ErrInsufficientClusterCapacity   = "operator.insufficient_cluster_capacity"
+	ErrCannotChangeNamespace         = "operator.cannot_change_namespace"
+	ErrNotATeamMember                = "operator.not_a_team_member"
+	ErrAPIForbidden                  = "operator.api_forbidden"
+	ErrTeamQuotaExceeded             = "operator.team_quota_exceeded"
+	ErrCortexAPIProjectRequired      = "operator.cortex_api_project_required"
+	ErrCortexAPINameMismatch         = "operator.cortex_api_name_mismatch"
+	ErrInvalidCortexAPIProject       = "operator.invalid_cortex_api_project"
+	ErrInvalidLogContainer           = "operator.invalid_log_container"
+	ErrInvalidMetricsTimeRange       = "operator.invalid_metrics_time_range"
+	ErrInvalidMetricsPeriod          = "operator.invalid_metrics_period"
+	ErrTooManyMetricsDatapoints      = "operator.too_many_metrics_datapoints"
+	ErrConflictingMetricsFilters     = "operator.conflicting_metrics_filters"
+	ErrSpotNotEnabled                = "operator.spot_not_enabled"
+	ErrNoOnDemandNodeGroup           = "operator.no_on_demand_node_group"
+	ErrNodeGroupNotFound             = "operator.node_group_not_found"
+	ErrAutoscalingGroupNotFound      = "operator.autoscaling_group_not_found"
+	ErrEnvSourceNotFound             = "operator.env_source_not_found"
+	ErrNotificationFailed            = "operator.notification_failed"
+	ErrIngressControllerNotFound     = "operator.ingress_controller_not_found"
+	ErrRequiresIstioNetworking       = "operator.requires_istio_networking"
+	ErrNoExperiment                  = "operator.no_experiment"
+	ErrNoSLO                         = "operator.no_slo"
+	ErrExperimentVariantNamespace    = "operator.experiment_variant_namespace"
+	ErrInvalidReplayTimeRange        = "operator.invalid_replay_time_range"
+	ErrReplayTargetIsStreamAPI       = "operator.replay_target_is_stream_api"
+	ErrTooManyReplayRequests         = "operator.too_many_replay_requests"
+	ErrReplayNotFound                = "operator.replay_not_found"
+	ErrDeadLetterPrefixNotConfigured = "operator.dead_letter_prefix_not_configured"
+	ErrSecretKeyNotFound             = "operator.secret_key_not_found"
+	ErrScratchVolumeFieldChanged     = "operator.scratch_volume_field_changed"
+	ErrScratchVolumeSizeDecreased    = "operator.scratch_volume_size_decreased"
+	ErrReservedContainerName         = "operator.reserved_container_name"
+	ErrUnsupportedDownloadSource     = "operator.unsupported_download_source"
+	ErrUnsupportedDownloadOption     = "operator.unsupported_download_option"
+	ErrInvalidDownloadSource         = "operator.invalid_download_source"
+	ErrProjectTooLarge               = "operator.project_too_large"
+	ErrProjectFileHashMismatch       = "operator.project_file_hash_mismatch"
+	ErrProjectFileNotUploaded        = "operator.project_file_not_uploaded"
+	ErrInvalidProjectFilePath        = "operator.invalid_project_file_path"
+	ErrInvalidProjectFileHash        = "operator.invalid_project_file_hash"
+	ErrNoOperatorLeader              = "operator.no_operator_leader"
+	ErrLostLeadership                = "operator.lost_leadership"
+	ErrAdminOnly                     = "operator.admin_only"
+	ErrInvalidBackup                 = "operator.invalid_backup"
+	ErrNodeMigrationInProgress       = "operator.node_migration_in_progress"
+	ErrNoNodesInGeneration           = "operator.no_nodes_in_generation"
+	ErrNodeMigrationNotFound         = "operator.node_migration_not_found"
+	ErrNodeMigrationTimeout          = "operator.node_migration_timeout"
+	ErrPodEvictionTimeout            = "operator.pod_eviction_timeout"
+	ErrInvalidLoadTestConfig         = "operator.invalid_load_test_config"
+	ErrLoadTestTargetIsStreamAPI     = "operator.load_test_target_is_stream_api"
+	ErrLoadTestInProgress            = "operator.load_test_in_progress"
+	ErrLoadTestNotFound              = "operator.load_test_not_found"
+	ErrLoadTestJobFailed             = "operator.load_test_job_failed"
+	ErrLoadTestTimeout               = "operator.load_test_timeout"
+	ErrMTLSRequiresSidecar           = "operator.mtls_requires_sidecar"
+	ErrClusterRequiresMTLS           = "operator.cluster_requires_mtls"
+	ErrWindowNotMultipleOfFlush      = "operator.window_not_multiple_of_flush"
+	ErrRequiresCloudWatchMetricSink  = "operator.requires_cloudwatch_metric_sink"
+	ErrUnsupportedOnGCP              = "operator.unsupported_on_gcp"
+	ErrGCPImageRegistryRequired      = "operator.gcp_image_registry_required"
+	ErrInvalidBatchJob               = "operator.invalid_batch_job"
+	ErrBatchJobUnsupportedAPI        = "operator.batch_job_unsupported_api"
+	ErrBatchJobNotFound              = "operator.batch_job_not_found"
+	ErrInvalidBatchTriggerObject     = "operator.invalid_batch_trigger_object"
 )
 
 func ErrorCortexInstallationBroken() error {
@@ -60,13 +132,490 @@ func ErrorAPINotDeployed(apiName string) error {
 	})
 }
 
-func ErrorNoAvailableNodeComputeLimit(resource string, reqStr string, maxStr string) error {
-	message := fmt.Sprintf("no instances can satisfy the requested %s quantity - requested %s %s but instances only have %s %s available", resource, reqStr, resource, maxStr, resource)
+func ErrorNoAvailableNodeComputeLimit(resource string, reqStr string, maxStr string, instanceType string) error {
+	message := fmt.Sprintf("no instances can satisfy the requested %s quantity - requested %s %s but %s instances only have %s %s available", resource, reqStr, resource, instanceType, maxStr, resource)
 	if maxStr == "0" {
-		message = fmt.Sprintf("no instances can satisfy the requested %s quantity - requested %s %s but instances don't have any %s", resource, reqStr, resource, resource)
+		message = fmt.Sprintf("no instances can satisfy the requested %s quantity - requested %s %s but %s instances don't have any %s", resource, reqStr, resource, instanceType, resource)
 	}
 	return errors.WithStack(&errors.Error{
 		Kind:    ErrNoAvailableNodeComputeLimit,
 		Message: message,
 	})
 }
+
+func ErrorInsufficientClusterCapacity(minReplicas int32, maxReplicas int64, maxInstances int64) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInsufficientClusterCapacity,
+		Message: fmt.Sprintf("%d replicas were requested, but at most %d %s can run on this cluster (which is limited to %d %s); reduce the api's compute request or %s, or increase the cluster's max_instances", minReplicas, maxReplicas, s.PluralS("replica", maxReplicas), maxInstances, s.PluralS("instance", maxInstances), userconfig.MinReplicasKey),
+	})
+}
+
+func ErrorCannotChangeNamespace(apiName string, prevNamespace string, namespace string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrCannotChangeNamespace,
+		Message: fmt.Sprintf("%s is already deployed in the %s namespace; to move it to the %s namespace, first delete it with `cortex delete %s`", apiName, prevNamespace, namespace, apiName),
+	})
+}
+
+func ErrorNotATeamMember(arn string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrNotATeamMember,
+		Message: fmt.Sprintf("%s is not an admin or a member of any team on this cluster", arn),
+	})
+}
+
+func ErrorAPIForbidden(apiName string, ownerTeam string) error {
+	message := fmt.Sprintf("%s is owned by the %s team; only members of that team or admins can modify it", apiName, ownerTeam)
+	if ownerTeam == "" {
+		message = fmt.Sprintf("%s is not owned by any team; only admins can modify it", apiName)
+	}
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrAPIForbidden,
+		Message: message,
+	})
+}
+
+func ErrorTeamQuotaExceeded(teamName string, limitKey string, requested int64, limit int64) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrTeamQuotaExceeded,
+		Message: fmt.Sprintf("this deployment would bring the %s team's usage to %d, which exceeds its %s limit of %d", teamName, requested, limitKey, limit),
+	})
+}
+
+func ErrorInvalidCortexAPIProject(apiName string, projectPath string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidCortexAPIProject,
+		Message: fmt.Sprintf("the %s CortexAPI resource's spec.project (%s) must be an S3 path (e.g. s3://my-bucket/project.zip), or a GCS path if the cluster is on gcp", apiName, projectPath),
+	})
+}
+
+func ErrorCortexAPIProjectRequired(apiName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrCortexAPIProjectRequired,
+		Message: fmt.Sprintf("the %s CortexAPI resource must specify either spec.project (the S3 path to a zip of the project directory) or spec.projectID", apiName),
+	})
+}
+
+func ErrorCortexAPINameMismatch(resourceName string, apiName interface{}) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrCortexAPINameMismatch,
+		Message: fmt.Sprintf("the name in the API's configuration (%s) must match the name of its CortexAPI resource (%s); the name can also be omitted from the configuration", s.UserStr(apiName), resourceName),
+	})
+}
+
+func ErrorInvalidLogContainer(containerName string, validContainerNames []string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidLogContainer,
+		Message: fmt.Sprintf("invalid container %s; valid containers are %s", s.UserStr(containerName), s.StrsAnd(validContainerNames)),
+	})
+}
+
+func ErrorInvalidMetricsTimeRange(startTime time.Time, endTime time.Time) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidMetricsTimeRange,
+		Message: fmt.Sprintf("the end of the time range (%s) must be after its start (%s)", endTime.Format(time.RFC3339), startTime.Format(time.RFC3339)),
+	})
+}
+
+func ErrorInvalidMetricsPeriod(period int64) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidMetricsPeriod,
+		Message: fmt.Sprintf("invalid period (%d seconds); the period must be 1, 5, 10, 30, or a multiple of 60 seconds", period),
+	})
+}
+
+func ErrorTooManyMetricsDatapoints(period int64, maxDatapoints int) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrTooManyMetricsDatapoints,
+		Message: fmt.Sprintf("a period of %d seconds would return more than %d datapoints for this time range; increase the period or shorten the time range", period, maxDatapoints),
+	})
+}
+
+func ErrorConflictingMetricsFilters() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrConflictingMetricsFilters,
+		Message: "metrics can be filtered by either api id or model, but not both",
+	})
+}
+
+func ErrorSpotNotEnabled() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrSpotNotEnabled,
+		Message: "the cluster does not use spot instances; set `spot: true` in your cluster configuration and run `cortex cluster up` with a new cluster to use them",
+	})
+}
+
+func ErrorNoOnDemandNodeGroup() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrNoOnDemandNodeGroup,
+		Message: "the cluster does not have an on-demand node group; set `on_demand_backup: true` in your cluster's `spot_config` to create one",
+	})
+}
+
+func ErrorNodeGroupNotFound(nodeGroupName string, availableNodeGroups []string) error {
+	message := fmt.Sprintf("the cluster does not have a node group named %s", nodeGroupName)
+	if len(availableNodeGroups) > 0 {
+		message += fmt.Sprintf(" (available node groups: %s)", s.StrsAnd(availableNodeGroups))
+	} else {
+		message += "; node groups can be configured via `node_groups` in your cluster configuration when creating a new cluster"
+	}
+
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrNodeGroupNotFound,
+		Message: message,
+	})
+}
+
+func ErrorAutoscalingGroupNotFound(nodeGroupName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrAutoscalingGroupNotFound,
+		Message: fmt.Sprintf("unable to find the autoscaling group of the %s node group", nodeGroupName),
+	})
+}
+
+func ErrorEnvSourceNotFound(sourceType string, name string, namespace string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrEnvSourceNotFound,
+		Message: fmt.Sprintf("%s %s does not exist in the %s namespace", sourceType, s.UserStr(name), s.UserStr(namespace)),
+	})
+}
+
+func ErrorNotificationFailed(destination string, statusCode int, body string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrNotificationFailed,
+		Message: fmt.Sprintf("%s responded with status code %d: %s", destination, statusCode, s.TruncateEllipses(body, 200)),
+	})
+}
+
+func ErrorIngressControllerNotFound(service string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrIngressControllerNotFound,
+		Message: fmt.Sprintf("unable to find the ingress controller's service (%s); set %s in your cluster configuration to the namespace/name of your ingress controller's LoadBalancer service", service, clusterconfig.IngressControllerServiceKey),
+	})
+}
+
+func ErrorRequiresIstioNetworking(feature string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrRequiresIstioNetworking,
+		Message: fmt.Sprintf("%s is only supported when %s is %s (this cluster uses %s)", feature, clusterconfig.NetworkingBackendKey, clusterconfig.IstioNetworkingBackend, clusterconfig.IngressNetworkingBackend),
+	})
+}
+
+func ErrorNoExperiment(apiName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrNoExperiment,
+		Message: fmt.Sprintf("%s does not have an %s configured", apiName, userconfig.ExperimentKey),
+	})
+}
+
+func ErrorNoSLO(apiName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrNoSLO,
+		Message: fmt.Sprintf("%s does not have an %s configured", apiName, userconfig.SLOKey),
+	})
+}
+
+func ErrorExperimentVariantNamespace(variantAPIName string, variantNamespace string, namespace string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrExperimentVariantNamespace,
+		Message: fmt.Sprintf("%s is deployed in the %s namespace, but experiment variants must be in the same namespace as the experiment's API (%s)", s.UserStr(variantAPIName), s.UserStr(variantNamespace), s.UserStr(namespace)),
+	})
+}
+
+func ErrorInvalidReplayTimeRange(startTime time.Time, endTime time.Time, maxTimeRange time.Duration) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidReplayTimeRange,
+		Message: fmt.Sprintf("the end of the time range (%s) must be after its start (%s), and the time range must not be longer than %s", endTime.Format(time.RFC3339), startTime.Format(time.RFC3339), maxTimeRange.String()),
+	})
+}
+
+func ErrorReplayTargetIsStreamAPI(apiName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrReplayTargetIsStreamAPI,
+		Message: fmt.Sprintf("requests can't be replayed to %s because it is a stream API (it does not serve requests)", apiName),
+	})
+}
+
+func ErrorTooManyReplayRequests(maxRequests int) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrTooManyReplayRequests,
+		Message: fmt.Sprintf("more than %d requests were captured in this time range; shorten the time range", maxRequests),
+	})
+}
+
+func ErrorReplayNotFound(apiName string, replayID string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrReplayNotFound,
+		Message: fmt.Sprintf("replay %s of %s was not found", replayID, apiName),
+	})
+}
+
+func ErrorDeadLetterPrefixNotConfigured(apiName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDeadLetterPrefixNotConfigured,
+		Message: fmt.Sprintf("%s does not write failed records to S3; specify %s in its %s configuration", apiName, userconfig.DeadLetterPrefixKey, userconfig.StreamKey),
+	})
+}
+
+func ErrorSecretKeyNotFound(secretName string, key string, namespace string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrSecretKeyNotFound,
+		Message: fmt.Sprintf("secret %s in the %s namespace does not contain the key %s", s.UserStr(secretName), s.UserStr(namespace), s.UserStr(key)),
+	})
+}
+
+func ErrorScratchVolumeFieldChanged(apiName string, prevValue string, newValue string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrScratchVolumeFieldChanged,
+		Message: fmt.Sprintf("cannot change from %s to %s, since %s's scratch volume has already been created; to recreate the volume (which deletes its contents), remove %s from the API's configuration and deploy it, and then add it back", prevValue, newValue, apiName, userconfig.ScratchVolumeKey),
+	})
+}
+
+func ErrorScratchVolumeSizeDecreased(apiName string, prevSize kresource.Quantity, newSize kresource.Quantity) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrScratchVolumeSizeDecreased,
+		Message: fmt.Sprintf("cannot decrease from %s to %s, since %s's scratch volume can only be expanded; to recreate the volume (which deletes its contents), remove %s from the API's configuration and deploy it, and then add it back", prevSize.String(), newSize.String(), apiName, userconfig.ScratchVolumeKey),
+	})
+}
+
+func ErrorReservedContainerName(name string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrReservedContainerName,
+		Message: fmt.Sprintf("%s is reserved for one of cortex's containers, so it can't be used as the name of an init container", s.UserStr(name)),
+	})
+}
+
+func ErrorUnsupportedDownloadSource(from string, sourceType string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrUnsupportedDownloadSource,
+		Message: fmt.Sprintf("unable to download %s: %s sources are not supported by the downloader", from, s.UserStr(sourceType)),
+	})
+}
+
+func ErrorUnsupportedDownloadOption(from string, sourceType string, option string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrUnsupportedDownloadOption,
+		Message: fmt.Sprintf("unable to download %s: the %s option is not supported by %s sources", from, s.UserStr(option), s.UserStr(sourceType)),
+	})
+}
+
+func ErrorInvalidDownloadSource(from string, sourceType string, reason string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidDownloadSource,
+		Message: fmt.Sprintf("%s is not a valid %s source: %s", from, s.UserStr(sourceType), reason),
+	})
+}
+
+func ErrorProjectFileHashMismatch(fileHash string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrProjectFileHashMismatch,
+		Message: fmt.Sprintf("the contents of the uploaded project file %s don't match its hash", fileHash),
+	})
+}
+
+func ErrorProjectFileNotUploaded(path string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrProjectFileNotUploaded,
+		Message: fmt.Sprintf("project file %s was not uploaded; please try deploying again", path),
+	})
+}
+
+func ErrorInvalidProjectFilePath(path string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidProjectFilePath,
+		Message: fmt.Sprintf("%s is not a valid project file path", s.UserStr(path)),
+	})
+}
+
+func ErrorInvalidProjectFileHash(fileHash string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidProjectFileHash,
+		Message: fmt.Sprintf("%s is not a valid project file hash (expected %d lowercase hexadecimal characters)", s.UserStr(fileHash), _projectFileHashLength),
+	})
+}
+
+func ErrorProjectTooLarge(projectSize int, maxProjectSize string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrProjectTooLarge,
+		Message: fmt.Sprintf("your zipped project directory is %s, which exceeds the cluster's max_project_size (%s); exclude unnecessary files (e.g. models and datasets, which can be downloaded from s3) by listing them in a .cortexignore file in your project directory, or increase max_project_size via `cortex cluster configure`", s.IntToBase2Byte(projectSize), maxProjectSize),
+	})
+}
+
+func ErrorNoOperatorLeader() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrNoOperatorLeader,
+		Message: "the operator's replicas are electing a leader; please try again in a few seconds",
+	})
+}
+
+func ErrorLostLeadership() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrLostLeadership,
+		Message: "this operator replica is no longer the leader; restarting",
+	})
+}
+
+func ErrorAdminOnly(action string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrAdminOnly,
+		Message: fmt.Sprintf("only admins can %s", action),
+	})
+}
+
+func ErrorInvalidBackup(path string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidBackup,
+		Message: fmt.Sprintf("%s is not a cortex backup (it does not contain %s)", path, _backupManifestFile),
+	})
+}
+
+func ErrorNodeMigrationInProgress() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrNodeMigrationInProgress,
+		Message: "the cluster's nodes are already being migrated; please wait for the migration to finish",
+	})
+}
+
+func ErrorNoNodesInGeneration(generation string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrNoNodesInGeneration,
+		Message: fmt.Sprintf("there are no nodes in node generation %s, so the cluster's apis can't be migrated to it", generation),
+	})
+}
+
+func ErrorNodeMigrationNotFound() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrNodeMigrationNotFound,
+		Message: "the cluster's nodes have not been migrated",
+	})
+}
+
+func ErrorNodeMigrationTimeout(apiName string, timeout time.Duration) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrNodeMigrationTimeout,
+		Message: fmt.Sprintf("%s's replicas were not migrated to the new nodes within %s (the new replicas may be unable to start, or the new nodes may not have enough capacity)", apiName, timeout.String()),
+	})
+}
+
+func ErrorPodEvictionTimeout(podNames []string, timeout time.Duration) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrPodEvictionTimeout,
+		Message: fmt.Sprintf("the eviction of %s was blocked by %s for %s", s.StrsAnd(podNames), s.PluralCustom("its pod disruption budget", "their pod disruption budgets", len(podNames)), timeout.String()),
+	})
+}
+
+func ErrorInvalidLoadTestConfig(message string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidLoadTestConfig,
+		Message: message,
+	})
+}
+
+func ErrorLoadTestTargetIsStreamAPI(apiName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrLoadTestTargetIsStreamAPI,
+		Message: fmt.Sprintf("%s can't be load tested because it is a stream API (it does not serve requests)", apiName),
+	})
+}
+
+func ErrorLoadTestInProgress(apiName string, loadTestID string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrLoadTestInProgress,
+		Message: fmt.Sprintf("load test %s of %s is still running; please wait for it to finish", loadTestID, apiName),
+	})
+}
+
+func ErrorLoadTestNotFound(apiName string, loadTestID string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrLoadTestNotFound,
+		Message: fmt.Sprintf("load test %s of %s was not found", loadTestID, apiName),
+	})
+}
+
+func ErrorLoadTestJobFailed(lastLog string) error {
+	message := "the load test's job failed"
+	if lastLog != "" {
+		message += ": " + lastLog
+	}
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrLoadTestJobFailed,
+		Message: message,
+	})
+}
+
+func ErrorLoadTestTimeout(timeout time.Duration) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrLoadTestTimeout,
+		Message: fmt.Sprintf("the load test didn't finish within %s (its job may not have been able to start)", timeout.String()),
+	})
+}
+
+func ErrorMTLSRequiresSidecar() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrMTLSRequiresSidecar,
+		Message: fmt.Sprintf("`%s: true` requires `%s: true`", userconfig.MTLSKey, userconfig.EnabledKey),
+	})
+}
+
+func ErrorWindowNotMultipleOfFlush(window time.Duration, flushInterval time.Duration) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrWindowNotMultipleOfFlush,
+		Message: fmt.Sprintf("%s must be a multiple of %s (this cluster's %s) to be averaged over, but %s was specified", userconfig.WindowKey, flushInterval.String(), clusterconfig.RequestMonitorFlushIntervalKey, window.String()),
+	})
+}
+
+func ErrorRequiresCloudWatchMetricSink(metricSinks []string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrRequiresCloudWatchMetricSink,
+		Message: fmt.Sprintf("this field requires the %s metric sink, since it's evaluated from the API's request metrics in cloudwatch (this cluster's %s are %s)", clusterconfig.CloudWatchMetricSink, clusterconfig.MetricSinksKey, s.StrsAnd(metricSinks)),
+	})
+}
+
+func ErrorClusterRequiresMTLS(key string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrClusterRequiresMTLS,
+		Message: fmt.Sprintf("`%s: false` is not allowed because this cluster has `%s: true` (all APIs must be in the mesh and only accept mutual TLS connections)", key, clusterconfig.APIMTLSKey),
+	})
+}
+
+func ErrorUnsupportedOnGCP(key string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrUnsupportedOnGCP,
+		Message: fmt.Sprintf("%s is not supported because this cluster has `%s: %s`", key, clusterconfig.ProviderKey, types.GCPProviderType),
+	})
+}
+
+func ErrorGCPImageRegistryRequired(key string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrGCPImageRegistryRequired,
+		Message: fmt.Sprintf("%s requires the cluster's %s.%s to be configured (the images are pushed to it)", key, clusterconfig.GCPKey, clusterconfig.GCPImageRegistryKey),
+	})
+}
+
+func ErrorInvalidBatchJob(message string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidBatchJob,
+		Message: message,
+	})
+}
+
+func ErrorBatchJobUnsupportedAPI(apiName string, reason string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrBatchJobUnsupportedAPI,
+		Message: fmt.Sprintf("%s can't run batch jobs because %s", apiName, reason),
+	})
+}
+
+func ErrorBatchJobNotFound(apiName string, jobID string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrBatchJobNotFound,
+		Message: fmt.Sprintf("batch job %s of %s was not found", jobID, apiName),
+	})
+}
+
+func ErrorInvalidBatchTriggerObject(object string, reason string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidBatchTriggerObject,
+		Message: fmt.Sprintf("%s can't be submitted as a batch job: %s", object, reason),
+	})
+}
@@ -0,0 +1,424 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/parallel"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	istioclientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+)
+
+const (
+	_experimentCheckPeriod = time.Minute
+
+	_experimentPromotedEvent = "experiment_promoted"
+
+	_experimentPromotedAnnotationKey = "networking.cortex.dev/experiment-promoted"
+)
+
+// experimentDestinations returns the variants of the API's experiment, which receive their share of the API's traffic
+// (variants must be in the same namespace as the API)
+func experimentDestinations(api *spec.API) []k8s.WeightedDestination {
+	if api.Experiment == nil {
+		return nil
+	}
+
+	destinations := make([]k8s.WeightedDestination, len(api.Experiment.Variants))
+	for i, variant := range api.Experiment.Variants {
+		destinations[i] = k8s.WeightedDestination{
+			ServiceName: k8sName(variant.API),
+			ServicePort: _defaultPortInt32,
+			Weight:      variant.Weight,
+		}
+	}
+	return destinations
+}
+
+// GetExperimentResults compares the API's metrics with those of the variants in its experiment
+func GetExperimentResults(apiName string) (*schema.ExperimentResults, error) {
+	deployment, err := getAPIDeployment(apiName)
+	if err != nil {
+		return nil, err
+	}
+	if deployment == nil {
+		return nil, ErrorAPINotDeployed(apiName)
+	}
+
+	api, err := DownloadAPISpec(apiName, deployment.Labels["apiID"])
+	if err != nil {
+		return nil, err
+	}
+	if api.Experiment == nil {
+		return nil, ErrorNoExperiment(apiName)
+	}
+
+	virtualService, err := config.K8sNamespace(deployment.Namespace).GetVirtualService(k8sName(apiName))
+	if err != nil {
+		return nil, err
+	}
+
+	results, _, err := getExperimentResults(api, virtualService)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// the stats of the control and its variants are also returned (in the same order as the results)
+func getExperimentResults(api *spec.API, virtualService *istioclientnetworking.VirtualService) (*schema.ExperimentResults, []experimentStats, error) {
+	experiment := api.Experiment
+
+	// the experiment is restarted whenever the API is updated
+	startTime := time.Unix(api.LastUpdated, 0)
+	endTime := time.Now()
+
+	apiNames := []string{api.Name}
+	for _, variant := range experiment.Variants {
+		apiNames = append(apiNames, variant.API)
+	}
+
+	stats := make([]experimentStats, len(apiNames))
+	fns := make([]func() error, len(apiNames))
+	for i := range apiNames {
+		localIdx := i
+		fns[i] = func() error {
+			var err error
+			stats[localIdx], err = getExperimentStats(apiNames[localIdx], startTime, endTime)
+			return err
+		}
+	}
+	if err := parallel.RunFirstErr(fns[0], fns[1:]...); err != nil {
+		return nil, nil, err
+	}
+
+	weights := routeWeights(virtualService)
+
+	results := &schema.ExperimentResults{
+		APIName:    api.Name,
+		Metric:     experiment.Metric.String(),
+		Confidence: experiment.Confidence,
+		StartTime:  startTime,
+		Variants:   make([]schema.ExperimentVariantResults, len(apiNames)),
+	}
+
+	control := stats[0]
+	var winnerStats *experimentStats
+	for i, apiName := range apiNames {
+		variantResults := schema.ExperimentVariantResults{
+			APIName:    apiName,
+			Weight:     weights[k8sName(apiName)],
+			Requests:   stats[i].requests,
+			ErrorRate:  stats[i].errorRate(),
+			LatencyAvg: stats[i].latency,
+			RewardAvg:  stats[i].rewardAvg(),
+		}
+
+		if i > 0 {
+			variantResults.PValue = experimentPValue(experiment, control, stats[i])
+			if variantResults.PValue != nil && *variantResults.PValue < 1-experiment.Confidence && stats[i].isBetterThan(control, experiment.Metric) {
+				variantResults.Significant = true
+				if winnerStats == nil || stats[i].isBetterThan(*winnerStats, experiment.Metric) {
+					winnerStats = &stats[i]
+					results.Winner = pointer.String(apiName)
+				}
+			}
+		}
+
+		results.Variants[i] = variantResults
+	}
+
+	results.Promoted = virtualService != nil && isExperimentPromoted(virtualService)
+
+	return results, stats, nil
+}
+
+type experimentStats struct {
+	rolloutStats
+	rewardCount      int64
+	rewardSum        float64
+	rewardSquaredSum float64
+}
+
+func (stats experimentStats) rewardAvg() *float64 {
+	if stats.rewardCount == 0 {
+		return nil
+	}
+	return pointer.Float64(stats.rewardSum / float64(stats.rewardCount))
+}
+
+func (stats experimentStats) rewardVariance() float64 {
+	mean := stats.rewardSum / float64(stats.rewardCount)
+	return math.Max(stats.rewardSquaredSum/float64(stats.rewardCount)-mean*mean, 0)
+}
+
+// the number of samples of the experiment's metric
+func (stats experimentStats) samples(metric userconfig.ExperimentMetricType) int64 {
+	if metric == userconfig.RewardExperimentMetricType {
+		return stats.rewardCount
+	}
+	return stats.requests
+}
+
+func (stats experimentStats) isBetterThan(other experimentStats, metric userconfig.ExperimentMetricType) bool {
+	if metric == userconfig.RewardExperimentMetricType {
+		return *stats.rewardAvg() > *other.rewardAvg()
+	}
+	return stats.errorRate() < other.errorRate()
+}
+
+// experimentPValue returns the (two-sided) p-value of the difference between the control and the variant on the experiment's
+// metric: a two-proportion z-test for the error rate, and a z-test of the difference in means for the reward (the sample sizes
+// are expected to be large); nil is returned if either has fewer than min_requests samples
+func experimentPValue(experiment *userconfig.Experiment, control experimentStats, variant experimentStats) *float64 {
+	controlSamples := float64(control.samples(experiment.Metric))
+	variantSamples := float64(variant.samples(experiment.Metric))
+	if int64(controlSamples) < experiment.MinRequests || int64(variantSamples) < experiment.MinRequests {
+		return nil
+	}
+
+	var difference, standardError float64
+	switch experiment.Metric {
+	case userconfig.RewardExperimentMetricType:
+		difference = *variant.rewardAvg() - *control.rewardAvg()
+		standardError = math.Sqrt(control.rewardVariance()/controlSamples + variant.rewardVariance()/variantSamples)
+	default:
+		difference = variant.errorRate() - control.errorRate()
+		pooledErrorRate := float64(control.errors+variant.errors) / (controlSamples + variantSamples)
+		standardError = math.Sqrt(pooledErrorRate * (1 - pooledErrorRate) * (1/controlSamples + 1/variantSamples))
+	}
+
+	if standardError == 0 {
+		if difference == 0 {
+			return pointer.Float64(1)
+		}
+		return pointer.Float64(0)
+	}
+
+	z := difference / standardError
+	return pointer.Float64(math.Erfc(math.Abs(z) / math.Sqrt2))
+}
+
+func getExperimentStats(apiName string, startTime time.Time, endTime time.Time) (experimentStats, error) {
+	var stats experimentStats
+
+	err := parallel.RunFirstErr(
+		func() error {
+			var err error
+			stats.rolloutStats, err = getRolloutStats(apiName, "", startTime, endTime)
+			return err
+		},
+		func() error {
+			return getRewardStats(apiName, startTime, endTime, &stats)
+		},
+	)
+	if err != nil {
+		return experimentStats{}, err
+	}
+
+	return stats, nil
+}
+
+// the reward is reported by the predictor's reward() method (along with its square, so that its variance can be computed)
+func getRewardStats(apiName string, startTime time.Time, endTime time.Time, stats *experimentStats) error {
+	period := defaultMetricsPeriod(endTime.Sub(startTime))
+
+	rewardQuery := func(id string, metricName string, stat string) *cloudwatch.MetricDataQuery {
+		return &cloudwatch.MetricDataQuery{
+			Id: aws.String(id),
+			MetricStat: &cloudwatch.MetricStat{
+				Metric: &cloudwatch.Metric{
+					Namespace:  aws.String(config.Cluster.ClusterName),
+					MetricName: aws.String(metricName),
+					Dimensions: timeSeriesDimensions(apiName, "", "", "histogram"),
+				},
+				Stat:   aws.String(stat),
+				Period: aws.Int64(period),
+			},
+		}
+	}
+
+	input := &cloudwatch.GetMetricDataInput{
+		StartTime: aws.Time(startTime),
+		EndTime:   aws.Time(endTime),
+		MetricDataQueries: []*cloudwatch.MetricDataQuery{
+			rewardQuery("reward_count", "Reward", "SampleCount"),
+			rewardQuery("reward_sum", "Reward", "Sum"),
+			rewardQuery("reward_squared_sum", "RewardSquared", "Sum"),
+		},
+	}
+	err := config.AWS.CloudWatch().GetMetricDataPages(input, func(output *cloudwatch.GetMetricDataOutput, lastPage bool) bool {
+		for _, result := range output.MetricDataResults {
+			for _, value := range result.Values {
+				if value == nil {
+					continue
+				}
+				switch *result.Id {
+				case "reward_count":
+					stats.rewardCount += int64(*value)
+				case "reward_sum":
+					stats.rewardSum += *value
+				case "reward_squared_sum":
+					stats.rewardSquaredSum += *value
+				}
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// routeWeights returns the percentage of the virtual service's traffic which is routed to each service
+func routeWeights(virtualService *istioclientnetworking.VirtualService) map[string]int32 {
+	weights := map[string]int32{}
+	if virtualService == nil || apiHTTPRoute(virtualService) == nil {
+		return weights
+	}
+
+	routes := apiHTTPRoute(virtualService).Route
+	for _, route := range routes {
+		weight := route.Weight
+		if len(routes) == 1 {
+			weight = 100
+		}
+		weights[route.Destination.Host] = weight
+	}
+	return weights
+}
+
+// checkExperiments promotes the winners of experiments which have auto_promote enabled, and updates the weights of
+// experiments which have a bandit
+func checkExperiments() error {
+	if !isIstioNetworking() {
+		return nil
+	}
+
+	virtualServices, err := config.K8sAllNamspaces.ListVirtualServicesWithLabelKeys("apiName")
+	if err != nil {
+		return err
+	}
+
+	for i := range virtualServices {
+		virtualService := &virtualServices[i]
+		apiName := virtualService.Labels["apiName"]
+
+		experimentJSON, ok := virtualService.Annotations[userconfig.ExperimentAnnotationKey]
+		if !ok {
+			continue
+		}
+		var experiment userconfig.Experiment
+		if err := json.Unmarshal([]byte(experimentJSON), &experiment); err != nil || (!experiment.AutoPromote && experiment.Bandit == nil) {
+			continue
+		}
+
+		if httpRoute := apiHTTPRoute(virtualService); httpRoute == nil || len(httpRoute.Route) < 2 || isExperimentPromoted(virtualService) {
+			continue
+		}
+
+		if experiment.Bandit != nil && !experiment.AutoPromote && !isBanditUpdateDue(apiName, experiment.Bandit) {
+			continue
+		}
+
+		if err := checkExperiment(apiName, virtualService); err != nil {
+			errors.PrintError(err, "failed to check the experiment of "+apiName)
+		}
+	}
+
+	return nil
+}
+
+func checkExperiment(apiName string, virtualService *istioclientnetworking.VirtualService) error {
+	deployment, err := getAPIDeployment(apiName)
+	if err != nil || deployment == nil {
+		return err
+	}
+
+	api, err := DownloadAPISpec(apiName, deployment.Labels["apiID"])
+	if err != nil {
+		return err
+	}
+	if api.Experiment == nil {
+		return nil
+	}
+
+	results, stats, err := getExperimentResults(api, virtualService)
+	if err != nil {
+		return err
+	}
+
+	if api.Experiment.AutoPromote && results.Winner != nil {
+		exhausted, err := isErrorBudgetExhausted(api.Name, api.SLO)
+		if err != nil {
+			return err
+		}
+		// the experiment keeps running, so the winner is promoted once the error budget is restored
+		if !exhausted {
+			return promoteExperimentWinner(api, *results.Winner, virtualService)
+		}
+	}
+
+	if api.Experiment.Bandit != nil && isBanditUpdateDue(apiName, api.Experiment.Bandit) {
+		return updateBanditWeights(api, stats, virtualService)
+	}
+
+	return nil
+}
+
+// the promotion lasts until the virtual service is re-applied (e.g. when the API is updated), which also removes this annotation
+func isExperimentPromoted(virtualService *istioclientnetworking.VirtualService) bool {
+	_, ok := virtualService.Annotations[_experimentPromotedAnnotationKey]
+	return ok
+}
+
+func promoteExperimentWinner(api *spec.API, winner string, virtualService *istioclientnetworking.VirtualService) error {
+	winnerServiceName := k8sName(winner)
+	for _, route := range apiHTTPRoute(virtualService).Route {
+		if route.Destination.Host == winnerServiceName {
+			route.Weight = 100
+		} else {
+			route.Weight = 0
+		}
+	}
+	virtualService.Annotations[_experimentPromotedAnnotationKey] = winner
+
+	mirrorAdditionalRoutes(virtualService)
+	if _, err := config.K8sNamespace(virtualService.Namespace).UpdateVirtualService(virtualService, virtualService); err != nil {
+		return err
+	}
+
+	recordDeploymentEvent(api.Name, api, "promote "+winner)
+	notify(api.Name, api.ID, _experimentPromotedEvent, fmt.Sprintf("%s's traffic is now routed to %s, which performed better than %s on %s with %s%% confidence", api.Name, winner, api.Name, api.Experiment.Metric.String(), s.Round(api.Experiment.Confidence*100, 2, 0)))
+
+	return nil
+}
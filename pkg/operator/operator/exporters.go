@@ -0,0 +1,126 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/parallel"
+	"github.com/cortexlabs/cortex/pkg/lib/prometheus"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	kcore "k8s.io/api/core/v1"
+)
+
+const (
+	_exportersNamespace = "kube-system"
+	_exporterTimeout    = 5 * time.Second
+)
+
+var _exporterHTTPClient = &http.Client{Timeout: _exporterTimeout}
+
+// scrapeNodeExporters scrapes the metrics of the exporter daemonset's pods which run on the same nodes as the given pods
+func scrapeNodeExporters(exporterName string, port string, pods []kcore.Pod) ([]prometheus.Sample, error) {
+	nodeNames := strset.New()
+	for _, pod := range pods {
+		if pod.Spec.NodeName != "" {
+			nodeNames.Add(pod.Spec.NodeName)
+		}
+	}
+	if len(nodeNames) == 0 {
+		return nil, nil
+	}
+
+	exporterPods, err := config.K8sNamespace(_exportersNamespace).ListPodsByLabel("name", exporterName)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodeExporterPods []kcore.Pod
+	for _, exporterPod := range exporterPods {
+		if nodeNames.Has(exporterPod.Spec.NodeName) {
+			nodeExporterPods = append(nodeExporterPods, exporterPod)
+		}
+	}
+
+	var samples []prometheus.Sample
+	for _, podSamples := range scrapePods(nodeExporterPods, port) {
+		samples = append(samples, podSamples...)
+	}
+	return samples, nil
+}
+
+// scrapePods scrapes the metrics which each of the pods exports on the given port (keyed by pod name); pods which can't be
+// reached are skipped, since they (or their nodes) may be starting up or shutting down
+func scrapePods(pods []kcore.Pod, port string) map[string][]prometheus.Sample {
+	var samplesMutex sync.Mutex
+	samples := map[string][]prometheus.Sample{}
+	var fns []func() error
+
+	for i := range pods {
+		pod := pods[i]
+		if pod.Status.PodIP == "" {
+			continue
+		}
+
+		fns = append(fns, func() error {
+			podSamples, err := scrapeExporter("http://" + pod.Status.PodIP + ":" + port + "/metrics")
+			if err != nil {
+				return errors.Wrap(err, "failed to scrape "+pod.Name)
+			}
+			samplesMutex.Lock()
+			samples[pod.Name] = podSamples
+			samplesMutex.Unlock()
+			return nil
+		})
+	}
+
+	if len(fns) > 0 {
+		for _, err := range parallel.Run(fns[0], fns[1:]...) {
+			if err != nil {
+				telemetry.Error(err)
+				errors.PrintError(err)
+			}
+		}
+	}
+
+	return samples
+}
+
+func scrapeExporter(url string) ([]prometheus.Sample, error) {
+	response, err := _exporterHTTPClient.Get(url)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.ErrorUnexpected("received status code " + response.Status + " from " + url)
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return prometheus.ParseText(string(body))
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	libmath "github.com/cortexlabs/cortex/pkg/lib/math"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	istionetworking "istio.io/api/networking/v1alpha3"
+	istioclientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	kapps "k8s.io/api/apps/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+)
+
+// some of an overloaded API's traffic is always sent to the API, so that its demand can still be estimated
+const _maxOverflowWeight = int32(99)
+
+// routes traffic for APIs which have a fallback API configured to the fallback while they have no ready replicas (and the
+// share of their traffic which exceeds their capacity, if fallback_on_overload is enabled); the percentage of the traffic which
+// is sent to the fallback is recorded on the virtual service, so that the maintenance envoy filter can respond to the same
+// percentage with the fallback response of APIs which have one
+func updateFallbackRoutes() error {
+	if !isIstioNetworking() {
+		return nil
+	}
+
+	virtualServices, err := config.K8sAllNamspaces.ListVirtualServicesWithLabelKeys("apiName")
+	if err != nil {
+		return err
+	}
+
+	deployments, err := listAPIDeployments(klabels.Everything())
+	if err != nil {
+		return err
+	}
+
+	apiDeployments := make(map[string]*kapps.Deployment, len(deployments)) // apiName -> deployment
+	for i := range deployments {
+		apiDeployments[deployments[i].Labels["apiName"]] = &deployments[i]
+	}
+
+	var errs []error
+	for i := range virtualServices {
+		virtualService := &virtualServices[i]
+		apiName := virtualService.Labels["apiName"]
+
+		fallbackAPI, hasFallbackAPI := virtualService.Annotations[userconfig.FallbackAPIAnnotationKey]
+		_, hasFallbackResponse := virtualService.Annotations[userconfig.FallbackResponseAnnotationKey]
+		if !hasFallbackAPI && !hasFallbackResponse {
+			continue
+		}
+
+		httpRoute := apiHTTPRoute(virtualService)
+		if httpRoute == nil || len(httpRoute.Route) == 0 {
+			continue
+		}
+
+		weight, err := fallbackWeight(virtualService, apiDeployments[apiName])
+		if err != nil {
+			errs = append(errs, errors.Wrap(err, apiName))
+			continue
+		}
+
+		var updated bool
+		if hasFallbackAPI {
+			fallbackHost := k8sName(fallbackAPI)
+			fallbackDeployment, ok := apiDeployments[fallbackAPI]
+			if !ok || fallbackDeployment.Status.ReadyReplicas == 0 {
+				weight = 0
+			} else if fallbackDeployment.Namespace != virtualService.Namespace {
+				fallbackHost = fmt.Sprintf("%s.%s.svc.cluster.local", fallbackHost, fallbackDeployment.Namespace)
+			}
+			updated = setFallbackRoute(httpRoute, k8sName(apiName), fallbackHost, weight)
+		}
+
+		if currentFallbackWeight(virtualService) != weight {
+			virtualService.Annotations[userconfig.FallbackWeightAnnotationKey] = s.Int32(weight)
+			updated = true
+		}
+
+		if !updated {
+			continue
+		}
+
+		mirrorAdditionalRoutes(virtualService)
+		if _, err := config.K8sNamespace(virtualService.Namespace).UpdateVirtualService(virtualService, virtualService); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if errors.HasError(errs) {
+		return errors.FirstError(errs...)
+	}
+	return nil
+}
+
+// fallbackWeight returns the percentage of the API's traffic which should be sent to its fallback: all of it while the API has no
+// ready replicas, and the share which exceeds its ready replicas' concurrency limit if fallback_on_overload is enabled
+func fallbackWeight(virtualService *istioclientnetworking.VirtualService, deployment *kapps.Deployment) (int32, error) {
+	if deployment == nil || deployment.Status.ReadyReplicas == 0 {
+		return 100, nil
+	}
+
+	if virtualService.Annotations[userconfig.FallbackOnOverloadAnnotationKey] != "true" {
+		return 0, nil
+	}
+
+	autoscaling, err := userconfig.AutoscalingFromAnnotations(deployment)
+	if err != nil {
+		return 0, err
+	}
+
+	inFlight, err := getInflightRequests(deployment.Labels["apiName"], requestMonitorFlushInterval())
+	if err != nil {
+		return 0, err
+	}
+	if inFlight == nil {
+		return 0, nil
+	}
+
+	prevWeight := currentFallbackWeight(virtualService)
+	if prevWeight >= 100 {
+		prevWeight = 0 // the API had no ready replicas, so its in-flight requests don't reflect the split
+	}
+	capacity := float64(autoscaling.ReplicaConcurrencyLimit()) * float64(deployment.Status.ReadyReplicas)
+
+	return overflowWeight(*inFlight, prevWeight, capacity), nil
+}
+
+// currentFallbackWeight returns the percentage of the API's traffic which was sent to its fallback as of the last update
+func currentFallbackWeight(virtualService *istioclientnetworking.VirtualService) int32 {
+	weight, ok := s.ParseInt32(virtualService.Annotations[userconfig.FallbackWeightAnnotationKey])
+	if !ok {
+		return 0
+	}
+	return weight
+}
+
+// overflowWeight returns the percentage of an API's traffic which exceeds its capacity; the API's in-flight requests only include
+// the requests which weren't sent to its fallback (prevWeight percent of them were), so its demand is extrapolated from them
+func overflowWeight(inFlight float64, prevWeight int32, capacity float64) int32 {
+	demand := inFlight * 100 / float64(100-prevWeight)
+	if demand <= capacity {
+		return 0
+	}
+	weight := int32(math.Round((1 - capacity/demand) * 100))
+	return libmath.MinInt32(weight, _maxOverflowWeight)
+}
+
+// setFallbackRoute sends the percentage of the route's traffic to the fallback API, and returns true if the route was changed;
+// the API's destination is the route's first destination (an experiment's variants follow it, and aren't changed)
+func setFallbackRoute(httpRoute *istionetworking.HTTPRoute, apiHost string, fallbackHost string, weight int32) bool {
+	prevDestinations := routeDestinationsStr(httpRoute)
+
+	// the overflow destination is only added when the API doesn't have an experiment (see validateFallback)
+	if len(httpRoute.Route) == 2 && httpRoute.Route[1].Destination.Host == fallbackHost {
+		httpRoute.Route = httpRoute.Route[:1]
+		httpRoute.Route[0].Weight = 0
+	}
+
+	switch {
+	case weight >= 100:
+		httpRoute.Route[0].Destination.Host = fallbackHost
+	case weight <= 0:
+		httpRoute.Route[0].Destination.Host = apiHost
+	default:
+		overflowDestination := *httpRoute.Route[0].Destination
+		overflowDestination.Host = fallbackHost
+		httpRoute.Route[0].Destination.Host = apiHost
+		httpRoute.Route[0].Weight = 100 - weight
+		httpRoute.Route = append(httpRoute.Route, &istionetworking.HTTPRouteDestination{
+			Destination: &overflowDestination,
+			Weight:      weight,
+		})
+	}
+
+	return routeDestinationsStr(httpRoute) != prevDestinations
+}
+
+func routeDestinationsStr(httpRoute *istionetworking.HTTPRoute) string {
+	destinations := make([]string, len(httpRoute.Route))
+	for i, route := range httpRoute.Route {
+		destinations[i] = fmt.Sprintf("%s:%d", route.Destination.Host, route.Weight)
+	}
+	return strings.Join(destinations, ",")
+}
+
+// returns true if updateFallbackRoutes has routed all of the API's traffic to its fallback API
+func isRoutedToFallback(virtualService *istioclientnetworking.VirtualService) bool {
+	httpRoute := apiHTTPRoute(virtualService)
+	if httpRoute == nil || len(httpRoute.Route) == 0 {
+		return false
+	}
+	return httpRoute.Route[0].Destination.Host != k8sName(virtualService.Labels["apiName"])
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	istionetworking "istio.io/api/networking/v1alpha3"
+)
+
+func TestOverflowWeight(t *testing.T) {
+	require.Equal(t, int32(0), overflowWeight(8, 0, 10))
+	require.Equal(t, int32(50), overflowWeight(20, 0, 10))
+	// half of the traffic was already sent to the fallback, so the API's demand is 40
+	require.Equal(t, int32(75), overflowWeight(20, 50, 10))
+	require.Equal(t, int32(0), overflowWeight(5, 50, 10))
+	require.Equal(t, _maxOverflowWeight, overflowWeight(10000, 0, 1))
+}
+
+func TestSetFallbackRoute(t *testing.T) {
+	httpRoute := &istionetworking.HTTPRoute{
+		Route: []*istionetworking.HTTPRouteDestination{
+			{Destination: &istionetworking.Destination{Host: "api-a", Port: &istionetworking.PortSelector{Number: 8888}}},
+		},
+	}
+
+	require.False(t, setFallbackRoute(httpRoute, "api-a", "api-b", 0))
+
+	require.True(t, setFallbackRoute(httpRoute, "api-a", "api-b", 30))
+	require.Equal(t, "api-a:70,api-b:30", routeDestinationsStr(httpRoute))
+	require.Equal(t, uint32(8888), httpRoute.Route[1].Destination.Port.Number)
+	require.False(t, setFallbackRoute(httpRoute, "api-a", "api-b", 30))
+
+	require.True(t, setFallbackRoute(httpRoute, "api-a", "api-b", 100))
+	require.Equal(t, "api-b:0", routeDestinationsStr(httpRoute))
+
+	require.True(t, setFallbackRoute(httpRoute, "api-a", "api-b", 0))
+	require.Equal(t, "api-a:0", routeDestinationsStr(httpRoute))
+}
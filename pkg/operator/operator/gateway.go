@@ -18,19 +18,34 @@ package operator
 
 import (
 	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
 	"github.com/cortexlabs/cortex/pkg/lib/urls"
 	"github.com/cortexlabs/cortex/pkg/operator/config"
 	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
 	"github.com/cortexlabs/cortex/pkg/types/spec"
 	"github.com/cortexlabs/cortex/pkg/types/userconfig"
-	istioclientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
 )
 
-func addAPIToAPIGateway(endpoint string, apiGatewayType userconfig.APIGatewayType) error {
-	if apiGatewayType == userconfig.NoneAPIGatewayType {
+// lightweight clusters don't have an API Gateway, so their APIs are only served by the load balancer
+func usesAPIGateway(apiGatewayType userconfig.APIGatewayType) bool {
+	return apiGatewayType != userconfig.NoneAPIGatewayType && !config.Cluster.Lightweight
+}
+
+func addAPIToAPIGateway(endpoints []string, apiGatewayType userconfig.APIGatewayType) error {
+	if !usesAPIGateway(apiGatewayType) {
 		return nil
 	}
 
+	for _, endpoint := range endpoints {
+		if err := addEndpointToAPIGateway(endpoint); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func addEndpointToAPIGateway(endpoint string) error {
 	apiGatewayID := *config.Cluster.APIGateway.ApiId
 
 	// check if API Gateway route already exists
@@ -70,11 +85,21 @@ func addAPIToAPIGateway(endpoint string, apiGatewayType userconfig.APIGatewayTyp
 	return nil
 }
 
-func removeAPIFromAPIGateway(endpoint string, apiGatewayType userconfig.APIGatewayType) error {
-	if apiGatewayType == userconfig.NoneAPIGatewayType {
+func removeAPIFromAPIGateway(endpoints []string, apiGatewayType userconfig.APIGatewayType) error {
+	if !usesAPIGateway(apiGatewayType) {
 		return nil
 	}
 
+	for _, endpoint := range endpoints {
+		if err := removeEndpointFromAPIGateway(endpoint); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func removeEndpointFromAPIGateway(endpoint string) error {
 	apiGatewayID := *config.Cluster.APIGateway.ApiId
 
 	route, err := config.AWS.DeleteRoute(apiGatewayID, endpoint)
@@ -96,9 +121,9 @@ func removeAPIFromAPIGateway(endpoint string, apiGatewayType userconfig.APIGatew
 }
 
 func updateAPIGateway(
-	prevEndpoint string,
+	prevEndpoints []string,
 	prevAPIGatewayType userconfig.APIGatewayType,
-	newEndpoint string,
+	newEndpoints []string,
 	newAPIGatewayType userconfig.APIGatewayType,
 ) error {
 
@@ -107,56 +132,45 @@ func updateAPIGateway(
 	}
 
 	if prevAPIGatewayType == userconfig.PublicAPIGatewayType && newAPIGatewayType == userconfig.NoneAPIGatewayType {
-		return removeAPIFromAPIGateway(prevEndpoint, prevAPIGatewayType)
+		return removeAPIFromAPIGateway(prevEndpoints, prevAPIGatewayType)
 	}
 
 	if prevAPIGatewayType == userconfig.NoneAPIGatewayType && newAPIGatewayType == userconfig.PublicAPIGatewayType {
-		return addAPIToAPIGateway(newEndpoint, newAPIGatewayType)
+		return addAPIToAPIGateway(newEndpoints, newAPIGatewayType)
 	}
 
-	if prevEndpoint == newEndpoint {
-		return nil
-	}
+	// only the endpoints which have been added or removed need to be updated
+	prevEndpointSet := strset.New(prevEndpoints...)
+	newEndpointSet := strset.New(newEndpoints...)
 
-	// the endpoint has changed
-	if err := addAPIToAPIGateway(newEndpoint, newAPIGatewayType); err != nil {
+	if err := addAPIToAPIGateway(strset.Difference(newEndpointSet, prevEndpointSet).SliceSorted(), newAPIGatewayType); err != nil {
 		return err
 	}
-	if err := removeAPIFromAPIGateway(prevEndpoint, prevAPIGatewayType); err != nil {
+	if err := removeAPIFromAPIGateway(strset.Difference(prevEndpointSet, newEndpointSet).SliceSorted(), prevAPIGatewayType); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func removeAPIFromAPIGatewayK8s(virtualService *istioclientnetworking.VirtualService) error {
-	if virtualService == nil {
+func removeAPIFromAPIGatewayK8s(route *apiRoute) error {
+	if route == nil {
 		return nil // API is not running
 	}
 
-	apiGatewayType, err := userconfig.APIGatewayFromAnnotations(virtualService)
+	apiGatewayType, err := userconfig.APIGatewayFromAnnotations(route.object)
 	if err != nil {
 		return err
 	}
 
-	endpoint, err := GetEndpointFromVirtualService(virtualService)
-	if err != nil {
-		return err
-	}
-
-	return removeAPIFromAPIGateway(endpoint, apiGatewayType)
+	return removeAPIFromAPIGateway(route.endpoints, apiGatewayType)
 }
 
-func updateAPIGatewayK8s(prevVirtualService *istioclientnetworking.VirtualService, newAPI *spec.API) error {
-	prevAPIGatewayType, err := userconfig.APIGatewayFromAnnotations(prevVirtualService)
-	if err != nil {
-		return err
-	}
-
-	prevEndpoint, err := GetEndpointFromVirtualService(prevVirtualService)
+func updateAPIGatewayK8s(prevRoute *apiRoute, newAPI *spec.API) error {
+	prevAPIGatewayType, err := userconfig.APIGatewayFromAnnotations(prevRoute.object)
 	if err != nil {
 		return err
 	}
 
-	return updateAPIGateway(prevEndpoint, prevAPIGatewayType, *newAPI.Endpoint, newAPI.Networking.APIGateway)
+	return updateAPIGateway(prevRoute.endpoints, prevAPIGatewayType, newAPI.Endpoints(), newAPI.Networking.APIGateway)
 }
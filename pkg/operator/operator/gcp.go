@@ -0,0 +1,108 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	kcore "k8s.io/api/core/v1"
+)
+
+const (
+	// on gcp, cortex's pods run as this kubernetes service account, which Workload Identity binds to the cluster's google
+	// service account (see dev/gke.sh), rather than reading AWS credentials from the aws-credentials secret
+	_workloadServiceAccountName        = "cortex-workload"
+	_gcpServiceAccountAnnotationKey    = "iam.gke.io/gcp-service-account"
+	_defaultWorkloadServiceAccountName = "default"
+)
+
+func isGCP() bool {
+	return config.Cluster.Provider == types.GCPProviderType
+}
+
+// baseEnvFrom returns the environment of cortex's containers (the cluster's env vars, and its AWS credentials unless it's on gcp)
+func baseEnvFrom() []kcore.EnvFromSource {
+	if isGCP() {
+		return []kcore.EnvFromSource{configMapEnvSource("env-vars")}
+	}
+	return []kcore.EnvFromSource{
+		configMapEnvSource("env-vars"),
+		secretEnvSource("aws-credentials"),
+	}
+}
+
+// sharedSecrets returns the secrets which API pods depend on, which are copied into each namespace that APIs are deployed in
+func sharedSecrets() []string {
+	if isGCP() {
+		return nil
+	}
+	return []string{"aws-credentials"}
+}
+
+func workloadServiceAccountName() string {
+	if isGCP() {
+		return _workloadServiceAccountName
+	}
+	return _defaultWorkloadServiceAccountName
+}
+
+// ensureWorkloadServiceAccount creates the kubernetes service account which Workload Identity binds to the cluster's google
+// service account in the namespace (the google service account must allow the namespace's service account to impersonate it)
+func ensureWorkloadServiceAccount(namespace string) error {
+	if !isGCP() {
+		return nil
+	}
+
+	_, err := config.K8sNamespace(namespace).ApplyServiceAccount(k8s.ServiceAccount(&k8s.ServiceAccountSpec{
+		Name: _workloadServiceAccountName,
+		Annotations: map[string]string{
+			_gcpServiceAccountAnnotationKey: config.Cluster.GCP.ServiceAccount,
+		},
+	}))
+	return err
+}
+
+// validateGCPAPI rejects features which depend on AWS when the cluster is on gcp
+func validateGCPAPI(api *userconfig.API) error {
+	if !isGCP() {
+		return nil
+	}
+
+	// the model optimizer reads and writes models in S3
+	if api.Predictor.ModelOptimization != nil {
+		return errors.Wrap(ErrorUnsupportedOnGCP(userconfig.ModelOptimizationKey), userconfig.PredictorKey)
+	}
+
+	// dependency images are pushed to the cluster's container registry
+	if api.Predictor.PrebuildDependencies && config.Cluster.GCP.ImageRegistry == nil {
+		return errors.Wrap(ErrorGCPImageRegistryRequired(userconfig.PrebuildDependenciesKey), userconfig.PredictorKey)
+	}
+
+	return nil
+}
+
+// dependencyImageRepositoryURI returns the repository which dependency images are pushed to (in the cluster's Artifact
+// Registry or Container Registry repository on gcp, otherwise in ECR)
+func dependencyImageRepositoryURI() (string, error) {
+	if isGCP() {
+		return *config.Cluster.GCP.ImageRegistry + "/" + dependencyImageRepository(), nil
+	}
+	return config.AWS.EnsureECRRepository(dependencyImageRepository())
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/lib/prometheus"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/metrics"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+)
+
+const (
+	// the dcgm exporter runs on each gpu node (see manager/manifests/nvidia.yaml), and labels each gpu's metrics with the pod which it is allocated to
+	_dcgmExporterName = "dcgm-exporter"
+	_dcgmExporterPort = "9400"
+
+	_dcgmGPUUtilMetric = "DCGM_FI_DEV_GPU_UTIL" // percent
+	_dcgmFBUsedMetric  = "DCGM_FI_DEV_FB_USED"  // MiB
+	_dcgmFBFreeMetric  = "DCGM_FI_DEV_FB_FREE"  // MiB
+	_dcgmGPUTempMetric = "DCGM_FI_DEV_GPU_TEMP" // celsius
+)
+
+// getGPUStats returns the current utilization of the gpus which are allocated to each of the api's pods (keyed by pod name)
+func getGPUStats(api *spec.API) (map[string]*metrics.GPUStats, error) {
+	deployment, err := getAPIDeployment(api.Name)
+	if err != nil {
+		return nil, err
+	}
+	if deployment == nil {
+		return nil, nil
+	}
+
+	pods, err := config.K8sNamespace(deployment.Namespace).ListPodsByLabel("apiName", api.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	samples, err := scrapeNodeExporters(_dcgmExporterName, _dcgmExporterPort, pods)
+	if err != nil {
+		return nil, err
+	}
+
+	podNames := strset.New()
+	for _, pod := range pods {
+		podNames.Add(pod.Name)
+	}
+
+	return gpuStatsByPod(samples, deployment.Namespace, podNames), nil
+}
+
+// gpuStatsByPod joins the dcgm exporter's per-gpu samples to the pods which the gpus are allocated to
+func gpuStatsByPod(samples []prometheus.Sample, namespace string, podNames strset.Set) map[string]*metrics.GPUStats {
+	type gpuSamples struct {
+		util   *float64
+		fbUsed *float64
+		fbFree *float64
+		temp   *float64
+	}
+
+	gpusByPod := map[string]map[string]*gpuSamples{} // pod name -> gpu uuid -> samples
+	for _, sample := range samples {
+		podName := sample.Labels["pod"]
+		if !podNames.Has(podName) || sample.Labels["namespace"] != namespace {
+			continue
+		}
+
+		if gpusByPod[podName] == nil {
+			gpusByPod[podName] = map[string]*gpuSamples{}
+		}
+		gpuID := sample.Labels["UUID"]
+		if gpusByPod[podName][gpuID] == nil {
+			gpusByPod[podName][gpuID] = &gpuSamples{}
+		}
+		gpu := gpusByPod[podName][gpuID]
+
+		switch sample.Name {
+		case _dcgmGPUUtilMetric:
+			gpu.util = pointer.Float64(sample.Value)
+		case _dcgmFBUsedMetric:
+			gpu.fbUsed = pointer.Float64(sample.Value)
+		case _dcgmFBFreeMetric:
+			gpu.fbFree = pointer.Float64(sample.Value)
+		case _dcgmGPUTempMetric:
+			gpu.temp = pointer.Float64(sample.Value)
+		}
+	}
+
+	if len(gpusByPod) == 0 {
+		return nil
+	}
+
+	statsByPod := make(map[string]*metrics.GPUStats, len(gpusByPod))
+	for podName, gpus := range gpusByPod {
+		stats := metrics.GPUStats{GPUs: len(gpus)}
+		var utilSum, memoryUsed, memoryTotal float64
+		var utilCount, memoryUsedCount, memoryTotalCount int
+
+		for _, gpu := range gpus {
+			if gpu.util != nil {
+				utilSum += *gpu.util
+				utilCount++
+			}
+			if gpu.fbUsed != nil {
+				memoryUsed += *gpu.fbUsed
+				memoryUsedCount++
+				if gpu.fbFree != nil {
+					memoryTotal += *gpu.fbUsed + *gpu.fbFree
+					memoryTotalCount++
+				}
+			}
+			if gpu.temp != nil && (stats.Temperature == nil || *gpu.temp > *stats.Temperature) {
+				stats.Temperature = pointer.Float64(*gpu.temp)
+			}
+		}
+
+		if utilCount > 0 {
+			stats.Utilization = pointer.Float64(utilSum / float64(utilCount))
+		}
+		if memoryUsedCount > 0 {
+			stats.MemoryUsed = pointer.Float64(memoryUsed)
+		}
+		if memoryTotalCount > 0 {
+			stats.MemoryTotal = pointer.Float64(memoryTotal)
+		}
+		statsByPod[podName] = &stats
+	}
+
+	return statsByPod
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"testing"
+
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/lib/prometheus"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/types/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGPUStatsByPod(t *testing.T) {
+	gpuSample := func(name string, uuid string, pod string, namespace string, value float64) prometheus.Sample {
+		return prometheus.Sample{
+			Name:   name,
+			Labels: map[string]string{"UUID": uuid, "pod": pod, "namespace": namespace},
+			Value:  value,
+		}
+	}
+
+	samples := []prometheus.Sample{
+		gpuSample(_dcgmGPUUtilMetric, "GPU-0", "api-a", "default", 80),
+		gpuSample(_dcgmFBUsedMetric, "GPU-0", "api-a", "default", 1000),
+		gpuSample(_dcgmFBFreeMetric, "GPU-0", "api-a", "default", 15000),
+		gpuSample(_dcgmGPUTempMetric, "GPU-0", "api-a", "default", 60),
+		gpuSample(_dcgmGPUUtilMetric, "GPU-1", "api-a", "default", 40),
+		gpuSample(_dcgmFBUsedMetric, "GPU-1", "api-a", "default", 3000),
+		gpuSample(_dcgmFBFreeMetric, "GPU-1", "api-a", "default", 13000),
+		gpuSample(_dcgmGPUTempMetric, "GPU-1", "api-a", "default", 70),
+		gpuSample(_dcgmGPUUtilMetric, "GPU-2", "api-b", "default", 0),
+		gpuSample(_dcgmGPUUtilMetric, "GPU-3", "other-api", "default", 100),
+		gpuSample(_dcgmGPUUtilMetric, "GPU-4", "api-a", "other-namespace", 100),
+		gpuSample(_dcgmGPUUtilMetric, "GPU-5", "", "", 100),
+	}
+
+	require.Equal(t, map[string]*metrics.GPUStats{
+		"api-a": {
+			GPUs:        2,
+			Utilization: pointer.Float64(60),
+			MemoryUsed:  pointer.Float64(4000),
+			MemoryTotal: pointer.Float64(32000),
+			Temperature: pointer.Float64(70),
+		},
+		"api-b": {
+			GPUs:        1,
+			Utilization: pointer.Float64(0),
+		},
+	}, gpuStatsByPod(samples, "default", strset.New("api-a", "api-b")))
+
+	require.Nil(t, gpuStatsByPod(samples, "default", strset.New("api-c")))
+}
@@ -0,0 +1,310 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/status"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	kapps "k8s.io/api/apps/v1"
+	kcore "k8s.io/api/core/v1"
+)
+
+// The leader detects patterns in the health of APIs' replicas which are hard to spot in individual pod events, reports
+// them as notifications when they start, and keeps the current warnings in a config map (apiName -> json-encoded
+// warnings) so that every operator replica can include them in the API's status.
+
+const (
+	_healthConfigMapName = "cortex-api-health"
+
+	_oomKillTrendWindow  = time.Hour // the warning's message assumes an hour
+	_minOOMKillsForTrend = 3
+
+	_readinessFlapWindow = 10 * time.Minute // the warning's message assumes 10 minutes
+	_minReadinessFlaps   = 3
+
+	_crashLoopingVersionWarning = "crash_looping_version"
+	_oomKillsIncreasingWarning  = "oom_kills_increasing"
+	_readinessFlappingWarning   = "readiness_flapping"
+)
+
+var _apiHealth = struct {
+	sync.Mutex
+	oomKills         map[string][]time.Time            // apiName -> times at which replicas were OOMKilled (within the last two trend windows)
+	podReadiness     map[string]bool                   // pod name -> whether the pod was ready when it was last checked
+	readinessFlaps   map[string][]time.Time            // pod name -> times at which the pod became unready (within the flap window)
+	warnings         map[string][]status.HealthWarning // apiName -> the API's current warnings
+	warningsRecorded bool                              // whether the warnings have been written to the config map since the operator started
+}{
+	oomKills:       map[string][]time.Time{},
+	podReadiness:   map[string]bool{},
+	readinessFlaps: map[string][]time.Time{},
+	warnings:       map[string][]status.HealthWarning{},
+}
+
+// recordOOMKill is called once for each container which is OOMKilled (see checkContainerFailures)
+func recordOOMKill(apiName string, finishedAt time.Time) {
+	_apiHealth.Lock()
+	defer _apiHealth.Unlock()
+	_apiHealth.oomKills[apiName] = append(_apiHealth.oomKills[apiName], finishedAt)
+}
+
+// checkAPIHealth returns the API's current warnings, and updates the readiness history of its pods
+func checkAPIHealth(deployment *kapps.Deployment, pods []kcore.Pod) []status.HealthWarning {
+	apiName := deployment.Labels["apiName"]
+	now := time.Now()
+
+	var warnings []status.HealthWarning
+	if warning := crashLoopingVersionWarning(deployment, pods); warning != nil {
+		warnings = append(warnings, *warning)
+	}
+
+	_apiHealth.Lock()
+	defer _apiHealth.Unlock()
+
+	if warning := oomKillsIncreasingWarning(apiName, now); warning != nil {
+		warnings = append(warnings, *warning)
+	}
+	if warning := readinessFlappingWarning(pods, now); warning != nil {
+		warnings = append(warnings, *warning)
+	}
+
+	// warnings which are still present keep the time at which they were first detected
+	for i := range warnings {
+		for _, prevWarning := range _apiHealth.warnings[apiName] {
+			if prevWarning.Type == warnings[i].Type {
+				warnings[i].Since = prevWarning.Since
+			}
+		}
+	}
+
+	return warnings
+}
+
+// crash loops which only affect one version of the API, while another version is healthy, were most likely caused by that version
+func crashLoopingVersionWarning(deployment *kapps.Deployment, pods []kcore.Pod) *status.HealthWarning {
+	crashLoopingAPIIDs := strset.New()
+	readyAPIIDs := strset.New()
+	numCrashLooping := 0
+
+	for i := range pods {
+		pod := &pods[i]
+		if k8s.IsPodReady(pod) {
+			readyAPIIDs.Add(pod.Labels["apiID"])
+			continue
+		}
+		for _, containerStatus := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+			if waiting := containerStatus.State.Waiting; waiting != nil && waiting.Reason == "CrashLoopBackOff" {
+				crashLoopingAPIIDs.Add(pod.Labels["apiID"])
+				numCrashLooping++
+				break
+			}
+		}
+	}
+
+	if len(crashLoopingAPIIDs) != 1 {
+		return nil
+	}
+	crashLoopingAPIID := crashLoopingAPIIDs.GetOne()
+	readyAPIIDs.Remove(crashLoopingAPIID)
+	if len(readyAPIIDs) == 0 {
+		return nil
+	}
+
+	apiName := deployment.Labels["apiName"]
+	message := fmt.Sprintf("%d %s of version %s %s crash looping, while replicas of version %s are ready", numCrashLooping, s.PluralS("replica", numCrashLooping), crashLoopingAPIID, s.PluralCustom("is", "are", numCrashLooping), s.StrsAnd(readyAPIIDs.SliceSorted()))
+	if crashLoopingAPIID == deployment.Labels["apiID"] {
+		message += fmt.Sprintf("; the latest update is the likely cause (run `cortex logs %s` to see the error, and redeploy the previous version if needed)", apiName)
+	} else {
+		message += "; the crash looping replicas will be replaced by the latest version"
+	}
+
+	return &status.HealthWarning{
+		Type:    _crashLoopingVersionWarning,
+		Message: message,
+		Since:   time.Now(),
+	}
+}
+
+// OOMKills are increasing if there were at least _minOOMKillsForTrend in the last trend window, and more than in the window before it
+func oomKillsIncreasingWarning(apiName string, now time.Time) *status.HealthWarning {
+	var recentKills, prevKills int
+	var kills []time.Time
+	for _, killTime := range _apiHealth.oomKills[apiName] {
+		age := now.Sub(killTime)
+		if age < _oomKillTrendWindow {
+			recentKills++
+		} else if age < 2*_oomKillTrendWindow {
+			prevKills++
+		} else {
+			continue
+		}
+		kills = append(kills, killTime)
+	}
+
+	if len(kills) == 0 {
+		delete(_apiHealth.oomKills, apiName)
+	} else {
+		_apiHealth.oomKills[apiName] = kills
+	}
+
+	if recentKills < _minOOMKillsForTrend || recentKills <= prevKills {
+		return nil
+	}
+
+	return &status.HealthWarning{
+		Type:    _oomKillsIncreasingWarning,
+		Message: fmt.Sprintf("replicas ran out of memory %d times in the last hour (%d %s in the hour before); consider increasing %s", recentKills, prevKills, s.PluralS("time", prevKills), userconfig.ComputeKey+"."+userconfig.MemKey),
+		Since:   now,
+	}
+}
+
+// a pod's readiness is flapping if it became unready at least _minReadinessFlaps times within the flap window (without
+// its containers crashing, which is reported separately)
+func readinessFlappingWarning(pods []kcore.Pod, now time.Time) *status.HealthWarning {
+	var flappingPods []string
+	maxFlaps := 0
+
+	for i := range pods {
+		pod := &pods[i]
+		if k8s.GetPodStatus(pod) != k8s.PodStatusRunning {
+			continue
+		}
+
+		isReady := k8s.IsPodReady(pod)
+		wasReady, ok := _apiHealth.podReadiness[pod.Name]
+		_apiHealth.podReadiness[pod.Name] = isReady
+
+		var flaps []time.Time
+		for _, flapTime := range _apiHealth.readinessFlaps[pod.Name] {
+			if now.Sub(flapTime) < _readinessFlapWindow {
+				flaps = append(flaps, flapTime)
+			}
+		}
+		if ok && wasReady && !isReady {
+			flaps = append(flaps, now)
+		}
+		_apiHealth.readinessFlaps[pod.Name] = flaps
+
+		if len(flaps) >= _minReadinessFlaps {
+			flappingPods = append(flappingPods, pod.Name)
+			if len(flaps) > maxFlaps {
+				maxFlaps = len(flaps)
+			}
+		}
+	}
+
+	if len(flappingPods) == 0 {
+		return nil
+	}
+	sort.Strings(flappingPods)
+
+	return &status.HealthWarning{
+		Type:    _readinessFlappingWarning,
+		Message: fmt.Sprintf("%s repeatedly became unready (up to %d times in the last 10 minutes), which usually means that the readiness check times out under load; consider increasing the api's %s, or lowering its %s", s.StrsAnd(flappingPods), maxFlaps, userconfig.ComputeKey, userconfig.TargetReplicaConcurrencyKey),
+		Since:   now,
+	}
+}
+
+// updateHealthWarnings notifies the APIs' notification targets of new warnings, and records the current warnings (which
+// are keyed by API name) in the config map if they've changed
+func updateHealthWarnings(warnings map[string][]status.HealthWarning, apiIDs map[string]string, podNames strset.Set) error {
+	_apiHealth.Lock()
+	prevWarnings := _apiHealth.warnings
+	_apiHealth.warnings = warnings
+	warningsRecorded := _apiHealth.warningsRecorded
+
+	// forget pods which no longer exist
+	for podName := range _apiHealth.podReadiness {
+		if !podNames.Has(podName) {
+			delete(_apiHealth.podReadiness, podName)
+			delete(_apiHealth.readinessFlaps, podName)
+		}
+	}
+	_apiHealth.Unlock()
+
+	changed := len(warnings) != len(prevWarnings)
+	for apiName, apiWarnings := range warnings {
+		prevTypes := strset.New()
+		for _, warning := range prevWarnings[apiName] {
+			prevTypes.Add(warning.Type)
+		}
+		if len(apiWarnings) != len(prevWarnings[apiName]) {
+			changed = true
+		}
+		for _, warning := range apiWarnings {
+			if !prevTypes.Has(warning.Type) {
+				changed = true
+				notify(apiName, apiIDs[apiName], warning.Type, apiName+": "+warning.Message)
+			}
+		}
+	}
+
+	if !changed && warningsRecorded {
+		return nil
+	}
+
+	data := make(map[string]string, len(warnings))
+	for apiName, apiWarnings := range warnings {
+		warningsJSON, err := json.Marshal(apiWarnings)
+		if err != nil {
+			return err
+		}
+		data[apiName] = string(warningsJSON)
+	}
+
+	if _, err := config.K8s.ApplyConfigMap(k8s.ConfigMap(&k8s.ConfigMapSpec{
+		Name: _healthConfigMapName,
+		Data: data,
+	})); err != nil {
+		return err
+	}
+
+	_apiHealth.Lock()
+	_apiHealth.warningsRecorded = true
+	_apiHealth.Unlock()
+
+	return nil
+}
+
+// getHealthWarnings returns the API's warnings which were last recorded by the leader
+func getHealthWarnings(apiName string) ([]status.HealthWarning, error) {
+	data, err := config.K8s.GetConfigMapData(_healthConfigMapName)
+	if err != nil {
+		return nil, err
+	}
+
+	warningsJSON, ok := data[apiName]
+	if !ok {
+		return nil, nil
+	}
+
+	var warnings []status.HealthWarning
+	if err := json.Unmarshal([]byte(warningsJSON), &warnings); err != nil {
+		return nil, err
+	}
+	return warnings, nil
+}
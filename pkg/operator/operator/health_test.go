@@ -0,0 +1,100 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	kapps "k8s.io/api/apps/v1"
+	kcore "k8s.io/api/core/v1"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func healthTestPod(name string, apiID string, ready bool, crashLooping bool) kcore.Pod {
+	pod := kcore.Pod{
+		ObjectMeta: kmeta.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{"apiName": "my-api", "apiID": apiID},
+		},
+		Status: kcore.PodStatus{
+			Phase: kcore.PodRunning,
+			Conditions: []kcore.PodCondition{
+				{Type: kcore.PodReady, Status: kcore.ConditionFalse},
+			},
+			ContainerStatuses: []kcore.ContainerStatus{
+				{Name: "api", State: kcore.ContainerState{Running: &kcore.ContainerStateRunning{}}},
+			},
+		},
+	}
+	if ready {
+		pod.Status.Conditions[0].Status = kcore.ConditionTrue
+	}
+	if crashLooping {
+		pod.Status.ContainerStatuses[0].State = kcore.ContainerState{Waiting: &kcore.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}
+	}
+	return pod
+}
+
+func TestCrashLoopingVersionWarning(t *testing.T) {
+	deployment := &kapps.Deployment{
+		ObjectMeta: kmeta.ObjectMeta{
+			Labels: map[string]string{"apiName": "my-api", "apiID": "v2"},
+		},
+	}
+
+	warning := crashLoopingVersionWarning(deployment, []kcore.Pod{
+		healthTestPod("a", "v1", true, false),
+		healthTestPod("b", "v2", false, true),
+		healthTestPod("c", "v2", false, true),
+	})
+	require.NotNil(t, warning)
+	require.Equal(t, _crashLoopingVersionWarning, warning.Type)
+	require.Contains(t, warning.Message, "2 replicas of version v2 are crash looping")
+
+	// both versions are crash looping
+	require.Nil(t, crashLoopingVersionWarning(deployment, []kcore.Pod{
+		healthTestPod("a", "v1", false, true),
+		healthTestPod("b", "v2", false, true),
+		healthTestPod("c", "v2", true, false),
+	}))
+
+	// no other version is ready
+	require.Nil(t, crashLoopingVersionWarning(deployment, []kcore.Pod{
+		healthTestPod("b", "v2", false, true),
+		healthTestPod("c", "v2", true, false),
+	}))
+}
+
+func TestOOMKillsIncreasingWarning(t *testing.T) {
+	now := time.Now()
+
+	_apiHealth.Lock()
+	defer _apiHealth.Unlock()
+
+	_apiHealth.oomKills["my-api"] = []time.Time{now.Add(-3 * time.Hour), now.Add(-90 * time.Minute), now.Add(-30 * time.Minute), now.Add(-20 * time.Minute), now.Add(-10 * time.Minute)}
+	warning := oomKillsIncreasingWarning("my-api", now)
+	require.NotNil(t, warning)
+	require.Contains(t, warning.Message, "3 times in the last hour (1 time in the hour before)")
+	require.Len(t, _apiHealth.oomKills["my-api"], 4)
+
+	_apiHealth.oomKills["my-api"] = []time.Time{now.Add(-100 * time.Minute), now.Add(-90 * time.Minute), now.Add(-80 * time.Minute), now.Add(-30 * time.Minute), now.Add(-20 * time.Minute), now.Add(-10 * time.Minute)}
+	require.Nil(t, oomKillsIncreasingWarning("my-api", now))
+
+	delete(_apiHealth.oomKills, "my-api")
+}
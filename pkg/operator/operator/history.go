@@ -0,0 +1,66 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+)
+
+const _deploymentHistoryKind = "deployments"
+
+// recordDeploymentEvent is best effort; failures are printed but don't affect the deployment
+func recordDeploymentEvent(apiName string, api *spec.API, action string) {
+	now := time.Now()
+
+	event := schema.DeploymentEvent{
+		APIName:   apiName,
+		Action:    action,
+		Timestamp: now.Unix(),
+	}
+	if api != nil {
+		event.APIID = api.ID
+		event.DeploymentID = api.DeploymentID
+	}
+
+	// zero-padded so that keys sort chronologically
+	key := fmt.Sprintf("%s/%020d", apiName, now.UnixNano())
+	if err := config.Metadata.Put(_deploymentHistoryKind, key, event); err != nil {
+		errors.PrintError(err, "failed to record deployment history")
+	}
+}
+
+func GetDeploymentHistory(apiName string) ([]schema.DeploymentEvent, error) {
+	items, err := config.Metadata.List(_deploymentHistoryKind, apiName+"/")
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]schema.DeploymentEvent, len(items))
+	for i, item := range items {
+		if err := item.Unmarshal(&events[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return events, nil
+}
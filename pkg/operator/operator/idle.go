@@ -0,0 +1,243 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	kapps "k8s.io/api/apps/v1"
+)
+
+const (
+	_idleCheckPeriod     = 5 * time.Minute
+	_pausedAnnotationKey = "cortex.dev/paused" // the time at which the API was paused (RFC3339)
+)
+
+// the time of the most recent request to each API is tracked in memory; APIs which the operator hasn't
+// observed yet (e.g. because it restarted) are considered active as of the first check
+var _apiActivity = struct {
+	sync.Mutex
+	lastRequests map[string]time.Time // apiName -> time
+}{
+	lastRequests: map[string]time.Time{},
+}
+
+func recordAPIActivity(apiName string) {
+	_apiActivity.Lock()
+	defer _apiActivity.Unlock()
+	_apiActivity.lastRequests[apiName] = time.Now()
+}
+
+func deleteAPIActivity(apiName string) {
+	_apiActivity.Lock()
+	defer _apiActivity.Unlock()
+	delete(_apiActivity.lastRequests, apiName)
+}
+
+func isAPIPaused(deployment *kapps.Deployment) bool {
+	_, ok := deployment.Annotations[_pausedAnnotationKey]
+	return ok
+}
+
+// pauseIdleAPIs pauses APIs which have an idle_timeout and haven't received requests within it
+func pauseIdleAPIs() error {
+	deployments, err := config.K8sAllNamspaces.ListDeploymentsWithLabelKeys("apiName")
+	if err != nil {
+		return err
+	}
+
+	for i := range deployments {
+		deployment := &deployments[i]
+		apiName := deployment.Labels["apiName"]
+
+		autoscalingSpec, err := userconfig.AutoscalingFromAnnotations(deployment)
+		if err != nil {
+			return err
+		}
+		if autoscalingSpec.IdleTimeout == nil || isAPIPaused(deployment) {
+			continue
+		}
+
+		requestCount, err := getRequestCount(apiName, 2*_idleCheckPeriod)
+		if err != nil {
+			return err
+		}
+
+		_apiActivity.Lock()
+		lastRequest, ok := _apiActivity.lastRequests[apiName]
+		if !ok || requestCount > 0 {
+			lastRequest = time.Now()
+			_apiActivity.lastRequests[apiName] = lastRequest
+		}
+		_apiActivity.Unlock()
+
+		if time.Since(lastRequest) < *autoscalingSpec.IdleTimeout {
+			continue
+		}
+
+		log.Printf("%s has not received requests in %s, pausing it", apiName, autoscalingSpec.IdleTimeout.String())
+		err = pauseDeployment(deployment)
+		RecordAuditEvent(schema.AuditEvent{
+			Caller:  _operatorAuditCaller,
+			Action:  "pause",
+			APIName: apiName,
+			Message: fmt.Sprintf("%s has not received requests in %s (its idle_timeout)", apiName, autoscalingSpec.IdleTimeout.String()),
+		}, err)
+		if err != nil {
+			errors.PrintError(err, "failed to pause "+apiName)
+		}
+	}
+
+	return nil
+}
+
+// PauseAPI scales the API to 0 replicas (its spec and networking resources are kept) until it is resumed or re-deployed
+func PauseAPI(apiName string) (string, error) {
+	deployment, err := getAPIDeployment(apiName)
+	if err != nil {
+		return "", err
+	} else if deployment == nil {
+		return "", ErrorAPINotDeployed(apiName)
+	}
+
+	if isAPIPaused(deployment) {
+		return fmt.Sprintf("%s is already paused", apiName), nil
+	}
+
+	if err := pauseDeployment(deployment); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("paused %s", apiName), nil
+}
+
+// ResumeAPI scales a paused API back to its min_replicas (after which the autoscaler takes over)
+func ResumeAPI(apiName string) (string, error) {
+	deployment, err := getAPIDeployment(apiName)
+	if err != nil {
+		return "", err
+	} else if deployment == nil {
+		return "", ErrorAPINotDeployed(apiName)
+	}
+
+	if !isAPIPaused(deployment) {
+		return fmt.Sprintf("%s is not paused", apiName), nil
+	}
+
+	if err := resumeDeployment(deployment); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("resuming %s", apiName), nil
+}
+
+func pauseDeployment(deployment *kapps.Deployment) error {
+	apiName := deployment.Labels["apiName"]
+
+	cancelAutoscalerCron(apiName)
+
+	if deployment.Annotations == nil {
+		deployment.Annotations = map[string]string{}
+	}
+	deployment.Annotations[_pausedAnnotationKey] = time.Now().UTC().Format(time.RFC3339)
+	deployment.Spec.Replicas = pointer.Int32(0)
+
+	if _, err := config.K8sNamespace(deployment.Namespace).UpdateDeployment(deployment); err != nil {
+		return err
+	}
+
+	return updateMaintenanceEnvoyFilter()
+}
+
+func resumeDeployment(deployment *kapps.Deployment) error {
+	autoscalingSpec, err := userconfig.AutoscalingFromAnnotations(deployment)
+	if err != nil {
+		return err
+	}
+
+	delete(deployment.Annotations, _pausedAnnotationKey)
+	deployment.Spec.Replicas = pointer.Int32(autoscalingSpec.MinReplicas)
+
+	updatedDeployment, err := config.K8sNamespace(deployment.Namespace).UpdateDeployment(deployment)
+	if err != nil {
+		return err
+	}
+
+	recordAPIActivity(deployment.Labels["apiName"])
+	prescaleInBackground(updatedDeployment)
+
+	return updateAutoscalerCron(updatedDeployment)
+}
+
+// getRequestCount returns the number of requests which the API received during the period
+func getRequestCount(apiName string, period time.Duration) (float64, error) {
+	endTime := time.Now().Truncate(time.Minute)
+	startTime := endTime.Add(-period)
+
+	metricsDataQuery := cloudwatch.GetMetricDataInput{
+		EndTime:   &endTime,
+		StartTime: &startTime,
+		MetricDataQueries: []*cloudwatch.MetricDataQuery{
+			{
+				Id:    aws.String("request_count"),
+				Label: aws.String("RequestCount"),
+				MetricStat: &cloudwatch.MetricStat{
+					Metric: &cloudwatch.Metric{
+						Namespace:  aws.String(config.Cluster.ClusterName),
+						MetricName: aws.String("Latency"),
+						Dimensions: []*cloudwatch.Dimension{
+							{
+								Name:  aws.String("APIName"),
+								Value: aws.String(apiName),
+							},
+							{
+								Name:  aws.String("metric_type"),
+								Value: aws.String("histogram"),
+							},
+						},
+					},
+					Stat:   aws.String("SampleCount"),
+					Period: aws.Int64(int64(period.Seconds())),
+				},
+			},
+		},
+	}
+
+	output, err := config.AWS.CloudWatch().GetMetricData(&metricsDataQuery)
+	if err != nil {
+		return 0, err
+	}
+
+	var requestCount float64
+	for _, result := range output.MetricDataResults {
+		for _, val := range result.Values {
+			requestCount += *val
+		}
+	}
+
+	return requestCount, nil
+}
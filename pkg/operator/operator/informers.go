@@ -0,0 +1,168 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	kapps "k8s.io/api/apps/v1"
+	kcore "k8s.io/api/core/v1"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+	kappslisters "k8s.io/client-go/listers/apps/v1"
+	kcorelisters "k8s.io/client-go/listers/core/v1"
+	kcache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// The APIs' deployments and pods are watched (rather than listed from the API server each time they're needed), so
+// statuses are read from an in-memory cache, and changes to an API's resources are handled as they happen. The cache
+// is only used to read the state of APIs: deploys and other updates read from the API server, so that they don't act on
+// a stale copy.
+
+const (
+	_informerResyncPeriod  = 10 * time.Minute
+	_informerSyncTimeout   = 5 * time.Minute
+	_apiEventWorkerThreads = 4
+)
+
+var (
+	_deploymentLister kappslisters.DeploymentLister
+	_podLister        kcorelisters.PodLister
+
+	// the names of the APIs whose deployments or pods have changed; an API is never handled by multiple workers at once
+	_apiEvents = workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "apis")
+)
+
+func initInformers() error {
+	factory := config.K8sAllNamspaces.NewInformerFactory(_informerResyncPeriod, "apiName")
+
+	deploymentInformer := factory.Apps().V1().Deployments()
+	podInformer := factory.Core().V1().Pods()
+
+	eventHandler := kcache.ResourceEventHandlerFuncs{
+		AddFunc:    enqueueAPIEvent,
+		UpdateFunc: func(_, obj interface{}) { enqueueAPIEvent(obj) },
+		DeleteFunc: enqueueAPIEvent,
+	}
+	deploymentInformer.Informer().AddEventHandler(eventHandler)
+	podInformer.Informer().AddEventHandler(eventHandler)
+
+	_deploymentLister = deploymentInformer.Lister()
+	_podLister = podInformer.Lister()
+
+	factory.Start(nil) // the informers run for the lifetime of the operator
+
+	return k8s.WaitForInformerCacheSync(_informerSyncTimeout, deploymentInformer.Informer(), podInformer.Informer())
+}
+
+// the API events are only handled by the leader (see startLeaderTasks), but are queued by all replicas
+func startAPIEventWorkers() {
+	for i := 0; i < _apiEventWorkerThreads; i++ {
+		go runAPIEventWorker()
+	}
+}
+
+func enqueueAPIEvent(obj interface{}) {
+	if tombstone, ok := obj.(kcache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+
+	object, ok := obj.(kmeta.Object)
+	if !ok {
+		return
+	}
+
+	if apiName := object.GetLabels()["apiName"]; apiName != "" {
+		_apiEvents.Add(apiName)
+	}
+}
+
+func runAPIEventWorker() {
+	for {
+		item, shutdown := _apiEvents.Get()
+		if shutdown {
+			return
+		}
+
+		apiName := item.(string)
+		if err := handleAPIEvent(apiName); err != nil {
+			errors.PrintError(err, "failed to handle the changes to "+apiName)
+			_apiEvents.AddRateLimited(item)
+		} else {
+			_apiEvents.Forget(item)
+		}
+
+		_apiEvents.Done(item)
+	}
+}
+
+// handleAPIEvent is called after an API's deployment or pods have changed
+func handleAPIEvent(apiName string) error {
+	if err := syncParallelGroups(apiName); err != nil {
+		return err
+	}
+	return checkRolloutReplicas(apiName)
+}
+
+// listAPIDeployments returns the (cached) deployments of the APIs whose labels match the selector
+func listAPIDeployments(selector klabels.Selector) ([]kapps.Deployment, error) {
+	cachedDeployments, err := _deploymentLister.List(selector)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// the cached objects are shared, so they are copied in case the caller modifies them
+	deployments := make([]kapps.Deployment, len(cachedDeployments))
+	for i, deployment := range cachedDeployments {
+		deployments[i] = *deployment.DeepCopy()
+	}
+	return deployments, nil
+}
+
+// listAPIPods returns the (cached) pods of the APIs whose labels match the selector
+func listAPIPods(selector klabels.Selector) ([]kcore.Pod, error) {
+	cachedPods, err := _podLister.List(selector)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	pods := make([]kcore.Pod, len(cachedPods))
+	for i, pod := range cachedPods {
+		pods[i] = *pod.DeepCopy()
+	}
+	return pods, nil
+}
+
+// getCachedAPIDeployment returns nil if the API isn't deployed
+func getCachedAPIDeployment(apiName string) (*kapps.Deployment, error) {
+	deployments, err := listAPIDeployments(apiSelector(apiName))
+	if err != nil {
+		return nil, err
+	}
+	if len(deployments) == 0 {
+		return nil, nil
+	}
+	return &deployments[0], nil
+}
+
+func apiSelector(apiName string) klabels.Selector {
+	return klabels.SelectorFromSet(klabels.Set{"apiName": apiName})
+}
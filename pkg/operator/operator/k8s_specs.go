@@ -17,25 +17,36 @@ limitations under the License.
 package operator
 
 import (
-	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"math"
 	"path"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/cortexlabs/cortex/pkg/consts"
 	"github.com/cortexlabs/cortex/pkg/lib/aws"
-	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/docker"
+	"github.com/cortexlabs/cortex/pkg/lib/hash"
 	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/maps"
+	libmath "github.com/cortexlabs/cortex/pkg/lib/math"
 	"github.com/cortexlabs/cortex/pkg/lib/pointer"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/urls"
 	"github.com/cortexlabs/cortex/pkg/operator/config"
-	"github.com/cortexlabs/cortex/pkg/types"
+	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
 	"github.com/cortexlabs/cortex/pkg/types/spec"
 	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	gogotypes "github.com/gogo/protobuf/types"
+	istionetworking "istio.io/api/networking/v1alpha3"
+	istioclientauthentication "istio.io/client-go/pkg/apis/authentication/v1alpha1"
 	istioclientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	istioclientsecurity "istio.io/client-go/pkg/apis/security/v1beta1"
 	kapps "k8s.io/api/apps/v1"
 	kcore "k8s.io/api/core/v1"
+	kextensions "k8s.io/api/extensions/v1beta1"
 	kresource "k8s.io/apimachinery/pkg/api/resource"
 	intstr "k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -44,29 +55,62 @@ const (
 	_specCacheDir                                  = "/mnt/spec"
 	_emptyDirMountPath                             = "/mnt"
 	_emptyDirVolumeName                            = "mnt"
+	_shmVolumeName                                 = "dshm"
+	_shmMountPath                                  = "/dev/shm"
 	_apiContainerName                              = "api"
 	_tfServingContainerName                        = "serve"
+	_llmServerContainerName                        = "llm-server"
 	_tfServingModelName                            = "model"
 	_downloaderInitContainerName                   = "downloader"
+	_requestMonitorContainerName                   = "request-monitor"
+	_featureStoreCacheContainerName                = "feature-store-cache"
+	_explainerContainerName                        = "explainer"
 	_downloaderLastLog                             = "downloading the %s serving image"
-	_neuronRTDContainerName                        = "neuron-rtd"
 	_defaultPortInt32, _defaultPortStr             = int32(8888), "8888"
 	_tfBaseServingPortInt32, _tfBaseServingPortStr = int32(9000), "9000"
+	_llmServerPortInt32, _llmServerPortStr         = int32(9000), "9000"
+	_featureStoreCachePort                         = int32(6380)
+	_explainerPortInt32, _explainerPortStr         = int32(8889), "8889"
 	_tfServingHost                                 = "localhost"
 	_tfServingEmptyModelConfig                     = "/etc/tfs/model_config_server.conf"
+	_tfServingBatchingVolumeName                   = "tfs-batching"
+	_tfServingBatchingConfigDir                    = "/etc/tfs/batching"
+	_tfServingBatchingConfigKey                    = "batching_parameters.conf"
 	_requestMonitorReadinessFile                   = "/request_monitor_ready.txt"
+	_successMarkerFile                             = "_SUCCESS" // written to S3 once an artifact which the downloader awaits is ready (e.g. an optimized model)
+	_failureMarkerFile                             = "_FAILED"  // written to S3 (containing the error) if an artifact which the downloader awaits could not be created
 	_apiReadinessFile                              = "/mnt/workspace/api_readiness.txt"
 	_apiLivenessFile                               = "/mnt/workspace/api_liveness.txt"
-	_neuronRTDSocket                               = "/sock/neuron.sock"
-	_apiLivenessStalePeriod                        = 7 // seconds (there is a 2-second buffer to be safe)
+	_compressionEnvoyFilterName                    = "apis-compression"
+	_maintenanceEnvoyFilterName                    = "apis-maintenance"
+	_apisGatewayPrincipal                          = "cluster.local/ns/istio-system/sa/ingressgateway-apis-service-account" // the identity of the APIs gateway's pods in the mesh
+	_apiLivenessStalePeriod                        = 7                                                                      // seconds (there is a 2-second buffer to be safe)
+	_lifecycleNodeLabelKey                         = "lifecycle"
+	_spotLifecycleNodeLabelValue                   = "Ec2Spot" // set on the nodes of the spot node group (which may include on-demand instances, depending on spot_config)
+	_highPriorityClassName                         = "cortex-api-high"
+	_lowPriorityClassName                          = "cortex-api-low"
+	_featureStorePasswordSecretKey                 = "password"
+	_scratchVolumeName                             = "scratch"
+	_specHashAnnotationKey                         = "cortex.dev/spec-hash" // the hash of the deployment which cortex generated for the API (see deploymentSpecHash)
+	_podInfoVolumeName                             = "podinfo"
+	_podInfoMountPath                              = "/mnt/podinfo"
+	_podLabelsFileName                             = "labels"
 )
 
 var (
-	_requestMonitorCPURequest = kresource.MustParse("10m")
-	_requestMonitorMemRequest = kresource.MustParse("10Mi")
+	_defaultTerminationGracePeriod = 30 * time.Second // kubernetes' default
 
-	// each Inferentia chip requires 128 HugePages with each HugePage having a size of 2Mi
-	_hugePagesMemPerInf = int64(128 * 2 * 1024 * 1024) // bytes
+	_featureStoreCacheCPURequest = kresource.MustParse("10m")
+
+	// istio's default sidecar requests (global.proxy.resources in manager/manifests/istio-values.yaml)
+	_istioProxyCPURequest = kresource.MustParse("100m")
+	_istioProxyMemRequest = kresource.MustParse("128Mi")
+
+	// the priority classes are defined in manager/manifests/api-priority-classes.yaml
+	_priorityClassValues = map[string]int32{
+		_highPriorityClassName: 100,
+		_lowPriorityClassName:  -1,
+	}
 )
 
 type downloadContainerConfig struct {
@@ -75,180 +119,396 @@ type downloadContainerConfig struct {
 }
 
 type downloadContainerArg struct {
-	From                 string `json:"from"`
-	To                   string `json:"to"`
-	Unzip                bool   `json:"unzip"`
-	ItemName             string `json:"item_name"`               // name of the item being downloaded, just for logging (if "" nothing will be logged)
-	TFModelVersionRename string `json:"tf_model_version_rename"` // e.g. passing in /mnt/model/1 will rename /mnt/model/* to /mnt/model/1 only if there is one item in /mnt/model/
-	HideFromLog          bool   `json:"hide_from_log"`           // if true, don't log where the file is being downloaded from
-	HideUnzippingLog     bool   `json:"hide_unzipping_log"`      // if true, don't log when unzipping
+	Type                 string            `json:"type,omitempty"`    // the source to download from (see _downloadSources); if "", the source is s3
+	Options              map[string]string `json:"options,omitempty"` // options which are specific to the source type
+	From                 string            `json:"from"`
+	To                   string            `json:"to"`
+	Unzip                bool              `json:"unzip"`
+	ItemName             string            `json:"item_name"`                    // name of the item being downloaded, just for logging (if "" nothing will be logged)
+	TFModelVersionRename string            `json:"tf_model_version_rename"`      // e.g. passing in /mnt/model/1 will rename /mnt/model/* to /mnt/model/1 only if there is one item in /mnt/model/
+	HideFromLog          bool              `json:"hide_from_log"`                // if true, don't log where the file is being downloaded from
+	HideUnzippingLog     bool              `json:"hide_unzipping_log"`           // if true, don't log when unzipping
+	VersionID            string            `json:"version_id"`                   // if set, download this version of the S3 object
+	AwaitSuccessPath     string            `json:"await_success_path,omitempty"` // if set, wait for this S3 object to exist before downloading (e.g. while the model is being optimized)
+	AwaitFailurePath     string            `json:"await_failure_path,omitempty"` // if this S3 object appears while waiting, its contents are raised as an error
+	AwaitLog             string            `json:"await_log,omitempty"`          // string to log while waiting
 }
 
 func deploymentSpec(api *spec.API, prevDeployment *kapps.Deployment) *kapps.Deployment {
+	pod := newAPIPod(api)
+
 	switch api.Predictor.Type {
 	case userconfig.TensorFlowPredictorType:
-		return tensorflowAPISpec(api, prevDeployment)
+		pod.tensorflowPredictor()
 	case userconfig.ONNXPredictorType:
-		return onnxAPISpec(api, prevDeployment)
+		pod.onnxPredictor()
 	case userconfig.PythonPredictorType:
-		return pythonAPISpec(api, prevDeployment)
+		pod.pythonPredictor()
+	case userconfig.LLMPredictorType:
+		pod.llmPredictor()
 	default:
 		return nil // unexpected
 	}
-}
-
-func tensorflowAPISpec(api *spec.API, prevDeployment *kapps.Deployment) *kapps.Deployment {
-	apiResourceList := kcore.ResourceList{}
-	tfServingResourceList := kcore.ResourceList{}
-	tfServingLimitsList := kcore.ResourceList{}
-	volumeMounts := _defaultVolumeMounts
-	volumes := _defaultVolumes
-	containers := []kcore.Container{}
 
-	if api.Compute.Inf == 0 {
-		if api.Compute.CPU != nil {
-			userPodCPURequest := k8s.QuantityPtr(api.Compute.CPU.Quantity.DeepCopy())
-			userPodCPURequest.Sub(_requestMonitorCPURequest)
-			q1, q2 := k8s.SplitInTwo(userPodCPURequest)
-			apiResourceList[kcore.ResourceCPU] = *q1
-			tfServingResourceList[kcore.ResourceCPU] = *q2
-		}
+	deployment := k8s.Deployment(&k8s.DeploymentSpec{
+		Name:           k8sName(api.Name),
+		Replicas:       getRequestedReplicasFromDeployment(api, prevDeployment),
+		MaxSurge:       pointer.String(api.UpdateStrategy.MaxSurge),
+		MaxUnavailable: pointer.String(api.UpdateStrategy.MaxUnavailable),
+		Labels: apiLabels(api, map[string]string{
+			"apiName":      api.Name,
+			"apiID":        api.ID,
+			"deploymentID": api.DeploymentID,
+		}),
+		Annotations: apiAnnotations(api, api.ToK8sAnnotations()),
+		Selector: map[string]string{
+			"apiName": api.Name,
+		},
+		PodSpec: k8s.PodSpec{
+			Labels: apiLabels(api, map[string]string{
+				"apiName":      api.Name,
+				"apiID":        api.ID,
+				"deploymentID": api.DeploymentID,
+			}),
+			Annotations: apiAnnotations(api, apiPodAnnotations(api)),
+			K8sPodSpec:  pod.build(),
+		},
+	})
 
-		if api.Compute.Mem != nil {
-			userPodMemRequest := k8s.QuantityPtr(api.Compute.Mem.Quantity.DeepCopy())
-			userPodMemRequest.Sub(_requestMonitorMemRequest)
-			q1, q2 := k8s.SplitInTwo(userPodMemRequest)
-			apiResourceList[kcore.ResourceMemory] = *q1
-			tfServingResourceList[kcore.ResourceMemory] = *q2
-		}
+	if api.Compute.Parallelism.IsMultiNode() {
+		parallelLeaderDeployment(api, deployment)
+	}
 
-		if api.Compute.GPU > 0 {
-			tfServingResourceList["nvidia.com/gpu"] = *kresource.NewQuantity(api.Compute.GPU, kresource.DecimalSI)
-			tfServingLimitsList["nvidia.com/gpu"] = *kresource.NewQuantity(api.Compute.GPU, kresource.DecimalSI)
-		}
-	} else {
-		volumes = append(volumes, kcore.Volume{
-			Name: "neuron-sock",
-		})
-		rtdVolumeMounts := []kcore.VolumeMount{
-			{
-				Name:      "neuron-sock",
-				MountPath: "/sock",
-			},
-		}
-		volumeMounts = append(volumeMounts, rtdVolumeMounts...)
+	deployment.Annotations[_specHashAnnotationKey] = deploymentSpecHash(deployment)
+	return deployment
+}
 
-		neuronContainer := *neuronRuntimeDaemonContainer(api, rtdVolumeMounts)
+// deploymentSpecHash hashes everything in the deployment which cortex sets, other than its replicas (which are managed by
+// the autoscaler), so that deployments can be compared without the fields which kubernetes sets to their defaults; the
+// deployment spec is generated deterministically (e.g. environment variables are sorted), so the hash only changes if
+// the deployment does
+func deploymentSpecHash(deployment *kapps.Deployment) string {
+	annotations := maps.MergeStrMaps(deployment.Annotations)
+	delete(annotations, _specHashAnnotationKey)
+
+	// json sorts the keys of maps
+	specBytes, err := json.Marshal(map[string]interface{}{
+		"labels":      deployment.Labels,
+		"annotations": annotations,
+		"selector":    deployment.Spec.Selector,
+		"strategy":    deployment.Spec.Strategy,
+		"template":    deployment.Spec.Template,
+	})
+	if err != nil {
+		return "" // unexpected; the deployment will be considered changed
+	}
+	return hash.Bytes(specBytes)
+}
 
-		if api.Compute.CPU != nil {
-			userPodCPURequest := k8s.QuantityPtr(api.Compute.CPU.Quantity.DeepCopy())
-			userPodCPURequest.Sub(_requestMonitorCPURequest)
-			q1, q2, q3 := k8s.SplitInThree(userPodCPURequest)
-			apiResourceList[kcore.ResourceCPU] = *q1
-			tfServingResourceList[kcore.ResourceCPU] = *q2
-			neuronContainer.Resources.Requests[kcore.ResourceCPU] = *q3
+// apiLabels adds the labels which are specified in the API's configuration to the labels which cortex sets
+func apiLabels(api *spec.API, labels map[string]string) map[string]string {
+	for key, value := range api.Labels {
+		if _, ok := labels[key]; !ok {
+			labels[key] = value
 		}
+	}
+	return labels
+}
 
-		if api.Compute.Mem != nil {
-			userPodMemRequest := k8s.QuantityPtr(api.Compute.Mem.Quantity.DeepCopy())
-			userPodMemRequest.Sub(_requestMonitorMemRequest)
-			q1, q2, q3 := k8s.SplitInThree(userPodMemRequest)
-			apiResourceList[kcore.ResourceMemory] = *q1
-			tfServingResourceList[kcore.ResourceMemory] = *q2
-			neuronContainer.Resources.Requests[kcore.ResourceMemory] = *q3
+// apiAnnotations adds the annotations which are specified in the API's configuration to the annotations which cortex sets
+func apiAnnotations(api *spec.API, annotations map[string]string) map[string]string {
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	for key, value := range api.Annotations {
+		if _, ok := annotations[key]; !ok {
+			annotations[key] = value
 		}
+	}
+	return annotations
+}
+
+// apiPod is the pod of an API; it is initialized with the containers and volumes which are common to all predictor
+// types, and then modified by the predictor type's mutator (e.g. tensorflowPredictor()) before being built
+type apiPod struct {
+	api            *spec.API
+	accelerator    accelerator // nil if the API doesn't use an accelerator
+	downloadConfig downloadContainerConfig
+	volumes        []kcore.Volume
+	volumeMounts   []kcore.VolumeMount
+
+	// the API container, followed by any containers which serve the API's models; the user's compute request is split
+	// evenly between these containers (and the accelerator's runtime container, if there is one)
+	containers []*kcore.Container
+
+	// the container which runs inference, which is allocated the accelerator's resources
+	inferenceContainer *kcore.Container
+}
 
-		containers = append(containers, neuronContainer)
+func newAPIPod(api *spec.API) *apiPod {
+	acc := getAccelerator(api)
+	volumes, volumeMounts := acceleratorVolumes(api, acc)
+
+	apiVolumeMounts := volumeMounts
+	if usesShmVolume(api) {
+		// the container runtime's default /dev/shm (64Mi) is replaced with a memory-backed volume of the requested size
+		// (NCCL passes data between the GPUs of sharded models through shared memory, so the volume is unbounded if shm_size isn't specified)
+		var shmSize *kresource.Quantity
+		if api.Compute.ShmSize != nil {
+			shmSize = k8s.QuantityPtr(api.Compute.ShmSize.Quantity.DeepCopy())
+		}
+		volumes = append(volumes, k8s.MemoryEmptyDirVolume(_shmVolumeName, shmSize))
+		apiVolumeMounts = append(append([]kcore.VolumeMount{}, volumeMounts...), k8s.EmptyDirVolumeMount(_shmVolumeName, _shmMountPath))
+	}
+	if api.Compute.ScratchVolume != nil {
+		volumes = append(volumes, k8s.PVCVolume(_scratchVolumeName, scratchVolumeClaimName(api.Name)))
+		apiVolumeMounts = append(append([]kcore.VolumeMount{}, apiVolumeMounts...), k8s.PVCVolumeMount(_scratchVolumeName, api.Compute.ScratchVolume.MountPath))
+	}
+	if api.Compute.Parallelism.IsMultiNode() {
+		// the leaders of multi-node APIs read the group which they were assigned (see syncParallelGroups) from their labels
+		volumes = append(volumes, k8s.PodLabelsVolume(_podInfoVolumeName, _podLabelsFileName))
+		apiVolumeMounts = append(append([]kcore.VolumeMount{}, apiVolumeMounts...), k8s.PodLabelsVolumeMount(_podInfoVolumeName, _podInfoMountPath))
 	}
 
-	containers = append(containers, kcore.Container{
+	apiContainer := &kcore.Container{
 		Name:            _apiContainerName,
-		Image:           api.Predictor.Image,
-		ImagePullPolicy: kcore.PullAlways,
+		Image:           apiImage(api),
+		ImagePullPolicy: kcore.PullPolicy(api.Predictor.ImagePullPolicy.String()),
 		Env:             getEnvVars(api, _apiContainerName),
-		EnvFrom:         _baseEnvVars,
-		VolumeMounts:    volumeMounts,
+		EnvFrom:         apiEnvFrom(api),
+		VolumeMounts:    apiVolumeMounts,
 		ReadinessProbe:  fileExistsProbe(_apiReadinessFile),
 		LivenessProbe:   _apiLivenessProbe,
+		Lifecycle:       onShutdownLifecycle(api),
 		Resources: kcore.ResourceRequirements{
-			Requests: apiResourceList,
+			Requests: kcore.ResourceList{},
+			Limits:   kcore.ResourceList{},
 		},
 		Ports: []kcore.ContainerPort{
 			{ContainerPort: _defaultPortInt32},
 		},
 		SecurityContext: &kcore.SecurityContext{
 			Privileged: pointer.Bool(true),
-		}},
-		*tensorflowServingContainer(
-			api,
-			volumeMounts,
-			kcore.ResourceRequirements{
-				Limits:   tfServingLimitsList,
-				Requests: tfServingResourceList,
-			},
-		),
-		*requestMonitorContainer(api),
-	)
+		},
+	}
 
-	return k8s.Deployment(&k8s.DeploymentSpec{
-		Name:           k8sName(api.Name),
-		Replicas:       getRequestedReplicasFromDeployment(api, prevDeployment),
-		MaxSurge:       pointer.String(api.UpdateStrategy.MaxSurge),
-		MaxUnavailable: pointer.String(api.UpdateStrategy.MaxUnavailable),
-		Labels: map[string]string{
-			"apiName":      api.Name,
-			"apiID":        api.ID,
-			"deploymentID": api.DeploymentID,
+	return &apiPod{
+		api:                api,
+		accelerator:        acc,
+		volumes:            volumes,
+		volumeMounts:       volumeMounts,
+		containers:         []*kcore.Container{apiContainer},
+		inferenceContainer: apiContainer,
+	}
+}
+
+func (pod *apiPod) tensorflowPredictor() {
+	pod.downloadConfig = tfDownloadConfig(pod.api)
+
+	// TensorFlow Serving runs inference, so the API container doesn't set limits
+	pod.containers[0].Resources.Limits = nil
+
+	tfServingContainer := tensorflowServingContainer(
+		pod.api,
+		pod.volumeMounts,
+		kcore.ResourceRequirements{
+			Limits:   kcore.ResourceList{},
+			Requests: kcore.ResourceList{},
 		},
-		Annotations: api.ToK8sAnnotations(),
-		Selector: map[string]string{
-			"apiName": api.Name,
+	)
+	pod.containers = append(pod.containers, tfServingContainer)
+	pod.inferenceContainer = tfServingContainer
+
+	if pod.api.Predictor.Batching != nil {
+		pod.volumes = append(pod.volumes, k8s.ConfigMapVolume(_tfServingBatchingVolumeName, k8sName(pod.api.Name)))
+	}
+}
+
+func (pod *apiPod) onnxPredictor() {
+	pod.downloadConfig = onnxDownloadConfig(pod.api)
+}
+
+func (pod *apiPod) pythonPredictor() {
+	pod.downloadConfig = pythonDownloadConfig(pod.api)
+}
+
+func (pod *apiPod) llmPredictor() {
+	pod.downloadConfig = llmDownloadConfig(pod.api)
+
+	// the LLM server runs inference, so the API container doesn't set limits
+	pod.containers[0].Resources.Limits = nil
+
+	llmContainer := llmServerContainer(pod.api, append(append([]kcore.VolumeMount{}, pod.volumeMounts...), k8s.EmptyDirVolumeMount(_shmVolumeName, _shmMountPath)))
+	pod.containers = append(pod.containers, llmContainer)
+	pod.inferenceContainer = llmContainer
+}
+
+func (pod *apiPod) build() kcore.PodSpec {
+	computeContainers := pod.containers
+	var runtimeContainer *kcore.Container
+
+	if pod.accelerator != nil {
+		for resourceName, quantity := range pod.accelerator.resources(pod.api) {
+			pod.inferenceContainer.Resources.Requests[resourceName] = quantity
+			pod.inferenceContainer.Resources.Limits[resourceName] = quantity
+		}
+		if runtimeContainer = pod.accelerator.runtimeContainer(pod.api); runtimeContainer != nil {
+			computeContainers = append(computeContainers, runtimeContainer)
+		}
+	}
+
+	cpus, mems := splitUserCompute(pod.api, len(computeContainers))
+	for i, container := range computeContainers {
+		if cpus != nil {
+			container.Resources.Requests[kcore.ResourceCPU] = cpus[i]
+		}
+		if mems != nil {
+			container.Resources.Requests[kcore.ResourceMemory] = mems[i]
+		}
+	}
+
+	downloaderResources := kcore.ResourceRequirements{}
+	if pod.api.Compute.EphemeralStorage != nil {
+		// the pod's effective request is the greater of the init containers' requests and the sum of the containers'
+		// requests, so the downloader and the API container both request the full amount
+		downloaderResources.Requests = kcore.ResourceList{
+			kcore.ResourceEphemeralStorage: pod.api.Compute.EphemeralStorage.Quantity,
+		}
+		pod.containers[0].Resources.Requests[kcore.ResourceEphemeralStorage] = pod.api.Compute.EphemeralStorage.Quantity
+	}
+
+	var containers []kcore.Container
+	if runtimeContainer != nil {
+		containers = append(containers, *runtimeContainer)
+	}
+	for _, container := range pod.containers {
+		containers = append(containers, *container)
+	}
+	if hasRequestMonitorSidecar() {
+		containers = append(containers, *requestMonitorContainer(pod.api))
+	}
+	if pod.api.FeatureStore != nil && pod.api.FeatureStore.Cache != nil {
+		containers = append(containers, *featureStoreCacheContainer(pod.api))
+	}
+	if pod.api.Predictor.Explainer != nil {
+		containers = append(containers, *explainerContainer(pod.api))
+	}
+	if pod.accelerator != nil {
+		if monitorContainer := pod.accelerator.monitorContainer(pod.api); monitorContainer != nil {
+			containers = append(containers, *monitorContainer)
+		}
+	}
+
+	volumes := pod.volumes
+	downloaderVolumeMounts := _defaultVolumeMounts
+	if usesModelCache(pod.downloadConfig) {
+		volumes = append(volumes, k8s.HostPathVolume(_modelCacheVolumeName, _modelCacheHostPath))
+		downloaderVolumeMounts = append(append([]kcore.VolumeMount{}, _defaultVolumeMounts...), k8s.HostPathVolumeMount(_modelCacheVolumeName, _modelCacheMountPath))
+	}
+
+	// init containers run in order, so the user's init containers run after the project and models have been downloaded
+	initContainers := []kcore.Container{
+		{
+			Name:            _downloaderInitContainerName,
+			Image:           config.Cluster.ImageDownloader,
+			ImagePullPolicy: "Always",
+			Args:            []string{"--download=" + encodeDownloadConfig(pod.downloadConfig)},
+			EnvFrom:         baseEnvFrom(),
+			VolumeMounts:    downloaderVolumeMounts,
+			Resources:       downloaderResources,
 		},
-		PodSpec: k8s.PodSpec{
-			Labels: map[string]string{
-				"apiName":      api.Name,
-				"apiID":        api.ID,
-				"deploymentID": api.DeploymentID,
-			},
-			Annotations: map[string]string{
-				"traffic.sidecar.istio.io/excludeOutboundIPRanges": "0.0.0.0/0",
-			},
-			K8sPodSpec: kcore.PodSpec{
-				RestartPolicy: "Always",
-				InitContainers: []kcore.Container{
-					{
-						Name:            _downloaderInitContainerName,
-						Image:           config.Cluster.ImageDownloader,
-						ImagePullPolicy: "Always",
-						Args:            []string{"--download=" + tfDownloadArgs(api)},
-						EnvFrom:         _baseEnvVars,
-						VolumeMounts:    _defaultVolumeMounts,
-					},
+	}
+	initContainers = append(initContainers, userInitContainers(pod.api)...)
+
+	return kcore.PodSpec{
+		RestartPolicy:      "Always",
+		InitContainers:     initContainers,
+		Containers:         containers,
+		NodeSelector:       nodeSelector(pod.api),
+		Affinity:           nodeAffinity(pod.api),
+		Tolerations:        tolerations(pod.api),
+		PriorityClassName:  priorityClassName(pod.api),
+		Volumes:            volumes,
+		ServiceAccountName: workloadServiceAccountName(),
+
+		TerminationGracePeriodSeconds: terminationGracePeriodSeconds(pod.api),
+	}
+}
+
+// onShutdownLifecycle runs the API's shutdown hook (if any) before the API container is sent SIGTERM; the command is
+// killed if it exceeds the timeout
+func onShutdownLifecycle(api *spec.API) *kcore.Lifecycle {
+	onShutdown := api.Predictor.OnShutdown
+	if onShutdown == nil {
+		return nil
+	}
+
+	if len(onShutdown.Command) > 0 {
+		return &kcore.Lifecycle{
+			PreStop: &kcore.Handler{
+				Exec: &kcore.ExecAction{
+					Command: append([]string{"timeout", s.Int64(int64(onShutdown.Timeout.Seconds()))}, onShutdown.Command...),
 				},
-				Containers: containers,
-				NodeSelector: map[string]string{
-					"workload": "true",
+			},
+		}
+	}
+
+	if onShutdown.HTTPPath != nil {
+		return &kcore.Lifecycle{
+			PreStop: &kcore.Handler{
+				HTTPGet: &kcore.HTTPGetAction{
+					Path: *onShutdown.HTTPPath,
+					Port: intstr.FromInt(int(_defaultPortInt32)),
 				},
-				Tolerations:        _tolerations,
-				Volumes:            volumes,
-				ServiceAccountName: "default",
 			},
-		},
-	})
+		}
+	}
+
+	return nil
+}
+
+// the grace period covers the shutdown hook, and then the predictor's on_shutdown() method and in-flight requests (after
+// SIGTERM), so it's extended by the timeout for each of them; nil uses kubernetes' default (30 seconds)
+func terminationGracePeriodSeconds(api *spec.API) *int64 {
+	onShutdown := api.Predictor.OnShutdown
+	if onShutdown == nil {
+		return nil
+	}
+
+	gracePeriod := _defaultTerminationGracePeriod + onShutdown.Timeout
+	if len(onShutdown.Command) > 0 || onShutdown.HTTPPath != nil {
+		gracePeriod += onShutdown.Timeout
+	}
+	return pointer.Int64(int64(gracePeriod.Seconds()))
+}
+
+func projectDownloadArg(api *spec.API) downloadContainerArg {
+	downloadArg := downloadContainerArg{
+		Type:             bucketDownloadSourceType(),
+		From:             config.Bucket.Path(api.ProjectKey),
+		To:               path.Join(_emptyDirMountPath, "project"),
+		Unzip:            true,
+		ItemName:         "the project code",
+		HideFromLog:      true,
+		HideUnzippingLog: true,
+	}
+
+	// the API container's image can't be pulled until it has been built
+	if api.DependencyImage != "" {
+		buildDir := dependencyBuildDir(dependencyBuildID(api.DependencyImage))
+		downloadArg.AwaitSuccessPath = config.Bucket.Path(path.Join(buildDir, _successMarkerFile))
+		downloadArg.AwaitFailurePath = config.Bucket.Path(path.Join(buildDir, _failureMarkerFile))
+		downloadArg.AwaitLog = "waiting for the image with the project's dependencies to be built"
+	}
+
+	return downloadArg
 }
 
-func tfDownloadArgs(api *spec.API) string {
+func tfDownloadConfig(api *spec.API) downloadContainerConfig {
 	downloadConfig := downloadContainerConfig{
 		LastLog: fmt.Sprintf(_downloaderLastLog, "tensorflow"),
 		DownloadArgs: []downloadContainerArg{
-			{
-				From:             aws.S3Path(config.Cluster.Bucket, api.ProjectKey),
-				To:               path.Join(_emptyDirMountPath, "project"),
-				Unzip:            true,
-				ItemName:         "the project code",
-				HideFromLog:      true,
-				HideUnzippingLog: true,
-			},
+			projectDownloadArg(api),
 		},
 	}
 
@@ -260,265 +520,62 @@ func tfDownloadArgs(api *spec.API) string {
 		} else {
 			itemName = fmt.Sprintf("model %s", model.Name)
 		}
-		downloadConfig.DownloadArgs = append(downloadConfig.DownloadArgs, downloadContainerArg{
+		downloadArg := downloadContainerArg{
 			From:                 model.Model,
 			To:                   path.Join(rootModelPath, model.Name),
 			Unzip:                strings.HasSuffix(model.Model, ".zip"),
 			ItemName:             itemName,
 			TFModelVersionRename: path.Join(rootModelPath, model.Name, "1"),
-		})
-	}
-
-	downloadArgsBytes, _ := json.Marshal(downloadConfig)
-	return base64.URLEncoding.EncodeToString(downloadArgsBytes)
-}
-
-func pythonAPISpec(api *spec.API, prevDeployment *kapps.Deployment) *kapps.Deployment {
-	apiPodResourceList := kcore.ResourceList{}
-	apiPodResourceLimitsList := kcore.ResourceList{}
-	apiPodVolumeMounts := _defaultVolumeMounts
-	volumes := _defaultVolumes
-	containers := []kcore.Container{}
-
-	if api.Compute.Inf == 0 {
-		if api.Compute.CPU != nil {
-			userPodCPURequest := k8s.QuantityPtr(api.Compute.CPU.Quantity.DeepCopy())
-			userPodCPURequest.Sub(_requestMonitorCPURequest)
-			apiPodResourceList[kcore.ResourceCPU] = *userPodCPURequest
-		}
-
-		if api.Compute.Mem != nil {
-			userPodMemRequest := k8s.QuantityPtr(api.Compute.Mem.Quantity.DeepCopy())
-			userPodMemRequest.Sub(_requestMonitorMemRequest)
-			apiPodResourceList[kcore.ResourceMemory] = *userPodMemRequest
+			VersionID:            api.ModelVersionIDs[model.Model],
 		}
-
-		if api.Compute.GPU > 0 {
-			apiPodResourceList["nvidia.com/gpu"] = *kresource.NewQuantity(api.Compute.GPU, kresource.DecimalSI)
-			apiPodResourceLimitsList["nvidia.com/gpu"] = *kresource.NewQuantity(api.Compute.GPU, kresource.DecimalSI)
+		if optimizedModel, ok := api.OptimizedModels[model.Model]; ok {
+			downloadArg = optimizedModelDownloadArg(downloadArg, optimizedModel)
 		}
-
-	} else {
-		volumes = append(volumes, kcore.Volume{
-			Name: "neuron-sock",
-		})
-		rtdVolumeMounts := []kcore.VolumeMount{
-			{
-				Name:      "neuron-sock",
-				MountPath: "/sock",
-			},
-		}
-		apiPodVolumeMounts = append(apiPodVolumeMounts, rtdVolumeMounts...)
-		neuronContainer := *neuronRuntimeDaemonContainer(api, rtdVolumeMounts)
-
-		if api.Compute.CPU != nil {
-			userPodCPURequest := k8s.QuantityPtr(api.Compute.CPU.Quantity.DeepCopy())
-			userPodCPURequest.Sub(_requestMonitorCPURequest)
-			q1, q2 := k8s.SplitInTwo(userPodCPURequest)
-			apiPodResourceList[kcore.ResourceCPU] = *q1
-			neuronContainer.Resources.Requests[kcore.ResourceCPU] = *q2
-		}
-
-		if api.Compute.Mem != nil {
-			userPodMemRequest := k8s.QuantityPtr(api.Compute.Mem.Quantity.DeepCopy())
-			userPodMemRequest.Sub(_requestMonitorMemRequest)
-			q1, q2 := k8s.SplitInTwo(userPodMemRequest)
-			apiPodResourceList[kcore.ResourceMemory] = *q1
-			neuronContainer.Resources.Requests[kcore.ResourceMemory] = *q2
-		}
-
-		containers = append(containers, neuronContainer)
+		downloadConfig.DownloadArgs = append(downloadConfig.DownloadArgs, s3ModelDownloadArg(downloadArg))
 	}
 
-	containers = append(containers, kcore.Container{
-		Name:            _apiContainerName,
-		Image:           api.Predictor.Image,
-		ImagePullPolicy: kcore.PullAlways,
-		Env:             getEnvVars(api, _apiContainerName),
-		EnvFrom:         _baseEnvVars,
-		VolumeMounts:    apiPodVolumeMounts,
-		ReadinessProbe:  fileExistsProbe(_apiReadinessFile),
-		LivenessProbe:   _apiLivenessProbe,
-		Resources: kcore.ResourceRequirements{
-			Requests: apiPodResourceList,
-			Limits:   apiPodResourceLimitsList,
-		},
-		Ports: []kcore.ContainerPort{
-			{ContainerPort: _defaultPortInt32},
-		},
-		SecurityContext: &kcore.SecurityContext{
-			Privileged: pointer.Bool(true),
-		}},
-		*requestMonitorContainer(api),
-	)
-
-	return k8s.Deployment(&k8s.DeploymentSpec{
-		Name:           k8sName(api.Name),
-		Replicas:       getRequestedReplicasFromDeployment(api, prevDeployment),
-		MaxSurge:       pointer.String(api.UpdateStrategy.MaxSurge),
-		MaxUnavailable: pointer.String(api.UpdateStrategy.MaxUnavailable),
-		Labels: map[string]string{
-			"apiName":      api.Name,
-			"apiID":        api.ID,
-			"deploymentID": api.DeploymentID,
-		},
-		Annotations: api.ToK8sAnnotations(),
-		Selector: map[string]string{
-			"apiName": api.Name,
-		},
-		PodSpec: k8s.PodSpec{
-			Labels: map[string]string{
-				"apiName":      api.Name,
-				"apiID":        api.ID,
-				"deploymentID": api.DeploymentID,
-			},
-			Annotations: map[string]string{
-				"traffic.sidecar.istio.io/excludeOutboundIPRanges": "0.0.0.0/0",
-			},
-			K8sPodSpec: kcore.PodSpec{
-				RestartPolicy: "Always",
-				InitContainers: []kcore.Container{
-					{
-						Name:            _downloaderInitContainerName,
-						Image:           config.Cluster.ImageDownloader,
-						ImagePullPolicy: "Always",
-						Args:            []string{"--download=" + pythonDownloadArgs(api)},
-						EnvFrom:         _baseEnvVars,
-						VolumeMounts:    _defaultVolumeMounts,
-					},
-				},
-				Containers: containers,
-				NodeSelector: map[string]string{
-					"workload": "true",
-				},
-				Tolerations:        _tolerations,
-				Volumes:            volumes,
-				ServiceAccountName: "default",
-			},
-		},
-	})
+	return downloadConfig
 }
 
-func pythonDownloadArgs(api *spec.API) string {
+func pythonDownloadConfig(api *spec.API) downloadContainerConfig {
 	downloadConfig := downloadContainerConfig{
 		LastLog: fmt.Sprintf(_downloaderLastLog, "python"),
 		DownloadArgs: []downloadContainerArg{
-			{
-				From:             aws.S3Path(config.Cluster.Bucket, api.ProjectKey),
-				To:               path.Join(_emptyDirMountPath, "project"),
-				Unzip:            true,
-				ItemName:         "the project code",
-				HideFromLog:      true,
-				HideUnzippingLog: true,
-			},
+			projectDownloadArg(api),
 		},
 	}
 
-	downloadArgsBytes, _ := json.Marshal(downloadConfig)
-	return base64.URLEncoding.EncodeToString(downloadArgsBytes)
+	return downloadConfig
 }
 
-func onnxAPISpec(api *spec.API, prevDeployment *kapps.Deployment) *kapps.Deployment {
-	resourceList := kcore.ResourceList{}
-	resourceLimitsList := kcore.ResourceList{}
-
-	if api.Compute.CPU != nil {
-		userPodCPURequest := k8s.QuantityPtr(api.Compute.CPU.Quantity.DeepCopy())
-		userPodCPURequest.Sub(_requestMonitorCPURequest)
-		resourceList[kcore.ResourceCPU] = *userPodCPURequest
+func llmDownloadConfig(api *spec.API) downloadContainerConfig {
+	modelDownloadArg := downloadContainerArg{
+		From:     *api.Predictor.Model,
+		To:       path.Join(_emptyDirMountPath, "model"),
+		ItemName: "the model",
 	}
-
-	if api.Compute.Mem != nil {
-		userPodMemRequest := k8s.QuantityPtr(api.Compute.Mem.Quantity.DeepCopy())
-		userPodMemRequest.Sub(_requestMonitorMemRequest)
-		resourceList[kcore.ResourceMemory] = *userPodMemRequest
+	if docker.IsOCIPath(*api.Predictor.Model) {
+		modelDownloadArg = ociDownloadArg(api, *api.Predictor.Model, modelDownloadArg.To, modelDownloadArg.ItemName)
+	} else {
+		modelDownloadArg = s3ModelDownloadArg(modelDownloadArg)
 	}
 
-	if api.Compute.GPU > 0 {
-		resourceList["nvidia.com/gpu"] = *kresource.NewQuantity(api.Compute.GPU, kresource.DecimalSI)
-		resourceLimitsList["nvidia.com/gpu"] = *kresource.NewQuantity(api.Compute.GPU, kresource.DecimalSI)
+	downloadConfig := downloadContainerConfig{
+		LastLog: fmt.Sprintf(_downloaderLastLog, "llm"),
+		DownloadArgs: []downloadContainerArg{
+			projectDownloadArg(api),
+			modelDownloadArg,
+		},
 	}
 
-	return k8s.Deployment(&k8s.DeploymentSpec{
-		Name:           k8sName(api.Name),
-		Replicas:       getRequestedReplicasFromDeployment(api, prevDeployment),
-		MaxSurge:       pointer.String(api.UpdateStrategy.MaxSurge),
-		MaxUnavailable: pointer.String(api.UpdateStrategy.MaxUnavailable),
-		Labels: map[string]string{
-			"apiName":      api.Name,
-			"apiID":        api.ID,
-			"deploymentID": api.DeploymentID,
-		},
-		Annotations: api.ToK8sAnnotations(),
-		Selector: map[string]string{
-			"apiName": api.Name,
-		},
-		PodSpec: k8s.PodSpec{
-			Labels: map[string]string{
-				"apiName":      api.Name,
-				"apiID":        api.ID,
-				"deploymentID": api.DeploymentID,
-			},
-			Annotations: map[string]string{
-				"traffic.sidecar.istio.io/excludeOutboundIPRanges": "0.0.0.0/0",
-			},
-			K8sPodSpec: kcore.PodSpec{
-				InitContainers: []kcore.Container{
-					{
-						Name:            _downloaderInitContainerName,
-						Image:           config.Cluster.ImageDownloader,
-						ImagePullPolicy: "Always",
-						Args:            []string{"--download=" + onnxDownloadArgs(api)},
-						EnvFrom:         _baseEnvVars,
-						VolumeMounts:    _defaultVolumeMounts,
-					},
-				},
-				Containers: []kcore.Container{
-					{
-						Name:            _apiContainerName,
-						Image:           api.Predictor.Image,
-						ImagePullPolicy: kcore.PullAlways,
-						Env:             getEnvVars(api, _apiContainerName),
-						EnvFrom:         _baseEnvVars,
-						VolumeMounts:    _defaultVolumeMounts,
-						ReadinessProbe:  fileExistsProbe(_apiReadinessFile),
-						LivenessProbe:   _apiLivenessProbe,
-						Resources: kcore.ResourceRequirements{
-							Requests: resourceList,
-							Limits:   resourceLimitsList,
-						},
-						Ports: []kcore.ContainerPort{
-							{ContainerPort: _defaultPortInt32},
-						},
-						SecurityContext: &kcore.SecurityContext{
-							Privileged: pointer.Bool(true),
-						},
-					},
-					*requestMonitorContainer(api),
-				},
-				NodeSelector: map[string]string{
-					"workload": "true",
-				},
-				Tolerations:        _tolerations,
-				Volumes:            _defaultVolumes,
-				ServiceAccountName: "default",
-			},
-		},
-	})
+	return downloadConfig
 }
 
-func onnxDownloadArgs(api *spec.API) string {
+func onnxDownloadConfig(api *spec.API) downloadContainerConfig {
 	downloadConfig := downloadContainerConfig{
 		LastLog: fmt.Sprintf(_downloaderLastLog, "onnx"),
 		DownloadArgs: []downloadContainerArg{
-			{
-				From:             aws.S3Path(config.Cluster.Bucket, api.ProjectKey),
-				To:               path.Join(_emptyDirMountPath, "project"),
-				Unzip:            true,
-				ItemName:         "the project code",
-				HideFromLog:      true,
-				HideUnzippingLog: true,
-			},
+			projectDownloadArg(api),
 		},
 	}
 
@@ -530,15 +587,31 @@ func onnxDownloadArgs(api *spec.API) string {
 		} else {
 			itemName = fmt.Sprintf("model %s", model.Name)
 		}
-		downloadConfig.DownloadArgs = append(downloadConfig.DownloadArgs, downloadContainerArg{
-			From:     model.Model,
-			To:       path.Join(rootModelPath, model.Name),
-			ItemName: itemName,
-		})
+		downloadArg := downloadContainerArg{
+			From:      model.Model,
+			To:        path.Join(rootModelPath, model.Name),
+			ItemName:  itemName,
+			VersionID: api.ModelVersionIDs[model.Model],
+		}
+		if optimizedModel, ok := api.OptimizedModels[model.Model]; ok {
+			downloadArg = optimizedModelDownloadArg(downloadArg, optimizedModel)
+		}
+		downloadConfig.DownloadArgs = append(downloadConfig.DownloadArgs, s3ModelDownloadArg(downloadArg))
 	}
 
-	downloadArgsBytes, _ := json.Marshal(downloadConfig)
-	return base64.URLEncoding.EncodeToString(downloadArgsBytes)
+	return downloadConfig
+}
+
+// downloads the optimized model instead of the original one, once the model optimizer has uploaded it
+func optimizedModelDownloadArg(downloadArg downloadContainerArg, optimizedModel string) downloadContainerArg {
+	bucket, key, _ := aws.SplitS3Path(optimizedModel)
+	downloadArg.From = optimizedModel
+	downloadArg.Unzip = false
+	downloadArg.VersionID = ""
+	downloadArg.AwaitSuccessPath = aws.S3Path(bucket, path.Join(path.Dir(key), _successMarkerFile))
+	downloadArg.AwaitFailurePath = aws.S3Path(bucket, path.Join(path.Dir(key), _failureMarkerFile))
+	downloadArg.AwaitLog = fmt.Sprintf("waiting for %s to be optimized", downloadArg.ItemName)
+	return downloadArg
 }
 
 func serviceSpec(api *spec.API) *kcore.Service {
@@ -546,10 +619,10 @@ func serviceSpec(api *spec.API) *kcore.Service {
 		Name:        k8sName(api.Name),
 		Port:        _defaultPortInt32,
 		TargetPort:  _defaultPortInt32,
-		Annotations: api.ToK8sAnnotations(),
-		Labels: map[string]string{
+		Annotations: apiAnnotations(api, api.ToK8sAnnotations()),
+		Labels: apiLabels(api, map[string]string{
 			"apiName": api.Name,
-		},
+		}),
 		Selector: map[string]string{
 			"apiName": api.Name,
 		},
@@ -558,17 +631,354 @@ func serviceSpec(api *spec.API) *kcore.Service {
 
 func virtualServiceSpec(api *spec.API) *istioclientnetworking.VirtualService {
 	return k8s.VirtualService(&k8s.VirtualServiceSpec{
+		Name:                 k8sName(api.Name),
+		Gateways:             []string{apisGateway(api.Namespace)},
+		ServiceName:          k8sName(api.Name),
+		ServicePort:          _defaultPortInt32,
+		Path:                 *api.Endpoint,
+		Rewrite:              pointer.String("predict"),
+		Annotations:          apiAnnotations(api, api.ToK8sAnnotations()),
+		WeightedDestinations: experimentDestinations(api),
+		CORS:                 corsPolicy(api),
+		AdditionalRoutes:     additionalRoutes(api),
+		RequestHeaders:       requestHeaderOperations(api),
+		ResponseHeaders:      responseHeaderOperations(api),
+		Labels: apiLabels(api, map[string]string{
+			"apiName": api.Name,
+		}),
+	})
+}
+
+func requestHeaderOperations(api *spec.API) *k8s.HeaderOperations {
+	if api.Networking.Headers == nil || api.Networking.Headers.Request == nil {
+		return nil
+	}
+	return &k8s.HeaderOperations{
+		Set:    api.Networking.Headers.Request.Set,
+		Add:    api.Networking.Headers.Request.Add,
+		Remove: api.Networking.Headers.Request.Remove,
+	}
+}
+
+// propagated headers are set on the response to the value of the request's header (using envoy's request variables)
+func responseHeaderOperations(api *spec.API) *k8s.HeaderOperations {
+	if api.Networking.Headers == nil || (api.Networking.Headers.Response == nil && len(api.Networking.Headers.Propagate) == 0) {
+		return nil
+	}
+
+	operations := &k8s.HeaderOperations{}
+	if api.Networking.Headers.Response != nil {
+		operations.Set = api.Networking.Headers.Response.Set
+		operations.Add = api.Networking.Headers.Response.Add
+		operations.Remove = api.Networking.Headers.Response.Remove
+	}
+
+	if len(api.Networking.Headers.Propagate) > 0 {
+		set := make(map[string]string, len(operations.Set)+len(api.Networking.Headers.Propagate))
+		for name, value := range operations.Set {
+			set[name] = value
+		}
+		for _, name := range api.Networking.Headers.Propagate {
+			set[name] = "%REQ(" + name + ")%"
+		}
+		operations.Set = set
+	}
+
+	return operations
+}
+
+func additionalRoutes(api *spec.API) []k8s.VirtualServiceRoute {
+	var routes []k8s.VirtualServiceRoute
+	for _, additionalEndpoint := range api.Networking.AdditionalEndpoints {
+		routes = append(routes, k8s.VirtualServiceRoute{
+			Path:    additionalEndpoint.Endpoint,
+			Methods: additionalEndpoint.Methods,
+			Rewrite: pointer.String(additionalEndpoint.Rewrite),
+		})
+	}
+	return routes
+}
+
+func corsPolicy(api *spec.API) *k8s.CORSPolicy {
+	if api.Networking.CORS == nil {
+		return nil
+	}
+	return &k8s.CORSPolicy{
+		AllowOrigins: api.Networking.CORS.AllowOrigins,
+		AllowMethods: api.Networking.CORS.AllowMethods,
+		AllowHeaders: api.Networking.CORS.AllowHeaders,
+		MaxAge:       api.Networking.CORS.MaxAge,
+	}
+}
+
+// used instead of virtualServiceSpec when the cluster's networking backend is a Kubernetes ingress controller
+func ingressSpec(api *spec.API) *kextensions.Ingress {
+	annotations := apiAnnotations(api, api.ToK8sAnnotations())
+	if config.Cluster.IngressClass == "nginx" {
+		annotations["nginx.ingress.kubernetes.io/rewrite-target"] = "/predict"
+	}
+
+	return k8s.Ingress(&k8s.IngressSpec{
+		Name:         k8sName(api.Name),
+		IngressClass: config.Cluster.IngressClass,
+		ServiceName:  k8sName(api.Name),
+		ServicePort:  _defaultPortInt32,
+		Path:         *api.Endpoint,
+		Annotations:  annotations,
+		Labels: apiLabels(api, map[string]string{
+			"apiName": api.Name,
+		}),
+	})
+}
+
+// API pods are in the mesh (i.e. they get an istio sidecar) if they opt in with networking.mesh, or if the cluster has
+// api_mtls (validateMesh prevents APIs from opting out in that case)
+func inMesh(api *userconfig.API) bool {
+	if !isIstioNetworking() {
+		return false
+	}
+	if api.Networking.Mesh != nil {
+		return api.Networking.Mesh.Enabled
+	}
+	return config.Cluster.APIMTLS
+}
+
+// whether the API's sidecar only accepts mutual TLS connections (see authenticationPolicySpec)
+func apiMTLS(api *userconfig.API) bool {
+	if !inMesh(api) {
+		return false
+	}
+	if api.Networking.Mesh != nil && api.Networking.Mesh.MTLS != nil {
+		return *api.Networking.Mesh.MTLS
+	}
+	return config.Cluster.APIMTLS
+}
+
+func apiPodAnnotations(api *spec.API) map[string]string {
+	if !inMesh(api.API) {
+		return map[string]string{
+			"sidecar.istio.io/inject": "false",
+		}
+	}
+
+	annotations := map[string]string{
+		"sidecar.istio.io/inject": "true",
+	}
+	if api.Networking.Mesh != nil {
+		annotations["sidecar.istio.io/proxyCPU"] = api.Networking.Mesh.SidecarCPU.String()
+		annotations["sidecar.istio.io/proxyMemory"] = api.Networking.Mesh.SidecarMem.String()
+	}
+	return annotations
+}
+
+// the destination rule is only needed for APIs which shed load or have version pinning (its subsets are managed by
+// updateVersionPinningRoutes), or when the gateway must use mutual TLS
+func needsDestinationRule(api *spec.API) bool {
+	return api.Autoscaling.OverloadBehavior == userconfig.ShedOverloadBehaviorType || api.Networking.VersionPinning || apiMTLS(api.API)
+}
+
+// Circuit breaker on the APIs gateway for APIs which shed load: once every replica is at its concurrency limit,
+// envoy rejects requests immediately instead of forwarding them to replicas which would reject them anyway.
+// The limits are enforced by each gateway pod independently, so they are approximate when the gateway is scaled out.
+// With mutual TLS, the rule also makes the gateway connect to the API's pods with istio's mutual TLS certificates
+func destinationRuleSpec(api *spec.API) *istioclientnetworking.DestinationRule {
+	trafficPolicy := &istionetworking.TrafficPolicy{}
+
+	if api.Autoscaling.OverloadBehavior == userconfig.ShedOverloadBehaviorType {
+		maxRequests := api.Autoscaling.ReplicaConcurrencyLimit() * int64(api.Autoscaling.MaxReplicas)
+		if maxRequests > math.MaxInt32 {
+			maxRequests = math.MaxInt32
+		}
+
+		trafficPolicy.ConnectionPool = &istionetworking.ConnectionPoolSettings{
+			Tcp: &istionetworking.ConnectionPoolSettings_TCPSettings{
+				MaxConnections: int32(maxRequests),
+			},
+			Http: &istionetworking.ConnectionPoolSettings_HTTPSettings{
+				Http1MaxPendingRequests: 1, // 0 would fall back to istio's default
+				Http2MaxRequests:        int32(maxRequests),
+			},
+		}
+	}
+
+	if apiMTLS(api.API) {
+		trafficPolicy.Tls = &istionetworking.TLSSettings{
+			Mode: istionetworking.TLSSettings_ISTIO_MUTUAL,
+		}
+	}
+
+	return k8s.DestinationRule(&k8s.DestinationRuleSpec{
+		Name:          k8sName(api.Name),
+		ServiceName:   k8sName(api.Name),
+		TrafficPolicy: trafficPolicy,
+		Annotations:   apiAnnotations(api, api.ToK8sAnnotations()),
+		Labels: apiLabels(api, map[string]string{
+			"apiName": api.Name,
+		}),
+	})
+}
+
+// requires mutual TLS for all requests to the API's pods (only used for APIs with mutual TLS)
+func authenticationPolicySpec(api *spec.API) *istioclientauthentication.Policy {
+	return k8s.AuthenticationPolicy(&k8s.AuthenticationPolicySpec{
 		Name:        k8sName(api.Name),
-		Gateways:    []string{"apis-gateway"},
 		ServiceName: k8sName(api.Name),
-		ServicePort: _defaultPortInt32,
-		Path:        *api.Endpoint,
-		Rewrite:     pointer.String("predict"),
-		Annotations: api.ToK8sAnnotations(),
-		Labels: map[string]string{
+		Annotations: apiAnnotations(api, nil),
+		Labels: apiLabels(api, map[string]string{
 			"apiName": api.Name,
+		}),
+	})
+}
+
+// only the APIs gateway may send requests to the API's pods (only used for APIs with mutual TLS)
+func authorizationPolicySpec(api *spec.API) *istioclientsecurity.AuthorizationPolicy {
+	return k8s.AuthorizationPolicy(&k8s.AuthorizationPolicySpec{
+		Name: k8sName(api.Name),
+		Selector: map[string]string{
+			"apiName": api.Name,
+		},
+		Principals:  []string{_apisGatewayPrincipal},
+		Annotations: apiAnnotations(api, nil),
+		Labels: apiLabels(api, map[string]string{
+			"apiName": api.Name,
+		}),
+	})
+}
+
+// API pods don't run istio sidecars (unless api_mtls is enabled), so compression is configured on the APIs gateway. Envoy's gzip filter can't be
+// disabled per route, so the Accept-Encoding header is hidden from it for requests to endpoints which don't have
+// compression enabled, and restored before the request is forwarded
+func compressionEnvoyFilterSpec(compressedEndpoints []string) (*istioclientnetworking.EnvoyFilter, error) {
+	var endpointsTable strings.Builder
+	for _, endpoint := range compressedEndpoints {
+		endpointsTable.WriteString(fmt.Sprintf("  [%q] = true,\n", urls.CanonicalizeEndpoint(endpoint)))
+	}
+
+	hideAcceptEncodingFilter, err := k8s.EnvoyFilterPatchValue(map[string]interface{}{
+		"name": "envoy.lua",
+		"config": map[string]interface{}{
+			"inline_code": fmt.Sprintf(_hideAcceptEncodingLua, endpointsTable.String()),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	gzipFilter, err := k8s.EnvoyFilterPatchValue(map[string]interface{}{
+		"name": "envoy.gzip",
+		"config": map[string]interface{}{
+			"remove_accept_encoding_header": false,
+			"compression_level":             "DEFAULT",
+			"content_type":                  _compressedContentTypes,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	restoreAcceptEncodingFilter, err := k8s.EnvoyFilterPatchValue(map[string]interface{}{
+		"name": "envoy.lua",
+		"config": map[string]interface{}{
+			"inline_code": _restoreAcceptEncodingLua,
 		},
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return k8s.EnvoyFilter(&k8s.EnvoyFilterSpec{
+		Name: _compressionEnvoyFilterName,
+		WorkloadLabels: map[string]string{
+			"istio": "ingressgateway-apis",
+		},
+		ConfigPatches: apisGatewayHTTPFilterPatches(hideAcceptEncodingFilter, gzipFilter, restoreAcceptEncodingFilter),
+	}), nil
+}
+
+// responses maps endpoints to the response that requests to them should be responded to with
+func maintenanceEnvoyFilterSpec(responses map[string]gatewayResponse) (*istioclientnetworking.EnvoyFilter, error) {
+	endpoints := make([]string, 0, len(responses))
+	for endpoint := range responses {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints) // so that the filter only changes when the responses do
+
+	var responsesTable strings.Builder
+	for _, endpoint := range endpoints {
+		response := responses[endpoint]
+		responsesTable.WriteString(fmt.Sprintf("  [%s] = {status = %s, content_type = %s, body = %s, weight = %d},\n",
+			luaString(urls.CanonicalizeEndpoint(endpoint)), luaString(s.Int32(response.statusCode)), luaString(response.contentType), luaString(response.body), response.weight))
+	}
+
+	maintenanceFilter, err := k8s.EnvoyFilterPatchValue(map[string]interface{}{
+		"name": "envoy.lua",
+		"config": map[string]interface{}{
+			"inline_code": fmt.Sprintf(_maintenanceLua, responsesTable.String()),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return k8s.EnvoyFilter(&k8s.EnvoyFilterSpec{
+		Name: _maintenanceEnvoyFilterName,
+		WorkloadLabels: map[string]string{
+			"istio": "ingressgateway-apis",
+		},
+		ConfigPatches: apisGatewayHTTPFilterPatches(maintenanceFilter),
+	}), nil
+}
+
+// luaString returns str as a Lua 5.1 string literal (Go's %q isn't valid Lua, e.g. for \u escapes); bytes other than
+// printable ASCII are written as decimal escapes, which are always 3 digits so that they can't absorb a following digit
+func luaString(str string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := 0; i < len(str); i++ {
+		b := str[i]
+		switch {
+		case b == '"' || b == '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(b)
+		case b < 0x20 || b >= 0x7f:
+			sb.WriteString(fmt.Sprintf("\\%03d", b))
+		default:
+			sb.WriteByte(b)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// each filter is inserted immediately before the router, so they will run in the order listed
+func apisGatewayHTTPFilterPatches(filters ...*gogotypes.Struct) []*istionetworking.EnvoyFilter_EnvoyConfigObjectPatch {
+	configPatches := make([]*istionetworking.EnvoyFilter_EnvoyConfigObjectPatch, len(filters))
+	for i, filter := range filters {
+		configPatches[i] = &istionetworking.EnvoyFilter_EnvoyConfigObjectPatch{
+			ApplyTo: istionetworking.EnvoyFilter_HTTP_FILTER,
+			Match: &istionetworking.EnvoyFilter_EnvoyConfigObjectMatch{
+				Context: istionetworking.EnvoyFilter_GATEWAY,
+				ObjectTypes: &istionetworking.EnvoyFilter_EnvoyConfigObjectMatch_Listener{
+					Listener: &istionetworking.EnvoyFilter_ListenerMatch{
+						PortNumber: 80,
+						FilterChain: &istionetworking.EnvoyFilter_ListenerMatch_FilterChainMatch{
+							Filter: &istionetworking.EnvoyFilter_ListenerMatch_FilterMatch{
+								Name: "envoy.http_connection_manager",
+								SubFilter: &istionetworking.EnvoyFilter_ListenerMatch_SubFilterMatch{
+									Name: "envoy.router",
+								},
+							},
+						},
+					},
+				},
+			},
+			Patch: &istionetworking.EnvoyFilter_Patch{
+				Operation: istionetworking.EnvoyFilter_Patch_INSERT_BEFORE,
+				Value:     filter,
+			},
+		}
+	}
+	return configPatches
 }
 
 func getRequestedReplicasFromDeployment(api *spec.API, deployment *kapps.Deployment) int32 {
@@ -589,20 +999,115 @@ func getRequestedReplicasFromDeployment(api *spec.API, deployment *kapps.Deploym
 	return requestedReplicas
 }
 
+// nodeSelector schedules the API on worker nodes, restricted to the API's node group if compute.node_group is specified
+func nodeSelector(api *spec.API) map[string]string {
+	selector := map[string]string{
+		"workload": "true",
+	}
+	if api.Compute.NodeGroup != nil {
+		selector[clusterconfig.NodeGroupLabelKey] = *api.Compute.NodeGroup
+	}
+	return selector
+}
+
+// tolerations allows the API to be scheduled on the nodes of its node group, which are tainted so that other APIs aren't scheduled on them
+func tolerations(api *spec.API) []kcore.Toleration {
+	if api.Compute.NodeGroup == nil {
+		return _tolerations
+	}
+
+	return append([]kcore.Toleration{
+		{
+			Key:      clusterconfig.NodeGroupLabelKey,
+			Operator: kcore.TolerationOpEqual,
+			Value:    *api.Compute.NodeGroup,
+			Effect:   kcore.TaintEffectNoSchedule,
+		},
+	}, _tolerations...)
+}
+
+// priorityClassName returns the priority class of the API's pods ("" for APIs with the default priority)
+func priorityClassName(api *spec.API) string {
+	return priorityTypeClassName(api.Compute.Priority)
+}
+
+func priorityTypeClassName(priority userconfig.PriorityType) string {
+	switch priority {
+	case userconfig.HighPriorityType:
+		return _highPriorityClassName
+	case userconfig.LowPriorityType:
+		return _lowPriorityClassName
+	}
+	return ""
+}
+
+// podPriority returns the priority which will be assigned to pods created from podSpec
+func podPriority(podSpec *kcore.PodSpec) int32 {
+	if podSpec.Priority != nil {
+		return *podSpec.Priority
+	}
+	return _priorityClassValues[podSpec.PriorityClassName]
+}
+
+// nodeAffinity schedules the API on the spot node group if compute.spot is true (preferring it if on_demand_fallback is
+// true), or off of it if compute.spot is false; if compute.spot isn't specified, the API can be scheduled on any worker
+func nodeAffinity(api *spec.API) *kcore.Affinity {
+	if api.Compute.Spot == nil {
+		return nil
+	}
+
+	operator := kcore.NodeSelectorOpNotIn
+	if *api.Compute.Spot {
+		operator = kcore.NodeSelectorOpIn
+	}
+
+	selectorTerm := kcore.NodeSelectorTerm{
+		MatchExpressions: []kcore.NodeSelectorRequirement{
+			{
+				Key:      _lifecycleNodeLabelKey,
+				Operator: operator,
+				Values:   []string{_spotLifecycleNodeLabelValue},
+			},
+		},
+	}
+
+	if *api.Compute.Spot && api.Compute.OnDemandFallback {
+		return &kcore.Affinity{
+			NodeAffinity: &kcore.NodeAffinity{
+				PreferredDuringSchedulingIgnoredDuringExecution: []kcore.PreferredSchedulingTerm{
+					{
+						Weight:     100,
+						Preference: selectorTerm,
+					},
+				},
+			},
+		}
+	}
+
+	return &kcore.Affinity{
+		NodeAffinity: &kcore.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &kcore.NodeSelector{
+				NodeSelectorTerms: []kcore.NodeSelectorTerm{selectorTerm},
+			},
+		},
+	}
+}
+
+// the local provider gives its containers the same environment (see getAPIEnv() in cli/local/docker_spec.go), so changes here should be mirrored there
 func getEnvVars(api *spec.API, container string) []kcore.EnvVar {
 	envVars := []kcore.EnvVar{}
 
-	for name, val := range api.Predictor.Env {
+	for _, name := range maps.StrMapSortedKeys(api.Predictor.Env) {
 		envVars = append(envVars, kcore.EnvVar{
 			Name:  name,
-			Value: val,
+			Value: api.Predictor.Env[name],
 		})
 	}
 
 	envVars = append(envVars,
 		kcore.EnvVar{
 			Name:  "CORTEX_PROVIDER",
-			Value: types.AWSProviderType.String(),
+			Value: config.Cluster.Provider.String(),
 		},
 	)
 
@@ -626,16 +1131,20 @@ func getEnvVars(api *spec.API, container string) []kcore.EnvVar {
 			},
 			kcore.EnvVar{
 				Name:  "CORTEX_MAX_REPLICA_CONCURRENCY",
-				Value: s.Int64(api.Autoscaling.MaxReplicaConcurrency),
+				Value: s.Int64(api.Autoscaling.ReplicaConcurrencyLimit()),
 			},
 			kcore.EnvVar{
 				Name: "CORTEX_MAX_WORKER_CONCURRENCY",
 				// add 1 because it was required to achieve the target concurrency for 1 worker, 1 thread
-				Value: s.Int64(1 + int64(math.Round(float64(api.Autoscaling.MaxReplicaConcurrency)/float64(api.Autoscaling.WorkersPerReplica)))),
+				Value: s.Int64(1 + int64(math.Round(float64(api.Autoscaling.ReplicaConcurrencyLimit())/float64(api.Autoscaling.WorkersPerReplica)))),
 			},
 			kcore.EnvVar{
 				Name:  "CORTEX_SO_MAX_CONN",
-				Value: s.Int64(api.Autoscaling.MaxReplicaConcurrency + 100), // add a buffer to be safe
+				Value: s.Int64(api.Autoscaling.ReplicaConcurrencyLimit() + 100), // add a buffer to be safe
+			},
+			kcore.EnvVar{
+				Name:  "CORTEX_OVERLOAD_BEHAVIOR",
+				Value: api.Autoscaling.OverloadBehavior.String(),
 			},
 			kcore.EnvVar{
 				Name:  "CORTEX_SERVING_PORT",
@@ -643,7 +1152,7 @@ func getEnvVars(api *spec.API, container string) []kcore.EnvVar {
 			},
 			kcore.EnvVar{
 				Name:  "CORTEX_API_SPEC",
-				Value: aws.S3Path(config.Cluster.Bucket, api.Key),
+				Value: config.Bucket.Path(api.Key),
 			},
 			kcore.EnvVar{
 				Name:  "CORTEX_CACHE_DIR",
@@ -653,8 +1162,50 @@ func getEnvVars(api *spec.API, container string) []kcore.EnvVar {
 				Name:  "CORTEX_PROJECT_DIR",
 				Value: path.Join(_emptyDirMountPath, "project"),
 			},
+			kcore.EnvVar{
+				// the predictor's metrics client drops metrics beyond this many, since the operator doesn't report them
+				Name:  "CORTEX_MAX_CUSTOM_METRICS",
+				Value: s.Int(consts.MaxCustomMetricsPerAPI),
+			},
 		)
 
+		if hasInProcessRequestMonitor() {
+			envVars = append(envVars,
+				kcore.EnvVar{
+					Name:  "CORTEX_REQUEST_MONITOR",
+					Value: clusterconfig.InProcessRequestMonitorMode.String(),
+				},
+				kcore.EnvVar{
+					Name:  "CORTEX_CLUSTER_NAME",
+					Value: config.Cluster.ClusterName,
+				},
+				kcore.EnvVar{
+					Name:  "CORTEX_REQUEST_MONITOR_FLUSH_INTERVAL",
+					Value: s.Int64(config.Cluster.RequestMonitorFlushInterval),
+				},
+			)
+		}
+
+		// request metrics are only shipped to the cloudwatch agent unless the statsd sink is configured
+		if config.Cluster.HasMetricSink(clusterconfig.StatsDMetricSink) {
+			envVars = append(envVars,
+				kcore.EnvVar{
+					Name:  "CORTEX_METRIC_SINKS",
+					Value: strings.Join(config.Cluster.MetricSinks, ","),
+				},
+				kcore.EnvVar{
+					Name:  "CORTEX_STATSD_PORT",
+					Value: s.Int64(config.Cluster.StatsDPort),
+				},
+			)
+			if config.Cluster.StatsDHost != nil {
+				envVars = append(envVars, kcore.EnvVar{
+					Name:  "CORTEX_STATSD_HOST",
+					Value: *config.Cluster.StatsDHost,
+				})
+			}
+		}
+
 		if api.Predictor.PythonPath != nil {
 			envVars = append(envVars, kcore.EnvVar{
 				Name:  "PYTHON_PATH",
@@ -662,6 +1213,172 @@ func getEnvVars(api *spec.API, container string) []kcore.EnvVar {
 			})
 		}
 
+		if api.Predictor.Batching != nil {
+			envVars = append(envVars,
+				kcore.EnvVar{
+					Name:  "CORTEX_MAX_BATCH_SIZE",
+					Value: s.Int32(api.Predictor.Batching.MaxBatchSize),
+				},
+				kcore.EnvVar{
+					Name:  "CORTEX_BATCH_INTERVAL",
+					Value: s.Float64(api.Predictor.Batching.BatchInterval.Seconds()),
+				},
+			)
+		}
+
+		if parallelism := api.Compute.Parallelism; parallelism != nil {
+			envVars = append(envVars,
+				kcore.EnvVar{
+					Name:  "CORTEX_TENSOR_PARALLEL_SIZE",
+					Value: s.Int64(parallelism.Tensor),
+				},
+				kcore.EnvVar{
+					Name:  "CORTEX_PIPELINE_PARALLEL_SIZE",
+					Value: s.Int64(parallelism.Pipeline),
+				},
+			)
+			if parallelism.IsMultiNode() {
+				envVars = append(envVars, parallelGroupEnvVars(api)...)
+			} else {
+				envVars = append(envVars, kcore.EnvVar{
+					// the ranks communicate over NVLink/PCIe and shared memory, so NCCL doesn't need to discover the pod's network interfaces
+					Name:  "NCCL_SOCKET_IFNAME",
+					Value: "lo",
+				})
+			}
+		}
+
+		if api.Networking.VersionPinning {
+			envVars = append(envVars, kcore.EnvVar{
+				Name:  "CORTEX_VERSION_PINNING",
+				Value: "true",
+			})
+		}
+
+		if api.Predictor.OnShutdown != nil {
+			envVars = append(envVars, kcore.EnvVar{
+				Name:  "CORTEX_SHUTDOWN_TIMEOUT",
+				Value: s.Float64(api.Predictor.OnShutdown.Timeout.Seconds()),
+			})
+		}
+
+		if api.Predictor.Explainer != nil {
+			envVars = append(envVars,
+				kcore.EnvVar{
+					Name:  "CORTEX_EXPLAINER_URL",
+					Value: "http://localhost:" + _explainerPortStr + "/explain",
+				},
+				kcore.EnvVar{
+					Name:  "CORTEX_EXPLAINER_TIMEOUT",
+					Value: s.Float64(api.Predictor.Explainer.Timeout.Seconds()),
+				},
+			)
+		}
+
+		if api.PayloadLogging != nil {
+			envVars = append(envVars,
+				kcore.EnvVar{
+					Name:  "CORTEX_PAYLOAD_LOG_ROOT",
+					Value: spec.PayloadLogRoot(api.Name),
+				},
+				kcore.EnvVar{
+					Name:  "CORTEX_PAYLOAD_LOG_SAMPLE_RATE",
+					Value: s.Float64(api.PayloadLogging.SampleRate),
+				},
+			)
+		}
+
+		if api.FeatureStore != nil {
+			envVars = append(envVars, featureStoreEnvVars(api.FeatureStore)...)
+		}
+
+		if stream := api.Stream; stream != nil {
+			envVars = append(envVars,
+				kcore.EnvVar{
+					Name:  "CORTEX_STREAM_SOURCE",
+					Value: stream.Source.String(),
+				},
+				kcore.EnvVar{
+					Name:  "CORTEX_STREAM_INPUT",
+					Value: stream.Input,
+				},
+				kcore.EnvVar{
+					Name:  "CORTEX_STREAM_BATCH_SIZE",
+					Value: s.Int32(stream.BatchSize),
+				},
+				kcore.EnvVar{
+					Name:  "CORTEX_STREAM_MAX_RETRIES",
+					Value: s.Int32(stream.MaxRetries),
+				},
+			)
+			if stream.RecordTimeout != nil {
+				envVars = append(envVars, kcore.EnvVar{
+					Name:  "CORTEX_STREAM_RECORD_TIMEOUT",
+					Value: s.Float64(stream.RecordTimeout.Seconds()),
+				})
+			}
+			if stream.DeadLetterPrefix != nil {
+				envVars = append(envVars, kcore.EnvVar{
+					Name:  "CORTEX_STREAM_DEAD_LETTER_PREFIX",
+					Value: *stream.DeadLetterPrefix,
+				})
+			}
+			if len(stream.Brokers) > 0 {
+				envVars = append(envVars, kcore.EnvVar{
+					Name:  "CORTEX_STREAM_BROKERS",
+					Value: strings.Join(stream.Brokers, ","),
+				})
+			}
+			if stream.Output != nil {
+				envVars = append(envVars, kcore.EnvVar{
+					Name:  "CORTEX_STREAM_OUTPUT",
+					Value: *stream.Output,
+				})
+			}
+			if stream.ConsumerGroup != nil {
+				envVars = append(envVars, kcore.EnvVar{
+					Name:  "CORTEX_STREAM_CONSUMER_GROUP",
+					Value: *stream.ConsumerGroup,
+				})
+			}
+			if stream.Source == userconfig.KinesisStreamSourceType {
+				envVars = append(envVars, kcore.EnvVar{
+					Name:  "CORTEX_STREAM_LEASE_TABLE",
+					Value: kinesisLeaseTable(),
+				})
+			}
+			if stream.S3Trigger != nil {
+				envVars = append(envVars, kcore.EnvVar{
+					Name:  "CORTEX_STREAM_S3_TRIGGER",
+					Value: "true",
+				})
+			}
+			if callback := stream.Callback; callback != nil {
+				envVars = append(envVars, kcore.EnvVar{
+					Name:  "CORTEX_STREAM_CALLBACK_MAX_RETRIES",
+					Value: s.Int32(callback.MaxRetries),
+				})
+				if callback.URL != nil {
+					envVars = append(envVars, kcore.EnvVar{
+						Name:  "CORTEX_STREAM_CALLBACK_URL",
+						Value: *callback.URL,
+					})
+				}
+				if callback.SNSTopic != nil {
+					envVars = append(envVars, kcore.EnvVar{
+						Name:  "CORTEX_STREAM_CALLBACK_SNS_TOPIC",
+						Value: *callback.SNSTopic,
+					})
+				}
+				if callback.SigningKeyEnv != nil {
+					envVars = append(envVars, kcore.EnvVar{
+						Name:  "CORTEX_STREAM_CALLBACK_SIGNING_KEY_ENV",
+						Value: *callback.SigningKeyEnv,
+					})
+				}
+			}
+		}
+
 		if api.Predictor.Type == userconfig.ONNXPredictorType {
 			envVars = append(envVars,
 				kcore.EnvVar{
@@ -673,6 +1390,29 @@ func getEnvVars(api *spec.API, container string) []kcore.EnvVar {
 					Value: strings.Join(api.ModelNames(), ","),
 				},
 			)
+
+			if onnxConfig := api.Predictor.ONNXRuntimeConfig; onnxConfig != nil {
+				if len(onnxConfig.ExecutionProviders) > 0 {
+					envVars = append(envVars, kcore.EnvVar{
+						Name:  "CORTEX_ONNX_EXECUTION_PROVIDERS",
+						Value: strings.Join(onnxConfig.ExecutionProviders, ","),
+					})
+				}
+				envVars = append(envVars,
+					kcore.EnvVar{
+						Name:  "CORTEX_ONNX_INTRA_OP_NUM_THREADS",
+						Value: s.Int32(onnxConfig.IntraOpNumThreads),
+					},
+					kcore.EnvVar{
+						Name:  "CORTEX_ONNX_INTER_OP_NUM_THREADS",
+						Value: s.Int32(onnxConfig.InterOpNumThreads),
+					},
+					kcore.EnvVar{
+						Name:  "CORTEX_ONNX_GRAPH_OPTIMIZATION_LEVEL",
+						Value: onnxConfig.GraphOptimizationLevel.String(),
+					},
+				)
+			}
 		}
 
 		if api.Predictor.Type == userconfig.TensorFlowPredictorType {
@@ -694,60 +1434,68 @@ func getEnvVars(api *spec.API, container string) []kcore.EnvVar {
 					Value: _tfServingHost,
 				},
 			)
+			if api.Predictor.TensorFlowServingConfig != nil && api.Predictor.TensorFlowServingConfig.ModelConfig != nil {
+				envVars = append(envVars, kcore.EnvVar{
+					Name:  "CORTEX_TF_MODEL_CONFIG",
+					Value: *api.Predictor.TensorFlowServingConfig.ModelConfig,
+				})
+			}
 		}
-	}
 
-	if api.Compute.Inf > 0 {
-		if (api.Predictor.Type == userconfig.PythonPredictorType && container == _apiContainerName) ||
-			(api.Predictor.Type == userconfig.TensorFlowPredictorType && container == _tfServingContainerName) {
+		if api.Predictor.Type == userconfig.LLMPredictorType {
 			envVars = append(envVars,
 				kcore.EnvVar{
-					Name:  "NEURONCORE_GROUP_SIZES",
-					Value: s.Int64(api.Compute.Inf * consts.NeuronCoresPerInf / int64(api.Autoscaling.WorkersPerReplica)),
+					Name:  "CORTEX_LLM_SERVER",
+					Value: api.Predictor.LLMServingConfig.Server.String(),
 				},
 				kcore.EnvVar{
-					Name:  "NEURON_RTD_ADDRESS",
-					Value: fmt.Sprintf("unix:%s", _neuronRTDSocket),
+					Name:  "CORTEX_LLM_SERVER_PORT",
+					Value: _llmServerPortStr,
 				},
 			)
 		}
+	}
 
-		if api.Predictor.Type == userconfig.TensorFlowPredictorType {
-			if container == _tfServingContainerName {
-				envVars = append(envVars,
-					kcore.EnvVar{
-						Name:  "TF_WORKERS",
-						Value: s.Int32(api.Autoscaling.WorkersPerReplica),
-					},
-					kcore.EnvVar{
-						Name:  "CORTEX_TF_BASE_SERVING_PORT",
-						Value: _tfBaseServingPortStr,
-					},
-					kcore.EnvVar{
-						Name:  "CORTEX_MODEL_DIR",
-						Value: path.Join(_emptyDirMountPath, "model"),
-					},
-					kcore.EnvVar{
-						Name:  "TF_EMPTY_MODEL_CONFIG",
-						Value: _tfServingEmptyModelConfig,
-					},
-				)
-			}
-			if container == _apiContainerName {
-				envVars = append(envVars,
-					kcore.EnvVar{
-						Name:  "CORTEX_MULTIPLE_TF_SERVERS",
-						Value: "yes",
-					},
-					kcore.EnvVar{
-						Name:  "CORTEX_ACTIVE_NEURON",
-						Value: "yes",
-					},
-				)
-			}
+	// the explainer loads the predictor's config from the API spec, and imports the explainer from the project
+	if container == _explainerContainerName {
+		envVars = append(envVars,
+			kcore.EnvVar{
+				Name:  "CORTEX_API_SPEC",
+				Value: config.Bucket.Path(api.Key),
+			},
+			kcore.EnvVar{
+				Name:  "CORTEX_CACHE_DIR",
+				Value: _specCacheDir,
+			},
+			kcore.EnvVar{
+				Name:  "CORTEX_PROJECT_DIR",
+				Value: path.Join(_emptyDirMountPath, "project"),
+			},
+			kcore.EnvVar{
+				Name:  "CORTEX_EXPLAINER_PORT",
+				Value: _explainerPortStr,
+			},
+		)
+		if api.Predictor.PythonPath != nil {
+			envVars = append(envVars, kcore.EnvVar{
+				Name:  "PYTHON_PATH",
+				Value: path.Join(_emptyDirMountPath, "project", *api.Predictor.PythonPath),
+			})
 		}
 	}
 
+	if container == _llmServerContainerName && api.Compute.Parallelism != nil {
+		// the server's ranks run in the same container, so NCCL doesn't need to discover the pod's network interfaces
+		envVars = append(envVars, kcore.EnvVar{
+			Name:  "NCCL_SOCKET_IFNAME",
+			Value: "lo",
+		})
+	}
+
+	if acc := getAccelerator(api); acc != nil {
+		envVars = append(envVars, acc.envVars(api, container)...)
+	}
+
 	return envVars
 }
 
@@ -759,7 +1507,10 @@ func tensorflowServingContainer(api *spec.API, volumeMounts []kcore.VolumeMount,
 		},
 	}
 
-	if api.Compute.Inf > 0 {
+	acc := getAccelerator(api)
+	modelServerPerWorker := acc != nil && acc.modelServerPerWorker()
+
+	if modelServerPerWorker {
 		numPorts := api.Autoscaling.WorkersPerReplica
 		for i := int32(1); i < numPorts; i++ {
 			ports = append(ports, kcore.ContainerPort{
@@ -768,12 +1519,17 @@ func tensorflowServingContainer(api *spec.API, volumeMounts []kcore.VolumeMount,
 		}
 	}
 
-	if api.Compute.Inf == 0 {
-		// the entrypoint is different for Inferentia-based APIs
+	if !modelServerPerWorker {
+		// the entrypoint is different for accelerators which run a server per worker (e.g. Inferentia)
 		args = []string{
 			"--port=" + _tfBaseServingPortStr,
 			"--model_config_file=" + _tfServingEmptyModelConfig,
 		}
+		args = append(args, tfServingExtraArgs(api)...)
+	}
+
+	if api.Predictor.Batching != nil {
+		volumeMounts = append(volumeMounts, k8s.ConfigMapVolumeMount(_tfServingBatchingVolumeName, _tfServingBatchingConfigDir))
 	}
 
 	var probeHandler kcore.Handler
@@ -795,11 +1551,11 @@ func tensorflowServingContainer(api *spec.API, volumeMounts []kcore.VolumeMount,
 
 	return &kcore.Container{
 		Name:            _tfServingContainerName,
-		Image:           api.Predictor.TensorFlowServingImage,
-		ImagePullPolicy: kcore.PullAlways,
+		Image:           api.PinnedImage(api.Predictor.TensorFlowServingImage),
+		ImagePullPolicy: kcore.PullPolicy(api.Predictor.ImagePullPolicy.String()),
 		Args:            args,
 		Env:             getEnvVars(api, _tfServingContainerName),
-		EnvFrom:         _baseEnvVars,
+		EnvFrom:         baseEnvFrom(),
 		VolumeMounts:    volumeMounts,
 		ReadinessProbe: &kcore.Probe{
 			InitialDelaySeconds: 5,
@@ -814,51 +1570,432 @@ func tensorflowServingContainer(api *spec.API, volumeMounts []kcore.VolumeMount,
 	}
 }
 
-func neuronRuntimeDaemonContainer(api *spec.API, volumeMounts []kcore.VolumeMount) *kcore.Container {
-	totalHugePages := api.Compute.Inf * _hugePagesMemPerInf
+// tfServingExtraArgs returns the tensorflow_model_server arguments which configure batching, followed by the user's flags
+func tfServingExtraArgs(api *spec.API) []string {
+	var args []string
+	if api.Predictor.Batching != nil {
+		args = append(args,
+			"--enable_batching=true",
+			"--batching_parameters_file="+path.Join(_tfServingBatchingConfigDir, _tfServingBatchingConfigKey),
+		)
+	}
+	if api.Predictor.TensorFlowServingConfig != nil {
+		args = append(args, api.Predictor.TensorFlowServingConfig.FlagArgs()...)
+	}
+	return args
+}
+
+// tfServingBatchingConfigMap holds TensorFlow Serving's batching parameters (in protobuf text format), which are
+// mounted into the TensorFlow Serving container
+func tfServingBatchingConfigMap(api *spec.API) *kcore.ConfigMap {
+	batching := api.Predictor.Batching
+	maxEnqueuedBatches := libmath.MaxInt64(1, int64(math.Ceil(float64(api.Autoscaling.ReplicaConcurrencyLimit())/float64(batching.MaxBatchSize))))
+
+	var params strings.Builder
+	params.WriteString(fmt.Sprintf("max_batch_size { value: %d }\n", batching.MaxBatchSize))
+	params.WriteString(fmt.Sprintf("batch_timeout_micros { value: %d }\n", batching.BatchInterval.Microseconds()))
+	params.WriteString(fmt.Sprintf("max_enqueued_batches { value: %d }\n", maxEnqueuedBatches))
+
+	return k8s.ConfigMap(&k8s.ConfigMapSpec{
+		Name: k8sName(api.Name),
+		Data: map[string]string{
+			_tfServingBatchingConfigKey: params.String(),
+		},
+		Annotations: apiAnnotations(api, nil),
+		Labels: apiLabels(api, map[string]string{
+			"apiName": api.Name,
+		}),
+	})
+}
+
+// userInitContainers returns the init containers which are declared in the API's predictor; they have access to the
+// downloaded project (and the scratch volume, if any), and replicas don't start until all of them have succeeded
+func userInitContainers(api *spec.API) []kcore.Container {
+	volumeMounts := append([]kcore.VolumeMount{}, _defaultVolumeMounts...)
+	if api.Compute.ScratchVolume != nil {
+		volumeMounts = append(volumeMounts, k8s.PVCVolumeMount(_scratchVolumeName, api.Compute.ScratchVolume.MountPath))
+	}
+
+	var initContainers []kcore.Container
+	for _, initContainer := range api.Predictor.InitContainers {
+		image := apiImage(api)
+		if initContainer.Image != "" {
+			image = api.PinnedImage(initContainer.Image)
+		}
+
+		envVars := append(getEnvVars(api, initContainer.Name), kcore.EnvVar{
+			Name:  "CORTEX_PROJECT_DIR",
+			Value: path.Join(_emptyDirMountPath, "project"),
+		})
+		for _, name := range maps.StrMapSortedKeys(initContainer.Env) {
+			envVars = append(envVars, kcore.EnvVar{
+				Name:  name,
+				Value: initContainer.Env[name],
+			})
+		}
+
+		resources := kcore.ResourceRequirements{
+			Requests: kcore.ResourceList{},
+			Limits:   kcore.ResourceList{},
+		}
+		if initContainer.CPU != nil {
+			resources.Requests[kcore.ResourceCPU] = initContainer.CPU.Quantity
+			resources.Limits[kcore.ResourceCPU] = initContainer.CPU.Quantity
+		}
+		if initContainer.Mem != nil {
+			resources.Requests[kcore.ResourceMemory] = initContainer.Mem.Quantity
+			resources.Limits[kcore.ResourceMemory] = initContainer.Mem.Quantity
+		}
+
+		initContainers = append(initContainers, kcore.Container{
+			Name:            initContainer.Name,
+			Image:           image,
+			ImagePullPolicy: kcore.PullPolicy(api.Predictor.ImagePullPolicy.String()),
+			Command:         initContainer.Command,
+			WorkingDir:      path.Join(_emptyDirMountPath, "project"),
+			Env:             envVars,
+			EnvFrom:         apiEnvFrom(api),
+			VolumeMounts:    volumeMounts,
+			Resources:       resources,
+		})
+	}
+
+	return initContainers
+}
+
+// scratchVolumeClaimSpec is shared by all of the API's replicas (the claim outlives them, so that its contents are kept across restarts and updates)
+func scratchVolumeClaimSpec(api *spec.API) *kcore.PersistentVolumeClaim {
+	scratchVolume := api.Compute.ScratchVolume
+
+	return k8s.PVC(&k8s.PVCSpec{
+		Name:             scratchVolumeClaimName(api.Name),
+		StorageClassName: scratchVolume.StorageClass,
+		AccessMode:       scratchVolumeAccessMode(scratchVolume.AccessMode),
+		Size:             scratchVolume.Size.Quantity,
+		Annotations:      apiAnnotations(api, nil),
+		Labels: apiLabels(api, map[string]string{
+			"apiName": api.Name,
+		}),
+	})
+}
+
+func scratchVolumeAccessMode(accessMode userconfig.AccessModeType) kcore.PersistentVolumeAccessMode {
+	if accessMode == userconfig.ReadWriteManyAccessModeType {
+		return kcore.ReadWriteMany
+	}
+	return kcore.ReadWriteOnce
+}
+
+func scratchVolumeClaimName(apiName string) string {
+	return k8sName(apiName) + "-scratch"
+}
+
+// featureStoreEnvVars configures the feature store client of the API container (the password is read from the secret when the container starts)
+func featureStoreEnvVars(featureStore *userconfig.FeatureStore) []kcore.EnvVar {
+	envVars := []kcore.EnvVar{
+		{
+			Name:  "CORTEX_FEATURE_STORE_TYPE",
+			Value: featureStore.Type.String(),
+		},
+		{
+			Name:  "CORTEX_FEATURE_STORE_URL",
+			Value: featureStore.URL,
+		},
+	}
+
+	if featureStore.Project != nil {
+		envVars = append(envVars, kcore.EnvVar{
+			Name:  "CORTEX_FEATURE_STORE_PROJECT",
+			Value: *featureStore.Project,
+		})
+	}
+
+	if featureStore.KeyPrefix != nil {
+		envVars = append(envVars, kcore.EnvVar{
+			Name:  "CORTEX_FEATURE_STORE_KEY_PREFIX",
+			Value: *featureStore.KeyPrefix,
+		})
+	}
+
+	if featureStore.Secret != nil {
+		envVars = append(envVars, kcore.EnvVar{
+			Name: "CORTEX_FEATURE_STORE_PASSWORD",
+			ValueFrom: &kcore.EnvVarSource{
+				SecretKeyRef: &kcore.SecretKeySelector{
+					LocalObjectReference: kcore.LocalObjectReference{
+						Name: *featureStore.Secret,
+					},
+					Key: _featureStorePasswordSecretKey,
+				},
+			},
+		})
+	}
+
+	if featureStore.Cache != nil {
+		envVars = append(envVars,
+			kcore.EnvVar{
+				Name:  "CORTEX_FEATURE_STORE_CACHE_URL",
+				Value: "redis://localhost:" + s.Int32(_featureStoreCachePort),
+			},
+			kcore.EnvVar{
+				Name:  "CORTEX_FEATURE_STORE_CACHE_TTL",
+				Value: s.Int64(int64(featureStore.Cache.TTL.Seconds())),
+			},
+		)
+	}
+
+	return envVars
+}
+
+// featureStoreCacheContainer runs a Redis server which only keeps features in memory, and evicts the least recently used
+// features once it reaches 90% of its memory (to leave room for Redis' own overhead)
+func featureStoreCacheContainer(api *spec.API) *kcore.Container {
+	mem := api.FeatureStore.Cache.Mem.Quantity
+
 	return &kcore.Container{
-		Name:            _neuronRTDContainerName,
-		Image:           config.Cluster.ImageNeuronRTD,
-		ImagePullPolicy: kcore.PullAlways,
-		SecurityContext: &kcore.SecurityContext{
-			Capabilities: &kcore.Capabilities{
-				Add: []kcore.Capability{
-					"SYS_ADMIN",
-					"IPC_LOCK",
+		Name:            _featureStoreCacheContainerName,
+		Image:           consts.DefaultImageRedis,
+		ImagePullPolicy: kcore.PullIfNotPresent,
+		Args: []string{
+			"redis-server",
+			"--port", s.Int32(_featureStoreCachePort),
+			"--bind", "127.0.0.1",
+			"--save", "",
+			"--appendonly", "no",
+			"--maxmemory", s.Int64(mem.Value() * 9 / 10),
+			"--maxmemory-policy", "allkeys-lru",
+		},
+		Ports: []kcore.ContainerPort{
+			{ContainerPort: _featureStoreCachePort},
+		},
+		ReadinessProbe: &kcore.Probe{
+			InitialDelaySeconds: 1,
+			TimeoutSeconds:      1,
+			PeriodSeconds:       5,
+			Handler: kcore.Handler{
+				TCPSocket: &kcore.TCPSocketAction{
+					Port: intstr.IntOrString{IntVal: _featureStoreCachePort},
 				},
 			},
 		},
-		VolumeMounts:   volumeMounts,
-		ReadinessProbe: socketExistsProbe(_neuronRTDSocket),
 		Resources: kcore.ResourceRequirements{
-			Requests: kcore.ResourceList{
-				"hugepages-2Mi":       *kresource.NewQuantity(totalHugePages, kresource.BinarySI),
-				"aws.amazon.com/infa": *kresource.NewQuantity(api.Compute.Inf, kresource.DecimalSI),
+			Requests: featureStoreCacheRequests(api.FeatureStore.Cache),
+			Limits: kcore.ResourceList{
+				kcore.ResourceMemory: mem,
+			},
+		},
+	}
+}
+
+// explainerContainer runs the user's explainer in the API's image (so that it has the project's dependencies), with its own
+// resources so that explanations don't compete with predictions
+func explainerContainer(api *spec.API) *kcore.Container {
+	return &kcore.Container{
+		Name:            _explainerContainerName,
+		Image:           apiImage(api),
+		ImagePullPolicy: kcore.PullPolicy(api.Predictor.ImagePullPolicy.String()),
+		Command:         []string{"/src/cortex/serve/run_explainer.sh"},
+		Env:             getEnvVars(api, _explainerContainerName),
+		EnvFrom:         apiEnvFrom(api),
+		VolumeMounts:    _defaultVolumeMounts,
+		Ports: []kcore.ContainerPort{
+			{ContainerPort: _explainerPortInt32},
+		},
+		ReadinessProbe: &kcore.Probe{
+			InitialDelaySeconds: 3,
+			TimeoutSeconds:      1,
+			PeriodSeconds:       5,
+			Handler: kcore.Handler{
+				TCPSocket: &kcore.TCPSocketAction{
+					Port: intstr.IntOrString{IntVal: _explainerPortInt32},
+				},
 			},
+		},
+		Resources: kcore.ResourceRequirements{
+			Requests: explainerRequests(api.Predictor.Explainer),
 			Limits: kcore.ResourceList{
-				"hugepages-2Mi":       *kresource.NewQuantity(totalHugePages, kresource.BinarySI),
-				"aws.amazon.com/infa": *kresource.NewQuantity(api.Compute.Inf, kresource.DecimalSI),
+				kcore.ResourceMemory: api.Predictor.Explainer.Mem.Quantity,
 			},
 		},
 	}
 }
 
+func explainerRequests(explainer *userconfig.Explainer) kcore.ResourceList {
+	return kcore.ResourceList{
+		kcore.ResourceCPU:    explainer.CPU.Quantity,
+		kcore.ResourceMemory: explainer.Mem.Quantity,
+	}
+}
+
+func featureStoreCacheRequests(cache *userconfig.FeatureStoreCache) kcore.ResourceList {
+	return kcore.ResourceList{
+		kcore.ResourceCPU:    _featureStoreCacheCPURequest,
+		kcore.ResourceMemory: cache.Mem.Quantity,
+	}
+}
+
+func istioProxyRequests(mesh *userconfig.Mesh) kcore.ResourceList {
+	if mesh == nil {
+		return kcore.ResourceList{
+			kcore.ResourceCPU:    _istioProxyCPURequest,
+			kcore.ResourceMemory: _istioProxyMemRequest,
+		}
+	}
+	return kcore.ResourceList{
+		kcore.ResourceCPU:    mesh.SidecarCPU.Quantity,
+		kcore.ResourceMemory: mesh.SidecarMem.Quantity,
+	}
+}
+
+// sidecarRequests returns the resources which each of the API's replicas requests in addition to its compute request
+// (the request monitor's resources are taken out of the compute request, so they aren't included)
+func sidecarRequests(api *userconfig.API) kcore.ResourceList {
+	requests := kcore.ResourceList{}
+	if api.FeatureStore != nil && api.FeatureStore.Cache != nil {
+		addResources(requests, featureStoreCacheRequests(api.FeatureStore.Cache))
+	}
+	if api.Predictor.Explainer != nil {
+		addResources(requests, explainerRequests(api.Predictor.Explainer))
+	}
+	if inMesh(api) {
+		addResources(requests, istioProxyRequests(api.Networking.Mesh))
+	}
+	if api.Compute.Inf > 0 {
+		addResources(requests, neuronMonitorRequests())
+	}
+	return requests
+}
+
+// hasRequestMonitorSidecar returns true if API pods report their in-flight requests with the request monitor sidecar, rather
+// than from within the API container (lightweight and gcp clusters don't have cloudwatch for either to report them to)
+func hasRequestMonitorSidecar() bool {
+	return config.Cluster.HasAWSResources() && config.Cluster.RequestMonitor == clusterconfig.SidecarRequestMonitorMode
+}
+
+// hasInProcessRequestMonitor returns true if API containers report their in-flight requests themselves
+func hasInProcessRequestMonitor() bool {
+	return config.Cluster.HasAWSResources() && config.Cluster.RequestMonitor == clusterconfig.InProcessRequestMonitorMode
+}
+
+// requestMonitorRequests returns the request monitor sidecar's requests (configured in the cluster config)
+func requestMonitorRequests() kcore.ResourceList {
+	return kcore.ResourceList{
+		kcore.ResourceCPU:    kresource.MustParse(config.Cluster.RequestMonitorCPU),
+		kcore.ResourceMemory: kresource.MustParse(config.Cluster.RequestMonitorMem),
+	}
+}
+
+// requestMonitorFlushInterval is the period of the in-flight requests metric which the request monitor publishes
+func requestMonitorFlushInterval() time.Duration {
+	return time.Duration(config.Cluster.RequestMonitorFlushInterval) * time.Second
+}
+
 func requestMonitorContainer(api *spec.API) *kcore.Container {
 	return &kcore.Container{
-		Name:            "request-monitor",
+		Name:            _requestMonitorContainerName,
 		Image:           config.Cluster.ImageRequestMonitor,
 		ImagePullPolicy: kcore.PullAlways,
-		Args:            []string{api.Name, config.Cluster.ClusterName},
-		EnvFrom:         _baseEnvVars,
+		Args:            []string{api.Name, config.Cluster.ClusterName, s.Int64(config.Cluster.RequestMonitorFlushInterval)},
+		EnvFrom:         baseEnvFrom(),
 		VolumeMounts:    _defaultVolumeMounts,
 		ReadinessProbe:  fileExistsProbe(_requestMonitorReadinessFile),
 		Resources: kcore.ResourceRequirements{
-			Requests: kcore.ResourceList{
-				kcore.ResourceCPU:    _requestMonitorCPURequest,
-				kcore.ResourceMemory: _requestMonitorMemRequest,
+			Requests: requestMonitorRequests(),
+		},
+	}
+}
+
+// llmServerContainer runs vLLM's OpenAI-compatible server or TGI's launcher (using their images' entrypoints), which the API container forwards requests to
+func llmServerContainer(api *spec.API, volumeMounts []kcore.VolumeMount) *kcore.Container {
+	llmConfig := api.Predictor.LLMServingConfig
+	modelPath := path.Join(_emptyDirMountPath, "model")
+
+	tensorParallelSize := int64(1)
+	pipelineParallelSize := int64(1)
+	if api.Compute.Parallelism != nil {
+		tensorParallelSize = api.Compute.Parallelism.Tensor
+		pipelineParallelSize = api.Compute.Parallelism.Pipeline
+	}
+
+	var args []string
+	switch llmConfig.Server {
+	case userconfig.TGIServerType:
+		args = []string{
+			"--model-id=" + modelPath,
+			"--port=" + _llmServerPortStr,
+			"--num-shard=" + s.Int64(tensorParallelSize),
+			"--cuda-memory-fraction=" + s.Float64(llmConfig.GPUMemoryUtilization),
+		}
+		if llmConfig.MaxTokens != nil {
+			args = append(args, "--max-total-tokens="+s.Int64(*llmConfig.MaxTokens))
+		}
+	default:
+		args = []string{
+			"--model=" + modelPath,
+			"--served-model-name=" + api.Name,
+			"--port=" + _llmServerPortStr,
+			"--tensor-parallel-size=" + s.Int64(tensorParallelSize),
+			"--pipeline-parallel-size=" + s.Int64(pipelineParallelSize),
+			"--gpu-memory-utilization=" + s.Float64(llmConfig.GPUMemoryUtilization),
+		}
+		if llmConfig.MaxTokens != nil {
+			args = append(args, "--max-model-len="+s.Int64(*llmConfig.MaxTokens))
+		}
+	}
+	args = append(args, llmConfig.Args...)
+
+	return &kcore.Container{
+		Name:            _llmServerContainerName,
+		Image:           api.PinnedImage(llmConfig.Image),
+		ImagePullPolicy: kcore.PullPolicy(api.Predictor.ImagePullPolicy.String()),
+		Args:            args,
+		Env:             getEnvVars(api, _llmServerContainerName),
+		EnvFrom:         apiEnvFrom(api), // e.g. for a Hugging Face token
+		VolumeMounts:    volumeMounts,
+		ReadinessProbe: &kcore.Probe{
+			InitialDelaySeconds: 5,
+			TimeoutSeconds:      5,
+			PeriodSeconds:       5,
+			SuccessThreshold:    1,
+			FailureThreshold:    2,
+			Handler: kcore.Handler{
+				HTTPGet: &kcore.HTTPGetAction{
+					Path: "/health",
+					Port: intstr.FromInt(int(_llmServerPortInt32)),
+				},
 			},
 		},
+		Resources: kcore.ResourceRequirements{
+			Requests: kcore.ResourceList{},
+			Limits:   kcore.ResourceList{},
+		},
+		Ports: []kcore.ContainerPort{
+			{ContainerPort: _llmServerPortInt32},
+		},
+	}
+}
+
+// usesShmVolume returns true if the API's containers mount a memory-backed volume at /dev/shm
+// (LLM servers use shared memory to communicate between their processes, even if the model isn't sharded)
+func usesShmVolume(api *spec.API) bool {
+	return api.Compute.ShmSize != nil || api.Compute.Parallelism != nil || api.Predictor.Type == userconfig.LLMPredictorType
+}
+
+// returns the predictor image, or the image which extends it with the project's dependencies if prebuild_dependencies is set
+// the volume which the downloader writes the project and models to; it is capped at the API's ephemeral storage request (if any)
+func emptyDirVolume(api *spec.API) kcore.Volume {
+	volume := k8s.EmptyDirVolume(_emptyDirVolumeName)
+	if api.Compute.EphemeralStorage != nil {
+		volume.EmptyDir.SizeLimit = k8s.QuantityPtr(api.Compute.EphemeralStorage.Quantity.DeepCopy())
+	}
+	return volume
+}
+
+func apiImage(api *spec.API) string {
+	if api.DependencyImage != "" {
+		return api.DependencyImage
 	}
+	return api.PinnedImage(api.Predictor.Image)
 }
 
 func k8sName(apiName string) string {
@@ -908,46 +2045,110 @@ func socketExistsProbe(socketName string) *kcore.Probe {
 	}
 }
 
-var _tolerations = []kcore.Toleration{
+const _hideAcceptEncodingLua = `
+local compressed_endpoints = {
+%s}
+
+function envoy_on_request(request_handle)
+  local headers = request_handle:headers()
+  local path = headers:get(":path") or ""
+  local query_start = string.find(path, "?", 1, true)
+  if query_start ~= nil then
+    path = string.sub(path, 1, query_start - 1)
+  end
+  local accept_encoding = headers:get("accept-encoding")
+  if accept_encoding ~= nil and not compressed_endpoints[path] then
+    headers:remove("accept-encoding")
+    headers:add("x-cortex-accept-encoding", accept_encoding)
+  end
+end
+`
+
+const _restoreAcceptEncodingLua = `
+function envoy_on_request(request_handle)
+  local headers = request_handle:headers()
+  local accept_encoding = headers:get("x-cortex-accept-encoding")
+  if accept_encoding ~= nil then
+    headers:remove("x-cortex-accept-encoding")
+    headers:add("accept-encoding", accept_encoding)
+  end
+end
+`
+
+const _maintenanceLua = `
+local responses = {
+%s}
+
+function envoy_on_request(request_handle)
+  local path = request_handle:headers():get(":path") or ""
+  local query_start = string.find(path, "?", 1, true)
+  if query_start ~= nil then
+    path = string.sub(path, 1, query_start - 1)
+  end
+  local response = responses[path]
+  if response ~= nil and (response.weight >= 100 or math.random(100) <= response.weight) then
+    request_handle:respond({[":status"] = response.status, ["content-type"] = response.content_type}, response.body)
+  end
+end
+`
+
+// responses with a "Cache-Control: no-transform" header (i.e. streamed responses) are never compressed
+var _compressedContentTypes = []string{
+	"application/json",
+	"application/javascript",
+	"text/plain",
+	"text/html",
+	"text/csv",
+}
+
+var _tolerations = append([]kcore.Toleration{
 	{
 		Key:      "workload",
 		Operator: kcore.TolerationOpEqual,
 		Value:    "true",
 		Effect:   kcore.TaintEffectNoSchedule,
 	},
-	{
-		Key:      "nvidia.com/gpu",
-		Operator: kcore.TolerationOpEqual,
-		Value:    "true",
-		Effect:   kcore.TaintEffectNoSchedule,
-	},
-	{
-		Key:      "aws.amazon.com/infa",
-		Operator: kcore.TolerationOpEqual,
-		Value:    "true",
-		Effect:   kcore.TaintEffectNoSchedule,
-	},
+}, acceleratorTolerations()...)
+
+// apiEnvFrom returns the sources of the API container's environment variables: cortex's, followed by the cluster's
+// api_env_config_maps and api_env_secrets, followed by the API's env_from (when a key is in multiple sources, the last one wins)
+func apiEnvFrom(api *spec.API) []kcore.EnvFromSource {
+	envFrom := baseEnvFrom()
+	for _, configMapName := range config.Cluster.APIEnvConfigMaps {
+		envFrom = append(envFrom, configMapEnvSource(configMapName))
+	}
+	for _, secretName := range config.Cluster.APIEnvSecrets {
+		envFrom = append(envFrom, secretEnvSource(secretName))
+	}
+	if api.Predictor.EnvFrom != nil {
+		for _, configMapName := range api.Predictor.EnvFrom.ConfigMaps {
+			envFrom = append(envFrom, configMapEnvSource(configMapName))
+		}
+		for _, secretName := range api.Predictor.EnvFrom.Secrets {
+			envFrom = append(envFrom, secretEnvSource(secretName))
+		}
+	}
+	return envFrom
 }
 
-var _baseEnvVars = []kcore.EnvFromSource{
-	{
+func configMapEnvSource(configMapName string) kcore.EnvFromSource {
+	return kcore.EnvFromSource{
 		ConfigMapRef: &kcore.ConfigMapEnvSource{
 			LocalObjectReference: kcore.LocalObjectReference{
-				Name: "env-vars",
+				Name: configMapName,
 			},
 		},
-	},
-	{
+	}
+}
+
+func secretEnvSource(secretName string) kcore.EnvFromSource {
+	return kcore.EnvFromSource{
 		SecretRef: &kcore.SecretEnvSource{
 			LocalObjectReference: kcore.LocalObjectReference{
-				Name: "aws-credentials",
+				Name: secretName,
 			},
 		},
-	},
-}
-
-var _defaultVolumes = []kcore.Volume{
-	k8s.EmptyDirVolume(_emptyDirVolumeName),
+	}
 }
 
 var _defaultVolumeMounts = []kcore.VolumeMount{
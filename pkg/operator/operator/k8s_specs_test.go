@@ -0,0 +1,332 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/storage"
+	"github.com/cortexlabs/cortex/pkg/types"
+	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/stretchr/testify/require"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+)
+
+// run `go test ./pkg/operator/operator -run TestDeploymentSpec -update` to regenerate the golden files
+var _updateGoldenFiles = flag.Bool("update", false, "update golden files")
+
+func testAPI(predictorType userconfig.PredictorType, compute userconfig.Compute) *spec.API {
+	return &spec.API{
+		API: &userconfig.API{
+			Name:     "iris-classifier",
+			Endpoint: pointer.String("iris-classifier"),
+			Predictor: &userconfig.Predictor{
+				Type:                   predictorType,
+				Image:                  "cortexlabs/" + predictorType.String() + "-predictor",
+				TensorFlowServingImage: "cortexlabs/tensorflow-serving",
+				ImagePullPolicy:        userconfig.AlwaysImagePullPolicyType,
+				Models: []*userconfig.ModelResource{
+					{
+						Name:  "iris",
+						Model: "s3://cortex-examples/iris/model",
+					},
+				},
+				Env: map[string]string{
+					"LOG_LEVEL": "info",
+				},
+			},
+			Networking: &userconfig.Networking{
+				APIGateway:  userconfig.PublicAPIGatewayType,
+				Compression: userconfig.NoneCompressionType,
+			},
+			Compute: &compute,
+			Autoscaling: &userconfig.Autoscaling{
+				MinReplicas:              1,
+				MaxReplicas:              10,
+				InitReplicas:             2,
+				WorkersPerReplica:        2,
+				ThreadsPerWorker:         1,
+				TargetReplicaConcurrency: pointer.Float64(2),
+				MaxReplicaConcurrency:    1024,
+				OverloadBehavior:         userconfig.QueueOverloadBehaviorType,
+			},
+			UpdateStrategy: &userconfig.UpdateStrategy{
+				MaxSurge:       "25%",
+				MaxUnavailable: "25%",
+			},
+		},
+		ID:           "apiid",
+		Key:          "apis/iris-classifier/spec.msgpack",
+		DeploymentID: "deploymentid",
+		ProjectKey:   "projects/projectid.zip",
+	}
+}
+
+func TestDeploymentSpec(t *testing.T) {
+	config.Cluster = &clusterconfig.InternalConfig{}
+	config.Cluster.ClusterName = "cortex"
+	config.Cluster.Provider = types.AWSProviderType
+	config.Cluster.Bucket = "cortex-bucket"
+	config.Bucket = storage.New(&config.Cluster.Config, nil, nil)
+	config.Cluster.ImageDownloader = "cortexlabs/downloader"
+	config.Cluster.ImageRequestMonitor = "cortexlabs/request-monitor"
+	config.Cluster.ImageNeuronRTD = "cortexlabs/neuron-rtd"
+	config.Cluster.ImageNeuronMonitor = "cortexlabs/neuron-monitor"
+	config.Cluster.RequestMonitor = clusterconfig.SidecarRequestMonitorMode
+	config.Cluster.RequestMonitorCPU = "10m"
+	config.Cluster.RequestMonitorMem = "10Mi"
+	config.Cluster.RequestMonitorFlushInterval = 10
+
+	cpuCompute := userconfig.Compute{
+		CPU: k8s.WrapQuantity(kresource.MustParse("1")),
+		Mem: k8s.WrapQuantity(kresource.MustParse("2Gi")),
+	}
+	gpuCompute := userconfig.Compute{
+		CPU: k8s.WrapQuantity(kresource.MustParse("1333m")),
+		GPU: 1,
+	}
+	infCompute := userconfig.Compute{
+		CPU: k8s.WrapQuantity(kresource.MustParse("1")),
+		Mem: k8s.WrapQuantity(kresource.MustParse("4Gi")),
+		Inf: 1,
+	}
+
+	spotCompute := userconfig.Compute{
+		CPU:              k8s.WrapQuantity(kresource.MustParse("1")),
+		Mem:              k8s.WrapQuantity(kresource.MustParse("2Gi")),
+		Spot:             pointer.Bool(true),
+		OnDemandFallback: true,
+	}
+
+	nodeGroupCompute := userconfig.Compute{
+		CPU:       k8s.WrapQuantity(kresource.MustParse("1")),
+		Mem:       k8s.WrapQuantity(kresource.MustParse("2Gi")),
+		GPU:       1,
+		NodeGroup: pointer.String("gpu"),
+	}
+
+	pinnedAPI := testAPI(userconfig.ONNXPredictorType, cpuCompute)
+	pinnedAPI.Pin(
+		map[string]string{"cortexlabs/onnx-predictor": "cortexlabs/onnx-predictor@sha256:3f6b0e1c52b0ce5a9c8f4a5b8ae2b7c0d4b2d6e3a1f0c9b8a7d6e5f4c3b2a190"},
+		map[string]string{"s3://cortex-examples/iris/model": "3HL4kqtJlcpXroDTDmjVBH40Nrjfkd"},
+	)
+
+	shedAPI := testAPI(userconfig.PythonPredictorType, cpuCompute)
+	shedAPI.Autoscaling.OverloadBehavior = userconfig.ShedOverloadBehaviorType
+	shedAPI.Autoscaling.MaxQueueLength = pointer.Int64(8)
+
+	batchingAPI := testAPI(userconfig.TensorFlowPredictorType, gpuCompute)
+	batchingAPI.Predictor.Batching = &userconfig.Batching{
+		MaxBatchSize:  8,
+		BatchInterval: 100 * time.Millisecond,
+	}
+	batchingAPI.Autoscaling.ThreadsPerWorker = 8
+
+	tfsConfigAPI := testAPI(userconfig.TensorFlowPredictorType, cpuCompute)
+	tfsConfigAPI.Predictor.TensorFlowServingConfig = &userconfig.TensorFlowServingConfig{
+		Flags: map[string]string{
+			"tensorflow_intra_op_parallelism": "4",
+			"tensorflow_inter_op_parallelism": "2",
+		},
+		ModelConfig: pointer.String("model_version_policy { all {} }"),
+	}
+
+	onnxConfigAPI := testAPI(userconfig.ONNXPredictorType, gpuCompute)
+	onnxConfigAPI.Predictor.ONNXRuntimeConfig = &userconfig.ONNXRuntimeConfig{
+		ExecutionProviders:     []string{"tensorrt", "cuda"},
+		IntraOpNumThreads:      4,
+		GraphOptimizationLevel: userconfig.ExtendedGraphOptimizationLevelType,
+	}
+
+	optimizedAPI := testAPI(userconfig.TensorFlowPredictorType, gpuCompute)
+	optimizedAPI.Predictor.ModelOptimization = &userconfig.ModelOptimization{
+		Precision: userconfig.FP16PrecisionType,
+	}
+	optimizedAPI.OptimizedModels = map[string]string{
+		optimizedAPI.Predictor.Models[0].Model: "s3://cortex-bucket/optimized_models/g4dn/3f6b0e1c52b0ce5a9c8f4a5b8ae2b7c0/model",
+	}
+
+	shmCompute := userconfig.Compute{
+		CPU:     k8s.WrapQuantity(kresource.MustParse("1")),
+		Mem:     k8s.WrapQuantity(kresource.MustParse("2Gi")),
+		ShmSize: k8s.WrapQuantity(kresource.MustParse("1Gi")),
+	}
+
+	storageCompute := userconfig.Compute{
+		CPU:              k8s.WrapQuantity(kresource.MustParse("1")),
+		EphemeralStorage: k8s.WrapQuantity(kresource.MustParse("20Gi")),
+	}
+
+	envFromAPI := testAPI(userconfig.PythonPredictorType, cpuCompute)
+	envFromAPI.Predictor.EnvFrom = &userconfig.EnvFrom{
+		ConfigMaps: []string{"team-a-config"},
+		Secrets:    []string{"team-a-credentials"},
+	}
+
+	prebuiltAPI := testAPI(userconfig.PythonPredictorType, cpuCompute)
+	prebuiltAPI.Predictor.PrebuildDependencies = true
+	prebuiltAPI.DependencyImage = "123456789012.dkr.ecr.us-west-2.amazonaws.com/cortex-cortex-dependencies:0d4c6f2b9a8e7d1c5b3a2f6e9d8c7b1a"
+
+	meshAPI := testAPI(userconfig.PythonPredictorType, cpuCompute)
+	meshAPI.Networking.Mesh = &userconfig.Mesh{
+		Enabled:    true,
+		SidecarCPU: k8s.WrapQuantity(kresource.MustParse("200m")),
+		SidecarMem: k8s.WrapQuantity(kresource.MustParse("256Mi")),
+	}
+
+	explainerAPI := testAPI(userconfig.PythonPredictorType, cpuCompute)
+	explainerAPI.Predictor.Explainer = &userconfig.Explainer{
+		Path:    "explainer.py",
+		CPU:     k8s.WrapQuantity(kresource.MustParse("500m")),
+		Mem:     k8s.WrapQuantity(kresource.MustParse("1Gi")),
+		Timeout: 30 * time.Second,
+	}
+
+	for name, api := range map[string]*spec.API{
+		"tensorflow-cpu":      testAPI(userconfig.TensorFlowPredictorType, cpuCompute),
+		"tensorflow-gpu":      testAPI(userconfig.TensorFlowPredictorType, gpuCompute),
+		"tensorflow-inf":      testAPI(userconfig.TensorFlowPredictorType, infCompute),
+		"tensorflow-batching": batchingAPI,
+		"tensorflow-config":   tfsConfigAPI,
+		"tensorflow-optimize": optimizedAPI,
+		"tensorflow-storage":  testAPI(userconfig.TensorFlowPredictorType, storageCompute),
+		"python-cpu":          testAPI(userconfig.PythonPredictorType, cpuCompute),
+		"python-gpu":          testAPI(userconfig.PythonPredictorType, gpuCompute),
+		"python-inf":          testAPI(userconfig.PythonPredictorType, infCompute),
+		"python-spot":         testAPI(userconfig.PythonPredictorType, spotCompute),
+		"python-node-group":   testAPI(userconfig.PythonPredictorType, nodeGroupCompute),
+		"python-shed":         shedAPI,
+		"python-prebuilt":     prebuiltAPI,
+		"python-shm":          testAPI(userconfig.PythonPredictorType, shmCompute),
+		"python-env-from":     envFromAPI,
+		"python-mesh":         meshAPI,
+		"python-explainer":    explainerAPI,
+		"onnx-cpu":            testAPI(userconfig.ONNXPredictorType, cpuCompute),
+		"onnx-gpu":            testAPI(userconfig.ONNXPredictorType, gpuCompute),
+		"onnx-pinned":         pinnedAPI,
+		"onnx-runtime-config": onnxConfigAPI,
+	} {
+		deploymentBytes, err := yaml.Marshal(deploymentSpec(api, nil))
+		require.NoError(t, err)
+
+		goldenFile := filepath.Join("testdata", name+".golden.yaml")
+		if *_updateGoldenFiles {
+			require.NoError(t, ioutil.WriteFile(goldenFile, deploymentBytes, 0644))
+		}
+
+		expected, err := ioutil.ReadFile(goldenFile)
+		require.NoError(t, err)
+		require.Equal(t, string(expected), string(deploymentBytes), name)
+	}
+
+	// re-deploying an unchanged API must not change its deployment (which would roll out its replicas)
+	envAPI := testAPI(userconfig.PythonPredictorType, cpuCompute)
+	envAPI.Predictor.Env = map[string]string{}
+	for _, name := range []string{"A", "B", "C", "D", "E", "F", "G", "H"} {
+		envAPI.Predictor.Env[name] = strings.ToLower(name)
+	}
+	specHash := deploymentSpec(envAPI, nil).Annotations[_specHashAnnotationKey]
+	for i := 0; i < 10; i++ {
+		require.Equal(t, specHash, deploymentSpec(envAPI, nil).Annotations[_specHashAnnotationKey])
+	}
+
+	// with the in-process request monitor, the API container reports its in-flight requests and receives the full compute request
+	config.Cluster.RequestMonitor = clusterconfig.InProcessRequestMonitorMode
+	defer func() { config.Cluster.RequestMonitor = clusterconfig.SidecarRequestMonitorMode }()
+
+	deploymentBytes, err := yaml.Marshal(deploymentSpec(testAPI(userconfig.PythonPredictorType, cpuCompute), nil))
+	require.NoError(t, err)
+
+	goldenFile := filepath.Join("testdata", "python-in-process-request-monitor.golden.yaml")
+	if *_updateGoldenFiles {
+		require.NoError(t, ioutil.WriteFile(goldenFile, deploymentBytes, 0644))
+	}
+
+	expected, err := ioutil.ReadFile(goldenFile)
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(deploymentBytes))
+}
+
+// decodeLuaString decodes the escapes of a Lua 5.1 string literal which are written by luaString
+func decodeLuaString(t *testing.T, literal string) string {
+	require.True(t, len(literal) >= 2 && literal[0] == '"' && literal[len(literal)-1] == '"')
+	var sb strings.Builder
+	for i := 1; i < len(literal)-1; i++ {
+		if literal[i] != '\\' {
+			require.NotEqual(t, byte('"'), literal[i], "unescaped quote in %s", literal)
+			sb.WriteByte(literal[i])
+			continue
+		}
+		if literal[i+1] == '"' || literal[i+1] == '\\' {
+			sb.WriteByte(literal[i+1])
+			i++
+			continue
+		}
+		b, err := strconv.Atoi(literal[i+1 : i+4])
+		require.NoError(t, err)
+		require.True(t, b <= 255)
+		sb.WriteByte(byte(b))
+		i += 3
+	}
+	return sb.String()
+}
+
+func TestLuaString(t *testing.T) {
+	require.Equal(t, `"maintenance"`, luaString("maintenance"))
+	require.Equal(t, `"say \"hi\" \\o/"`, luaString(`say "hi" \o/`))
+	require.Equal(t, `"a\010b\0001"`, luaString("a\nb\x001")) // escapes are 3 digits, so the 1 isn't part of the escape
+	require.Equal(t, `"caf\195\169"`, luaString("café"))
+
+	for _, str := range []string{
+		"down for maintenance",
+		"línea 1\r\nlínea 2\ttab\x00nul\x7fdel\x1besc",
+		"メンテナンス中です 🚧",
+		"\u2028line separator\u2029",
+		`{"error": "maintenance", "until": "2020-01-01T00:00:00Z"}`,
+		"\xff\xfe invalid utf-8",
+	} {
+		literal := luaString(str)
+		for i := 0; i < len(literal); i++ {
+			require.True(t, literal[i] >= 0x20 && literal[i] < 0x7f, "non-printable byte in %s", literal)
+		}
+		require.Equal(t, str, decodeLuaString(t, literal))
+	}
+}
+
+func TestMaintenanceEnvoyFilterSpec(t *testing.T) {
+	envoyFilter, err := maintenanceEnvoyFilterSpec(map[string]gatewayResponse{
+		"/my-api": {statusCode: 503, contentType: "text/plain; charset=utf-8", body: "en mantenimiento\n\x00🚧", weight: 100},
+	})
+	require.NoError(t, err)
+
+	inlineCode := envoyFilter.Spec.ConfigPatches[0].Patch.Value.Fields["config"].GetStructValue().Fields["inline_code"].GetStringValue()
+	require.Contains(t, inlineCode, `["/my-api"] = {status = "503", content_type = "text/plain; charset=utf-8", body = "en mantenimiento\010\000\240\159\154\167", weight = 100},`)
+	require.NotContains(t, inlineCode, `\u`)
+	require.NotContains(t, inlineCode, `\x`)
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kschema "k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _scaledObjectResource = kschema.GroupVersionResource{
+	Group:    "keda.k8s.io",
+	Version:  "v1alpha1",
+	Resource: "scaledobjects",
+}
+
+var _scaledObjectTypeMeta = kmeta.TypeMeta{
+	APIVersion: "keda.k8s.io/v1alpha1",
+	Kind:       "ScaledObject",
+}
+
+// kedaScaledObject is KEDA's ScaledObject custom resource (KEDA must be installed in the cluster); KEDA manages a
+// HorizontalPodAutoscaler for the API's deployment based on the object's triggers
+type kedaScaledObject struct {
+	kmeta.TypeMeta   `json:",inline"`
+	kmeta.ObjectMeta `json:"metadata,omitempty"`
+	Spec             kedaScaledObjectSpec `json:"spec"`
+}
+
+type kedaScaledObjectSpec struct {
+	ScaleTargetRef  kedaScaleTarget `json:"scaleTargetRef"`
+	PollingInterval int32           `json:"pollingInterval"`
+	CooldownPeriod  int32           `json:"cooldownPeriod"`
+	MinReplicaCount int32           `json:"minReplicaCount"`
+	MaxReplicaCount int32           `json:"maxReplicaCount"`
+	Triggers        []kedaTrigger   `json:"triggers"`
+}
+
+type kedaScaleTarget struct {
+	DeploymentName string `json:"deploymentName"`
+	ContainerName  string `json:"containerName,omitempty"`
+}
+
+type kedaTrigger struct {
+	Type     string            `json:"type"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+// The primary trigger (see primaryTrigger()) is followed by the API's keda_triggers
+func scaledObjectSpec(api *spec.API, primary kedaTrigger) *kedaScaledObject {
+	triggers := []kedaTrigger{primary}
+	for _, trigger := range api.Autoscaling.KEDATriggers {
+		triggers = append(triggers, kedaTrigger{
+			Type:     trigger.Type,
+			Metadata: trigger.Metadata,
+		})
+	}
+
+	return &kedaScaledObject{
+		TypeMeta: _scaledObjectTypeMeta,
+		ObjectMeta: kmeta.ObjectMeta{
+			Name:        k8sName(api.Name),
+			Namespace:   api.Namespace,
+			Annotations: apiAnnotations(api, nil),
+			Labels: apiLabels(api, map[string]string{
+				"apiName":        api.Name,
+				"deploymentName": k8sName(api.Name), // required by KEDA
+			}),
+		},
+		Spec: kedaScaledObjectSpec{
+			ScaleTargetRef: kedaScaleTarget{
+				DeploymentName: k8sName(api.Name),
+				ContainerName:  _apiContainerName,
+			},
+			PollingInterval: int32(spec.AutoscalingTickInterval.Seconds()),
+			CooldownPeriod:  int32(api.Autoscaling.DownscaleStabilizationPeriod.Seconds()),
+			MinReplicaCount: api.Autoscaling.MinReplicas,
+			MaxReplicaCount: api.Autoscaling.MaxReplicas,
+			Triggers:        triggers,
+		},
+	}
+}
+
+// The API's in-flight requests (which the request monitor reports to CloudWatch) are the primary trigger, with
+// the same target as cortex's autoscaler; KEDA reads the metric with the AWS credentials in the API container's environment.
+// Stream APIs don't receive requests, so they are scaled on their consumer group's lag, their stream's shard count, or their queue's depth instead
+func primaryTrigger(api *spec.API) (kedaTrigger, error) {
+	if api.Stream != nil {
+		switch api.Stream.Source {
+		case userconfig.KafkaStreamSourceType:
+			return kafkaLagTrigger(api.Stream), nil
+		case userconfig.KinesisStreamSourceType:
+			return kinesisShardTrigger(api.Stream), nil
+		case userconfig.SQSStreamSourceType:
+			queueURL, err := config.AWS.GetSQSQueueURL(api.Stream.Input)
+			if err != nil {
+				return kedaTrigger{}, err
+			}
+			return sqsQueueTrigger(api.Stream, queueURL), nil
+		}
+	}
+	return inFlightTrigger(api), nil
+}
+
+func inFlightTrigger(api *spec.API) kedaTrigger {
+	return kedaTrigger{
+		Type: "aws-cloudwatch",
+		Metadata: map[string]string{
+			"namespace":            config.Cluster.ClusterName,
+			"metricName":           "in-flight",
+			"dimensionName":        "apiName",
+			"dimensionValue":       api.Name,
+			"metricStat":           "Sum",
+			"metricStatPeriod":     s.Int64(config.Cluster.RequestMonitorFlushInterval),
+			"metricCollectionTime": s.Int64(int64(api.Autoscaling.Window.Seconds())),
+			"targetMetricValue":    s.Float64(*api.Autoscaling.TargetReplicaConcurrency),
+			"minMetricValue":       "0",
+			"awsRegion":            *config.Cluster.Region,
+			"awsAccessKeyID":       "AWS_ACCESS_KEY_ID",
+			"awsSecretAccessKey":   "AWS_SECRET_ACCESS_KEY",
+		},
+	}
+}
+
+func kafkaLagTrigger(stream *userconfig.Stream) kedaTrigger {
+	return kedaTrigger{
+		Type: "kafka",
+		Metadata: map[string]string{
+			"bootstrapServers": strings.Join(stream.Brokers, ","),
+			"consumerGroup":    *stream.ConsumerGroup,
+			"topic":            stream.Input,
+			"lagThreshold":     s.Int64(*stream.TargetLag),
+		},
+	}
+}
+
+// The replicas split the stream's shards between them (see serve/stream.py), so there is no benefit to running more replicas than there are shards
+func kinesisShardTrigger(stream *userconfig.Stream) kedaTrigger {
+	return kedaTrigger{
+		Type: "aws-kinesis-stream",
+		Metadata: map[string]string{
+			"streamName":         stream.Input,
+			"shardCount":         s.Int64(*stream.ShardsPerReplica),
+			"awsRegion":          *config.Cluster.Region,
+			"awsAccessKeyID":     "AWS_ACCESS_KEY_ID",
+			"awsSecretAccessKey": "AWS_SECRET_ACCESS_KEY",
+		},
+	}
+}
+
+func sqsQueueTrigger(stream *userconfig.Stream, queueURL string) kedaTrigger {
+	return kedaTrigger{
+		Type: "aws-sqs-queue",
+		Metadata: map[string]string{
+			"queueURL":           queueURL,
+			"queueLength":        s.Int64(*stream.TargetLag),
+			"awsRegion":          *config.Cluster.Region,
+			"awsAccessKeyID":     "AWS_ACCESS_KEY_ID",
+			"awsSecretAccessKey": "AWS_SECRET_ACCESS_KEY",
+		},
+	}
+}
+
+func applyK8sScaledObject(api *spec.API) error {
+	k8sNamespace := config.K8sNamespace(api.Namespace)
+
+	if api.Autoscaling.Autoscaler != userconfig.KEDAAutoscalerType {
+		_, err := k8sNamespace.DeleteCustomResource(_scaledObjectResource, k8sName(api.Name))
+		return err
+	}
+
+	primary, err := primaryTrigger(api)
+	if err != nil {
+		return err
+	}
+
+	obj, err := k8s.ToUnstructured(scaledObjectSpec(api, primary))
+	if err != nil {
+		return err
+	}
+	_, err = k8sNamespace.ApplyCustomResource(_scaledObjectResource, obj)
+	return err
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+)
+
+const (
+	_kinesisLeaseConsumerAttribute = "consumer"
+	_kinesisLeaseShardAttribute    = "shard_id"
+)
+
+// kinesisLeaseTable is the DynamoDB table in which the replicas of Kinesis stream APIs hold leases on the shards which
+// they read, and checkpoint the last record which they processed in each shard (see serve/stream.py); an item's hash
+// key identifies the API and its input stream ("<api_name>/<stream_name>"), and its range key is the shard ID
+func kinesisLeaseTable() string {
+	return config.Cluster.ClusterName + "-kinesis-leases"
+}
+
+// ensureKinesisLeaseTable creates the cluster's Kinesis lease table (with the cluster's tags) if it doesn't already exist;
+// the table (and the API's checkpoints) are not deleted with the API, so a redeployed API resumes from its checkpoints
+func ensureKinesisLeaseTable(api *spec.API) error {
+	if api.Stream == nil || api.Stream.Source != userconfig.KinesisStreamSourceType {
+		return nil
+	}
+
+	table := kinesisLeaseTable()
+	exists, err := config.AWS.DoesDynamoDBTableExist(table)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	return config.AWS.CreateDynamoDBTable(table, _kinesisLeaseConsumerAttribute, _kinesisLeaseShardAttribute, config.Cluster.Tags)
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"net/url"
+	"os"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"k8s.io/client-go/tools/leaderelection"
+)
+
+// When the operator runs multiple replicas, one of them is elected as the leader: it runs the background tasks (e.g.
+// autoscaling, reconciliation and rollout checks) and handles the requests which change the cluster's state or read
+// state which is kept in memory (see LeaderMiddleware), while all replicas serve the other read requests.
+
+const (
+	_leaderElectionName = "operator"
+	_operatorPodNameEnv = "CORTEX_OPERATOR_POD_NAME" // set from the pod's name in the operator's deployment
+	_operatorPort       = "8888"
+)
+
+var _leaderElector *leaderelection.LeaderElector // nil if leader election is disabled (i.e. when running outside of the cluster)
+
+func initLeaderElection() error {
+	podName := os.Getenv(_operatorPodNameEnv)
+	if podName == "" {
+		return startLeaderTasks()
+	}
+
+	elector, err := config.K8s.RunLeaderElection(_leaderElectionName, podName, onStartedLeading, onStoppedLeading)
+	if err != nil {
+		return err
+	}
+	_leaderElector = elector
+
+	return nil
+}
+
+func onStartedLeading() {
+	if err := startLeaderTasks(); err != nil {
+		exit.Error(errors.Wrap(err, "start leader tasks"))
+	}
+}
+
+// the background tasks can't be stopped cleanly, so the replica restarts (and rejoins as a follower)
+func onStoppedLeading() {
+	exit.Error(ErrorLostLeadership())
+}
+
+func IsLeader() bool {
+	return _leaderElector == nil || _leaderElector.IsLeader()
+}
+
+// LeaderURL returns the URL of the replica which is currently the leader
+func LeaderURL() (*url.URL, error) {
+	if _leaderElector == nil {
+		return nil, ErrorNoOperatorLeader()
+	}
+
+	leaderPodName := _leaderElector.GetLeader()
+	if leaderPodName == "" {
+		return nil, ErrorNoOperatorLeader()
+	}
+
+	pod, err := config.K8s.GetPod(leaderPodName)
+	if err != nil {
+		return nil, err
+	}
+	if pod == nil || pod.Status.PodIP == "" {
+		return nil, ErrorNoOperatorLeader()
+	}
+
+	return &url.URL{
+		Scheme: "http",
+		Host:   pod.Status.PodIP + ":" + _operatorPort,
+	}, nil
+}
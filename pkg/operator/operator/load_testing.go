@@ -0,0 +1,462 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/lib/urls"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	kbatch "k8s.io/api/batch/v1"
+	kcore "k8s.io/api/core/v1"
+)
+
+const (
+	_loadTestKind               = "load_tests"
+	_loadTestLabelKey           = "loadTest"
+	_loadTesterContainerName    = "load-tester"
+	_loadTestCheckPeriod        = 5 * time.Second
+	_loadTestStartTimeout       = 10 * time.Minute // allows for the job's pod to be scheduled and its image to be pulled
+	_loadTestRequestTimeout     = 60               // seconds
+	_maxLoadTestPayloadSize     = 64 * 1024        // the payload is passed to the job as an argument
+	_maxLoadTestConcurrency     = 500
+	_maxLoadTestSteps           = 20
+	_maxLoadTestStepDuration    = 10 * 60 // seconds
+	_maxSustainableErrorRate    = 0.01    // steps in which a higher fraction of the requests fail aren't sustainable
+	_defaultLoadTestContentType = "application/json"
+)
+
+// the IDs of the load tests which are running in this process, by API name
+var _runningLoadTests = map[string]string{}
+var _runningLoadTestsMutex sync.Mutex
+
+// the configuration of the load tester (see images/load-tester)
+type loadTesterConfig struct {
+	URL             string  `json:"url"`
+	Payload         []byte  `json:"payload"`
+	ContentType     string  `json:"content_type"`
+	MinConcurrency  int     `json:"min_concurrency"`
+	MaxConcurrency  int     `json:"max_concurrency"`
+	ConcurrencyStep int     `json:"concurrency_step"`
+	StepDuration    float64 `json:"step_duration"`
+	RequestTimeout  float64 `json:"request_timeout"`
+}
+
+// the results which the load tester prints on the last line of its logs
+type loadTesterResult struct {
+	Steps []struct {
+		Concurrency int     `json:"concurrency"`
+		Start       int64   `json:"start"`
+		End         int64   `json:"end"`
+		Requests    int64   `json:"requests"`
+		Errors      int64   `json:"errors"`
+		QPS         float64 `json:"qps"`
+		P50         float64 `json:"p50"`
+		P90         float64 `json:"p90"`
+		P99         float64 `json:"p99"`
+	} `json:"steps"`
+}
+
+// the number of ready replicas which the API had at a point in time during the load test
+type replicaSample struct {
+	time     int64
+	replicas int32
+}
+
+type loadTestRun struct {
+	loadTest       *schema.LoadTest
+	api            *spec.API
+	jobName        string
+	timeout        time.Duration
+	replicaSamples []replicaSample
+}
+
+// StartLoadTest runs a job which sends requests to the API at each step of the config's concurrency ramp; the load test
+// runs in the background, and its results can be checked with GetLoadTest()
+func StartLoadTest(apiName string, loadTestConfig schema.LoadTestConfig) (*schema.LoadTest, error) {
+	if err := validateLoadTestConfig(&loadTestConfig); err != nil {
+		return nil, err
+	}
+
+	deployment, err := getAPIDeployment(apiName)
+	if err != nil {
+		return nil, err
+	}
+	if deployment == nil {
+		return nil, ErrorAPINotDeployed(apiName)
+	}
+
+	api, err := DownloadAPISpec(apiName, deployment.Labels["apiID"])
+	if err != nil {
+		return nil, err
+	}
+	if api.Stream != nil {
+		return nil, ErrorLoadTestTargetIsStreamAPI(apiName)
+	}
+
+	loadBalancerURL, err := APILoadBalancerURL()
+	if err != nil {
+		return nil, err
+	}
+
+	_runningLoadTestsMutex.Lock()
+	defer _runningLoadTestsMutex.Unlock()
+	if loadTestID, ok := _runningLoadTests[apiName]; ok {
+		return nil, ErrorLoadTestInProgress(apiName, loadTestID)
+	}
+
+	now := time.Now()
+	payload := loadTestConfig.Payload
+	loadTestConfig.Payload = ""
+	run := &loadTestRun{
+		loadTest: &schema.LoadTest{
+			ID:        fmt.Sprintf("%x", now.UnixNano()), // sorts chronologically
+			APIName:   apiName,
+			Config:    loadTestConfig,
+			Status:    schema.LoadTestStatusRunning,
+			StartedAt: now.Unix(),
+			Steps:     []schema.LoadTestStep{},
+		},
+		api: api,
+	}
+	run.jobName = loadTestJobName(run.loadTest.ID)
+	numSteps := (loadTestConfig.MaxConcurrency-loadTestConfig.MinConcurrency)/loadTestConfig.ConcurrencyStep + 1
+	run.timeout = _loadTestStartTimeout + time.Duration(int64(numSteps)*(loadTestConfig.StepDuration+_loadTestRequestTimeout))*time.Second
+
+	job := loadTestJobSpec(run.jobName, apiName, run.timeout, loadTesterConfig{
+		URL:             urls.Join(loadBalancerURL, *api.Endpoint),
+		Payload:         []byte(payload),
+		ContentType:     loadTestConfig.ContentType,
+		MinConcurrency:  loadTestConfig.MinConcurrency,
+		MaxConcurrency:  loadTestConfig.MaxConcurrency,
+		ConcurrencyStep: loadTestConfig.ConcurrencyStep,
+		StepDuration:    float64(loadTestConfig.StepDuration),
+		RequestTimeout:  _loadTestRequestTimeout,
+	})
+	if _, err := config.K8s.CreateJob(job); err != nil {
+		return nil, err
+	}
+
+	if err := run.save(); err != nil {
+		config.K8s.DeleteJob(run.jobName)
+		return nil, err
+	}
+
+	_runningLoadTests[apiName] = run.loadTest.ID
+	go run.run()
+
+	return run.loadTest, nil
+}
+
+func GetLoadTest(apiName string, loadTestID string) (*schema.LoadTest, error) {
+	var loadTest schema.LoadTest
+	exists, err := config.Metadata.Get(_loadTestKind, loadTestKey(apiName, loadTestID), &loadTest)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrorLoadTestNotFound(apiName, loadTestID)
+	}
+
+	// load tests don't survive operator restarts (their jobs are deleted by deleteOrphanedLoadTestJobs)
+	if loadTest.Status == schema.LoadTestStatusRunning {
+		_runningLoadTestsMutex.Lock()
+		if _runningLoadTests[apiName] != loadTest.ID {
+			loadTest.Status = schema.LoadTestStatusInterrupted
+		}
+		_runningLoadTestsMutex.Unlock()
+	}
+
+	return &loadTest, nil
+}
+
+func loadTestKey(apiName string, loadTestID string) string {
+	return apiName + "/" + loadTestID
+}
+
+func loadTestJobName(loadTestID string) string {
+	return "load-test-" + loadTestID
+}
+
+func validateLoadTestConfig(loadTestConfig *schema.LoadTestConfig) error {
+	if loadTestConfig.ContentType == "" {
+		loadTestConfig.ContentType = _defaultLoadTestContentType
+	}
+	if loadTestConfig.ConcurrencyStep == 0 {
+		loadTestConfig.ConcurrencyStep = 1
+	}
+
+	switch {
+	case len(loadTestConfig.Payload) > _maxLoadTestPayloadSize:
+		return ErrorInvalidLoadTestConfig(fmt.Sprintf("the payload must not be larger than %d bytes", _maxLoadTestPayloadSize))
+	case loadTestConfig.MinConcurrency < 1 || loadTestConfig.ConcurrencyStep < 1:
+		return ErrorInvalidLoadTestConfig("the minimum concurrency and the concurrency step must be at least 1")
+	case loadTestConfig.MaxConcurrency < loadTestConfig.MinConcurrency || loadTestConfig.MaxConcurrency > _maxLoadTestConcurrency:
+		return ErrorInvalidLoadTestConfig(fmt.Sprintf("the maximum concurrency must be between the minimum concurrency (%d) and %d", loadTestConfig.MinConcurrency, _maxLoadTestConcurrency))
+	case (loadTestConfig.MaxConcurrency-loadTestConfig.MinConcurrency)/loadTestConfig.ConcurrencyStep+1 > _maxLoadTestSteps:
+		return ErrorInvalidLoadTestConfig(fmt.Sprintf("the concurrency ramp must not have more than %d steps", _maxLoadTestSteps))
+	case loadTestConfig.StepDuration < 1 || loadTestConfig.StepDuration > _maxLoadTestStepDuration:
+		return ErrorInvalidLoadTestConfig(fmt.Sprintf("the step duration must be between 1 and %d seconds", _maxLoadTestStepDuration))
+	case loadTestConfig.MaxLatency < 0:
+		return ErrorInvalidLoadTestConfig("the maximum latency must not be negative")
+	}
+
+	return nil
+}
+
+// the load tester doesn't tolerate the workers' taints, so it runs on the operator's instance rather than competing
+// with the APIs for resources
+func loadTestJobSpec(jobName string, apiName string, timeout time.Duration, testerConfig loadTesterConfig) *kbatch.Job {
+	testerConfigBytes, _ := json.Marshal(testerConfig)
+	labels := map[string]string{
+		_loadTestLabelKey: "true",
+		"apiName":         apiName,
+	}
+
+	job := k8s.Job(&k8s.JobSpec{
+		Name:   jobName,
+		Labels: labels,
+		PodSpec: k8s.PodSpec{
+			Labels: labels,
+			K8sPodSpec: kcore.PodSpec{
+				RestartPolicy: "Never",
+				Containers: []kcore.Container{
+					{
+						Name:            _loadTesterContainerName,
+						Image:           config.Cluster.ImageLoadTester,
+						ImagePullPolicy: "Always",
+						Args:            []string{"--config=" + base64.URLEncoding.EncodeToString(testerConfigBytes)},
+					},
+				},
+				ServiceAccountName: "default",
+			},
+		},
+	})
+	job.Spec.ActiveDeadlineSeconds = pointer.Int64(int64(timeout.Seconds()))
+	return job
+}
+
+func (run *loadTestRun) run() {
+	defer func() {
+		_runningLoadTestsMutex.Lock()
+		delete(_runningLoadTests, run.loadTest.APIName)
+		_runningLoadTestsMutex.Unlock()
+
+		if _, err := config.K8s.DeleteJob(run.jobName); err != nil {
+			errors.PrintError(err, "failed to delete load test job", run.jobName)
+		}
+	}()
+
+	ticker := time.NewTicker(_loadTestCheckPeriod)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		run.sampleReplicas()
+
+		job, err := config.K8s.GetJob(run.jobName)
+		if err != nil {
+			errors.PrintError(err, "failed to get load test job", run.jobName)
+			continue
+		}
+		if job == nil {
+			run.finish(ErrorLoadTestJobFailed("the job was deleted"))
+			return
+		}
+
+		if job.Status.Succeeded > 0 {
+			lastLog, err := lastLoadTesterLog(run.jobName)
+			if err != nil {
+				run.finish(err)
+				return
+			}
+			var result loadTesterResult
+			if err := json.Unmarshal([]byte(lastLog), &result); err != nil {
+				run.finish(errors.Wrap(err, "load tester results"))
+				return
+			}
+			run.setResults(result)
+			run.finish(nil)
+			return
+		}
+
+		if job.Status.Failed > 0 {
+			lastLog, _ := lastLoadTesterLog(run.jobName)
+			run.finish(ErrorLoadTestJobFailed(lastLog))
+			return
+		}
+
+		if time.Since(time.Unix(run.loadTest.StartedAt, 0)) > run.timeout {
+			run.finish(ErrorLoadTestTimeout(run.timeout))
+			return
+		}
+	}
+}
+
+func (run *loadTestRun) sampleReplicas() {
+	deployment, err := getAPIDeployment(run.loadTest.APIName)
+	if err != nil || deployment == nil {
+		return
+	}
+	run.replicaSamples = append(run.replicaSamples, replicaSample{
+		time:     time.Now().Unix(),
+		replicas: deployment.Status.ReadyReplicas,
+	})
+}
+
+// maxReplicas returns the most ready replicas that the API had between start and end (or the number of replicas at the
+// latest sample before end, if there weren't any samples in the time range)
+func (run *loadTestRun) maxReplicas(start int64, end int64) int32 {
+	var maxReplicas int32
+	for _, sample := range run.replicaSamples {
+		if sample.time > end {
+			break
+		}
+		if sample.time < start {
+			maxReplicas = sample.replicas
+			continue
+		}
+		if sample.replicas > maxReplicas {
+			maxReplicas = sample.replicas
+		}
+	}
+	return maxReplicas
+}
+
+func (run *loadTestRun) setResults(result loadTesterResult) {
+	loadTest := run.loadTest
+
+	var maxSustainableStep *schema.LoadTestStep
+	for _, testerStep := range result.Steps {
+		step := schema.LoadTestStep{
+			Concurrency: testerStep.Concurrency,
+			Requests:    testerStep.Requests,
+			Errors:      testerStep.Errors,
+			QPS:         testerStep.QPS,
+			P50:         testerStep.P50,
+			P90:         testerStep.P90,
+			P99:         testerStep.P99,
+			Replicas:    run.maxReplicas(testerStep.Start, testerStep.End),
+		}
+		step.GPUs = int64(step.Replicas) * run.api.Compute.GPU
+		step.Sustainable = testerStep.Requests > 0 &&
+			float64(testerStep.Errors) <= _maxSustainableErrorRate*float64(testerStep.Requests) &&
+			(loadTest.Config.MaxLatency == 0 || testerStep.P99 <= loadTest.Config.MaxLatency)
+
+		loadTest.Steps = append(loadTest.Steps, step)
+		if step.Replicas > loadTest.MaxReplicas {
+			loadTest.MaxReplicas = step.Replicas
+		}
+		if step.Sustainable && step.QPS > loadTest.MaxSustainableQPS {
+			loadTest.MaxSustainableQPS = step.QPS
+			maxSustainableStep = &loadTest.Steps[len(loadTest.Steps)-1]
+		}
+	}
+	loadTest.MaxGPUs = int64(loadTest.MaxReplicas) * run.api.Compute.GPU
+
+	// each client of the load tester always has one request in flight, so the step's concurrency was spread across its replicas
+	if maxSustainableStep != nil && maxSustainableStep.Replicas > 0 {
+		targetReplicaConcurrency := math.Floor(float64(maxSustainableStep.Concurrency)/float64(maxSustainableStep.Replicas)*10) / 10
+		loadTest.RecommendedTargetReplicaConcurrency = pointer.Float64(math.Max(targetReplicaConcurrency, 0.1))
+	}
+}
+
+func (run *loadTestRun) finish(err error) {
+	run.loadTest.Status = schema.LoadTestStatusCompleted
+	if err != nil {
+		run.loadTest.Status = schema.LoadTestStatusFailed
+		run.loadTest.Error = errors.Message(err)
+	}
+	finishedAt := time.Now().Unix()
+	run.loadTest.FinishedAt = &finishedAt
+
+	if err := run.save(); err != nil {
+		errors.PrintError(err, "failed to save load test", run.loadTest.ID)
+	}
+}
+
+func (run *loadTestRun) save() error {
+	return config.Metadata.Put(_loadTestKind, loadTestKey(run.loadTest.APIName, run.loadTest.ID), run.loadTest)
+}
+
+// lastLoadTesterLog returns the last line of the logs of the load test job's pod
+func lastLoadTesterLog(jobName string) (string, error) {
+	pods, err := config.K8s.ListPodsByLabel("job-name", jobName)
+	if err != nil {
+		return "", err
+	}
+	if len(pods) == 0 {
+		return "", nil
+	}
+
+	stream, err := config.K8s.StreamPodLogs(pods[0].Name, &kcore.PodLogOptions{
+		Container: _loadTesterContainerName,
+		TailLines: pointer.Int64(1),
+	})
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+
+	logBytes, err := ioutil.ReadAll(stream)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return strings.TrimSpace(string(logBytes)), nil
+}
+
+// deleteOrphanedLoadTestJobs deletes the jobs of load tests which were interrupted by an operator restart
+func deleteOrphanedLoadTestJobs() error {
+	jobs, err := config.K8s.ListJobsByLabel(_loadTestLabelKey, "true")
+	if err != nil {
+		return err
+	}
+
+	_runningLoadTestsMutex.Lock()
+	runningLoadTests := map[string]string{}
+	for apiName, loadTestID := range _runningLoadTests {
+		runningLoadTests[apiName] = loadTestID
+	}
+	_runningLoadTestsMutex.Unlock()
+
+	var errs []error
+	for _, job := range jobs {
+		loadTestID, ok := runningLoadTests[job.Labels["apiName"]]
+		if ok && job.Name == loadTestJobName(loadTestID) {
+			continue
+		}
+		if _, err := config.K8s.DeleteJob(job.Name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if errors.HasError(errs) {
+		return errors.FirstError(errs...)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"sync"
+)
+
+// the maximum number of APIs which are deployed at once (across all deploy requests), so that a bulk deploy doesn't
+// exceed the rate limits of the kubernetes and AWS APIs
+const _maxConcurrentAPIUpdates = 10
+
+var (
+	_apiUpdateSlots = make(chan struct{}, _maxConcurrentAPIUpdates)
+
+	_apiLocks      = make(map[string]*apiLock) // apiName -> lock
+	_apiLocksMutex sync.Mutex
+)
+
+type apiLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lockAPI serializes the operations which modify an API (deploying, reverting, refreshing and deleting it), so that
+// concurrent requests for the same API don't conflict; the returned function releases the lock
+func lockAPI(apiName string) func() {
+	_apiLocksMutex.Lock()
+	lock, ok := _apiLocks[apiName]
+	if !ok {
+		lock = &apiLock{}
+		_apiLocks[apiName] = lock
+	}
+	lock.refs++
+	_apiLocksMutex.Unlock()
+
+	lock.Lock()
+
+	return func() {
+		lock.Unlock()
+
+		_apiLocksMutex.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(_apiLocks, apiName)
+		}
+		_apiLocksMutex.Unlock()
+	}
+}
+
+// acquireAPIUpdateSlot blocks until fewer than _maxConcurrentAPIUpdates APIs are being deployed; the returned function
+// releases the slot
+func acquireAPIUpdateSlot() func() {
+	_apiUpdateSlots <- struct{}{}
+	return func() {
+		<-_apiUpdateSlots
+	}
+}
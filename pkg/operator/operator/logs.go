@@ -118,7 +118,7 @@ func streamFromCloudWatch(apiName string, podCheckCancel chan struct{}, socket *
 		case <-timer.C:
 			if deployment == nil || time.Since(lastDeploymentRefresh) > _deploymentRefreshPeriod {
 				var err error
-				deployment, err = config.K8s.GetDeployment(k8sName(apiName))
+				deployment, err = getAPIDeployment(apiName)
 				if err != nil {
 					telemetry.Error(err)
 					writeAndCloseSocket(socket, "error: "+errors.Message(err))
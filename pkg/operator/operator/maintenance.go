@@ -0,0 +1,218 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	istioclientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	klabels "k8s.io/apimachinery/pkg/labels"
+)
+
+const _maintenanceKind = "maintenance"
+
+type maintenanceMode struct {
+	Message   string `json:"message"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// EnableMaintenanceMode causes all requests to the API to be rejected with the message until maintenance mode is disabled
+// (if message is empty, the API's maintenance_message is used); returns the message
+func EnableMaintenanceMode(apiName string, message string) (string, error) {
+	if !isIstioNetworking() {
+		return "", ErrorRequiresIstioNetworking("maintenance mode")
+	}
+
+	deployment, err := getAPIDeployment(apiName)
+	if err != nil {
+		return "", err
+	} else if deployment == nil {
+		return "", ErrorAPINotDeployed(apiName)
+	}
+
+	if message == "" {
+		virtualService, err := config.K8sNamespace(deployment.Namespace).GetVirtualService(k8sName(apiName))
+		if err != nil {
+			return "", err
+		}
+		if virtualService != nil {
+			message = virtualService.Annotations[userconfig.MaintenanceMessageAnnotationKey]
+		}
+	}
+	if message == "" {
+		message = fmt.Sprintf("%s is under maintenance", apiName)
+	}
+
+	err = config.Metadata.Put(_maintenanceKind, apiName, maintenanceMode{
+		Message:   message,
+		Timestamp: time.Now().Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if err := updateMaintenanceEnvoyFilter(); err != nil {
+		return "", err
+	}
+
+	return message, nil
+}
+
+func DisableMaintenanceMode(apiName string) error {
+	if err := config.Metadata.Delete(_maintenanceKind, apiName); err != nil {
+		return err
+	}
+	return updateMaintenanceEnvoyFilter()
+}
+
+// a gatewayResponse is responded with by the APIs gateway (rather than routing the request to the API) to the percentage of the
+// requests to an endpoint which is its weight
+type gatewayResponse struct {
+	statusCode  int32
+	contentType string
+	body        string
+	weight      int32
+}
+
+// the maintenance envoy filter is shared by all APIs; an API's requests are rejected if it is in maintenance mode,
+// or if it has a maintenance message and has no ready replicas (and is not being routed to its fallback API); APIs with a
+// fallback response are responded to with it while they have no ready replicas (or, with fallback_on_overload, the share of
+// their requests which exceeds their capacity)
+func updateMaintenanceEnvoyFilter() error {
+	if !isIstioNetworking() {
+		return nil
+	}
+
+	virtualServices, err := config.K8sAllNamspaces.ListVirtualServicesWithLabelKeys("apiName")
+	if err != nil {
+		return err
+	}
+
+	deployments, err := listAPIDeployments(klabels.Everything())
+	if err != nil {
+		return err
+	}
+	readyReplicas := make(map[string]int32, len(deployments)) // apiName -> ready replicas
+	for _, deployment := range deployments {
+		readyReplicas[deployment.Labels["apiName"]] = deployment.Status.ReadyReplicas
+	}
+
+	items, err := config.Metadata.List(_maintenanceKind, "")
+	if err != nil {
+		return err
+	}
+	maintenanceModes := make(map[string]maintenanceMode, len(items)) // apiName -> maintenance mode
+	for _, item := range items {
+		var mode maintenanceMode
+		if err := item.Unmarshal(&mode); err != nil {
+			return err
+		}
+		maintenanceModes[item.Key] = mode
+	}
+
+	responses := map[string]gatewayResponse{}
+	for i := range virtualServices {
+		virtualService := &virtualServices[i]
+		apiName := virtualService.Labels["apiName"]
+
+		response, err := apiGatewayResponse(virtualService, maintenanceModes, readyReplicas[apiName])
+		if err != nil {
+			return err
+		}
+		if response == nil {
+			continue
+		}
+		for endpoint := range k8s.ExtractVirtualServiceEndpoints(virtualService) {
+			responses[endpoint] = *response
+		}
+	}
+
+	if len(responses) == 0 {
+		_, err := config.K8sIstio.DeleteEnvoyFilter(_maintenanceEnvoyFilterName)
+		return err
+	}
+
+	envoyFilter, err := maintenanceEnvoyFilterSpec(responses)
+	if err != nil {
+		return err
+	}
+
+	_, err = config.K8sIstio.ApplyEnvoyFilter(envoyFilter)
+	return err
+}
+
+// apiGatewayResponse returns nil if all of the API's requests should be routed to it
+func apiGatewayResponse(virtualService *istioclientnetworking.VirtualService, maintenanceModes map[string]maintenanceMode, readyReplicas int32) (*gatewayResponse, error) {
+	apiName := virtualService.Labels["apiName"]
+
+	if mode, ok := maintenanceModes[apiName]; ok {
+		return maintenanceResponse(mode.Message), nil
+	}
+
+	if fallbackResponseStr, ok := virtualService.Annotations[userconfig.FallbackResponseAnnotationKey]; ok {
+		var fallbackResponse userconfig.FallbackResponse
+		if err := json.Unmarshal([]byte(fallbackResponseStr), &fallbackResponse); err != nil {
+			return nil, errors.Wrap(err, apiName, userconfig.FallbackResponseKey)
+		}
+
+		// the weight is recorded by updateFallbackRoutes, which may not have run since the API's replicas became unavailable
+		weight := currentFallbackWeight(virtualService)
+		if readyReplicas == 0 {
+			weight = 100
+		}
+		if weight == 0 {
+			return nil, nil
+		}
+
+		return &gatewayResponse{
+			statusCode:  fallbackResponse.StatusCode,
+			contentType: fallbackResponse.ContentType,
+			body:        fallbackResponse.Body,
+			weight:      weight,
+		}, nil
+	}
+
+	if readyReplicas == 0 && !isRoutedToFallback(virtualService) {
+		if message := virtualService.Annotations[userconfig.MaintenanceMessageAnnotationKey]; message != "" {
+			return maintenanceResponse(message), nil
+		}
+	}
+
+	return nil, nil
+}
+
+func maintenanceResponse(message string) *gatewayResponse {
+	return &gatewayResponse{
+		statusCode:  503,
+		contentType: "text/plain",
+		body:        message,
+		weight:      100,
+	}
+}
+
+// deleteMaintenanceMode is best effort; failures are printed but don't affect the deletion
+func deleteMaintenanceMode(apiName string) {
+	if err := config.Metadata.Delete(_maintenanceKind, apiName); err != nil {
+		errors.PrintError(err, "failed to delete maintenance mode")
+	}
+}
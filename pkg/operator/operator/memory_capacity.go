@@ -19,6 +19,7 @@ package operator
 import (
 	"github.com/cortexlabs/cortex/pkg/lib/k8s"
 	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
 	kresource "k8s.io/apimachinery/pkg/api/resource"
 	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	klabels "k8s.io/apimachinery/pkg/labels"
@@ -66,6 +67,11 @@ func getMemoryCapacityFromNodes() (*kresource.Quantity, error) {
 
 	var minMem *kresource.Quantity
 	for _, node := range nodes {
+		// nodes in additional node groups may have a different instance type than the cluster's default worker nodes
+		if _, ok := node.Labels[clusterconfig.NodeGroupLabelKey]; ok {
+			continue
+		}
+
 		curMem := node.Status.Capacity.Memory()
 
 		if curMem != nil && minMem == nil {
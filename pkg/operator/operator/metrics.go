@@ -30,6 +30,7 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/parallel"
 	"github.com/cortexlabs/cortex/pkg/lib/pointer"
 	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
 	"github.com/cortexlabs/cortex/pkg/operator/config"
 	"github.com/cortexlabs/cortex/pkg/types/metrics"
 	"github.com/cortexlabs/cortex/pkg/types/spec"
@@ -72,25 +73,50 @@ func GetMetrics(api *spec.API) (*metrics.Metrics, error) {
 	batchMetrics := metrics.Metrics{}
 	requestList := []func() error{}
 
+	// custom metrics are best-effort, so that the api's request metrics are still returned if they can't be listed
+	customMetrics, err := listCustomMetrics(api)
+	if err != nil {
+		telemetry.Error(err)
+		errors.PrintError(err)
+	}
+
 	if realTimeStart.Before(realTimeEnd) {
-		requestList = append(requestList, getMetricsFunc(api, 1, &realTimeStart, &realTimeEnd, &realTimeMetrics))
+		requestList = append(requestList, getMetricsFunc(api, customMetrics, 1, &realTimeStart, &realTimeEnd, &realTimeMetrics))
 	}
 
 	batchEnd := realTimeStart
 	batchStart := batchEnd.Add(-14 * 24 * time.Hour) // two weeks ago
-	requestList = append(requestList, getMetricsFunc(api, 60*60, &batchStart, &batchEnd, &batchMetrics))
+	requestList = append(requestList, getMetricsFunc(api, customMetrics, 60*60, &batchStart, &batchEnd, &batchMetrics))
 
-	err := parallel.RunFirstErr(requestList[0], requestList[1:]...)
+	err = parallel.RunFirstErr(requestList[0], requestList[1:]...)
 	if err != nil {
 		return nil, err
 	}
 
 	mergedMetrics := realTimeMetrics.Merge(batchMetrics)
 	mergedMetrics.APIName = api.Name
+
+	// accelerator telemetry is best-effort, so that the api's request metrics are still returned if it's unavailable
+	if api.Compute.GPU > 0 {
+		gpuStats, err := getGPUStats(api)
+		if err != nil {
+			telemetry.Error(err)
+			errors.PrintError(err)
+		}
+		mergedMetrics.GPUStats = gpuStats
+	}
+	if api.Compute.Inf > 0 {
+		infStats, err := getInfStats(api)
+		if err != nil {
+			telemetry.Error(err)
+			errors.PrintError(err)
+		}
+		mergedMetrics.InfStats = infStats
+	}
 	return &mergedMetrics, nil
 }
 
-func getMetricsFunc(api *spec.API, period int64, startTime *time.Time, endTime *time.Time, metrics *metrics.Metrics) func() error {
+func getMetricsFunc(api *spec.API, customMetrics []customMetric, period int64, startTime *time.Time, endTime *time.Time, metrics *metrics.Metrics) func() error {
 	return func() error {
 		metricDataResults, err := queryMetrics(api, period, startTime, endTime)
 		if err != nil {
@@ -102,6 +128,20 @@ func getMetricsFunc(api *spec.API, period int64, startTime *time.Time, endTime *
 		}
 		metrics.NetworkStats = networkStats
 
+		streamStats, err := extractStreamMetrics(metricDataResults)
+		if err != nil {
+			return err
+		}
+		metrics.StreamStats = streamStats
+
+		if len(api.Predictor.Models) > 0 {
+			modelStats, err := getModelNetworkStats(api, period, startTime, endTime)
+			if err != nil {
+				return err
+			}
+			metrics.ModelStats = modelStats
+		}
+
 		if api.Monitoring != nil {
 			if api.Monitoring.ModelType == userconfig.ClassificationModelType {
 				metrics.ClassDistribution = extractClassificationMetrics(metricDataResults)
@@ -113,12 +153,19 @@ func getMetricsFunc(api *spec.API, period int64, startTime *time.Time, endTime *
 				metrics.RegressionStats = regressionStats
 			}
 		}
+
+		customStats, err := getCustomStats(api, customMetrics, period, startTime, endTime)
+		if err != nil {
+			return err
+		}
+		metrics.CustomStats = customStats
 		return nil
 	}
 }
 
 func queryMetrics(api *spec.API, period int64, startTime *time.Time, endTime *time.Time) ([]*cloudwatch.MetricDataResult, error) {
 	allMetrics := getNetworkStatsDef(api, period)
+	allMetrics = append(allMetrics, getStreamStatsDef(api, period)...)
 
 	if api.Monitoring != nil {
 		if api.Monitoring.ModelType == userconfig.ClassificationModelType {
@@ -179,6 +226,52 @@ func extractNetworkMetrics(metricsDataResults []*cloudwatch.MetricDataResult) (*
 	return &networkStats, nil
 }
 
+// extractStreamMetrics returns nil if the API hasn't streamed any responses
+func extractStreamMetrics(metricsDataResults []*cloudwatch.MetricDataResult) (*metrics.StreamStats, error) {
+	var streamStats metrics.StreamStats
+	var timeToFirstTokenAvgs []*float64
+	var streamCounts []*float64
+	var tokensPerSecondAvgs []*float64
+	var tokenRateCounts []*float64
+
+	for _, metricData := range metricsDataResults {
+		if metricData.Values == nil {
+			continue
+		}
+
+		switch {
+		case *metricData.Label == "TimeToFirstToken":
+			timeToFirstTokenAvgs = metricData.Values
+		case *metricData.Label == "StreamCount":
+			streamStats.Total = slices.Float64PtrSumInt(metricData.Values...)
+			streamCounts = metricData.Values
+		case *metricData.Label == "TokensPerSecond":
+			tokensPerSecondAvgs = metricData.Values
+		case *metricData.Label == "TokenRateCount":
+			streamStats.TokenRateTotal = slices.Float64PtrSumInt(metricData.Values...)
+			tokenRateCounts = metricData.Values
+		}
+	}
+
+	if streamStats.Total == 0 {
+		return nil, nil
+	}
+
+	avg, err := slices.Float64PtrAvg(timeToFirstTokenAvgs, streamCounts)
+	if err != nil {
+		return nil, err
+	}
+	streamStats.TimeToFirstToken = avg
+
+	avg, err = slices.Float64PtrAvg(tokensPerSecondAvgs, tokenRateCounts)
+	if err != nil {
+		return nil, err
+	}
+	streamStats.TokensPerSecond = avg
+
+	return &streamStats, nil
+}
+
 func extractClassificationMetrics(metricsDataResults []*cloudwatch.MetricDataResult) map[string]int {
 	classDistribution := map[string]int{}
 	for _, metricData := range metricsDataResults {
@@ -304,18 +397,83 @@ func getRegressionMetricDef(api *spec.API, period int64) []*cloudwatch.MetricDat
 	return regressionMetric
 }
 
+func getStreamStatsDef(api *spec.API, period int64) []*cloudwatch.MetricDataQuery {
+	timeToFirstTokenMetric := &cloudwatch.Metric{
+		Namespace:  aws.String(config.Cluster.ClusterName),
+		MetricName: aws.String("TimeToFirstToken"),
+		Dimensions: getAPIDimensionsHistogram(api),
+	}
+	tokensPerSecondMetric := &cloudwatch.Metric{
+		Namespace:  aws.String(config.Cluster.ClusterName),
+		MetricName: aws.String("TokensPerSecond"),
+		Dimensions: getAPIDimensionsHistogram(api),
+	}
+
+	return []*cloudwatch.MetricDataQuery{
+		{
+			Id:    aws.String("time_to_first_token"),
+			Label: aws.String("TimeToFirstToken"),
+			MetricStat: &cloudwatch.MetricStat{
+				Metric: timeToFirstTokenMetric,
+				Stat:   aws.String("Average"),
+				Period: aws.Int64(period),
+			},
+		},
+		{
+			Id:    aws.String("stream_count"),
+			Label: aws.String("StreamCount"),
+			MetricStat: &cloudwatch.MetricStat{
+				Metric: timeToFirstTokenMetric,
+				Stat:   aws.String("SampleCount"),
+				Period: aws.Int64(period),
+			},
+		},
+		{
+			Id:    aws.String("tokens_per_second"),
+			Label: aws.String("TokensPerSecond"),
+			MetricStat: &cloudwatch.MetricStat{
+				Metric: tokensPerSecondMetric,
+				Stat:   aws.String("Average"),
+				Period: aws.Int64(period),
+			},
+		},
+		{
+			Id:    aws.String("token_rate_count"),
+			Label: aws.String("TokenRateCount"),
+			MetricStat: &cloudwatch.MetricStat{
+				Metric: tokensPerSecondMetric,
+				Stat:   aws.String("SampleCount"),
+				Period: aws.Int64(period),
+			},
+		},
+	}
+}
+
 func getNetworkStatsDef(api *spec.API, period int64) []*cloudwatch.MetricDataQuery {
+	return networkStatsDefs(getAPIDimensions(api), "", period)
+}
+
+// networkStatsDefs returns the queries for the network stats of the metrics with the given dimensions; idSuffix distinguishes the queries when they are combined with others in one request
+func networkStatsDefs(dimensions []*cloudwatch.Dimension, idSuffix string, period int64) []*cloudwatch.MetricDataQuery {
+	counterDimensions := append(append([]*cloudwatch.Dimension{}, dimensions...), &cloudwatch.Dimension{
+		Name:  aws.String("metric_type"),
+		Value: aws.String("counter"),
+	})
+	histogramDimensions := append(append([]*cloudwatch.Dimension{}, dimensions...), &cloudwatch.Dimension{
+		Name:  aws.String("metric_type"),
+		Value: aws.String("histogram"),
+	})
+
 	statusCodes := []string{"2XX", "4XX", "5XX"}
 	networkDataQueries := make([]*cloudwatch.MetricDataQuery, len(statusCodes)+2)
 
 	for i, code := range statusCodes {
-		dimensions := getAPIDimensionsCounter(api)
-		statusCodeDimensions := append(dimensions, &cloudwatch.Dimension{
+		statusCodeDimensions := append(append([]*cloudwatch.Dimension{}, counterDimensions...), &cloudwatch.Dimension{
 			Name:  aws.String("Code"),
 			Value: aws.String(code),
 		})
 		networkDataQueries[i] = &cloudwatch.MetricDataQuery{
-			Id:    aws.String("datapoints_" + code),
+			Id:    aws.String("datapoints_" + code + idSuffix),
 			Label: aws.String(code),
 			MetricStat: &cloudwatch.MetricStat{
 				Metric: &cloudwatch.Metric{
@@ -330,13 +488,13 @@ func getNetworkStatsDef(api *spec.API, period int64) []*cloudwatch.MetricDataQue
 	}
 
 	networkDataQueries[3] = &cloudwatch.MetricDataQuery{
-		Id:    aws.String("latency"),
+		Id:    aws.String("latency" + idSuffix),
 		Label: aws.String("Latency"),
 		MetricStat: &cloudwatch.MetricStat{
 			Metric: &cloudwatch.Metric{
 				Namespace:  aws.String(config.Cluster.ClusterName),
 				MetricName: aws.String("Latency"),
-				Dimensions: getAPIDimensionsHistogram(api),
+				Dimensions: histogramDimensions,
 			},
 			Stat:   aws.String("Average"),
 			Period: aws.Int64(period),
@@ -344,13 +502,13 @@ func getNetworkStatsDef(api *spec.API, period int64) []*cloudwatch.MetricDataQue
 	}
 
 	networkDataQueries[4] = &cloudwatch.MetricDataQuery{
-		Id:    aws.String("request_count"),
+		Id:    aws.String("request_count" + idSuffix),
 		Label: aws.String("RequestCount"),
 		MetricStat: &cloudwatch.MetricStat{
 			Metric: &cloudwatch.Metric{
 				Namespace:  aws.String(config.Cluster.ClusterName),
 				MetricName: aws.String("Latency"),
-				Dimensions: getAPIDimensionsHistogram(api),
+				Dimensions: histogramDimensions,
 			},
 			Stat:   aws.String("SampleCount"),
 			Period: aws.Int64(period),
@@ -359,21 +517,84 @@ func getNetworkStatsDef(api *spec.API, period int64) []*cloudwatch.MetricDataQue
 	return networkDataQueries
 }
 
+// getModelNetworkStats queries the network stats of each of the API's models, in batches so that each request stays within cloudwatch's limit on the number of metrics
+func getModelNetworkStats(api *spec.API, period int64, startTime *time.Time, endTime *time.Time) (map[string]*metrics.NetworkStats, error) {
+	allStats := make([]*metrics.NetworkStats, len(api.Predictor.Models))
+	var fns []func() error
+
+	for batchStart := 0; batchStart < len(api.Predictor.Models); batchStart += consts.MaxModelsPerMetricsRequest {
+		batchStart := batchStart
+		batchEnd := batchStart + consts.MaxModelsPerMetricsRequest
+		if batchEnd > len(api.Predictor.Models) {
+			batchEnd = len(api.Predictor.Models)
+		}
+
+		fns = append(fns, func() error {
+			var queries []*cloudwatch.MetricDataQuery
+			for i := batchStart; i < batchEnd; i++ {
+				dimensions := append(getAPIDimensions(api), &cloudwatch.Dimension{
+					Name:  aws.String("ModelName"),
+					Value: aws.String(api.Predictor.Models[i].Name),
+				})
+				queries = append(queries, networkStatsDefs(dimensions, modelQueryIDSuffix(i), period)...)
+			}
+
+			output, err := config.AWS.CloudWatch().GetMetricData(&cloudwatch.GetMetricDataInput{
+				StartTime:         startTime,
+				EndTime:           endTime,
+				MetricDataQueries: queries,
+			})
+			if err != nil {
+				return err
+			}
+
+			for i := batchStart; i < batchEnd; i++ {
+				var modelResults []*cloudwatch.MetricDataResult
+				for _, result := range output.MetricDataResults {
+					if strings.HasSuffix(*result.Id, modelQueryIDSuffix(i)) {
+						modelResults = append(modelResults, result)
+					}
+				}
+				allStats[i], err = extractNetworkMetrics(modelResults)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	err := parallel.RunFirstErr(fns[0], fns[1:]...)
+	if err != nil {
+		return nil, err
+	}
+
+	modelStats := make(map[string]*metrics.NetworkStats, len(allStats))
+	for i, model := range api.Predictor.Models {
+		modelStats[model.Name] = allStats[i]
+	}
+	return modelStats, nil
+}
+
+// model names can't be used in query IDs, which may only contain letters, numbers, and underscores
+func modelQueryIDSuffix(modelIndex int) string {
+	return fmt.Sprintf("_model_%d", modelIndex)
+}
+
 func getClassesMetricDef(api *spec.API, period int64) ([]*cloudwatch.MetricDataQuery, error) {
 	prefix := filepath.Join(api.MetadataRoot, "classes") + "/"
-	classes, err := config.AWS.ListS3Prefix(config.Cluster.Bucket, prefix, false, pointer.Int64(int64(consts.MaxClassesPerMonitoringRequest)))
+	classKeys, err := config.Bucket.ListKeys(prefix, pointer.Int64(int64(consts.MaxClassesPerMonitoringRequest)))
 	if err != nil {
 		return nil, err
 	}
 
-	if len(classes) == 0 {
+	if len(classKeys) == 0 {
 		return nil, nil
 	}
 
 	classMetricQueries := []*cloudwatch.MetricDataQuery{}
 
-	for i, classObj := range classes {
-		classKey := *classObj.Key
+	for i, classKey := range classKeys {
 		urlSplit := strings.Split(classKey, "/")
 		encodedClassName := urlSplit[len(urlSplit)-1]
 		decodedBytes, err := base64.URLEncoding.DecodeString(encodedClassName)
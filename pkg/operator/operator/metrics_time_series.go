@@ -0,0 +1,215 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/metrics"
+)
+
+const (
+	DefaultMetricsTimeRange   = time.Hour
+	_maxTimeSeriesDatapoints  = 1440
+	_metricsLatencyStatPrefix = "latency_"
+)
+
+var _highResolutionPeriods = []int64{1, 5, 10, 30}
+
+// GetMetricsTimeSeries returns the API's request metrics between startTime and endTime; if apiID or modelName is set, only requests to that version of the API or that model are included, and if period is 0, it is chosen based on the time range
+func GetMetricsTimeSeries(apiName string, apiID string, modelName string, startTime time.Time, endTime time.Time, period int64) (*metrics.TimeSeries, error) {
+	if apiID != "" && modelName != "" {
+		return nil, ErrorConflictingMetricsFilters()
+	}
+
+	if !endTime.After(startTime) {
+		return nil, ErrorInvalidMetricsTimeRange(startTime, endTime)
+	}
+
+	if period == 0 {
+		period = defaultMetricsPeriod(endTime.Sub(startTime))
+	} else if period < 60 && !slices.HasInt64(_highResolutionPeriods, period) || period >= 60 && period%60 != 0 {
+		return nil, ErrorInvalidMetricsPeriod(period)
+	}
+
+	if int64(endTime.Sub(startTime).Seconds())/period > _maxTimeSeriesDatapoints {
+		return nil, ErrorTooManyMetricsDatapoints(period, _maxTimeSeriesDatapoints)
+	}
+
+	datapoints := map[time.Time]*metrics.Datapoint{}
+
+	input := &cloudwatch.GetMetricDataInput{
+		StartTime:         aws.Time(startTime),
+		EndTime:           aws.Time(endTime),
+		MetricDataQueries: getTimeSeriesDefs(apiName, apiID, modelName, period),
+	}
+	err := config.AWS.CloudWatch().GetMetricDataPages(input, func(output *cloudwatch.GetMetricDataOutput, lastPage bool) bool {
+		for _, result := range output.MetricDataResults {
+			for i := range result.Timestamps {
+				if result.Timestamps[i] == nil || result.Values[i] == nil {
+					continue
+				}
+				datapoint, ok := datapoints[*result.Timestamps[i]]
+				if !ok {
+					datapoint = &metrics.Datapoint{Timestamp: *result.Timestamps[i]}
+					datapoints[*result.Timestamps[i]] = datapoint
+				}
+				addToDatapoint(datapoint, *result.Id, *result.Values[i])
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	timeSeries := &metrics.TimeSeries{
+		APIName:    apiName,
+		APIID:      apiID,
+		ModelName:  modelName,
+		Period:     period,
+		Datapoints: make([]metrics.Datapoint, 0, len(datapoints)),
+	}
+	for _, datapoint := range datapoints {
+		datapoint.QPS = float64(datapoint.Requests) / float64(period)
+		timeSeries.Datapoints = append(timeSeries.Datapoints, *datapoint)
+	}
+	sort.Slice(timeSeries.Datapoints, func(i, j int) bool {
+		return timeSeries.Datapoints[i].Timestamp.Before(timeSeries.Datapoints[j].Timestamp)
+	})
+
+	return timeSeries, nil
+}
+
+// the shortest standard period which keeps the number of datapoints within the limit
+func defaultMetricsPeriod(timeRange time.Duration) int64 {
+	for _, period := range []int64{60, 5 * 60, 15 * 60, 60 * 60, 6 * 60 * 60} {
+		if int64(timeRange.Seconds())/period <= _maxTimeSeriesDatapoints {
+			return period
+		}
+	}
+	return 24 * 60 * 60
+}
+
+func addToDatapoint(datapoint *metrics.Datapoint, queryID string, value float64) {
+	switch queryID {
+	case "code_2xx":
+		datapoint.Code2XX = int(value)
+	case "code_4xx":
+		datapoint.Code4XX = int(value)
+	case "code_5xx":
+		datapoint.Code5XX = int(value)
+	case "requests":
+		datapoint.Requests = int(value)
+	case _metricsLatencyStatPrefix + "avg":
+		datapoint.LatencyAvg = pointer.Float64(value)
+	case _metricsLatencyStatPrefix + "p50":
+		datapoint.LatencyP50 = pointer.Float64(value)
+	case _metricsLatencyStatPrefix + "p95":
+		datapoint.LatencyP95 = pointer.Float64(value)
+	case _metricsLatencyStatPrefix + "p99":
+		datapoint.LatencyP99 = pointer.Float64(value)
+	}
+}
+
+func timeSeriesDimensions(apiName string, apiID string, modelName string, metricType string) []*cloudwatch.Dimension {
+	dimensions := []*cloudwatch.Dimension{
+		{
+			Name:  aws.String("APIName"),
+			Value: aws.String(apiName),
+		},
+		{
+			Name:  aws.String("metric_type"),
+			Value: aws.String(metricType),
+		},
+	}
+	if apiID != "" {
+		dimensions = append(dimensions, &cloudwatch.Dimension{
+			Name:  aws.String("APIID"),
+			Value: aws.String(apiID),
+		})
+	}
+	if modelName != "" {
+		dimensions = append(dimensions, &cloudwatch.Dimension{
+			Name:  aws.String("ModelName"),
+			Value: aws.String(modelName),
+		})
+	}
+	return dimensions
+}
+
+func getTimeSeriesDefs(apiName string, apiID string, modelName string, period int64) []*cloudwatch.MetricDataQuery {
+	var queries []*cloudwatch.MetricDataQuery
+
+	for _, code := range []string{"2XX", "4XX", "5XX"} {
+		queries = append(queries, &cloudwatch.MetricDataQuery{
+			Id: aws.String("code_" + strings.ToLower(code)),
+			MetricStat: &cloudwatch.MetricStat{
+				Metric: &cloudwatch.Metric{
+					Namespace:  aws.String(config.Cluster.ClusterName),
+					MetricName: aws.String("StatusCode"),
+					Dimensions: append(timeSeriesDimensions(apiName, apiID, modelName, "counter"), &cloudwatch.Dimension{
+						Name:  aws.String("Code"),
+						Value: aws.String(code),
+					}),
+				},
+				Stat:   aws.String("Sum"),
+				Period: aws.Int64(period),
+			},
+		})
+	}
+
+	latencyMetric := &cloudwatch.Metric{
+		Namespace:  aws.String(config.Cluster.ClusterName),
+		MetricName: aws.String("Latency"),
+		Dimensions: timeSeriesDimensions(apiName, apiID, modelName, "histogram"),
+	}
+
+	queries = append(queries, &cloudwatch.MetricDataQuery{
+		Id: aws.String("requests"),
+		MetricStat: &cloudwatch.MetricStat{
+			Metric: latencyMetric,
+			Stat:   aws.String("SampleCount"),
+			Period: aws.Int64(period),
+		},
+	})
+
+	for _, stat := range []string{"Average", "p50", "p95", "p99"} {
+		id := stat
+		if stat == "Average" {
+			id = "avg"
+		}
+		queries = append(queries, &cloudwatch.MetricDataQuery{
+			Id: aws.String(_metricsLatencyStatPrefix + id),
+			MetricStat: &cloudwatch.MetricStat{
+				Metric: latencyMetric,
+				Stat:   aws.String(stat),
+				Period: aws.Int64(period),
+			},
+		})
+	}
+
+	return queries
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"sync"
+
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	kapps "k8s.io/api/apps/v1"
+)
+
+const _apisGatewayName = "apis-gateway"
+
+var _sharedConfigMaps = []string{"env-vars"}
+
+// serializes the creation of namespaces, since the APIs in a namespace can be deployed concurrently
+var _namespacesMutex sync.Mutex
+
+// returns nil if the API is not deployed in any namespace
+func getAPIDeployment(apiName string) (*kapps.Deployment, error) {
+	deployments, err := config.K8sAllNamspaces.ListDeploymentsByLabel("apiName", apiName)
+	if err != nil {
+		return nil, err
+	}
+	if len(deployments) == 0 {
+		return nil, nil
+	}
+	return &deployments[0], nil
+}
+
+// returns the namespace that the API's resources are in (or the default namespace if the API is not deployed)
+func getAPINamespace(apiName string) (string, error) {
+	deployment, err := getAPIDeployment(apiName)
+	if err != nil {
+		return "", err
+	}
+	if deployment != nil {
+		return deployment.Namespace, nil
+	}
+
+	// the deployment may have already been deleted
+	routes, err := router().listRoutes()
+	if err != nil {
+		return "", err
+	}
+	for _, route := range routes {
+		if route.apiName == apiName {
+			return route.object.GetNamespace(), nil
+		}
+	}
+
+	return config.K8s.Namespace, nil
+}
+
+// the gateway is in the default namespace, so virtual services in other namespaces must qualify it
+func apisGateway(namespace string) string {
+	if namespace == config.K8s.Namespace {
+		return _apisGatewayName
+	}
+	return config.K8s.Namespace + "/" + _apisGatewayName
+}
+
+// creates the namespace if necessary, and copies the config maps and secrets that API pods depend on into it (including
+// the cluster's api_env_config_maps and api_env_secrets)
+func ensureNamespace(namespace string) error {
+	if namespace == config.K8s.Namespace {
+		return nil
+	}
+
+	_namespacesMutex.Lock()
+	defer _namespacesMutex.Unlock()
+
+	existing, err := config.K8s.GetNamespace(namespace)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		_, err := config.K8s.CreateNamespace(k8s.Namespace(&k8s.NamespaceSpec{
+			Name: namespace,
+			Labels: map[string]string{
+				"cortex.dev/managed": "true",
+			},
+		}))
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := ensureWorkloadServiceAccount(namespace); err != nil {
+		return err
+	}
+
+	k8sNamespace := config.K8sNamespace(namespace)
+
+	configMapNames := append(append([]string{}, _sharedConfigMaps...), config.Cluster.APIEnvConfigMaps...)
+	for i, configMapName := range configMapNames {
+		data, err := config.K8s.GetConfigMapData(configMapName)
+		if err != nil {
+			return err
+		}
+		if data == nil {
+			if i >= len(_sharedConfigMaps) {
+				return ErrorEnvSourceNotFound("config map", configMapName, config.K8s.Namespace)
+			}
+			return ErrorCortexInstallationBroken()
+		}
+		if _, err := k8sNamespace.ApplyConfigMap(k8s.ConfigMap(&k8s.ConfigMapSpec{
+			Name: configMapName,
+			Data: data,
+		})); err != nil {
+			return err
+		}
+	}
+
+	shared := sharedSecrets()
+	secretNames := append(append([]string{}, shared...), config.Cluster.APIEnvSecrets...)
+	for i, secretName := range secretNames {
+		data, err := config.K8s.GetSecretData(secretName)
+		if err != nil {
+			return err
+		}
+		if data == nil {
+			if i >= len(shared) {
+				return ErrorEnvSourceNotFound("secret", secretName, config.K8s.Namespace)
+			}
+			return ErrorCortexInstallationBroken()
+		}
+		if _, err := k8sNamespace.ApplySecret(k8s.Secret(&k8s.SecretSpec{
+			Name: secretName,
+			Data: data,
+		})); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
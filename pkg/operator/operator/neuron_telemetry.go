@@ -0,0 +1,105 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/lib/prometheus"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/metrics"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+)
+
+const (
+	// exported by neuron-monitor-prometheus.py in each inferentia pod's neuron-monitor container
+	_neuronCoreUtilMetric       = "neuroncore_utilization_ratio"     // 0 to 1, labeled with the neuroncore
+	_neuronMemoryUsedMetric     = "neuron_runtime_memory_used_bytes" // labeled with the memory_location
+	_neuronDeviceMemoryLocation = "neuron_device"
+	_neuronHostMemoryLocation   = "host"
+)
+
+// getInfStats returns the current utilization of the inferentia chips which are allocated to each of the api's pods (keyed by pod name)
+func getInfStats(api *spec.API) (map[string]*metrics.InfStats, error) {
+	deployment, err := getAPIDeployment(api.Name)
+	if err != nil {
+		return nil, err
+	}
+	if deployment == nil {
+		return nil, nil
+	}
+
+	pods, err := config.K8sNamespace(deployment.Namespace).ListPodsByLabel("apiName", api.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	statsByPod := map[string]*metrics.InfStats{}
+	for podName, samples := range scrapePods(pods, _neuronMonitorPortStr) {
+		if stats := infStatsFromSamples(samples); stats != nil {
+			statsByPod[podName] = stats
+		}
+	}
+
+	if len(statsByPod) == 0 {
+		return nil, nil
+	}
+	return statsByPod, nil
+}
+
+// returns nil if neuron-monitor hasn't reported any of the pod's NeuronCores yet (e.g. if the runtime is still starting)
+func infStatsFromSamples(samples []prometheus.Sample) *metrics.InfStats {
+	neuronCores := strset.New()
+	var utilSum, deviceMemoryUsed, hostMemoryUsed float64
+	var hasDeviceMemory, hasHostMemory bool
+
+	for _, sample := range samples {
+		switch sample.Name {
+		case _neuronCoreUtilMetric:
+			neuronCore := sample.Labels["neuroncore"]
+			if !neuronCores.Has(neuronCore) {
+				neuronCores.Add(neuronCore)
+				utilSum += sample.Value
+			}
+		case _neuronMemoryUsedMetric:
+			switch sample.Labels["memory_location"] {
+			case _neuronDeviceMemoryLocation:
+				deviceMemoryUsed += sample.Value / (1024 * 1024)
+				hasDeviceMemory = true
+			case _neuronHostMemoryLocation:
+				hostMemoryUsed += sample.Value / (1024 * 1024)
+				hasHostMemory = true
+			}
+		}
+	}
+
+	if len(neuronCores) == 0 {
+		return nil
+	}
+
+	stats := metrics.InfStats{
+		NeuronCores: len(neuronCores),
+		Utilization: pointer.Float64(100 * utilSum / float64(len(neuronCores))),
+	}
+	if hasDeviceMemory {
+		stats.DeviceMemoryUsed = pointer.Float64(deviceMemoryUsed)
+	}
+	if hasHostMemory {
+		stats.HostMemoryUsed = pointer.Float64(hostMemoryUsed)
+	}
+	return &stats
+}
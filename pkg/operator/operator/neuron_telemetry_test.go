@@ -0,0 +1,52 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"testing"
+
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/lib/prometheus"
+	"github.com/cortexlabs/cortex/pkg/types/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfStatsFromSamples(t *testing.T) {
+	samples := []prometheus.Sample{
+		{Name: _neuronCoreUtilMetric, Labels: map[string]string{"neuroncore": "0"}, Value: 0.5},
+		{Name: _neuronCoreUtilMetric, Labels: map[string]string{"neuroncore": "1"}, Value: 0.25},
+		{Name: _neuronCoreUtilMetric, Labels: map[string]string{"neuroncore": "2"}, Value: 0},
+		{Name: _neuronCoreUtilMetric, Labels: map[string]string{"neuroncore": "3"}, Value: 0.25},
+		{Name: _neuronMemoryUsedMetric, Labels: map[string]string{"memory_location": _neuronDeviceMemoryLocation}, Value: 512 * 1024 * 1024},
+		{Name: _neuronMemoryUsedMetric, Labels: map[string]string{"memory_location": _neuronHostMemoryLocation}, Value: 64 * 1024 * 1024},
+		{Name: "execution_errors_total", Labels: map[string]string{}, Value: 3},
+	}
+
+	require.Equal(t, &metrics.InfStats{
+		NeuronCores:      4,
+		Utilization:      pointer.Float64(25),
+		DeviceMemoryUsed: pointer.Float64(512),
+		HostMemoryUsed:   pointer.Float64(64),
+	}, infStatsFromSamples(samples))
+
+	require.Equal(t, &metrics.InfStats{
+		NeuronCores: 1,
+		Utilization: pointer.Float64(50),
+	}, infStatsFromSamples(samples[:1]))
+
+	require.Nil(t, infStatsFromSamples(samples[4:]))
+}
@@ -0,0 +1,391 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	kcore "k8s.io/api/core/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	_nodeMigrationKind            = "node_migrations"
+	_nodeMigrationKey             = "latest"
+	_nodeGenerationLabelKey       = "cortex.dev/node-generation" // set on the nodes of the node groups which are created by a cluster upgrade
+	_nodeMigrationCheckPeriod     = 10 * time.Second
+	_nodeMigrationAPITimeout      = 30 * time.Minute
+	_nodeMigrationEvictionTimeout = 10 * time.Minute
+	_maxConcurrentAPIMigrations   = 5
+)
+
+// whether a node migration is running in this process (only one migration can run at a time)
+var _isNodeMigrationRunning bool
+var _nodeMigrationMutex sync.Mutex
+
+type nodeMigrationRun struct {
+	sync.Mutex
+	migration *schema.NodeMigration
+}
+
+// StartNodeMigration cordons the nodes which don't belong to the generation, moves the APIs' replicas onto the
+// generation's nodes by rolling each API (which respects its max_surge and max_unavailable, and waits for the new
+// replicas to be ready), and then evicts the old nodes' other pods (which respects their pod disruption budgets); the
+// migration runs in the background, and its progress can be checked with GetNodeMigration()
+func StartNodeMigration(generation string) (*schema.NodeMigration, error) {
+	_nodeMigrationMutex.Lock()
+	defer _nodeMigrationMutex.Unlock()
+
+	if _isNodeMigrationRunning {
+		return nil, ErrorNodeMigrationInProgress()
+	}
+
+	nodes, err := config.K8s.ListNodes(nil)
+	if err != nil {
+		return nil, err
+	}
+	var newNodes []string
+	var oldNodes []string
+	for _, node := range nodes {
+		if node.Labels[_nodeGenerationLabelKey] == generation {
+			newNodes = append(newNodes, node.Name)
+		} else {
+			oldNodes = append(oldNodes, node.Name)
+		}
+	}
+	if len(newNodes) == 0 {
+		return nil, ErrorNoNodesInGeneration(generation)
+	}
+
+	run := &nodeMigrationRun{
+		migration: &schema.NodeMigration{
+			Generation: generation,
+			Status:     schema.NodeMigrationStatusRunning,
+			StartedAt:  time.Now().Unix(),
+			Nodes:      oldNodes,
+		},
+	}
+	if err := run.save(); err != nil {
+		return nil, err
+	}
+
+	_isNodeMigrationRunning = true
+	go run.run()
+
+	migration := *run.migration
+	return &migration, nil
+}
+
+func GetNodeMigration() (*schema.NodeMigration, error) {
+	var migration schema.NodeMigration
+	exists, err := config.Metadata.Get(_nodeMigrationKind, _nodeMigrationKey, &migration)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrorNodeMigrationNotFound()
+	}
+	return &migration, nil
+}
+
+// a migration which was running when the previous leader stopped is resumed by the new leader
+func resumeNodeMigration() error {
+	var migration schema.NodeMigration
+	exists, err := config.Metadata.Get(_nodeMigrationKind, _nodeMigrationKey, &migration)
+	if err != nil || !exists || migration.Status != schema.NodeMigrationStatusRunning {
+		return err
+	}
+
+	_nodeMigrationMutex.Lock()
+	defer _nodeMigrationMutex.Unlock()
+	if _isNodeMigrationRunning {
+		return nil
+	}
+
+	_isNodeMigrationRunning = true
+	run := &nodeMigrationRun{migration: &migration}
+	go run.run()
+
+	return nil
+}
+
+func (run *nodeMigrationRun) run() {
+	defer func() {
+		_nodeMigrationMutex.Lock()
+		_isNodeMigrationRunning = false
+		_nodeMigrationMutex.Unlock()
+	}()
+
+	run.finish(run.migrate())
+}
+
+func (run *nodeMigrationRun) migrate() error {
+	generation := run.migration.Generation
+
+	oldNodes, err := cordonOldNodes(generation)
+	if err != nil {
+		return err
+	}
+
+	pods, err := listAPIPods(klabels.Everything())
+	if err != nil {
+		return err
+	}
+	apiNames := strset.New()
+	for _, pod := range pods {
+		if oldNodes.Has(pod.Spec.NodeName) {
+			apiNames.Add(pod.Labels["apiName"])
+		}
+	}
+
+	// when a migration is resumed, the APIs which were already migrated don't have replicas on the old nodes
+	run.Lock()
+	run.migration.APIs = run.migration.MigratedAPIs + len(apiNames)
+	run.Unlock()
+	if err := run.save(); err != nil {
+		return err
+	}
+
+	sem := make(chan struct{}, _maxConcurrentAPIMigrations)
+	errs := make([]error, 0, len(apiNames))
+	var errsMutex sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, apiName := range apiNames.SliceSorted() {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(apiName string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := migrateAPI(apiName, generation); err != nil {
+				errsMutex.Lock()
+				errs = append(errs, err)
+				errsMutex.Unlock()
+				return
+			}
+
+			run.Lock()
+			run.migration.MigratedAPIs++
+			run.Unlock()
+			if err := run.save(); err != nil {
+				errors.PrintError(err, "failed to save the progress of the node migration")
+			}
+		}(apiName)
+	}
+	wg.Wait()
+
+	if errors.HasError(errs) {
+		return errors.FirstError(errs...)
+	}
+
+	return evictOldPods(generation)
+}
+
+// migrateAPI rolls the API's replicas which are running on the old nodes onto the new nodes, and waits until all of
+// its replicas are on the new nodes and it has at least min_replicas ready replicas
+func migrateAPI(apiName string, generation string) error {
+	deadline := time.Now().Add(_nodeMigrationAPITimeout)
+
+	for {
+		// the old node groups' autoscalers may have added nodes since the migration started
+		oldNodes, err := cordonOldNodes(generation)
+		if err != nil {
+			return err
+		}
+
+		deployment, err := getAPIDeployment(apiName)
+		if err != nil {
+			return err
+		}
+		if deployment == nil {
+			return nil
+		}
+
+		pods, err := listAPIPods(klabels.SelectorFromSet(map[string]string{"apiName": apiName}))
+		if err != nil {
+			return err
+		}
+
+		var hasOldPods bool
+		var hasLatestOldPods bool // old replicas which won't be replaced by an update which is already in progress
+		for i := range pods {
+			if oldNodes.Has(pods[i].Spec.NodeName) {
+				hasOldPods = true
+				if isPodSpecLatest(deployment, &pods[i]) {
+					hasLatestOldPods = true
+				}
+			}
+		}
+
+		isUpdating, err := isAPIUpdating(deployment)
+		if err != nil {
+			return err
+		}
+
+		if !hasOldPods && !isUpdating {
+			return nil
+		}
+
+		if hasLatestOldPods && !isUpdating {
+			if _, err := RefreshAPI(apiName, false); err != nil && errors.GetKind(err) != ErrAPIUpdating {
+				return err
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return ErrorNodeMigrationTimeout(apiName, _nodeMigrationAPITimeout)
+		}
+		time.Sleep(_nodeMigrationCheckPeriod)
+	}
+}
+
+// evictOldPods evicts the pods which remain on the old nodes (e.g. the cluster's system pods), other than daemon set
+// pods (which run on every node) and this replica's pod (which is evicted when the old nodes are deleted)
+func evictOldPods(generation string) error {
+	deadline := time.Now().Add(_nodeMigrationEvictionTimeout)
+
+	for {
+		oldNodes, err := cordonOldNodes(generation)
+		if err != nil {
+			return err
+		}
+
+		pods, err := config.K8sAllNamspaces.ListPods(nil)
+		if err != nil {
+			return err
+		}
+
+		var blockedPods []string
+		for i := range pods {
+			pod := &pods[i]
+			if !oldNodes.Has(pod.Spec.NodeName) || !isEvictablePod(pod) {
+				continue
+			}
+
+			evicted, err := config.K8sNamespace(pod.Namespace).EvictPod(pod.Name)
+			if err != nil {
+				return err
+			}
+			if !evicted {
+				blockedPods = append(blockedPods, pod.Namespace+"/"+pod.Name)
+			}
+		}
+
+		if len(blockedPods) == 0 {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return ErrorPodEvictionTimeout(blockedPods, _nodeMigrationEvictionTimeout)
+		}
+		time.Sleep(_nodeMigrationCheckPeriod)
+	}
+}
+
+func isEvictablePod(pod *kcore.Pod) bool {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase == kcore.PodSucceeded || pod.Status.Phase == kcore.PodFailed {
+		return false
+	}
+	if pod.Name == os.Getenv(_operatorPodNameEnv) {
+		return false
+	}
+	if _, ok := pod.Annotations[kcore.MirrorPodAnnotationKey]; ok {
+		return false
+	}
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == "DaemonSet" {
+			return false
+		}
+	}
+	return true
+}
+
+// cordonOldNodes marks the nodes which don't belong to the generation as unschedulable; returns the old nodes' names
+func cordonOldNodes(generation string) (strset.Set, error) {
+	return setOldNodesUnschedulable(generation, true)
+}
+
+func uncordonOldNodes(generation string) error {
+	_, err := setOldNodesUnschedulable(generation, false)
+	return err
+}
+
+func setOldNodesUnschedulable(generation string, unschedulable bool) (strset.Set, error) {
+	nodes, err := config.K8s.ListNodes(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	oldNodes := strset.New()
+	for i := range nodes {
+		node := &nodes[i]
+		if node.Labels[_nodeGenerationLabelKey] == generation {
+			continue
+		}
+		oldNodes.Add(node.Name)
+
+		if node.Spec.Unschedulable == unschedulable {
+			continue
+		}
+		node.Spec.Unschedulable = unschedulable
+		if _, err := config.K8s.UpdateNode(node); err != nil {
+			return nil, err
+		}
+	}
+
+	return oldNodes, nil
+}
+
+// if the migration fails, the old nodes are uncordoned so that their capacity can be used until the upgrade is retried
+func (run *nodeMigrationRun) finish(err error) {
+	run.Lock()
+	run.migration.Status = schema.NodeMigrationStatusSucceeded
+	if err != nil {
+		run.migration.Status = schema.NodeMigrationStatusFailed
+		run.migration.Error = errors.Message(err)
+	}
+	finishedAt := time.Now().Unix()
+	run.migration.FinishedAt = &finishedAt
+	run.Unlock()
+
+	if err != nil {
+		if err := uncordonOldNodes(run.migration.Generation); err != nil {
+			errors.PrintError(err, "failed to uncordon the old nodes")
+		}
+	}
+
+	if err := run.save(); err != nil {
+		errors.PrintError(err, "failed to save the node migration")
+	}
+}
+
+func (run *nodeMigrationRun) save() error {
+	run.Lock()
+	migration := *run.migration
+	run.Unlock()
+
+	return config.Metadata.Put(_nodeMigrationKind, _nodeMigrationKey, migration)
+}
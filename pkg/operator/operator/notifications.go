@@ -0,0 +1,303 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/parallel"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/status"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	kapps "k8s.io/api/apps/v1"
+	kcore "k8s.io/api/core/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	_notificationCheckPeriod = 10 * time.Second
+	_notificationTimeout     = 10 * time.Second
+
+	_deployStartedEvent   = "deploy_started"
+	_deploySucceededEvent = "deploy_succeeded"
+	_deployFailedEvent    = "deploy_failed"
+	_crashLoopingEvent    = "crash_looping"
+	_oomKilledEvent       = "oom_killed"
+	_scaledToMaxEvent     = "scaled_to_max"
+)
+
+type notification struct {
+	ClusterName string `json:"cluster_name"`
+	APIName     string `json:"api_name"`
+	APIID       string `json:"api_id"`
+	Event       string `json:"event"`
+	Message     string `json:"message"`
+	Timestamp   int64  `json:"timestamp"`
+}
+
+var (
+	_notificationsMutex    sync.Mutex
+	_apiNotifications      = make(map[string]*userconfig.Notifications) // apiID -> the api's notifications config (nil if it has none)
+	_rollouts              = make(map[string]string)                    // apiName -> apiID of the rollout which is in progress
+	_sentNotifications     = newEventCache(_maxCacheSize)               // ids of container failures which have already been reported
+	_notificationsInitTime = time.Now()
+
+	_notificationHTTPClient = &http.Client{Timeout: _notificationTimeout}
+)
+
+// notifyDeployStarted is called after an api's deployment has been applied, and starts tracking the rollout so that its result can be reported
+func notifyDeployStarted(api *spec.API, message string) {
+	_notificationsMutex.Lock()
+	_apiNotifications[api.ID] = api.Notifications
+	_rollouts[api.Name] = api.ID
+	_notificationsMutex.Unlock()
+
+	notify(api.Name, api.ID, _deployStartedEvent, message)
+}
+
+func forgetAPINotifications(apiName string) {
+	_notificationsMutex.Lock()
+	defer _notificationsMutex.Unlock()
+	delete(_rollouts, apiName)
+}
+
+// notify is best effort; notifications are sent in the background, and failures are printed but otherwise ignored
+func notify(apiName string, apiID string, event string, message string) {
+	n := notification{
+		ClusterName: config.Cluster.ClusterName,
+		APIName:     apiName,
+		APIID:       apiID,
+		Event:       event,
+		Message:     message,
+		Timestamp:   time.Now().Unix(),
+	}
+
+	go func() {
+		if err := sendNotification(n, getAPINotifications(apiName, apiID)); err != nil {
+			errors.PrintError(err, fmt.Sprintf("failed to send %s notification for %s", event, apiName))
+		}
+	}()
+}
+
+// the api's notifications config is downloaded once per api id, and only when there is an event to report
+func getAPINotifications(apiName string, apiID string) *userconfig.Notifications {
+	_notificationsMutex.Lock()
+	notifications, ok := _apiNotifications[apiID]
+	_notificationsMutex.Unlock()
+	if ok {
+		return notifications
+	}
+
+	api, err := DownloadAPISpec(apiName, apiID)
+	if err != nil {
+		errors.PrintError(err, "failed to get the notifications config of "+apiName)
+		return nil
+	}
+
+	_notificationsMutex.Lock()
+	_apiNotifications[apiID] = api.Notifications
+	_notificationsMutex.Unlock()
+
+	return api.Notifications
+}
+
+func sendNotification(n notification, apiNotifications *userconfig.Notifications) error {
+	var slackWebhooks, webhooks, snsTopics []string
+
+	if clusterNotifications := config.Cluster.Notifications; clusterNotifications != nil {
+		appendIfSet(&slackWebhooks, clusterNotifications.SlackWebhook)
+		appendIfSet(&webhooks, clusterNotifications.Webhook)
+		appendIfSet(&snsTopics, clusterNotifications.SNSTopic)
+	}
+	if apiNotifications != nil {
+		appendIfSet(&slackWebhooks, apiNotifications.SlackWebhook)
+		appendIfSet(&webhooks, apiNotifications.Webhook)
+		appendIfSet(&snsTopics, apiNotifications.SNSTopic)
+	}
+
+	var fns []func() error
+
+	for _, slackWebhook := range slackWebhooks {
+		webhookURL := slackWebhook
+		fns = append(fns, func() error {
+			text := fmt.Sprintf("[%s] %s: %s", n.ClusterName, n.APIName, n.Message)
+			return postNotification(webhookURL, "slack webhook", map[string]string{"text": text})
+		})
+	}
+
+	for _, webhook := range webhooks {
+		webhookURL := webhook
+		fns = append(fns, func() error {
+			return postNotification(webhookURL, "webhook", n)
+		})
+	}
+
+	for _, snsTopic := range snsTopics {
+		topicARN := snsTopic
+		fns = append(fns, func() error {
+			message, err := libjson.MarshalJSONStr(n)
+			if err != nil {
+				return err
+			}
+			subject := fmt.Sprintf("cortex %s: %s %s", n.ClusterName, n.APIName, n.Event)
+			return config.AWS.PublishSNSMessage(topicARN, subject, message)
+		})
+	}
+
+	if len(fns) == 0 {
+		return nil
+	}
+
+	return parallel.RunFirstErr(fns[0], fns[1:]...)
+}
+
+func appendIfSet(strs *[]string, str *string) {
+	if str != nil && *str != "" {
+		*strs = append(*strs, *str)
+	}
+}
+
+func postNotification(webhookURL string, destination string, body interface{}) error {
+	jsonBytes, err := libjson.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	response, err := _notificationHTTPClient.Post(webhookURL, "application/json", bytes.NewReader(jsonBytes))
+	if err != nil {
+		// the url isn't included in the error since it typically embeds a secret token
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		return errors.Wrap(err, destination)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		responseBytes, _ := ioutil.ReadAll(response.Body)
+		return ErrorNotificationFailed(destination, response.StatusCode, string(responseBytes))
+	}
+
+	return nil
+}
+
+// checkNotificationEvents reports the results of rollouts which were started by this operator, as well as containers which are crash looping or have run out of memory
+func checkNotificationEvents() error {
+	deployments, err := listAPIDeployments(klabels.Everything())
+	if err != nil {
+		return err
+	}
+
+	pods, err := listAPIPods(klabels.Everything())
+	if err != nil {
+		return err
+	}
+
+	warnings := map[string][]status.HealthWarning{}
+	apiIDs := map[string]string{}
+	podNames := strset.New()
+
+	for i := range deployments {
+		deployment := &deployments[i]
+		apiName := deployment.Labels["apiName"]
+
+		var apiPods []kcore.Pod
+		for _, pod := range pods {
+			if pod.Labels["apiName"] == apiName {
+				apiPods = append(apiPods, pod)
+				podNames.Add(pod.Name)
+			}
+		}
+
+		checkRollout(deployment, apiPods)
+		checkContainerFailures(deployment, apiPods)
+
+		if apiWarnings := checkAPIHealth(deployment, apiPods); len(apiWarnings) > 0 {
+			warnings[apiName] = apiWarnings
+			apiIDs[apiName] = deployment.Labels["apiID"]
+		}
+	}
+
+	return updateHealthWarnings(warnings, apiIDs, podNames)
+}
+
+func checkRollout(deployment *kapps.Deployment, pods []kcore.Pod) {
+	apiName := deployment.Labels["apiName"]
+	apiID := deployment.Labels["apiID"]
+
+	_notificationsMutex.Lock()
+	rolloutAPIID, ok := _rollouts[apiName]
+	_notificationsMutex.Unlock()
+	if !ok || rolloutAPIID != apiID {
+		return
+	}
+
+	counts := getReplicaCounts(deployment, pods)
+
+	if counts.Updated.Ready >= counts.Requested && counts.Stale.Total() == 0 {
+		forgetAPINotifications(apiName)
+		notify(apiName, apiID, _deploySucceededEvent, fmt.Sprintf("%s is live (%s)", apiName, progressSummary(&counts)))
+		return
+	}
+
+	if counts.Updated.TotalFailed() > 0 {
+		forgetAPINotifications(apiName)
+		notify(apiName, apiID, _deployFailedEvent, fmt.Sprintf("%s failed to roll out (%s); run `cortex get %s` and `cortex logs %s` for more details", apiName, progressSummary(&counts), apiName, apiName))
+	}
+}
+
+func checkContainerFailures(deployment *kapps.Deployment, pods []kcore.Pod) {
+	apiName := deployment.Labels["apiName"]
+
+	for i := range pods {
+		pod := &pods[i]
+		apiID := pod.Labels["apiID"]
+
+		for _, containerStatus := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+			if waiting := containerStatus.State.Waiting; waiting != nil && waiting.Reason == "CrashLoopBackOff" {
+				// only reported once per container, since crash looping containers are restarted indefinitely
+				id := pod.Name + "/" + containerStatus.Name + "/" + _crashLoopingEvent
+				if !_sentNotifications.Has(id) {
+					_sentNotifications.Add(id)
+					notify(apiName, apiID, _crashLoopingEvent, containerProblemMessage(apiName, pod.Name, containerStatus.Name, waiting.Reason, ""))
+				}
+			}
+
+			for _, terminated := range []*kcore.ContainerStateTerminated{containerStatus.LastTerminationState.Terminated, containerStatus.State.Terminated} {
+				if terminated == nil || terminated.Reason != "OOMKilled" || terminated.FinishedAt.Time.Before(_notificationsInitTime) {
+					continue
+				}
+				id := pod.Name + "/" + containerStatus.Name + "/" + terminated.FinishedAt.String() + "/" + _oomKilledEvent
+				if !_sentNotifications.Has(id) {
+					_sentNotifications.Add(id)
+					recordOOMKill(apiName, terminated.FinishedAt.Time)
+					notify(apiName, apiID, _oomKilledEvent, containerProblemMessage(apiName, pod.Name, containerStatus.Name, terminated.Reason, ""))
+				}
+			}
+		}
+	}
+}
@@ -23,29 +23,76 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
 	"github.com/cortexlabs/cortex/pkg/operator/config"
+	klabels "k8s.io/apimachinery/pkg/labels"
 )
 
 func Init() error {
 	telemetry.Event("operator.init")
 
+	if err := initInformers(); err != nil {
+		return errors.Wrap(err, "init")
+	}
+
+	if err := initLeaderElection(); err != nil {
+		return errors.Wrap(err, "init")
+	}
+
+	return nil
+}
+
+// startLeaderTasks starts the operator's background tasks, which only run on the leader (see leader.go)
+func startLeaderTasks() error {
 	_, err := updateMemoryCapacityConfigMap()
 	if err != nil {
-		return errors.Wrap(err, "init")
+		return err
 	}
 
-	deployments, err := config.K8s.ListDeploymentsWithLabelKeys("apiName")
+	deployments, err := listAPIDeployments(klabels.Everything())
 	if err != nil {
 		return err
 	}
 
 	for _, deployment := range deployments {
+		if isAPIPaused(&deployment) {
+			continue
+		}
 		if err := updateAutoscalerCron(&deployment); err != nil {
 			return err
 		}
 	}
 
+	if err := resumeNodeMigration(); err != nil {
+		return err
+	}
+
 	cron.Run(deleteEvictedPods, cronErrHandler("delete evicted pods"), 12*time.Hour)
+	cron.Run(deleteSucceededModelOptimizerJobs, cronErrHandler("delete succeeded model optimizer jobs"), 1*time.Hour)
+	cron.Run(deleteOrphanedLoadTestJobs, cronErrHandler("delete orphaned load test jobs"), 1*time.Hour)
+	cron.Run(updateDependencyBuilds, cronErrHandler("update dependency builds"), 10*time.Second)
 	cron.Run(operatorTelemetry, cronErrHandler("operator telemetry"), 1*time.Hour)
+	cron.Run(updateFallbackRoutes, cronErrHandler("update fallback routes"), 10*time.Second)
+	cron.Run(updateVersionPinningRoutes, cronErrHandler("update version pinning routes"), 10*time.Second)
+	cron.Run(updateMaintenanceEnvoyFilter, cronErrHandler("update maintenance envoy filter"), 10*time.Second)
+	cron.Run(reconcileCortexAPIs, cronErrHandler("reconcile cortex apis"), 10*time.Second)
+	cron.Run(checkNotificationEvents, cronErrHandler("check notification events"), _notificationCheckPeriod)
+	cron.Run(checkRollouts, cronErrHandler("check rollouts"), _rolloutCheckPeriod)
+	cron.Run(checkExperiments, cronErrHandler("check experiments"), _experimentCheckPeriod)
+	cron.Run(checkSLOs, cronErrHandler("check slos"), _sloCheckPeriod)
+	cron.Run(scheduleBatchJobs, cronErrHandler("schedule batch jobs"), _batchJobSchedulePeriod)
+	cron.Run(sendBatchJobCallbacks, cronErrHandler("send batch job callbacks"), _batchJobSchedulePeriod)
+	cron.Run(processBatchTriggers, cronErrHandler("process batch triggers"), _batchJobSchedulePeriod)
+
+	// lightweight and gcp clusters don't have instance prices or cloudwatch metrics
+	if config.Cluster.HasAWSResources() {
+		cron.Run(recordCosts, cronErrHandler("record costs"), _costSamplePeriod)
+		cron.Run(pauseIdleAPIs, cronErrHandler("pause idle apis"), _idleCheckPeriod)
+	}
+
+	if config.Cluster.Spot != nil && *config.Cluster.Spot {
+		cron.Run(drainInterruptedSpotNodes, cronErrHandler("drain interrupted spot nodes"), 15*time.Second)
+	}
+
+	startAPIEventWorkers()
 
 	return nil
 }
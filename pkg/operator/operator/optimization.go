@@ -0,0 +1,205 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"path"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/hash"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	kbatch "k8s.io/api/batch/v1"
+	kcore "k8s.io/api/core/v1"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	_modelOptimizerContainerName = "model-optimizer"
+	_modelOptimizerLabelKey      = "modelOptimizer"
+	_optimizedModelsDir          = "optimized_models"
+)
+
+type optimizationConfig struct {
+	From          string `json:"from"`
+	VersionID     string `json:"version_id"`
+	To            string `json:"to"` // S3 directory to upload the optimized model and the success/failure file to
+	PredictorType string `json:"predictor_type"`
+	Precision     string `json:"precision"`
+}
+
+// optimizeModels starts a job to optimize each of the API's models for the GPU type that the API runs on (unless the
+// optimized model is already cached in S3), and records the paths which the API's replicas download the optimized models from
+func optimizeModels(api *spec.API) error {
+	if api.Predictor.ModelOptimization == nil {
+		return nil
+	}
+
+	instanceFamily := apiInstanceFamily(api)
+
+	optimizedModels := map[string]string{}
+	for _, model := range api.Predictor.Models {
+		optimizedDir := optimizedModelDir(api, model.Model, instanceFamily)
+		optimizedModels[model.Model] = config.Bucket.Path(path.Join(optimizedDir, optimizedModelName(api)))
+
+		if err := ensureModelOptimized(api, model.Model, optimizedDir); err != nil {
+			return errors.Wrap(err, "optimize model", model.Name)
+		}
+	}
+
+	api.OptimizedModels = optimizedModels
+	return nil
+}
+
+func ensureModelOptimized(api *spec.API, modelPath string, optimizedDir string) error {
+	jobName := modelOptimizerJobName(optimizedDir)
+
+	isOptimized, err := config.Bucket.Exists(path.Join(optimizedDir, _successMarkerFile))
+	if err != nil {
+		return err
+	}
+	if isOptimized {
+		return nil
+	}
+
+	job, err := config.K8s.GetJob(jobName)
+	if err != nil {
+		return err
+	}
+	if job != nil && job.Status.CompletionTime == nil && job.Status.Failed == 0 {
+		return nil // the model is being optimized (possibly for another API which uses the same model)
+	}
+
+	// retry models which could not be optimized
+	if job != nil {
+		if _, err := config.K8s.DeleteJob(jobName); err != nil {
+			return err
+		}
+	}
+	if err := config.Bucket.DeleteFile(path.Join(optimizedDir, _failureMarkerFile)); err != nil {
+		return err
+	}
+
+	_, err = config.K8s.CreateJob(modelOptimizerJobSpec(api, jobName, optimizationConfig{
+		From:          modelPath,
+		VersionID:     api.ModelVersionIDs[modelPath],
+		To:            config.Bucket.Path(optimizedDir),
+		PredictorType: api.Predictor.Type.String(),
+		Precision:     api.Predictor.ModelOptimization.Precision.String(),
+	}))
+	return err
+}
+
+// optimized models are cached by model (and version, for models which are pinned), precision, and instance family,
+// since TensorRT engines and ONNX Runtime's optimized graphs are specific to the GPU that they were built on
+func optimizedModelDir(api *spec.API, modelPath string, instanceFamily string) string {
+	modelID := hash.String(
+		api.Predictor.Type.String() +
+			modelPath +
+			api.ModelVersionIDs[modelPath] +
+			api.Predictor.ModelOptimization.Precision.String() +
+			consts.CortexVersion,
+	)
+	return path.Join(_optimizedModelsDir, instanceFamily, modelID)
+}
+
+func optimizedModelName(api *spec.API) string {
+	if api.Predictor.Type == userconfig.ONNXPredictorType {
+		return "model.onnx"
+	}
+	return "model" // SavedModel directory
+}
+
+// returns the instance family (e.g. g4dn) of the instances that the API runs on
+func apiInstanceFamily(api *spec.API) string {
+	instanceType := *config.Cluster.InstanceType
+	if nodeGroup := targetNodeGroup(api.Compute); nodeGroup != nil {
+		instanceType = nodeGroup.InstanceType
+	}
+	return strings.Split(instanceType, ".")[0]
+}
+
+func modelOptimizerJobName(optimizedDir string) string {
+	return "model-optimizer-" + hash.String(optimizedDir)[:20]
+}
+
+func modelOptimizerJobSpec(api *spec.API, jobName string, optConfig optimizationConfig) *kbatch.Job {
+	optConfigBytes, _ := json.Marshal(optConfig)
+	gpu := kresource.MustParse("1")
+
+	return k8s.Job(&k8s.JobSpec{
+		Name: jobName,
+		Labels: map[string]string{
+			_modelOptimizerLabelKey: "true",
+		},
+		PodSpec: k8s.PodSpec{
+			Labels: map[string]string{
+				_modelOptimizerLabelKey: "true",
+			},
+			K8sPodSpec: kcore.PodSpec{
+				RestartPolicy: "Never",
+				Containers: []kcore.Container{
+					{
+						Name:            _modelOptimizerContainerName,
+						Image:           config.Cluster.ImageModelOptimizer,
+						ImagePullPolicy: "Always",
+						Args:            []string{"--optimize=" + base64.URLEncoding.EncodeToString(optConfigBytes)},
+						EnvFrom:         baseEnvFrom(),
+						Resources: kcore.ResourceRequirements{
+							Requests: kcore.ResourceList{_nvidiaGPUResource: gpu},
+							Limits:   kcore.ResourceList{_nvidiaGPUResource: gpu},
+						},
+					},
+				},
+				// run on the same instances as the API, so that the model is optimized for their GPU
+				NodeSelector:       nodeSelector(api),
+				Affinity:           nodeAffinity(api),
+				Tolerations:        tolerations(api),
+				ServiceAccountName: workloadServiceAccountName(),
+			},
+		},
+	})
+}
+
+// deleteSucceededModelOptimizerJobs cleans up the jobs of models which have been optimized (failed jobs are kept so that
+// their logs can be inspected, and are replaced when the API is redeployed)
+func deleteSucceededModelOptimizerJobs() error {
+	jobs, err := config.K8s.ListJobsByLabel(_modelOptimizerLabelKey, "true")
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, job := range jobs {
+		if job.Status.Succeeded > 0 {
+			if _, err := config.K8s.DeleteJob(job.Name); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if errors.HasError(errs) {
+		return errors.FirstError(errs...)
+	}
+	return nil
+}
@@ -0,0 +1,437 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/parallel"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	kapps "k8s.io/api/apps/v1"
+	kcore "k8s.io/api/core/v1"
+)
+
+// The replicas of APIs which are sharded across multiple nodes (compute.parallelism.nodes > 1) are groups of pods: the
+// API's deployment runs the leaders (which serve the API, and are node 0 of their group), and a stateful set runs the
+// workers (nodes - 1 per group, in order of their ordinals). The operator assigns each leader to the lowest free group
+// (see syncParallelGroups), and each group has a headless service which selects its leader, so that the group's workers
+// can find it at a stable address.
+
+const (
+	_parallelNodesLabelKey        = "parallelNodes"   // set on the deployments of multi-node APIs
+	_parallelGroupLabelKey        = "parallelGroup"   // set on the leader pods of multi-node APIs once they've been assigned to a group
+	_parallelAPILabelKey          = "parallelAPIName" // set on the workers and services of multi-node APIs (instead of apiName, so that the workers aren't selected by the API's service)
+	_podDeletionCostAnnotationKey = "controller.kubernetes.io/pod-deletion-cost"
+	_parallelMasterPortInt32      = int32(29500)
+)
+
+func parallelWorkersName(apiName string) string {
+	return k8sName(apiName) + "-workers"
+}
+
+func parallelGroupServicePrefix(apiName string) string {
+	return k8sName(apiName) + "-group-"
+}
+
+func parallelGroupServiceName(apiName string, group int) string {
+	return parallelGroupServicePrefix(apiName) + s.Int(group)
+}
+
+// the environment variables which the leaders and workers use to find each other (run.sh sets NODE_RANK and MASTER_ADDR)
+func parallelGroupEnvVars(api *spec.API) []kcore.EnvVar {
+	parallelism := api.Compute.Parallelism
+	return []kcore.EnvVar{
+		{
+			// the ranks on the group's other pods are reached over the pod network
+			Name:  "NCCL_SOCKET_IFNAME",
+			Value: "eth0",
+		},
+		{
+			Name:  "GLOO_SOCKET_IFNAME",
+			Value: "eth0",
+		},
+		{
+			Name:  "CORTEX_PARALLEL_NODES",
+			Value: s.Int64(parallelism.Nodes),
+		},
+		{
+			Name:  "CORTEX_PARALLEL_ROLE",
+			Value: "leader", // replaced in the workers' spec
+		},
+		{
+			Name:  "CORTEX_PARALLEL_LABELS_FILE",
+			Value: _podInfoMountPath + "/" + _podLabelsFileName,
+		},
+		{
+			Name:  "CORTEX_PARALLEL_LEADER_SERVICE_PREFIX",
+			Value: parallelGroupServicePrefix(api.Name),
+		},
+		{
+			Name:  "CORTEX_PARALLEL_NAMESPACE",
+			Value: api.Namespace,
+		},
+		{
+			Name:  "MASTER_PORT",
+			Value: s.Int32(_parallelMasterPortInt32),
+		},
+		{
+			Name:  "NNODES",
+			Value: s.Int64(parallelism.Nodes),
+		},
+		{
+			Name:  "NPROC_PER_NODE",
+			Value: s.Int64(api.Compute.GPU),
+		},
+		{
+			Name:  "WORLD_SIZE",
+			Value: s.Int64(parallelism.Degree()),
+		},
+	}
+}
+
+// parallelLeaderDeployment updates the deployment of a multi-node API
+func parallelLeaderDeployment(api *spec.API, deployment *kapps.Deployment) {
+	deployment.Labels[_parallelNodesLabelKey] = s.Int64(api.Compute.Parallelism.Nodes)
+
+	// the workers don't have sidecars, so only the ports which the leader's containers serve go through its sidecar
+	// (the ranks connect to each other directly)
+	if inMesh(api.API) {
+		var ports []string
+		for _, container := range deployment.Spec.Template.Spec.Containers {
+			for _, port := range container.Ports {
+				ports = append(ports, s.Int32(port.ContainerPort))
+			}
+		}
+		deployment.Spec.Template.Annotations["traffic.sidecar.istio.io/includeInboundPorts"] = strings.Join(ports, ",")
+	}
+}
+
+// the workers run the API container (without its ports and shutdown hook, since they don't serve requests)
+func parallelWorkersStatefulSetSpec(api *spec.API, replicas int32) *kapps.StatefulSet {
+	pod := newAPIPod(api)
+	pod.pythonPredictor()
+	podSpec := pod.build()
+
+	var containers []kcore.Container
+	for _, container := range podSpec.Containers {
+		if container.Name != _apiContainerName {
+			continue
+		}
+		container.Lifecycle = nil
+		container.Ports = nil
+		for i := range container.Env {
+			if container.Env[i].Name == "CORTEX_PARALLEL_ROLE" {
+				container.Env[i].Value = "worker"
+			}
+		}
+		containers = append(containers, container)
+	}
+	podSpec.Containers = containers
+
+	return k8s.StatefulSet(&k8s.StatefulSetSpec{
+		Name:        parallelWorkersName(api.Name),
+		Replicas:    replicas,
+		ServiceName: parallelWorkersName(api.Name),
+		Labels: apiLabels(api, map[string]string{
+			_parallelAPILabelKey: api.Name,
+			"apiID":              api.ID,
+		}),
+		Selector: map[string]string{
+			_parallelAPILabelKey: api.Name,
+		},
+		PodSpec: k8s.PodSpec{
+			Labels: apiLabels(api, map[string]string{
+				_parallelAPILabelKey: api.Name,
+				"apiID":              api.ID,
+			}),
+			Annotations: map[string]string{
+				"sidecar.istio.io/inject": "false",
+			},
+			K8sPodSpec: podSpec,
+		},
+	})
+}
+
+// the stateful set's governing service, which gives the workers their network identities
+func parallelWorkersServiceSpec(api *spec.API) *kcore.Service {
+	return k8s.Service(&k8s.ServiceSpec{
+		Name:       parallelWorkersName(api.Name),
+		Port:       _parallelMasterPortInt32,
+		TargetPort: _parallelMasterPortInt32,
+		Headless:   true,
+		Labels: map[string]string{
+			_parallelAPILabelKey: api.Name,
+		},
+		Selector: map[string]string{
+			_parallelAPILabelKey: api.Name,
+		},
+	})
+}
+
+func parallelGroupServiceSpec(apiName string, group int) *kcore.Service {
+	return k8s.Service(&k8s.ServiceSpec{
+		Name:       parallelGroupServiceName(apiName, group),
+		Port:       _parallelMasterPortInt32,
+		TargetPort: _parallelMasterPortInt32,
+		Headless:   true,
+		Labels: map[string]string{
+			_parallelAPILabelKey:   apiName,
+			_parallelGroupLabelKey: s.Int(group),
+		},
+		Selector: map[string]string{
+			"apiName":              apiName,
+			_parallelGroupLabelKey: s.Int(group),
+		},
+	})
+}
+
+func applyK8sParallelWorkers(api *spec.API) error {
+	if !api.Compute.Parallelism.IsMultiNode() {
+		return deleteParallelWorkers(api.Name, api.Namespace)
+	}
+
+	k8sNamespace := config.K8sNamespace(api.Namespace)
+
+	if _, err := k8sNamespace.ApplyService(parallelWorkersServiceSpec(api)); err != nil {
+		return err
+	}
+
+	// the number of workers is managed by syncParallelGroups
+	var replicas int32
+	prevStatefulSet, err := k8sNamespace.GetStatefulSet(parallelWorkersName(api.Name))
+	if err != nil {
+		return err
+	}
+	if prevStatefulSet != nil && prevStatefulSet.Spec.Replicas != nil {
+		replicas = *prevStatefulSet.Spec.Replicas
+	}
+
+	_, err = k8sNamespace.ApplyStatefulSet(parallelWorkersStatefulSetSpec(api, replicas))
+	return err
+}
+
+func deleteParallelWorkers(apiName string, namespace string) error {
+	k8sNamespace := config.K8sNamespace(namespace)
+
+	return parallel.RunFirstErr(
+		func() error {
+			_, err := k8sNamespace.DeleteStatefulSet(parallelWorkersName(apiName))
+			return err
+		},
+		func() error {
+			// the workers' service and the groups' services
+			services, err := k8sNamespace.ListServicesByLabel(_parallelAPILabelKey, apiName)
+			if err != nil {
+				return err
+			}
+			for _, service := range services {
+				if _, err := k8sNamespace.DeleteService(service.Name); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+}
+
+type parallelLeader struct {
+	podName string
+	group   int // -1 if the leader hasn't been assigned to a group
+}
+
+type parallelGroupsPlan struct {
+	assignments map[string]int // pod name -> group
+	evictions   []string       // the pods of leaders which should be replaced, so that their replacements fill lower groups
+	numGroups   int            // the number of groups which have (or will have) a leader, including any gaps
+}
+
+// planParallelGroups assigns the leaders which don't have a group to the lowest free groups. Leaders can't change
+// groups once they've started (their workers have joined them), so if a leader's group is higher than the number of
+// leaders while a lower group is free (e.g. after a lower group's leader was deleted), the leader is evicted and its
+// replacement takes the lowest free group; this keeps the groups (and therefore the workers) contiguous.
+func planParallelGroups(leaders []parallelLeader) parallelGroupsPlan {
+	plan := parallelGroupsPlan{
+		assignments: map[string]int{},
+	}
+
+	// in order of group (and then name), so that the lowest group keeps its leader if a group has multiple leaders
+	sort.Slice(leaders, func(i, j int) bool {
+		if leaders[i].group != leaders[j].group {
+			return leaders[i].group < leaders[j].group
+		}
+		return leaders[i].podName < leaders[j].podName
+	})
+
+	usedGroups := map[int]bool{}
+	var assigned []parallelLeader
+	var unassigned []string
+	for _, leader := range leaders {
+		if leader.group < 0 {
+			unassigned = append(unassigned, leader.podName)
+		} else if usedGroups[leader.group] {
+			plan.evictions = append(plan.evictions, leader.podName) // unexpected
+		} else {
+			usedGroups[leader.group] = true
+			assigned = append(assigned, leader)
+		}
+	}
+
+	nextGroup := 0
+	nextFreeGroup := func() int {
+		for usedGroups[nextGroup] {
+			nextGroup++
+		}
+		return nextGroup
+	}
+
+	for _, podName := range unassigned {
+		group := nextFreeGroup()
+		usedGroups[group] = true
+		plan.assignments[podName] = group
+	}
+
+	// the evicted leaders are replaced by the deployment, so the number of leaders doesn't change
+	numLeaders := len(leaders)
+	for i := len(assigned) - 1; i >= 0; i-- {
+		if assigned[i].group < numLeaders {
+			break
+		}
+		freeGroup := nextFreeGroup()
+		if freeGroup >= numLeaders {
+			break
+		}
+		usedGroups[freeGroup] = true // reserved for the leader's replacement
+		delete(usedGroups, assigned[i].group)
+		plan.evictions = append(plan.evictions, assigned[i].podName)
+	}
+
+	for group := range usedGroups {
+		if group+1 > plan.numGroups {
+			plan.numGroups = group + 1
+		}
+	}
+
+	return plan
+}
+
+// syncParallelGroups assigns the leaders of a multi-node API to groups, and scales its workers and group services to
+// match; it's called whenever the API's deployment or pods change
+func syncParallelGroups(apiName string) error {
+	deployment, err := getCachedAPIDeployment(apiName)
+	if err != nil {
+		return err
+	}
+	if deployment == nil || deployment.Labels[_parallelNodesLabelKey] == "" {
+		return nil
+	}
+	nodes, err := strconv.Atoi(deployment.Labels[_parallelNodesLabelKey])
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	pods, err := listAPIPods(apiSelector(apiName))
+	if err != nil {
+		return err
+	}
+
+	podsByName := map[string]kcore.Pod{}
+	var leaders []parallelLeader
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil || pod.Status.Phase == kcore.PodSucceeded || pod.Status.Phase == kcore.PodFailed {
+			continue
+		}
+		group := -1
+		if groupStr, ok := pod.Labels[_parallelGroupLabelKey]; ok {
+			if group, err = strconv.Atoi(groupStr); err != nil {
+				group = -1
+			}
+		}
+		podsByName[pod.Name] = pod
+		leaders = append(leaders, parallelLeader{podName: pod.Name, group: group})
+	}
+
+	plan := planParallelGroups(leaders)
+	k8sNamespace := config.K8sNamespace(deployment.Namespace)
+
+	for podName, group := range plan.assignments {
+		pod := podsByName[podName]
+		pod.Labels[_parallelGroupLabelKey] = s.Int(group)
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		// when the deployment is scaled down, the leaders of the highest groups are removed first
+		pod.Annotations[_podDeletionCostAnnotationKey] = s.Int(-group)
+		if _, err := k8sNamespace.UpdatePod(&pod); err != nil {
+			return err
+		}
+	}
+
+	for _, podName := range plan.evictions {
+		if _, err := k8sNamespace.DeletePod(podName); err != nil {
+			return err
+		}
+	}
+
+	statefulSet, err := k8sNamespace.GetStatefulSet(parallelWorkersName(apiName))
+	if err != nil {
+		return err
+	}
+	if statefulSet != nil {
+		replicas := int32(plan.numGroups * (nodes - 1))
+		if statefulSet.Spec.Replicas == nil || *statefulSet.Spec.Replicas != replicas {
+			statefulSet.Spec.Replicas = &replicas
+			if _, err := k8sNamespace.UpdateStatefulSet(statefulSet); err != nil {
+				return err
+			}
+		}
+	}
+
+	services, err := k8sNamespace.ListServicesByLabel(_parallelAPILabelKey, apiName)
+	if err != nil {
+		return err
+	}
+	existingGroups := map[int]bool{}
+	for _, service := range services {
+		groupStr, ok := service.Labels[_parallelGroupLabelKey]
+		if !ok {
+			continue // the workers' service
+		}
+		group, err := strconv.Atoi(groupStr)
+		if err != nil || group >= plan.numGroups {
+			if _, err := k8sNamespace.DeleteService(service.Name); err != nil {
+				return err
+			}
+			continue
+		}
+		existingGroups[group] = true
+	}
+	for group := 0; group < plan.numGroups; group++ {
+		if !existingGroups[group] {
+			if _, err := k8sNamespace.CreateService(parallelGroupServiceSpec(apiName, group)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanParallelGroups(t *testing.T) {
+	// new leaders fill the lowest free groups
+	plan := planParallelGroups([]parallelLeader{{"a", 0}, {"b", -1}, {"c", 2}, {"d", -1}})
+	require.Equal(t, map[string]int{"b": 1, "d": 3}, plan.assignments)
+	require.Empty(t, plan.evictions)
+	require.Equal(t, 4, plan.numGroups)
+
+	// a leader above the number of leaders is replaced when a lower group is free
+	plan = planParallelGroups([]parallelLeader{{"a", 1}, {"b", 2}, {"c", 3}})
+	require.Empty(t, plan.assignments)
+	require.Equal(t, []string{"c"}, plan.evictions)
+	require.Equal(t, 3, plan.numGroups)
+
+	// a new leader takes the free group instead
+	plan = planParallelGroups([]parallelLeader{{"a", 1}, {"b", 2}, {"c", 3}, {"d", -1}})
+	require.Equal(t, map[string]int{"d": 0}, plan.assignments)
+	require.Empty(t, plan.evictions)
+	require.Equal(t, 4, plan.numGroups)
+
+	// only one leader keeps a group which is claimed by multiple leaders
+	plan = planParallelGroups([]parallelLeader{{"b", 0}, {"a", 0}})
+	require.Empty(t, plan.assignments)
+	require.Equal(t, []string{"b"}, plan.evictions)
+	require.Equal(t, 1, plan.numGroups)
+
+	plan = planParallelGroups(nil)
+	require.Equal(t, 0, plan.numGroups)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/docker"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/regex"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+)
+
+// pinArtifacts resolves the API's image tags to digests and its model files to their current S3 versions, so that
+// replicas which are created later (e.g. when scaling up) run exactly what was deployed
+func pinArtifacts(api *spec.API) error {
+	imageDigests, err := resolveImageDigests(api)
+	if err != nil {
+		return err
+	}
+
+	modelVersionIDs, err := resolveModelVersionIDs(api)
+	if err != nil {
+		return err
+	}
+
+	api.Pin(imageDigests, modelVersionIDs)
+	return nil
+}
+
+func resolveImageDigests(api *spec.API) (map[string]string, error) {
+	images := strset.New(api.Predictor.Image)
+	if api.Predictor.Type == userconfig.TensorFlowPredictorType {
+		images.Add(api.Predictor.TensorFlowServingImage)
+	}
+	// OCI artifacts are pinned like images, since they are stored in the same registries
+	if api.Predictor.Model != nil && docker.IsOCIPath(*api.Predictor.Model) {
+		images.Add(docker.OCIReference(*api.Predictor.Model))
+	}
+	for _, initContainer := range api.Predictor.InitContainers {
+		if initContainer.Image != "" {
+			images.Add(initContainer.Image)
+		}
+	}
+
+	imageDigests := map[string]string{}
+	for image := range images {
+		// cortex's images are tagged with the cortex version, and are not overwritten
+		if consts.DefaultImagePathsSet.Has(image) || strings.Contains(image, "@") {
+			continue
+		}
+
+		digest, err := getImageDigest(image)
+		if err != nil {
+			return nil, err
+		}
+		if digest != "" {
+			imageDigests[image] = docker.ImageWithDigest(image, digest)
+		}
+	}
+
+	return imageDigests, nil
+}
+
+// returns "" if the operator isn't able to access the image's registry (but the cluster's instances are)
+func getImageDigest(image string) (string, error) {
+	dockerClient, err := docker.GetDockerClient()
+	if err != nil {
+		return "", err
+	}
+
+	dockerAuth := docker.NoAuth
+	if regex.IsValidECRURL(image) {
+		dockerAuth, err = docker.AWSAuthConfig(config.AWS)
+		if err != nil {
+			if _, ok := errors.CauseOrSelf(err).(awserr.Error); ok {
+				// the operator's IAM user may not have access to ECR even though the instances' IAM role does
+				return "", nil
+			}
+			return "", err
+		}
+	} else if regex.IsValidGCRURL(image) && config.GCP != nil {
+		dockerAuth, err = docker.GCPAuthConfig(config.GCP, image)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return docker.GetImageDigest(dockerClient, image, dockerAuth)
+}
+
+// only models which are single files (e.g. zipped TensorFlow models and ONNX models) are pinned
+func resolveModelVersionIDs(api *spec.API) (map[string]string, error) {
+	modelVersionIDs := map[string]string{}
+
+	for _, model := range api.Predictor.Models {
+		if !aws.IsValidS3Path(model.Model) || !isModelFile(api, model.Model) {
+			continue
+		}
+
+		awsClientForBucket, err := aws.NewFromClientS3Path(model.Model, config.AWS)
+		if err != nil {
+			return nil, err
+		}
+
+		versionID, err := awsClientForBucket.GetS3PathVersionID(model.Model)
+		if err != nil {
+			return nil, err
+		}
+		if versionID != "" {
+			modelVersionIDs[model.Model] = versionID
+		}
+	}
+
+	return modelVersionIDs, nil
+}
+
+func isModelFile(api *spec.API, modelPath string) bool {
+	switch api.Predictor.Type {
+	case userconfig.TensorFlowPredictorType:
+		return strings.HasSuffix(modelPath, ".zip")
+	case userconfig.ONNXPredictorType:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"log"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	libmath "github.com/cortexlabs/cortex/pkg/lib/math"
+	"github.com/cortexlabs/cortex/pkg/lib/parallel"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	kapps "k8s.io/api/apps/v1"
+	kcore "k8s.io/api/core/v1"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	_eksctlClusterNameTagKey   = "alpha.eksctl.io/cluster-name"
+	_eksctlNodeGroupNameTagKey = "eksctl.io/v1alpha2/nodegroup-name"
+)
+
+// prescaleTarget describes the eksctl node group on which an API's replicas will be scheduled
+type prescaleTarget struct {
+	nodeGroupName string
+	nodeGroup     *string // set if the API targets one of the cluster's node_groups
+	isSpot        bool
+}
+
+func getPrescaleTarget(deployment *kapps.Deployment) prescaleTarget {
+	if nodeGroup, ok := deployment.Spec.Template.Spec.NodeSelector[clusterconfig.NodeGroupLabelKey]; ok {
+		return prescaleTarget{
+			nodeGroupName: "ng-cortex-group-" + nodeGroup,
+			nodeGroup:     &nodeGroup,
+		}
+	}
+
+	isSpotCluster := config.Cluster.Spot != nil && *config.Cluster.Spot
+	if isSpotCluster && deployment.Annotations[userconfig.SpotAnnotationKey] != "false" {
+		return prescaleTarget{nodeGroupName: "ng-cortex-worker-spot", isSpot: true}
+	}
+
+	return prescaleTarget{nodeGroupName: "ng-cortex-worker-on-demand"}
+}
+
+func (target prescaleTarget) matchesNode(node *kcore.Node) bool {
+	nodeGroup, inNodeGroup := node.Labels[clusterconfig.NodeGroupLabelKey]
+	if target.nodeGroup != nil {
+		return inNodeGroup && nodeGroup == *target.nodeGroup
+	}
+	isSpotNode := node.Labels[_lifecycleNodeLabelKey] == _spotLifecycleNodeLabelValue
+	return !inNodeGroup && isSpotNode == target.isSpot
+}
+
+// prescaleInBackground runs prescale without blocking the caller, since it is only an optimization
+func prescaleInBackground(deployment *kapps.Deployment) {
+	go func() {
+		if err := prescale(deployment); err != nil {
+			errors.PrintError(err, "failed to pre-scale instances for "+deployment.Labels["apiName"])
+		}
+	}()
+}
+
+// prescale increases the desired capacity of the API's node group by the number of instances which its unscheduled
+// replicas will require, so that the instances start launching immediately rather than after the cluster autoscaler
+// notices the pending pods (the cluster autoscaler removes any instances which end up being unneeded)
+func prescale(deployment *kapps.Deployment) error {
+	if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas == 0 {
+		return nil
+	}
+
+	apiName := deployment.Labels["apiName"]
+	podSpec := &deployment.Spec.Template.Spec
+	requests := podRequests(podSpec)
+	apiPriority := podPriority(podSpec)
+	target := getPrescaleTarget(deployment)
+
+	var nodes []kcore.Node
+	var pods []kcore.Pod
+	err := parallel.RunFirstErr(
+		func() error {
+			var err error
+			nodes, err = config.K8s.ListNodesByLabel("workload", "true")
+			return err
+		},
+		func() error {
+			var err error
+			pods, err = config.K8sAllNamspaces.ListPods(nil)
+			return err
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	unscheduledReplicas := int64(*deployment.Spec.Replicas)
+	nodeUsage := map[string]kcore.ResourceList{}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Spec.NodeName == "" || pod.Status.Phase == kcore.PodSucceeded || pod.Status.Phase == kcore.PodFailed {
+			continue
+		}
+		// pods with a lower priority than the API's pods (e.g. overprovisioning placeholders) are preempted by them
+		if podPriority(&pod.Spec) < apiPriority {
+			continue
+		}
+
+		if nodeUsage[pod.Spec.NodeName] == nil {
+			nodeUsage[pod.Spec.NodeName] = kcore.ResourceList{}
+		}
+		addResources(nodeUsage[pod.Spec.NodeName], podRequests(&pod.Spec))
+
+		isReplica := pod.Namespace == deployment.Namespace && pod.Labels["apiName"] == apiName && k8s.PodComputesEqual(podSpec, &pod.Spec)
+		if isReplica && k8s.GetPodStatus(pod) != k8s.PodStatusTerminating {
+			unscheduledReplicas--
+		}
+	}
+
+	for i := range nodes {
+		if nodes[i].Spec.Unschedulable || !target.matchesNode(&nodes[i]) {
+			continue
+		}
+		available := nodes[i].Status.Allocatable.DeepCopy()
+		subtractResources(available, nodeUsage[nodes[i].Name])
+		unscheduledReplicas -= replicasThatFit(available, requests)
+	}
+
+	if unscheduledReplicas <= 0 {
+		return nil
+	}
+
+	replicasPerInstance := replicasThatFit(newInstanceCapacity(target), requests)
+	if replicasPerInstance <= 0 {
+		return nil
+	}
+	requiredInstances := (unscheduledReplicas + replicasPerInstance - 1) / replicasPerInstance
+
+	asgs, err := config.AWS.AutoscalingGroups(map[string]string{
+		_eksctlClusterNameTagKey:   config.Cluster.ClusterName,
+		_eksctlNodeGroupNameTagKey: target.nodeGroupName,
+	})
+	if err != nil {
+		return err
+	}
+	if len(asgs) == 0 {
+		return ErrorAutoscalingGroupNotFound(target.nodeGroupName)
+	}
+	asg := asgs[0]
+
+	desiredCapacity := libmath.MinInt64(*asg.MaxSize, *asg.DesiredCapacity+requiredInstances)
+	if desiredCapacity <= *asg.DesiredCapacity {
+		return nil
+	}
+
+	log.Printf("%s pre-scaling: increasing the desired capacity of %s from %d to %d instances for %d unscheduled replicas", apiName, target.nodeGroupName, *asg.DesiredCapacity, desiredCapacity, unscheduledReplicas)
+
+	return config.AWS.SetAutoscalingGroupDesiredCapacity(*asg.AutoScalingGroupName, desiredCapacity)
+}
+
+// newInstanceCapacity returns the resources which will be available to APIs on a new instance in the target node group
+func newInstanceCapacity(target prescaleTarget) kcore.ResourceList {
+	maxMem := k8s.QuantityPtr(config.Cluster.InstanceMetadata.Memory.DeepCopy())
+	if memFromConfigMap, err := getMemoryCapacityFromConfigMap(); err == nil && memFromConfigMap != nil {
+		maxMem = memFromConfigMap
+	}
+
+	maxCPU, maxMemAvailable, maxGPU, maxInf := instanceCapacity(&userconfig.Compute{NodeGroup: target.nodeGroup}, maxMem)
+
+	return kcore.ResourceList{
+		kcore.ResourceCPU:    maxCPU,
+		kcore.ResourceMemory: maxMemAvailable,
+		_nvidiaGPUResource:   *kresource.NewQuantity(maxGPU, kresource.DecimalSI),
+		_inferentiaResource:  *kresource.NewQuantity(maxInf, kresource.DecimalSI),
+	}
+}
+
+func podRequests(podSpec *kcore.PodSpec) kcore.ResourceList {
+	requests := kcore.ResourceList{}
+	for _, container := range podSpec.Containers {
+		addResources(requests, container.Resources.Requests)
+	}
+	return requests
+}
+
+func addResources(total kcore.ResourceList, resources kcore.ResourceList) {
+	for name, quantity := range resources {
+		sum := total[name]
+		sum.Add(quantity)
+		total[name] = sum
+	}
+}
+
+func subtractResources(total kcore.ResourceList, resources kcore.ResourceList) {
+	for name, quantity := range resources {
+		difference := total[name]
+		difference.Sub(quantity)
+		total[name] = difference
+	}
+}
+
+// replicasThatFit returns the number of replicas with the requested resources which fit in the available resources
+func replicasThatFit(available kcore.ResourceList, requests kcore.ResourceList) int64 {
+	replicas := int64(-1)
+	for name, requested := range requests {
+		if requested.IsZero() {
+			continue
+		}
+		availableQuantity := available[name]
+		fit := libmath.MaxInt64(availableQuantity.MilliValue()/requested.MilliValue(), 0)
+		if replicas == -1 || fit < replicas {
+			replicas = fit
+		}
+	}
+
+	if replicas == -1 {
+		return 0 // replicas which don't request any resources are not considered
+	}
+	return replicas
+}
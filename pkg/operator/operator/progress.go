@@ -0,0 +1,281 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/parallel"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/status"
+	"github.com/gorilla/websocket"
+	kapps "k8s.io/api/apps/v1"
+	kcore "k8s.io/api/core/v1"
+)
+
+const _progressPollPeriod = 2 * time.Second
+
+// container states which won't resolve on their own, mapped to a suggestion for how to fix them
+var _containerProblemHints = map[string]string{
+	"ErrImagePull":               "check that the image exists and that the cluster has permission to pull it",
+	"ImagePullBackOff":           "check that the image exists and that the cluster has permission to pull it",
+	"InvalidImageName":           "check the image name in your api configuration",
+	"CrashLoopBackOff":           "run `cortex logs %s` to see why the container is crashing",
+	"CreateContainerConfigError": "check the environment variables and secrets referenced by your api configuration",
+	"CreateContainerError":       "check the environment variables and secrets referenced by your api configuration",
+	"OOMKilled":                  "increase `compute.mem` in your api configuration",
+	"Error":                      "run `cortex logs %s` to see the container's logs",
+}
+
+// StreamProgress writes a narrative of the API's rollout to the socket, until the rollout completes or the client disconnects
+func StreamProgress(apiName string, socket *websocket.Conn) {
+	progressCancel := make(chan struct{})
+	defer close(progressCancel)
+	go streamProgress(apiName, progressCancel, socket)
+	pumpStdin(socket)
+	progressCancel <- struct{}{}
+}
+
+func streamProgress(apiName string, progressCancel chan struct{}, socket *websocket.Conn) {
+	seenMessages := newEventCache(_maxCacheSize)
+	lastSummary := ""
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-progressCancel:
+			return
+		case <-timer.C:
+			deployment, pods, events, err := getProgressResources(apiName)
+			if err != nil {
+				telemetry.Error(err)
+				writeAndCloseSocket(socket, "error: "+errors.Message(err))
+				continue
+			}
+
+			if deployment == nil {
+				writeAndCloseSocket(socket, apiName+" is not deployed")
+				continue
+			}
+
+			for _, message := range progressMessages(apiName, deployment, pods, events) {
+				if !seenMessages.Has(message.id) {
+					writeString(socket, message.text)
+					seenMessages.Add(message.id)
+				}
+			}
+
+			counts := getReplicaCounts(deployment, pods)
+			if summary := progressSummary(&counts); summary != lastSummary {
+				writeString(socket, summary)
+				lastSummary = summary
+			}
+
+			if counts.Updated.Ready >= counts.Requested && counts.Stale.Total() == 0 {
+				writeAndCloseSocket(socket, apiName+" is up to date")
+				continue
+			}
+
+			timer.Reset(_progressPollPeriod)
+		}
+	}
+}
+
+func getProgressResources(apiName string) (*kapps.Deployment, []kcore.Pod, []kcore.Event, error) {
+	deployment, err := getAPIDeployment(apiName)
+	if err != nil || deployment == nil {
+		return nil, nil, nil, err
+	}
+
+	k8sNamespace := config.K8sNamespace(deployment.Namespace)
+
+	var pods []kcore.Pod
+	var events []kcore.Event
+
+	err = parallel.RunFirstErr(
+		func() error {
+			var err error
+			pods, err = k8sNamespace.ListPodsByLabel("apiName", apiName)
+			return err
+		},
+		func() error {
+			var err error
+			events, err = k8sNamespace.ListEventsByInvolvedObjectKind("Pod")
+			return err
+		},
+	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return deployment, pods, events, nil
+}
+
+type progressMessage struct {
+	id   string
+	text string
+}
+
+// progressMessages describes the pod events and container states of the API's latest version
+func progressMessages(apiName string, deployment *kapps.Deployment, pods []kcore.Pod, events []kcore.Event) []progressMessage {
+	var messages []progressMessage
+
+	updatedPods := strset.New()
+	for i := range pods {
+		if isPodSpecLatest(deployment, &pods[i]) {
+			updatedPods.Add(pods[i].Name)
+		}
+	}
+
+	for _, event := range events {
+		if !updatedPods.Has(event.InvolvedObject.Name) {
+			continue
+		}
+		messages = append(messages, progressMessage{
+			id:   string(event.UID),
+			text: fmt.Sprintf("%s %s: %s: %s", k8s.EventTime(&event).Format("15:04:05"), event.InvolvedObject.Name, event.Reason, strings.TrimSpace(event.Message)),
+		})
+	}
+
+	for i := range pods {
+		if !updatedPods.Has(pods[i].Name) {
+			continue
+		}
+		messages = append(messages, containerProgressMessages(apiName, &pods[i])...)
+	}
+
+	return messages
+}
+
+func containerProgressMessages(apiName string, pod *kcore.Pod) []progressMessage {
+	var messages []progressMessage
+
+	for _, containerStatus := range pod.Status.InitContainerStatuses {
+		id := pod.Name + "/" + containerStatus.Name + "/"
+		if containerStatus.State.Running != nil {
+			text := fmt.Sprintf("%s: running the %s init container", pod.Name, containerStatus.Name)
+			if containerStatus.Name == _downloaderInitContainerName {
+				text = fmt.Sprintf("%s: downloading the api's project and model files", pod.Name)
+			}
+			messages = append(messages, progressMessage{
+				id:   id + "running",
+				text: text,
+			})
+		}
+		if terminated := containerStatus.State.Terminated; terminated != nil && terminated.ExitCode == 0 {
+			duration := terminated.FinishedAt.Sub(terminated.StartedAt.Time).Round(time.Second)
+			text := fmt.Sprintf("%s: the %s init container finished in %s", pod.Name, containerStatus.Name, duration)
+			if containerStatus.Name == _downloaderInitContainerName {
+				text = fmt.Sprintf("%s: finished downloading the api's project and model files in %s", pod.Name, duration)
+			}
+			messages = append(messages, progressMessage{
+				id:   id + "completed",
+				text: text,
+			})
+		}
+	}
+
+	for _, containerStatus := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		id := pod.Name + "/" + containerStatus.Name + "/" + s.Int32(containerStatus.RestartCount) + "/"
+
+		if waiting := containerStatus.State.Waiting; waiting != nil {
+			if _, ok := _containerProblemHints[waiting.Reason]; ok {
+				messages = append(messages, progressMessage{
+					id:   id + waiting.Reason,
+					text: containerProblemMessage(apiName, pod.Name, containerStatus.Name, waiting.Reason, waiting.Message),
+				})
+			}
+		}
+
+		for _, terminated := range []*kcore.ContainerStateTerminated{containerStatus.LastTerminationState.Terminated, containerStatus.State.Terminated} {
+			if terminated == nil || terminated.ExitCode == 0 {
+				continue
+			}
+			reason := terminated.Reason
+			if _, ok := _containerProblemHints[reason]; !ok {
+				reason = "Error"
+			}
+			message := fmt.Sprintf("exited with code %d", terminated.ExitCode)
+			if terminated.Message != "" {
+				message += ": " + terminated.Message
+			}
+			messages = append(messages, progressMessage{
+				id:   pod.Name + "/" + containerStatus.Name + "/" + terminated.FinishedAt.String() + "/terminated",
+				text: containerProblemMessage(apiName, pod.Name, containerStatus.Name, reason, message),
+			})
+		}
+	}
+
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.State.Running != nil && !containerStatus.Ready {
+			messages = append(messages, progressMessage{
+				id:   pod.Name + "/" + containerStatus.Name + "/" + s.Int32(containerStatus.RestartCount) + "/running",
+				text: fmt.Sprintf("%s: the %s container has started, waiting for it to become ready", pod.Name, containerStatus.Name),
+			})
+		}
+	}
+
+	return messages
+}
+
+func containerProblemMessage(apiName string, podName string, containerName string, reason string, message string) string {
+	text := fmt.Sprintf("%s: the %s container is failing (%s)", podName, containerName, reason)
+	if message != "" {
+		text += ": " + strings.TrimSpace(message)
+	}
+
+	hint := _containerProblemHints[reason]
+	if strings.Contains(hint, "%s") {
+		hint = fmt.Sprintf(hint, apiName)
+	}
+	return text + "; " + hint
+}
+
+func progressSummary(counts *status.ReplicaCounts) string {
+	summary := fmt.Sprintf("%d/%d updated replicas ready", counts.Updated.Ready, counts.Requested)
+
+	var details []string
+	for _, detail := range []struct {
+		count int32
+		name  string
+	}{
+		{counts.Updated.Pending, "pending"},
+		{counts.Updated.Stalled, "stalled"},
+		{counts.Updated.Initializing, "initializing"},
+		{counts.Updated.Failed + counts.Updated.Killed, "failed"},
+		{counts.Updated.KilledOOM, "out of memory"},
+		{counts.Stale.Total(), "stale"},
+	} {
+		if detail.count > 0 {
+			details = append(details, fmt.Sprintf("%d %s", detail.count, detail.name))
+		}
+	}
+
+	if len(details) > 0 {
+		summary += " (" + strings.Join(details, ", ") + ")"
+	}
+	return summary
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/cortexlabs/cortex/pkg/lib/hash"
+	"github.com/cortexlabs/cortex/pkg/lib/parallel"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/lib/zip"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	_projectFileBatchSize  = 50 // the number of project files which are checked, uploaded, or downloaded concurrently
+	_projectFileHashLength = 63 // the length of hash.Bytes()
+)
+
+// ProjectID returns the ID of the zipped project directory, which is derived from its contents
+func ProjectID(projectBytes []byte) string {
+	return hash.Bytes(projectBytes)
+}
+
+// ValidateProjectSize checks the size of the zipped project directory against the cluster's max_project_size, since
+// the project is downloaded by each of the APIs' replicas when they start
+func ValidateProjectSize(projectBytes []byte) error {
+	maxProjectSize := kresource.MustParse(config.Cluster.MaxProjectSize)
+	if int64(len(projectBytes)) > maxProjectSize.Value() {
+		return ErrorProjectTooLarge(len(projectBytes), config.Cluster.MaxProjectSize)
+	}
+	return nil
+}
+
+// UploadProject uploads the zipped project directory to the cluster's bucket (unless it has already been uploaded), and returns its ID
+func UploadProject(projectBytes []byte) (string, error) {
+	projectID := ProjectID(projectBytes)
+	projectKey := spec.ProjectKey(projectID)
+
+	isProjectUploaded, err := config.Bucket.Exists(projectKey)
+	if err != nil {
+		return "", err
+	}
+	if !isProjectUploaded {
+		if err = config.Bucket.UploadBytes(projectBytes, projectKey); err != nil {
+			return "", err
+		}
+	}
+
+	return projectID, nil
+}
+
+// MissingProjectFiles returns the hashes of the files in the project manifest which haven't been uploaded to the
+// cluster's bucket (by a previous incremental upload)
+func MissingProjectFiles(manifest []schema.ProjectFile) ([]string, error) {
+	hashes := strset.New()
+	for _, projectFile := range manifest {
+		if !isProjectFileHash(projectFile.Hash) {
+			return nil, ErrorInvalidProjectFileHash(projectFile.Hash)
+		}
+		hashes.Add(projectFile.Hash)
+	}
+	hashList := hashes.SliceSorted()
+
+	var mux sync.Mutex
+	var missing []string
+	err := forEachInBatches(len(hashList), func(i int) error {
+		exists, err := config.Bucket.Exists(spec.ProjectFileKey(hashList[i]))
+		if err != nil {
+			return err
+		}
+		if !exists {
+			mux.Lock()
+			missing = append(missing, hashList[i])
+			mux.Unlock()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return missing, nil
+}
+
+// AssembleProject zips the project described by the manifest, from the files which were uploaded with the request
+// (keyed by hash, which are also stored in the cluster's bucket for subsequent uploads) and the files which were
+// previously stored in the cluster's bucket. Files are zipped in the manifest's order, so the project is the same as
+// the zipped project directory which the manifest was created from
+func AssembleProject(manifest []schema.ProjectFile, uploadedFiles map[string][]byte) ([]byte, error) {
+	for fileHash, content := range uploadedFiles {
+		if hash.Bytes(content) != fileHash {
+			return nil, ErrorProjectFileHashMismatch(fileHash)
+		}
+	}
+	cleanPaths := make([]string, len(manifest))
+	for i, projectFile := range manifest {
+		if !isProjectFileHash(projectFile.Hash) {
+			return nil, ErrorInvalidProjectFileHash(projectFile.Hash)
+		}
+		cleanPath := strings.TrimPrefix(projectFile.Path, "/")
+		if cleanPath == "" || filepath.Clean(cleanPath) != cleanPath || strings.HasPrefix(cleanPath, "..") {
+			return nil, ErrorInvalidProjectFilePath(projectFile.Path)
+		}
+		cleanPaths[i] = cleanPath
+	}
+
+	uploadedHashes := make([]string, 0, len(uploadedFiles))
+	for fileHash := range uploadedFiles {
+		uploadedHashes = append(uploadedHashes, fileHash)
+	}
+	err := forEachInBatches(len(uploadedHashes), func(i int) error {
+		return config.Bucket.UploadBytes(uploadedFiles[uploadedHashes[i]], spec.ProjectFileKey(uploadedHashes[i]))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	contents := make([]zip.BytesInput, len(manifest))
+	err = forEachInBatches(len(manifest), func(i int) error {
+		projectFile := manifest[i]
+		content, ok := uploadedFiles[projectFile.Hash]
+		if !ok {
+			var err error
+			content, err = config.Bucket.ReadBytes(spec.ProjectFileKey(projectFile.Hash))
+			if err != nil {
+				return ErrorProjectFileNotUploaded(projectFile.Path)
+			}
+		}
+		contents[i] = zip.BytesInput{Content: content, Dest: cleanPaths[i]}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return zip.ToMem(&zip.Input{Bytes: contents})
+}
+
+// isProjectFileHash returns true if fileHash has the format of hash.Bytes() (the manifest's hashes are used in the keys
+// of the project files in the cluster's bucket, so anything else could refer to another object in the bucket)
+func isProjectFileHash(fileHash string) bool {
+	if len(fileHash) != _projectFileHashLength {
+		return false
+	}
+	for _, char := range fileHash {
+		if !(char >= '0' && char <= '9') && !(char >= 'a' && char <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// forEachInBatches calls fn for 0 through n-1, running up to _projectFileBatchSize calls concurrently
+func forEachInBatches(n int, fn func(i int) error) error {
+	for start := 0; start < n; start += _projectFileBatchSize {
+		var fns []func() error
+		for i := start; i < n && i < start+_projectFileBatchSize; i++ {
+			i := i
+			fns = append(fns, func() error { return fn(i) })
+		}
+		if err := parallel.RunFirstErr(fns[0], fns[1:]...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
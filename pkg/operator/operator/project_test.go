@@ -0,0 +1,87 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/hash"
+	"github.com/cortexlabs/cortex/pkg/lib/zip"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/storage"
+	"github.com/stretchr/testify/require"
+)
+
+// memBucket is an in-memory storage.Bucket
+type memBucket struct {
+	storage.Bucket // the methods which aren't implemented panic
+	objects        map[string][]byte
+}
+
+func (bucket *memBucket) UploadBytes(data []byte, key string) error {
+	bucket.objects[key] = data
+	return nil
+}
+
+func (bucket *memBucket) ReadBytes(key string) ([]byte, error) {
+	data, ok := bucket.objects[key]
+	if !ok {
+		return nil, errors.ErrorUnexpected("not found", key)
+	}
+	return data, nil
+}
+
+func (bucket *memBucket) Exists(key string) (bool, error) {
+	_, ok := bucket.objects[key]
+	return ok, nil
+}
+
+func TestAssembleProject(t *testing.T) {
+	bucket := &memBucket{objects: map[string][]byte{
+		"apis/other-team-api/spec.msgpack": []byte("secret"),
+	}}
+	config.Bucket = bucket
+
+	content := []byte("class PythonPredictor:\n    pass\n")
+	contentHash := hash.Bytes(content)
+
+	// the file is zipped at its cleaned path
+	projectBytes, err := AssembleProject(
+		[]schema.ProjectFile{{Path: "/predictor.py", Hash: contentHash}},
+		map[string][]byte{contentHash: content},
+	)
+	require.NoError(t, err)
+	projectFiles, err := zip.UnzipMemToMem(projectBytes)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{"predictor.py": content}, projectFiles)
+
+	// hashes which aren't hashes can't refer to other objects in the bucket
+	for _, fileHash := range []string{"../../apis/other-team-api/spec.msgpack", strings.ToUpper(contentHash), contentHash[1:], ""} {
+		_, err = AssembleProject([]schema.ProjectFile{{Path: "predictor.py", Hash: fileHash}}, nil)
+		require.Equal(t, ErrInvalidProjectFileHash, errors.GetKind(err))
+
+		_, err = MissingProjectFiles([]schema.ProjectFile{{Path: "predictor.py", Hash: fileHash}})
+		require.Equal(t, ErrInvalidProjectFileHash, errors.GetKind(err))
+	}
+
+	missing, err := MissingProjectFiles([]schema.ProjectFile{{Path: "predictor.py", Hash: contentHash}, {Path: "other.py", Hash: hash.String("other")}})
+	require.NoError(t, err)
+	require.Equal(t, []string{hash.String("other")}, missing)
+}
@@ -0,0 +1,268 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/gcp"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/parallel"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	"github.com/cortexlabs/cortex/pkg/lib/zip"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"sigs.k8s.io/yaml"
+)
+
+// reconcileCortexAPIs deploys APIs whose CortexAPI resource has changed, deletes APIs whose CortexAPI resource has been
+// deleted, and re-applies the kubernetes resources of APIs which have drifted from their spec (e.g. due to manual edits);
+// the APIs are reconciled concurrently
+func reconcileCortexAPIs() error {
+	cortexAPIs, err := getCortexAPIs()
+	if err != nil {
+		return err
+	}
+	if len(cortexAPIs) == 0 {
+		return nil
+	}
+
+	fns := make([]func() error, len(cortexAPIs))
+	for i := range cortexAPIs {
+		capi := &cortexAPIs[i]
+		fns[i] = func() error {
+			if err := reconcileCortexAPI(capi); err != nil {
+				return errors.Wrap(err, "reconcile", capi.Name)
+			}
+			return nil
+		}
+	}
+
+	return parallel.RunFirstErr(fns[0], fns[1:]...)
+}
+
+func reconcileCortexAPI(capi *cortexAPI) error {
+	if capi.DeletionTimestamp != nil {
+		if !slices.HasString(capi.Finalizers, _cortexAPIFinalizer) {
+			return nil
+		}
+		if err := DeleteAPI(capi.Name, false); err != nil {
+			return err
+		}
+		capi.Finalizers = slices.SubtractStrSlice(capi.Finalizers, []string{_cortexAPIFinalizer})
+		_, err := updateCortexAPI(capi)
+		return err
+	}
+
+	if !slices.HasString(capi.Finalizers, _cortexAPIFinalizer) {
+		capi.Finalizers = append(capi.Finalizers, _cortexAPIFinalizer)
+		updated, err := updateCortexAPI(capi)
+		if err != nil {
+			return err
+		}
+		capi = updated
+	}
+
+	if capi.Status.ObservedGeneration != capi.Generation {
+		capi.Status.ObservedGeneration = capi.Generation
+		capi.Status.Error = ""
+
+		api, projectID, err := deployCortexAPI(capi)
+		if err != nil {
+			capi.Status.Error = errors.Message(err)
+		} else {
+			capi.Status.ProjectID = projectID
+			capi.Status.APIID = api.ID
+		}
+
+		return updateCortexAPIStatus(capi)
+	}
+
+	// the latest spec failed to deploy, so there is nothing to converge to
+	if capi.Status.Error != "" || capi.Status.APIID == "" {
+		return nil
+	}
+
+	return healAPI(capi.Name, capi.Status.APIID)
+}
+
+func deployCortexAPI(capi *cortexAPI) (*spec.API, string, error) {
+	var projectBytes []byte
+	var err error
+
+	projectID := capi.Spec.ProjectID
+	if capi.Spec.Project != "" {
+		projectBytes, err = readCortexAPIProject(capi.Name, capi.Spec.Project)
+		if err != nil {
+			return nil, "", err
+		}
+		projectID, err = UploadProject(projectBytes)
+		if err != nil {
+			return nil, "", err
+		}
+	} else if projectID != "" {
+		projectBytes, err = config.Bucket.ReadBytes(spec.ProjectKey(projectID))
+		if err != nil {
+			return nil, "", err
+		}
+	} else {
+		return nil, "", ErrorCortexAPIProjectRequired(capi.Name)
+	}
+
+	projectFileMap, err := zip.UnzipMemToMem(projectBytes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	configBytes, configPath, err := cortexAPIConfigFile(capi)
+	if err != nil {
+		return nil, "", err
+	}
+
+	projectFiles := ProjectFiles{
+		ProjectByteMap: projectFileMap,
+		ConfigFilePath: configPath,
+	}
+
+	apiConfigs, err := spec.ExtractAPIConfigs(configBytes, config.Cluster.Provider, projectFiles, configPath, DeployEnvironment())
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := ValidateClusterAPIs(apiConfigs, projectFiles); err != nil {
+		return nil, "", err
+	}
+
+	api, _, err := UpdateAPI(&apiConfigs[0], projectID, true)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return api, projectID, nil
+}
+
+// readCortexAPIProject reads the zipped project at the CortexAPI's spec.project, which is read with the cluster's
+// storage if it's in the cluster's bucket
+func readCortexAPIProject(apiName string, projectPath string) ([]byte, error) {
+	if key, ok := clusterBucketKey(projectPath); ok {
+		return config.Bucket.ReadBytes(key)
+	}
+
+	if aws.IsValidS3Path(projectPath) {
+		awsClient, err := aws.NewFromClientS3Path(projectPath, config.AWS)
+		if err != nil {
+			return nil, err
+		}
+		bucket, key, err := aws.SplitS3Path(projectPath)
+		if err != nil {
+			return nil, err
+		}
+		return awsClient.ReadBytesFromS3(bucket, key)
+	}
+
+	if gcp.IsValidGCSPath(projectPath) && config.GCP != nil {
+		bucket, key, err := gcp.SplitGCSPath(projectPath)
+		if err != nil {
+			return nil, err
+		}
+		return config.GCP.ReadBytesFromGCS(bucket, key)
+	}
+
+	return nil, ErrorInvalidCortexAPIProject(apiName, projectPath)
+}
+
+// clusterBucketKey returns the key of path in the cluster's bucket, or false if path isn't in the cluster's bucket
+func clusterBucketKey(path string) (string, bool) {
+	clusterBucketPrefix := config.Bucket.Path("") + "/"
+	if !strings.HasPrefix(path, clusterBucketPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(path, clusterBucketPrefix), true
+}
+
+// cortexAPIConfigFile returns the CortexAPI's configuration as a configuration file (and the file's path)
+func cortexAPIConfigFile(capi *cortexAPI) ([]byte, string, error) {
+	configData := make(map[string]interface{}, len(capi.Spec.Config)+1)
+	for key, value := range capi.Spec.Config {
+		configData[key] = value
+	}
+	if name, ok := configData[userconfig.NameKey]; !ok {
+		configData[userconfig.NameKey] = capi.Name
+	} else if name != capi.Name {
+		return nil, "", ErrorCortexAPINameMismatch(capi.Name, name)
+	}
+
+	configBytes, err := yaml.Marshal([]map[string]interface{}{configData})
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	configPath := capi.Spec.ConfigPath
+	if configPath == "" {
+		configPath = _cortexAPIDefaultConfigPath
+	}
+
+	return configBytes, configPath, nil
+}
+
+// healAPI re-applies the API's kubernetes resources if any are missing or the deployment was modified
+func healAPI(apiName string, apiID string) error {
+	unlock := lockAPI(apiName)
+	defer unlock()
+
+	deployment, err := getAPIDeployment(apiName)
+	if err != nil {
+		return err
+	}
+	// the API may have been refreshed since its CortexAPI resource was applied
+	if deployment != nil && deployment.Labels["apiID"] != "" {
+		apiID = deployment.Labels["apiID"]
+	}
+
+	api, err := DownloadAPISpec(apiName, apiID)
+	if err != nil {
+		return err
+	}
+
+	prevDeployment, prevService, prevRoute, err := getK8sResources(api.API)
+	if err != nil {
+		return err
+	}
+
+	if prevDeployment != nil && prevService != nil && prevRoute != nil {
+		isUpdating, err := isAPIUpdating(prevDeployment)
+		if err != nil {
+			return err
+		}
+		if isUpdating {
+			return nil
+		}
+
+		desiredDeployment := deploymentSpec(api, prevDeployment)
+		if areAPIsEqual(desiredDeployment, prevDeployment) && k8s.PodContainersEqual(&desiredDeployment.Spec.Template.Spec, &prevDeployment.Spec.Template.Spec) {
+			return nil
+		}
+	}
+
+	release := acquireAPIUpdateSlot()
+	defer release()
+
+	return applyK8sResources(api, prevDeployment, prevService, prevRoute)
+}
@@ -0,0 +1,380 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/urls"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+)
+
+const (
+	_replayKind            = "replays"
+	_replayHeader          = "x-cortex-replay" // replayed requests are not captured by the target API
+	_replayRequestTimeout  = 60 * time.Second
+	_replayProgressPeriod  = 10 * time.Second
+	_maxReplayTimeRange    = 7 * 24 * time.Hour
+	_maxReplayRequests     = 100000
+	_maxReplayConcurrency  = 50
+	_maxReplayDiffs        = 10
+	_maxReplayDiffLength   = 1000 // characters of each response
+	_jsonContentTypePrefix = "application/json"
+)
+
+var _replayHTTPClient = &http.Client{Timeout: _replayRequestTimeout}
+
+// the IDs of the replays which are running in this process
+var _runningReplays = map[string]bool{}
+var _runningReplaysMutex sync.Mutex
+
+// a request and its response, as captured by the API (see payload_logging.py)
+type capturedRequest struct {
+	Timestamp           float64 `json:"timestamp"` // unix time in seconds
+	RequestID           string  `json:"request_id"`
+	ContentType         string  `json:"content_type"`
+	Body                []byte  `json:"body"`
+	StatusCode          int     `json:"status_code"`
+	ResponseContentType string  `json:"response_content_type"`
+	Response            []byte  `json:"response"`
+}
+
+type replayRun struct {
+	sync.Mutex
+	replay    *schema.Replay
+	targetURL string
+}
+
+// StartReplay re-sends the requests which apiName captured between start and end to targetAPIName (which may be apiName itself);
+// the replay runs in the background, and its progress can be checked with GetReplay()
+func StartReplay(apiName string, targetAPIName string, start time.Time, end time.Time, speed float64) (*schema.Replay, error) {
+	if !end.After(start) || end.Sub(start) > _maxReplayTimeRange {
+		return nil, ErrorInvalidReplayTimeRange(start, end, _maxReplayTimeRange)
+	}
+
+	deployment, err := getAPIDeployment(targetAPIName)
+	if err != nil {
+		return nil, err
+	}
+	if deployment == nil {
+		return nil, ErrorAPINotDeployed(targetAPIName)
+	}
+
+	targetAPI, err := DownloadAPISpec(targetAPIName, deployment.Labels["apiID"])
+	if err != nil {
+		return nil, err
+	}
+	if targetAPI.Stream != nil {
+		return nil, ErrorReplayTargetIsStreamAPI(targetAPIName)
+	}
+
+	loadBalancerURL, err := APILoadBalancerURL()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	run := &replayRun{
+		replay: &schema.Replay{
+			ID:        fmt.Sprintf("%x", now.UnixNano()), // sorts chronologically
+			APIName:   apiName,
+			TargetAPI: targetAPIName,
+			Start:     start,
+			End:       end,
+			Speed:     speed,
+			Status:    schema.ReplayStatusRunning,
+			StartedAt: now.Unix(),
+			Diffs:     []schema.ReplayDiff{},
+		},
+		targetURL: urls.Join(loadBalancerURL, *targetAPI.Endpoint),
+	}
+
+	if err := run.save(); err != nil {
+		return nil, err
+	}
+
+	_runningReplaysMutex.Lock()
+	_runningReplays[run.replay.ID] = true
+	_runningReplaysMutex.Unlock()
+
+	go run.run()
+
+	return run.replay, nil
+}
+
+func GetReplay(apiName string, replayID string) (*schema.Replay, error) {
+	var replay schema.Replay
+	exists, err := config.Metadata.Get(_replayKind, replayKey(apiName, replayID), &replay)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrorReplayNotFound(apiName, replayID)
+	}
+
+	setInterruptedStatus(&replay)
+	return &replay, nil
+}
+
+func ListReplays(apiName string) ([]schema.Replay, error) {
+	items, err := config.Metadata.List(_replayKind, apiName+"/")
+	if err != nil {
+		return nil, err
+	}
+
+	replays := make([]schema.Replay, len(items))
+	for i, item := range items {
+		if err := item.Unmarshal(&replays[i]); err != nil {
+			return nil, err
+		}
+		setInterruptedStatus(&replays[i])
+	}
+
+	return replays, nil
+}
+
+func replayKey(apiName string, replayID string) string {
+	return apiName + "/" + replayID
+}
+
+// replays don't survive operator restarts, so a replay which is running but isn't running in this process was interrupted
+func setInterruptedStatus(replay *schema.Replay) {
+	if replay.Status != schema.ReplayStatusRunning {
+		return
+	}
+
+	_runningReplaysMutex.Lock()
+	defer _runningReplaysMutex.Unlock()
+	if !_runningReplays[replay.ID] {
+		replay.Status = schema.ReplayStatusInterrupted
+	}
+}
+
+func (run *replayRun) run() {
+	defer func() {
+		_runningReplaysMutex.Lock()
+		delete(_runningReplays, run.replay.ID)
+		_runningReplaysMutex.Unlock()
+	}()
+
+	requests, err := listCapturedRequests(run.replay.APIName, run.replay.Start, run.replay.End)
+	if err != nil {
+		run.finish(err)
+		return
+	}
+
+	run.Lock()
+	run.replay.Requests = int64(len(requests))
+	run.Unlock()
+
+	done := make(chan struct{})
+	go run.saveProgress(done)
+
+	sem := make(chan struct{}, _maxReplayConcurrency)
+	var wg sync.WaitGroup
+	replayStart := time.Now()
+
+	for _, request := range requests {
+		// preserve the captured requests' relative timing, scaled by the replay's speed
+		if run.replay.Speed > 0 {
+			offset := (request.Timestamp - requests[0].Timestamp) / run.replay.Speed
+			time.Sleep(time.Until(replayStart.Add(time.Duration(offset * float64(time.Second)))))
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(request capturedRequest) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			run.send(request)
+		}(request)
+	}
+
+	wg.Wait()
+	close(done)
+	run.finish(nil)
+}
+
+func (run *replayRun) send(request capturedRequest) {
+	statusCode, contentType, response, err := sendReplayRequest(run.targetURL, request)
+
+	run.Lock()
+	defer run.Unlock()
+
+	run.replay.Sent++
+	if err != nil {
+		run.replay.Failed++
+		return
+	}
+
+	if statusCode == request.StatusCode && responsesEqual(request.Response, request.ResponseContentType, response, contentType) {
+		run.replay.Matching++
+		return
+	}
+
+	run.replay.Differing++
+	if len(run.replay.Diffs) < _maxReplayDiffs {
+		run.replay.Diffs = append(run.replay.Diffs, schema.ReplayDiff{
+			RequestID:        request.RequestID,
+			StatusCode:       request.StatusCode,
+			TargetStatusCode: statusCode,
+			Response:         s.TruncateEllipses(string(request.Response), _maxReplayDiffLength),
+			TargetResponse:   s.TruncateEllipses(string(response), _maxReplayDiffLength),
+		})
+	}
+}
+
+func (run *replayRun) saveProgress(done <-chan struct{}) {
+	ticker := time.NewTicker(_replayProgressPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := run.save(); err != nil {
+				errors.PrintError(err, "failed to save the progress of replay", run.replay.ID)
+			}
+		}
+	}
+}
+
+func (run *replayRun) finish(err error) {
+	run.Lock()
+	run.replay.Status = schema.ReplayStatusCompleted
+	if err != nil {
+		run.replay.Status = schema.ReplayStatusFailed
+		run.replay.Error = errors.Message(err)
+	}
+	finishedAt := time.Now().Unix()
+	run.replay.FinishedAt = &finishedAt
+	run.Unlock()
+
+	if err := run.save(); err != nil {
+		errors.PrintError(err, "failed to save replay", run.replay.ID)
+	}
+}
+
+func (run *replayRun) save() error {
+	run.Lock()
+	replay := *run.replay
+	replay.Diffs = append([]schema.ReplayDiff{}, run.replay.Diffs...)
+	run.Unlock()
+
+	return config.Metadata.Put(_replayKind, replayKey(replay.APIName, replay.ID), replay)
+}
+
+// returns the requests which were captured between start and end, sorted by the time they were received
+func listCapturedRequests(apiName string, start time.Time, end time.Time) ([]capturedRequest, error) {
+	var requests []capturedRequest
+
+	// files are stored under the hour in which their first request was received, so the previous hour's files may contain requests in the time range
+	for hour := start.UTC().Truncate(time.Hour).Add(-time.Hour); hour.Before(end); hour = hour.Add(time.Hour) {
+		prefix := filepath.Join(spec.PayloadLogRoot(apiName), hour.Format("2006-01-02"), hour.Format("15")) + "/"
+		keys, err := config.Bucket.ListKeys(prefix, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range keys {
+			data, err := config.Bucket.ReadBytes(key)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, line := range bytes.Split(data, []byte("\n")) {
+				if len(bytes.TrimSpace(line)) == 0 {
+					continue
+				}
+
+				var request capturedRequest
+				if err := json.Unmarshal(line, &request); err != nil {
+					return nil, errors.Wrap(err, key)
+				}
+
+				timestamp := time.Unix(0, int64(request.Timestamp*float64(time.Second)))
+				if timestamp.Before(start) || !timestamp.Before(end) {
+					continue
+				}
+
+				if len(requests) == _maxReplayRequests {
+					return nil, ErrorTooManyReplayRequests(_maxReplayRequests)
+				}
+				requests = append(requests, request)
+			}
+		}
+	}
+
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].Timestamp < requests[j].Timestamp
+	})
+
+	return requests, nil
+}
+
+func sendReplayRequest(targetURL string, request capturedRequest) (int, string, []byte, error) {
+	req, err := http.NewRequest(http.MethodPost, targetURL, bytes.NewReader(request.Body))
+	if err != nil {
+		return 0, "", nil, errors.WithStack(err)
+	}
+	if request.ContentType != "" {
+		req.Header.Set("Content-Type", request.ContentType)
+	}
+	req.Header.Set(_replayHeader, "true")
+
+	resp, err := _replayHTTPClient.Do(req)
+	if err != nil {
+		return 0, "", nil, errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, "", nil, errors.WithStack(err)
+	}
+
+	return resp.StatusCode, resp.Header.Get("Content-Type"), body, nil
+}
+
+// JSON responses are compared by value, so that differences in formatting (e.g. key order) are ignored
+func responsesEqual(response []byte, contentType string, targetResponse []byte, targetContentType string) bool {
+	if strings.HasPrefix(contentType, _jsonContentTypePrefix) && strings.HasPrefix(targetContentType, _jsonContentTypePrefix) {
+		var obj, targetObj interface{}
+		if json.Unmarshal(response, &obj) == nil && json.Unmarshal(targetResponse, &targetObj) == nil {
+			return reflect.DeepEqual(obj, targetObj)
+		}
+	}
+
+	return bytes.Equal(response, targetResponse)
+}
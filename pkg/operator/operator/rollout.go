@@ -0,0 +1,319 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/parallel"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/types/metrics"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	kapps "k8s.io/api/apps/v1"
+	kcore "k8s.io/api/core/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	_rolloutCheckPeriod = 30 * time.Second
+
+	_deployRolledBackEvent = "deploy_rolled_back"
+)
+
+// rolloutWatch tracks an update of an API which has a rollout_policy, until its bake window has elapsed
+type rolloutWatch struct {
+	apiName   string
+	apiID     string
+	prevAPIID string
+	startTime time.Time
+	policy    userconfig.RolloutPolicy
+}
+
+var (
+	_rolloutWatchesMutex sync.Mutex
+	_rolloutWatches      = make(map[string]*rolloutWatch) // apiName -> the update which is being baked
+)
+
+// watchRollout is called after an API has been updated; rollouts which were started by a previous operator aren't watched
+func watchRollout(api *spec.API, prevAPIID string) {
+	_rolloutWatchesMutex.Lock()
+	defer _rolloutWatchesMutex.Unlock()
+
+	if api.RolloutPolicy == nil || prevAPIID == "" || prevAPIID == api.ID {
+		delete(_rolloutWatches, api.Name)
+		return
+	}
+
+	_rolloutWatches[api.Name] = &rolloutWatch{
+		apiName:   api.Name,
+		apiID:     api.ID,
+		prevAPIID: prevAPIID,
+		startTime: time.Now(),
+		policy:    *api.RolloutPolicy,
+	}
+}
+
+func forgetRolloutWatch(apiName string) {
+	_rolloutWatchesMutex.Lock()
+	defer _rolloutWatchesMutex.Unlock()
+	delete(_rolloutWatches, apiName)
+}
+
+// claimRolloutWatch stops watching the rollout, and returns false if it was already stopped (e.g. by another check)
+func claimRolloutWatch(watch *rolloutWatch) bool {
+	_rolloutWatchesMutex.Lock()
+	defer _rolloutWatchesMutex.Unlock()
+
+	if _rolloutWatches[watch.apiName] != watch {
+		return false
+	}
+	delete(_rolloutWatches, watch.apiName)
+	return true
+}
+
+func getRolloutWatch(apiName string) *rolloutWatch {
+	_rolloutWatchesMutex.Lock()
+	defer _rolloutWatchesMutex.Unlock()
+	return _rolloutWatches[apiName]
+}
+
+func getRolloutWatches() []*rolloutWatch {
+	_rolloutWatchesMutex.Lock()
+	defer _rolloutWatchesMutex.Unlock()
+
+	watches := make([]*rolloutWatch, 0, len(_rolloutWatches))
+	for _, watch := range _rolloutWatches {
+		watches = append(watches, watch)
+	}
+	return watches
+}
+
+// checkRollouts rolls back updates whose replicas fail, or whose error rate or latency regress relative to the previous version, within their bake window
+func checkRollouts() error {
+	watches := getRolloutWatches()
+	if len(watches) == 0 {
+		return nil
+	}
+
+	deployments, err := listAPIDeployments(klabels.Everything())
+	if err != nil {
+		return err
+	}
+
+	pods, err := listAPIPods(klabels.Everything())
+	if err != nil {
+		return err
+	}
+
+	for _, watch := range watches {
+		var deployment *kapps.Deployment
+		for i := range deployments {
+			if deployments[i].Labels["apiName"] == watch.apiName {
+				deployment = &deployments[i]
+				break
+			}
+		}
+
+		// the API was deleted or updated again
+		if deployment == nil || deployment.Labels["apiID"] != watch.apiID {
+			forgetRolloutWatch(watch.apiName)
+			continue
+		}
+
+		var apiPods []kcore.Pod
+		for _, pod := range pods {
+			if pod.Labels["apiName"] == watch.apiName {
+				apiPods = append(apiPods, pod)
+			}
+		}
+
+		reason, err := rolloutRollbackReason(watch, deployment, apiPods)
+		if err != nil {
+			errors.PrintError(err, "failed to check the rollout of "+watch.apiName)
+			continue
+		}
+
+		if reason != "" {
+			if claimRolloutWatch(watch) {
+				if err := rollbackAPI(watch, reason); err != nil {
+					errors.PrintError(err, "failed to roll back "+watch.apiName)
+				}
+			}
+			continue
+		}
+
+		if time.Since(watch.startTime) >= watch.policy.BakeWindow {
+			forgetRolloutWatch(watch.apiName)
+		}
+	}
+
+	return nil
+}
+
+// checkRolloutReplicas is called when an API's deployment or pods change, so that an update whose replicas fail is
+// rolled back immediately (rather than at the next periodic check)
+func checkRolloutReplicas(apiName string) error {
+	watch := getRolloutWatch(apiName)
+	if watch == nil {
+		return nil
+	}
+
+	deployment, err := getCachedAPIDeployment(apiName)
+	if err != nil {
+		return err
+	}
+	if deployment == nil || deployment.Labels["apiID"] != watch.apiID {
+		return nil // handled by checkRollouts()
+	}
+
+	pods, err := listAPIPods(apiSelector(apiName))
+	if err != nil {
+		return err
+	}
+
+	reason := failedReplicasRollbackReason(deployment, pods)
+	if reason == "" || !claimRolloutWatch(watch) {
+		return nil
+	}
+	return rollbackAPI(watch, reason)
+}
+
+func failedReplicasRollbackReason(deployment *kapps.Deployment, pods []kcore.Pod) string {
+	counts := getReplicaCounts(deployment, pods)
+	if counts.Updated.TotalFailed() > 0 {
+		return fmt.Sprintf("%d %s of the new version failed", counts.Updated.TotalFailed(), s.PluralS("replica", counts.Updated.TotalFailed()))
+	}
+	return ""
+}
+
+// rolloutRollbackReason returns the reason that the update should be rolled back, or an empty string if it is healthy so far
+func rolloutRollbackReason(watch *rolloutWatch, deployment *kapps.Deployment, pods []kcore.Pod) (string, error) {
+	if reason := failedReplicasRollbackReason(deployment, pods); reason != "" {
+		return reason, nil
+	}
+
+	if watch.policy.MaxErrorRateIncrease == nil && watch.policy.MaxLatencyIncrease == nil {
+		return "", nil
+	}
+
+	now := time.Now()
+	elapsed := now.Sub(watch.startTime)
+	if elapsed < time.Minute {
+		return "", nil // wait for the new version to report metrics
+	}
+
+	var newStats, prevStats rolloutStats
+	err := parallel.RunFirstErr(
+		func() error {
+			var err error
+			newStats, err = getRolloutStats(watch.apiName, watch.apiID, watch.startTime, now)
+			return err
+		},
+		// the previous version is measured over the same length of time, immediately before the update
+		func() error {
+			var err error
+			prevStats, err = getRolloutStats(watch.apiName, watch.prevAPIID, watch.startTime.Add(-elapsed), watch.startTime)
+			return err
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	if newStats.requests < watch.policy.MinRequests {
+		return "", nil
+	}
+
+	if watch.policy.MaxErrorRateIncrease != nil {
+		if newStats.errorRate() > prevStats.errorRate()+*watch.policy.MaxErrorRateIncrease {
+			return fmt.Sprintf("the new version's error rate (%s) exceeded the previous version's error rate (%s) by more than %s", s.Round(newStats.errorRate(), 3, 0), s.Round(prevStats.errorRate(), 3, 0), s.Float64(*watch.policy.MaxErrorRateIncrease)), nil
+		}
+	}
+
+	if watch.policy.MaxLatencyIncrease != nil && newStats.latency != nil && prevStats.latency != nil {
+		if *newStats.latency > *prevStats.latency*(1+*watch.policy.MaxLatencyIncrease) {
+			return fmt.Sprintf("the new version's average latency (%s ms) exceeded the previous version's average latency (%s ms) by more than %s%%", s.Round(*newStats.latency, 1, 0), s.Round(*prevStats.latency, 1, 0), s.Round(*watch.policy.MaxLatencyIncrease*100, 1, 0)), nil
+		}
+	}
+
+	return "", nil
+}
+
+type rolloutStats struct {
+	requests int64
+	errors   int64
+	latency  *float64 // milliseconds (average)
+}
+
+func (stats rolloutStats) errorRate() float64 {
+	if stats.requests == 0 {
+		return 0
+	}
+	return float64(stats.errors) / float64(stats.requests)
+}
+
+func getRolloutStats(apiName string, apiID string, startTime time.Time, endTime time.Time) (rolloutStats, error) {
+	timeSeries, err := GetMetricsTimeSeries(apiName, apiID, "", startTime, endTime, 0)
+	if err != nil {
+		return rolloutStats{}, err
+	}
+	return sumRolloutStats(timeSeries.Datapoints), nil
+}
+
+func sumRolloutStats(datapoints []metrics.Datapoint) rolloutStats {
+	var stats rolloutStats
+	var latencySum float64
+	var latencyRequests int64
+
+	for _, datapoint := range datapoints {
+		stats.requests += int64(datapoint.Requests)
+		stats.errors += int64(datapoint.Code5XX)
+		if datapoint.LatencyAvg != nil {
+			latencySum += *datapoint.LatencyAvg * float64(datapoint.Requests)
+			latencyRequests += int64(datapoint.Requests)
+		}
+	}
+
+	if latencyRequests > 0 {
+		latency := latencySum / float64(latencyRequests)
+		stats.latency = &latency
+	}
+
+	return stats
+}
+
+// rollbackAPI re-applies the previous version of the API's spec (which is still in the bucket)
+func rollbackAPI(watch *rolloutWatch, reason string) error {
+	prevAPI, err := DownloadAPISpec(watch.apiName, watch.prevAPIID)
+	if err != nil {
+		return err
+	}
+
+	if err := reapplyAPI(prevAPI); err != nil {
+		return err
+	}
+
+	recordDeploymentEvent(watch.apiName, prevAPI, "rollback")
+	notifyDeployStarted(prevAPI, fmt.Sprintf("rolling back %s to its previous version (%s)", watch.apiName, reason))
+	notify(watch.apiName, watch.apiID, _deployRolledBackEvent, fmt.Sprintf("%s was rolled back to its previous version because %s", watch.apiName, reason))
+
+	return nil
+}
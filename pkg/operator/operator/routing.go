@@ -0,0 +1,281 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/urls"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	istionetworking "istio.io/api/networking/v1alpha3"
+	istioclientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	kextensions "k8s.io/api/extensions/v1beta1"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// an apiRoute is the resource which routes requests for an API's endpoint to the API's service
+type apiRoute struct {
+	object    kmeta.Object // *istioclientnetworking.VirtualService or *kextensions.Ingress
+	apiName   string
+	endpoints []string // the API's endpoint, followed by its additional endpoints
+}
+
+// an apiRouter creates and manages APIs' routes for a networking backend (see the cluster's networking_backend)
+type apiRouter interface {
+	routeKind() string
+	// routeSpec returns the route which would be applied for the API
+	routeSpec(api *spec.API) kmeta.Object
+	// getRoute returns nil if the API has no route
+	getRoute(apiName string, namespace string) (*apiRoute, error)
+	// listRoutes returns the routes of all APIs, in all namespaces
+	listRoutes() ([]apiRoute, error)
+	applyRoute(api *spec.API, prevRoute *apiRoute) error
+	deleteRoute(apiName string, namespace string) error
+	// loadBalancerURL returns the base URL of the load balancer which APIs are served from
+	loadBalancerURL() (string, error)
+}
+
+func router() apiRouter {
+	if config.Cluster.NetworkingBackend == clusterconfig.IngressNetworkingBackend {
+		return &ingressRouter{}
+	}
+	return &istioRouter{}
+}
+
+// features which rely on istio resources other than the route (envoy filters and destination rules) are only
+// available when using the istio networking backend
+func isIstioNetworking() bool {
+	return config.Cluster.NetworkingBackend != clusterconfig.IngressNetworkingBackend
+}
+
+// apiHTTPRoute returns the virtual service's route for the API's endpoint which doesn't match on any headers (it is the last
+// route, since the routes for pinned versions must be matched first), or nil if there is none
+func apiHTTPRoute(virtualService *istioclientnetworking.VirtualService) *istionetworking.HTTPRoute {
+	if len(virtualService.Spec.Http) == 0 {
+		return nil
+	}
+	return virtualService.Spec.Http[len(virtualService.Spec.Http)-1]
+}
+
+// additionalHTTPRoutes returns the virtual service's routes for the API's additional endpoints
+func additionalHTTPRoutes(virtualService *istioclientnetworking.VirtualService) []*istionetworking.HTTPRoute {
+	var httpRoutes []*istionetworking.HTTPRoute
+	for _, httpRoute := range virtualService.Spec.Http {
+		if k8s.IsAdditionalVirtualServiceRoute(httpRoute) {
+			httpRoutes = append(httpRoutes, httpRoute)
+		}
+	}
+	return httpRoutes
+}
+
+// mirrorAdditionalRoutes sends requests to the API's additional endpoints to the same destinations as its endpoint; it
+// must be called whenever the destinations of the route returned by apiHTTPRoute() are modified
+func mirrorAdditionalRoutes(virtualService *istioclientnetworking.VirtualService) {
+	httpRoute := apiHTTPRoute(virtualService)
+	for _, additionalRoute := range additionalHTTPRoutes(virtualService) {
+		additionalRoute.Route = httpRoute.Route
+		additionalRoute.CorsPolicy = httpRoute.CorsPolicy
+		additionalRoute.Headers = httpRoute.Headers
+	}
+}
+
+// routeObject returns nil (rather than a typed nil pointer) if the route doesn't exist
+func routeObject(route *apiRoute) interface{} {
+	if route == nil {
+		return nil
+	}
+	return route.object
+}
+
+type istioRouter struct{}
+
+func (r *istioRouter) routeKind() string {
+	return "VirtualService"
+}
+
+func (r *istioRouter) routeSpec(api *spec.API) kmeta.Object {
+	return virtualServiceSpec(api)
+}
+
+func (r *istioRouter) getRoute(apiName string, namespace string) (*apiRoute, error) {
+	virtualService, err := config.K8sNamespace(namespace).GetVirtualService(k8sName(apiName))
+	if err != nil || virtualService == nil {
+		return nil, err
+	}
+	return istioRoute(virtualService), nil
+}
+
+func (r *istioRouter) listRoutes() ([]apiRoute, error) {
+	virtualServices, err := config.K8sAllNamspaces.ListVirtualServices(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var routes []apiRoute
+	for i := range virtualServices {
+		// only virtual services on the APIs gateway route to APIs
+		if !k8s.ExtractVirtualServiceGateways(&virtualServices[i]).Has(apisGateway(virtualServices[i].Namespace)) {
+			continue
+		}
+		routes = append(routes, *istioRoute(&virtualServices[i]))
+	}
+	return routes, nil
+}
+
+func (r *istioRouter) applyRoute(api *spec.API, prevRoute *apiRoute) error {
+	newVirtualService := virtualServiceSpec(api)
+
+	k8sNamespace := config.K8sNamespace(api.Namespace)
+
+	if prevRoute == nil {
+		_, err := k8sNamespace.CreateVirtualService(newVirtualService)
+		return err
+	}
+
+	_, err := k8sNamespace.UpdateVirtualService(prevRoute.object.(*istioclientnetworking.VirtualService), newVirtualService)
+	return err
+}
+
+func (r *istioRouter) deleteRoute(apiName string, namespace string) error {
+	_, err := config.K8sNamespace(namespace).DeleteVirtualService(k8sName(apiName))
+	return err
+}
+
+func (r *istioRouter) loadBalancerURL() (string, error) {
+	service, err := config.K8sIstio.GetService("ingressgateway-apis")
+	if err != nil {
+		return "", err
+	}
+	if service == nil {
+		return "", ErrorCortexInstallationBroken()
+	}
+	if len(service.Status.LoadBalancer.Ingress) == 0 {
+		return "", ErrorLoadBalancerInitializing()
+	}
+	return "http://" + service.Status.LoadBalancer.Ingress[0].Hostname, nil
+}
+
+func istioRoute(virtualService *istioclientnetworking.VirtualService) *apiRoute {
+	var endpoints []string
+	if httpRoute := apiHTTPRoute(virtualService); httpRoute != nil && len(httpRoute.Match) > 0 {
+		endpoints = append(endpoints, urls.CanonicalizeEndpoint(httpRoute.Match[0].Uri.GetExact()))
+	}
+	for _, httpRoute := range additionalHTTPRoutes(virtualService) {
+		if len(httpRoute.Match) > 0 {
+			endpoints = append(endpoints, urls.CanonicalizeEndpoint(httpRoute.Match[0].Uri.GetExact()))
+		}
+	}
+
+	return &apiRoute{
+		object:    virtualService,
+		apiName:   virtualService.Labels["apiName"],
+		endpoints: endpoints,
+	}
+}
+
+type ingressRouter struct{}
+
+func (r *ingressRouter) routeKind() string {
+	return "Ingress"
+}
+
+func (r *ingressRouter) routeSpec(api *spec.API) kmeta.Object {
+	return ingressSpec(api)
+}
+
+func (r *ingressRouter) getRoute(apiName string, namespace string) (*apiRoute, error) {
+	ingress, err := config.K8sNamespace(namespace).GetIngress(k8sName(apiName))
+	if err != nil || ingress == nil {
+		return nil, err
+	}
+	return ingressRoute(ingress), nil
+}
+
+func (r *ingressRouter) listRoutes() ([]apiRoute, error) {
+	ingresses, err := config.K8sAllNamspaces.ListIngressesWithLabelKeys("apiName")
+	if err != nil {
+		return nil, err
+	}
+
+	routes := make([]apiRoute, len(ingresses))
+	for i := range ingresses {
+		routes[i] = *ingressRoute(&ingresses[i])
+	}
+	return routes, nil
+}
+
+func (r *ingressRouter) applyRoute(api *spec.API, prevRoute *apiRoute) error {
+	newIngress := ingressSpec(api)
+
+	k8sNamespace := config.K8sNamespace(api.Namespace)
+
+	if prevRoute == nil {
+		_, err := k8sNamespace.CreateIngress(newIngress)
+		return err
+	}
+
+	newIngress.ResourceVersion = prevRoute.object.GetResourceVersion()
+	_, err := k8sNamespace.UpdateIngress(newIngress)
+	return err
+}
+
+func (r *ingressRouter) deleteRoute(apiName string, namespace string) error {
+	_, err := config.K8sNamespace(namespace).DeleteIngress(k8sName(apiName))
+	return err
+}
+
+func (r *ingressRouter) loadBalancerURL() (string, error) {
+	split := strings.Split(config.Cluster.IngressControllerService, "/")
+	service, err := config.K8sNamespace(split[0]).GetService(split[1])
+	if err != nil {
+		return "", err
+	}
+	if service == nil {
+		return "", ErrorIngressControllerNotFound(config.Cluster.IngressControllerService)
+	}
+	if len(service.Status.LoadBalancer.Ingress) == 0 {
+		// kind and minikube clusters usually can't provision load balancers, so lightweight clusters fall back to the service's cluster DNS name
+		if config.Cluster.Lightweight {
+			return "http://" + service.Name + "." + service.Namespace, nil
+		}
+		return "", ErrorLoadBalancerInitializing()
+	}
+
+	loadBalancer := service.Status.LoadBalancer.Ingress[0]
+	if loadBalancer.Hostname != "" {
+		return "http://" + loadBalancer.Hostname, nil
+	}
+	return "http://" + loadBalancer.IP, nil
+}
+
+func ingressRoute(ingress *kextensions.Ingress) *apiRoute {
+	var endpoints []string
+	if len(ingress.Spec.Rules) > 0 && ingress.Spec.Rules[0].HTTP != nil {
+		for _, path := range ingress.Spec.Rules[0].HTTP.Paths {
+			endpoints = append(endpoints, path.Path)
+		}
+	}
+
+	return &apiRoute{
+		object:    ingress,
+		apiName:   ingress.Labels["apiName"],
+		endpoints: endpoints,
+	}
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/metrics"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	kapps "k8s.io/api/apps/v1"
+)
+
+const (
+	_sloCheckPeriod = 5 * time.Minute
+
+	// the burn rate is measured over this window (at the highest resolution which CloudWatch keeps for it)
+	_sloBurnRateWindow = time.Hour
+
+	_errorBudgetExhaustedEvent = "error_budget_exhausted"
+	_errorBudgetRestoredEvent  = "error_budget_restored"
+)
+
+var _exhaustedErrorBudgets = struct {
+	sync.Mutex
+	apiNames map[string]bool
+}{
+	apiNames: map[string]bool{},
+}
+
+type sloStats struct {
+	requests    int64
+	errors      int64 // 5XX responses
+	periods     int64 // periods which received requests
+	slowPeriods int64 // periods in which the p99 latency exceeded the SLO's latency target
+}
+
+// GetSLOStatus returns the API's compliance with its SLO, and how much of its error budget remains
+func GetSLOStatus(apiName string) (*schema.SLOStatus, error) {
+	deployment, err := getAPIDeployment(apiName)
+	if err != nil {
+		return nil, err
+	}
+	if deployment == nil {
+		return nil, ErrorAPINotDeployed(apiName)
+	}
+
+	slo := sloFromDeployment(deployment)
+	if slo == nil {
+		return nil, ErrorNoSLO(apiName)
+	}
+
+	return getSLOStatus(apiName, slo, time.Now())
+}
+
+// the SLO is read from the deployment's annotation, so that the SLO cron doesn't need to download the API's spec
+func sloFromDeployment(deployment *kapps.Deployment) *userconfig.SLO {
+	sloJSON, ok := deployment.Annotations[userconfig.SLOAnnotationKey]
+	if !ok {
+		return nil
+	}
+	var slo userconfig.SLO
+	if err := json.Unmarshal([]byte(sloJSON), &slo); err != nil {
+		return nil
+	}
+	return &slo
+}
+
+// the SLO is tracked across all versions of the API (so updates don't reset the error budget)
+func getSLOStatus(apiName string, slo *userconfig.SLO, endTime time.Time) (*schema.SLOStatus, error) {
+	startTime := endTime.Add(-slo.Window)
+	burnRateStartTime := endTime.Add(-_sloBurnRateWindow)
+
+	timeSeries, err := GetMetricsTimeSeries(apiName, "", "", startTime, endTime, 0)
+	if err != nil {
+		return nil, err
+	}
+	burnRateTimeSeries, err := GetMetricsTimeSeries(apiName, "", "", burnRateStartTime, endTime, 60)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := sumSLOStats(timeSeries.Datapoints, slo.LatencyP99)
+	burnRateStats := sumSLOStats(burnRateTimeSeries.Datapoints, slo.LatencyP99)
+
+	status := &schema.SLOStatus{
+		APIName:   apiName,
+		StartTime: startTime,
+		EndTime:   endTime,
+	}
+
+	status.Objectives = append(status.Objectives, sloObjectiveStatus(userconfig.AvailabilityKey, slo.Availability, stats.requests, stats.errors, burnRateStats.requests, burnRateStats.errors))
+	if slo.LatencyP99 != nil {
+		status.Objectives = append(status.Objectives, sloObjectiveStatus(userconfig.LatencyP99Key, slo.Availability, stats.periods, stats.slowPeriods, burnRateStats.periods, burnRateStats.slowPeriods))
+	}
+
+	for _, objective := range status.Objectives {
+		if objective.ErrorBudgetRemaining <= 0 {
+			status.BudgetExhausted = true
+		}
+	}
+
+	return status, nil
+}
+
+func sumSLOStats(datapoints []metrics.Datapoint, latencyTarget *time.Duration) sloStats {
+	var stats sloStats
+	for _, datapoint := range datapoints {
+		if datapoint.Requests == 0 {
+			continue
+		}
+		stats.requests += int64(datapoint.Requests)
+		stats.errors += int64(datapoint.Code5XX)
+		stats.periods++
+		if latencyTarget != nil && datapoint.LatencyP99 != nil && *datapoint.LatencyP99 > float64(*latencyTarget)/float64(time.Millisecond) {
+			stats.slowPeriods++
+		}
+	}
+	return stats
+}
+
+// the error budget is the number of bad events (failed requests, or slow periods) which the target allows during the window;
+// the burn rate compares the fraction of bad events in the last hour with the fraction which the target allows
+func sloObjectiveStatus(name string, target float64, total int64, bad int64, recentTotal int64, recentBad int64) schema.SLOObjectiveStatus {
+	status := schema.SLOObjectiveStatus{
+		Name:                 name,
+		Target:               target,
+		ErrorBudgetRemaining: 1,
+	}
+
+	if total > 0 {
+		status.Compliance = pointer.Float64(1 - float64(bad)/float64(total))
+		status.ErrorBudgetRemaining = 1 - float64(bad)/((1-target)*float64(total))
+	}
+
+	if recentTotal > 0 {
+		status.BurnRate = pointer.Float64(float64(recentBad) / float64(recentTotal) / (1 - target))
+	}
+
+	return status
+}
+
+// isErrorBudgetExhausted returns whether the API's SLO blocks risky actions (e.g. promoting an experiment's winner)
+func isErrorBudgetExhausted(apiName string, slo *userconfig.SLO) (bool, error) {
+	if slo == nil || !slo.BlockPromotions {
+		return false, nil
+	}
+	status, err := getSLOStatus(apiName, slo, time.Now())
+	if err != nil {
+		return false, err
+	}
+	return status.BudgetExhausted, nil
+}
+
+// checkSLOs sends notifications when APIs' error budgets are exhausted (and when they are restored)
+func checkSLOs() error {
+	deployments, err := config.K8sAllNamspaces.ListDeploymentsWithLabelKeys("apiName")
+	if err != nil {
+		return err
+	}
+
+	apiNames := map[string]bool{}
+	for i := range deployments {
+		deployment := &deployments[i]
+		apiName := deployment.Labels["apiName"]
+
+		slo := sloFromDeployment(deployment)
+		if slo == nil {
+			continue
+		}
+		apiNames[apiName] = true
+
+		status, err := getSLOStatus(apiName, slo, time.Now())
+		if err != nil {
+			errors.PrintError(err, "failed to check the slo of "+apiName)
+			continue
+		}
+
+		_exhaustedErrorBudgets.Lock()
+		wasExhausted := _exhaustedErrorBudgets.apiNames[apiName]
+		_exhaustedErrorBudgets.apiNames[apiName] = status.BudgetExhausted
+		_exhaustedErrorBudgets.Unlock()
+
+		if status.BudgetExhausted && !wasExhausted {
+			notify(apiName, deployment.Labels["apiID"], _errorBudgetExhaustedEvent, errorBudgetExhaustedMessage(apiName, slo, status))
+		} else if !status.BudgetExhausted && wasExhausted {
+			notify(apiName, deployment.Labels["apiID"], _errorBudgetRestoredEvent, fmt.Sprintf("%s's error budget is no longer exhausted", apiName))
+		}
+	}
+
+	// forget APIs which have been deleted, or whose SLO has been removed
+	_exhaustedErrorBudgets.Lock()
+	for apiName := range _exhaustedErrorBudgets.apiNames {
+		if !apiNames[apiName] {
+			delete(_exhaustedErrorBudgets.apiNames, apiName)
+		}
+	}
+	_exhaustedErrorBudgets.Unlock()
+
+	return nil
+}
+
+func errorBudgetExhaustedMessage(apiName string, slo *userconfig.SLO, status *schema.SLOStatus) string {
+	var exhausted []string
+	for _, objective := range status.Objectives {
+		if objective.ErrorBudgetRemaining <= 0 {
+			exhausted = append(exhausted, objective.Name)
+		}
+	}
+
+	message := fmt.Sprintf("%s has exhausted its %s error budget for the last %s", apiName, s.StrsAnd(exhausted), slo.Window.String())
+	if slo.BlockPromotions {
+		message += " (experiment winners won't be promoted until the error budget is restored)"
+	}
+	return message
+}
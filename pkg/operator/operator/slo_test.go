@@ -0,0 +1,52 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/types/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSLOObjectiveStatus(t *testing.T) {
+	status := sloObjectiveStatus("availability", 0.99, 1000, 5, 100, 2)
+	require.InDelta(t, 0.995, *status.Compliance, 1e-9)
+	require.InDelta(t, 0.5, status.ErrorBudgetRemaining, 1e-9)
+	require.InDelta(t, 2, *status.BurnRate, 1e-9)
+
+	status = sloObjectiveStatus("availability", 0.99, 1000, 20, 0, 0)
+	require.InDelta(t, -1, status.ErrorBudgetRemaining, 1e-9)
+	require.Nil(t, status.BurnRate)
+
+	status = sloObjectiveStatus("availability", 0.99, 0, 0, 0, 0)
+	require.Nil(t, status.Compliance)
+	require.Equal(t, float64(1), status.ErrorBudgetRemaining)
+}
+
+func TestSumSLOStats(t *testing.T) {
+	datapoints := []metrics.Datapoint{
+		{Requests: 100, Code5XX: 1, LatencyP99: pointer.Float64(200)},
+		{Requests: 50, Code5XX: 0, LatencyP99: pointer.Float64(600)},
+		{Requests: 0},
+	}
+
+	require.Equal(t, sloStats{requests: 150, errors: 1, periods: 2, slowPeriods: 1}, sumSLOStats(datapoints, pointer.Duration(500*time.Millisecond)))
+	require.Equal(t, sloStats{requests: 150, errors: 1, periods: 2, slowPeriods: 0}, sumSLOStats(datapoints, nil))
+}
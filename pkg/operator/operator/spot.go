@@ -0,0 +1,89 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"log"
+	"path"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	kcore "k8s.io/api/core/v1"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// drainInterruptedSpotNodes cordons spot nodes which have received an interruption notice (two minutes before they
+// are reclaimed) and deletes their API pods, so that replacements can be scheduled while the pods shut down gracefully
+func drainInterruptedSpotNodes() error {
+	nodes, err := config.K8s.ListNodesByLabel(_lifecycleNodeLabelKey, _spotLifecycleNodeLabelValue)
+	if err != nil {
+		return err
+	}
+
+	nodesByInstanceID := map[string]*kcore.Node{}
+	for i := range nodes {
+		if nodes[i].Spec.Unschedulable || !strings.HasPrefix(nodes[i].Spec.ProviderID, "aws://") {
+			continue
+		}
+		nodesByInstanceID[path.Base(nodes[i].Spec.ProviderID)] = &nodes[i]
+	}
+
+	instanceIDs := make([]string, 0, len(nodesByInstanceID))
+	for instanceID := range nodesByInstanceID {
+		instanceIDs = append(instanceIDs, instanceID)
+	}
+
+	interruptedInstanceIDs, err := config.AWS.ListInterruptedSpotInstances(instanceIDs)
+	if err != nil {
+		return err
+	}
+
+	for instanceID := range interruptedInstanceIDs {
+		node := nodesByInstanceID[instanceID]
+		log.Printf("spot instance %s (node %s) will be interrupted, draining it", instanceID, node.Name)
+		if err := drainNode(node); err != nil {
+			errors.PrintError(err, "failed to drain node "+node.Name)
+		}
+	}
+
+	return nil
+}
+
+func drainNode(node *kcore.Node) error {
+	node.Spec.Unschedulable = true
+	if _, err := config.K8s.UpdateNode(node); err != nil {
+		return err
+	}
+
+	pods, err := config.K8sAllNamspaces.ListPods(&kmeta.ListOptions{
+		FieldSelector: "spec.nodeName=" + node.Name,
+		LabelSelector: k8s.LabelExistsSelector("apiName"),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, pod := range pods {
+		if _, err := config.K8sNamespace(pod.Namespace).DeletePod(pod.Name); err != nil {
+			errors.PrintError(err, "failed to delete pod "+pod.Name)
+		}
+	}
+
+	return nil
+}
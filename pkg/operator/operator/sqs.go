@@ -0,0 +1,178 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+)
+
+// ensureSQSQueues creates an SQS stream's queues which don't already exist (with the cluster's tags), configures the input
+// queue to move messages which fail max_receive_count times to the dead letter queue, and subscribes the input queue to
+// the s3_trigger bucket's events. Queues (and bucket notifications) are not deleted with the API
+func ensureSQSQueues(api *spec.API) error {
+	if api.Stream == nil || api.Stream.Source != userconfig.SQSStreamSourceType {
+		return nil
+	}
+
+	tags := map[string]string{"cortex.dev/api-name": api.Name}
+	for key, value := range config.Cluster.Tags {
+		tags[key] = value
+	}
+
+	inputURL, err := config.AWS.EnsureSQSQueue(api.Stream.Input, tags)
+	if err != nil {
+		return err
+	}
+
+	if api.Stream.Output != nil {
+		if _, err := config.AWS.EnsureSQSQueue(*api.Stream.Output, tags); err != nil {
+			return err
+		}
+	}
+
+	if api.Stream.S3Trigger != nil {
+		if err := ensureS3Trigger(api, inputURL); err != nil {
+			return err
+		}
+	}
+
+	if api.Stream.DeadLetterQueue == nil {
+		return nil
+	}
+
+	dlqURL, err := config.AWS.EnsureSQSQueue(*api.Stream.DeadLetterQueue, tags)
+	if err != nil {
+		return err
+	}
+	dlqARN, err := config.AWS.GetSQSQueueARN(dlqURL)
+	if err != nil {
+		return err
+	}
+
+	redrivePolicy, err := json.Marshal(map[string]interface{}{
+		"deadLetterTargetArn": dlqARN,
+		"maxReceiveCount":     *api.Stream.MaxReceiveCount,
+	})
+	if err != nil {
+		return err
+	}
+
+	return config.AWS.SetSQSQueueAttributes(inputURL, map[string]string{"RedrivePolicy": string(redrivePolicy)})
+}
+
+func ensureS3Trigger(api *spec.API, queueURL string) error {
+	s3Trigger := api.Stream.S3Trigger
+	notificationID := fmt.Sprintf("cortex-%s-%s", config.Cluster.ClusterName, api.Name)
+	return ensureS3QueueNotification(queueURL, notificationID, s3Trigger.Bucket, s3Trigger.Prefix, s3Trigger.Suffix)
+}
+
+// ensureBatchTriggerQueue creates the batch_trigger's queue if it doesn't exist, and subscribes it to the bucket's events
+// (the queue is consumed by processBatchTriggers). The queue (and bucket notification) is not deleted with the API
+func ensureBatchTriggerQueue(api *spec.API) error {
+	if api.BatchTrigger == nil {
+		return nil
+	}
+
+	tags := map[string]string{"cortex.dev/api-name": api.Name}
+	for key, value := range config.Cluster.Tags {
+		tags[key] = value
+	}
+
+	queueURL, err := config.AWS.EnsureSQSQueue(api.BatchTrigger.Queue, tags)
+	if err != nil {
+		return err
+	}
+
+	notificationID := fmt.Sprintf("cortex-%s-%s-batch", config.Cluster.ClusterName, api.Name)
+	return ensureS3QueueNotification(queueURL, notificationID, api.BatchTrigger.Bucket, api.BatchTrigger.Prefix, api.BatchTrigger.Suffix)
+}
+
+func ensureS3QueueNotification(queueURL string, notificationID string, bucket string, prefix string, suffix string) error {
+	queueARN, err := config.AWS.GetSQSQueueARN(queueURL)
+	if err != nil {
+		return err
+	}
+
+	if err := allowS3ToSendMessages(queueURL, queueARN, bucket); err != nil {
+		return err
+	}
+
+	return config.AWS.EnsureS3QueueNotification(bucket, notificationID, queueARN, prefix, suffix)
+}
+
+// allowS3ToSendMessages adds a statement to the queue's access policy which allows the bucket to send its events to the queue
+// (the policy's other statements are preserved)
+func allowS3ToSendMessages(queueURL string, queueARN string, bucket string) error {
+	policy := map[string]interface{}{
+		"Version": "2012-10-17",
+	}
+
+	currentPolicy, err := config.AWS.GetSQSQueueAttribute(queueURL, "Policy")
+	if err != nil {
+		return err
+	}
+	if currentPolicy != nil && *currentPolicy != "" {
+		if err := json.Unmarshal([]byte(*currentPolicy), &policy); err != nil {
+			return err
+		}
+	}
+
+	// statement IDs may only contain alphanumeric characters
+	sid := "CortexS3Trigger" + strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, bucket)
+
+	statements := []interface{}{}
+	currentStatements, ok := policy["Statement"].([]interface{})
+	if !ok && policy["Statement"] != nil {
+		currentStatements = []interface{}{policy["Statement"]}
+	}
+	for _, statement := range currentStatements {
+		if statementMap, ok := statement.(map[string]interface{}); ok && statementMap["Sid"] == sid {
+			continue
+		}
+		statements = append(statements, statement)
+	}
+
+	policy["Statement"] = append(statements, map[string]interface{}{
+		"Sid":       sid,
+		"Effect":    "Allow",
+		"Principal": map[string]interface{}{"Service": "s3.amazonaws.com"},
+		"Action":    "sqs:SendMessage",
+		"Resource":  queueARN,
+		"Condition": map[string]interface{}{
+			"ArnLike": map[string]interface{}{"aws:SourceArn": "arn:aws:s3:::" + bucket},
+		},
+	})
+
+	policyBytes, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+
+	return config.AWS.SetSQSQueueAttributes(queueURL, map[string]string{"Policy": string(policyBytes)})
+}
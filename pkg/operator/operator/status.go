@@ -18,62 +18,55 @@ package operator
 
 import (
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/cortexlabs/cortex/pkg/lib/k8s"
-	"github.com/cortexlabs/cortex/pkg/lib/parallel"
-	"github.com/cortexlabs/cortex/pkg/operator/config"
 	"github.com/cortexlabs/cortex/pkg/types/status"
 	"github.com/cortexlabs/cortex/pkg/types/userconfig"
 	kapps "k8s.io/api/apps/v1"
 	kcore "k8s.io/api/core/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
 )
 
 const _stalledPodTimeout = 10 * time.Minute
 
 func GetStatus(apiName string) (*status.Status, error) {
-	var deployment *kapps.Deployment
-	var pods []kcore.Pod
-
-	err := parallel.RunFirstErr(
-		func() error {
-			var err error
-			deployment, err = config.K8s.GetDeployment(k8sName(apiName))
-			return err
-		},
-		func() error {
-			var err error
-			pods, err = config.K8s.ListPodsByLabel("apiName", apiName)
-			return err
-		},
-	)
+	deployment, err := getCachedAPIDeployment(apiName)
 	if err != nil {
 		return nil, err
 	}
-
 	if deployment == nil {
 		return nil, ErrorAPINotDeployed(apiName)
 	}
 
-	return apiStatus(deployment, pods)
+	pods, err := listAPIPods(apiSelector(apiName))
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := apiStatus(deployment, pods)
+	if err != nil {
+		return nil, err
+	}
+	status.Replicas = getReplicaStatuses(deployment, pods)
+
+	status.Warnings, err = getHealthWarnings(apiName)
+	if err != nil {
+		return nil, err
+	}
+
+	return status, nil
 }
 
-func GetAllStatuses() ([]status.Status, error) {
-	var deployments []kapps.Deployment
-	var pods []kcore.Pod
-
-	err := parallel.RunFirstErr(
-		func() error {
-			var err error
-			deployments, err = config.K8s.ListDeploymentsWithLabelKeys("apiName")
-			return err
-		},
-		func() error {
-			var err error
-			pods, err = config.K8s.ListPodsWithLabelKeys("apiName")
-			return err
-		},
-	)
+// GetAllStatuses returns the statuses of the APIs whose labels match the selector
+func GetAllStatuses(selector klabels.Selector) ([]status.Status, error) {
+	deployments, err := listAPIDeployments(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := listAPIPods(klabels.Everything())
 	if err != nil {
 		return nil, err
 	}
@@ -104,7 +97,7 @@ func apiStatus(deployment *kapps.Deployment, allPods []kcore.Pod) (*status.Statu
 	status.APIName = deployment.Labels["apiName"]
 	status.APIID = deployment.Labels["apiID"]
 	status.ReplicaCounts = getReplicaCounts(deployment, allPods)
-	status.Code = getStatusCode(&status.ReplicaCounts, autoscalingSpec.MinReplicas)
+	status.Code = getStatusCode(deployment, &status.ReplicaCounts, autoscalingSpec.MinReplicas)
 
 	return status, nil
 }
@@ -160,7 +153,98 @@ func addPodToReplicaCounts(pod *kcore.Pod, deployment *kapps.Deployment, counts
 	}
 }
 
-func getStatusCode(counts *status.ReplicaCounts, minReplicas int32) status.Code {
+// getReplicaStatuses describes each of the API's replicas, with the replicas of the API's latest version first
+func getReplicaStatuses(deployment *kapps.Deployment, pods []kcore.Pod) []status.ReplicaStatus {
+	var replicas []status.ReplicaStatus
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Labels["apiName"] != deployment.Labels["apiName"] {
+			continue
+		}
+
+		replica := status.ReplicaStatus{
+			Name:        pod.Name,
+			Updated:     isPodSpecLatest(deployment, pod),
+			Phase:       replicaPhase(pod),
+			ModelStatus: replicaModelStatus(pod),
+			Node:        pod.Spec.NodeName,
+			CreatedAt:   pod.CreationTimestamp.Time,
+		}
+
+		for _, containerStatus := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+			replica.Restarts += containerStatus.RestartCount
+			for _, terminated := range []*kcore.ContainerStateTerminated{containerStatus.LastTerminationState.Terminated, containerStatus.State.Terminated} {
+				if terminated == nil || terminated.ExitCode == 0 {
+					continue
+				}
+				if replica.LastTerminationTime == nil || terminated.FinishedAt.Time.After(*replica.LastTerminationTime) {
+					finishedAt := terminated.FinishedAt.Time
+					replica.LastTerminationTime = &finishedAt
+					replica.LastTerminationReason = terminated.Reason
+				}
+			}
+		}
+
+		replicas = append(replicas, replica)
+	}
+
+	sort.Slice(replicas, func(i, j int) bool {
+		if replicas[i].Updated != replicas[j].Updated {
+			return replicas[i].Updated
+		}
+		return replicas[i].Name < replicas[j].Name
+	})
+
+	return replicas
+}
+
+// replicaPhase corresponds to the replica counts (see addPodToReplicaCounts)
+func replicaPhase(pod *kcore.Pod) string {
+	if k8s.IsPodReady(pod) {
+		return "ready"
+	}
+
+	podStatus := k8s.GetPodStatus(pod)
+	switch podStatus {
+	case k8s.PodStatusPending:
+		if time.Since(pod.CreationTimestamp.Time) > _stalledPodTimeout {
+			return "stalled"
+		}
+	case k8s.PodStatusRunning:
+		return "initializing"
+	}
+	return strings.ToLower(string(podStatus))
+}
+
+// replicaModelStatus is inferred from the replica's containers: the downloader init container downloads the API's
+// models, and the API's containers become ready once they have loaded them
+func replicaModelStatus(pod *kcore.Pod) string {
+	if k8s.IsPodReady(pod) {
+		return "loaded"
+	}
+
+	for _, containerStatus := range pod.Status.InitContainerStatuses {
+		if containerStatus.Name == _downloaderInitContainerName && containerStatus.State.Running != nil {
+			return "downloading"
+		}
+	}
+
+	if len(pod.Status.ContainerStatuses) == 0 {
+		return ""
+	}
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.State.Running == nil {
+			return ""
+		}
+	}
+	return "loading"
+}
+
+func getStatusCode(deployment *kapps.Deployment, counts *status.ReplicaCounts, minReplicas int32) status.Code {
+	if isAPIPaused(deployment) {
+		return status.Paused
+	}
+
 	if counts.Updated.Ready >= counts.Requested {
 		return status.Live
 	}
@@ -0,0 +1,260 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/gorilla/websocket"
+	kcore "k8s.io/api/core/v1"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	_tailPodRefreshPeriod = 5 * time.Second
+	_tailMaxLineSize      = 1024 * 1024
+)
+
+var _logContainerNames = []string{_apiContainerName, _tfServingContainerName, _downloaderInitContainerName, _requestMonitorContainerName, _explainerContainerName}
+
+type TailOptions struct {
+	Containers []string       // defaults to all of the API's containers
+	Filter     *regexp.Regexp // only lines which match are written
+	Since      *time.Time
+	Until      *time.Time // if set, the logs which exist are written and the socket is closed; otherwise new logs are followed
+}
+
+func ValidateLogContainers(containerNames []string) error {
+	for _, containerName := range containerNames {
+		if !slices.HasString(_logContainerNames, containerName) {
+			return ErrorInvalidLogContainer(containerName, _logContainerNames)
+		}
+	}
+	return nil
+}
+
+// TailLogs writes the logs of all of the API's replicas to the socket, prefixing each line with the pod and container it came from
+func TailLogs(apiName string, opts TailOptions, socket *websocket.Conn) {
+	tailCancel := make(chan struct{})
+	defer close(tailCancel)
+	go tailPods(apiName, opts, tailCancel, socket)
+	pumpStdin(socket)
+	tailCancel <- struct{}{}
+}
+
+func tailPods(apiName string, opts TailOptions, tailCancel chan struct{}, socket *websocket.Conn) {
+	tailer := newLogTailer(opts, socket)
+	defer tailer.stop()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-tailCancel:
+			return
+		case <-timer.C:
+			deployment, err := getAPIDeployment(apiName)
+			if err != nil {
+				telemetry.Error(err)
+				tailer.writeAndClose("error: " + errors.Message(err))
+				continue
+			}
+			if deployment == nil {
+				tailer.writeAndClose(apiName + " is not deployed")
+				continue
+			}
+
+			k8sNamespace := config.K8sNamespace(deployment.Namespace)
+			pods, err := k8sNamespace.ListPodsByLabel("apiName", apiName)
+			if err != nil {
+				telemetry.Error(err)
+				tailer.writeAndClose("error: " + errors.Message(err))
+				continue
+			}
+
+			for i := range pods {
+				for _, containerName := range startedContainerNames(&pods[i]) {
+					if len(opts.Containers) == 0 || slices.HasString(opts.Containers, containerName) {
+						tailer.start(k8sNamespace, pods[i].Name, containerName)
+					}
+				}
+			}
+
+			if opts.Until != nil {
+				tailer.wait()
+				tailer.writeAndClose("")
+				continue
+			}
+
+			timer.Reset(_tailPodRefreshPeriod)
+		}
+	}
+}
+
+// the logs of containers which haven't started yet can't be requested
+func startedContainerNames(pod *kcore.Pod) []string {
+	var containerNames []string
+	for _, containerStatus := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		if containerStatus.State.Running != nil || containerStatus.State.Terminated != nil || containerStatus.LastTerminationState.Terminated != nil {
+			containerNames = append(containerNames, containerStatus.Name)
+		}
+	}
+	return containerNames
+}
+
+type logTailer struct {
+	opts      TailOptions
+	socket    *websocket.Conn
+	socketMux sync.Mutex
+	streamMux sync.Mutex
+	streams   map[string]io.ReadCloser // pod/container -> open log stream
+	lastTimes map[string]time.Time     // pod/container -> timestamp of the last line that was read
+	wg        sync.WaitGroup
+}
+
+func newLogTailer(opts TailOptions, socket *websocket.Conn) *logTailer {
+	return &logTailer{
+		opts:      opts,
+		socket:    socket,
+		streams:   map[string]io.ReadCloser{},
+		lastTimes: map[string]time.Time{},
+	}
+}
+
+// start is a no-op if the container is already being tailed; if its stream had ended (e.g. because the container restarted), it resumes after the last line that was read
+func (t *logTailer) start(k8sClient *k8s.Client, podName string, containerName string) {
+	key := podName + "/" + containerName
+
+	t.streamMux.Lock()
+	defer t.streamMux.Unlock()
+
+	if _, ok := t.streams[key]; ok {
+		return
+	}
+
+	logOpts := &kcore.PodLogOptions{
+		Container:  containerName,
+		Follow:     t.opts.Until == nil,
+		Timestamps: true,
+	}
+	if lastTime, ok := t.lastTimes[key]; ok {
+		logOpts.SinceTime = &kmeta.Time{Time: lastTime}
+	} else if t.opts.Since != nil {
+		logOpts.SinceTime = &kmeta.Time{Time: *t.opts.Since}
+	}
+
+	stream, err := k8sClient.StreamPodLogs(podName, logOpts)
+	if err != nil {
+		// the pod may have been deleted since it was listed
+		return
+	}
+
+	t.streams[key] = stream
+	t.wg.Add(1)
+	go t.read(key, podName, containerName, stream)
+}
+
+func (t *logTailer) read(key string, podName string, containerName string, stream io.ReadCloser) {
+	defer t.wg.Done()
+	defer func() {
+		t.streamMux.Lock()
+		delete(t.streams, key)
+		t.streamMux.Unlock()
+		stream.Close()
+	}()
+
+	t.streamMux.Lock()
+	lastTime, resumed := t.lastTimes[key]
+	t.streamMux.Unlock()
+
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(nil, _tailMaxLineSize)
+
+	for scanner.Scan() {
+		timestamp, line := splitLogTimestamp(scanner.Text())
+		if timestamp != nil {
+			// SinceTime only has second precision, so lines which were already written may be read again after resuming
+			if resumed && !timestamp.After(lastTime) {
+				continue
+			}
+			if t.opts.Until != nil && timestamp.After(*t.opts.Until) {
+				return
+			}
+			t.streamMux.Lock()
+			t.lastTimes[key] = *timestamp
+			t.streamMux.Unlock()
+		}
+
+		if t.opts.Filter != nil && !t.opts.Filter.MatchString(line) {
+			continue
+		}
+
+		t.write(fmt.Sprintf("%s %s: %s", podName, containerName, line))
+	}
+}
+
+// lines are prefixed with an RFC 3339 timestamp when PodLogOptions.Timestamps is set
+func splitLogTimestamp(line string) (*time.Time, string) {
+	split := strings.SplitN(line, " ", 2)
+	if len(split) != 2 {
+		return nil, line
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, split[0])
+	if err != nil {
+		return nil, line
+	}
+	return &timestamp, split[1]
+}
+
+func (t *logTailer) wait() {
+	t.wg.Wait()
+}
+
+func (t *logTailer) stop() {
+	t.streamMux.Lock()
+	defer t.streamMux.Unlock()
+	for _, stream := range t.streams {
+		stream.Close()
+	}
+}
+
+func (t *logTailer) write(message string) {
+	t.socketMux.Lock()
+	defer t.socketMux.Unlock()
+	writeString(t.socket, message)
+}
+
+func (t *logTailer) writeAndClose(message string) {
+	t.socketMux.Lock()
+	defer t.socketMux.Unlock()
+	if message != "" {
+		writeString(t.socket, message)
+	}
+	closeSocket(t.socket)
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"strconv"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	kapps "k8s.io/api/apps/v1"
+)
+
+const _apiOwnersKind = "api_owners"
+
+// Principal is the IAM identity which made a request to the operator
+type Principal struct {
+	ARN  string
+	Team *clusterconfig.Team // nil if the principal is an admin who does not belong to a team
+}
+
+type apiOwner struct {
+	Team string `json:"team"`
+	ARN  string `json:"arn"`
+}
+
+type teamUsage struct {
+	apis     int64
+	replicas int64
+	gpus     int64
+}
+
+// GetPrincipal returns nil if teams are not configured (in which case all requests are unrestricted)
+func GetPrincipal(arn string) (*Principal, error) {
+	if !config.Cluster.IsMultiTenant() {
+		return nil, nil
+	}
+
+	principal := &Principal{
+		ARN:  arn,
+		Team: config.Cluster.GetTeam(arn),
+	}
+
+	if principal.Team == nil && !principal.IsAdmin() {
+		return nil, ErrorNotATeamMember(arn)
+	}
+
+	return principal, nil
+}
+
+func (principal *Principal) IsAdmin() bool {
+	if principal == nil {
+		return true
+	}
+	return config.Cluster.IsAdmin(principal.ARN)
+}
+
+// AuthorizeAPI returns an error if the principal is not allowed to modify or delete the API
+func AuthorizeAPI(principal *Principal, apiName string) error {
+	if principal.IsAdmin() {
+		return nil
+	}
+
+	var owner apiOwner
+	exists, err := config.Metadata.Get(_apiOwnersKind, apiName, &owner)
+	if err != nil {
+		return err
+	}
+
+	if exists && owner.Team == principal.Team.Name {
+		return nil
+	}
+
+	// the owner may be stale if the API was deleted without going through the operator
+	isDeployed, err := IsAPIDeployed(apiName)
+	if err != nil {
+		return err
+	}
+	if !isDeployed {
+		return nil
+	}
+
+	// APIs which were deployed before teams were configured can only be managed by admins
+	return ErrorAPIForbidden(apiName, owner.Team)
+}
+
+// SetAPIOwner records the principal's team as the owner of the API (AuthorizeAPI must be called first)
+func SetAPIOwner(principal *Principal, apiName string) error {
+	if principal == nil || principal.Team == nil {
+		return nil
+	}
+
+	var owner apiOwner
+	exists, err := config.Metadata.Get(_apiOwnersKind, apiName, &owner)
+	if err != nil {
+		return err
+	}
+	// admins don't take ownership of other teams' APIs
+	if exists && (owner.Team == principal.Team.Name || principal.IsAdmin()) {
+		return nil
+	}
+
+	return config.Metadata.Put(_apiOwnersKind, apiName, apiOwner{
+		Team: principal.Team.Name,
+		ARN:  principal.ARN,
+	})
+}
+
+// deleteAPIOwner is best effort; failures are printed but don't affect the deletion
+func deleteAPIOwner(apiName string) {
+	if err := config.Metadata.Delete(_apiOwnersKind, apiName); err != nil {
+		errors.PrintError(err, "failed to delete api owner")
+	}
+}
+
+// ValidateTeamQuota returns an error if deploying the APIs would cause the principal's team to exceed its limits
+func ValidateTeamQuota(principal *Principal, apiConfigs []userconfig.API) error {
+	if principal == nil || principal.Team == nil {
+		return nil
+	}
+	team := principal.Team
+
+	if team.MaxAPIs == nil && team.MaxReplicas == nil && team.MaxGPUs == nil {
+		return nil
+	}
+
+	apiNames := strset.New()
+	for _, apiConfig := range apiConfigs {
+		apiNames.Add(apiConfig.Name)
+	}
+
+	usage, err := getTeamUsage(team.Name, apiNames)
+	if err != nil {
+		return err
+	}
+
+	for _, apiConfig := range apiConfigs {
+		maxReplicas := int64(apiConfig.Autoscaling.MaxReplicas)
+		usage.apis++
+		usage.replicas += maxReplicas
+		usage.gpus += apiConfig.Compute.GPU * maxReplicas
+	}
+
+	if team.MaxAPIs != nil && usage.apis > *team.MaxAPIs {
+		return ErrorTeamQuotaExceeded(team.Name, clusterconfig.MaxAPIsKey, usage.apis, *team.MaxAPIs)
+	}
+	if team.MaxReplicas != nil && usage.replicas > *team.MaxReplicas {
+		return ErrorTeamQuotaExceeded(team.Name, clusterconfig.MaxReplicasKey, usage.replicas, *team.MaxReplicas)
+	}
+	if team.MaxGPUs != nil && usage.gpus > *team.MaxGPUs {
+		return ErrorTeamQuotaExceeded(team.Name, clusterconfig.MaxGPUsKey, usage.gpus, *team.MaxGPUs)
+	}
+
+	return nil
+}
+
+// usage is based on each API's max replicas, since that is what the team could consume; APIs in excludedAPIs are not counted
+func getTeamUsage(teamName string, excludedAPIs strset.Set) (teamUsage, error) {
+	var usage teamUsage
+
+	items, err := config.Metadata.List(_apiOwnersKind, "")
+	if err != nil {
+		return usage, err
+	}
+
+	for _, item := range items {
+		var owner apiOwner
+		if err := item.Unmarshal(&owner); err != nil {
+			return usage, err
+		}
+		if owner.Team != teamName || excludedAPIs.Has(item.Key) {
+			continue
+		}
+
+		deployment, err := getAPIDeployment(item.Key)
+		if err != nil {
+			return usage, err
+		}
+		if deployment == nil {
+			continue
+		}
+
+		autoscaling, err := userconfig.AutoscalingFromAnnotations(deployment)
+		if err != nil {
+			return usage, err
+		}
+		maxReplicas := int64(autoscaling.MaxReplicas)
+
+		usage.apis++
+		usage.replicas += maxReplicas
+		usage.gpus += deploymentGPUs(deployment) * maxReplicas
+	}
+
+	return usage, nil
+}
+
+// returns the number of GPUs requested by each replica (including the workers of multi-node APIs, whose pods request
+// as many GPUs as their leader)
+func deploymentGPUs(deployment *kapps.Deployment) int64 {
+	var gpus int64
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if gpu, ok := container.Resources.Requests[_nvidiaGPUResource]; ok {
+			gpus += gpu.Value()
+		}
+	}
+	if nodes, err := strconv.ParseInt(deployment.Labels[_parallelNodesLabelKey], 10, 64); err == nil {
+		gpus *= nodes
+	}
+	return gpus
+}
@@ -19,16 +19,17 @@ package operator
 import (
 	"fmt"
 
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/files"
-	"github.com/cortexlabs/cortex/pkg/lib/k8s"
 	"github.com/cortexlabs/cortex/pkg/lib/parallel"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/operator/config"
-	"github.com/cortexlabs/cortex/pkg/types"
+	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
 	"github.com/cortexlabs/cortex/pkg/types/spec"
 	"github.com/cortexlabs/cortex/pkg/types/userconfig"
-	istioclientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	kcore "k8s.io/api/core/v1"
 	kresource "k8s.io/apimachinery/pkg/api/resource"
 )
 
@@ -62,12 +63,23 @@ func (projectFiles ProjectFiles) GetConfigFilePath() string {
 	return projectFiles.ConfigFilePath
 }
 
+// DeployEnvironment returns the cluster's environment, which selects the APIs' overrides and defines their variables
+func DeployEnvironment() spec.Environment {
+	environment := spec.Environment{
+		Variables: config.Cluster.Variables,
+	}
+	if config.Cluster.Environment != nil {
+		environment.Name = *config.Cluster.Environment
+	}
+	return environment
+}
+
 func ValidateClusterAPIs(apis []userconfig.API, projectFiles spec.ProjectFiles) error {
 	if len(apis) == 0 {
 		return spec.ErrorNoAPIs()
 	}
 
-	virtualServices, maxMem, err := getValidationK8sResources()
+	routes, maxMem, err := getValidationK8sResources()
 	if err != nil {
 		return err
 	}
@@ -76,10 +88,13 @@ func ValidateClusterAPIs(apis []userconfig.API, projectFiles spec.ProjectFiles)
 
 	for i := range apis {
 		api := &apis[i]
-		if err := spec.ValidateAPI(api, projectFiles, types.AWSProviderType, config.AWS); err != nil {
+		if err := spec.ValidateAPI(api, projectFiles, config.Cluster.Provider, config.AWS); err != nil {
 			return err
 		}
-		if err := validateK8s(api, virtualServices, maxMem); err != nil {
+		if err := validateGCPAPI(api); err != nil {
+			return errors.Wrap(err, api.Identify())
+		}
+		if err := validateK8s(api, routes, maxMem); err != nil {
 			return err
 		}
 
@@ -102,64 +117,438 @@ func ValidateClusterAPIs(apis []userconfig.API, projectFiles spec.ProjectFiles)
 	return nil
 }
 
-func validateK8s(api *userconfig.API, virtualServices []istioclientnetworking.VirtualService, maxMem *kresource.Quantity) error {
-	if err := validateK8sCompute(api.Compute, maxMem); err != nil {
+func validateK8s(api *userconfig.API, routes []apiRoute, maxMem *kresource.Quantity) error {
+	if err := validateK8sNodeGroup(api.Compute); err != nil {
+		return errors.Wrap(err, api.Identify(), userconfig.ComputeKey, userconfig.NodeGroupKey)
+	}
+
+	if err := validateK8sCompute(api, maxMem); err != nil {
+		return errors.Wrap(err, api.Identify(), userconfig.ComputeKey)
+	}
+
+	if err := validateK8sSpot(api.Compute); err != nil {
 		return errors.Wrap(err, api.Identify(), userconfig.ComputeKey)
 	}
 
-	if err := validateEndpointCollisions(api, virtualServices); err != nil {
+	if len(api.Predictor.InitContainers) > 0 {
+		if err := validateK8sInitContainers(api, maxMem); err != nil {
+			return errors.Wrap(err, api.Identify(), userconfig.PredictorKey, userconfig.InitContainersKey)
+		}
+	}
+
+	if api.Predictor.EnvFrom != nil {
+		if err := validateK8sEnvFrom(api.Predictor.EnvFrom, api.Namespace); err != nil {
+			return errors.Wrap(err, api.Identify(), userconfig.PredictorKey, userconfig.EnvFromKey)
+		}
+	}
+
+	if !config.Cluster.HasMetricSink(clusterconfig.CloudWatchMetricSink) {
+		if err := validateWithoutCloudWatchMetrics(api); err != nil {
+			return errors.Wrap(err, api.Identify())
+		}
+	}
+
+	if err := validateAutoscalingWindow(api.Autoscaling); err != nil {
+		return errors.Wrap(err, api.Identify(), userconfig.AutoscalingKey)
+	}
+
+	if err := validateK8sCapacity(api, maxMem); err != nil {
+		return errors.Wrap(err, api.Identify(), userconfig.AutoscalingKey, userconfig.MinReplicasKey)
+	}
+
+	if err := validateEndpointCollisions(api, routes); err != nil {
 		return err
 	}
 
+	if api.Experiment != nil {
+		if err := validateK8sExperimentVariants(api.Experiment, api.Namespace); err != nil {
+			return errors.Wrap(err, api.Identify(), userconfig.ExperimentKey, userconfig.VariantsKey)
+		}
+	}
+
+	if api.FeatureStore != nil && api.FeatureStore.Secret != nil {
+		if err := validateK8sFeatureStoreSecret(*api.FeatureStore.Secret, api.Namespace); err != nil {
+			return errors.Wrap(err, api.Identify(), userconfig.FeatureStoreKey, userconfig.FeatureStoreSecretKey)
+		}
+	}
+
+	if api.Compute.ScratchVolume != nil {
+		if err := validateK8sScratchVolume(api); err != nil {
+			return errors.Wrap(err, api.Identify(), userconfig.ComputeKey, userconfig.ScratchVolumeKey)
+		}
+	}
+
+	if !isIstioNetworking() {
+		if err := validateIngressNetworking(api); err != nil {
+			return errors.Wrap(err, api.Identify())
+		}
+	}
+
+	if api.Networking.Mesh != nil {
+		if err := validateMesh(api.Networking.Mesh); err != nil {
+			return errors.Wrap(err, api.Identify(), userconfig.NetworkingKey, userconfig.MeshKey)
+		}
+	}
+
+	return nil
+}
+
+// SLOs, experiments, idle timeouts, and overload fallbacks are evaluated with the request metrics in cloudwatch
+func validateWithoutCloudWatchMetrics(api *userconfig.API) error {
+	if api.SLO != nil {
+		return errors.Wrap(ErrorRequiresCloudWatchMetricSink(config.Cluster.MetricSinks), userconfig.SLOKey)
+	}
+	if api.Experiment != nil {
+		return errors.Wrap(ErrorRequiresCloudWatchMetricSink(config.Cluster.MetricSinks), userconfig.ExperimentKey)
+	}
+	if api.Autoscaling != nil && api.Autoscaling.IdleTimeout != nil {
+		return errors.Wrap(ErrorRequiresCloudWatchMetricSink(config.Cluster.MetricSinks), userconfig.AutoscalingKey, userconfig.IdleTimeoutKey)
+	}
+	if api.Networking != nil && api.Networking.FallbackOnOverload {
+		return errors.Wrap(ErrorRequiresCloudWatchMetricSink(config.Cluster.MetricSinks), userconfig.NetworkingKey, userconfig.FallbackOnOverloadKey)
+	}
+	return nil
+}
+
+// the window is validated to be a multiple of the autoscaling tick interval, but the in-flight requests metric is only
+// published once per flush interval
+func validateAutoscalingWindow(autoscaling *userconfig.Autoscaling) error {
+	if autoscaling == nil {
+		return nil
+	}
+	flushInterval := requestMonitorFlushInterval()
+	if autoscaling.Window%flushInterval != 0 {
+		return ErrorWindowNotMultipleOfFlush(autoscaling.Window, flushInterval)
+	}
 	return nil
 }
 
-func validateK8sCompute(compute *userconfig.Compute, maxMem *kresource.Quantity) error {
-	maxMem.Sub(_cortexMemReserve)
+// with api_mtls, the APIs gateway only connects to API pods with mutual TLS, so every API must keep its sidecar
+func validateMesh(mesh *userconfig.Mesh) error {
+	if mesh.MTLS != nil && *mesh.MTLS && !mesh.Enabled {
+		return ErrorMTLSRequiresSidecar()
+	}
+	if config.Cluster.APIMTLS {
+		if !mesh.Enabled {
+			return ErrorClusterRequiresMTLS(userconfig.EnabledKey)
+		}
+		if mesh.MTLS != nil && !*mesh.MTLS {
+			return ErrorClusterRequiresMTLS(userconfig.MTLSKey)
+		}
+	}
+	return nil
+}
 
-	maxCPU := config.Cluster.InstanceMetadata.CPU
+// returns the node group which the API targets, or nil if it runs on the cluster's default worker nodes
+func targetNodeGroup(compute *userconfig.Compute) *clusterconfig.NodeGroup {
+	if compute.NodeGroup == nil {
+		return nil
+	}
+	return config.Cluster.GetNodeGroup(*compute.NodeGroup)
+}
+
+// returns the instance type of the nodes which the API can be scheduled on (instances in the spot_config's
+// instance_distribution are validated to be at least as large as the primary instance type)
+func targetInstanceType(compute *userconfig.Compute) string {
+	if nodeGroup := targetNodeGroup(compute); nodeGroup != nil {
+		return nodeGroup.InstanceType
+	}
+	return *config.Cluster.InstanceType
+}
+
+// returns the CPU, memory, GPUs, and Inferentia chips which are available to APIs on a single instance
+// (maxMem is the memory capacity of the cluster's default worker nodes, and is not used for APIs which target a node group)
+func instanceCapacity(compute *userconfig.Compute, maxMem *kresource.Quantity) (kresource.Quantity, kresource.Quantity, int64, int64) {
+	instanceMetadata := config.Cluster.InstanceMetadata
+	if nodeGroup := targetNodeGroup(compute); nodeGroup != nil {
+		instanceMetadata = aws.InstanceMetadatas[*config.Cluster.Region][nodeGroup.InstanceType]
+		maxMem = &instanceMetadata.Memory
+	}
+
+	maxMemAvailable := maxMem.DeepCopy()
+	maxMemAvailable.Sub(_cortexMemReserve)
+
+	maxCPU := instanceMetadata.CPU.DeepCopy()
 	maxCPU.Sub(_cortexCPUReserve)
 
-	maxGPU := config.Cluster.InstanceMetadata.GPU
+	maxGPU := instanceMetadata.GPU
 	if maxGPU > 0 {
 		// Reserve resources for nvidia device plugin daemonset
 		maxCPU.Sub(_nvidiaCPUReserve)
-		maxMem.Sub(_nvidiaMemReserve)
+		maxMemAvailable.Sub(_nvidiaMemReserve)
 	}
 
-	maxInf := config.Cluster.InstanceMetadata.Inf
+	maxInf := instanceMetadata.Inf
 	if maxInf > 0 {
 		// Reserve resources for inferentia device plugin daemonset
 		maxCPU.Sub(_inferentiaCPUReserve)
-		maxMem.Sub(_inferentiaMemReserve)
+		maxMemAvailable.Sub(_inferentiaMemReserve)
+	}
+
+	return maxCPU, maxMemAvailable, maxGPU, maxInf
+}
+
+// returns the CPU, memory, GPUs, and Inferentia chips which are available to the API's compute request on a single
+// instance, i.e. excluding the resources of the containers which cortex runs alongside the API's containers
+func apiCapacity(api *userconfig.API, maxMem *kresource.Quantity) (kresource.Quantity, kresource.Quantity, int64, int64) {
+	maxCPU, maxMemAvailable, maxGPU, maxInf := instanceCapacity(api.Compute, maxMem)
+
+	sidecarRequests := sidecarRequests(api)
+	maxCPU.Sub(sidecarRequests[kcore.ResourceCPU])
+	maxMemAvailable.Sub(sidecarRequests[kcore.ResourceMemory])
+
+	return maxCPU, maxMemAvailable, maxGPU, maxInf
+}
+
+// validateK8sCompute rejects APIs whose replicas can't fit on any of the instances which they can be scheduled on; if
+// the API doesn't target a node group, the error lists the node groups whose instances are large enough
+func validateK8sCompute(api *userconfig.API, maxMem *kresource.Quantity) error {
+	err := validateK8sComputeFits(api, maxMem)
+	if err == nil || api.Compute.NodeGroup != nil {
+		return err
+	}
+
+	var nodeGroupNames []string
+	for _, nodeGroup := range config.Cluster.NodeGroups {
+		compute := *api.Compute
+		compute.NodeGroup = &nodeGroup.Name
+		nodeGroupAPI := *api
+		nodeGroupAPI.Compute = &compute
+		if validateK8sComputeFits(&nodeGroupAPI, maxMem) == nil {
+			nodeGroupNames = append(nodeGroupNames, nodeGroup.Name)
+		}
+	}
+	if len(nodeGroupNames) == 0 {
+		return err
 	}
 
+	return errors.Append(err, fmt.Sprintf(" (the instances in the %s %s are large enough; set %s to deploy the api to them)", s.UserStrsOr(nodeGroupNames), s.PluralS("node group", len(nodeGroupNames)), userconfig.NodeGroupKey))
+}
+
+func validateK8sComputeFits(api *userconfig.API, maxMem *kresource.Quantity) error {
+	compute := api.Compute
+	instanceType := targetInstanceType(compute)
+	maxCPU, maxMemAvailable, maxGPU, maxInf := apiCapacity(api, maxMem)
+
 	if compute.CPU != nil && maxCPU.Cmp(compute.CPU.Quantity) < 0 {
-		return ErrorNoAvailableNodeComputeLimit("CPU", compute.CPU.String(), maxCPU.String())
+		return ErrorNoAvailableNodeComputeLimit("CPU", compute.CPU.String(), maxCPU.String(), instanceType)
 	}
-	if compute.Mem != nil && maxMem.Cmp(compute.Mem.Quantity) < 0 {
-		return ErrorNoAvailableNodeComputeLimit("memory", compute.Mem.String(), maxMem.String())
+	if compute.Mem != nil && maxMemAvailable.Cmp(compute.Mem.Quantity) < 0 {
+		return ErrorNoAvailableNodeComputeLimit("memory", compute.Mem.String(), maxMemAvailable.String(), instanceType)
 	}
 	if compute.GPU > maxGPU {
-		return ErrorNoAvailableNodeComputeLimit("GPU", fmt.Sprintf("%d", compute.GPU), fmt.Sprintf("%d", maxGPU))
+		return ErrorNoAvailableNodeComputeLimit("GPU", fmt.Sprintf("%d", compute.GPU), fmt.Sprintf("%d", maxGPU), instanceType)
 	}
 	if compute.Inf > maxInf {
-		return ErrorNoAvailableNodeComputeLimit("Inf", fmt.Sprintf("%d", compute.Inf), fmt.Sprintf("%d", maxInf))
+		return ErrorNoAvailableNodeComputeLimit("Inf", fmt.Sprintf("%d", compute.Inf), fmt.Sprintf("%d", maxInf), instanceType)
+	}
+	// the instances' volume also holds the container images, so this only catches requests which can never be satisfied
+	maxEphemeralStorage := kresource.MustParse(fmt.Sprintf("%dGi", config.Cluster.InstanceVolumeSize))
+	if compute.EphemeralStorage != nil && maxEphemeralStorage.Cmp(compute.EphemeralStorage.Quantity) < 0 {
+		return ErrorNoAvailableNodeComputeLimit("ephemeral storage", compute.EphemeralStorage.String(), maxEphemeralStorage.String(), instanceType)
 	}
 	return nil
 }
 
-func validateEndpointCollisions(api *userconfig.API, virtualServices []istioclientnetworking.VirtualService) error {
-	for _, virtualService := range virtualServices {
-		gateways := k8s.ExtractVirtualServiceGateways(&virtualService)
-		if !gateways.Has("apis-gateway") {
-			continue
+// the init containers share the pod with cortex's containers, so they can't use their names
+var _reservedContainerNames = strset.New(
+	_apiContainerName,
+	_tfServingContainerName,
+	_llmServerContainerName,
+	_downloaderInitContainerName,
+	_requestMonitorContainerName,
+	_featureStoreCacheContainerName,
+	_explainerContainerName,
+	_neuronRTDContainerName,
+	_neuronMonitorContainerName,
+)
+
+func validateK8sInitContainers(api *userconfig.API, maxMem *kresource.Quantity) error {
+	maxCPU, maxMemAvailable, _, _ := instanceCapacity(api.Compute, maxMem)
+	instanceType := targetInstanceType(api.Compute)
+
+	for i, initContainer := range api.Predictor.InitContainers {
+		if _reservedContainerNames.Has(initContainer.Name) {
+			return errors.Wrap(ErrorReservedContainerName(initContainer.Name), s.Index(i), userconfig.NameKey)
+		}
+		if initContainer.CPU != nil && maxCPU.Cmp(initContainer.CPU.Quantity) < 0 {
+			return errors.Wrap(ErrorNoAvailableNodeComputeLimit("CPU", initContainer.CPU.String(), maxCPU.String(), instanceType), s.Index(i), userconfig.CPUKey)
+		}
+		if initContainer.Mem != nil && maxMemAvailable.Cmp(initContainer.Mem.Quantity) < 0 {
+			return errors.Wrap(ErrorNoAvailableNodeComputeLimit("memory", initContainer.Mem.String(), maxMemAvailable.String(), instanceType), s.Index(i), userconfig.MemKey)
+		}
+	}
+
+	return nil
+}
+
+// variants don't need to be deployed yet (e.g. if they are deployed together with the experiment's API), but their
+// services are addressed by name, so they must be in the API's namespace
+func validateK8sExperimentVariants(experiment *userconfig.Experiment, namespace string) error {
+	for _, variant := range experiment.Variants {
+		deployment, err := getAPIDeployment(variant.API)
+		if err != nil {
+			return err
+		}
+		if deployment != nil && deployment.Namespace != namespace {
+			return ErrorExperimentVariantNamespace(variant.API, deployment.Namespace, namespace)
+		}
+	}
+	return nil
+}
+
+// validateK8sEnvFrom ensures that the config maps and secrets which the API's containers read environment variables from
+// exist (otherwise the API's pods would not be able to start)
+func validateK8sEnvFrom(envFrom *userconfig.EnvFrom, namespace string) error {
+	k8sNamespace := config.K8sNamespace(namespace)
+
+	for _, configMapName := range envFrom.ConfigMaps {
+		configMap, err := k8sNamespace.GetConfigMap(configMapName)
+		if err != nil {
+			return err
+		}
+		if configMap == nil {
+			return errors.Wrap(ErrorEnvSourceNotFound("config map", configMapName, namespace), userconfig.ConfigMapsKey)
+		}
+	}
+
+	for _, secretName := range envFrom.Secrets {
+		secret, err := k8sNamespace.GetSecret(secretName)
+		if err != nil {
+			return err
+		}
+		if secret == nil {
+			return errors.Wrap(ErrorEnvSourceNotFound("secret", secretName, namespace), userconfig.SecretsKey)
+		}
+	}
+
+	return nil
+}
+
+// the feature store's password is read from the secret's key when the API's containers start, so a missing key would
+// prevent the API's pods from starting
+func validateK8sFeatureStoreSecret(secretName string, namespace string) error {
+	secret, err := config.K8sNamespace(namespace).GetSecret(secretName)
+	if err != nil {
+		return err
+	}
+	if secret == nil {
+		return ErrorEnvSourceNotFound("secret", secretName, namespace)
+	}
+	if _, ok := secret.Data[_featureStorePasswordSecretKey]; !ok {
+		return ErrorSecretKeyNotFound(secretName, _featureStorePasswordSecretKey, namespace)
+	}
+	return nil
+}
+
+// validateK8sScratchVolume ensures that the API's existing scratch volume (if any) can be updated to match its configuration
+func validateK8sScratchVolume(api *userconfig.API) error {
+	prevClaim, err := config.K8sNamespace(api.Namespace).GetPVC(scratchVolumeClaimName(api.Name))
+	if err != nil {
+		return err
+	}
+	if prevClaim == nil {
+		return nil
+	}
+
+	scratchVolume := api.Compute.ScratchVolume
+
+	// if the storage class isn't specified, the claim's storage class is set to the cluster's default when it's created
+	if scratchVolume.StorageClass != nil && prevClaim.Spec.StorageClassName != nil && *scratchVolume.StorageClass != *prevClaim.Spec.StorageClassName {
+		return errors.Wrap(ErrorScratchVolumeFieldChanged(api.Name, *prevClaim.Spec.StorageClassName, *scratchVolume.StorageClass), userconfig.StorageClassKey)
+	}
+
+	accessMode := scratchVolumeAccessMode(scratchVolume.AccessMode)
+	if len(prevClaim.Spec.AccessModes) > 0 && prevClaim.Spec.AccessModes[0] != accessMode {
+		return errors.Wrap(ErrorScratchVolumeFieldChanged(api.Name, string(prevClaim.Spec.AccessModes[0]), string(accessMode)), userconfig.AccessModeKey)
+	}
+
+	prevSize := prevClaim.Spec.Resources.Requests[kcore.ResourceStorage]
+	if scratchVolume.Size.Cmp(prevSize) < 0 {
+		return errors.Wrap(ErrorScratchVolumeSizeDecreased(api.Name, prevSize, scratchVolume.Size.Quantity), userconfig.SizeKey)
+	}
+
+	return nil
+}
+
+// validateK8sSpot ensures that the cluster has a node group on which the API can be scheduled
+func validateK8sSpot(compute *userconfig.Compute) error {
+	if compute.Spot == nil {
+		return nil
+	}
+
+	isSpotCluster := config.Cluster.Spot != nil && *config.Cluster.Spot
+	hasOnDemandBackup := isSpotCluster && config.Cluster.SpotConfig != nil && config.Cluster.SpotConfig.OnDemandBackup != nil && *config.Cluster.SpotConfig.OnDemandBackup
+
+	if *compute.Spot {
+		if !isSpotCluster {
+			return errors.Wrap(ErrorSpotNotEnabled(), userconfig.SpotKey)
+		}
+		if compute.OnDemandFallback && !hasOnDemandBackup {
+			return errors.Wrap(ErrorNoOnDemandNodeGroup(), userconfig.OnDemandFallbackKey)
 		}
+		return nil
+	}
+
+	if isSpotCluster && !hasOnDemandBackup {
+		return errors.Wrap(ErrorNoOnDemandNodeGroup(), userconfig.SpotKey)
+	}
+
+	return nil
+}
+
+// validateK8sNodeGroup ensures that the node group which the API targets exists in the cluster
+func validateK8sNodeGroup(compute *userconfig.Compute) error {
+	if compute.NodeGroup == nil || targetNodeGroup(compute) != nil {
+		return nil
+	}
+
+	nodeGroupNames := make([]string, len(config.Cluster.NodeGroups))
+	for i, nodeGroup := range config.Cluster.NodeGroups {
+		nodeGroupNames[i] = nodeGroup.Name
+	}
+	return ErrorNodeGroupNotFound(*compute.NodeGroup, nodeGroupNames)
+}
+
+// validateK8sCapacity rejects APIs whose minimum number of replicas couldn't be scheduled even if the cluster was
+// scaled up to its maximum number of instances and no other APIs were running
+func validateK8sCapacity(api *userconfig.API, maxMem *kresource.Quantity) error {
+	if config.Cluster.MaxInstances == nil {
+		return nil
+	}
+
+	maxInstances := *config.Cluster.MaxInstances
+	if nodeGroup := targetNodeGroup(api.Compute); nodeGroup != nil {
+		maxInstances = nodeGroup.MaxInstances
+	}
+
+	replicasPerInstance := replicasPerInstance(resourceFits(api, maxMem))
+	if replicasPerInstance == -1 {
+		return nil
+	}
+
+	maxReplicas := replicasPerInstance * maxInstances
+	if api.Compute.Parallelism.IsMultiNode() {
+		// each replica of a multi-node API is a group of pods
+		maxReplicas /= api.Compute.Parallelism.Nodes
+	}
+	if int64(api.Autoscaling.MinReplicas) > maxReplicas {
+		return ErrorInsufficientClusterCapacity(api.Autoscaling.MinReplicas, maxReplicas, maxInstances)
+	}
+
+	return nil
+}
 
-		endpoints := k8s.ExtractVirtualServiceEndpoints(&virtualService)
-		for endpoint := range endpoints {
-			if s.EnsureSuffix(endpoint, "/") == s.EnsureSuffix(*api.Endpoint, "/") && virtualService.GetLabels()["apiName"] != api.Name {
-				return errors.Wrap(spec.ErrorDuplicateEndpoint(virtualService.GetLabels()["apiName"]), api.Identify(), userconfig.EndpointKey, endpoint)
+func validateEndpointCollisions(api *userconfig.API, routes []apiRoute) error {
+	for _, route := range routes {
+		if route.apiName == api.Name {
+			continue
+		}
+		for _, routeEndpoint := range route.endpoints {
+			for _, endpoint := range api.Endpoints() {
+				if s.EnsureSuffix(routeEndpoint, "/") == s.EnsureSuffix(endpoint, "/") {
+					return errors.Wrap(spec.ErrorDuplicateEndpoint(route.apiName), api.Identify(), userconfig.EndpointKey, routeEndpoint)
+				}
 			}
 		}
 	}
@@ -167,11 +556,55 @@ func validateEndpointCollisions(api *userconfig.API, virtualServices []istioclie
 	return nil
 }
 
+// these features are implemented with istio resources (envoy filters and destination rules); the API gateway's VPC link
+// (used when the API load balancer is internal) is attached to istio's load balancer
+func validateIngressNetworking(api *userconfig.API) error {
+	if api.Networking.APIGateway == userconfig.PublicAPIGatewayType && config.Cluster.APILoadBalancerScheme == clusterconfig.InternalLoadBalancerScheme {
+		return errors.Wrap(ErrorRequiresIstioNetworking(userconfig.APIGatewayKey+": "+api.Networking.APIGateway.String()), userconfig.NetworkingKey)
+	}
+	if api.Networking.FallbackAPI != nil {
+		return errors.Wrap(ErrorRequiresIstioNetworking(userconfig.FallbackAPIKey), userconfig.NetworkingKey)
+	}
+	if api.Networking.FallbackResponse != nil {
+		return errors.Wrap(ErrorRequiresIstioNetworking(userconfig.FallbackResponseKey), userconfig.NetworkingKey)
+	}
+	if api.Networking.MaintenanceMessage != nil {
+		return errors.Wrap(ErrorRequiresIstioNetworking(userconfig.MaintenanceMessageKey), userconfig.NetworkingKey)
+	}
+	if api.Networking.VersionPinning {
+		return errors.Wrap(ErrorRequiresIstioNetworking(userconfig.VersionPinningKey), userconfig.NetworkingKey)
+	}
+	if api.Experiment != nil {
+		return ErrorRequiresIstioNetworking(userconfig.ExperimentKey)
+	}
+	if api.Networking.Compression == userconfig.GzipCompressionType {
+		return errors.Wrap(ErrorRequiresIstioNetworking(userconfig.CompressionKey+": "+api.Networking.Compression.String()), userconfig.NetworkingKey)
+	}
+	if api.Autoscaling.OverloadBehavior == userconfig.ShedOverloadBehaviorType {
+		return errors.Wrap(ErrorRequiresIstioNetworking(userconfig.OverloadBehaviorKey+": "+api.Autoscaling.OverloadBehavior.String()), userconfig.AutoscalingKey)
+	}
+	if api.Networking.Mesh != nil {
+		return errors.Wrap(ErrorRequiresIstioNetworking(userconfig.MeshKey), userconfig.NetworkingKey)
+	}
+	if api.Networking.CORS != nil {
+		return errors.Wrap(ErrorRequiresIstioNetworking(userconfig.CORSKey), userconfig.NetworkingKey)
+	}
+	if api.Networking.Headers != nil {
+		return errors.Wrap(ErrorRequiresIstioNetworking(userconfig.HeadersKey), userconfig.NetworkingKey)
+	}
+	if len(api.Networking.AdditionalEndpoints) > 0 {
+		return errors.Wrap(ErrorRequiresIstioNetworking(userconfig.AdditionalEndpointsKey), userconfig.NetworkingKey)
+	}
+	return nil
+}
+
 func findDuplicateEndpoints(apis []userconfig.API) []userconfig.API {
 	endpoints := make(map[string][]userconfig.API)
 
 	for _, api := range apis {
-		endpoints[*api.Endpoint] = append(endpoints[*api.Endpoint], api)
+		for _, endpoint := range api.Endpoints() {
+			endpoints[endpoint] = append(endpoints[endpoint], api)
+		}
 	}
 
 	for endpoint := range endpoints {
@@ -183,14 +616,14 @@ func findDuplicateEndpoints(apis []userconfig.API) []userconfig.API {
 	return nil
 }
 
-func getValidationK8sResources() ([]istioclientnetworking.VirtualService, *kresource.Quantity, error) {
-	var virtualServices []istioclientnetworking.VirtualService
+func getValidationK8sResources() ([]apiRoute, *kresource.Quantity, error) {
+	var routes []apiRoute
 	var maxMem *kresource.Quantity
 
 	err := parallel.RunFirstErr(
 		func() error {
 			var err error
-			virtualServices, err = config.K8s.ListVirtualServices(nil)
+			routes, err = router().listRoutes()
 			return err
 		},
 		func() error {
@@ -200,5 +633,5 @@ func getValidationK8sResources() ([]istioclientnetworking.VirtualService, *kreso
 		},
 	)
 
-	return virtualServices, maxMem, err
+	return routes, maxMem, err
 }
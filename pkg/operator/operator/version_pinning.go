@@ -0,0 +1,200 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	istionetworking "istio.io/api/networking/v1alpha3"
+	istioclientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	klabels "k8s.io/apimachinery/pkg/labels"
+)
+
+// clients set this header to the API ID which was returned with a previous response (in the same header)
+const _apiIDHeader = "x-cortex-api-id"
+
+// updateVersionPinningRoutes routes requests which pin a version of an API to the API's replicas which run that version,
+// for APIs which have version_pinning enabled; requests which pin a version that isn't running are rejected with status 404
+func updateVersionPinningRoutes() error {
+	if !isIstioNetworking() {
+		return nil
+	}
+
+	virtualServices, err := config.K8sAllNamspaces.ListVirtualServicesWithLabelKeys("apiName")
+	if err != nil {
+		return err
+	}
+
+	pods, err := listAPIPods(klabels.Everything())
+	if err != nil {
+		return err
+	}
+
+	runningAPIIDs := make(map[string]strset.Set) // apiName -> IDs of the versions which have ready replicas
+	for i := range pods {
+		if !k8s.IsPodReady(&pods[i]) {
+			continue
+		}
+		apiName := pods[i].Labels["apiName"]
+		if _, ok := runningAPIIDs[apiName]; !ok {
+			runningAPIIDs[apiName] = strset.New()
+		}
+		runningAPIIDs[apiName].Add(pods[i].Labels["apiID"])
+	}
+
+	var errs []error
+	for i := range virtualServices {
+		virtualService := &virtualServices[i]
+		if virtualService.Annotations[userconfig.VersionPinningAnnotationKey] != "true" {
+			continue
+		}
+
+		apiName := virtualService.Labels["apiName"]
+		var apiIDs []string
+		if runningAPIIDs[apiName] != nil {
+			apiIDs = runningAPIIDs[apiName].SliceSorted()
+		}
+
+		if err := updateVersionPinningRoute(virtualService, apiIDs); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if errors.HasError(errs) {
+		return errors.FirstError(errs...)
+	}
+	return nil
+}
+
+func updateVersionPinningRoute(virtualService *istioclientnetworking.VirtualService, apiIDs []string) error {
+	apiName := virtualService.Labels["apiName"]
+	k8sNamespace := config.K8sNamespace(virtualService.Namespace)
+
+	httpRoute := apiHTTPRoute(virtualService)
+	if httpRoute == nil || len(httpRoute.Match) == 0 {
+		return nil
+	}
+
+	destinationRule, err := k8sNamespace.GetDestinationRule(k8sName(apiName))
+	if err != nil || destinationRule == nil {
+		return err // the destination rule is created along with the virtual service
+	}
+
+	if !slices.StrSliceElementsMatch(subsetNames(destinationRule.Spec.Subsets), apiIDs) {
+		destinationRule.Spec.Subsets = make([]*istionetworking.Subset, len(apiIDs))
+		for i, apiID := range apiIDs {
+			destinationRule.Spec.Subsets[i] = &istionetworking.Subset{
+				Name: apiID,
+				Labels: map[string]string{
+					"apiName": apiName,
+					"apiID":   apiID,
+				},
+			}
+		}
+		if _, err := k8sNamespace.UpdateDestinationRule(destinationRule, destinationRule); err != nil {
+			return err
+		}
+	}
+
+	additionalRoutes := additionalHTTPRoutes(virtualService)
+
+	if slices.StrSliceElementsMatch(pinnedAPIIDs(virtualService), apiIDs) && len(virtualService.Spec.Http) == len(apiIDs)+2+len(additionalRoutes) {
+		return nil
+	}
+
+	destination := func(subset string) []*istionetworking.HTTPRouteDestination {
+		return []*istionetworking.HTTPRouteDestination{
+			{
+				Destination: &istionetworking.Destination{
+					Host:   k8sName(apiName),
+					Subset: subset,
+					Port: &istionetworking.PortSelector{
+						Number: uint32(_defaultPortInt32),
+					},
+				},
+			},
+		}
+	}
+
+	var httpRoutes []*istionetworking.HTTPRoute
+	for _, apiID := range apiIDs {
+		httpRoutes = append(httpRoutes, &istionetworking.HTTPRoute{
+			Match: []*istionetworking.HTTPMatchRequest{
+				{
+					Uri: httpRoute.Match[0].Uri,
+					Headers: map[string]*istionetworking.StringMatch{
+						_apiIDHeader: {MatchType: &istionetworking.StringMatch_Exact{Exact: apiID}},
+					},
+				},
+			},
+			Rewrite:    httpRoute.Rewrite,
+			CorsPolicy: httpRoute.CorsPolicy,
+			Headers:    httpRoute.Headers,
+			Route:      destination(apiID),
+		})
+	}
+
+	// requests which pin a version that isn't running
+	httpRoutes = append(httpRoutes, &istionetworking.HTTPRoute{
+		Match: []*istionetworking.HTTPMatchRequest{
+			{
+				Uri: httpRoute.Match[0].Uri,
+				Headers: map[string]*istionetworking.StringMatch{
+					_apiIDHeader: {MatchType: &istionetworking.StringMatch_Regex{Regex: ".*"}},
+				},
+			},
+		},
+		Fault: &istionetworking.HTTPFaultInjection{
+			Abort: &istionetworking.HTTPFaultInjection_Abort{
+				ErrorType:  &istionetworking.HTTPFaultInjection_Abort_HttpStatus{HttpStatus: 404},
+				Percentage: &istionetworking.Percent{Value: 100},
+			},
+		},
+		Route: destination(""),
+	})
+
+	// versions are only pinned on the API's endpoint; requests to its additional endpoints are routed to the latest version
+	httpRoutes = append(httpRoutes, additionalRoutes...)
+	virtualService.Spec.Http = append(httpRoutes, httpRoute)
+	_, err = k8sNamespace.UpdateVirtualService(virtualService, virtualService)
+	return err
+}
+
+func subsetNames(subsets []*istionetworking.Subset) []string {
+	names := make([]string, len(subsets))
+	for i, subset := range subsets {
+		names[i] = subset.Name
+	}
+	return names
+}
+
+// returns the API IDs which the virtual service's routes for pinned versions match
+func pinnedAPIIDs(virtualService *istioclientnetworking.VirtualService) []string {
+	var apiIDs []string
+	for _, httpRoute := range virtualService.Spec.Http {
+		for _, match := range httpRoute.Match {
+			if headerMatch, ok := match.Headers[_apiIDHeader]; ok && headerMatch.GetExact() != "" {
+				apiIDs = append(apiIDs, headerMatch.GetExact())
+			}
+		}
+	}
+	return apiIDs
+}
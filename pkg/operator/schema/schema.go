@@ -17,6 +17,9 @@ limitations under the License.
 package schema
 
 import (
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
 	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
 	"github.com/cortexlabs/cortex/pkg/types/metrics"
 	"github.com/cortexlabs/cortex/pkg/types/spec"
@@ -41,6 +44,32 @@ type NodeInfo struct {
 	ComputeAvailable userconfig.Compute `json:"compute_available"` // unused resources on a node
 }
 
+type CostsResponse struct {
+	APIs              []APICost  `json:"apis"`
+	Teams             []TeamCost `json:"teams"`
+	InstancesHourly   float64    `json:"instances_hourly"`   // all worker instances, including their volumes
+	UnallocatedHourly float64    `json:"unallocated_hourly"` // the share of InstancesHourly which isn't requested by any API
+	FixedHourly       float64    `json:"fixed_hourly"`       // EKS, the operator instance, load balancers, and NAT gateways
+	TrackedSince      time.Time  `json:"tracked_since"`      // when the operator started recording costs
+}
+
+// APICost is estimated from the share of each instance's resources that the API's replicas request
+type APICost struct {
+	APIName      string  `json:"api_name"`
+	Team         string  `json:"team,omitempty"`
+	Replicas     int     `json:"replicas"`      // currently running
+	Hourly       float64 `json:"hourly"`        // at the current number of replicas
+	Daily        float64 `json:"daily"`         // based on the past 24 hours
+	ReplicaHours float64 `json:"replica_hours"` // over the past 24 hours
+}
+
+type TeamCost struct {
+	Team   string  `json:"team"`
+	APIs   int     `json:"apis"`
+	Hourly float64 `json:"hourly"`
+	Daily  float64 `json:"daily"`
+}
+
 type DeployResponse struct {
 	Results []DeployResult `json:"results"`
 }
@@ -51,6 +80,75 @@ type DeployResult struct {
 	Error   string
 }
 
+// ProjectFile is an entry in the manifest of a project which is uploaded incrementally (the project's files are
+// uploaded by hash, and only if the cluster doesn't already have them)
+type ProjectFile struct {
+	Path string `json:"path"`
+	Hash string `json:"hash"`
+}
+
+type MissingProjectFilesResponse struct {
+	Hashes []string `json:"hashes"`
+}
+
+type ValidateResponse struct {
+	APINames []string `json:"api_names"`
+	Message  string   `json:"message"`
+}
+
+type DiffResponse struct {
+	Results []DiffResult `json:"results"`
+}
+
+type DiffResult struct {
+	APIName   string         `json:"api_name"`
+	Message   string         `json:"message"`
+	Error     string         `json:"error"`
+	Resources []ResourceDiff `json:"resources"` // empty if the API would not be updated
+}
+
+type RenderResponse struct {
+	Results []RenderResult `json:"results"`
+}
+
+type RenderResult struct {
+	APIName  string `json:"api_name"`
+	Error    string `json:"error"`
+	Manifest string `json:"manifest"` // the resources which would be applied, in YAML (separated by ---)
+}
+
+type ComputeRecommendationsResponse struct {
+	Results []ComputeRecommendationsResult `json:"results"`
+}
+
+type ComputeRecommendationsResult struct {
+	APIName             string                  `json:"api_name"`
+	Error               string                  `json:"error"`
+	InstanceType        string                  `json:"instance_type"`
+	ReplicasPerInstance int64                   `json:"replicas_per_instance"` // -1 if the API doesn't request any resources
+	Recommendations     []ComputeRecommendation `json:"recommendations"`
+}
+
+type ComputeRecommendation struct {
+	Resource            string `json:"resource"` // the compute field, e.g. "cpu"
+	Requested           string `json:"requested"`
+	Recommended         string `json:"recommended"`
+	ReplicasPerInstance int64  `json:"replicas_per_instance"` // with the recommended request
+	Message             string `json:"message"`
+}
+
+type ResourceDiff struct {
+	Kind     string          `json:"kind"`
+	Name     string          `json:"name"`
+	Created  bool            `json:"created"` // true if the resource doesn't exist yet
+	Changes  []k8s.FieldDiff `json:"changes"`
+	Manifest string          `json:"manifest"` // the resource which would be applied, in YAML
+}
+
+type GetMetricsResponse struct {
+	TimeSeries metrics.TimeSeries `json:"time_series"`
+}
+
 type GetAPIsResponse struct {
 	APIs       []spec.API        `json:"apis"`
 	Statuses   []status.Status   `json:"statuses"`
@@ -73,6 +171,342 @@ type RefreshResponse struct {
 	Message string `json:"message"`
 }
 
+// Backup describes a snapshot of the APIs which are deployed in a cluster, which can be restored onto another cluster
+type Backup struct {
+	Path          string    `json:"path"` // the S3 path of the backup's directory
+	ClusterName   string    `json:"cluster_name"`
+	CortexVersion string    `json:"cortex_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	APIs          []string  `json:"apis"`
+}
+
+type BackupResponse struct {
+	Backup Backup `json:"backup"`
+}
+
+type RestoreResponse struct {
+	Backup  Backup         `json:"backup"`
+	Results []DeployResult `json:"results"`
+}
+
+type DeploymentEvent struct {
+	APIName      string `json:"api_name"`
+	APIID        string `json:"api_id,omitempty"`
+	DeploymentID string `json:"deployment_id,omitempty"`
+	Action       string `json:"action"`
+	Timestamp    int64  `json:"timestamp"`
+}
+
+type AuditEvent struct {
+	Timestamp int64           `json:"timestamp"`
+	Caller    string          `json:"caller"` // ARN of the IAM identity which made the request
+	Action    string          `json:"action"`
+	APIName   string          `json:"api_name"`
+	APIID     string          `json:"api_id,omitempty"`
+	Message   string          `json:"message,omitempty"`
+	Error     string          `json:"error,omitempty"`   // set if the action failed
+	Changes   []k8s.FieldDiff `json:"changes,omitempty"` // changes to the API's configuration (for deploys)
+}
+
+type GetAuditLogResponse struct {
+	Events []AuditEvent `json:"events"`
+}
+
+type MaintenanceResponse struct {
+	Message string `json:"message"`
+}
+
+type PauseResponse struct {
+	Message string `json:"message"`
+}
+
+type GetHistoryResponse struct {
+	Events []DeploymentEvent `json:"events"`
+}
+
+// ExperimentResults compares an API (the experiment's control) with its variants, since the API was last deployed
+type ExperimentResults struct {
+	APIName    string                     `json:"api_name"`
+	Metric     string                     `json:"metric"`
+	Confidence float64                    `json:"confidence"`
+	StartTime  time.Time                  `json:"start_time"`
+	Variants   []ExperimentVariantResults `json:"variants"` // the control is first
+	Winner     *string                    `json:"winner"`   // the variant which performs best on the experiment's metric, if it is significantly better than the control
+	Promoted   bool                       `json:"promoted"` // whether all traffic has been routed to the winner (see auto_promote)
+}
+
+type ExperimentVariantResults struct {
+	APIName     string   `json:"api_name"`
+	Weight      int32    `json:"weight"` // the percentage of traffic which is currently routed to the API
+	Requests    int64    `json:"requests"`
+	ErrorRate   float64  `json:"error_rate"`
+	LatencyAvg  *float64 `json:"latency_avg"` // milliseconds
+	RewardAvg   *float64 `json:"reward_avg"`
+	PValue      *float64 `json:"p_value"` // of the difference from the control on the experiment's metric (nil for the control, or if there isn't enough data)
+	Significant bool     `json:"significant"`
+}
+
+type GetExperimentResponse struct {
+	Experiment ExperimentResults `json:"experiment"`
+}
+
+// SLOStatus tracks an API's service level objectives over the SLO's window
+type SLOStatus struct {
+	APIName         string               `json:"api_name"`
+	StartTime       time.Time            `json:"start_time"`
+	EndTime         time.Time            `json:"end_time"`
+	Objectives      []SLOObjectiveStatus `json:"objectives"`
+	BudgetExhausted bool                 `json:"budget_exhausted"` // whether any of the objectives' error budgets has been used up
+}
+
+type SLOObjectiveStatus struct {
+	Name                 string   `json:"name"`                   // availability or latency_p99
+	Target               float64  `json:"target"`                 // the fraction of requests (for availability) or time (for latency_p99) which must meet the objective
+	Compliance           *float64 `json:"compliance"`             // the fraction which met the objective during the window (nil if the API received no requests)
+	ErrorBudgetRemaining float64  `json:"error_budget_remaining"` // the fraction of the error budget which hasn't been used (negative if it has been overspent)
+	BurnRate             *float64 `json:"burn_rate"`              // how fast the error budget was used in the last hour (1 would use exactly the whole budget over the window)
+}
+
+type GetSLOResponse struct {
+	SLO SLOStatus `json:"slo"`
+}
+
+const (
+	ReplayStatusRunning     = "running"
+	ReplayStatusCompleted   = "completed"
+	ReplayStatusFailed      = "failed"
+	ReplayStatusInterrupted = "interrupted" // the operator restarted while the replay was running
+)
+
+// Replay re-sends an API's captured requests (see payload_logging) to a target API, and compares the target's responses with the captured responses
+type Replay struct {
+	ID         string       `json:"id"`
+	APIName    string       `json:"api_name"` // the API whose requests were captured
+	TargetAPI  string       `json:"target_api"`
+	Start      time.Time    `json:"start"`
+	End        time.Time    `json:"end"`
+	Speed      float64      `json:"speed"` // relative to the pace at which the requests were captured (0 sends them as quickly as possible)
+	Status     string       `json:"status"`
+	Error      string       `json:"error,omitempty"`
+	StartedAt  int64        `json:"started_at"`
+	FinishedAt *int64       `json:"finished_at"`
+	Requests   int64        `json:"requests"` // the number of captured requests in the time range
+	Sent       int64        `json:"sent"`
+	Matching   int64        `json:"matching"`
+	Differing  int64        `json:"differing"`
+	Failed     int64        `json:"failed"` // requests which didn't receive a response from the target
+	Diffs      []ReplayDiff `json:"diffs"`  // a sample of the differing responses
+}
+
+type ReplayDiff struct {
+	RequestID        string `json:"request_id"`
+	StatusCode       int    `json:"status_code"` // of the captured response
+	TargetStatusCode int    `json:"target_status_code"`
+	Response         string `json:"response"` // truncated
+	TargetResponse   string `json:"target_response"`
+}
+
+type ReplayResponse struct {
+	Replay Replay `json:"replay"`
+}
+
+type ListReplaysResponse struct {
+	Replays []Replay `json:"replays"`
+}
+
+const (
+	LoadTestStatusRunning     = "running"
+	LoadTestStatusCompleted   = "completed"
+	LoadTestStatusFailed      = "failed"
+	LoadTestStatusInterrupted = "interrupted" // the operator restarted while the load test was running
+)
+
+// LoadTestConfig configures the traffic which a load test sends to an API: each step of the concurrency ramp sends
+// requests from a fixed number of concurrent clients for the step's duration
+type LoadTestConfig struct {
+	Payload         string  `json:"payload"`
+	ContentType     string  `json:"content_type"`
+	MinConcurrency  int     `json:"min_concurrency"`
+	MaxConcurrency  int     `json:"max_concurrency"`
+	ConcurrencyStep int     `json:"concurrency_step"`
+	StepDuration    int64   `json:"step_duration"` // seconds
+	MaxLatency      float64 `json:"max_latency"`   // milliseconds; steps whose p99 latency is higher aren't sustainable (0 means no limit)
+}
+
+// LoadTest runs a traffic generator job against an API, and measures the latency and throughput at each step of the concurrency ramp
+type LoadTest struct {
+	ID                string         `json:"id"`
+	APIName           string         `json:"api_name"`
+	Config            LoadTestConfig `json:"config"` // the payload is omitted
+	Status            string         `json:"status"`
+	Error             string         `json:"error,omitempty"`
+	StartedAt         int64          `json:"started_at"`
+	FinishedAt        *int64         `json:"finished_at"`
+	Steps             []LoadTestStep `json:"steps"`
+	MaxSustainableQPS float64        `json:"max_sustainable_qps"` // the highest throughput of the sustainable steps (0 if no step was sustainable)
+	MaxReplicas       int32          `json:"max_replicas"`        // the most ready replicas that the API had during the load test
+	MaxGPUs           int64          `json:"max_gpus"`
+
+	// the target_replica_concurrency which would scale the API to the replicas which sustained the highest throughput
+	// (nil if no step was sustainable)
+	RecommendedTargetReplicaConcurrency *float64 `json:"recommended_target_replica_concurrency"`
+}
+
+type LoadTestStep struct {
+	Concurrency int     `json:"concurrency"`
+	Requests    int64   `json:"requests"`
+	Errors      int64   `json:"errors"`
+	QPS         float64 `json:"qps"` // successful responses per second
+	P50         float64 `json:"p50"` // milliseconds
+	P90         float64 `json:"p90"`
+	P99         float64 `json:"p99"`
+	Replicas    int32   `json:"replicas"` // the most ready replicas that the API had during the step
+	GPUs        int64   `json:"gpus"`
+	Sustainable bool    `json:"sustainable"`
+}
+
+type LoadTestResponse struct {
+	LoadTest LoadTest `json:"load_test"`
+}
+
+const (
+	BatchJobStatusQueued    = "queued"
+	BatchJobStatusRunning   = "running"
+	BatchJobStatusSucceeded = "succeeded"
+	BatchJobStatusFailed    = "failed"
+	BatchJobStatusCancelled = "cancelled"
+)
+
+const (
+	BatchJobPartitionStatusPending   = "pending"
+	BatchJobPartitionStatusRunning   = "running"
+	BatchJobPartitionStatusSucceeded = "succeeded"
+	BatchJobPartitionStatusFailed    = "failed"
+)
+
+// BatchJobSubmission is a batch of items for an API's predictor; the items are split evenly between the job's workers
+type BatchJobSubmission struct {
+	Items                   []interface{}            `json:"items"`
+	Workers                 int32                    `json:"workers"`                   // defaults to 1
+	Priority                *userconfig.PriorityType `json:"priority"`                  // defaults to the API's compute.priority
+	MaxRetries              int32                    `json:"max_retries"`               // the number of times a failed partition is retried
+	PartitionTimeoutSeconds *int64                   `json:"partition_timeout_seconds"` // how long each attempt of a partition may run for
+	DeadLetterPrefix        *string                  `json:"dead_letter_prefix"`        // the S3 path which the items of failed partitions (and the items which fail) are written to
+	Callback                *BatchJobCallback        `json:"callback"`
+}
+
+const (
+	BatchJobCallbackStatusPending = "pending"
+	BatchJobCallbackStatusSent    = "sent"
+	BatchJobCallbackStatusFailed  = "failed"
+)
+
+// BatchJobCallback configures the job's status and results to be sent to a URL and/or an SNS topic once the job has finished
+type BatchJobCallback struct {
+	URL           *string `json:"url"`
+	SNSTopic      *string `json:"sns_topic"`
+	SigningKeyEnv *string `json:"signing_key_env"` // the variable in the API's predictor.env which contains the key that URL callbacks are signed with
+	MaxRetries    int32   `json:"max_retries"`
+}
+
+// BatchJob runs an API's predictor on a batch of items; jobs wait in a queue until the API's (and its team's) limit on
+// concurrent jobs allows them to run, and the cluster has enough GPUs for them
+type BatchJob struct {
+	ID               string                  `json:"id"`
+	APIName          string                  `json:"api_name"`
+	APIID            string                  `json:"api_id"`         // the ID of the API which the job runs (or will run) with
+	Namespace        string                  `json:"namespace"`      // the namespace of the API, in which the workers run
+	Team             string                  `json:"team,omitempty"` // the team which owns the API
+	Priority         userconfig.PriorityType `json:"priority"`
+	Items            int                     `json:"items"`
+	Workers          int32                   `json:"workers"`
+	GPUs             int64                   `json:"gpus"` // the total GPUs requested by the job's workers
+	Status           string                  `json:"status"`
+	QueuedReason     string                  `json:"queued_reason,omitempty"` // why a queued job hasn't started yet
+	Error            string                  `json:"error,omitempty"`
+	SubmittedAt      int64                   `json:"submitted_at"`
+	StartedAt        *int64                  `json:"started_at"`
+	FinishedAt       *int64                  `json:"finished_at"`
+	SucceededWorkers int32                   `json:"succeeded_workers"`
+	ResultsPath      string                  `json:"results_path"`     // the directory to which each worker writes its results
+	Source           string                  `json:"source,omitempty"` // the S3 object which the items were read from, if the job was submitted by the API's batch_trigger
+
+	MaxRetries              int32               `json:"max_retries"`
+	PartitionTimeoutSeconds *int64              `json:"partition_timeout_seconds"`
+	DeadLetterPrefix        *string             `json:"dead_letter_prefix"`
+	Partitions              []BatchJobPartition `json:"partitions"` // the outcome of each worker's partition of the items
+
+	Callback         *BatchJobCallback `json:"callback"`
+	CallbackStatus   string            `json:"callback_status,omitempty"` // set once the job has finished, if it has a callback
+	CallbackAttempts int32             `json:"callback_attempts"`
+	CallbackError    string            `json:"callback_error,omitempty"`
+	NextCallbackAt   int64             `json:"next_callback_at,omitempty"` // when the callback is retried after it fails
+}
+
+// BatchJobPartition is the share of a batch job's items which is processed by one of its workers; a partition which
+// fails is retried up to the job's max_retries times, and if it still fails, its items are written to the job's dead
+// letter prefix (if the job has one) while the other partitions continue
+type BatchJobPartition struct {
+	Index          int32  `json:"index"` // the index of the worker which processes the partition
+	Items          int    `json:"items"`
+	Status         string `json:"status"`
+	Attempts       int32  `json:"attempts"`
+	FailedItems    int    `json:"failed_items"` // the items for which the predictor raised an exception (known once the partition succeeds)
+	Error          string `json:"error,omitempty"`
+	DeadLetterPath string `json:"dead_letter_path,omitempty"` // the file which the partition's items were written to if it failed
+}
+
+type BatchJobResponse struct {
+	BatchJob BatchJob `json:"batch_job"`
+}
+
+type BatchJobsResponse struct {
+	BatchJobs []BatchJob `json:"batch_jobs"`
+}
+
+const (
+	NodeMigrationStatusRunning   = "running"
+	NodeMigrationStatusSucceeded = "succeeded"
+	NodeMigrationStatusFailed    = "failed"
+)
+
+// NodeMigration moves the cluster's APIs (and other pods) off of the nodes which don't belong to a node generation
+// (i.e. the node groups which were created by a cluster upgrade), so that the old nodes can be deleted
+type NodeMigration struct {
+	Generation   string   `json:"generation"`
+	Status       string   `json:"status"`
+	Error        string   `json:"error,omitempty"`
+	StartedAt    int64    `json:"started_at"`
+	FinishedAt   *int64   `json:"finished_at"`
+	Nodes        []string `json:"nodes"` // the old nodes
+	APIs         int      `json:"apis"`  // the APIs which had replicas on the old nodes
+	MigratedAPIs int      `json:"migrated_apis"`
+}
+
+type NodeMigrationResponse struct {
+	NodeMigration NodeMigration `json:"node_migration"`
+}
+
+// DeadLetters summarizes the records which a stream API could not process
+type DeadLetters struct {
+	APIName string         `json:"api_name"`
+	Prefix  string         `json:"prefix"` // the S3 path which the API's records are written to
+	Counts  map[string]int `json:"counts"` // the number of records per day (YYYY-MM-DD, UTC)
+	Recent  []DeadLetter   `json:"recent"` // the most recent records, newest first
+}
+
+type DeadLetter struct {
+	ID        string  `json:"id"` // the record's ID in the stream (e.g. the SQS message ID)
+	Timestamp float64 `json:"timestamp"`
+	Attempts  int     `json:"attempts"`
+	Error     string  `json:"error"`
+	S3Path    string  `json:"s3_path"` // the file which contains the record and the traceback of its failure
+}
+
+type DeadLettersResponse struct {
+	DeadLetters DeadLetters `json:"dead_letters"`
+}
+
 type ErrorResponse struct {
 	Kind    string `json:"kind"`
 	Message string `json:"message"`
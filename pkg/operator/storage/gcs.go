@@ -0,0 +1,73 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/gcp"
+)
+
+type gcsBucket struct {
+	gcp  *gcp.Client
+	name string
+}
+
+func newGCSBucket(gcpClient *gcp.Client, bucket string) *gcsBucket {
+	return &gcsBucket{
+		gcp:  gcpClient,
+		name: bucket,
+	}
+}
+
+func (bucket *gcsBucket) Path(key string) string {
+	return gcp.GCSPath(bucket.name, key)
+}
+
+func (bucket *gcsBucket) UploadBytes(data []byte, key string) error {
+	return bucket.gcp.UploadBytesToGCS(data, bucket.name, key)
+}
+
+func (bucket *gcsBucket) UploadString(str string, key string) error {
+	return bucket.gcp.UploadStringToGCS(str, bucket.name, key)
+}
+
+func (bucket *gcsBucket) UploadMsgpack(obj interface{}, key string) error {
+	return bucket.gcp.UploadMsgpackToGCS(obj, bucket.name, key)
+}
+
+func (bucket *gcsBucket) ReadBytes(key string) ([]byte, error) {
+	return bucket.gcp.ReadBytesFromGCS(bucket.name, key)
+}
+
+func (bucket *gcsBucket) ReadMsgpack(objPtr interface{}, key string) error {
+	return bucket.gcp.ReadMsgpackFromGCS(objPtr, bucket.name, key)
+}
+
+func (bucket *gcsBucket) Exists(key string) (bool, error) {
+	return bucket.gcp.IsGCSFile(bucket.name, key)
+}
+
+func (bucket *gcsBucket) ListKeys(prefix string, maxResults *int64) ([]string, error) {
+	return bucket.gcp.ListGCSPrefix(bucket.name, prefix, maxResults)
+}
+
+func (bucket *gcsBucket) DeleteFile(key string) error {
+	return bucket.gcp.DeleteGCSFile(bucket.name, key)
+}
+
+func (bucket *gcsBucket) DeleteDir(prefix string) error {
+	return bucket.gcp.DeleteGCSPrefix(bucket.name, prefix)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+)
+
+type s3Bucket struct {
+	aws  *aws.Client
+	name string
+}
+
+func newS3Bucket(awsClient *aws.Client, bucket string) *s3Bucket {
+	return &s3Bucket{
+		aws:  awsClient,
+		name: bucket,
+	}
+}
+
+func (bucket *s3Bucket) Path(key string) string {
+	return aws.S3Path(bucket.name, key)
+}
+
+func (bucket *s3Bucket) UploadBytes(data []byte, key string) error {
+	return bucket.aws.UploadBytesToS3(data, bucket.name, key)
+}
+
+func (bucket *s3Bucket) UploadString(str string, key string) error {
+	return bucket.aws.UploadStringToS3(str, bucket.name, key)
+}
+
+func (bucket *s3Bucket) UploadMsgpack(obj interface{}, key string) error {
+	return bucket.aws.UploadMsgpackToS3(obj, bucket.name, key)
+}
+
+func (bucket *s3Bucket) ReadBytes(key string) ([]byte, error) {
+	return bucket.aws.ReadBytesFromS3(bucket.name, key)
+}
+
+func (bucket *s3Bucket) ReadMsgpack(objPtr interface{}, key string) error {
+	return bucket.aws.ReadMsgpackFromS3(objPtr, bucket.name, key)
+}
+
+func (bucket *s3Bucket) Exists(key string) (bool, error) {
+	return bucket.aws.IsS3File(bucket.name, key)
+}
+
+func (bucket *s3Bucket) ListKeys(prefix string, maxResults *int64) ([]string, error) {
+	objects, err := bucket.aws.ListS3Prefix(bucket.name, prefix, false, maxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(objects))
+	for i, object := range objects {
+		keys[i] = *object.Key
+	}
+	return keys, nil
+}
+
+func (bucket *s3Bucket) DeleteFile(key string) error {
+	return bucket.aws.DeleteS3File(bucket.name, key)
+}
+
+func (bucket *s3Bucket) DeleteDir(prefix string) error {
+	return bucket.aws.DeleteS3Dir(bucket.name, prefix, true)
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/gcp"
+	"github.com/cortexlabs/cortex/pkg/types"
+	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
+)
+
+// Bucket stores the cluster's objects (e.g. api specs, projects, and build artifacts); keys are relative to the cluster's bucket
+type Bucket interface {
+	// Path returns the full path of key (e.g. s3://bucket/key or gs://bucket/key), as read by the downloader and the api containers
+	Path(key string) string
+	UploadBytes(data []byte, key string) error
+	UploadString(str string, key string) error
+	UploadMsgpack(obj interface{}, key string) error
+	ReadBytes(key string) ([]byte, error)
+	ReadMsgpack(objPtr interface{}, key string) error
+	Exists(key string) (bool, error)
+	// ListKeys returns the keys which begin with prefix (if maxResults is not nil, at most maxResults keys are returned)
+	ListKeys(prefix string, maxResults *int64) ([]string, error)
+	DeleteFile(key string) error
+	// DeleteDir deletes all objects whose keys begin with prefix
+	DeleteDir(prefix string) error
+}
+
+// New returns the cluster's bucket, which is in GCS on gcp and in S3 (or an S3-compatible object store) otherwise
+func New(clusterConfig *clusterconfig.Config, awsClient *aws.Client, gcpClient *gcp.Client) Bucket {
+	if clusterConfig.Provider == types.GCPProviderType {
+		return newGCSBucket(gcpClient, clusterConfig.Bucket)
+	}
+	return newS3Bucket(awsClient, clusterConfig.Bucket)
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/prompt"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/lib/table"
+	"github.com/cortexlabs/cortex/pkg/types"
 )
 
 const ClusterNameTag = "cortex.dev/cluster-name"
@@ -46,41 +47,78 @@ var (
 )
 
 type Config struct {
-	InstanceType               *string            `json:"instance_type" yaml:"instance_type"`
-	MinInstances               *int64             `json:"min_instances" yaml:"min_instances"`
-	MaxInstances               *int64             `json:"max_instances" yaml:"max_instances"`
-	InstanceVolumeSize         int64              `json:"instance_volume_size" yaml:"instance_volume_size"`
-	InstanceVolumeType         VolumeType         `json:"instance_volume_type" yaml:"instance_volume_type"`
-	InstanceVolumeIOPS         *int64             `json:"instance_volume_iops" yaml:"instance_volume_iops"`
-	Tags                       map[string]string  `json:"tags" yaml:"tags"`
-	Spot                       *bool              `json:"spot" yaml:"spot"`
-	SpotConfig                 *SpotConfig        `json:"spot_config" yaml:"spot_config"`
-	ClusterName                string             `json:"cluster_name" yaml:"cluster_name"`
-	Region                     *string            `json:"region" yaml:"region"`
-	AvailabilityZones          []string           `json:"availability_zones" yaml:"availability_zones"`
-	SSLCertificateARN          *string            `json:"ssl_certificate_arn,omitempty" yaml:"ssl_certificate_arn,omitempty"`
-	Bucket                     string             `json:"bucket" yaml:"bucket"`
-	LogGroup                   string             `json:"log_group" yaml:"log_group"`
-	SubnetVisibility           SubnetVisibility   `json:"subnet_visibility" yaml:"subnet_visibility"`
-	NATGateway                 NATGateway         `json:"nat_gateway" yaml:"nat_gateway"`
-	APILoadBalancerScheme      LoadBalancerScheme `json:"api_load_balancer_scheme" yaml:"api_load_balancer_scheme"`
-	OperatorLoadBalancerScheme LoadBalancerScheme `json:"operator_load_balancer_scheme" yaml:"operator_load_balancer_scheme"`
-	Telemetry                  bool               `json:"telemetry" yaml:"telemetry"`
-	ImageOperator              string             `json:"image_operator" yaml:"image_operator"`
-	ImageManager               string             `json:"image_manager" yaml:"image_manager"`
-	ImageDownloader            string             `json:"image_downloader" yaml:"image_downloader"`
-	ImageRequestMonitor        string             `json:"image_request_monitor" yaml:"image_request_monitor"`
-	ImageClusterAutoscaler     string             `json:"image_cluster_autoscaler" yaml:"image_cluster_autoscaler"`
-	ImageMetricsServer         string             `json:"image_metrics_server" yaml:"image_metrics_server"`
-	ImageInferentia            string             `json:"image_inferentia" yaml:"image_inferentia"`
-	ImageNeuronRTD             string             `json:"image_neuron_rtd" yaml:"image_neuron_rtd"`
-	ImageNvidia                string             `json:"image_nvidia" yaml:"image_nvidia"`
-	ImageFluentd               string             `json:"image_fluentd" yaml:"image_fluentd"`
-	ImageStatsd                string             `json:"image_statsd" yaml:"image_statsd"`
-	ImageIstioProxy            string             `json:"image_istio_proxy" yaml:"image_istio_proxy"`
-	ImageIstioPilot            string             `json:"image_istio_pilot" yaml:"image_istio_pilot"`
-	ImageIstioCitadel          string             `json:"image_istio_citadel" yaml:"image_istio_citadel"`
-	ImageIstioGalley           string             `json:"image_istio_galley" yaml:"image_istio_galley"`
+	InstanceType                *string            `json:"instance_type" yaml:"instance_type"`
+	MinInstances                *int64             `json:"min_instances" yaml:"min_instances"`
+	MaxInstances                *int64             `json:"max_instances" yaml:"max_instances"`
+	InstanceVolumeSize          int64              `json:"instance_volume_size" yaml:"instance_volume_size"`
+	InstanceVolumeType          VolumeType         `json:"instance_volume_type" yaml:"instance_volume_type"`
+	InstanceVolumeIOPS          *int64             `json:"instance_volume_iops" yaml:"instance_volume_iops"`
+	Tags                        map[string]string  `json:"tags" yaml:"tags"`
+	Spot                        *bool              `json:"spot" yaml:"spot"`
+	SpotConfig                  *SpotConfig        `json:"spot_config" yaml:"spot_config"`
+	ClusterName                 string             `json:"cluster_name" yaml:"cluster_name"`
+	Region                      *string            `json:"region" yaml:"region"`
+	AvailabilityZones           []string           `json:"availability_zones" yaml:"availability_zones"`
+	SSLCertificateARN           *string            `json:"ssl_certificate_arn,omitempty" yaml:"ssl_certificate_arn,omitempty"`
+	Bucket                      string             `json:"bucket" yaml:"bucket"`
+	S3Endpoint                  *string            `json:"s3_endpoint,omitempty" yaml:"s3_endpoint,omitempty"`
+	LogGroup                    string             `json:"log_group" yaml:"log_group"`
+	MetadataStore               MetadataStoreType  `json:"metadata_store" yaml:"metadata_store"`
+	MetadataTable               string             `json:"metadata_table" yaml:"metadata_table"`
+	SubnetVisibility            SubnetVisibility   `json:"subnet_visibility" yaml:"subnet_visibility"`
+	NATGateway                  NATGateway         `json:"nat_gateway" yaml:"nat_gateway"`
+	APILoadBalancerScheme       LoadBalancerScheme `json:"api_load_balancer_scheme" yaml:"api_load_balancer_scheme"`
+	OperatorLoadBalancerScheme  LoadBalancerScheme `json:"operator_load_balancer_scheme" yaml:"operator_load_balancer_scheme"`
+	APILoadBalancerEIPs         []string           `json:"api_load_balancer_eip_allocations" yaml:"api_load_balancer_eip_allocations"`
+	APILoadBalancerPrivateLink  *PrivateLink       `json:"api_load_balancer_private_link" yaml:"api_load_balancer_private_link"`
+	OperatorReplicas            int64              `json:"operator_replicas" yaml:"operator_replicas"`
+	Lightweight                 bool               `json:"lightweight" yaml:"lightweight"`
+	Provider                    types.ProviderType `json:"provider" yaml:"provider"`
+	GCP                         *GCPConfig         `json:"gcp" yaml:"gcp"`
+	NetworkingBackend           NetworkingBackend  `json:"networking_backend" yaml:"networking_backend"`
+	IngressClass                string             `json:"ingress_class" yaml:"ingress_class"`
+	IngressControllerService    string             `json:"ingress_controller_service" yaml:"ingress_controller_service"`
+	APIMTLS                     bool               `json:"api_mtls" yaml:"api_mtls"`
+	RequestMonitor              RequestMonitorMode `json:"request_monitor" yaml:"request_monitor"`
+	RequestMonitorCPU           string             `json:"request_monitor_cpu" yaml:"request_monitor_cpu"`
+	RequestMonitorMem           string             `json:"request_monitor_mem" yaml:"request_monitor_mem"`
+	RequestMonitorFlushInterval int64              `json:"request_monitor_flush_interval" yaml:"request_monitor_flush_interval"` // seconds
+	MetricSinks                 []string           `json:"metric_sinks" yaml:"metric_sinks"`
+	StatsDHost                  *string            `json:"statsd_host" yaml:"statsd_host"` // defaults to the IP of the API's node (e.g. for an agent daemonset)
+	StatsDPort                  int64              `json:"statsd_port" yaml:"statsd_port"`
+	Admins                      []string           `json:"admins" yaml:"admins"`
+	Teams                       []*Team            `json:"teams" yaml:"teams"`
+	APIEnvConfigMaps            []string           `json:"api_env_config_maps" yaml:"api_env_config_maps"`
+	APIEnvSecrets               []string           `json:"api_env_secrets" yaml:"api_env_secrets"`
+	Notifications               *Notifications     `json:"notifications" yaml:"notifications"`
+	NodeGroups                  []*NodeGroup       `json:"node_groups" yaml:"node_groups"`
+	Overprovisioning            *Overprovisioning  `json:"overprovisioning" yaml:"overprovisioning"`
+	MaxProjectSize              string             `json:"max_project_size" yaml:"max_project_size"`
+	P2PModelDistribution        bool               `json:"p2p_model_distribution" yaml:"p2p_model_distribution"`
+	Environment                 *string            `json:"environment" yaml:"environment"`
+	Variables                   map[string]string  `json:"variables" yaml:"variables"`
+	Telemetry                   bool               `json:"telemetry" yaml:"telemetry"`
+	ImageOperator               string             `json:"image_operator" yaml:"image_operator"`
+	ImageManager                string             `json:"image_manager" yaml:"image_manager"`
+	ImageDownloader             string             `json:"image_downloader" yaml:"image_downloader"`
+	ImageRequestMonitor         string             `json:"image_request_monitor" yaml:"image_request_monitor"`
+	ImageModelOptimizer         string             `json:"image_model_optimizer" yaml:"image_model_optimizer"`
+	ImageLoadTester             string             `json:"image_load_tester" yaml:"image_load_tester"`
+	ImageKaniko                 string             `json:"image_kaniko" yaml:"image_kaniko"`
+	ImageClusterAutoscaler      string             `json:"image_cluster_autoscaler" yaml:"image_cluster_autoscaler"`
+	ImageMetricsServer          string             `json:"image_metrics_server" yaml:"image_metrics_server"`
+	ImageInferentia             string             `json:"image_inferentia" yaml:"image_inferentia"`
+	ImageNeuronRTD              string             `json:"image_neuron_rtd" yaml:"image_neuron_rtd"`
+	ImageNeuronMonitor          string             `json:"image_neuron_monitor" yaml:"image_neuron_monitor"`
+	ImageNvidia                 string             `json:"image_nvidia" yaml:"image_nvidia"`
+	ImageDCGMExporter           string             `json:"image_dcgm_exporter" yaml:"image_dcgm_exporter"`
+	ImageFluentd                string             `json:"image_fluentd" yaml:"image_fluentd"`
+	ImageStatsd                 string             `json:"image_statsd" yaml:"image_statsd"`
+	ImagePause                  string             `json:"image_pause" yaml:"image_pause"`
+	ImageIstioProxy             string             `json:"image_istio_proxy" yaml:"image_istio_proxy"`
+	ImageIstioPilot             string             `json:"image_istio_pilot" yaml:"image_istio_pilot"`
+	ImageIstioCitadel           string             `json:"image_istio_citadel" yaml:"image_istio_citadel"`
+	ImageIstioGalley            string             `json:"image_istio_galley" yaml:"image_istio_galley"`
 }
 
 type SpotConfig struct {
@@ -92,6 +130,56 @@ type SpotConfig struct {
 	OnDemandBackup                      *bool    `json:"on_demand_backup" yaml:"on_demand_backup"`
 }
 
+type Team struct {
+	Name              string   `json:"name" yaml:"name"`
+	Members           []string `json:"members" yaml:"members"`
+	MaxAPIs           *int64   `json:"max_apis" yaml:"max_apis"`
+	MaxReplicas       *int64   `json:"max_replicas" yaml:"max_replicas"`
+	MaxGPUs           *int64   `json:"max_gpus" yaml:"max_gpus"`
+	MaxConcurrentJobs *int64   `json:"max_concurrent_jobs" yaml:"max_concurrent_jobs"`
+}
+
+// PrivateLink exposes the API load balancer as a VPC endpoint service, so that APIs can be consumed from other VPCs (and
+// other AWS accounts) via interface VPC endpoints, without VPC peering or traversing the internet
+type PrivateLink struct {
+	AllowedPrincipals  []string `json:"allowed_principals" yaml:"allowed_principals"`
+	AcceptanceRequired bool     `json:"acceptance_required" yaml:"acceptance_required"`
+}
+
+// GCPConfig configures a cluster which runs on an existing GKE cluster (see ValidateGCP)
+type GCPConfig struct {
+	Project        string  `json:"project" yaml:"project"`
+	Zone           string  `json:"zone" yaml:"zone"`
+	ServiceAccount string  `json:"service_account" yaml:"service_account"`
+	ImageRegistry  *string `json:"image_registry" yaml:"image_registry"`
+	MachineType    string  `json:"machine_type" yaml:"machine_type"`
+	Accelerator    *string `json:"accelerator" yaml:"accelerator"`
+}
+
+type Overprovisioning struct {
+	Replicas int64  `json:"replicas" yaml:"replicas"`
+	CPU      string `json:"cpu" yaml:"cpu"`
+	Mem      string `json:"mem" yaml:"mem"`
+	GPU      int64  `json:"gpu" yaml:"gpu"`
+}
+
+type Notifications struct {
+	SlackWebhook *string `json:"slack_webhook" yaml:"slack_webhook"`
+	Webhook      *string `json:"webhook" yaml:"webhook"`
+	SNSTopic     *string `json:"sns_topic" yaml:"sns_topic"`
+}
+
+type NodeGroup struct {
+	Name         string `json:"name" yaml:"name"`
+	InstanceType string `json:"instance_type" yaml:"instance_type"`
+	MinInstances int64  `json:"min_instances" yaml:"min_instances"`
+	MaxInstances int64  `json:"max_instances" yaml:"max_instances"`
+	Spot         bool   `json:"spot" yaml:"spot"`
+	// the GKE node pool's machine type and accelerator (only used on gcp, where instance_type describes the nodes' capacity)
+	GCPMachineType *string `json:"gcp_machine_type" yaml:"gcp_machine_type"`
+	GCPAccelerator *string `json:"gcp_accelerator" yaml:"gcp_accelerator"`
+}
+
 type InternalConfig struct {
 	Config
 
@@ -263,6 +351,12 @@ var UserValidation = &cr.StructValidation{
 				Validator:        validateBucketNameOrEmpty,
 			},
 		},
+		{
+			StructField: "S3Endpoint",
+			StringPtrValidation: &cr.StringPtrValidation{
+				Validator: cr.GetURLValidator(false, false),
+			},
+		},
 		{
 			StructField: "LogGroup",
 			StringValidation: &cr.StringValidation{
@@ -270,6 +364,26 @@ var UserValidation = &cr.StructValidation{
 			},
 			DefaultField: "ClusterName",
 		},
+		{
+			StructField: "MetadataStore",
+			StringValidation: &cr.StringValidation{
+				AllowedValues: MetadataStoreTypeStrings(),
+				Default:       S3MetadataStoreType.String(),
+			},
+			Parser: func(str string) (interface{}, error) {
+				return MetadataStoreTypeFromString(str), nil
+			},
+		},
+		{
+			StructField: "MetadataTable",
+			StringValidation: &cr.StringValidation{
+				MaxLength: 255,
+			},
+			DefaultField: "ClusterName",
+			DefaultFieldFunc: func(val interface{}) interface{} {
+				return val.(string) + "-metadata"
+			},
+		},
 		{
 			StructField: "SubnetVisibility",
 			StringValidation: &cr.StringValidation{
@@ -316,6 +430,412 @@ var UserValidation = &cr.StructValidation{
 				return LoadBalancerSchemeFromString(str), nil
 			},
 		},
+		{
+			StructField: "APILoadBalancerEIPs",
+			StringListValidation: &cr.StringListValidation{
+				AllowEmpty:        true,
+				AllowExplicitNull: true,
+				DisallowDups:      true,
+				Validator:         validateEIPAllocationIDs,
+			},
+		},
+		{
+			StructField: "APILoadBalancerPrivateLink",
+			StructValidation: &cr.StructValidation{
+				DefaultNil:        true,
+				AllowExplicitNull: true,
+				StructFieldValidations: []*cr.StructFieldValidation{
+					{
+						StructField: "AllowedPrincipals",
+						StringListValidation: &cr.StringListValidation{
+							AllowEmpty:        true,
+							AllowExplicitNull: true,
+							DisallowDups:      true,
+							Validator:         validatePrivateLinkPrincipals,
+						},
+					},
+					{
+						StructField: "AcceptanceRequired",
+						BoolValidation: &cr.BoolValidation{
+							Default: true,
+						},
+					},
+				},
+			},
+		},
+		{
+			StructField: "OperatorReplicas",
+			Int64Validation: &cr.Int64Validation{
+				Default:              1,
+				GreaterThanOrEqualTo: pointer.Int64(1),
+				LessThanOrEqualTo:    pointer.Int64(5),
+			},
+		},
+		{
+			StructField: "Lightweight",
+			BoolValidation: &cr.BoolValidation{
+				Default: false,
+			},
+		},
+		{
+			StructField: "Provider",
+			StringValidation: &cr.StringValidation{
+				AllowedValues: types.ClusterProviderTypeStrings(),
+				Default:       types.AWSProviderType.String(),
+			},
+			Parser: func(str string) (interface{}, error) {
+				return types.ProviderTypeFromString(str), nil
+			},
+		},
+		{
+			StructField: "GCP",
+			StructValidation: &cr.StructValidation{
+				DefaultNil:        true,
+				AllowExplicitNull: true,
+				StructFieldValidations: []*cr.StructFieldValidation{
+					{
+						StructField: "Project",
+						StringValidation: &cr.StringValidation{
+							Required: true,
+						},
+					},
+					{
+						StructField: "Zone",
+						StringValidation: &cr.StringValidation{
+							Required: true,
+						},
+					},
+					{
+						StructField: "ServiceAccount",
+						StringValidation: &cr.StringValidation{
+							Required:  true,
+							Validator: validateGCPServiceAccount,
+						},
+					},
+					{
+						StructField: "ImageRegistry",
+						StringPtrValidation: &cr.StringPtrValidation{
+							Validator: validateGCPImageRegistry,
+						},
+					},
+					{
+						StructField: "MachineType",
+						StringValidation: &cr.StringValidation{
+							Required: true,
+						},
+					},
+					{
+						StructField:         "Accelerator",
+						StringPtrValidation: &cr.StringPtrValidation{},
+					},
+				},
+			},
+		},
+		{
+			StructField: "NetworkingBackend",
+			StringValidation: &cr.StringValidation{
+				AllowedValues: NetworkingBackendStrings(),
+				Default:       IstioNetworkingBackend.String(),
+			},
+			Parser: func(str string) (interface{}, error) {
+				return NetworkingBackendFromString(str), nil
+			},
+		},
+		{
+			StructField: "IngressClass",
+			StringValidation: &cr.StringValidation{
+				Default: "nginx",
+			},
+		},
+		{
+			StructField: "IngressControllerService",
+			StringValidation: &cr.StringValidation{
+				Default:   "ingress-nginx/ingress-nginx-controller",
+				Validator: validateIngressControllerService,
+			},
+		},
+		{
+			StructField: "APIMTLS",
+			BoolValidation: &cr.BoolValidation{
+				Default: false,
+			},
+		},
+		{
+			StructField: "RequestMonitor",
+			StringValidation: &cr.StringValidation{
+				AllowedValues: RequestMonitorModeStrings(),
+				Default:       SidecarRequestMonitorMode.String(),
+			},
+			Parser: func(str string) (interface{}, error) {
+				return RequestMonitorModeFromString(str), nil
+			},
+		},
+		{
+			StructField: "RequestMonitorCPU",
+			StringValidation: &cr.StringValidation{
+				Default:     "10m",
+				CastNumeric: true,
+				Validator:   validateQuantity,
+			},
+		},
+		{
+			StructField: "RequestMonitorMem",
+			StringValidation: &cr.StringValidation{
+				Default:   "10Mi",
+				Validator: validateQuantity,
+			},
+		},
+		{
+			StructField: "RequestMonitorFlushInterval",
+			Int64Validation: &cr.Int64Validation{
+				Default:       10,
+				AllowedValues: RequestMonitorFlushIntervals, // the periods of high-resolution cloudwatch metrics
+			},
+		},
+		{
+			StructField: "MetricSinks",
+			StringListValidation: &cr.StringListValidation{
+				Default:        []string{CloudWatchMetricSink.String()},
+				CastSingleItem: true,
+				DisallowDups:   true,
+				MinLength:      1,
+				Validator:      validateMetricSinks,
+			},
+		},
+		{
+			StructField:         "StatsDHost",
+			StringPtrValidation: &cr.StringPtrValidation{},
+		},
+		{
+			StructField: "StatsDPort",
+			Int64Validation: &cr.Int64Validation{
+				Default:           8125,
+				GreaterThan:       pointer.Int64(0),
+				LessThanOrEqualTo: pointer.Int64(65535),
+			},
+		},
+		{
+			StructField: "Admins",
+			StringListValidation: &cr.StringListValidation{
+				AllowEmpty:        true,
+				AllowExplicitNull: true,
+				DisallowDups:      true,
+			},
+		},
+		{
+			StructField: "Teams",
+			StructListValidation: &cr.StructListValidation{
+				AllowExplicitNull: true,
+				TreatNullAsEmpty:  true,
+				StructValidation: &cr.StructValidation{
+					StructFieldValidations: []*cr.StructFieldValidation{
+						{
+							StructField: "Name",
+							StringValidation: &cr.StringValidation{
+								Required:                   true,
+								AlphaNumericDashUnderscore: true,
+							},
+						},
+						{
+							StructField: "Members",
+							StringListValidation: &cr.StringListValidation{
+								Required:     true,
+								DisallowDups: true,
+							},
+						},
+						{
+							StructField: "MaxAPIs",
+							Int64PtrValidation: &cr.Int64PtrValidation{
+								GreaterThanOrEqualTo: pointer.Int64(0),
+								AllowExplicitNull:    true,
+							},
+						},
+						{
+							StructField: "MaxReplicas",
+							Int64PtrValidation: &cr.Int64PtrValidation{
+								GreaterThanOrEqualTo: pointer.Int64(0),
+								AllowExplicitNull:    true,
+							},
+						},
+						{
+							StructField: "MaxGPUs",
+							Int64PtrValidation: &cr.Int64PtrValidation{
+								GreaterThanOrEqualTo: pointer.Int64(0),
+								AllowExplicitNull:    true,
+							},
+						},
+						{
+							StructField: "MaxConcurrentJobs",
+							Int64PtrValidation: &cr.Int64PtrValidation{
+								GreaterThan:       pointer.Int64(0),
+								AllowExplicitNull: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			StructField: "APIEnvConfigMaps",
+			StringListValidation: &cr.StringListValidation{
+				AllowEmpty:        true,
+				AllowExplicitNull: true,
+				DisallowDups:      true,
+			},
+		},
+		{
+			StructField: "APIEnvSecrets",
+			StringListValidation: &cr.StringListValidation{
+				AllowEmpty:        true,
+				AllowExplicitNull: true,
+				DisallowDups:      true,
+			},
+		},
+		{
+			StructField: "Notifications",
+			StructValidation: &cr.StructValidation{
+				DefaultNil:        true,
+				AllowExplicitNull: true,
+				StructFieldValidations: []*cr.StructFieldValidation{
+					{
+						StructField: "SlackWebhook",
+						StringPtrValidation: &cr.StringPtrValidation{
+							Prefix: "https://",
+						},
+					},
+					{
+						StructField: "Webhook",
+						StringPtrValidation: &cr.StringPtrValidation{
+							Prefix: "https://",
+						},
+					},
+					{
+						StructField: "SNSTopic",
+						StringPtrValidation: &cr.StringPtrValidation{
+							Prefix: "arn:aws:sns:",
+						},
+					},
+				},
+			},
+		},
+		{
+			StructField: "NodeGroups",
+			StructListValidation: &cr.StructListValidation{
+				AllowExplicitNull: true,
+				TreatNullAsEmpty:  true,
+				StructValidation: &cr.StructValidation{
+					StructFieldValidations: []*cr.StructFieldValidation{
+						{
+							StructField: "Name",
+							StringValidation: &cr.StringValidation{
+								Required:  true,
+								DNS1123:   true,
+								MaxLength: 32,
+							},
+						},
+						{
+							StructField: "InstanceType",
+							StringValidation: &cr.StringValidation{
+								Required:  true,
+								Validator: validateInstanceType,
+							},
+						},
+						{
+							StructField: "MinInstances",
+							Int64Validation: &cr.Int64Validation{
+								Default:              0,
+								GreaterThanOrEqualTo: pointer.Int64(0),
+							},
+						},
+						{
+							StructField: "MaxInstances",
+							Int64Validation: &cr.Int64Validation{
+								Required:    true,
+								GreaterThan: pointer.Int64(0),
+							},
+						},
+						{
+							StructField: "Spot",
+							BoolValidation: &cr.BoolValidation{
+								Default: false,
+							},
+						},
+						{
+							StructField:         "GCPMachineType",
+							StringPtrValidation: &cr.StringPtrValidation{},
+						},
+						{
+							StructField:         "GCPAccelerator",
+							StringPtrValidation: &cr.StringPtrValidation{},
+						},
+					},
+				},
+			},
+		},
+		{
+			StructField: "Overprovisioning",
+			StructValidation: &cr.StructValidation{
+				StructFieldValidations: []*cr.StructFieldValidation{
+					{
+						StructField: "Replicas",
+						Int64Validation: &cr.Int64Validation{
+							Default:              0,
+							GreaterThanOrEqualTo: pointer.Int64(0),
+						},
+					},
+					{
+						StructField: "CPU",
+						StringValidation: &cr.StringValidation{
+							Default:     "1",
+							CastNumeric: true,
+							Validator:   validateQuantity,
+						},
+					},
+					{
+						StructField: "Mem",
+						StringValidation: &cr.StringValidation{
+							Default:   "2Gi",
+							Validator: validateQuantity,
+						},
+					},
+					{
+						StructField: "GPU",
+						Int64Validation: &cr.Int64Validation{
+							Default:              0,
+							GreaterThanOrEqualTo: pointer.Int64(0),
+						},
+					},
+				},
+			},
+		},
+		{
+			StructField: "MaxProjectSize",
+			StringValidation: &cr.StringValidation{
+				Default:   "1Gi",
+				Validator: validateQuantity,
+			},
+		},
+		{
+			StructField: "P2PModelDistribution",
+			BoolValidation: &cr.BoolValidation{
+				Default: false,
+			},
+		},
+		{
+			StructField: "Environment",
+			StringPtrValidation: &cr.StringPtrValidation{
+				AllowExplicitNull:             true,
+				AlphaNumericDashDotUnderscore: true,
+			},
+		},
+		{
+			StructField: "Variables",
+			StringMapValidation: &cr.StringMapValidation{
+				AllowExplicitNull:  true,
+				AllowEmpty:         true,
+				ConvertNullToEmpty: true,
+			},
+		},
 		{
 			StructField: "ImageOperator",
 			StringValidation: &cr.StringValidation{
@@ -344,6 +864,27 @@ var UserValidation = &cr.StructValidation{
 				Validator: validateImageVersion,
 			},
 		},
+		{
+			StructField: "ImageModelOptimizer",
+			StringValidation: &cr.StringValidation{
+				Default:   "cortexlabs/model-optimizer:" + consts.CortexVersion,
+				Validator: validateImageVersion,
+			},
+		},
+		{
+			StructField: "ImageLoadTester",
+			StringValidation: &cr.StringValidation{
+				Default:   "cortexlabs/load-tester:" + consts.CortexVersion,
+				Validator: validateImageVersion,
+			},
+		},
+		{
+			StructField: "ImageKaniko",
+			StringValidation: &cr.StringValidation{
+				Default:   "cortexlabs/kaniko:" + consts.CortexVersion,
+				Validator: validateImageVersion,
+			},
+		},
 		{
 			StructField: "ImageClusterAutoscaler",
 			StringValidation: &cr.StringValidation{
@@ -372,6 +913,13 @@ var UserValidation = &cr.StructValidation{
 				Validator: validateImageVersion,
 			},
 		},
+		{
+			StructField: "ImageNeuronMonitor",
+			StringValidation: &cr.StringValidation{
+				Default:   "cortexlabs/neuron-monitor:" + consts.CortexVersion,
+				Validator: validateImageVersion,
+			},
+		},
 		{
 			StructField: "ImageNvidia",
 			StringValidation: &cr.StringValidation{
@@ -379,6 +927,13 @@ var UserValidation = &cr.StructValidation{
 				Validator: validateImageVersion,
 			},
 		},
+		{
+			StructField: "ImageDCGMExporter",
+			StringValidation: &cr.StringValidation{
+				Default:   "cortexlabs/dcgm-exporter:" + consts.CortexVersion,
+				Validator: validateImageVersion,
+			},
+		},
 		{
 			StructField: "ImageFluentd",
 			StringValidation: &cr.StringValidation{
@@ -393,6 +948,13 @@ var UserValidation = &cr.StructValidation{
 				Validator: validateImageVersion,
 			},
 		},
+		{
+			StructField: "ImagePause",
+			StringValidation: &cr.StringValidation{
+				Default:   "cortexlabs/pause:" + consts.CortexVersion,
+				Validator: validateImageVersion,
+			},
+		},
 		{
 			StructField: "ImageIstioProxy",
 			StringValidation: &cr.StringValidation{
@@ -510,6 +1072,18 @@ func (cc *Config) ToAccessConfig() AccessConfig {
 func (cc *Config) Validate(awsClient *aws.Client) error {
 	fmt.Print("verifying your configuration ...\n\n")
 
+	if cc.Lightweight {
+		return ErrorLightweightClusterUp()
+	}
+
+	if cc.Provider == types.GCPProviderType {
+		return ErrorGCPClusterUp()
+	}
+
+	if err := cc.ValidateGCP(); err != nil {
+		return err
+	}
+
 	if *cc.MinInstances > *cc.MaxInstances {
 		return ErrorMinInstancesGreaterThanMax(*cc.MinInstances, *cc.MaxInstances)
 	}
@@ -518,6 +1092,33 @@ func (cc *Config) Validate(awsClient *aws.Client) error {
 		return ErrorNATRequiredWithPrivateSubnetVisibility()
 	}
 
+	if cc.APIMTLS && cc.NetworkingBackend != IstioNetworkingBackend {
+		return ErrorMTLSRequiresIstio()
+	}
+
+	if len(cc.APILoadBalancerEIPs) > 0 && cc.APILoadBalancerScheme != InternetFacingLoadBalancerScheme {
+		return ErrorEIPsRequireInternetFacing()
+	}
+
+	if err := cc.validateMetricSinks(); err != nil {
+		return err
+	}
+
+	if (len(cc.APILoadBalancerEIPs) > 0 || cc.APILoadBalancerPrivateLink != nil) && cc.NetworkingBackend != IstioNetworkingBackend {
+		if len(cc.APILoadBalancerEIPs) > 0 {
+			return ErrorRequiresIstioLoadBalancer(APILoadBalancerEIPsKey)
+		}
+		return ErrorRequiresIstioLoadBalancer(APILoadBalancerPrivateLinkKey)
+	}
+
+	if err := cc.validateTeams(); err != nil {
+		return err
+	}
+
+	if err := cc.validateNodeGroups(); err != nil {
+		return err
+	}
+
 	if cc.Bucket == "" {
 		accountID, _, err := awsClient.GetCachedAccountID()
 		if err != nil {
@@ -535,7 +1136,7 @@ func (cc *Config) Validate(awsClient *aws.Client) error {
 		}
 
 		cc.Bucket = defaultBucket
-	} else {
+	} else if cc.S3Endpoint == nil {
 		bucketRegion, _ := aws.GetBucketRegion(cc.Bucket)
 		if bucketRegion != "" && bucketRegion != *cc.Region { // if the bucket didn't exist, we will create it in the correct region, so there is no error
 			return ErrorS3RegionDiffersFromCluster(cc.Bucket, bucketRegion, *cc.Region)
@@ -547,6 +1148,10 @@ func (cc *Config) Validate(awsClient *aws.Client) error {
 		return errors.Wrap(ErrorInstanceTypeNotSupportedInRegion(primaryInstanceType, *cc.Region), InstanceTypeKey)
 	}
 
+	if err := cc.validateOverprovisioning(); err != nil {
+		return errors.Wrap(err, OverprovisioningKey)
+	}
+
 	if cc.SSLCertificateARN != nil {
 		exists, err := awsClient.DoesCertificateExist(*cc.SSLCertificateARN)
 		if err != nil {
@@ -589,6 +1194,10 @@ func (cc *Config) Validate(awsClient *aws.Client) error {
 		return errors.Wrap(err, AvailabilityZonesKey)
 	}
 
+	if err := cc.validateAPILoadBalancerEIPs(awsClient); err != nil {
+		return errors.Wrap(err, APILoadBalancerEIPsKey)
+	}
+
 	if cc.Spot != nil && *cc.Spot {
 		cc.FillEmptySpotFields(awsClient)
 
@@ -936,6 +1545,15 @@ func validateClusterName(clusterName string) (string, error) {
 	return clusterName, nil
 }
 
+// the ingress controller's service is specified as <namespace>/<name>
+func validateIngressControllerService(service string) (string, error) {
+	split := strings.Split(service, "/")
+	if len(split) != 2 || split[0] == "" || split[1] == "" {
+		return "", ErrorInvalidIngressControllerService(service)
+	}
+	return service, nil
+}
+
 func validateBucketNameOrEmpty(bucket string) (string, error) {
 	if bucket == "" {
 		return "", nil
@@ -1033,6 +1651,9 @@ func (cc *Config) UserTable() table.KeyValuePairs {
 		items.Add(AvailabilityZonesUserKey, cc.AvailabilityZones)
 	}
 	items.Add(BucketUserKey, cc.Bucket)
+	if cc.S3Endpoint != nil {
+		items.Add(S3EndpointUserKey, *cc.S3Endpoint)
+	}
 	items.Add(InstanceTypeUserKey, *cc.InstanceType)
 	items.Add(MinInstancesUserKey, *cc.MinInstances)
 	items.Add(MaxInstancesUserKey, *cc.MaxInstances)
@@ -1054,22 +1675,110 @@ func (cc *Config) UserTable() table.KeyValuePairs {
 		items.Add(OnDemandBackupUserKey, s.YesNo(*cc.SpotConfig.OnDemandBackup))
 	}
 	items.Add(LogGroupUserKey, cc.LogGroup)
+	items.Add(MetadataStoreUserKey, cc.MetadataStore)
+	if cc.MetadataStore == DynamoDBMetadataStoreType {
+		items.Add(MetadataTableUserKey, cc.MetadataTable)
+	}
 	items.Add(SubnetVisibilityUserKey, cc.SubnetVisibility)
 	items.Add(NATGatewayUserKey, cc.NATGateway)
 	items.Add(APILoadBalancerSchemeUserKey, cc.APILoadBalancerScheme)
 	items.Add(OperatorLoadBalancerSchemeUserKey, cc.OperatorLoadBalancerScheme)
+	if len(cc.APILoadBalancerEIPs) > 0 {
+		items.Add(APILoadBalancerEIPsUserKey, cc.APILoadBalancerEIPs)
+	}
+	if cc.APILoadBalancerPrivateLink != nil {
+		items.Add(PrivateLinkPrincipalsUserKey, cc.APILoadBalancerPrivateLink.AllowedPrincipals)
+		items.Add(PrivateLinkAcceptanceUserKey, s.YesNo(cc.APILoadBalancerPrivateLink.AcceptanceRequired))
+	}
+	items.Add(OperatorReplicasUserKey, cc.OperatorReplicas)
+	if cc.Lightweight {
+		items.Add(LightweightUserKey, s.YesNo(cc.Lightweight))
+	}
+	items.Add(ProviderUserKey, cc.Provider)
+	if cc.GCP != nil {
+		items.Add(GCPProjectUserKey, cc.GCP.Project)
+		items.Add(GCPZoneUserKey, cc.GCP.Zone)
+		items.Add(GCPServiceAccountUserKey, cc.GCP.ServiceAccount)
+		if cc.GCP.ImageRegistry != nil {
+			items.Add(GCPImageRegistryUserKey, *cc.GCP.ImageRegistry)
+		}
+		items.Add(GCPMachineTypeUserKey, cc.GCP.MachineType)
+		if cc.GCP.Accelerator != nil {
+			items.Add(GCPAcceleratorUserKey, *cc.GCP.Accelerator)
+		}
+	}
+	items.Add(NetworkingBackendUserKey, cc.NetworkingBackend)
+	if cc.NetworkingBackend == IngressNetworkingBackend {
+		items.Add(IngressClassUserKey, cc.IngressClass)
+		items.Add(IngressControllerServiceUserKey, cc.IngressControllerService)
+	}
+	items.Add(APIMTLSUserKey, s.YesNo(cc.APIMTLS))
+	items.Add(RequestMonitorUserKey, cc.RequestMonitor)
+	if cc.RequestMonitor == SidecarRequestMonitorMode {
+		items.Add(RequestMonitorCPUUserKey, cc.RequestMonitorCPU)
+		items.Add(RequestMonitorMemUserKey, cc.RequestMonitorMem)
+	}
+	items.Add(RequestMonitorFlushIntervalUserKey, s.Int64(cc.RequestMonitorFlushInterval)+"s")
+	items.Add(MetricSinksUserKey, s.StrsAnd(cc.MetricSinks))
+	if cc.HasMetricSink(StatsDMetricSink) {
+		if cc.StatsDHost != nil {
+			items.Add(StatsDHostUserKey, *cc.StatsDHost)
+		}
+		items.Add(StatsDPortUserKey, cc.StatsDPort)
+	}
+	if len(cc.Teams) > 0 {
+		items.Add(AdminsUserKey, cc.Admins)
+		teamNames := make([]string, len(cc.Teams))
+		for i, team := range cc.Teams {
+			teamNames[i] = team.Name
+		}
+		items.Add(TeamsUserKey, teamNames)
+	}
+	if len(cc.APIEnvConfigMaps) > 0 {
+		items.Add(APIEnvConfigMapsUserKey, cc.APIEnvConfigMaps)
+	}
+	if len(cc.APIEnvSecrets) > 0 {
+		items.Add(APIEnvSecretsUserKey, cc.APIEnvSecrets)
+	}
+	if cc.Notifications != nil {
+		items.Add(NotificationsUserKey, cc.Notifications.UserStr())
+	}
+	if len(cc.NodeGroups) > 0 {
+		nodeGroupNames := make([]string, len(cc.NodeGroups))
+		for i, nodeGroup := range cc.NodeGroups {
+			nodeGroupNames[i] = nodeGroup.Name
+		}
+		items.Add(NodeGroupsUserKey, nodeGroupNames)
+	}
+	if cc.Overprovisioning != nil && cc.Overprovisioning.Replicas > 0 {
+		items.Add(OverprovisioningUserKey, cc.Overprovisioning.UserStr())
+	}
+	items.Add(MaxProjectSizeUserKey, cc.MaxProjectSize)
+	items.Add(P2PModelDistributionUserKey, s.YesNo(cc.P2PModelDistribution))
+	if cc.Environment != nil {
+		items.Add(EnvironmentUserKey, *cc.Environment)
+	}
+	if len(cc.Variables) > 0 {
+		items.Add(VariablesUserKey, s.ObjFlat(cc.Variables))
+	}
 	items.Add(TelemetryUserKey, cc.Telemetry)
 	items.Add(ImageOperatorUserKey, cc.ImageOperator)
 	items.Add(ImageManagerUserKey, cc.ImageManager)
 	items.Add(ImageDownloaderUserKey, cc.ImageDownloader)
 	items.Add(ImageRequestMonitorUserKey, cc.ImageRequestMonitor)
+	items.Add(ImageModelOptimizerUserKey, cc.ImageModelOptimizer)
+	items.Add(ImageLoadTesterUserKey, cc.ImageLoadTester)
+	items.Add(ImageKanikoUserKey, cc.ImageKaniko)
 	items.Add(ImageClusterAutoscalerUserKey, cc.ImageClusterAutoscaler)
 	items.Add(ImageMetricsServerUserKey, cc.ImageMetricsServer)
 	items.Add(ImageInferentiaUserKey, cc.ImageInferentia)
 	items.Add(ImageNeuronRTDUserKey, cc.ImageNeuronRTD)
+	items.Add(ImageNeuronMonitorUserKey, cc.ImageNeuronMonitor)
 	items.Add(ImageNvidiaUserKey, cc.ImageNvidia)
+	items.Add(ImageDCGMExporterUserKey, cc.ImageDCGMExporter)
 	items.Add(ImageFluentdUserKey, cc.ImageFluentd)
 	items.Add(ImageStatsdUserKey, cc.ImageStatsd)
+	items.Add(ImagePauseUserKey, cc.ImagePause)
 	items.Add(ImageIstioProxyUserKey, cc.ImageIstioProxy)
 	items.Add(ImageIstioPilotUserKey, cc.ImageIstioPilot)
 	items.Add(ImageIstioCitadelUserKey, cc.ImageIstioCitadel)
@@ -37,23 +37,83 @@ const (
 	AvailabilityZonesKey                   = "availability_zones"
 	SSLCertificateARNKey                   = "ssl_certificate_arn"
 	BucketKey                              = "bucket"
+	S3EndpointKey                          = "s3_endpoint"
 	LogGroupKey                            = "log_group"
+	MetadataStoreKey                       = "metadata_store"
+	MetadataTableKey                       = "metadata_table"
 	SubnetVisibilityKey                    = "subnet_visibility"
 	NATGatewayKey                          = "nat_gateway"
 	APILoadBalancerSchemeKey               = "api_load_balancer_scheme"
 	OperatorLoadBalancerSchemeKey          = "operator_load_balancer_scheme"
+	APILoadBalancerEIPsKey                 = "api_load_balancer_eip_allocations"
+	APILoadBalancerPrivateLinkKey          = "api_load_balancer_private_link"
+	AllowedPrincipalsKey                   = "allowed_principals"
+	AcceptanceRequiredKey                  = "acceptance_required"
+	OperatorReplicasKey                    = "operator_replicas"
+	LightweightKey                         = "lightweight"
+	ProviderKey                            = "provider"
+	GCPKey                                 = "gcp"
+	GCPProjectKey                          = "project"
+	GCPZoneKey                             = "zone"
+	GCPServiceAccountKey                   = "service_account"
+	GCPImageRegistryKey                    = "image_registry"
+	GCPMachineTypeKey                      = "machine_type"
+	GCPAcceleratorKey                      = "accelerator"
+	NodeGroupGCPMachineTypeKey             = "gcp_machine_type"
+	NetworkingBackendKey                   = "networking_backend"
+	IngressClassKey                        = "ingress_class"
+	IngressControllerServiceKey            = "ingress_controller_service"
+	APIMTLSKey                             = "api_mtls"
+	RequestMonitorKey                      = "request_monitor"
+	RequestMonitorCPUKey                   = "request_monitor_cpu"
+	RequestMonitorMemKey                   = "request_monitor_mem"
+	RequestMonitorFlushIntervalKey         = "request_monitor_flush_interval"
+	MetricSinksKey                         = "metric_sinks"
+	StatsDHostKey                          = "statsd_host"
+	StatsDPortKey                          = "statsd_port"
+	AdminsKey                              = "admins"
+	TeamsKey                               = "teams"
+	TeamNameKey                            = "name"
+	TeamMembersKey                         = "members"
+	MaxAPIsKey                             = "max_apis"
+	MaxReplicasKey                         = "max_replicas"
+	MaxGPUsKey                             = "max_gpus"
+	MaxConcurrentJobsKey                   = "max_concurrent_jobs"
+	APIEnvConfigMapsKey                    = "api_env_config_maps"
+	APIEnvSecretsKey                       = "api_env_secrets"
+	NotificationsKey                       = "notifications"
+	SlackWebhookKey                        = "slack_webhook"
+	WebhookKey                             = "webhook"
+	SNSTopicKey                            = "sns_topic"
+	NodeGroupsKey                          = "node_groups"
+	NodeGroupNameKey                       = "name"
+	OverprovisioningKey                    = "overprovisioning"
+	ReplicasKey                            = "replicas"
+	CPUKey                                 = "cpu"
+	MemKey                                 = "mem"
+	GPUKey                                 = "gpu"
+	MaxProjectSizeKey                      = "max_project_size"
+	P2PModelDistributionKey                = "p2p_model_distribution"
+	EnvironmentKey                         = "environment"
+	VariablesKey                           = "variables"
 	TelemetryKey                           = "telemetry"
 	ImageOperatorKey                       = "image_operator"
 	ImageManagerKey                        = "image_manager"
 	ImageDownloaderKey                     = "image_downloader"
 	ImageRequestMonitorKey                 = "image_request_monitor"
+	ImageModelOptimizerKey                 = "image_model_optimizer"
+	ImageLoadTesterKey                     = "image_load_tester"
+	ImageKanikoKey                         = "image_kaniko"
 	ImageClusterAutoscalerKey              = "image_cluster_autoscaler"
 	ImageMetricsServerKey                  = "image_metrics_server"
 	ImageInferentiaKey                     = "image_inferentia"
 	ImageNeuronRTDKey                      = "image_neuron_rtd"
+	ImageNeuronMonitorKey                  = "image_neuron_monitor"
 	ImageNvidiaKey                         = "image_nvidia"
+	ImageDCGMExporterKey                   = "image_dcgm_exporter"
 	ImageFluentdKey                        = "image_fluentd"
 	ImageStatsdKey                         = "image_statsd"
+	ImagePauseKey                          = "image_pause"
 	ImageIstioProxyKey                     = "image_istio_proxy"
 	ImageIstioPilotKey                     = "image_istio_pilot"
 	ImageIstioCitadelKey                   = "image_istio_citadel"
@@ -66,6 +126,7 @@ const (
 	AvailabilityZonesUserKey                   = "availability zones"
 	SSLCertificateARNUserKey                   = "ssl certificate arn"
 	BucketUserKey                              = "s3 bucket"
+	S3EndpointUserKey                          = "s3 endpoint"
 	SpotUserKey                                = "use spot instances"
 	InstanceTypeUserKey                        = "instance type"
 	MinInstancesUserKey                        = "min instances"
@@ -81,22 +142,64 @@ const (
 	InstancePoolsUserKey                       = "spot instance pools"
 	OnDemandBackupUserKey                      = "on demand backup"
 	LogGroupUserKey                            = "cloudwatch log group"
+	MetadataStoreUserKey                       = "metadata store"
+	MetadataTableUserKey                       = "metadata dynamodb table"
 	SubnetVisibilityUserKey                    = "subnet visibility"
 	NATGatewayUserKey                          = "nat gateway"
 	APILoadBalancerSchemeUserKey               = "api load balancer scheme"
 	OperatorLoadBalancerSchemeUserKey          = "operator load balancer scheme"
+	APILoadBalancerEIPsUserKey                 = "api load balancer elastic ips"
+	PrivateLinkPrincipalsUserKey               = "api load balancer privatelink principals"
+	PrivateLinkAcceptanceUserKey               = "api load balancer privatelink acceptance required"
+	OperatorReplicasUserKey                    = "operator replicas"
+	LightweightUserKey                         = "lightweight"
+	ProviderUserKey                            = "provider"
+	GCPProjectUserKey                          = "gcp project"
+	GCPZoneUserKey                             = "gcp zone"
+	GCPServiceAccountUserKey                   = "gcp service account"
+	GCPImageRegistryUserKey                    = "gcp image registry"
+	GCPMachineTypeUserKey                      = "gcp machine type"
+	GCPAcceleratorUserKey                      = "gcp accelerator"
+	NetworkingBackendUserKey                   = "networking backend"
+	IngressClassUserKey                        = "ingress class"
+	IngressControllerServiceUserKey            = "ingress controller service"
+	APIMTLSUserKey                             = "api mtls"
+	RequestMonitorUserKey                      = "request monitor"
+	RequestMonitorCPUUserKey                   = "request monitor cpu"
+	RequestMonitorMemUserKey                   = "request monitor memory"
+	RequestMonitorFlushIntervalUserKey         = "request monitor flush interval"
+	MetricSinksUserKey                         = "metric sinks"
+	StatsDHostUserKey                          = "statsd host"
+	StatsDPortUserKey                          = "statsd port"
+	AdminsUserKey                              = "admins"
+	TeamsUserKey                               = "teams"
+	APIEnvConfigMapsUserKey                    = "api env config maps"
+	APIEnvSecretsUserKey                       = "api env secrets"
+	NotificationsUserKey                       = "notifications"
+	NodeGroupsUserKey                          = "node groups"
+	OverprovisioningUserKey                    = "overprovisioning replicas"
+	MaxProjectSizeUserKey                      = "max project size"
+	P2PModelDistributionUserKey                = "p2p model distribution"
+	EnvironmentUserKey                         = "environment"
+	VariablesUserKey                           = "variables"
 	TelemetryUserKey                           = "telemetry"
 	ImageOperatorUserKey                       = "operator image"
 	ImageManagerUserKey                        = "manager image"
 	ImageDownloaderUserKey                     = "downloader image"
 	ImageRequestMonitorUserKey                 = "request monitor image"
+	ImageModelOptimizerUserKey                 = "model optimizer image"
+	ImageLoadTesterUserKey                     = "load tester image"
+	ImageKanikoUserKey                         = "kaniko image"
 	ImageClusterAutoscalerUserKey              = "cluster autoscaler image"
 	ImageMetricsServerUserKey                  = "metrics server image"
 	ImageInferentiaUserKey                     = "inferentia image"
 	ImageNeuronRTDUserKey                      = "neuron rtd image"
+	ImageNeuronMonitorUserKey                  = "neuron monitor image"
 	ImageNvidiaUserKey                         = "nvidia image"
+	ImageDCGMExporterUserKey                   = "dcgm exporter image"
 	ImageFluentdUserKey                        = "fluentd image"
 	ImageStatsdUserKey                         = "statsd image"
+	ImagePauseUserKey                          = "pause image"
 	ImageIstioProxyUserKey                     = "istio proxy image"
 	ImageIstioPilotUserKey                     = "istio pilot image"
 	ImageIstioCitadelUserKey                   = "istio citadel image"
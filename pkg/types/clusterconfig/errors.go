@@ -24,6 +24,7 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/types"
 )
 
 const (
@@ -54,6 +55,34 @@ const (
 	ErrIOPSTooLarge                           = "clusterconfig.iops_too_large"
 	ErrCantOverrideDefaultTag                 = "clusterconfig.cant_override_default_tag"
 	ErrSSLCertificateARNNotFound              = "clusterconfig.ssl_certificate_arn_not_found"
+	ErrAdminsRequiredWithTeams                = "clusterconfig.admins_required_with_teams"
+	ErrDuplicateTeamName                      = "clusterconfig.duplicate_team_name"
+	ErrMemberInMultipleTeams                  = "clusterconfig.member_in_multiple_teams"
+	ErrDuplicateNodeGroupName                 = "clusterconfig.duplicate_node_group_name"
+	ErrInvalidQuantity                        = "clusterconfig.invalid_quantity"
+	ErrOverprovisioningExceedsInstance        = "clusterconfig.overprovisioning_exceeds_instance"
+	ErrInvalidIngressControllerService        = "clusterconfig.invalid_ingress_controller_service"
+	ErrMTLSRequiresIstio                      = "clusterconfig.mtls_requires_istio"
+	ErrLightweightClusterUp                   = "clusterconfig.lightweight_cluster_up"
+	ErrLightweightRequiresField               = "clusterconfig.lightweight_requires_field"
+	ErrLightweightRequiresValue               = "clusterconfig.lightweight_requires_value"
+	ErrLightweightUnsupportedField            = "clusterconfig.lightweight_unsupported_field"
+	ErrGCPClusterUp                           = "clusterconfig.gcp_cluster_up"
+	ErrGCPRequiresField                       = "clusterconfig.gcp_requires_field"
+	ErrGCPRequiresValue                       = "clusterconfig.gcp_requires_value"
+	ErrGCPUnsupportedField                    = "clusterconfig.gcp_unsupported_field"
+	ErrGCPUnsupportedValue                    = "clusterconfig.gcp_unsupported_value"
+	ErrFieldRequiresGCPProvider               = "clusterconfig.field_requires_gcp_provider"
+	ErrInvalidGCPServiceAccount               = "clusterconfig.invalid_gcp_service_account"
+	ErrInvalidGCPImageRegistry                = "clusterconfig.invalid_gcp_image_registry"
+	ErrInvalidEIPAllocationID                 = "clusterconfig.invalid_eip_allocation_id"
+	ErrEIPNotFound                            = "clusterconfig.eip_not_found"
+	ErrEIPsRequireInternetFacing              = "clusterconfig.eips_require_internet_facing"
+	ErrEIPCountMismatch                       = "clusterconfig.eip_count_mismatch"
+	ErrRequiresIstioLoadBalancer              = "clusterconfig.requires_istio_load_balancer"
+	ErrInvalidPrivateLinkPrincipal            = "clusterconfig.invalid_private_link_principal"
+	ErrInvalidMetricSink                      = "clusterconfig.invalid_metric_sink"
+	ErrStatsDPortConflict                     = "clusterconfig.statsd_port_conflict"
 )
 
 func ErrorInvalidRegion(region string) error {
@@ -249,3 +278,199 @@ func ErrorSSLCertificateARNNotFound(sslCertificateARN string, region string) err
 		Message: fmt.Sprintf("unable to find the specified ssl certificate in region %s: %s", region, sslCertificateARN),
 	})
 }
+
+func ErrorAdminsRequiredWithTeams() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrAdminsRequiredWithTeams,
+		Message: fmt.Sprintf("at least one admin must be specified in `%s` when `%s` are configured", AdminsKey, TeamsKey),
+	})
+}
+
+func ErrorDuplicateTeamName(teamName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDuplicateTeamName,
+		Message: fmt.Sprintf("multiple teams are named %s", teamName),
+	})
+}
+
+func ErrorMemberInMultipleTeams(member string, team1 string, team2 string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrMemberInMultipleTeams,
+		Message: fmt.Sprintf("%s cannot be a member of multiple teams (it is a member of both %s and %s)", member, team1, team2),
+	})
+}
+
+func ErrorDuplicateNodeGroupName(nodeGroupName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDuplicateNodeGroupName,
+		Message: fmt.Sprintf("multiple node groups are named %s", nodeGroupName),
+	})
+}
+
+func ErrorInvalidQuantity(quantity string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidQuantity,
+		Message: fmt.Sprintf("%s is not a valid quantity (e.g. 500m, 1, 2Gi)", s.UserStr(quantity)),
+	})
+}
+
+func ErrorOverprovisioningExceedsInstance(resource string, requested string, instanceType string, available string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrOverprovisioningExceedsInstance,
+		Message: fmt.Sprintf("placeholder pods cannot request more %s than is available on a single %s instance (requested %s, %s available)", resource, instanceType, requested, available),
+	})
+}
+
+func ErrorInvalidIngressControllerService(service string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidIngressControllerService,
+		Message: fmt.Sprintf("%s is not a valid ingress controller service; it must be specified as <namespace>/<name> (e.g. ingress-nginx/ingress-nginx-controller)", s.UserStr(service)),
+	})
+}
+
+func ErrorMTLSRequiresIstio() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrMTLSRequiresIstio,
+		Message: fmt.Sprintf("`%s: true` requires `%s: %s`", APIMTLSKey, NetworkingBackendKey, IstioNetworkingBackend),
+	})
+}
+
+func ErrorLightweightClusterUp() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrLightweightClusterUp,
+		Message: fmt.Sprintf("clusters with `%s: true` are installed onto an existing kubernetes cluster (e.g. kind or minikube) with `dev/lightweight.sh`, rather than with `cortex cluster up`", LightweightKey),
+	})
+}
+
+func ErrorLightweightRequiresField(key string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrLightweightRequiresField,
+		Message: fmt.Sprintf("`%s: true` requires %s to be specified", LightweightKey, key),
+	})
+}
+
+func ErrorLightweightRequiresValue(key string, value string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrLightweightRequiresValue,
+		Message: fmt.Sprintf("`%s: true` requires `%s: %s`", LightweightKey, key, value),
+	})
+}
+
+func ErrorLightweightUnsupportedField(key string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrLightweightUnsupportedField,
+		Message: fmt.Sprintf("%s is not supported when `%s: true`", key, LightweightKey),
+	})
+}
+
+func ErrorGCPClusterUp() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrGCPClusterUp,
+		Message: fmt.Sprintf("clusters with `%s: %s` are installed onto an existing GKE cluster with `dev/gke.sh`, rather than with `cortex cluster up`", ProviderKey, types.GCPProviderType.String()),
+	})
+}
+
+func ErrorGCPRequiresField(key string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrGCPRequiresField,
+		Message: fmt.Sprintf("`%s: %s` requires %s to be specified", ProviderKey, types.GCPProviderType.String(), key),
+	})
+}
+
+func ErrorGCPRequiresValue(key string, value string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrGCPRequiresValue,
+		Message: fmt.Sprintf("`%s: %s` requires `%s: %s`", ProviderKey, types.GCPProviderType.String(), key, value),
+	})
+}
+
+func ErrorGCPUnsupportedField(key string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrGCPUnsupportedField,
+		Message: fmt.Sprintf("%s is not supported when `%s: %s`", key, ProviderKey, types.GCPProviderType.String()),
+	})
+}
+
+func ErrorGCPUnsupportedValue(key string, value string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrGCPUnsupportedValue,
+		Message: fmt.Sprintf("%s can't include %s when `%s: %s`", key, value, ProviderKey, types.GCPProviderType.String()),
+	})
+}
+
+func ErrorFieldRequiresGCPProvider(key string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrFieldRequiresGCPProvider,
+		Message: fmt.Sprintf("%s is only supported when `%s: %s`", key, ProviderKey, types.GCPProviderType.String()),
+	})
+}
+
+func ErrorInvalidGCPServiceAccount(serviceAccount string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidGCPServiceAccount,
+		Message: fmt.Sprintf("%s is not a valid google service account; it must be the service account's email (e.g. cortex@my-project.iam.gserviceaccount.com)", s.UserStr(serviceAccount)),
+	})
+}
+
+func ErrorInvalidGCPImageRegistry(imageRegistry string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidGCPImageRegistry,
+		Message: fmt.Sprintf("%s is not a Container Registry or Artifact Registry repository (e.g. gcr.io/my-project or us-central1-docker.pkg.dev/my-project/my-repository)", s.UserStr(imageRegistry)),
+	})
+}
+
+func ErrorInvalidEIPAllocationID(allocationID string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidEIPAllocationID,
+		Message: fmt.Sprintf("%s is not an elastic ip allocation id (allocation ids begin with \"eipalloc-\")", allocationID),
+	})
+}
+
+func ErrorEIPNotFound(allocationID string, region string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrEIPNotFound,
+		Message: fmt.Sprintf("elastic ip allocation %s does not exist in %s", allocationID, region),
+	})
+}
+
+func ErrorEIPsRequireInternetFacing() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrEIPsRequireInternetFacing,
+		Message: fmt.Sprintf("%s can only be specified when `%s: %s` (internal load balancers use private ip addresses)", APILoadBalancerEIPsKey, APILoadBalancerSchemeKey, InternetFacingLoadBalancerScheme),
+	})
+}
+
+func ErrorEIPCountMismatch(numEIPs int, zones []string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrEIPCountMismatch,
+		Message: fmt.Sprintf("%d elastic ip allocations were specified, but the api load balancer requires exactly one per availability zone (the cluster's availability zones are %s)", numEIPs, s.StrsAnd(zones)),
+	})
+}
+
+func ErrorRequiresIstioLoadBalancer(key string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrRequiresIstioLoadBalancer,
+		Message: fmt.Sprintf("%s is only supported when `%s: %s` (with %s, the api load balancer is managed by your ingress controller)", key, NetworkingBackendKey, IstioNetworkingBackend, IngressNetworkingBackend),
+	})
+}
+
+func ErrorInvalidPrivateLinkPrincipal(principal string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidPrivateLinkPrincipal,
+		Message: fmt.Sprintf("%s is not a valid principal; principals must be IAM ARNs (e.g. arn:aws:iam::123456789012:root to allow all users and roles in an account), or \"*\" to allow all principals", principal),
+	})
+}
+
+func ErrorInvalidMetricSink(sink string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidMetricSink,
+		Message: fmt.Sprintf("invalid value for %s: %s (valid values are %s)", MetricSinksKey, sink, s.StrsOr(MetricSinkStrings())),
+	})
+}
+
+func ErrorStatsDPortConflict(port int64) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrStatsDPortConflict,
+		Message: fmt.Sprintf("port %d of each node is used by the cloudwatch metric sink's statsd agent; specify %s or a different %s for the statsd metric sink", port, StatsDHostKey, StatsDPortKey),
+	})
+}
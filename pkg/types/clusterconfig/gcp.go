@@ -0,0 +1,128 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterconfig
+
+import (
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/regex"
+	"github.com/cortexlabs/cortex/pkg/types"
+)
+
+// ValidateGCP validates the configuration of a cluster on GCP, which is installed onto an existing GKE cluster (see
+// dev/gke.sh): the cluster's bucket is stored in GCS and its metadata in GCS or postgres, cortex's pods authenticate as
+// the google service account with Workload Identity (rather than with the aws-credentials secret), APIs are routed by
+// the cluster's ingress controller, and the worker nodes and node groups are GKE node pools (region, instance_type, and each node group's
+// instance_type describe the capacity of the node pools' machine types, which APIs' compute requests are validated against)
+func (cc *Config) ValidateGCP() error {
+	if cc.Provider != types.GCPProviderType {
+		if cc.GCP != nil {
+			return ErrorFieldRequiresGCPProvider(GCPKey)
+		}
+		if cc.MetadataStore == GCSMetadataStoreType {
+			return ErrorFieldRequiresGCPProvider(MetadataStoreKey + ": " + GCSMetadataStoreType.String())
+		}
+		for _, nodeGroup := range cc.NodeGroups {
+			if nodeGroup.GCPMachineType != nil || nodeGroup.GCPAccelerator != nil {
+				return ErrorFieldRequiresGCPProvider(NodeGroupGCPMachineTypeKey)
+			}
+		}
+		return nil
+	}
+
+	if cc.GCP == nil {
+		return ErrorGCPRequiresField(GCPKey)
+	}
+
+	if cc.Lightweight {
+		return ErrorGCPUnsupportedField(LightweightKey)
+	}
+
+	if cc.NetworkingBackend != IngressNetworkingBackend {
+		return ErrorGCPRequiresValue(NetworkingBackendKey, IngressNetworkingBackend.String())
+	}
+
+	if cc.MetadataStore != GCSMetadataStoreType && cc.MetadataStore != PostgresMetadataStoreType {
+		return ErrorGCPUnsupportedValue(MetadataStoreKey, cc.MetadataStore.String())
+	}
+
+	if cc.Bucket == "" {
+		return ErrorGCPRequiresField(BucketKey)
+	}
+
+	if cc.Region == nil {
+		return ErrorGCPRequiresField(RegionKey)
+	}
+
+	if cc.InstanceType == nil {
+		return ErrorGCPRequiresField(InstanceTypeKey)
+	}
+
+	if cc.S3Endpoint != nil {
+		return ErrorGCPUnsupportedField(S3EndpointKey)
+	}
+
+	if cc.HasMetricSink(CloudWatchMetricSink) {
+		return ErrorGCPUnsupportedValue(MetricSinksKey, CloudWatchMetricSink.String())
+	}
+
+	// spot node pools are configured per node group
+	if cc.Spot != nil && *cc.Spot {
+		return ErrorGCPUnsupportedField(SpotKey)
+	}
+
+	for _, nodeGroup := range cc.NodeGroups {
+		if nodeGroup.GCPMachineType == nil {
+			return ErrorGCPRequiresField(NodeGroupsKey + "." + nodeGroup.Name + "." + NodeGroupGCPMachineTypeKey)
+		}
+	}
+
+	if len(cc.APILoadBalancerEIPs) > 0 {
+		return ErrorGCPUnsupportedField(APILoadBalancerEIPsKey)
+	}
+
+	if cc.APILoadBalancerPrivateLink != nil {
+		return ErrorGCPUnsupportedField(APILoadBalancerPrivateLinkKey)
+	}
+
+	// the operator authenticates requests with the cluster's credentials, so there are no IAM identities to assign to teams
+	if cc.IsMultiTenant() {
+		return ErrorGCPUnsupportedField(TeamsKey)
+	}
+
+	return nil
+}
+
+// HasAWSResources returns false for clusters which aren't created by `cortex cluster up` (lightweight clusters and clusters
+// on GCP), which don't have an API Gateway, cloudwatch metrics, or IAM identities to authenticate requests with
+func (cc *Config) HasAWSResources() bool {
+	return !cc.Lightweight && cc.Provider != types.GCPProviderType
+}
+
+func validateGCPServiceAccount(serviceAccount string) (string, error) {
+	if !strings.Contains(serviceAccount, "@") || !strings.HasSuffix(serviceAccount, ".gserviceaccount.com") {
+		return "", ErrorInvalidGCPServiceAccount(serviceAccount)
+	}
+	return serviceAccount, nil
+}
+
+func validateGCPImageRegistry(imageRegistry string) (string, error) {
+	if !regex.IsValidGCRURL(imageRegistry) {
+		return "", ErrorInvalidGCPImageRegistry(imageRegistry)
+	}
+	return strings.TrimSuffix(imageRegistry, "/"), nil
+}
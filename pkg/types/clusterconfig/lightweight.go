@@ -0,0 +1,69 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterconfig
+
+// ValidateLightweight validates the configuration of a lightweight cluster, which is installed onto an existing kubernetes
+// cluster (e.g. kind or minikube) without AWS resources: APIs are routed by the cluster's ingress controller rather than
+// by istio, the cluster's bucket is served by an S3-compatible object store (e.g. minio), and APIs' replicas aren't autoscaled
+func (cc *Config) ValidateLightweight() error {
+	if !cc.Lightweight {
+		return nil
+	}
+
+	if cc.NetworkingBackend != IngressNetworkingBackend {
+		return ErrorLightweightRequiresValue(NetworkingBackendKey, IngressNetworkingBackend.String())
+	}
+
+	if cc.MetadataStore != S3MetadataStoreType {
+		return ErrorLightweightRequiresValue(MetadataStoreKey, S3MetadataStoreType.String())
+	}
+
+	if cc.S3Endpoint == nil {
+		return ErrorLightweightRequiresField(S3EndpointKey)
+	}
+
+	if cc.Bucket == "" {
+		return ErrorLightweightRequiresField(BucketKey)
+	}
+
+	if cc.OperatorReplicas > 1 {
+		return ErrorLightweightRequiresValue(OperatorReplicasKey, "1")
+	}
+
+	if cc.Spot != nil && *cc.Spot {
+		return ErrorLightweightUnsupportedField(SpotKey)
+	}
+
+	if len(cc.NodeGroups) > 0 {
+		return ErrorLightweightUnsupportedField(NodeGroupsKey)
+	}
+
+	if len(cc.APILoadBalancerEIPs) > 0 {
+		return ErrorLightweightUnsupportedField(APILoadBalancerEIPsKey)
+	}
+
+	if cc.APILoadBalancerPrivateLink != nil {
+		return ErrorLightweightUnsupportedField(APILoadBalancerPrivateLinkKey)
+	}
+
+	// the operator authenticates requests with the cluster's credentials, so there are no IAM identities to assign to teams
+	if cc.IsMultiTenant() {
+		return ErrorLightweightUnsupportedField(TeamsKey)
+	}
+
+	return nil
+}
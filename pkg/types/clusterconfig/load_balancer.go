@@ -0,0 +1,65 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterconfig
+
+import (
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+)
+
+func validateEIPAllocationIDs(allocationIDs []string) ([]string, error) {
+	for _, allocationID := range allocationIDs {
+		if !strings.HasPrefix(allocationID, "eipalloc-") {
+			return nil, ErrorInvalidEIPAllocationID(allocationID)
+		}
+	}
+	return allocationIDs, nil
+}
+
+func validatePrivateLinkPrincipals(principals []string) ([]string, error) {
+	for _, principal := range principals {
+		if principal != "*" && !strings.HasPrefix(principal, "arn:") {
+			return nil, ErrorInvalidPrivateLinkPrincipal(principal)
+		}
+	}
+	return principals, nil
+}
+
+// the api load balancer has a node in each of the cluster's availability zones, and each node needs its own elastic ip
+// (this must be called after the availability zones have been set)
+func (cc *Config) validateAPILoadBalancerEIPs(awsClient *aws.Client) error {
+	if len(cc.APILoadBalancerEIPs) == 0 {
+		return nil
+	}
+
+	if len(cc.APILoadBalancerEIPs) != len(cc.AvailabilityZones) {
+		return ErrorEIPCountMismatch(len(cc.APILoadBalancerEIPs), cc.AvailabilityZones)
+	}
+
+	existingAllocationIDs, err := awsClient.ListElasticIPAllocationIDs(cc.APILoadBalancerEIPs...)
+	if err != nil {
+		return err
+	}
+	for _, allocationID := range cc.APILoadBalancerEIPs {
+		if !existingAllocationIDs.Has(allocationID) {
+			return ErrorEIPNotFound(allocationID, *cc.Region)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterconfig
+
+type MetadataStoreType int
+
+const (
+	UnknownMetadataStoreType MetadataStoreType = iota
+	S3MetadataStoreType
+	DynamoDBMetadataStoreType
+	GCSMetadataStoreType
+	PostgresMetadataStoreType
+)
+
+var _metadataStoreTypes = []string{
+	"unknown",
+	"s3",
+	"dynamodb",
+	"gcs",
+	"postgres",
+}
+
+func MetadataStoreTypeFromString(s string) MetadataStoreType {
+	for i := 0; i < len(_metadataStoreTypes); i++ {
+		if s == _metadataStoreTypes[i] {
+			return MetadataStoreType(i)
+		}
+	}
+	return UnknownMetadataStoreType
+}
+
+func MetadataStoreTypeStrings() []string {
+	return _metadataStoreTypes[1:]
+}
+
+func (t MetadataStoreType) String() string {
+	return _metadataStoreTypes[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t MetadataStoreType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *MetadataStoreType) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(_metadataStoreTypes); i++ {
+		if enum == _metadataStoreTypes[i] {
+			*t = MetadataStoreType(i)
+			return nil
+		}
+	}
+
+	*t = UnknownMetadataStoreType
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *MetadataStoreType) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t MetadataStoreType) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterconfig
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+)
+
+type MetricSink int
+
+const (
+	UnknownMetricSink MetricSink = iota
+	CloudWatchMetricSink
+	StatsDMetricSink
+)
+
+var _metricSinks = []string{
+	"unknown",
+	"cloudwatch",
+	"statsd",
+}
+
+func MetricSinkFromString(s string) MetricSink {
+	for i := 0; i < len(_metricSinks); i++ {
+		if s == _metricSinks[i] {
+			return MetricSink(i)
+		}
+	}
+	return UnknownMetricSink
+}
+
+func MetricSinkStrings() []string {
+	return _metricSinks[1:]
+}
+
+func (t MetricSink) String() string {
+	return _metricSinks[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t MetricSink) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *MetricSink) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(_metricSinks); i++ {
+		if enum == _metricSinks[i] {
+			*t = MetricSink(i)
+			return nil
+		}
+	}
+
+	*t = UnknownMetricSink
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *MetricSink) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t MetricSink) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func validateMetricSinks(sinks []string) ([]string, error) {
+	for _, sink := range sinks {
+		if MetricSinkFromString(sink) == UnknownMetricSink {
+			return nil, ErrorInvalidMetricSink(sink)
+		}
+	}
+	return sinks, nil
+}
+
+// HasMetricSink returns true if APIs' request metrics are shipped to the sink
+func (cc *Config) HasMetricSink(sink MetricSink) bool {
+	return slices.HasString(cc.MetricSinks, sink.String())
+}
+
+// the cloudwatch agent's statsd daemonset listens on port 8125 of each node
+const _cloudWatchAgentStatsDPort = 8125
+
+func (cc *Config) validateMetricSinks() error {
+	if !cc.HasMetricSink(StatsDMetricSink) {
+		return nil
+	}
+	if cc.HasMetricSink(CloudWatchMetricSink) && cc.StatsDHost == nil && cc.StatsDPort == _cloudWatchAgentStatsDPort {
+		return errors.Wrap(ErrorStatsDPortConflict(cc.StatsDPort), StatsDPortKey)
+	}
+	return nil
+}
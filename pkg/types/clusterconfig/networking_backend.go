@@ -0,0 +1,78 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterconfig
+
+type NetworkingBackend int
+
+const (
+	UnknownNetworkingBackend NetworkingBackend = iota
+	IstioNetworkingBackend
+	IngressNetworkingBackend
+)
+
+var _networkingBackends = []string{
+	"unknown",
+	"istio",
+	"ingress",
+}
+
+func NetworkingBackendFromString(s string) NetworkingBackend {
+	for i := 0; i < len(_networkingBackends); i++ {
+		if s == _networkingBackends[i] {
+			return NetworkingBackend(i)
+		}
+	}
+	return UnknownNetworkingBackend
+}
+
+func NetworkingBackendStrings() []string {
+	return _networkingBackends[1:]
+}
+
+func (t NetworkingBackend) String() string {
+	return _networkingBackends[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t NetworkingBackend) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *NetworkingBackend) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(_networkingBackends); i++ {
+		if enum == _networkingBackends[i] {
+			*t = NetworkingBackend(i)
+			return nil
+		}
+	}
+
+	*t = UnknownNetworkingBackend
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *NetworkingBackend) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t NetworkingBackend) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterconfig
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+// NodeGroupLabelKey is the node label which identifies the node group that a worker node belongs to
+const NodeGroupLabelKey = "cortex.dev/node-group"
+
+// GetNodeGroup returns the node group with the given name, or nil if it doesn't exist
+func (cc *Config) GetNodeGroup(name string) *NodeGroup {
+	for _, nodeGroup := range cc.NodeGroups {
+		if nodeGroup.Name == name {
+			return nodeGroup
+		}
+	}
+	return nil
+}
+
+// MaxInstancesTotal returns the maximum number of worker instances across the default node group and all additional node groups
+func (cc *Config) MaxInstancesTotal() int64 {
+	var total int64
+	if cc.MaxInstances != nil {
+		total += *cc.MaxInstances
+	}
+	for _, nodeGroup := range cc.NodeGroups {
+		total += nodeGroup.MaxInstances
+	}
+	return total
+}
+
+func (cc *Config) validateNodeGroups() error {
+	nodeGroupNames := strset.New()
+	for i, nodeGroup := range cc.NodeGroups {
+		if nodeGroupNames.Has(nodeGroup.Name) {
+			return errors.Wrap(ErrorDuplicateNodeGroupName(nodeGroup.Name), NodeGroupsKey)
+		}
+		nodeGroupNames.Add(nodeGroup.Name)
+
+		if nodeGroup.MinInstances > nodeGroup.MaxInstances {
+			return errors.Wrap(ErrorMinInstancesGreaterThanMax(nodeGroup.MinInstances, nodeGroup.MaxInstances), NodeGroupsKey, s.Index(i))
+		}
+
+		if _, ok := aws.InstanceMetadatas[*cc.Region][nodeGroup.InstanceType]; !ok {
+			return errors.Wrap(ErrorInstanceTypeNotSupportedInRegion(nodeGroup.InstanceType, *cc.Region), NodeGroupsKey, s.Index(i), InstanceTypeKey)
+		}
+	}
+
+	return nil
+}
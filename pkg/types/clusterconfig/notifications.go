@@ -0,0 +1,39 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterconfig
+
+import (
+	"strings"
+)
+
+// UserStr lists the configured destinations without revealing the webhook urls (which typically embed a secret token)
+func (notifications *Notifications) UserStr() string {
+	var destinations []string
+	if notifications.SlackWebhook != nil {
+		destinations = append(destinations, "slack")
+	}
+	if notifications.Webhook != nil {
+		destinations = append(destinations, "webhook")
+	}
+	if notifications.SNSTopic != nil {
+		destinations = append(destinations, *notifications.SNSTopic)
+	}
+	if len(destinations) == 0 {
+		return "none"
+	}
+	return strings.Join(destinations, ", ")
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterconfig
+
+import (
+	"fmt"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+// UserStr describes the placeholder pods which are used to keep spare capacity in the cluster
+func (overprovisioning *Overprovisioning) UserStr() string {
+	str := fmt.Sprintf("%d (cpu: %s, mem: %s", overprovisioning.Replicas, overprovisioning.CPU, overprovisioning.Mem)
+	if overprovisioning.GPU > 0 {
+		str += fmt.Sprintf(", gpu: %d", overprovisioning.GPU)
+	}
+	return str + ")"
+}
+
+func validateQuantity(quantity string) (string, error) {
+	if _, err := kresource.ParseQuantity(quantity); err != nil {
+		return "", ErrorInvalidQuantity(quantity)
+	}
+	return quantity, nil
+}
+
+// placeholder pods run on the cluster's default worker nodes, so each one must fit on a single instance
+func (cc *Config) validateOverprovisioning() error {
+	if cc.Overprovisioning == nil || cc.Overprovisioning.Replicas == 0 {
+		return nil
+	}
+
+	instanceMetadata := aws.InstanceMetadatas[*cc.Region][*cc.InstanceType]
+
+	cpu := kresource.MustParse(cc.Overprovisioning.CPU)
+	if cpu.Cmp(instanceMetadata.CPU) > 0 {
+		return errors.Wrap(ErrorOverprovisioningExceedsInstance("cpu", cpu.String(), instanceMetadata.Type, instanceMetadata.CPU.String()), CPUKey)
+	}
+
+	mem := kresource.MustParse(cc.Overprovisioning.Mem)
+	if mem.Cmp(instanceMetadata.Memory) > 0 {
+		return errors.Wrap(ErrorOverprovisioningExceedsInstance("memory", mem.String(), instanceMetadata.Type, instanceMetadata.Memory.String()), MemKey)
+	}
+
+	if cc.Overprovisioning.GPU > instanceMetadata.GPU {
+		return errors.Wrap(ErrorOverprovisioningExceedsInstance("gpus", fmt.Sprintf("%d", cc.Overprovisioning.GPU), instanceMetadata.Type, fmt.Sprintf("%d", instanceMetadata.GPU)), GPUKey)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterconfig
+
+// RequestMonitorFlushIntervals are the allowed values of request_monitor_flush_interval (in seconds)
+var RequestMonitorFlushIntervals = []int64{1, 5, 10, 30, 60}
+
+type RequestMonitorMode int
+
+const (
+	UnknownRequestMonitorMode RequestMonitorMode = iota
+	SidecarRequestMonitorMode
+	InProcessRequestMonitorMode
+)
+
+var _requestMonitorModes = []string{
+	"unknown",
+	"sidecar",
+	"in_process",
+}
+
+func RequestMonitorModeFromString(s string) RequestMonitorMode {
+	for i := 0; i < len(_requestMonitorModes); i++ {
+		if s == _requestMonitorModes[i] {
+			return RequestMonitorMode(i)
+		}
+	}
+	return UnknownRequestMonitorMode
+}
+
+func RequestMonitorModeStrings() []string {
+	return _requestMonitorModes[1:]
+}
+
+func (t RequestMonitorMode) String() string {
+	return _requestMonitorModes[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t RequestMonitorMode) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *RequestMonitorMode) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(_requestMonitorModes); i++ {
+		if enum == _requestMonitorModes[i] {
+			*t = RequestMonitorMode(i)
+			return nil
+		}
+	}
+
+	*t = UnknownRequestMonitorMode
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *RequestMonitorMode) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t RequestMonitorMode) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
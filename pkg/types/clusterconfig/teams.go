@@ -0,0 +1,100 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterconfig
+
+import (
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+// IsMultiTenant returns true if teams are configured, in which case access to the operator is restricted to admins and team members
+func (cc *Config) IsMultiTenant() bool {
+	return len(cc.Teams) > 0
+}
+
+// IsAdmin returns true if the IAM identity is listed in admins (or if the cluster is not multi-tenant)
+func (cc *Config) IsAdmin(arn string) bool {
+	if !cc.IsMultiTenant() {
+		return true
+	}
+	return matchesARNs(cc.Admins, arn)
+}
+
+// GetTeam returns the team that the IAM identity belongs to, or nil if it doesn't belong to any team
+func (cc *Config) GetTeam(arn string) *Team {
+	for _, team := range cc.Teams {
+		if matchesARNs(team.Members, arn) {
+			return team
+		}
+	}
+	return nil
+}
+
+// GetTeamByName returns nil if there is no team with the name
+func (cc *Config) GetTeamByName(name string) *Team {
+	for _, team := range cc.Teams {
+		if team.Name == name {
+			return team
+		}
+	}
+	return nil
+}
+
+// patterns may end in "*" to match all ARNs which begin with the preceding characters
+func matchesARNs(patterns []string, arn string) bool {
+	for _, pattern := range patterns {
+		if strings.HasSuffix(pattern, "*") {
+			if strings.HasPrefix(arn, pattern[:len(pattern)-1]) {
+				return true
+			}
+		} else if pattern == arn {
+			return true
+		}
+	}
+	return false
+}
+
+func (cc *Config) validateTeams() error {
+	if !cc.IsMultiTenant() {
+		return nil
+	}
+
+	if len(cc.Admins) == 0 {
+		return ErrorAdminsRequiredWithTeams()
+	}
+
+	teamNames := strset.New()
+	members := map[string]string{}
+	for i, team := range cc.Teams {
+		if teamNames.Has(team.Name) {
+			return errors.Wrap(ErrorDuplicateTeamName(team.Name), TeamsKey)
+		}
+		teamNames.Add(team.Name)
+
+		for _, member := range team.Members {
+			if otherTeam, ok := members[member]; ok {
+				return errors.Wrap(ErrorMemberInMultipleTeams(member, otherTeam, team.Name), TeamsKey, s.Index(i), TeamMembersKey)
+			}
+			members[member] = team.Name
+		}
+	}
+
+	return nil
+}
@@ -166,6 +166,28 @@ func getStatus(statusMap map[string]string, controlPlane string) (Status, error)
 	return StatusNotFound, ErrorUnexpectedCloudFormationStatus(s.ObjFlat(statusMap))
 }
 
+// the node group stacks which were replaced by a cluster upgrade are listed as deleted (until they expire), so they are
+// ignored as long as a newer generation of the node group exists
+func withoutReplacedNodeGroupStacks(stackSummaries []*cloudformation.StackSummary, nodeGroupStackNames strset.Set) []*cloudformation.StackSummary {
+	existingNodeGroups := strset.New()
+	for _, stackSummary := range stackSummaries {
+		nodeGroupStackName := aws.NodeGroupStackName(*stackSummary.StackName, nodeGroupStackNames)
+		if nodeGroupStackName != "" && *stackSummary.StackStatus != cloudformation.StackStatusDeleteComplete {
+			existingNodeGroups.Add(nodeGroupStackName)
+		}
+	}
+
+	var filtered []*cloudformation.StackSummary
+	for _, stackSummary := range stackSummaries {
+		nodeGroupStackName := aws.NodeGroupStackName(*stackSummary.StackName, nodeGroupStackNames)
+		if existingNodeGroups.Has(nodeGroupStackName) && *stackSummary.StackStatus == cloudformation.StackStatusDeleteComplete {
+			continue
+		}
+		filtered = append(filtered, stackSummary)
+	}
+	return filtered
+}
+
 func GetClusterState(awsClient *aws.Client, accessConfig *clusterconfig.AccessConfig) (*ClusterState, error) {
 	controlPlaneStackName := fmt.Sprintf(controlPlaneTemplate, *accessConfig.ClusterName)
 	operatorStackName := fmt.Sprintf(operatorTemplate, *accessConfig.ClusterName)
@@ -178,6 +200,7 @@ func GetClusterState(awsClient *aws.Client, accessConfig *clusterconfig.AccessCo
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to get cluster state from cloudformation")
 	}
+	stackSummaries = withoutReplacedNodeGroupStacks(stackSummaries, nodeGroupStackNamesSet)
 
 	statusMap := map[string]string{}
 	nodeGroupStackNames := []string{}
@@ -17,15 +17,22 @@ limitations under the License.
 package metrics
 
 import (
+	"time"
+
 	"github.com/cortexlabs/cortex/pkg/lib/pointer"
 	"github.com/cortexlabs/cortex/pkg/lib/slices"
 )
 
 type Metrics struct {
-	APIName           string           `json:"api_name"`
-	NetworkStats      *NetworkStats    `json:"network_stats"`
-	ClassDistribution map[string]int   `json:"class_distribution"`
-	RegressionStats   *RegressionStats `json:"regression_stats"`
+	APIName           string                   `json:"api_name"`
+	NetworkStats      *NetworkStats            `json:"network_stats"`
+	ModelStats        map[string]*NetworkStats `json:"model_stats"` // model name -> stats (only for APIs which serve multiple models)
+	ClassDistribution map[string]int           `json:"class_distribution"`
+	RegressionStats   *RegressionStats         `json:"regression_stats"`
+	StreamStats       *StreamStats             `json:"stream_stats"` // only for APIs which have streamed responses
+	GPUStats          map[string]*GPUStats     `json:"gpu_stats"`    // pod name -> stats (only for APIs which request GPUs)
+	InfStats          map[string]*InfStats     `json:"inf_stats"`    // pod name -> stats (only for APIs which request Inferentia chips)
+	CustomStats       map[string]*CustomStats  `json:"custom_stats"` // metric name -> stats (only for APIs whose predictors emit custom metrics)
 }
 
 type NetworkStats struct {
@@ -36,6 +43,45 @@ type NetworkStats struct {
 	Total   int      `json:"total"`
 }
 
+// TimeSeries holds an API's request metrics over a time range, bucketed by Period
+type TimeSeries struct {
+	APIName    string      `json:"api_name"`
+	APIID      string      `json:"api_id,omitempty"`     // if empty, the metrics include all versions of the API
+	ModelName  string      `json:"model_name,omitempty"` // if empty, the metrics include all of the API's models
+	Period     int64       `json:"period"`               // seconds
+	Datapoints []Datapoint `json:"datapoints"`
+}
+
+type Datapoint struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Requests   int       `json:"requests"`
+	QPS        float64   `json:"qps"`
+	Code2XX    int       `json:"code_2xx"`
+	Code4XX    int       `json:"code_4xx"`
+	Code5XX    int       `json:"code_5xx"`
+	LatencyAvg *float64  `json:"latency_avg"` // milliseconds
+	LatencyP50 *float64  `json:"latency_p50"`
+	LatencyP95 *float64  `json:"latency_p95"`
+	LatencyP99 *float64  `json:"latency_p99"`
+}
+
+// GPUStats holds the current utilization of the GPUs which are allocated to a pod
+type GPUStats struct {
+	GPUs        int      `json:"gpus"`
+	Utilization *float64 `json:"utilization"`  // percent, averaged across the pod's GPUs
+	MemoryUsed  *float64 `json:"memory_used"`  // MiB, summed across the pod's GPUs
+	MemoryTotal *float64 `json:"memory_total"` // MiB, summed across the pod's GPUs
+	Temperature *float64 `json:"temperature"`  // celsius, of the pod's hottest GPU
+}
+
+// InfStats holds the current utilization of the Inferentia chips which are allocated to a pod
+type InfStats struct {
+	NeuronCores      int      `json:"neuron_cores"`
+	Utilization      *float64 `json:"utilization"`        // percent, averaged across the pod's NeuronCores
+	DeviceMemoryUsed *float64 `json:"device_memory_used"` // MiB of the chips' on-chip memory used by the pod's models
+	HostMemoryUsed   *float64 `json:"host_memory_used"`   // MiB of host memory used by the neuron runtime
+}
+
 type RegressionStats struct {
 	Min         *float64 `json:"min"`
 	Max         *float64 `json:"max"`
@@ -43,6 +89,31 @@ type RegressionStats struct {
 	SampleCount int      `json:"sample_count"`
 }
 
+// StreamStats holds the token metrics of streamed responses (each chunk of a stream is counted as a token)
+type StreamStats struct {
+	TimeToFirstToken *float64 `json:"time_to_first_token"` // milliseconds
+	TokensPerSecond  *float64 `json:"tokens_per_second"`
+	Total            int      `json:"total"`            // number of streams which produced a token
+	TokenRateTotal   int      `json:"token_rate_total"` // number of streams which produced more than one token (which have a token rate)
+}
+
+// CustomStats aggregates a custom metric which the API's predictor emitted with its metrics client
+type CustomStats struct {
+	Type  CustomMetricType `json:"type"`
+	Sum   *float64         `json:"sum"`   // the counter's total
+	Count int              `json:"count"` // the number of values which were recorded by the histogram
+	Avg   *float64         `json:"avg"`
+	Min   *float64         `json:"min"`
+	Max   *float64         `json:"max"`
+}
+
+type CustomMetricType string
+
+const (
+	CounterCustomMetricType   CustomMetricType = "counter"
+	HistogramCustomMetricType CustomMetricType = "histogram"
+)
+
 func (left Metrics) Merge(right Metrics) Metrics {
 	mergedClassDistribution := left.ClassDistribution
 
@@ -56,15 +127,15 @@ func (left Metrics) Merge(right Metrics) Metrics {
 		}
 	}
 
-	var mergedNetworkStats *NetworkStats
-	switch {
-	case left.NetworkStats != nil && right.NetworkStats != nil:
-		merged := (*left.NetworkStats).Merge(*right.NetworkStats)
-		mergedNetworkStats = &merged
-	case left.NetworkStats != nil:
-		mergedNetworkStats = left.NetworkStats
-	case right.NetworkStats != nil:
-		mergedNetworkStats = right.NetworkStats
+	var mergedModelStats map[string]*NetworkStats
+	if left.ModelStats != nil || right.ModelStats != nil {
+		mergedModelStats = map[string]*NetworkStats{}
+		for modelName, networkStats := range left.ModelStats {
+			mergedModelStats[modelName] = networkStats
+		}
+		for modelName, networkStats := range right.ModelStats {
+			mergedModelStats[modelName] = mergeNetworkStatsPtrs(mergedModelStats[modelName], networkStats)
+		}
 	}
 
 	var mergedRegressionStats *RegressionStats
@@ -78,10 +149,29 @@ func (left Metrics) Merge(right Metrics) Metrics {
 		mergedRegressionStats = right.RegressionStats
 	}
 
+	var mergedCustomStats map[string]*CustomStats
+	if left.CustomStats != nil || right.CustomStats != nil {
+		mergedCustomStats = map[string]*CustomStats{}
+		for metricName, customStats := range left.CustomStats {
+			mergedCustomStats[metricName] = customStats
+		}
+		for metricName, customStats := range right.CustomStats {
+			if leftStats := mergedCustomStats[metricName]; leftStats != nil {
+				merged := leftStats.Merge(*customStats)
+				mergedCustomStats[metricName] = &merged
+			} else {
+				mergedCustomStats[metricName] = customStats
+			}
+		}
+	}
+
 	return Metrics{
-		NetworkStats:      mergedNetworkStats,
+		NetworkStats:      mergeNetworkStatsPtrs(left.NetworkStats, right.NetworkStats),
+		ModelStats:        mergedModelStats,
 		RegressionStats:   mergedRegressionStats,
 		ClassDistribution: mergedClassDistribution,
+		StreamStats:       mergeStreamStatsPtrs(left.StreamStats, right.StreamStats),
+		CustomStats:       mergedCustomStats,
 	}
 }
 
@@ -95,6 +185,18 @@ func (left NetworkStats) Merge(right NetworkStats) NetworkStats {
 	}
 }
 
+func mergeNetworkStatsPtrs(left *NetworkStats, right *NetworkStats) *NetworkStats {
+	switch {
+	case left != nil && right != nil:
+		merged := left.Merge(*right)
+		return &merged
+	case left != nil:
+		return left
+	default:
+		return right
+	}
+}
+
 func (left RegressionStats) Merge(right RegressionStats) RegressionStats {
 	totalSampleCount := left.SampleCount + right.SampleCount
 
@@ -106,6 +208,38 @@ func (left RegressionStats) Merge(right RegressionStats) RegressionStats {
 	}
 }
 
+func (left StreamStats) Merge(right StreamStats) StreamStats {
+	return StreamStats{
+		TimeToFirstToken: mergeAvg(left.TimeToFirstToken, left.Total, right.TimeToFirstToken, right.Total),
+		TokensPerSecond:  mergeAvg(left.TokensPerSecond, left.TokenRateTotal, right.TokensPerSecond, right.TokenRateTotal),
+		Total:            left.Total + right.Total,
+		TokenRateTotal:   left.TokenRateTotal + right.TokenRateTotal,
+	}
+}
+
+func (left CustomStats) Merge(right CustomStats) CustomStats {
+	return CustomStats{
+		Type:  left.Type,
+		Sum:   slices.Float64PtrSum(left.Sum, right.Sum),
+		Count: left.Count + right.Count,
+		Avg:   mergeAvg(left.Avg, left.Count, right.Avg, right.Count),
+		Min:   slices.Float64PtrMin(left.Min, right.Min),
+		Max:   slices.Float64PtrMax(left.Max, right.Max),
+	}
+}
+
+func mergeStreamStatsPtrs(left *StreamStats, right *StreamStats) *StreamStats {
+	switch {
+	case left != nil && right != nil:
+		merged := left.Merge(*right)
+		return &merged
+	case left != nil:
+		return left
+	default:
+		return right
+	}
+}
+
 func mergeAvg(left *float64, leftCount int, right *float64, rightCount int) *float64 {
 	leftCountFloat64Ptr := pointer.Float64(float64(leftCount))
 	rightCountFloat64Ptr := pointer.Float64(float64(rightCount))
@@ -160,3 +160,61 @@ func TestAPIMetricsMerge(t *testing.T) {
 
 	require.Equal(t, mergedAPIMetrics, apiMetrics.Merge(apiMetrics))
 }
+
+func TestModelStatsMerge(t *testing.T) {
+	networkStats := NetworkStats{
+		Code2XX: 3,
+		Code5XX: 1,
+		Latency: pointer.Float64(30),
+		Total:   4,
+	}
+	mergedNetworkStats := networkStats.Merge(networkStats)
+
+	left := Metrics{ModelStats: map[string]*NetworkStats{"model_a": &networkStats, "model_b": &networkStats}}
+	right := Metrics{ModelStats: map[string]*NetworkStats{"model_b": &networkStats, "model_c": &networkStats}}
+
+	merged := Metrics{ModelStats: map[string]*NetworkStats{
+		"model_a": &networkStats,
+		"model_b": &mergedNetworkStats,
+		"model_c": &networkStats,
+	}}
+
+	require.Equal(t, merged, left.Merge(right))
+	require.Equal(t, merged, right.Merge(left))
+	require.Equal(t, left, left.Merge(Metrics{}))
+	require.Equal(t, left, Metrics{}.Merge(left))
+}
+
+func TestCustomStatsMerge(t *testing.T) {
+	counter := CustomStats{Type: CounterCustomMetricType, Sum: pointer.Float64(3)}
+	histogram := CustomStats{
+		Type:  HistogramCustomMetricType,
+		Count: 2,
+		Avg:   pointer.Float64(0.5),
+		Min:   pointer.Float64(0.25),
+		Max:   pointer.Float64(0.75),
+	}
+
+	left := Metrics{CustomStats: map[string]*CustomStats{"low_confidence": &counter}}
+	right := Metrics{CustomStats: map[string]*CustomStats{
+		"low_confidence": &counter,
+		"confidence":     &histogram,
+	}}
+
+	merged := Metrics{CustomStats: map[string]*CustomStats{
+		"low_confidence": {Type: CounterCustomMetricType, Sum: pointer.Float64(6)},
+		"confidence":     &histogram,
+	}}
+
+	require.Equal(t, merged, left.Merge(right))
+	require.Equal(t, merged, right.Merge(left))
+	require.Equal(t, left, left.Merge(Metrics{}))
+
+	require.Equal(t, CustomStats{
+		Type:  HistogramCustomMetricType,
+		Count: 4,
+		Avg:   pointer.Float64(0.5),
+		Min:   pointer.Float64(0.25),
+		Max:   pointer.Float64(0.75),
+	}, histogram.Merge(histogram))
+}
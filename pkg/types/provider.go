@@ -22,15 +22,17 @@ const (
 	UnknownProviderType ProviderType = iota
 	LocalProviderType
 	AWSProviderType
+	GCPProviderType
 )
 
 var _providerTypes = []string{
 	"unknown",
 	"local",
 	"aws",
+	"gcp",
 }
 
-var _ = [1]int{}[int(AWSProviderType)-(len(_providerTypes)-1)] // Ensure list length matches
+var _ = [1]int{}[int(GCPProviderType)-(len(_providerTypes)-1)] // Ensure list length matches
 
 func ProviderTypeFromString(s string) ProviderType {
 	for i := 0; i < len(_providerTypes); i++ {
@@ -41,8 +43,15 @@ func ProviderTypeFromString(s string) ProviderType {
 	return UnknownProviderType
 }
 
+// ProviderTypeStrings returns the providers of cli environments (clusters on gcp are managed through aws environments,
+// since their operators authenticate requests in the same way as lightweight clusters' operators)
 func ProviderTypeStrings() []string {
-	return _providerTypes[1:]
+	return []string{LocalProviderType.String(), AWSProviderType.String()}
+}
+
+// ClusterProviderTypeStrings returns the providers which clusters can run on
+func ClusterProviderTypeStrings() []string {
+	return []string{AWSProviderType.String(), GCPProviderType.String()}
 }
 
 func (t ProviderType) String() string {
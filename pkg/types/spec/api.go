@@ -39,6 +39,10 @@ type API struct {
 	ProjectKey       string             `json:"project_key"`
 	LocalModelCaches []*LocalModelCache `json:"local_model_cache"` // local only
 	LocalProjectDir  string             `json:"local_project_dir"`
+	ImageDigests     map[string]string  `json:"image_digests"`     // image -> image pinned to its digest
+	ModelVersionIDs  map[string]string  `json:"model_version_ids"` // s3 path -> S3 version ID
+	OptimizedModels  map[string]string  `json:"optimized_models"`  // s3 path -> S3 path of the model optimized for the API's GPU type
+	DependencyImage  string             `json:"dependency_image"`  // the predictor image with the project's dependencies installed (if prebuild_dependencies is set)
 }
 
 type LocalModelCache struct {
@@ -58,6 +62,9 @@ func GetAPISpec(apiConfig *userconfig.API, projectID string, deploymentID string
 	}
 	buf.WriteString(s.Obj(apiConfig.Predictor))
 	buf.WriteString(s.Obj(apiConfig.Monitoring))
+	if len(apiConfig.MetricTags) > 0 {
+		buf.WriteString(s.Obj(apiConfig.MetricTags))
+	}
 	buf.WriteString(deploymentID)
 	buf.WriteString(projectID)
 	id := hash.Bytes(buf.Bytes())
@@ -74,6 +81,28 @@ func GetAPISpec(apiConfig *userconfig.API, projectID string, deploymentID string
 	}
 }
 
+// Pin records the digests of the API's images and the versions of its models, so that all of its replicas run
+// identical artifacts; the API's ID changes if any of them change
+func (api *API) Pin(imageDigests map[string]string, modelVersionIDs map[string]string) {
+	api.ImageDigests = imageDigests
+	api.ModelVersionIDs = modelVersionIDs
+
+	if len(imageDigests) == 0 && len(modelVersionIDs) == 0 {
+		return
+	}
+
+	api.ID = hash.String(api.ID + s.Obj(imageDigests) + s.Obj(modelVersionIDs))
+	api.Key = Key(api.Name, api.ID)
+}
+
+// PinnedImage returns the image pinned to its digest, or the image itself if it wasn't pinned
+func (api *API) PinnedImage(image string) string {
+	if pinnedImage, ok := api.ImageDigests[image]; ok {
+		return pinnedImage
+	}
+	return image
+}
+
 func (api *API) ModelIDs() []string {
 	models := []string{}
 	if api != nil && len(api.LocalModelCaches) > 0 {
@@ -125,9 +154,27 @@ func MetadataRoot(apiName string) string {
 	)
 }
 
+// PayloadLogRoot is the prefix of the API's captured requests and responses (see payload_logging)
+func PayloadLogRoot(apiName string) string {
+	return filepath.Join(
+		"apis",
+		apiName,
+		"payloads",
+	)
+}
+
 func ProjectKey(projectID string) string {
 	return filepath.Join(
 		"projects",
 		projectID+".zip",
 	)
 }
+
+// ProjectFileKey is the key of a file which was uploaded as part of an incrementally uploaded project
+func ProjectFileKey(fileHash string) string {
+	return filepath.Join(
+		"projects",
+		"files",
+		fileHash,
+	)
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"regexp"
+
+	"github.com/cortexlabs/cortex/pkg/lib/cast"
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+)
+
+// matches ${VAR} and $${VAR} (which is escaped, and becomes ${VAR})
+var _variableRegex = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Environment is the environment which APIs are deployed to (e.g. the cluster's `environment` and `variables`)
+type Environment struct {
+	Name      string            // selects the APIs' overrides (if empty, overrides are not applied)
+	Variables map[string]string // the values of the ${VAR} references in the APIs' configurations
+}
+
+// applyEnvironment applies the overrides for the environment (`overrides.<environment>`, which is merged into the API's
+// configuration like a template), and replaces the ${VAR} references in the API's configuration with the environment's
+// variables
+func applyEnvironment(data map[string]interface{}, environment Environment) (map[string]interface{}, error) {
+	data, err := applyOverrides(data, environment.Name)
+	if err != nil {
+		return nil, errors.Wrap(err, userconfig.OverridesKey)
+	}
+
+	interpolated, err := interpolateVariables(data, environment.Variables)
+	if err != nil {
+		return nil, err
+	}
+	return interpolated.(map[string]interface{}), nil
+}
+
+func applyOverrides(data map[string]interface{}, environmentName string) (map[string]interface{}, error) {
+	overridesVal, ok := data[userconfig.OverridesKey]
+	if !ok {
+		return data, nil
+	}
+
+	data = mergeConfigData(data, nil)
+	delete(data, userconfig.OverridesKey)
+
+	if overridesVal == nil {
+		return data, nil
+	}
+	overrides, ok := cast.InterfaceToStrInterfaceMap(overridesVal)
+	if !ok {
+		return nil, cr.ErrorInvalidPrimitiveType(overridesVal, cr.PrimTypeMap)
+	}
+
+	// overrides for other environments are ignored, but must be valid
+	var environmentOverride map[string]interface{}
+	for name, overrideVal := range overrides {
+		if overrideVal == nil {
+			continue
+		}
+		override, ok := cast.InterfaceToStrInterfaceMap(overrideVal)
+		if !ok {
+			return nil, errors.Wrap(cr.ErrorInvalidPrimitiveType(overrideVal, cr.PrimTypeMap), name)
+		}
+		for _, key := range []string{userconfig.NameKey, userconfig.OverridesKey, userconfig.BaseKey, userconfig.TemplateKey} {
+			if _, ok := override[key]; ok {
+				return nil, errors.Wrap(cr.ErrorUnsupportedKey(key), name)
+			}
+		}
+		if name == environmentName {
+			environmentOverride = override
+		}
+	}
+
+	if environmentName == "" || environmentOverride == nil {
+		return data, nil
+	}
+	return mergeConfigData(data, environmentOverride), nil
+}
+
+// interpolateVariables returns a copy of val in which the ${VAR} references in strings are replaced. If a string consists
+// of a single reference, the variable's value is parsed as YAML (so that e.g. `gpu: ${NUM_GPUS}` is an int)
+func interpolateVariables(val interface{}, variables map[string]string) (interface{}, error) {
+	if str, ok := val.(string); ok {
+		return interpolateString(str, variables)
+	}
+
+	if list, ok := val.([]interface{}); ok {
+		interpolated := make([]interface{}, len(list))
+		for i, elem := range list {
+			interpolatedElem, err := interpolateVariables(elem, variables)
+			if err != nil {
+				return nil, errors.Wrap(err, s.Int(i))
+			}
+			interpolated[i] = interpolatedElem
+		}
+		return interpolated, nil
+	}
+
+	if m, ok := cast.InterfaceToStrInterfaceMap(val); ok && m != nil {
+		interpolated := make(map[string]interface{}, len(m))
+		for key, elem := range m {
+			interpolatedElem, err := interpolateVariables(elem, variables)
+			if err != nil {
+				return nil, errors.Wrap(err, key)
+			}
+			interpolated[key] = interpolatedElem
+		}
+		return interpolated, nil
+	}
+
+	return val, nil
+}
+
+func interpolateString(str string, variables map[string]string) (interface{}, error) {
+	matches := _variableRegex.FindAllStringSubmatch(str, -1)
+	if len(matches) == 0 {
+		return str, nil
+	}
+
+	for _, match := range matches {
+		if match[0][1] == '$' {
+			continue
+		}
+		if _, ok := variables[match[1]]; !ok {
+			return nil, ErrorUndefinedVariable(match[1])
+		}
+	}
+
+	if len(matches) == 1 && matches[0][0] == str && str[1] != '$' {
+		value := variables[matches[0][1]]
+		if parsed, err := cr.ReadYAMLBytes([]byte(value)); err == nil && parsed != nil {
+			if _, ok := cast.InterfaceToStrInterfaceMap(parsed); !ok {
+				if _, ok := parsed.([]interface{}); !ok {
+					return parsed, nil
+				}
+			}
+		}
+		return value, nil
+	}
+
+	return _variableRegex.ReplaceAllStringFunc(str, func(match string) string {
+		if match[1] == '$' {
+			return match[1:]
+		}
+		return variables[match[2:len(match)-1]]
+	}), nil
+}
@@ -18,9 +18,11 @@ package spec
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/cortexlabs/cortex/pkg/consts"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
 	libmath "github.com/cortexlabs/cortex/pkg/lib/math"
 	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
@@ -57,12 +59,62 @@ const (
 	ErrCortexPrefixedEnvVarNotAllowed       = "spec.cortex_prefixed_env_var_not_allowed"
 	ErrLocalPathNotSupportedByAWSProvider   = "spec.local_path_not_supported_by_aws_provider"
 	ErrUnsupportedLocalComputeResource      = "spec.unsupported_local_compute_resource"
+	ErrUnsupportedLocalField                = "spec.unsupported_local_field"
+	ErrReservedTensorFlowServingFlag        = "spec.reserved_tensorflow_serving_flag"
+	ErrONNXExecutionProviderRequiresGPU     = "spec.onnx_execution_provider_requires_gpu"
+	ErrModelOptimizationRequiresGPU         = "spec.model_optimization_requires_gpu"
 	ErrRegistryInDifferentRegion            = "spec.registry_in_different_region"
 	ErrRegistryAccountIDMismatch            = "spec.registry_account_id_mismatch"
 	ErrCannotAccessECRWithAnonymousAWSCreds = "spec.cannot_access_ecr_with_anonymous_aws_creds"
 	ErrComputeResourceConflict              = "spec.compute_resource_conflict"
 	ErrInvalidNumberOfInfWorkers            = "spec.invalid_number_of_inf_workers"
 	ErrInvalidNumberOfInfs                  = "spec.invalid_number_of_infs"
+	ErrFallbackAPIIsSelf                    = "spec.fallback_api_is_self"
+	ErrFallbackOnOverloadRequiresFallback   = "spec.fallback_on_overload_requires_fallback"
+	ErrOnDemandFallbackRequiresSpot         = "spec.on_demand_fallback_requires_spot"
+	ErrMaxQueueLengthRequiresShed           = "spec.max_queue_length_requires_shed"
+	ErrShmSizeExceedsMem                    = "spec.shm_size_exceeds_mem"
+	ErrKEDATriggersRequireKEDA              = "spec.keda_triggers_require_keda"
+	ErrIdleTimeoutWithKEDA                  = "spec.idle_timeout_with_keda"
+	ErrFieldRequiredByStreamSource          = "spec.field_required_by_stream_source"
+	ErrFieldNotSupportedByStreamSource      = "spec.field_not_supported_by_stream_source"
+	ErrStreamRequiresKEDA                   = "spec.stream_requires_keda"
+	ErrStreamBatchSizeExceedsLimit          = "spec.stream_batch_size_exceeds_limit"
+	ErrMaxReceiveCountRequiresDLQ           = "spec.max_receive_count_requires_dlq"
+	ErrExperimentVariantIsSelf              = "spec.experiment_variant_is_self"
+	ErrDuplicateExperimentVariant           = "spec.duplicate_experiment_variant"
+	ErrExperimentWeightsTooHigh             = "spec.experiment_weights_too_high"
+	ErrMinWeightGreaterThanMaxWeight        = "spec.min_weight_greater_than_max_weight"
+	ErrInvalidBanditWeights                 = "spec.invalid_bandit_weights"
+	ErrParallelismGPUMismatch               = "spec.parallelism_gpu_mismatch"
+	ErrParallelismRequiresSingleWorker      = "spec.parallelism_requires_single_worker"
+	ErrLLMPredictorRequiresGPU              = "spec.llm_predictor_requires_gpu"
+	ErrFieldNotSupportedByLLMServer         = "spec.field_not_supported_by_llm_server"
+	ErrInvalidFeatureStoreURL               = "spec.invalid_feature_store_url"
+	ErrFieldNotSupportedByFeatureStoreType  = "spec.field_not_supported_by_feature_store_type"
+	ErrReservedScratchVolumeMountPath       = "spec.reserved_scratch_volume_mount_path"
+	ErrScratchVolumeRequiresSingleReplica   = "spec.scratch_volume_requires_single_replica"
+	ErrScratchVolumeRequiresNoSurge         = "spec.scratch_volume_requires_no_surge"
+	ErrDuplicateInitContainerNames          = "spec.duplicate_init_container_names"
+	ErrInvalidOCIPath                       = "spec.invalid_oci_path"
+	ErrTemplateNotFound                     = "spec.template_not_found"
+	ErrDuplicateTemplateName                = "spec.duplicate_template_name"
+	ErrTemplateCycle                        = "spec.template_cycle"
+	ErrUndefinedVariable                    = "spec.undefined_variable"
+	ErrInvalidLabel                         = "spec.invalid_label"
+	ErrReservedLabel                        = "spec.reserved_label"
+	ErrInvalidLabelSelector                 = "spec.invalid_label_selector"
+	ErrInvalidAnnotationKey                 = "spec.invalid_annotation_key"
+	ErrReservedAnnotation                   = "spec.reserved_annotation"
+	ErrInvalidMetricTag                     = "spec.invalid_metric_tag"
+	ErrReservedMetricTag                    = "spec.reserved_metric_tag"
+	ErrInvalidCORSOrigin                    = "spec.invalid_cors_origin"
+	ErrInvalidHTTPMethod                    = "spec.invalid_http_method"
+	ErrEndpointSpecifiedTwice               = "spec.endpoint_specified_twice"
+	ErrInvalidHeaderName                    = "spec.invalid_header_name"
+	ErrInvalidHeaderValue                   = "spec.invalid_header_value"
+	ErrReservedHeader                       = "spec.reserved_header"
+	ErrBatchTriggerNotSupported             = "spec.batch_trigger_not_supported"
 )
 
 func ErrorMalformedConfig() error {
@@ -286,7 +338,7 @@ func ErrorCortexPrefixedEnvVarNotAllowed() error {
 func ErrorLocalModelPathNotSupportedByAWSProvider() error {
 	return errors.WithStack(&errors.Error{
 		Kind:    ErrLocalPathNotSupportedByAWSProvider,
-		Message: fmt.Sprintf("local model paths are not supported for aws provider, please specify an S3 path"),
+		Message: fmt.Sprintf("local model paths are not supported by the cluster, please specify a bucket path (e.g. s3://bucket/model)"),
 	})
 }
 
@@ -297,6 +349,34 @@ func ErrorUnsupportedLocalComputeResource(resourceType string) error {
 	})
 }
 
+func ErrorUnsupportedLocalField(fieldKey string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrUnsupportedLocalField,
+		Message: fmt.Sprintf("%s is not supported when deploying locally", fieldKey),
+	})
+}
+
+func ErrorReservedTensorFlowServingFlag(flagName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrReservedTensorFlowServingFlag,
+		Message: fmt.Sprintf("the %s flag is set by cortex and cannot be overridden (use the %s field to configure batching)", flagName, userconfig.BatchingKey),
+	})
+}
+
+func ErrorONNXExecutionProviderRequiresGPU(provider string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrONNXExecutionProviderRequiresGPU,
+		Message: fmt.Sprintf("the %s execution provider requires a GPU (%s must be greater than 0)", provider, userconfig.GPUKey),
+	})
+}
+
+func ErrorModelOptimizationRequiresGPU() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrModelOptimizationRequiresGPU,
+		Message: fmt.Sprintf("models can only be optimized for APIs which run on GPUs (%s must be greater than 0)", userconfig.GPUKey),
+	})
+}
+
 func ErrorRegistryInDifferentRegion(registryRegion string, awsClientRegion string) error {
 	return errors.WithStack(&errors.Error{
 		Kind:    ErrRegistryInDifferentRegion,
@@ -339,3 +419,333 @@ func ErrorInvalidNumberOfInfs(requestedInfs int64) error {
 		Message: fmt.Sprintf("cannot request %d Infs (currently only 1 Inf can be used per API replica, due to AWS's bug: https://github.com/aws/aws-neuron-sdk/issues/110)", requestedInfs),
 	})
 }
+
+func ErrorFallbackAPIIsSelf(apiName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrFallbackAPIIsSelf,
+		Message: fmt.Sprintf("%s cannot be its own fallback api", s.UserStr(apiName)),
+	})
+}
+
+func ErrorFallbackOnOverloadRequiresFallback() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrFallbackOnOverloadRequiresFallback,
+		Message: fmt.Sprintf("%s can only be enabled when %s or %s is specified", userconfig.FallbackOnOverloadKey, userconfig.FallbackAPIKey, userconfig.FallbackResponseKey),
+	})
+}
+
+func ErrorOnDemandFallbackRequiresSpot() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrOnDemandFallbackRequiresSpot,
+		Message: fmt.Sprintf("%s can only be enabled when %s is true", userconfig.OnDemandFallbackKey, userconfig.SpotKey),
+	})
+}
+
+func ErrorMaxQueueLengthRequiresShed() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrMaxQueueLengthRequiresShed,
+		Message: fmt.Sprintf("%s can only be specified when %s is %s", userconfig.MaxQueueLengthKey, userconfig.OverloadBehaviorKey, userconfig.ShedOverloadBehaviorType.String()),
+	})
+}
+
+func ErrorShmSizeExceedsMem(shmSize k8s.Quantity, mem k8s.Quantity) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrShmSizeExceedsMem,
+		Message: fmt.Sprintf("%s (%s) cannot be greater than %s (%s)", userconfig.ShmSizeKey, shmSize.UserString, userconfig.MemKey, mem.UserString),
+	})
+}
+
+func ErrorKEDATriggersRequireKEDA() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrKEDATriggersRequireKEDA,
+		Message: fmt.Sprintf("%s can only be specified when %s is %s", userconfig.KEDATriggersKey, userconfig.AutoscalerKey, userconfig.KEDAAutoscalerType.String()),
+	})
+}
+
+func ErrorIdleTimeoutWithKEDA() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrIdleTimeoutWithKEDA,
+		Message: fmt.Sprintf("%s is not supported when %s is %s (KEDA manages the API's replica count)", userconfig.IdleTimeoutKey, userconfig.AutoscalerKey, userconfig.KEDAAutoscalerType.String()),
+	})
+}
+
+func ErrorFieldRequiredByStreamSource(fieldKey string, source userconfig.StreamSourceType) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrFieldRequiredByStreamSource,
+		Message: fmt.Sprintf("%s must be specified for %s streams", fieldKey, source.String()),
+	})
+}
+
+func ErrorFieldNotSupportedByStreamSource(fieldKey string, source userconfig.StreamSourceType) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrFieldNotSupportedByStreamSource,
+		Message: fmt.Sprintf("%s is not a supported field for %s streams", fieldKey, source.String()),
+	})
+}
+
+func ErrorStreamRequiresKEDA(source userconfig.StreamSourceType) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrStreamRequiresKEDA,
+		Message: fmt.Sprintf("%s streams are autoscaled based on their backlog (or, for kinesis streams, their number of shards), which requires %s.%s to be %s", source.String(), userconfig.AutoscalingKey, userconfig.AutoscalerKey, userconfig.KEDAAutoscalerType.String()),
+	})
+}
+
+func ErrorStreamBatchSizeExceedsLimit(source userconfig.StreamSourceType, limit int32) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrStreamBatchSizeExceedsLimit,
+		Message: fmt.Sprintf("%s cannot exceed %d for %s streams", userconfig.StreamBatchSizeKey, limit, source.String()),
+	})
+}
+
+func ErrorMaxReceiveCountRequiresDLQ() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrMaxReceiveCountRequiresDLQ,
+		Message: fmt.Sprintf("%s can only be specified when %s is specified", userconfig.MaxReceiveCountKey, userconfig.DeadLetterQueueKey),
+	})
+}
+
+func ErrorExperimentVariantIsSelf(apiName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrExperimentVariantIsSelf,
+		Message: fmt.Sprintf("%s cannot be a variant in its own experiment (it is the experiment's control)", s.UserStr(apiName)),
+	})
+}
+
+func ErrorDuplicateExperimentVariant(apiName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDuplicateExperimentVariant,
+		Message: fmt.Sprintf("%s is listed as a variant more than once", s.UserStr(apiName)),
+	})
+}
+
+func ErrorExperimentWeightsTooHigh(totalWeight int32) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrExperimentWeightsTooHigh,
+		Message: fmt.Sprintf("the variants' weights add up to %d%%, but must add up to less than 100%% so that the control receives traffic", totalWeight),
+	})
+}
+
+func ErrorMinWeightGreaterThanMaxWeight(minWeight int32, maxWeight int32) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrMinWeightGreaterThanMaxWeight,
+		Message: fmt.Sprintf("%s (%d) cannot be greater than %s (%d)", userconfig.MinWeightKey, minWeight, userconfig.MaxWeightKey, maxWeight),
+	})
+}
+
+func ErrorInvalidBanditWeights(minWeight int32, maxWeight int32, numAPIs int32) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidBanditWeights,
+		Message: fmt.Sprintf("the weights of the experiment's %d APIs (the control and its variants) must be able to add up to 100%% when each is between %s (%d) and %s (%d)", numAPIs, userconfig.MinWeightKey, minWeight, userconfig.MaxWeightKey, maxWeight),
+	})
+}
+
+func ErrorParallelismGPUMismatch(parallelism userconfig.Parallelism, gpu int64) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrParallelismGPUMismatch,
+		Message: fmt.Sprintf("%s x %s must be %d (%s: %d x %s: %d, since each rank uses one GPU), but is %d x %d", userconfig.GPUKey, userconfig.NodesParallelismKey, parallelism.Degree(), userconfig.TensorParallelismKey, parallelism.Tensor, userconfig.PipelineParallelismKey, parallelism.Pipeline, gpu, parallelism.Nodes),
+	})
+}
+
+func ErrorLLMPredictorRequiresGPU() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrLLMPredictorRequiresGPU,
+		Message: fmt.Sprintf("the %s predictor type requires at least one GPU (specify %s in the %s configuration)", userconfig.LLMPredictorType.String(), userconfig.GPUKey, userconfig.ComputeKey),
+	})
+}
+
+func ErrorFieldNotSupportedByLLMServer(fieldKey string, server userconfig.LLMServerType) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrFieldNotSupportedByLLMServer,
+		Message: fmt.Sprintf("%s is not supported by the %s server", fieldKey, server.String()),
+	})
+}
+
+func ErrorParallelismRequiresSingleWorker(workersPerReplica int32) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrParallelismRequiresSingleWorker,
+		Message: fmt.Sprintf("%s must be 1 when %s is specified, since each worker would load the entire model onto the replica's GPUs (got %d)", userconfig.WorkersPerReplicaKey, userconfig.ParallelismKey, workersPerReplica),
+	})
+}
+
+func ErrorInvalidFeatureStoreURL(url string, featureStoreType userconfig.FeatureStoreType) error {
+	var expected string
+	switch featureStoreType {
+	case userconfig.RedisFeatureStoreType:
+		expected = "a redis:// or rediss:// URL (e.g. redis://my-redis.example.com:6379/0)"
+	case userconfig.FeastFeatureStoreType:
+		expected = "the host:port of the Feast serving service (e.g. feast-serving.feast:6566)"
+	}
+
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidFeatureStoreURL,
+		Message: fmt.Sprintf("%s is not a valid %s %s; it must be %s", url, featureStoreType.String(), userconfig.FeatureStoreURLKey, expected),
+	})
+}
+
+func ErrorFieldNotSupportedByFeatureStoreType(fieldKey string, featureStoreType userconfig.FeatureStoreType) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrFieldNotSupportedByFeatureStoreType,
+		Message: fmt.Sprintf("%s is not supported by the %s feature store type", fieldKey, featureStoreType.String()),
+	})
+}
+
+func ErrorReservedScratchVolumeMountPath(mountPath string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrReservedScratchVolumeMountPath,
+		Message: fmt.Sprintf("%s cannot be mounted at %s, since it is reserved by cortex (/, /mnt, and /dev/shm cannot be used)", userconfig.ScratchVolumeKey, mountPath),
+	})
+}
+
+func ErrorScratchVolumeRequiresSingleReplica(maxReplicas int32) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrScratchVolumeRequiresSingleReplica,
+		Message: fmt.Sprintf("%s.%s must be 1 when the %s's %s is %s, since the volume can only be attached to one node at a time (got %d); set %s to %s to share the volume between replicas", userconfig.AutoscalingKey, userconfig.MaxReplicasKey, userconfig.ScratchVolumeKey, userconfig.AccessModeKey, userconfig.ReadWriteOnceAccessModeType.String(), maxReplicas, userconfig.AccessModeKey, userconfig.ReadWriteManyAccessModeType.String()),
+	})
+}
+
+func ErrorScratchVolumeRequiresNoSurge(maxSurge string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrScratchVolumeRequiresNoSurge,
+		Message: fmt.Sprintf("%s.%s must be 0 when the %s's %s is %s, since a new replica can't attach the volume until the old replica has been removed (got %s)", userconfig.UpdateStrategyKey, userconfig.MaxSurgeKey, userconfig.ScratchVolumeKey, userconfig.AccessModeKey, userconfig.ReadWriteOnceAccessModeType.String(), maxSurge),
+	})
+}
+
+func ErrorDuplicateInitContainerNames(name string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDuplicateInitContainerNames,
+		Message: fmt.Sprintf("cannot have multiple %s with the same name (%s)", userconfig.InitContainersKey, name),
+	})
+}
+
+func ErrorTemplateNotFound(templateName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrTemplateNotFound,
+		Message: fmt.Sprintf("template %s is not defined (templates must be defined in the same file, e.g. `- %s: %s`)", templateName, userconfig.TemplateKey, templateName),
+	})
+}
+
+func ErrorDuplicateTemplateName(templateName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDuplicateTemplateName,
+		Message: fmt.Sprintf("cannot have multiple templates with the same name (%s)", templateName),
+	})
+}
+
+func ErrorTemplateCycle(templateNames []string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrTemplateCycle,
+		Message: fmt.Sprintf("templates cannot inherit from themselves (%s)", strings.Join(templateNames, " -> ")),
+	})
+}
+
+func ErrorUndefinedVariable(variableName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrUndefinedVariable,
+		Message: fmt.Sprintf("variable ${%s} is not defined (variables are defined by the cluster's `variables` configuration; use $${%s} for a literal ${%s})", variableName, variableName, variableName),
+	})
+}
+
+func ErrorInvalidLabel(key string, value string, problems []string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidLabel,
+		Message: fmt.Sprintf("%s: %s is not a valid label (%s)", key, value, strings.Join(problems, "; ")),
+	})
+}
+
+func ErrorReservedLabel(key string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrReservedLabel,
+		Message: fmt.Sprintf("%s is a reserved label key (reserved label keys: %s)", key, s.StrsAnd(ReservedLabelKeys)),
+	})
+}
+
+func ErrorInvalidAnnotationKey(key string, problems []string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidAnnotationKey,
+		Message: fmt.Sprintf("%s is not a valid annotation key (%s)", key, strings.Join(problems, "; ")),
+	})
+}
+
+func ErrorReservedAnnotation(key string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrReservedAnnotation,
+		Message: fmt.Sprintf("%s is a reserved annotation key (annotations with the %s prefixes are set by cortex)", key, s.StrsOr(ReservedAnnotationPrefixes)),
+	})
+}
+
+func ErrorInvalidMetricTag(key string, value string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidMetricTag,
+		Message: fmt.Sprintf("%s: %s is not a valid metric tag (keys must start with a letter, and keys and values may only contain alphanumeric characters, underscores, minuses, colons, periods, and slashes)", key, value),
+	})
+}
+
+func ErrorReservedMetricTag(key string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrReservedMetricTag,
+		Message: fmt.Sprintf("%s is a reserved metric tag key (reserved metric tag keys: %s)", key, s.StrsAnd(ReservedMetricTagKeys)),
+	})
+}
+
+func ErrorInvalidHeaderName(name string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidHeaderName,
+		Message: fmt.Sprintf("%s is not a valid HTTP header name", s.UserStr(name)),
+	})
+}
+
+func ErrorInvalidHeaderValue(name string, value string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidHeaderValue,
+		Message: fmt.Sprintf("the value of the %s header (%s) must not contain %% characters or line breaks", name, s.UserStr(value)),
+	})
+}
+
+func ErrorReservedHeader(name string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrReservedHeader,
+		Message: fmt.Sprintf("the %s header can't be modified (reserved headers: %s)", name, s.StrsAnd(ReservedHeaders)),
+	})
+}
+
+func ErrorInvalidLabelSelector(selector string, reason string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidLabelSelector,
+		Message: fmt.Sprintf("invalid label selector \"%s\": %s (e.g. team=search,tier in (frontend,backend),!experimental)", selector, reason),
+	})
+}
+
+func ErrorInvalidCORSOrigin(origin string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidCORSOrigin,
+		Message: fmt.Sprintf("%s is not a valid origin; it must be \"*\" or a scheme and host, e.g. https://example.com or http://localhost:3000", origin),
+	})
+}
+
+func ErrorInvalidHTTPMethod(method string, allowedMethods []string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidHTTPMethod,
+		Message: fmt.Sprintf("%s is not a supported method (supported methods: %s)", method, s.StrsAnd(allowedMethods)),
+	})
+}
+
+func ErrorEndpointSpecifiedTwice(endpoint string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrEndpointSpecifiedTwice,
+		Message: fmt.Sprintf("%s is specified more than once (each of the api's endpoints must be unique)", endpoint),
+	})
+}
+
+func ErrorBatchTriggerNotSupported(reason string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrBatchTriggerNotSupported,
+		Message: fmt.Sprintf("%s can't be used because %s", userconfig.BatchTriggerKey, reason),
+	})
+}
+
+func ErrorInvalidOCIPath(path string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidOCIPath,
+		Message: fmt.Sprintf("%s is not a valid OCI artifact path; it must include the registry's host, e.g. oci://123456789.dkr.ecr.us-west-2.amazonaws.com/my-model:v1 or oci://ghcr.io/my-org/my-model@sha256:...", path),
+	})
+}
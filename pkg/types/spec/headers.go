@@ -0,0 +1,71 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+)
+
+// ReservedHeaders are managed by the APIs gateway (or by HTTP itself), so they can't be set, added, removed, or propagated
+var ReservedHeaders = []string{"host", "content-length", "transfer-encoding", "connection"}
+
+// header names are HTTP tokens (RFC 7230)
+var _headerNameRegex = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9a-zA-Z-]+$")
+
+// header names are case insensitive, so they are lowercased (which is how envoy handles them)
+func validateHeaderName(name string) (string, error) {
+	if !_headerNameRegex.MatchString(name) {
+		return "", ErrorInvalidHeaderName(name)
+	}
+
+	name = strings.ToLower(name)
+	if slices.HasString(ReservedHeaders, name) {
+		return "", ErrorReservedHeader(name)
+	}
+
+	return name, nil
+}
+
+func validateHeaderNames(names []string) ([]string, error) {
+	validated := make([]string, len(names))
+	for i, name := range names {
+		var err error
+		if validated[i], err = validateHeaderName(name); err != nil {
+			return nil, err
+		}
+	}
+	return validated, nil
+}
+
+// % is disallowed in header values because the gateway would interpret it as the start of a request variable
+func validateHeaders(headers map[string]string) (map[string]string, error) {
+	validated := make(map[string]string, len(headers))
+	for _, name := range sortedKeys(headers) {
+		validatedName, err := validateHeaderName(name)
+		if err != nil {
+			return nil, err
+		}
+		if strings.ContainsAny(headers[name], "%\r\n") {
+			return nil, ErrorInvalidHeaderValue(validatedName, headers[name])
+		}
+		validated[validatedName] = headers[name]
+	}
+	return validated, nil
+}
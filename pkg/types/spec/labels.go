@@ -0,0 +1,122 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	klabels "k8s.io/apimachinery/pkg/labels"
+	kvalidation "k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ReservedLabelKeys are set by cortex on the kubernetes resources of APIs
+var ReservedLabelKeys = []string{"apiName", "apiID", "deploymentID", "deploymentName", "buildID", "cortex.dev/managed"}
+
+// ReservedAnnotationPrefixes are the prefixes of the annotations which are set by cortex (or istio) on the kubernetes
+// resources of APIs; a prefix matches its subdomains too (e.g. networking.cortex.dev)
+var ReservedAnnotationPrefixes = []string{"cortex.dev", "istio.io"}
+
+// ReservedMetricTagKeys are the tags (dimensions) which cortex sets on APIs' request metrics
+var ReservedMetricTagKeys = []string{"APIName", "APIID", "ModelName", "Code", "Class"}
+
+// statsd tags are key:value pairs; these are the characters which datadog preserves (it converts others to underscores)
+var (
+	_metricTagKeyRegex   = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_\-./]*$`)
+	_metricTagValueRegex = regexp.MustCompile(`^[a-zA-Z0-9_\-:./]+$`)
+)
+
+func validateLabels(labels map[string]string) (map[string]string, error) {
+	for _, key := range sortedKeys(labels) {
+		for _, reservedKey := range ReservedLabelKeys {
+			if key == reservedKey {
+				return nil, ErrorReservedLabel(key)
+			}
+		}
+
+		problems := append(kvalidation.IsQualifiedName(key), kvalidation.IsValidLabelValue(labels[key])...)
+		if len(problems) > 0 {
+			return nil, ErrorInvalidLabel(key, labels[key], problems)
+		}
+	}
+
+	return labels, nil
+}
+
+func validateAnnotations(annotations map[string]string) (map[string]string, error) {
+	for _, key := range sortedKeys(annotations) {
+		if problems := kvalidation.IsQualifiedName(key); len(problems) > 0 {
+			return nil, ErrorInvalidAnnotationKey(key, problems)
+		}
+
+		if slash := strings.Index(key, "/"); slash != -1 {
+			prefix := key[:slash]
+			for _, reservedPrefix := range ReservedAnnotationPrefixes {
+				if prefix == reservedPrefix || strings.HasSuffix(prefix, "."+reservedPrefix) {
+					return nil, ErrorReservedAnnotation(key)
+				}
+			}
+		}
+	}
+
+	return annotations, nil
+}
+
+func validateMetricTags(tags map[string]string) (map[string]string, error) {
+	for _, key := range sortedKeys(tags) {
+		for _, reservedKey := range ReservedMetricTagKeys {
+			if strings.EqualFold(key, reservedKey) {
+				return nil, ErrorReservedMetricTag(key)
+			}
+		}
+
+		if !_metricTagKeyRegex.MatchString(key) || !_metricTagValueRegex.MatchString(tags[key]) || len(key)+len(tags[key]) >= 200 {
+			return nil, ErrorInvalidMetricTag(key, tags[key])
+		}
+	}
+
+	return tags, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ParseLabelSelector parses a kubernetes label selector (e.g. "team=search,tier in (frontend,backend),!experimental")
+func ParseLabelSelector(selector string) (klabels.Selector, error) {
+	labelSelector, err := klabels.Parse(selector)
+	if err != nil {
+		return nil, ErrorInvalidLabelSelector(selector, errors.Message(err))
+	}
+	return labelSelector, nil
+}
+
+// MatchesLabelSelector returns whether the API's labels (including its name, as the apiName label) match the selector
+func (api *API) MatchesLabelSelector(selector klabels.Selector) bool {
+	labels := klabels.Set{"apiName": api.Name}
+	for key, value := range api.Labels {
+		labels[key] = value
+	}
+	return selector.Matches(labels)
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/cast"
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+)
+
+// An API configuration file can define templates (list items with a `template` field rather than a `name`), which
+// contain any of an API's fields. An API (or another template) which sets `base` to a template's name inherits the
+// template's fields: maps are merged recursively, and the API's values take precedence (lists and other values are
+// replaced rather than merged). Templates aren't deployed.
+//
+// resolveTemplates returns the APIs' configurations with their templates applied, along with their indexes in the file
+func resolveTemplates(configDataSlice []map[string]interface{}, filePath string) ([]map[string]interface{}, []int, error) {
+	templates := map[string]map[string]interface{}{}
+	for i, data := range configDataSlice {
+		if _, ok := data[userconfig.TemplateKey]; !ok {
+			continue
+		}
+		if _, ok := data[userconfig.NameKey]; ok {
+			return nil, nil, errors.Wrap(ErrorConflictingFields(userconfig.NameKey, userconfig.TemplateKey), userconfig.IdentifyAPI(filePath, "", i))
+		}
+		templateName, err := templateNameField(data, userconfig.TemplateKey)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, userconfig.IdentifyAPI(filePath, "", i), userconfig.TemplateKey)
+		}
+		if _, ok := templates[templateName]; ok {
+			return nil, nil, errors.Wrap(ErrorDuplicateTemplateName(templateName), filePath)
+		}
+		templates[templateName] = data
+	}
+
+	var apisData []map[string]interface{}
+	var indexes []int
+	for i, data := range configDataSlice {
+		if _, ok := data[userconfig.TemplateKey]; ok {
+			continue
+		}
+		name, _ := data[userconfig.NameKey].(string)
+		resolved, err := applyTemplates(data, templates, nil)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, userconfig.IdentifyAPI(filePath, name, i))
+		}
+		apisData = append(apisData, resolved)
+		indexes = append(indexes, i)
+	}
+
+	return apisData, indexes, nil
+}
+
+// ResolveAPIConfig returns the configuration of the API at index in the file with its templates applied, so that it can
+// be deployed without the rest of the file
+func ResolveAPIConfig(configDataSlice []map[string]interface{}, filePath string, index int) (map[string]interface{}, error) {
+	apisData, indexes, err := resolveTemplates(configDataSlice, filePath)
+	if err != nil {
+		return nil, err
+	}
+	for i, apiIndex := range indexes {
+		if apiIndex == index {
+			return apisData[i], nil
+		}
+	}
+	return nil, errors.Wrap(ErrorMalformedConfig(), filePath)
+}
+
+// applyTemplates merges data into its base template (which is merged into its own base, and so on); visited is the
+// chain of templates which have already been applied, to detect cycles
+func applyTemplates(data map[string]interface{}, templates map[string]map[string]interface{}, visited []string) (map[string]interface{}, error) {
+	if _, ok := data[userconfig.BaseKey]; !ok {
+		return data, nil
+	}
+
+	baseName, err := templateNameField(data, userconfig.BaseKey)
+	if err != nil {
+		return nil, errors.Wrap(err, userconfig.BaseKey)
+	}
+	for _, visitedName := range visited {
+		if visitedName == baseName {
+			return nil, ErrorTemplateCycle(append(visited, baseName))
+		}
+	}
+	template, ok := templates[baseName]
+	if !ok {
+		return nil, errors.Wrap(ErrorTemplateNotFound(baseName), userconfig.BaseKey)
+	}
+
+	base, err := applyTemplates(template, templates, append(visited, baseName))
+	if err != nil {
+		return nil, err
+	}
+
+	merged := mergeConfigData(base, data)
+	delete(merged, userconfig.BaseKey)
+	delete(merged, userconfig.TemplateKey)
+	return merged, nil
+}
+
+func templateNameField(data map[string]interface{}, key string) (string, error) {
+	name, ok := data[key].(string)
+	if !ok {
+		return "", cr.ErrorInvalidPrimitiveType(data[key], cr.PrimTypeString)
+	}
+	if name == "" {
+		return "", cr.ErrorCannotBeEmpty()
+	}
+	return name, nil
+}
+
+// mergeConfigData returns a copy of base with override's fields merged into it (neither argument is modified)
+func mergeConfigData(base map[string]interface{}, override map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(override))
+	for key, val := range base {
+		merged[key] = val
+	}
+
+	for key, overrideVal := range override {
+		overrideMap, overrideIsMap := cast.InterfaceToStrInterfaceMap(overrideVal)
+		baseMap, baseIsMap := cast.InterfaceToStrInterfaceMap(merged[key])
+		if overrideIsMap && baseIsMap && overrideMap != nil && baseMap != nil {
+			merged[key] = mergeConfigData(baseMap, overrideMap)
+		} else {
+			merged[key] = overrideVal
+		}
+	}
+
+	return merged
+}
@@ -19,6 +19,8 @@ package spec
 import (
 	"fmt"
 	"math"
+	"net"
+	"net/url"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -37,6 +39,7 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/pointer"
 	"github.com/cortexlabs/cortex/pkg/lib/regex"
 	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	libtime "github.com/cortexlabs/cortex/pkg/lib/time"
 	"github.com/cortexlabs/cortex/pkg/lib/urls"
@@ -65,6 +68,45 @@ func apiValidation(provider types.ProviderType) *cr.StructValidation {
 					MaxLength: 1000, // no particular reason other than it works
 				},
 			},
+			{
+				StructField: "Namespace",
+				StringValidation: &cr.StringValidation{
+					Default:          "default",
+					DNS1123:          true,
+					MaxLength:        63,
+					DisallowedValues: []string{"kube-system", "kube-public", "kube-node-lease", "istio-system"},
+				},
+			},
+			{
+				StructField: "Labels",
+				StringMapValidation: &cr.StringMapValidation{
+					Default:            map[string]string{},
+					AllowEmpty:         true,
+					AllowExplicitNull:  true,
+					ConvertNullToEmpty: true,
+					Validator:          validateLabels,
+				},
+			},
+			{
+				StructField: "Annotations",
+				StringMapValidation: &cr.StringMapValidation{
+					Default:            map[string]string{},
+					AllowEmpty:         true,
+					AllowExplicitNull:  true,
+					ConvertNullToEmpty: true,
+					Validator:          validateAnnotations,
+				},
+			},
+			{
+				StructField: "MetricTags",
+				StringMapValidation: &cr.StringMapValidation{
+					Default:            map[string]string{},
+					AllowEmpty:         true,
+					AllowExplicitNull:  true,
+					ConvertNullToEmpty: true,
+					Validator:          validateMetricTags,
+				},
+			},
 			{
 				StructField: "LocalPort",
 				IntPtrValidation: &cr.IntPtrValidation{
@@ -78,6 +120,14 @@ func apiValidation(provider types.ProviderType) *cr.StructValidation {
 			computeValidation(provider),
 			autoscalingValidation(provider),
 			updateStrategyValidation(provider),
+			notificationsValidation(),
+			streamValidation(),
+			batchTriggerValidation(),
+			rolloutPolicyValidation(),
+			experimentValidation(),
+			payloadLoggingValidation(),
+			featureStoreValidation(),
+			sloValidation(),
 		},
 	}
 }
@@ -133,6 +183,22 @@ func predictorValidation() *cr.StructFieldValidation {
 						DockerImageOrEmpty: true,
 					},
 				},
+				{
+					StructField: "ImagePullPolicy",
+					StringValidation: &cr.StringValidation{
+						AllowedValues: userconfig.ImagePullPolicyTypeStrings(),
+						Default:       userconfig.AlwaysImagePullPolicyType.String(),
+					},
+					Parser: func(str string) (interface{}, error) {
+						return userconfig.ImagePullPolicyTypeFromString(str), nil
+					},
+				},
+				{
+					StructField: "PrebuildDependencies",
+					BoolValidation: &cr.BoolValidation{
+						Default: false,
+					},
+				},
 				{
 					StructField: "Config",
 					InterfaceMapValidation: &cr.InterfaceMapValidation{
@@ -150,36 +216,46 @@ func predictorValidation() *cr.StructFieldValidation {
 						AllowEmpty: true,
 					},
 				},
+				envFromValidation(),
 				{
 					StructField:         "SignatureKey",
 					StringPtrValidation: &cr.StringPtrValidation{},
 				},
 				multiModelValidation(),
+				batchingValidation(),
+				tensorFlowServingConfigValidation(),
+				onnxRuntimeConfigValidation(),
+				llmServingConfigValidation(),
+				initContainersValidation(),
+				onShutdownValidation(),
+				explainerValidation(),
+				modelOptimizationValidation(),
 			},
 		},
 	}
 }
 
-func monitoringValidation() *cr.StructFieldValidation {
+func envFromValidation() *cr.StructFieldValidation {
 	return &cr.StructFieldValidation{
-		StructField: "Monitoring",
+		StructField: "EnvFrom",
 		StructValidation: &cr.StructValidation{
 			DefaultNil:        true,
 			AllowExplicitNull: true,
 			StructFieldValidations: []*cr.StructFieldValidation{
 				{
-					StructField:         "Key",
-					StringPtrValidation: &cr.StringPtrValidation{},
+					StructField: "ConfigMaps",
+					StringListValidation: &cr.StringListValidation{
+						AllowEmpty:        true,
+						AllowExplicitNull: true,
+						DisallowDups:      true,
+					},
 				},
 				{
-					StructField: "ModelType",
-					StringValidation: &cr.StringValidation{
-						Required:      false,
-						AllowEmpty:    true,
-						AllowedValues: userconfig.ModelTypeStrings(),
-					},
-					Parser: func(str string) (interface{}, error) {
-						return userconfig.ModelTypeFromString(str), nil
+					StructField: "Secrets",
+					StringListValidation: &cr.StringListValidation{
+						AllowEmpty:        true,
+						AllowExplicitNull: true,
+						DisallowDups:      true,
 					},
 				},
 			},
@@ -187,68 +263,75 @@ func monitoringValidation() *cr.StructFieldValidation {
 	}
 }
 
-func networkingValidation() *cr.StructFieldValidation {
+func tensorFlowServingConfigValidation() *cr.StructFieldValidation {
 	return &cr.StructFieldValidation{
-		StructField: "Networking",
+		StructField: "TensorFlowServingConfig",
 		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
 			StructFieldValidations: []*cr.StructFieldValidation{
 				{
-					StructField: "APIGateway",
-					StringValidation: &cr.StringValidation{
-						AllowedValues: userconfig.APIGatewayTypeStrings(),
-						Default:       userconfig.PublicAPIGatewayType.String(),
-					},
-					Parser: func(str string) (interface{}, error) {
-						return userconfig.APIGatewayTypeFromString(str), nil
+					StructField: "Flags",
+					StringMapValidation: &cr.StringMapValidation{
+						Default:            map[string]string{},
+						AllowEmpty:         true,
+						AllowExplicitNull:  true,
+						ConvertNullToEmpty: true,
 					},
 				},
+				{
+					StructField:         "ModelConfig",
+					StringPtrValidation: &cr.StringPtrValidation{},
+				},
 			},
 		},
 	}
 }
 
-func computeValidation(provider types.ProviderType) *cr.StructFieldValidation {
-	cpuDefault := pointer.String("200m")
-	if provider == types.LocalProviderType {
-		cpuDefault = nil
-	}
+func llmServingConfigValidation() *cr.StructFieldValidation {
 	return &cr.StructFieldValidation{
-		StructField: "Compute",
+		StructField: "LLMServingConfig",
 		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
 			StructFieldValidations: []*cr.StructFieldValidation{
 				{
-					StructField: "CPU",
-					StringPtrValidation: &cr.StringPtrValidation{
-						Default:           cpuDefault,
-						AllowExplicitNull: true,
-						CastNumeric:       true,
+					StructField: "Server",
+					StringValidation: &cr.StringValidation{
+						AllowedValues: userconfig.LLMServerTypeStrings(),
+						Default:       userconfig.VLLMServerType.String(),
+					},
+					Parser: func(str string) (interface{}, error) {
+						return userconfig.LLMServerTypeFromString(str), nil
 					},
-					Parser: k8s.QuantityParser(&k8s.QuantityValidation{
-						GreaterThanOrEqualTo: k8s.QuantityPtr(kresource.MustParse("20m")),
-					}),
 				},
 				{
-					StructField: "Mem",
-					StringPtrValidation: &cr.StringPtrValidation{
-						Default:           nil,
-						AllowExplicitNull: true,
+					StructField: "Image",
+					StringValidation: &cr.StringValidation{
+						Required:           false,
+						AllowEmpty:         true,
+						DockerImageOrEmpty: true,
 					},
-					Parser: k8s.QuantityParser(&k8s.QuantityValidation{
-						GreaterThanOrEqualTo: k8s.QuantityPtr(kresource.MustParse("20Mi")),
-					}),
 				},
 				{
-					StructField: "GPU",
-					Int64Validation: &cr.Int64Validation{
-						Default:              0,
-						GreaterThanOrEqualTo: pointer.Int64(0),
+					StructField: "MaxTokens",
+					Int64PtrValidation: &cr.Int64PtrValidation{
+						GreaterThan: pointer.Int64(0),
 					},
 				},
 				{
-					StructField: "Inf",
-					Int64Validation: &cr.Int64Validation{
-						Default:              0,
-						GreaterThanOrEqualTo: pointer.Int64(0),
+					StructField: "GPUMemoryUtilization",
+					Float64Validation: &cr.Float64Validation{
+						Default:           0.9,
+						GreaterThan:       pointer.Float64(0),
+						LessThanOrEqualTo: pointer.Float64(1),
+					},
+				},
+				{
+					StructField: "Args",
+					StringListValidation: &cr.StringListValidation{
+						AllowEmpty:        true,
+						AllowExplicitNull: true,
 					},
 				},
 			},
@@ -256,161 +339,125 @@ func computeValidation(provider types.ProviderType) *cr.StructFieldValidation {
 	}
 }
 
-func autoscalingValidation(provider types.ProviderType) *cr.StructFieldValidation {
-	defaultNil := provider == types.LocalProviderType
-	allowExplicitNull := provider == types.LocalProviderType
+func fallbackResponseValidation() *cr.StructFieldValidation {
 	return &cr.StructFieldValidation{
-		StructField: "Autoscaling",
+		StructField: "FallbackResponse",
 		StructValidation: &cr.StructValidation{
-			DefaultNil:        defaultNil,
-			AllowExplicitNull: allowExplicitNull,
+			DefaultNil:        true,
+			AllowExplicitNull: true,
 			StructFieldValidations: []*cr.StructFieldValidation{
 				{
-					StructField: "MinReplicas",
-					Int32Validation: &cr.Int32Validation{
-						Default:     1,
-						GreaterThan: pointer.Int32(0),
-					},
-				},
-				{
-					StructField: "MaxReplicas",
-					Int32Validation: &cr.Int32Validation{
-						Default:     100,
-						GreaterThan: pointer.Int32(0),
-					},
-				},
-				{
-					StructField:  "InitReplicas",
-					DefaultField: "MinReplicas",
-					Int32Validation: &cr.Int32Validation{
-						GreaterThan: pointer.Int32(0),
-					},
-				},
-				{
-					StructField: "WorkersPerReplica",
-					Int32Validation: &cr.Int32Validation{
-						Default:              1,
-						GreaterThanOrEqualTo: pointer.Int32(1),
-						LessThanOrEqualTo:    pointer.Int32(20),
-					},
-				},
-				{
-					StructField: "ThreadsPerWorker",
+					StructField: "StatusCode",
 					Int32Validation: &cr.Int32Validation{
-						Default:              1,
-						GreaterThanOrEqualTo: pointer.Int32(1),
+						Default:              200,
+						GreaterThanOrEqualTo: pointer.Int32(200),
+						LessThanOrEqualTo:    pointer.Int32(599),
 					},
 				},
 				{
-					StructField: "TargetReplicaConcurrency",
-					Float64PtrValidation: &cr.Float64PtrValidation{
-						GreaterThan: pointer.Float64(0),
+					StructField: "ContentType",
+					StringValidation: &cr.StringValidation{
+						Default: "application/json",
 					},
 				},
 				{
-					StructField: "MaxReplicaConcurrency",
-					Int64Validation: &cr.Int64Validation{
-						Default:           1024,
-						GreaterThan:       pointer.Int64(0),
-						LessThanOrEqualTo: pointer.Int64(math.MaxUint16),
+					StructField: "Body",
+					StringValidation: &cr.StringValidation{
+						Required:   true,
+						AllowEmpty: true,
+						MaxLength:  10000,
 					},
 				},
+			},
+		},
+	}
+}
+
+func onShutdownValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "OnShutdown",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
 				{
-					StructField: "Window",
-					StringValidation: &cr.StringValidation{
-						Default: "60s",
+					StructField: "Command",
+					StringListValidation: &cr.StringListValidation{
+						AllowEmpty:        true,
+						AllowExplicitNull: true,
 					},
-					Parser: cr.DurationParser(&cr.DurationValidation{
-						GreaterThanOrEqualTo: &AutoscalingTickInterval,
-						MultipleOf:           &AutoscalingTickInterval,
-					}),
 				},
 				{
-					StructField: "DownscaleStabilizationPeriod",
-					StringValidation: &cr.StringValidation{
-						Default: "5m",
+					StructField: "HTTPPath",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Validator: urls.ValidateEndpoint,
 					},
-					Parser: cr.DurationParser(&cr.DurationValidation{
-						GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("0s")),
-					}),
 				},
 				{
-					StructField: "UpscaleStabilizationPeriod",
+					StructField: "Timeout",
 					StringValidation: &cr.StringValidation{
-						Default: "1m",
+						Default: "10s",
 					},
 					Parser: cr.DurationParser(&cr.DurationValidation{
-						GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("0s")),
+						GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("1s")),
+						LessThanOrEqualTo:    pointer.Duration(libtime.MustParseDuration("10m")),
 					}),
 				},
-				{
-					StructField: "MaxDownscaleFactor",
-					Float64Validation: &cr.Float64Validation{
-						Default:              0.75,
-						GreaterThanOrEqualTo: pointer.Float64(0),
-						LessThan:             pointer.Float64(1),
-					},
-				},
-				{
-					StructField: "MaxUpscaleFactor",
-					Float64Validation: &cr.Float64Validation{
-						Default:     1.5,
-						GreaterThan: pointer.Float64(1),
-					},
-				},
-				{
-					StructField: "DownscaleTolerance",
-					Float64Validation: &cr.Float64Validation{
-						Default:              0.05,
-						GreaterThanOrEqualTo: pointer.Float64(0),
-						LessThan:             pointer.Float64(1),
-					},
-				},
-				{
-					StructField: "UpscaleTolerance",
-					Float64Validation: &cr.Float64Validation{
-						Default:              0.05,
-						GreaterThanOrEqualTo: pointer.Float64(0),
-					},
-				},
 			},
 		},
 	}
 }
 
-func updateStrategyValidation(provider types.ProviderType) *cr.StructFieldValidation {
-	defaultNil := provider == types.LocalProviderType
-	allowExplicitNull := provider == types.LocalProviderType
+func explainerValidation() *cr.StructFieldValidation {
 	return &cr.StructFieldValidation{
-		StructField: "UpdateStrategy",
+		StructField: "Explainer",
 		StructValidation: &cr.StructValidation{
-			DefaultNil:        defaultNil,
-			AllowExplicitNull: allowExplicitNull,
+			DefaultNil:        true,
+			AllowExplicitNull: true,
 			StructFieldValidations: []*cr.StructFieldValidation{
 				{
-					StructField: "MaxSurge",
+					StructField: "Path",
 					StringValidation: &cr.StringValidation{
-						Default:   "25%",
-						CastInt:   true,
-						Validator: surgeOrUnavailableValidator,
+						Required: true,
 					},
 				},
 				{
-					StructField: "MaxUnavailable",
+					StructField: "CPU",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Default:     pointer.String("200m"),
+						CastNumeric: true,
+					},
+					Parser: k8s.QuantityParser(&k8s.QuantityValidation{
+						GreaterThanOrEqualTo: k8s.QuantityPtr(kresource.MustParse("20m")),
+					}),
+				},
+				{
+					StructField: "Mem",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Default: pointer.String("512Mi"),
+					},
+					Parser: k8s.QuantityParser(&k8s.QuantityValidation{
+						GreaterThanOrEqualTo: k8s.QuantityPtr(kresource.MustParse("64Mi")),
+					}),
+				},
+				{
+					StructField: "Timeout",
 					StringValidation: &cr.StringValidation{
-						Default:   "25%",
-						CastInt:   true,
-						Validator: surgeOrUnavailableValidator,
+						Default: "30s",
 					},
+					Parser: cr.DurationParser(&cr.DurationValidation{
+						GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("1s")),
+						LessThanOrEqualTo:    pointer.Duration(libtime.MustParseDuration("5m")),
+					}),
 				},
 			},
 		},
 	}
 }
 
-func multiModelValidation() *cr.StructFieldValidation {
+func initContainersValidation() *cr.StructFieldValidation {
 	return &cr.StructFieldValidation{
-		StructField: "Models",
+		StructField: "InitContainers",
 		StructListValidation: &cr.StructListValidation{
 			Required:         false,
 			TreatNullAsEmpty: true,
@@ -419,23 +466,1445 @@ func multiModelValidation() *cr.StructFieldValidation {
 					{
 						StructField: "Name",
 						StringValidation: &cr.StringValidation{
-							Required:                   true,
-							AllowEmpty:                 false,
-							DisallowedValues:           []string{consts.SingleModelName},
-							AlphaNumericDashUnderscore: true,
+							Required:  true,
+							DNS1123:   true,
+							MaxLength: 63,
 						},
 					},
 					{
-						StructField: "Model",
+						StructField: "Image",
 						StringValidation: &cr.StringValidation{
-							Required:   true,
-							AllowEmpty: false,
+							Required:           false,
+							AllowEmpty:         true,
+							DockerImageOrEmpty: true,
 						},
 					},
 					{
-						StructField: "SignatureKey",
-						StringPtrValidation: &cr.StringPtrValidation{
-							Required:   false,
+						StructField: "Command",
+						StringListValidation: &cr.StringListValidation{
+							Required:   true,
+							AllowEmpty: false,
+						},
+					},
+					{
+						StructField: "Env",
+						StringMapValidation: &cr.StringMapValidation{
+							Default:    map[string]string{},
+							AllowEmpty: true,
+						},
+					},
+					{
+						StructField: "CPU",
+						StringPtrValidation: &cr.StringPtrValidation{
+							AllowExplicitNull: true,
+							CastNumeric:       true,
+						},
+						Parser: k8s.QuantityParser(&k8s.QuantityValidation{
+							GreaterThanOrEqualTo: k8s.QuantityPtr(kresource.MustParse("20m")),
+						}),
+					},
+					{
+						StructField: "Mem",
+						StringPtrValidation: &cr.StringPtrValidation{
+							AllowExplicitNull: true,
+						},
+						Parser: k8s.QuantityParser(&k8s.QuantityValidation{
+							GreaterThanOrEqualTo: k8s.QuantityPtr(kresource.MustParse("20Mi")),
+						}),
+					},
+				},
+			},
+		},
+	}
+}
+
+func onnxRuntimeConfigValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "ONNXRuntimeConfig",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "ExecutionProviders",
+					StringListValidation: &cr.StringListValidation{
+						AllowExplicitNull: true,
+						AllowEmpty:        true,
+						CastSingleItem:    true,
+						DisallowDups:      true,
+						Validator: func(providers []string) ([]string, error) {
+							for i, provider := range providers {
+								if !slices.HasString(_onnxExecutionProviders, provider) {
+									return nil, errors.Wrap(cr.ErrorInvalidStr(provider, _onnxExecutionProviders[0], _onnxExecutionProviders[1:]...), s.Index(i))
+								}
+							}
+							return providers, nil
+						},
+					},
+				},
+				{
+					StructField: "IntraOpNumThreads",
+					Int32Validation: &cr.Int32Validation{
+						Default:              0,
+						GreaterThanOrEqualTo: pointer.Int32(0),
+					},
+				},
+				{
+					StructField: "InterOpNumThreads",
+					Int32Validation: &cr.Int32Validation{
+						Default:              0,
+						GreaterThanOrEqualTo: pointer.Int32(0),
+					},
+				},
+				{
+					StructField: "GraphOptimizationLevel",
+					StringValidation: &cr.StringValidation{
+						AllowedValues: userconfig.GraphOptimizationLevelTypeStrings(),
+						Default:       userconfig.AllGraphOptimizationLevelType.String(),
+					},
+					Parser: func(str string) (interface{}, error) {
+						return userconfig.GraphOptimizationLevelTypeFromString(str), nil
+					},
+				},
+			},
+		},
+	}
+}
+
+func modelOptimizationValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "ModelOptimization",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "Precision",
+					StringValidation: &cr.StringValidation{
+						AllowedValues: userconfig.PrecisionTypeStrings(),
+						Default:       userconfig.FP32PrecisionType.String(),
+					},
+					Parser: func(str string) (interface{}, error) {
+						return userconfig.PrecisionTypeFromString(str), nil
+					},
+				},
+			},
+		},
+	}
+}
+
+func batchingValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "Batching",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "MaxBatchSize",
+					Int32Validation: &cr.Int32Validation{
+						Required:             true,
+						GreaterThanOrEqualTo: pointer.Int32(2),
+					},
+				},
+				{
+					StructField: "BatchInterval",
+					StringValidation: &cr.StringValidation{
+						Required: true,
+					},
+					Parser: cr.DurationParser(&cr.DurationValidation{
+						GreaterThan: pointer.Duration(libtime.MustParseDuration("0s")),
+					}),
+				},
+			},
+		},
+	}
+}
+
+func monitoringValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "Monitoring",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField:         "Key",
+					StringPtrValidation: &cr.StringPtrValidation{},
+				},
+				{
+					StructField: "ModelType",
+					StringValidation: &cr.StringValidation{
+						Required:      false,
+						AllowEmpty:    true,
+						AllowedValues: userconfig.ModelTypeStrings(),
+					},
+					Parser: func(str string) (interface{}, error) {
+						return userconfig.ModelTypeFromString(str), nil
+					},
+				},
+			},
+		},
+	}
+}
+
+func networkingValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "Networking",
+		StructValidation: &cr.StructValidation{
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "APIGateway",
+					StringValidation: &cr.StringValidation{
+						AllowedValues: userconfig.APIGatewayTypeStrings(),
+						Default:       userconfig.PublicAPIGatewayType.String(),
+					},
+					Parser: func(str string) (interface{}, error) {
+						return userconfig.APIGatewayTypeFromString(str), nil
+					},
+				},
+				{
+					StructField: "Compression",
+					StringValidation: &cr.StringValidation{
+						AllowedValues: userconfig.CompressionTypeStrings(),
+						Default:       userconfig.NoneCompressionType.String(),
+					},
+					Parser: func(str string) (interface{}, error) {
+						return userconfig.CompressionTypeFromString(str), nil
+					},
+				},
+				{
+					StructField: "FallbackAPI",
+					StringPtrValidation: &cr.StringPtrValidation{
+						DNS1035:   true,
+						MaxLength: 42,
+					},
+				},
+				fallbackResponseValidation(),
+				{
+					StructField: "FallbackOnOverload",
+					BoolValidation: &cr.BoolValidation{
+						Default: false,
+					},
+				},
+				{
+					StructField: "MaintenanceMessage",
+					StringPtrValidation: &cr.StringPtrValidation{
+						MaxLength: 1000,
+					},
+				},
+				{
+					StructField: "VersionPinning",
+					BoolValidation: &cr.BoolValidation{
+						Default: false,
+					},
+				},
+				{
+					StructField: "Mesh",
+					StructValidation: &cr.StructValidation{
+						DefaultNil:        true,
+						AllowExplicitNull: true,
+						StructFieldValidations: []*cr.StructFieldValidation{
+							{
+								StructField: "Enabled",
+								BoolValidation: &cr.BoolValidation{
+									Default: true,
+								},
+							},
+							{
+								StructField: "MTLS",
+								BoolPtrValidation: &cr.BoolPtrValidation{
+									AllowExplicitNull: true,
+								},
+							},
+							{
+								StructField: "SidecarCPU",
+								StringPtrValidation: &cr.StringPtrValidation{
+									Default:     pointer.String("100m"),
+									CastNumeric: true,
+								},
+								Parser: k8s.QuantityParser(&k8s.QuantityValidation{
+									GreaterThanOrEqualTo: k8s.QuantityPtr(kresource.MustParse("10m")),
+								}),
+							},
+							{
+								StructField: "SidecarMem",
+								StringPtrValidation: &cr.StringPtrValidation{
+									Default: pointer.String("128Mi"),
+								},
+								Parser: k8s.QuantityParser(&k8s.QuantityValidation{
+									GreaterThanOrEqualTo: k8s.QuantityPtr(kresource.MustParse("32Mi")),
+								}),
+							},
+						},
+					},
+				},
+				{
+					StructField: "CORS",
+					StructValidation: &cr.StructValidation{
+						DefaultNil:        true,
+						AllowExplicitNull: true,
+						StructFieldValidations: []*cr.StructFieldValidation{
+							{
+								StructField: "AllowOrigins",
+								StringListValidation: &cr.StringListValidation{
+									Required:       true,
+									CastSingleItem: true,
+									DisallowDups:   true,
+									Validator:      validateCORSOrigins,
+								},
+							},
+							{
+								StructField: "AllowMethods",
+								StringListValidation: &cr.StringListValidation{
+									Default:        []string{"GET", "POST"},
+									CastSingleItem: true,
+									DisallowDups:   true,
+									Validator:      validateHTTPMethods,
+								},
+							},
+							{
+								StructField: "AllowHeaders",
+								StringListValidation: &cr.StringListValidation{
+									Default:        []string{"Content-Type"},
+									AllowEmpty:     true,
+									CastSingleItem: true,
+									DisallowDups:   true,
+								},
+							},
+							{
+								StructField: "MaxAge",
+								StringValidation: &cr.StringValidation{
+									Default: "1h",
+								},
+								Parser: cr.DurationParser(&cr.DurationValidation{
+									GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("0s")),
+								}),
+							},
+						},
+					},
+				},
+				{
+					StructField: "AdditionalEndpoints",
+					StructListValidation: &cr.StructListValidation{
+						AllowExplicitNull: true,
+						StructValidation: &cr.StructValidation{
+							StructFieldValidations: []*cr.StructFieldValidation{
+								{
+									StructField: "Endpoint",
+									StringValidation: &cr.StringValidation{
+										Required:  true,
+										Validator: urls.ValidateEndpoint,
+										MaxLength: 1000,
+									},
+								},
+								{
+									StructField: "Methods",
+									StringListValidation: &cr.StringListValidation{
+										AllowEmpty:        true,
+										AllowExplicitNull: true,
+										CastSingleItem:    true,
+										DisallowDups:      true,
+										Validator:         validateHTTPMethods,
+									},
+								},
+								{
+									StructField: "Rewrite",
+									StringValidation: &cr.StringValidation{
+										Default:   "/predict",
+										Validator: urls.ValidateEndpoint,
+									},
+								},
+							},
+						},
+					},
+				},
+				{
+					StructField: "Headers",
+					StructValidation: &cr.StructValidation{
+						DefaultNil:        true,
+						AllowExplicitNull: true,
+						StructFieldValidations: []*cr.StructFieldValidation{
+							headerOperationsValidation("Request"),
+							headerOperationsValidation("Response"),
+							{
+								StructField: "Propagate",
+								StringListValidation: &cr.StringListValidation{
+									AllowEmpty:        true,
+									AllowExplicitNull: true,
+									CastSingleItem:    true,
+									DisallowDups:      true,
+									Validator:         validateHeaderNames,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func headerOperationsValidation(structField string) *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: structField,
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "Set",
+					StringMapValidation: &cr.StringMapValidation{
+						AllowEmpty:        true,
+						AllowExplicitNull: true,
+						Validator:         validateHeaders,
+					},
+				},
+				{
+					StructField: "Add",
+					StringMapValidation: &cr.StringMapValidation{
+						AllowEmpty:        true,
+						AllowExplicitNull: true,
+						Validator:         validateHeaders,
+					},
+				},
+				{
+					StructField: "Remove",
+					StringListValidation: &cr.StringListValidation{
+						AllowEmpty:        true,
+						AllowExplicitNull: true,
+						CastSingleItem:    true,
+						DisallowDups:      true,
+						Validator:         validateHeaderNames,
+					},
+				},
+			},
+		},
+	}
+}
+
+func computeValidation(provider types.ProviderType) *cr.StructFieldValidation {
+	cpuDefault := pointer.String("200m")
+	if provider == types.LocalProviderType {
+		cpuDefault = nil
+	}
+	return &cr.StructFieldValidation{
+		StructField: "Compute",
+		StructValidation: &cr.StructValidation{
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "CPU",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Default:           cpuDefault,
+						AllowExplicitNull: true,
+						CastNumeric:       true,
+					},
+					Parser: k8s.QuantityParser(&k8s.QuantityValidation{
+						GreaterThanOrEqualTo: k8s.QuantityPtr(kresource.MustParse("20m")),
+					}),
+				},
+				{
+					StructField: "Mem",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Default:           nil,
+						AllowExplicitNull: true,
+					},
+					Parser: k8s.QuantityParser(&k8s.QuantityValidation{
+						GreaterThanOrEqualTo: k8s.QuantityPtr(kresource.MustParse("20Mi")),
+					}),
+				},
+				{
+					StructField: "ShmSize",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Default:           nil,
+						AllowExplicitNull: true,
+					},
+					Parser: k8s.QuantityParser(&k8s.QuantityValidation{
+						GreaterThanOrEqualTo: k8s.QuantityPtr(kresource.MustParse("1Mi")),
+					}),
+				},
+				{
+					StructField: "EphemeralStorage",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Default:           nil,
+						AllowExplicitNull: true,
+					},
+					Parser: k8s.QuantityParser(&k8s.QuantityValidation{
+						GreaterThanOrEqualTo: k8s.QuantityPtr(kresource.MustParse("100Mi")),
+					}),
+				},
+				{
+					StructField: "GPU",
+					Int64Validation: &cr.Int64Validation{
+						Default:              0,
+						GreaterThanOrEqualTo: pointer.Int64(0),
+					},
+				},
+				{
+					StructField: "Inf",
+					Int64Validation: &cr.Int64Validation{
+						Default:              0,
+						GreaterThanOrEqualTo: pointer.Int64(0),
+					},
+				},
+				{
+					StructField: "Spot",
+					BoolPtrValidation: &cr.BoolPtrValidation{
+						AllowExplicitNull: true,
+					},
+				},
+				{
+					StructField: "OnDemandFallback",
+					BoolValidation: &cr.BoolValidation{
+						Default: false,
+					},
+				},
+				{
+					StructField: "NodeGroup",
+					StringPtrValidation: &cr.StringPtrValidation{
+						AllowExplicitNull: true,
+						DNS1123:           true,
+					},
+				},
+				{
+					StructField: "Priority",
+					StringValidation: &cr.StringValidation{
+						AllowedValues: userconfig.PriorityTypeStrings(),
+						Default:       userconfig.DefaultPriorityType.String(),
+					},
+					Parser: func(str string) (interface{}, error) {
+						return userconfig.PriorityTypeFromString(str), nil
+					},
+				},
+				{
+					StructField: "MaxConcurrentJobs",
+					Int64Validation: &cr.Int64Validation{
+						Default:     1,
+						GreaterThan: pointer.Int64(0),
+					},
+				},
+				{
+					StructField: "Parallelism",
+					StructValidation: &cr.StructValidation{
+						DefaultNil:        true,
+						AllowExplicitNull: true,
+						StructFieldValidations: []*cr.StructFieldValidation{
+							{
+								StructField: "Tensor",
+								Int64Validation: &cr.Int64Validation{
+									Default:     1,
+									GreaterThan: pointer.Int64(0),
+								},
+							},
+							{
+								StructField: "Pipeline",
+								Int64Validation: &cr.Int64Validation{
+									Default:     1,
+									GreaterThan: pointer.Int64(0),
+								},
+							},
+							{
+								StructField: "Nodes",
+								Int64Validation: &cr.Int64Validation{
+									Default:     1,
+									GreaterThan: pointer.Int64(0),
+								},
+							},
+						},
+					},
+				},
+				{
+					StructField: "ScratchVolume",
+					StructValidation: &cr.StructValidation{
+						DefaultNil:        true,
+						AllowExplicitNull: true,
+						StructFieldValidations: []*cr.StructFieldValidation{
+							{
+								StructField: "Size",
+								StringPtrValidation: &cr.StringPtrValidation{
+									Required: true,
+								},
+								Parser: k8s.QuantityParser(&k8s.QuantityValidation{
+									GreaterThanOrEqualTo: k8s.QuantityPtr(kresource.MustParse("1Gi")),
+								}),
+							},
+							{
+								StructField: "StorageClass",
+								StringPtrValidation: &cr.StringPtrValidation{
+									AllowExplicitNull: true,
+									DNS1123:           true,
+								},
+							},
+							{
+								StructField: "MountPath",
+								StringValidation: &cr.StringValidation{
+									Default:   "/scratch",
+									Prefix:    "/",
+									Validator: validateScratchVolumeMountPath,
+								},
+							},
+							{
+								StructField: "AccessMode",
+								StringValidation: &cr.StringValidation{
+									AllowedValues: userconfig.AccessModeTypeStrings(),
+									Default:       userconfig.ReadWriteOnceAccessModeType.String(),
+								},
+								Parser: func(str string) (interface{}, error) {
+									return userconfig.AccessModeTypeFromString(str), nil
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// origins are matched exactly by the gateway, so they can't include a path (e.g. a trailing slash)
+func validateCORSOrigins(origins []string) ([]string, error) {
+	for _, origin := range origins {
+		if origin == "*" {
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" || u.RawQuery != "" {
+			return nil, ErrorInvalidCORSOrigin(origin)
+		}
+	}
+	return origins, nil
+}
+
+var _httpMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+func validateHTTPMethods(methods []string) ([]string, error) {
+	upperMethods := make([]string, len(methods))
+	for i, method := range methods {
+		upperMethods[i] = strings.ToUpper(method)
+		if !slices.HasString(_httpMethods, upperMethods[i]) {
+			return nil, ErrorInvalidHTTPMethod(method, _httpMethods)
+		}
+	}
+	return upperMethods, nil
+}
+
+func validateScratchVolumeMountPath(mountPath string) (string, error) {
+	mountPath = filepath.Clean(mountPath)
+	if mountPath == "/" || mountPath == "/mnt" || strings.HasPrefix(mountPath, "/mnt/") || mountPath == "/dev/shm" {
+		return "", ErrorReservedScratchVolumeMountPath(mountPath)
+	}
+	return mountPath, nil
+}
+
+func autoscalingValidation(provider types.ProviderType) *cr.StructFieldValidation {
+	defaultNil := provider == types.LocalProviderType
+	allowExplicitNull := provider == types.LocalProviderType
+	return &cr.StructFieldValidation{
+		StructField: "Autoscaling",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        defaultNil,
+			AllowExplicitNull: allowExplicitNull,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "MinReplicas",
+					Int32Validation: &cr.Int32Validation{
+						Default:     1,
+						GreaterThan: pointer.Int32(0),
+					},
+				},
+				{
+					StructField: "MaxReplicas",
+					Int32Validation: &cr.Int32Validation{
+						Default:     100,
+						GreaterThan: pointer.Int32(0),
+					},
+				},
+				{
+					StructField:  "InitReplicas",
+					DefaultField: "MinReplicas",
+					Int32Validation: &cr.Int32Validation{
+						GreaterThan: pointer.Int32(0),
+					},
+				},
+				{
+					StructField: "WorkersPerReplica",
+					Int32Validation: &cr.Int32Validation{
+						Default:              1,
+						GreaterThanOrEqualTo: pointer.Int32(1),
+						LessThanOrEqualTo:    pointer.Int32(20),
+					},
+				},
+				{
+					StructField: "ThreadsPerWorker",
+					Int32Validation: &cr.Int32Validation{
+						Default:              1,
+						GreaterThanOrEqualTo: pointer.Int32(1),
+					},
+				},
+				{
+					StructField: "TargetReplicaConcurrency",
+					Float64PtrValidation: &cr.Float64PtrValidation{
+						GreaterThan: pointer.Float64(0),
+					},
+				},
+				{
+					StructField: "MaxReplicaConcurrency",
+					Int64Validation: &cr.Int64Validation{
+						Default:           1024,
+						GreaterThan:       pointer.Int64(0),
+						LessThanOrEqualTo: pointer.Int64(math.MaxUint16),
+					},
+				},
+				{
+					StructField: "MaxQueueLength",
+					Int64PtrValidation: &cr.Int64PtrValidation{
+						AllowExplicitNull:    true,
+						GreaterThanOrEqualTo: pointer.Int64(0),
+					},
+				},
+				{
+					StructField: "OverloadBehavior",
+					StringValidation: &cr.StringValidation{
+						AllowedValues: userconfig.OverloadBehaviorTypeStrings(),
+						Default:       userconfig.QueueOverloadBehaviorType.String(),
+					},
+					Parser: func(str string) (interface{}, error) {
+						return userconfig.OverloadBehaviorTypeFromString(str), nil
+					},
+				},
+				{
+					StructField: "Window",
+					StringValidation: &cr.StringValidation{
+						Default: "60s",
+					},
+					Parser: cr.DurationParser(&cr.DurationValidation{
+						GreaterThanOrEqualTo: &AutoscalingTickInterval,
+						MultipleOf:           &AutoscalingTickInterval,
+					}),
+				},
+				{
+					StructField: "DownscaleStabilizationPeriod",
+					StringValidation: &cr.StringValidation{
+						Default: "5m",
+					},
+					Parser: cr.DurationParser(&cr.DurationValidation{
+						GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("0s")),
+					}),
+				},
+				{
+					StructField: "UpscaleStabilizationPeriod",
+					StringValidation: &cr.StringValidation{
+						Default: "1m",
+					},
+					Parser: cr.DurationParser(&cr.DurationValidation{
+						GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("0s")),
+					}),
+				},
+				{
+					StructField: "MaxDownscaleFactor",
+					Float64Validation: &cr.Float64Validation{
+						Default:              0.75,
+						GreaterThanOrEqualTo: pointer.Float64(0),
+						LessThan:             pointer.Float64(1),
+					},
+				},
+				{
+					StructField: "MaxUpscaleFactor",
+					Float64Validation: &cr.Float64Validation{
+						Default:     1.5,
+						GreaterThan: pointer.Float64(1),
+					},
+				},
+				{
+					StructField: "DownscaleTolerance",
+					Float64Validation: &cr.Float64Validation{
+						Default:              0.05,
+						GreaterThanOrEqualTo: pointer.Float64(0),
+						LessThan:             pointer.Float64(1),
+					},
+				},
+				{
+					StructField: "UpscaleTolerance",
+					Float64Validation: &cr.Float64Validation{
+						Default:              0.05,
+						GreaterThanOrEqualTo: pointer.Float64(0),
+					},
+				},
+				{
+					StructField: "IdleTimeout",
+					StringPtrValidation: &cr.StringPtrValidation{
+						AllowExplicitNull: true,
+					},
+					Parser: cr.DurationParser(&cr.DurationValidation{
+						GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("15m")),
+					}),
+				},
+				{
+					StructField: "MaxDownscaleStep",
+					Int32PtrValidation: &cr.Int32PtrValidation{
+						AllowExplicitNull: true,
+						GreaterThan:       pointer.Int32(0),
+					},
+				},
+				{
+					StructField: "MaxUpscaleStep",
+					Int32PtrValidation: &cr.Int32PtrValidation{
+						AllowExplicitNull: true,
+						GreaterThan:       pointer.Int32(0),
+					},
+				},
+				{
+					StructField: "DownscaleCooldown",
+					StringValidation: &cr.StringValidation{
+						Default: "0s",
+					},
+					Parser: cr.DurationParser(&cr.DurationValidation{
+						GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("0s")),
+					}),
+				},
+				{
+					StructField: "UpscaleCooldown",
+					StringValidation: &cr.StringValidation{
+						Default: "0s",
+					},
+					Parser: cr.DurationParser(&cr.DurationValidation{
+						GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("0s")),
+					}),
+				},
+				{
+					StructField: "Autoscaler",
+					StringValidation: &cr.StringValidation{
+						AllowedValues: userconfig.AutoscalerTypeStrings(),
+						Default:       userconfig.CortexAutoscalerType.String(),
+					},
+					Parser: func(str string) (interface{}, error) {
+						return userconfig.AutoscalerTypeFromString(str), nil
+					},
+				},
+				{
+					StructField: "KEDATriggers",
+					StructListValidation: &cr.StructListValidation{
+						AllowExplicitNull: true,
+						StructValidation: &cr.StructValidation{
+							StructFieldValidations: []*cr.StructFieldValidation{
+								{
+									StructField: "Type",
+									StringValidation: &cr.StringValidation{
+										Required: true,
+									},
+								},
+								{
+									StructField: "Metadata",
+									StringMapValidation: &cr.StringMapValidation{
+										Required:             true,
+										AllowCortexResources: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func updateStrategyValidation(provider types.ProviderType) *cr.StructFieldValidation {
+	defaultNil := provider == types.LocalProviderType
+	allowExplicitNull := provider == types.LocalProviderType
+	return &cr.StructFieldValidation{
+		StructField: "UpdateStrategy",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        defaultNil,
+			AllowExplicitNull: allowExplicitNull,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "MaxSurge",
+					StringValidation: &cr.StringValidation{
+						Default:   "25%",
+						CastInt:   true,
+						Validator: surgeOrUnavailableValidator,
+					},
+				},
+				{
+					StructField: "MaxUnavailable",
+					StringValidation: &cr.StringValidation{
+						Default:   "25%",
+						CastInt:   true,
+						Validator: surgeOrUnavailableValidator,
+					},
+				},
+			},
+		},
+	}
+}
+
+func rolloutPolicyValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "RolloutPolicy",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "BakeWindow",
+					StringValidation: &cr.StringValidation{
+						Default: "10m",
+					},
+					Parser: cr.DurationParser(&cr.DurationValidation{
+						GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("1m")),
+					}),
+				},
+				{
+					StructField: "MaxErrorRateIncrease",
+					Float64PtrValidation: &cr.Float64PtrValidation{
+						GreaterThanOrEqualTo: pointer.Float64(0),
+						LessThanOrEqualTo:    pointer.Float64(1),
+					},
+				},
+				{
+					StructField: "MaxLatencyIncrease",
+					Float64PtrValidation: &cr.Float64PtrValidation{
+						GreaterThanOrEqualTo: pointer.Float64(0),
+					},
+				},
+				{
+					StructField: "MinRequests",
+					Int64Validation: &cr.Int64Validation{
+						Default:     100,
+						GreaterThan: pointer.Int64(0),
+					},
+				},
+			},
+		},
+	}
+}
+
+func sloValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "SLO",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "Availability",
+					Float64Validation: &cr.Float64Validation{
+						Default:     0.999,
+						GreaterThan: pointer.Float64(0),
+						LessThan:    pointer.Float64(1),
+					},
+				},
+				{
+					StructField: "LatencyP99",
+					StringPtrValidation: &cr.StringPtrValidation{
+						AllowExplicitNull: true,
+					},
+					Parser: cr.DurationParser(&cr.DurationValidation{
+						GreaterThan: pointer.Duration(libtime.MustParseDuration("0s")),
+					}),
+				},
+				{
+					StructField: "Window",
+					StringValidation: &cr.StringValidation{
+						Default: "720h",
+					},
+					Parser: cr.DurationParser(&cr.DurationValidation{
+						GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("1h")),
+						LessThanOrEqualTo:    pointer.Duration(libtime.MustParseDuration("2160h")),
+					}),
+				},
+				{
+					StructField: "BlockPromotions",
+					BoolValidation: &cr.BoolValidation{
+						Default: true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func experimentValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "Experiment",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "Variants",
+					StructListValidation: &cr.StructListValidation{
+						Required: true,
+						StructValidation: &cr.StructValidation{
+							StructFieldValidations: []*cr.StructFieldValidation{
+								{
+									StructField: "API",
+									StringValidation: &cr.StringValidation{
+										Required: true,
+									},
+								},
+								{
+									StructField: "Weight",
+									Int32Validation: &cr.Int32Validation{
+										Required:    true,
+										GreaterThan: pointer.Int32(0),
+										LessThan:    pointer.Int32(100),
+									},
+								},
+							},
+						},
+					},
+				},
+				{
+					StructField: "Metric",
+					StringValidation: &cr.StringValidation{
+						Default:       userconfig.ErrorRateExperimentMetricType.String(),
+						AllowedValues: userconfig.ExperimentMetricTypeStrings(),
+					},
+					Parser: func(str string) (interface{}, error) {
+						return userconfig.ExperimentMetricTypeFromString(str), nil
+					},
+				},
+				{
+					StructField: "Confidence",
+					Float64Validation: &cr.Float64Validation{
+						Default:     0.95,
+						GreaterThan: pointer.Float64(0),
+						LessThan:    pointer.Float64(1),
+					},
+				},
+				{
+					StructField: "MinRequests",
+					Int64Validation: &cr.Int64Validation{
+						Default:     1000,
+						GreaterThan: pointer.Int64(0),
+					},
+				},
+				{
+					StructField: "AutoPromote",
+					BoolValidation: &cr.BoolValidation{
+						Default: false,
+					},
+				},
+				{
+					StructField: "Bandit",
+					StructValidation: &cr.StructValidation{
+						DefaultNil:        true,
+						AllowExplicitNull: true,
+						StructFieldValidations: []*cr.StructFieldValidation{
+							{
+								StructField: "MinWeight",
+								Int32Validation: &cr.Int32Validation{
+									Default:              5,
+									GreaterThanOrEqualTo: pointer.Int32(0),
+									LessThan:             pointer.Int32(100),
+								},
+							},
+							{
+								StructField: "MaxWeight",
+								Int32Validation: &cr.Int32Validation{
+									Default:           100,
+									GreaterThan:       pointer.Int32(0),
+									LessThanOrEqualTo: pointer.Int32(100),
+								},
+							},
+							{
+								StructField: "UpdateInterval",
+								StringValidation: &cr.StringValidation{
+									Default: "5m",
+								},
+								Parser: cr.DurationParser(&cr.DurationValidation{
+									GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("1m")),
+								}),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func payloadLoggingValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "PayloadLogging",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "SampleRate",
+					Float64Validation: &cr.Float64Validation{
+						Default:           1,
+						GreaterThan:       pointer.Float64(0),
+						LessThanOrEqualTo: pointer.Float64(1),
+					},
+				},
+			},
+		},
+	}
+}
+
+func featureStoreValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "FeatureStore",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "Type",
+					StringValidation: &cr.StringValidation{
+						Required:      true,
+						AllowedValues: userconfig.FeatureStoreTypeStrings(),
+					},
+					Parser: func(str string) (interface{}, error) {
+						return userconfig.FeatureStoreTypeFromString(str), nil
+					},
+				},
+				{
+					StructField: "URL",
+					StringValidation: &cr.StringValidation{
+						Required: true,
+					},
+				},
+				{
+					StructField:         "Project",
+					StringPtrValidation: &cr.StringPtrValidation{},
+				},
+				{
+					StructField:         "KeyPrefix",
+					StringPtrValidation: &cr.StringPtrValidation{},
+				},
+				{
+					StructField: "Secret",
+					StringPtrValidation: &cr.StringPtrValidation{
+						DNS1123: true,
+					},
+				},
+				{
+					StructField: "Cache",
+					StructValidation: &cr.StructValidation{
+						DefaultNil:        true,
+						AllowExplicitNull: true,
+						StructFieldValidations: []*cr.StructFieldValidation{
+							{
+								StructField: "TTL",
+								StringValidation: &cr.StringValidation{
+									Default: "60s",
+								},
+								Parser: cr.DurationParser(&cr.DurationValidation{
+									GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("1s")),
+								}),
+							},
+							{
+								StructField: "Mem",
+								StringPtrValidation: &cr.StringPtrValidation{
+									Default: pointer.String("256Mi"),
+								},
+								Parser: k8s.QuantityParser(&k8s.QuantityValidation{
+									GreaterThanOrEqualTo: k8s.QuantityPtr(kresource.MustParse("32Mi")),
+								}),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func streamValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "Stream",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "Source",
+					StringValidation: &cr.StringValidation{
+						Required:      true,
+						AllowedValues: userconfig.StreamSourceTypeStrings(),
+					},
+					Parser: func(str string) (interface{}, error) {
+						return userconfig.StreamSourceTypeFromString(str), nil
+					},
+				},
+				{
+					StructField: "Brokers",
+					StringListValidation: &cr.StringListValidation{
+						AllowEmpty:        true,
+						AllowExplicitNull: true,
+						DisallowDups:      true,
+						CastSingleItem:    true,
+					},
+				},
+				{
+					StructField: "Input",
+					StringValidation: &cr.StringValidation{
+						Required: true,
+					},
+				},
+				{
+					StructField:         "Output",
+					StringPtrValidation: &cr.StringPtrValidation{},
+				},
+				{
+					StructField:         "ConsumerGroup",
+					StringPtrValidation: &cr.StringPtrValidation{},
+				},
+				{
+					StructField: "BatchSize",
+					Int32Validation: &cr.Int32Validation{
+						Default:     1,
+						GreaterThan: pointer.Int32(0),
+					},
+				},
+				{
+					StructField: "TargetLag",
+					Int64PtrValidation: &cr.Int64PtrValidation{
+						GreaterThan: pointer.Int64(0),
+					},
+				},
+				{
+					StructField: "ShardsPerReplica",
+					Int64PtrValidation: &cr.Int64PtrValidation{
+						GreaterThan: pointer.Int64(0),
+					},
+				},
+				{
+					StructField: "DeadLetterQueue",
+					StringPtrValidation: &cr.StringPtrValidation{
+						AlphaNumericDashUnderscore: true,
+						MaxLength:                  80, // SQS queue names are limited to 80 characters
+					},
+				},
+				{
+					StructField: "MaxReceiveCount",
+					Int32PtrValidation: &cr.Int32PtrValidation{
+						GreaterThan:       pointer.Int32(0),
+						LessThanOrEqualTo: pointer.Int32(1000), // the maximum allowed by SQS
+					},
+				},
+				streamCallbackValidation(),
+				streamS3TriggerValidation(),
+				{
+					StructField: "MaxRetries",
+					Int32Validation: &cr.Int32Validation{
+						Default:              0,
+						GreaterThanOrEqualTo: pointer.Int32(0),
+						LessThanOrEqualTo:    pointer.Int32(10),
+					},
+				},
+				{
+					StructField: "RecordTimeout",
+					StringPtrValidation: &cr.StringPtrValidation{
+						AllowExplicitNull: true,
+					},
+					Parser: cr.DurationParser(&cr.DurationValidation{
+						GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("1s")),
+					}),
+				},
+				{
+					StructField: "DeadLetterPrefix",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Validator: func(path string) (string, error) {
+							if !aws.IsValidS3Path(path) {
+								return "", aws.ErrorInvalidS3Path(path)
+							}
+							return s.EnsureSuffix(path, "/"), nil
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func streamS3TriggerValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "S3Trigger",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "Bucket",
+					StringValidation: &cr.StringValidation{
+						Required: true,
+					},
+				},
+				{
+					StructField: "Prefix",
+					StringValidation: &cr.StringValidation{
+						AllowEmpty: true,
+						MaxLength:  1024, // the maximum length of S3 keys
+					},
+				},
+				{
+					StructField: "Suffix",
+					StringValidation: &cr.StringValidation{
+						AllowEmpty: true,
+						MaxLength:  1024,
+					},
+				},
+			},
+		},
+	}
+}
+
+func streamCallbackValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "Callback",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "URL",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Validator: func(str string) (string, error) {
+							u, err := urls.Parse(str)
+							if err != nil {
+								return "", err
+							}
+							if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+								return "", urls.ErrorInvalidURL(str)
+							}
+							return str, nil
+						},
+					},
+				},
+				{
+					StructField: "SNSTopic",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Prefix: "arn:aws:sns:",
+					},
+				},
+				{
+					StructField:         "SigningKeyEnv",
+					StringPtrValidation: &cr.StringPtrValidation{},
+				},
+				{
+					StructField: "MaxRetries",
+					Int32Validation: &cr.Int32Validation{
+						Default:              3,
+						GreaterThanOrEqualTo: pointer.Int32(0),
+						LessThanOrEqualTo:    pointer.Int32(10),
+					},
+				},
+			},
+		},
+	}
+}
+
+func batchTriggerValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "BatchTrigger",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "Queue",
+					StringValidation: &cr.StringValidation{
+						Required:  true,
+						MaxLength: 80, // the maximum length of SQS queue names
+					},
+				},
+				{
+					StructField: "Bucket",
+					StringValidation: &cr.StringValidation{
+						Required: true,
+					},
+				},
+				{
+					StructField: "Prefix",
+					StringValidation: &cr.StringValidation{
+						AllowEmpty: true,
+						MaxLength:  1024, // the maximum length of S3 keys
+					},
+				},
+				{
+					StructField: "Suffix",
+					StringValidation: &cr.StringValidation{
+						AllowEmpty: true,
+						MaxLength:  1024,
+					},
+				},
+				{
+					StructField: "Workers",
+					Int32Validation: &cr.Int32Validation{
+						Default:           1,
+						GreaterThan:       pointer.Int32(0),
+						LessThanOrEqualTo: pointer.Int32(100),
+					},
+				},
+			},
+		},
+	}
+}
+
+func notificationsValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "Notifications",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "SlackWebhook",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Prefix: "https://",
+					},
+				},
+				{
+					StructField: "Webhook",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Prefix: "https://",
+					},
+				},
+				{
+					StructField: "SNSTopic",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Prefix: "arn:aws:sns:",
+					},
+				},
+			},
+		},
+	}
+}
+
+func multiModelValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "Models",
+		StructListValidation: &cr.StructListValidation{
+			Required:         false,
+			TreatNullAsEmpty: true,
+			StructValidation: &cr.StructValidation{
+				StructFieldValidations: []*cr.StructFieldValidation{
+					{
+						StructField: "Name",
+						StringValidation: &cr.StringValidation{
+							Required:                   true,
+							AllowEmpty:                 false,
+							DisallowedValues:           []string{consts.SingleModelName},
+							AlphaNumericDashUnderscore: true,
+						},
+					},
+					{
+						StructField: "Model",
+						StringValidation: &cr.StringValidation{
+							Required:   true,
+							AllowEmpty: false,
+						},
+					},
+					{
+						StructField: "SignatureKey",
+						StringPtrValidation: &cr.StringPtrValidation{
+							Required:   false,
 							AllowEmpty: false,
 						},
 					},
@@ -467,7 +1936,7 @@ func surgeOrUnavailableValidator(str string) (string, error) {
 	return str, nil
 }
 
-func ExtractAPIConfigs(configBytes []byte, provider types.ProviderType, projectFiles ProjectFiles, filePath string) ([]userconfig.API, error) {
+func ExtractAPIConfigs(configBytes []byte, provider types.ProviderType, projectFiles ProjectFiles, filePath string, environment Environment) ([]userconfig.API, error) {
 	var err error
 
 	configData, err := cr.ReadYAMLBytes(configBytes)
@@ -479,16 +1948,28 @@ func ExtractAPIConfigs(configBytes []byte, provider types.ProviderType, projectF
 	if !ok {
 		return nil, errors.Wrap(ErrorMalformedConfig(), filePath)
 	}
-	apis := make([]userconfig.API, len(configDataSlice))
-	for i, data := range configDataSlice {
+
+	apisData, indexes, err := resolveTemplates(configDataSlice, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	apis := make([]userconfig.API, len(apisData))
+	for i, data := range apisData {
+		data, err := applyEnvironment(data, environment)
+		if err != nil {
+			name, _ := apisData[i][userconfig.NameKey].(string)
+			return nil, errors.Wrap(err, userconfig.IdentifyAPI(filePath, name, indexes[i]))
+		}
+
 		api := userconfig.API{}
 		errs := cr.Struct(&api, data, apiValidation(provider))
 		if errors.HasError(errs) {
 			name, _ := data[userconfig.NameKey].(string)
-			err = errors.Wrap(errors.FirstError(errs...), userconfig.IdentifyAPI(filePath, name, i))
+			err = errors.Wrap(errors.FirstError(errs...), userconfig.IdentifyAPI(filePath, name, indexes[i]))
 			return nil, errors.Append(err, fmt.Sprintf("\n\napi configuration schema can be found here: https://docs.cortex.dev/v/%s/deployments/api-configuration", consts.CortexVersionMinor))
 		}
-		api.Index = i
+		api.Index = indexes[i]
 		api.FilePath = filePath
 
 		api.ApplyDefaultDockerPaths()
@@ -505,27 +1986,170 @@ func ValidateAPI(
 	providerType types.ProviderType,
 	awsClient *aws.Client,
 ) error {
-	if providerType == types.AWSProviderType && api.Endpoint == nil {
+	if providerType != types.LocalProviderType && api.Endpoint == nil {
 		api.Endpoint = pointer.String("/" + api.Name)
 	}
 
-	if err := validatePredictor(api, projectFiles, providerType, awsClient); err != nil {
-		return errors.Wrap(err, api.Identify(), userconfig.PredictorKey)
+	if err := validatePredictor(api, projectFiles, providerType, awsClient); err != nil {
+		return errors.Wrap(err, api.Identify(), userconfig.PredictorKey)
+	}
+
+	if api.Autoscaling != nil { // should only be nil for local provider
+		if err := validateAutoscaling(api); err != nil {
+			return errors.Wrap(err, api.Identify(), userconfig.AutoscalingKey)
+		}
+	}
+
+	if api.Networking.FallbackAPI != nil && *api.Networking.FallbackAPI == api.Name {
+		return errors.Wrap(ErrorFallbackAPIIsSelf(api.Name), api.Identify(), userconfig.NetworkingKey, userconfig.FallbackAPIKey)
+	}
+
+	if err := validateFallback(api); err != nil {
+		return errors.Wrap(err, api.Identify(), userconfig.NetworkingKey)
+	}
+
+	if err := validateCompute(api, providerType); err != nil {
+		return errors.Wrap(err, api.Identify(), userconfig.ComputeKey)
+	}
+
+	if api.UpdateStrategy != nil { // should only be nil for local provider
+		if err := validateUpdateStrategy(api.UpdateStrategy); err != nil {
+			return errors.Wrap(err, api.Identify(), userconfig.UpdateStrategyKey)
+		}
+	}
+
+	if api.Notifications != nil && providerType == types.LocalProviderType {
+		return errors.Wrap(ErrorUnsupportedLocalField(userconfig.NotificationsKey), api.Identify())
+	}
+
+	if api.Stream != nil {
+		if err := validateStream(api, providerType); err != nil {
+			return errors.Wrap(err, api.Identify(), userconfig.StreamKey)
+		}
+	}
+
+	if api.BatchTrigger != nil {
+		if err := validateBatchTrigger(api, providerType); err != nil {
+			return errors.Wrap(err, api.Identify(), userconfig.BatchTriggerKey)
+		}
+	}
+
+	if api.Networking.VersionPinning && providerType == types.LocalProviderType {
+		return errors.Wrap(ErrorUnsupportedLocalField(userconfig.VersionPinningKey), api.Identify(), userconfig.NetworkingKey)
+	}
+
+	if api.Networking.Mesh != nil && providerType == types.LocalProviderType {
+		return errors.Wrap(ErrorUnsupportedLocalField(userconfig.MeshKey), api.Identify(), userconfig.NetworkingKey)
+	}
+
+	if api.Networking.CORS != nil && providerType == types.LocalProviderType {
+		return errors.Wrap(ErrorUnsupportedLocalField(userconfig.CORSKey), api.Identify(), userconfig.NetworkingKey)
+	}
+
+	if api.Networking.Headers != nil {
+		if providerType == types.LocalProviderType {
+			return errors.Wrap(ErrorUnsupportedLocalField(userconfig.HeadersKey), api.Identify(), userconfig.NetworkingKey)
+		}
+		// propagated headers are set on the response
+		if api.Networking.Headers.Response != nil {
+			for _, name := range api.Networking.Headers.Propagate {
+				if _, ok := api.Networking.Headers.Response.Set[name]; ok {
+					return errors.Wrap(ErrorConflictingFields(userconfig.PropagateKey, userconfig.ResponseKey+"."+userconfig.SetKey), api.Identify(), userconfig.NetworkingKey, userconfig.HeadersKey, name)
+				}
+			}
+		}
+	}
+
+	if len(api.Networking.AdditionalEndpoints) > 0 {
+		if providerType == types.LocalProviderType {
+			return errors.Wrap(ErrorUnsupportedLocalField(userconfig.AdditionalEndpointsKey), api.Identify(), userconfig.NetworkingKey)
+		}
+		if dupEndpoints := slices.FindDuplicateStrs(api.Endpoints()); len(dupEndpoints) > 0 {
+			return errors.Wrap(ErrorEndpointSpecifiedTwice(dupEndpoints[0]), api.Identify(), userconfig.NetworkingKey, userconfig.AdditionalEndpointsKey)
+		}
+	}
+
+	if api.RolloutPolicy != nil && providerType == types.LocalProviderType {
+		return errors.Wrap(ErrorUnsupportedLocalField(userconfig.RolloutPolicyKey), api.Identify())
+	}
+
+	if api.SLO != nil && providerType == types.LocalProviderType {
+		return errors.Wrap(ErrorUnsupportedLocalField(userconfig.SLOKey), api.Identify())
+	}
+
+	if api.Experiment != nil {
+		if err := validateExperiment(api, providerType); err != nil {
+			return errors.Wrap(err, api.Identify(), userconfig.ExperimentKey)
+		}
+	}
+
+	if api.PayloadLogging != nil {
+		if providerType == types.LocalProviderType {
+			return errors.Wrap(ErrorUnsupportedLocalField(userconfig.PayloadLoggingKey), api.Identify())
+		}
+		// stream APIs don't serve requests, so there is nothing to capture
+		if api.Stream != nil {
+			return errors.Wrap(ErrorConflictingFields(userconfig.PayloadLoggingKey, userconfig.StreamKey), api.Identify())
+		}
+	}
+
+	if api.FeatureStore != nil {
+		if err := validateFeatureStore(api.FeatureStore, providerType); err != nil {
+			return errors.Wrap(err, api.Identify(), userconfig.FeatureStoreKey)
+		}
+	}
+
+	return nil
+}
+
+func validateFeatureStore(featureStore *userconfig.FeatureStore, providerType types.ProviderType) error {
+	if providerType == types.LocalProviderType {
+		return ErrorUnsupportedLocalField(userconfig.FeatureStoreKey)
 	}
 
-	if api.Autoscaling != nil { // should only be nil for local provider
-		if err := validateAutoscaling(api); err != nil {
-			return errors.Wrap(err, api.Identify(), userconfig.AutoscalingKey)
+	switch featureStore.Type {
+	case userconfig.RedisFeatureStoreType:
+		if !strings.HasPrefix(featureStore.URL, "redis://") && !strings.HasPrefix(featureStore.URL, "rediss://") {
+			return errors.Wrap(ErrorInvalidFeatureStoreURL(featureStore.URL, featureStore.Type), userconfig.FeatureStoreURLKey)
+		}
+		if featureStore.Project != nil {
+			return ErrorFieldNotSupportedByFeatureStoreType(userconfig.ProjectKey, featureStore.Type)
+		}
+	case userconfig.FeastFeatureStoreType:
+		// the python client connects to Feast's serving service over gRPC, which doesn't take a scheme
+		if _, _, err := net.SplitHostPort(featureStore.URL); err != nil || strings.Contains(featureStore.URL, "://") {
+			return errors.Wrap(ErrorInvalidFeatureStoreURL(featureStore.URL, featureStore.Type), userconfig.FeatureStoreURLKey)
+		}
+		if featureStore.KeyPrefix != nil {
+			return ErrorFieldNotSupportedByFeatureStoreType(userconfig.KeyPrefixKey, featureStore.Type)
 		}
 	}
 
-	if err := validateCompute(api, providerType); err != nil {
-		return errors.Wrap(err, api.Identify(), userconfig.ComputeKey)
+	return nil
+}
+
+func validateInitContainers(predictor *userconfig.Predictor, providerType types.ProviderType, awsClient *aws.Client) error {
+	if providerType == types.LocalProviderType {
+		return ErrorUnsupportedLocalField(userconfig.InitContainersKey)
 	}
 
-	if api.UpdateStrategy != nil { // should only be nil for local provider
-		if err := validateUpdateStrategy(api.UpdateStrategy); err != nil {
-			return errors.Wrap(err, api.Identify(), userconfig.UpdateStrategyKey)
+	names := strset.New()
+	for i, initContainer := range predictor.InitContainers {
+		if names.Has(initContainer.Name) {
+			return ErrorDuplicateInitContainerNames(initContainer.Name)
+		}
+		names.Add(initContainer.Name)
+
+		if initContainer.Image != "" {
+			if err := validateDockerImagePath(initContainer.Image, providerType, awsClient); err != nil {
+				return errors.Wrap(err, s.Index(i), userconfig.ImageKey)
+			}
+		}
+
+		for key := range initContainer.Env {
+			if strings.HasPrefix(key, "CORTEX_") {
+				return errors.Wrap(ErrorCortexPrefixedEnvVarNotAllowed(), s.Index(i), userconfig.EnvKey, key)
+			}
 		}
 	}
 
@@ -551,18 +2175,82 @@ func validatePredictor(api *userconfig.API, projectFiles ProjectFiles, providerT
 		if err := validateONNXPredictor(predictor, providerType, projectFiles, awsClient); err != nil {
 			return err
 		}
+	case userconfig.LLMPredictorType:
+		if err := validateLLMPredictor(api, providerType, awsClient); err != nil {
+			return err
+		}
+		if err := validateDockerImagePath(predictor.LLMServingConfig.Image, providerType, awsClient); err != nil {
+			return errors.Wrap(err, userconfig.LLMServingConfigKey, userconfig.ImageKey)
+		}
+	}
+
+	if predictor.LLMServingConfig != nil && predictor.Type != userconfig.LLMPredictorType {
+		return ErrorFieldNotSupportedByPredictorType(userconfig.LLMServingConfigKey, predictor.Type)
 	}
 
 	if err := validateDockerImagePath(predictor.Image, providerType, awsClient); err != nil {
 		return errors.Wrap(err, userconfig.ImageKey)
 	}
 
+	if predictor.Batching != nil {
+		if err := validateBatching(api, providerType); err != nil {
+			return errors.Wrap(err, userconfig.BatchingKey)
+		}
+	}
+
+	if predictor.TensorFlowServingConfig != nil {
+		if err := validateTensorFlowServingConfig(predictor); err != nil {
+			return errors.Wrap(err, userconfig.TensorFlowServingConfigKey)
+		}
+	}
+
+	if predictor.ONNXRuntimeConfig != nil {
+		if err := validateONNXRuntimeConfig(api); err != nil {
+			return errors.Wrap(err, userconfig.ONNXRuntimeConfigKey)
+		}
+	}
+
+	if predictor.PrebuildDependencies && providerType == types.LocalProviderType {
+		return ErrorUnsupportedLocalField(userconfig.PrebuildDependenciesKey)
+	}
+
+	if predictor.EnvFrom != nil && providerType == types.LocalProviderType {
+		return ErrorUnsupportedLocalField(userconfig.EnvFromKey)
+	}
+
+	if predictor.ModelOptimization != nil {
+		if err := validateModelOptimization(api, providerType); err != nil {
+			return errors.Wrap(err, userconfig.ModelOptimizationKey)
+		}
+	}
+
 	for key := range predictor.Env {
 		if strings.HasPrefix(key, "CORTEX_") {
 			return errors.Wrap(ErrorCortexPrefixedEnvVarNotAllowed(), userconfig.EnvKey, key)
 		}
 	}
 
+	if len(predictor.InitContainers) > 0 {
+		if err := validateInitContainers(predictor, providerType, awsClient); err != nil {
+			return errors.Wrap(err, userconfig.InitContainersKey)
+		}
+	}
+
+	if predictor.OnShutdown != nil {
+		if providerType == types.LocalProviderType {
+			return ErrorUnsupportedLocalField(userconfig.OnShutdownKey)
+		}
+		if len(predictor.OnShutdown.Command) > 0 && predictor.OnShutdown.HTTPPath != nil {
+			return errors.Wrap(ErrorConflictingFields(userconfig.CommandKey, userconfig.HTTPPathKey), userconfig.OnShutdownKey)
+		}
+	}
+
+	if predictor.Explainer != nil {
+		if err := validateExplainer(api, providerType, projectFiles); err != nil {
+			return errors.Wrap(err, userconfig.ExplainerKey)
+		}
+	}
+
 	if _, err := projectFiles.GetFile(predictor.Path); err != nil {
 		if errors.GetKind(err) == files.ErrFileDoesNotExist {
 			return errors.Wrap(files.ErrorFileDoesNotExist(predictor.Path), userconfig.PathKey)
@@ -579,6 +2267,137 @@ func validatePredictor(api *userconfig.API, projectFiles ProjectFiles, providerT
 	return nil
 }
 
+func validateFallback(api *userconfig.API) error {
+	networking := api.Networking
+
+	if networking.FallbackAPI != nil && networking.FallbackResponse != nil {
+		return ErrorConflictingFields(userconfig.FallbackAPIKey, userconfig.FallbackResponseKey)
+	}
+
+	// both are responded with while the API has no ready replicas
+	if networking.FallbackResponse != nil && networking.MaintenanceMessage != nil {
+		return ErrorConflictingFields(userconfig.FallbackResponseKey, userconfig.MaintenanceMessageKey)
+	}
+
+	if networking.FallbackOnOverload {
+		if networking.FallbackAPI == nil && networking.FallbackResponse == nil {
+			return ErrorFallbackOnOverloadRequiresFallback()
+		}
+		// the overflow is split from the API's traffic with the route's weights, which the experiment also sets
+		if networking.FallbackAPI != nil && api.Experiment != nil {
+			return ErrorConflictingFields(userconfig.FallbackOnOverloadKey, userconfig.ExperimentKey)
+		}
+	}
+
+	return nil
+}
+
+func validateExplainer(api *userconfig.API, providerType types.ProviderType, projectFiles ProjectFiles) error {
+	explainer := api.Predictor.Explainer
+
+	if providerType == types.LocalProviderType {
+		return ErrorUnsupportedLocalField(userconfig.ExplainerKey)
+	}
+
+	// explanations are requested with a query parameter, so stream APIs have nothing to explain
+	if api.Stream != nil {
+		return ErrorConflictingFields(userconfig.ExplainerKey, userconfig.StreamKey)
+	}
+
+	if _, err := projectFiles.GetFile(explainer.Path); err != nil {
+		if errors.GetKind(err) == files.ErrFileDoesNotExist {
+			return errors.Wrap(files.ErrorFileDoesNotExist(explainer.Path), userconfig.PathKey)
+		}
+		return errors.Wrap(err, userconfig.PathKey)
+	}
+
+	return nil
+}
+
+func validateBatching(api *userconfig.API, providerType types.ProviderType) error {
+	// LLM servers batch requests continuously
+	if api.Predictor.Type == userconfig.ONNXPredictorType || api.Predictor.Type == userconfig.LLMPredictorType {
+		return ErrorFieldNotSupportedByPredictorType(userconfig.BatchingKey, api.Predictor.Type)
+	}
+
+	if providerType == types.LocalProviderType {
+		return ErrorUnsupportedLocalField(userconfig.BatchingKey)
+	}
+
+	// each worker needs enough threads to have a full batch of requests in flight
+	if api.Predictor.Batching.MaxBatchSize > api.Autoscaling.ThreadsPerWorker {
+		return ErrorConfigGreaterThanOtherConfig(userconfig.MaxBatchSizeKey, api.Predictor.Batching.MaxBatchSize, userconfig.ThreadsPerWorkerKey, api.Autoscaling.ThreadsPerWorker)
+	}
+
+	return nil
+}
+
+func validateModelOptimization(api *userconfig.API, providerType types.ProviderType) error {
+	if api.Predictor.Type == userconfig.PythonPredictorType || api.Predictor.Type == userconfig.LLMPredictorType {
+		return ErrorFieldNotSupportedByPredictorType(userconfig.ModelOptimizationKey, api.Predictor.Type)
+	}
+
+	if providerType == types.LocalProviderType {
+		return ErrorUnsupportedLocalField(userconfig.ModelOptimizationKey)
+	}
+
+	// models are optimized with TensorRT (TensorFlow) or the CUDA execution provider (ONNX) for the GPU they will be served on
+	if api.Compute.GPU == 0 {
+		return ErrorModelOptimizationRequiresGPU()
+	}
+
+	return nil
+}
+
+// flags which cortex sets on tensorflow_model_server (batching is configured with the batching field)
+var _reservedTensorFlowServingFlags = strset.New(
+	"port",
+	"rest_api_port",
+	"model_config_file",
+	"model_name",
+	"model_base_path",
+	"enable_batching",
+	"batching_parameters_file",
+)
+
+func validateTensorFlowServingConfig(predictor *userconfig.Predictor) error {
+	if predictor.Type != userconfig.TensorFlowPredictorType {
+		return ErrorFieldNotSupportedByPredictorType(userconfig.TensorFlowServingConfigKey, predictor.Type)
+	}
+
+	flags := make(map[string]string, len(predictor.TensorFlowServingConfig.Flags))
+	for flagName, value := range predictor.TensorFlowServingConfig.Flags {
+		flagName = strings.TrimLeft(flagName, "-")
+		if _reservedTensorFlowServingFlags.Has(flagName) {
+			return errors.Wrap(ErrorReservedTensorFlowServingFlag(flagName), userconfig.FlagsKey)
+		}
+		flags[flagName] = value
+	}
+	predictor.TensorFlowServingConfig.Flags = flags
+
+	return nil
+}
+
+var _onnxExecutionProviders = []string{"cuda", "tensorrt", "openvino", "cpu"}
+
+var _gpuONNXExecutionProviders = strset.New("cuda", "tensorrt")
+
+func validateONNXRuntimeConfig(api *userconfig.API) error {
+	if api.Predictor.Type != userconfig.ONNXPredictorType {
+		return ErrorFieldNotSupportedByPredictorType(userconfig.ONNXRuntimeConfigKey, api.Predictor.Type)
+	}
+
+	if api.Compute.GPU == 0 {
+		for _, provider := range api.Predictor.ONNXRuntimeConfig.ExecutionProviders {
+			if _gpuONNXExecutionProviders.Has(provider) {
+				return errors.Wrap(ErrorONNXExecutionProviderRequiresGPU(provider), userconfig.ExecutionProvidersKey)
+			}
+		}
+	}
+
+	return nil
+}
+
 func validatePythonPredictor(predictor *userconfig.Predictor) error {
 	if predictor.SignatureKey != nil {
 		return ErrorFieldNotSupportedByPredictorType(userconfig.SignatureKeyKey, userconfig.PythonPredictorType)
@@ -599,6 +2418,62 @@ func validatePythonPredictor(predictor *userconfig.Predictor) error {
 	return nil
 }
 
+func validateLLMPredictor(api *userconfig.API, providerType types.ProviderType, awsClient *aws.Client) error {
+	predictor := api.Predictor
+
+	if providerType == types.LocalProviderType {
+		return ErrorUnsupportedLocalField(userconfig.LLMServingConfigKey)
+	}
+
+	if predictor.SignatureKey != nil {
+		return ErrorFieldNotSupportedByPredictorType(userconfig.SignatureKeyKey, predictor.Type)
+	}
+	if len(predictor.Models) > 0 {
+		return ErrorFieldNotSupportedByPredictorType(userconfig.ModelsKey, predictor.Type)
+	}
+	if predictor.TensorFlowServingImage != "" {
+		return ErrorFieldNotSupportedByPredictorType(userconfig.TensorFlowServingImageKey, predictor.Type)
+	}
+	if predictor.LLMServingConfig == nil {
+		return ErrorFieldMustBeDefinedForPredictorType(userconfig.LLMServingConfigKey, predictor.Type)
+	}
+	if predictor.Model == nil {
+		return ErrorFieldMustBeDefinedForPredictorType(userconfig.ModelKey, predictor.Type)
+	}
+
+	// the model is a directory of weights in the Hugging Face format (or an OCI artifact of its files), which the
+	// downloader copies to the replica
+	if docker.IsOCIPath(*predictor.Model) {
+		if !docker.IsValidOCIPath(*predictor.Model) {
+			return errors.Wrap(ErrorInvalidOCIPath(*predictor.Model), userconfig.ModelKey)
+		}
+	} else {
+		model, err := cr.S3PathValidator(*predictor.Model)
+		if err != nil {
+			return errors.Wrap(err, userconfig.ModelKey)
+		}
+		awsClientForBucket, err := aws.NewFromClientS3Path(model, awsClient)
+		if err != nil {
+			return errors.Wrap(err, userconfig.ModelKey)
+		}
+		if ok, err := awsClientForBucket.IsS3PathDir(model); err != nil || !ok {
+			return errors.Wrap(ErrorS3FileNotFound(model), userconfig.ModelKey)
+		}
+		predictor.Model = &model
+	}
+
+	if api.Compute.GPU == 0 {
+		return ErrorLLMPredictorRequiresGPU()
+	}
+
+	// TGI only shards models with tensor parallelism
+	if predictor.LLMServingConfig.Server == userconfig.TGIServerType && api.Compute.Parallelism != nil && api.Compute.Parallelism.Pipeline > 1 {
+		return errors.Wrap(ErrorFieldNotSupportedByLLMServer(userconfig.PipelineParallelismKey, predictor.LLMServingConfig.Server), userconfig.ComputeKey, userconfig.ParallelismKey)
+	}
+
+	return nil
+}
+
 func validateTensorFlowPredictor(api *userconfig.API, providerType types.ProviderType, projectFiles ProjectFiles, awsClient *aws.Client) error {
 	predictor := api.Predictor
 
@@ -666,7 +2541,7 @@ func validateTensorFlowModel(modelResource *userconfig.ModelResource, api *userc
 			modelResource.Model = path
 		}
 	} else {
-		if providerType == types.AWSProviderType {
+		if providerType != types.LocalProviderType {
 			return errors.Wrap(ErrorLocalModelPathNotSupportedByAWSProvider(), model, userconfig.ModelKey)
 		}
 
@@ -760,7 +2635,7 @@ func validateONNXModel(modelResource *userconfig.ModelResource, providerType typ
 			return errors.Wrap(ErrorS3FileNotFound(model), userconfig.ModelKey)
 		}
 	} else {
-		if providerType == types.AWSProviderType {
+		if providerType != types.LocalProviderType {
 			return errors.Wrap(ErrorLocalModelPathNotSupportedByAWSProvider(), model, userconfig.ModelKey)
 		}
 
@@ -951,6 +2826,18 @@ func validateAutoscaling(api *userconfig.API) error {
 		return ErrorConfigGreaterThanOtherConfig(userconfig.TargetReplicaConcurrencyKey, *autoscaling.TargetReplicaConcurrency, userconfig.MaxReplicaConcurrencyKey, autoscaling.MaxReplicaConcurrency)
 	}
 
+	if autoscaling.MaxQueueLength != nil && autoscaling.OverloadBehavior != userconfig.ShedOverloadBehaviorType {
+		return ErrorMaxQueueLengthRequiresShed()
+	}
+
+	if len(autoscaling.KEDATriggers) > 0 && autoscaling.Autoscaler != userconfig.KEDAAutoscalerType {
+		return ErrorKEDATriggersRequireKEDA()
+	}
+
+	if autoscaling.IdleTimeout != nil && autoscaling.Autoscaler == userconfig.KEDAAutoscalerType {
+		return ErrorIdleTimeoutWithKEDA()
+	}
+
 	if autoscaling.MinReplicas > autoscaling.MaxReplicas {
 		return ErrorMinReplicasGreaterThanMax(autoscaling.MinReplicas, autoscaling.MaxReplicas)
 	}
@@ -974,6 +2861,153 @@ func validateAutoscaling(api *userconfig.API) error {
 	return nil
 }
 
+func validateExperiment(api *userconfig.API, providerType types.ProviderType) error {
+	if providerType == types.LocalProviderType {
+		return ErrorUnsupportedLocalField(userconfig.ExperimentKey)
+	}
+
+	// stream APIs don't serve requests, so there is no traffic to split
+	if api.Stream != nil {
+		return ErrorConflictingFields(userconfig.ExperimentKey, userconfig.StreamKey)
+	}
+
+	variantNames := strset.New()
+	var totalWeight int32
+	for i, variant := range api.Experiment.Variants {
+		if variant.API == api.Name {
+			return errors.Wrap(ErrorExperimentVariantIsSelf(api.Name), userconfig.VariantsKey, s.Index(i), userconfig.VariantAPIKey)
+		}
+		if variantNames.Has(variant.API) {
+			return errors.Wrap(ErrorDuplicateExperimentVariant(variant.API), userconfig.VariantsKey, s.Index(i), userconfig.VariantAPIKey)
+		}
+		variantNames.Add(variant.API)
+		totalWeight += variant.Weight
+	}
+
+	if totalWeight >= 100 {
+		return errors.Wrap(ErrorExperimentWeightsTooHigh(totalWeight), userconfig.VariantsKey)
+	}
+
+	if bandit := api.Experiment.Bandit; bandit != nil {
+		numAPIs := int32(len(api.Experiment.Variants) + 1)
+		if bandit.MinWeight > bandit.MaxWeight {
+			return errors.Wrap(ErrorMinWeightGreaterThanMaxWeight(bandit.MinWeight, bandit.MaxWeight), userconfig.BanditKey)
+		}
+		if bandit.MinWeight*numAPIs > 100 || bandit.MaxWeight*numAPIs < 100 {
+			return errors.Wrap(ErrorInvalidBanditWeights(bandit.MinWeight, bandit.MaxWeight, numAPIs), userconfig.BanditKey)
+		}
+	}
+
+	return nil
+}
+
+// the operator submits the batch jobs, so the API must be able to run them (the other requirements of batch jobs are checked
+// when the jobs are submitted)
+func validateBatchTrigger(api *userconfig.API, providerType types.ProviderType) error {
+	if providerType == types.LocalProviderType {
+		return ErrorUnsupportedLocalField(userconfig.BatchTriggerKey)
+	}
+	if api.Stream != nil {
+		return ErrorBatchTriggerNotSupported("the api is a stream api")
+	}
+	if api.Predictor.Type != userconfig.PythonPredictorType && api.Predictor.Type != userconfig.ONNXPredictorType {
+		return ErrorBatchTriggerNotSupported(fmt.Sprintf("batch jobs only support the %s and %s predictor types", userconfig.PythonPredictorType, userconfig.ONNXPredictorType))
+	}
+	return nil
+}
+
+func validateStream(api *userconfig.API, providerType types.ProviderType) error {
+	stream := api.Stream
+
+	if providerType == types.LocalProviderType {
+		return ErrorUnsupportedLocalField(userconfig.StreamKey)
+	}
+
+	if stream.Source != userconfig.SQSStreamSourceType {
+		if stream.S3Trigger != nil {
+			return ErrorFieldNotSupportedByStreamSource(userconfig.S3TriggerKey, stream.Source)
+		}
+		if stream.DeadLetterQueue != nil {
+			return ErrorFieldNotSupportedByStreamSource(userconfig.DeadLetterQueueKey, stream.Source)
+		}
+		if stream.MaxReceiveCount != nil {
+			return ErrorFieldNotSupportedByStreamSource(userconfig.MaxReceiveCountKey, stream.Source)
+		}
+	}
+
+	if stream.Source != userconfig.KinesisStreamSourceType && stream.ShardsPerReplica != nil {
+		return ErrorFieldNotSupportedByStreamSource(userconfig.ShardsPerReplicaKey, stream.Source)
+	}
+
+	switch stream.Source {
+	case userconfig.KafkaStreamSourceType:
+		if len(stream.Brokers) == 0 {
+			return ErrorFieldRequiredByStreamSource(userconfig.BrokersKey, stream.Source)
+		}
+		if api.Autoscaling.Autoscaler != userconfig.KEDAAutoscalerType {
+			return ErrorStreamRequiresKEDA(stream.Source)
+		}
+		if stream.ConsumerGroup == nil {
+			stream.ConsumerGroup = pointer.String(api.Name)
+		}
+		if stream.TargetLag == nil {
+			stream.TargetLag = pointer.Int64(100)
+		}
+	case userconfig.KinesisStreamSourceType:
+		if len(stream.Brokers) > 0 {
+			return ErrorFieldNotSupportedByStreamSource(userconfig.BrokersKey, stream.Source)
+		}
+		if stream.ConsumerGroup != nil {
+			return ErrorFieldNotSupportedByStreamSource(userconfig.ConsumerGroupKey, stream.Source)
+		}
+		if stream.TargetLag != nil {
+			return ErrorFieldNotSupportedByStreamSource(userconfig.TargetLagKey, stream.Source)
+		}
+		if api.Autoscaling.Autoscaler != userconfig.KEDAAutoscalerType {
+			return ErrorStreamRequiresKEDA(stream.Source)
+		}
+		if stream.ShardsPerReplica == nil {
+			stream.ShardsPerReplica = pointer.Int64(1)
+		}
+	case userconfig.SQSStreamSourceType:
+		if len(stream.Brokers) > 0 {
+			return ErrorFieldNotSupportedByStreamSource(userconfig.BrokersKey, stream.Source)
+		}
+		if stream.ConsumerGroup != nil {
+			return ErrorFieldNotSupportedByStreamSource(userconfig.ConsumerGroupKey, stream.Source)
+		}
+		if stream.BatchSize > 10 {
+			return ErrorStreamBatchSizeExceedsLimit(stream.Source, 10) // the maximum number of messages which SQS returns per request
+		}
+		if api.Autoscaling.Autoscaler != userconfig.KEDAAutoscalerType {
+			return ErrorStreamRequiresKEDA(stream.Source)
+		}
+		if stream.TargetLag == nil {
+			stream.TargetLag = pointer.Int64(100)
+		}
+		if stream.DeadLetterQueue == nil && stream.MaxReceiveCount != nil {
+			return ErrorMaxReceiveCountRequiresDLQ()
+		}
+		if stream.DeadLetterQueue != nil && stream.MaxReceiveCount == nil {
+			stream.MaxReceiveCount = pointer.Int32(3)
+		}
+		// records which are written to the dead letter prefix are deleted from the queue, so they would never reach the dead letter queue
+		if stream.DeadLetterQueue != nil && stream.DeadLetterPrefix != nil {
+			return ErrorConflictingFields(userconfig.DeadLetterQueueKey, userconfig.DeadLetterPrefixKey)
+		}
+	}
+
+	// Kinesis records don't have attributes which could specify their own callback URL
+	if stream.Callback != nil && stream.Source == userconfig.KinesisStreamSourceType && stream.Callback.URL == nil && stream.Callback.SNSTopic == nil {
+		return errors.Wrap(ErrorFieldRequiredByStreamSource(userconfig.CallbackURLKey+" or "+userconfig.SNSTopicKey, stream.Source), userconfig.CallbackKey)
+	}
+
+	// stream APIs don't serve prediction requests, so there is nothing to expose through the API Gateway
+	api.Networking.APIGateway = userconfig.NoneAPIGatewayType
+
+	return nil
+}
+
 func validateCompute(api *userconfig.API, providerType types.ProviderType) error {
 	compute := api.Compute
 
@@ -993,6 +3027,72 @@ func validateCompute(api *userconfig.API, providerType types.ProviderType) error
 		return ErrorInvalidNumberOfInfs(compute.Inf)
 	}
 
+	if compute.EphemeralStorage != nil && providerType == types.LocalProviderType {
+		return ErrorUnsupportedLocalComputeResource(userconfig.EphemeralStorageKey)
+	}
+
+	if compute.Spot != nil && providerType == types.LocalProviderType {
+		return ErrorUnsupportedLocalComputeResource(userconfig.SpotKey)
+	}
+
+	if compute.OnDemandFallback && (compute.Spot == nil || !*compute.Spot) {
+		return ErrorOnDemandFallbackRequiresSpot()
+	}
+
+	if compute.NodeGroup != nil && providerType == types.LocalProviderType {
+		return ErrorUnsupportedLocalComputeResource(userconfig.NodeGroupKey)
+	}
+
+	if compute.NodeGroup != nil && compute.Spot != nil {
+		return ErrorConflictingFields(userconfig.NodeGroupKey, userconfig.SpotKey)
+	}
+
+	if compute.Priority != userconfig.DefaultPriorityType && providerType == types.LocalProviderType {
+		return ErrorUnsupportedLocalComputeResource(userconfig.PriorityKey)
+	}
+
+	if compute.Parallelism != nil {
+		if providerType == types.LocalProviderType {
+			return ErrorUnsupportedLocalComputeResource(userconfig.ParallelismKey)
+		}
+		// TensorFlow Serving and ONNX Runtime load each model onto a single device
+		if api.Predictor.Type != userconfig.PythonPredictorType && api.Predictor.Type != userconfig.LLMPredictorType {
+			return ErrorFieldNotSupportedByPredictorType(userconfig.ParallelismKey, api.Predictor.Type)
+		}
+		// gpu is the request of each of the replica's pods
+		if compute.GPU*compute.Parallelism.Nodes != compute.Parallelism.Degree() {
+			return ErrorParallelismGPUMismatch(*compute.Parallelism, compute.GPU)
+		}
+		// the LLM servers only shard models across the GPUs of one pod
+		if compute.Parallelism.IsMultiNode() && api.Predictor.Type != userconfig.PythonPredictorType {
+			return errors.Wrap(ErrorFieldNotSupportedByPredictorType(userconfig.NodesParallelismKey, api.Predictor.Type), userconfig.ParallelismKey)
+		}
+		// the LLM server loads the model once, regardless of the number of workers which forward requests to it
+		if api.Autoscaling.WorkersPerReplica > 1 && api.Predictor.Type != userconfig.LLMPredictorType {
+			return ErrorParallelismRequiresSingleWorker(api.Autoscaling.WorkersPerReplica)
+		}
+	}
+
+	if compute.ScratchVolume != nil {
+		if providerType == types.LocalProviderType {
+			return ErrorUnsupportedLocalComputeResource(userconfig.ScratchVolumeKey)
+		}
+		// a ReadWriteOnce volume can only be attached to one node, so replicas which are scheduled onto other nodes would never start
+		if compute.ScratchVolume.AccessMode == userconfig.ReadWriteOnceAccessModeType {
+			if api.Autoscaling.MaxReplicas > 1 {
+				return errors.Wrap(ErrorScratchVolumeRequiresSingleReplica(api.Autoscaling.MaxReplicas), userconfig.ScratchVolumeKey, userconfig.AccessModeKey)
+			}
+			if api.UpdateStrategy.MaxSurge != "0" && api.UpdateStrategy.MaxSurge != "0%" {
+				return errors.Wrap(ErrorScratchVolumeRequiresNoSurge(api.UpdateStrategy.MaxSurge), userconfig.ScratchVolumeKey, userconfig.AccessModeKey)
+			}
+		}
+	}
+
+	// the shared memory volume is memory-backed, so it counts towards the API container's memory usage
+	if compute.ShmSize != nil && compute.Mem != nil && compute.ShmSize.Cmp(compute.Mem.Quantity) > 0 {
+		return ErrorShmSizeExceedsMem(*compute.ShmSize, *compute.Mem)
+	}
+
 	return nil
 }
 
@@ -1086,6 +3186,10 @@ func validateDockerImagePath(image string, providerType types.ProviderType, awsC
 
 			return err
 		}
+	} else if regex.IsValidGCRURL(image) && providerType != types.LocalProviderType {
+		// GKE nodes pull from Container Registry and Artifact Registry as their node pool's service account, which the
+		// operator can't authenticate as (the operator checks these images when it pins their digests)
+		return nil
 	}
 
 	if err := docker.CheckImageAccessible(dockerClient, image, dockerAuth); err != nil {
@@ -25,6 +25,7 @@ const (
 	OOM
 	Live
 	Updating
+	Paused
 )
 
 var _codes = []string{
@@ -34,9 +35,10 @@ var _codes = []string{
 	"status_oom",
 	"status_live",
 	"status_updating",
+	"status_paused",
 }
 
-var _ = [1]int{}[int(Updating)-(len(_codes)-1)] // Ensure list length matches
+var _ = [1]int{}[int(Paused)-(len(_codes)-1)] // Ensure list length matches
 
 var _codeMessages = []string{
 	"unknown",               // Unknown
@@ -45,9 +47,10 @@ var _codeMessages = []string{
 	"error (out of memory)", // OOM
 	"live",                  // Live
 	"updating",              // Updating
+	"paused",                // Paused
 }
 
-var _ = [1]int{}[int(Updating)-(len(_codeMessages)-1)] // Ensure list length matches
+var _ = [1]int{}[int(Paused)-(len(_codeMessages)-1)] // Ensure list length matches
 
 func (code Code) String() string {
 	if int(code) < 0 || int(code) >= len(_codes) {
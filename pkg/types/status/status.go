@@ -16,11 +16,38 @@ limitations under the License.
 
 package status
 
+import (
+	"time"
+)
+
 type Status struct {
 	APIName       string `json:"api_name"`
 	APIID         string `json:"api_id"`
 	Code          Code   `json:"status_code"`
 	ReplicaCounts `json:"replica_counts"`
+	Replicas      []ReplicaStatus `json:"replicas,omitempty"` // only included in the status of a single API
+	Warnings      []HealthWarning `json:"warnings,omitempty"` // only included in the status of a single API
+}
+
+// HealthWarning describes a pattern in the health of an API's replicas which is likely to need attention (e.g. replicas
+// of one version crash looping while the other version is healthy)
+type HealthWarning struct {
+	Type    string    `json:"type"`
+	Message string    `json:"message"`
+	Since   time.Time `json:"since"`
+}
+
+// ReplicaStatus describes a single replica (pod) of an API
+type ReplicaStatus struct {
+	Name                  string     `json:"name"`
+	Updated               bool       `json:"updated"` // running the API's latest version
+	Phase                 string     `json:"phase"`   // e.g. ready, pending, initializing, out of memory
+	Restarts              int32      `json:"restarts"`
+	LastTerminationReason string     `json:"last_termination_reason,omitempty"` // of the most recently terminated container, e.g. OOMKilled or Error
+	LastTerminationTime   *time.Time `json:"last_termination_time,omitempty"`
+	ModelStatus           string     `json:"model_status,omitempty"` // downloading, loading, or loaded
+	Node                  string     `json:"node"`
+	CreatedAt             time.Time  `json:"created_at"`
 }
 
 type ReplicaCounts struct {
@@ -48,3 +75,7 @@ func (status *Status) Message() string {
 func (src *SubReplicaCounts) TotalFailed() int32 {
 	return src.Failed + src.Killed + src.KilledOOM + src.Stalled
 }
+
+func (src *SubReplicaCounts) Total() int32 {
+	return src.Pending + src.Initializing + src.Ready + src.Terminating + src.TotalFailed() + src.Unknown
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+type AccessModeType int
+
+const (
+	UnknownAccessModeType AccessModeType = iota
+	ReadWriteOnceAccessModeType
+	ReadWriteManyAccessModeType
+)
+
+var _accessModeTypes = []string{
+	"unknown",
+	"read_write_once",
+	"read_write_many",
+}
+
+func AccessModeTypeFromString(s string) AccessModeType {
+	for i := 0; i < len(_accessModeTypes); i++ {
+		if s == _accessModeTypes[i] {
+			return AccessModeType(i)
+		}
+	}
+	return UnknownAccessModeType
+}
+
+func AccessModeTypeStrings() []string {
+	return _accessModeTypes[1:]
+}
+
+func (t AccessModeType) String() string {
+	return _accessModeTypes[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t AccessModeType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *AccessModeType) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(_accessModeTypes); i++ {
+		if enum == _accessModeTypes[i] {
+			*t = AccessModeType(i)
+			return nil
+		}
+	}
+
+	*t = UnknownAccessModeType
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *AccessModeType) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t AccessModeType) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
@@ -18,10 +18,12 @@ package userconfig
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
 	"github.com/cortexlabs/cortex/pkg/lib/k8s"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/types"
@@ -30,31 +32,115 @@ import (
 )
 
 type API struct {
-	Name           string          `json:"name" yaml:"name"`
-	Endpoint       *string         `json:"endpoint" yaml:"endpoint"`
-	LocalPort      *int            `json:"local_port" yaml:"local_port"`
-	Predictor      *Predictor      `json:"predictor" yaml:"predictor"`
-	Monitoring     *Monitoring     `json:"monitoring" yaml:"monitoring"`
-	Networking     *Networking     `json:"networking" yaml:"networking"`
-	Compute        *Compute        `json:"compute" yaml:"compute"`
-	Autoscaling    *Autoscaling    `json:"autoscaling" yaml:"autoscaling"`
-	UpdateStrategy *UpdateStrategy `json:"update_strategy" yaml:"update_strategy"`
+	Name           string            `json:"name" yaml:"name"`
+	Endpoint       *string           `json:"endpoint" yaml:"endpoint"`
+	Namespace      string            `json:"namespace" yaml:"namespace"`
+	Labels         map[string]string `json:"labels" yaml:"labels"`
+	Annotations    map[string]string `json:"annotations" yaml:"annotations"`
+	MetricTags     map[string]string `json:"metric_tags" yaml:"metric_tags"` // added to the API's request metrics in the statsd metric sink
+	LocalPort      *int              `json:"local_port" yaml:"local_port"`
+	Predictor      *Predictor        `json:"predictor" yaml:"predictor"`
+	Monitoring     *Monitoring       `json:"monitoring" yaml:"monitoring"`
+	Networking     *Networking       `json:"networking" yaml:"networking"`
+	Compute        *Compute          `json:"compute" yaml:"compute"`
+	Autoscaling    *Autoscaling      `json:"autoscaling" yaml:"autoscaling"`
+	UpdateStrategy *UpdateStrategy   `json:"update_strategy" yaml:"update_strategy"`
+	Notifications  *Notifications    `json:"notifications" yaml:"notifications"`
+	Stream         *Stream           `json:"stream" yaml:"stream"`
+	BatchTrigger   *BatchTrigger     `json:"batch_trigger" yaml:"batch_trigger"`
+	RolloutPolicy  *RolloutPolicy    `json:"rollout_policy" yaml:"rollout_policy"`
+	Experiment     *Experiment       `json:"experiment" yaml:"experiment"`
+	PayloadLogging *PayloadLogging   `json:"payload_logging" yaml:"payload_logging"`
+	FeatureStore   *FeatureStore     `json:"feature_store" yaml:"feature_store"`
+	SLO            *SLO              `json:"slo" yaml:"slo"`
 
 	Index    int    `json:"index" yaml:"-"`
 	FilePath string `json:"file_path" yaml:"-"`
 }
 
 type Predictor struct {
-	Type                   PredictorType          `json:"type" yaml:"type"`
-	Path                   string                 `json:"path" yaml:"path"`
-	Model                  *string                `json:"model" yaml:"model"`
-	Models                 []*ModelResource       `json:"models" yaml:"models"`
-	PythonPath             *string                `json:"python_path" yaml:"python_path"`
-	Image                  string                 `json:"image" yaml:"image"`
-	TensorFlowServingImage string                 `json:"tensorflow_serving_image" yaml:"tensorflow_serving_image"`
-	Config                 map[string]interface{} `json:"config" yaml:"config"`
-	Env                    map[string]string      `json:"env" yaml:"env"`
-	SignatureKey           *string                `json:"signature_key" yaml:"signature_key"`
+	Type                    PredictorType            `json:"type" yaml:"type"`
+	Path                    string                   `json:"path" yaml:"path"`
+	Model                   *string                  `json:"model" yaml:"model"`
+	Models                  []*ModelResource         `json:"models" yaml:"models"`
+	PythonPath              *string                  `json:"python_path" yaml:"python_path"`
+	Image                   string                   `json:"image" yaml:"image"`
+	TensorFlowServingImage  string                   `json:"tensorflow_serving_image" yaml:"tensorflow_serving_image"`
+	ImagePullPolicy         ImagePullPolicyType      `json:"image_pull_policy" yaml:"image_pull_policy"`
+	PrebuildDependencies    bool                     `json:"prebuild_dependencies" yaml:"prebuild_dependencies"`
+	Config                  map[string]interface{}   `json:"config" yaml:"config"`
+	Env                     map[string]string        `json:"env" yaml:"env"`
+	EnvFrom                 *EnvFrom                 `json:"env_from" yaml:"env_from"`
+	SignatureKey            *string                  `json:"signature_key" yaml:"signature_key"`
+	Batching                *Batching                `json:"batching" yaml:"batching"`
+	TensorFlowServingConfig *TensorFlowServingConfig `json:"tensorflow_serving_config" yaml:"tensorflow_serving_config"`
+	ONNXRuntimeConfig       *ONNXRuntimeConfig       `json:"onnx_runtime_config" yaml:"onnx_runtime_config"`
+	ModelOptimization       *ModelOptimization       `json:"model_optimization" yaml:"model_optimization"`
+	LLMServingConfig        *LLMServingConfig        `json:"llm_serving_config" yaml:"llm_serving_config"`
+	InitContainers          []*InitContainer         `json:"init_containers" yaml:"init_containers"`
+	OnShutdown              *OnShutdown              `json:"on_shutdown" yaml:"on_shutdown"`
+	Explainer               *Explainer               `json:"explainer" yaml:"explainer"`
+}
+
+// Explainer runs the user's explainer (e.g. with SHAP or Captum) in a sidecar container of each replica; requests which include
+// the explain=true query parameter are responded to with the prediction's feature attributions alongside the prediction
+type Explainer struct {
+	Path    string        `json:"path" yaml:"path"`
+	CPU     *k8s.Quantity `json:"cpu" yaml:"cpu"`
+	Mem     *k8s.Quantity `json:"mem" yaml:"mem"`
+	Timeout time.Duration `json:"timeout" yaml:"timeout"` // how long the API container waits for each explanation
+}
+
+// InitContainer runs a command in each replica after the project and models have been downloaded, and before the API starts
+// OnShutdown configures the hook which runs in the API container before it's stopped (e.g. when the API is scaled down)
+type OnShutdown struct {
+	Command  []string      `json:"command" yaml:"command"`
+	HTTPPath *string       `json:"http_path" yaml:"http_path"` // requested (GET) from the API container
+	Timeout  time.Duration `json:"timeout" yaml:"timeout"`     // applies to the hook and to the predictor's on_shutdown() method
+}
+
+type InitContainer struct {
+	Name    string            `json:"name" yaml:"name"`
+	Image   string            `json:"image" yaml:"image"` // if empty, the API container's image is used
+	Command []string          `json:"command" yaml:"command"`
+	Env     map[string]string `json:"env" yaml:"env"`
+	CPU     *k8s.Quantity     `json:"cpu" yaml:"cpu"`
+	Mem     *k8s.Quantity     `json:"mem" yaml:"mem"`
+}
+
+// LLMServingConfig configures the server (vLLM or TGI) which serves the model of an llm predictor
+type LLMServingConfig struct {
+	Server               LLMServerType `json:"server" yaml:"server"`
+	Image                string        `json:"image" yaml:"image"`
+	MaxTokens            *int64        `json:"max_tokens" yaml:"max_tokens"` // the maximum number of tokens per request (the prompt and the generated tokens)
+	GPUMemoryUtilization float64       `json:"gpu_memory_utilization" yaml:"gpu_memory_utilization"`
+	Args                 []string      `json:"args" yaml:"args"` // additional command-line arguments for the server
+}
+
+type ModelOptimization struct {
+	Precision PrecisionType `json:"precision" yaml:"precision"`
+}
+
+type EnvFrom struct {
+	ConfigMaps []string `json:"config_maps" yaml:"config_maps"`
+	Secrets    []string `json:"secrets" yaml:"secrets"`
+}
+
+type ONNXRuntimeConfig struct {
+	ExecutionProviders     []string                   `json:"execution_providers" yaml:"execution_providers"`
+	IntraOpNumThreads      int32                      `json:"intra_op_num_threads" yaml:"intra_op_num_threads"`
+	InterOpNumThreads      int32                      `json:"inter_op_num_threads" yaml:"inter_op_num_threads"`
+	GraphOptimizationLevel GraphOptimizationLevelType `json:"graph_optimization_level" yaml:"graph_optimization_level"`
+}
+
+type TensorFlowServingConfig struct {
+	Flags       map[string]string `json:"flags" yaml:"flags"`
+	ModelConfig *string           `json:"model_config" yaml:"model_config"`
+}
+
+type Batching struct {
+	MaxBatchSize  int32         `json:"max_batch_size" yaml:"max_batch_size"`
+	BatchInterval time.Duration `json:"batch_interval" yaml:"batch_interval"`
 }
 
 type ModelResource struct {
@@ -69,31 +155,128 @@ type Monitoring struct {
 }
 
 type Networking struct {
-	APIGateway APIGatewayType `json:"api_gateway" yaml:"api_gateway"`
+	APIGateway         APIGatewayType    `json:"api_gateway" yaml:"api_gateway"`
+	Compression        CompressionType   `json:"compression" yaml:"compression"`
+	FallbackAPI        *string           `json:"fallback_api" yaml:"fallback_api"`
+	FallbackResponse   *FallbackResponse `json:"fallback_response" yaml:"fallback_response"`
+	FallbackOnOverload bool              `json:"fallback_on_overload" yaml:"fallback_on_overload"` // whether the requests which exceed the API's capacity are also sent to its fallback
+	MaintenanceMessage *string           `json:"maintenance_message" yaml:"maintenance_message"`
+	VersionPinning     bool              `json:"version_pinning" yaml:"version_pinning"`
+	Mesh               *Mesh             `json:"mesh" yaml:"mesh"`
+	CORS               *CORS             `json:"cors" yaml:"cors"`
+	// AdditionalEndpoints are routed to the API in addition to its endpoint (e.g. to keep serving the API's previous URL)
+	AdditionalEndpoints []*AdditionalEndpoint `json:"additional_endpoints" yaml:"additional_endpoints"`
+	Headers             *Headers              `json:"headers" yaml:"headers"`
+}
+
+// FallbackResponse is responded with by the APIs gateway (rather than routing the request to the API) while the API has no
+// ready replicas
+type FallbackResponse struct {
+	StatusCode  int32  `json:"status_code" yaml:"status_code"`
+	ContentType string `json:"content_type" yaml:"content_type"`
+	Body        string `json:"body" yaml:"body"`
+}
+
+// Headers are the changes which the APIs gateway makes to the headers of the API's requests (before they reach the API)
+// and responses (before they reach the client)
+type Headers struct {
+	Request  *HeaderOperations `json:"request" yaml:"request"`
+	Response *HeaderOperations `json:"response" yaml:"response"`
+	// Propagate are request headers which are copied to the response (e.g. x-request-id)
+	Propagate []string `json:"propagate" yaml:"propagate"`
+}
+
+type HeaderOperations struct {
+	Set    map[string]string `json:"set" yaml:"set"`       // overwrites the header if it is present
+	Add    map[string]string `json:"add" yaml:"add"`       // appends to the header if it is present
+	Remove []string          `json:"remove" yaml:"remove"` // applied before set and add
+}
+
+type AdditionalEndpoint struct {
+	Endpoint string   `json:"endpoint" yaml:"endpoint"`
+	Methods  []string `json:"methods" yaml:"methods"`
+	Rewrite  string   `json:"rewrite" yaml:"rewrite"`
+}
+
+// CORS is the cross-origin resource sharing policy which the APIs gateway applies to the API's responses (and which it
+// uses to respond to preflight requests without forwarding them to the API)
+type CORS struct {
+	AllowOrigins []string      `json:"allow_origins" yaml:"allow_origins"`
+	AllowMethods []string      `json:"allow_methods" yaml:"allow_methods"`
+	AllowHeaders []string      `json:"allow_headers" yaml:"allow_headers"`
+	MaxAge       time.Duration `json:"max_age" yaml:"max_age"`
+}
+
+// Mesh configures the istio sidecar which is injected into the API's pods
+type Mesh struct {
+	Enabled    bool          `json:"enabled" yaml:"enabled"`
+	MTLS       *bool         `json:"mtls" yaml:"mtls"`
+	SidecarCPU *k8s.Quantity `json:"sidecar_cpu" yaml:"sidecar_cpu"`
+	SidecarMem *k8s.Quantity `json:"sidecar_mem" yaml:"sidecar_mem"`
 }
 
 type Compute struct {
-	CPU *k8s.Quantity `json:"cpu" yaml:"cpu"`
-	Mem *k8s.Quantity `json:"mem" yaml:"mem"`
-	GPU int64         `json:"gpu" yaml:"gpu"`
-	Inf int64         `json:"inf" yaml:"inf"`
+	CPU               *k8s.Quantity  `json:"cpu" yaml:"cpu"`
+	Mem               *k8s.Quantity  `json:"mem" yaml:"mem"`
+	ShmSize           *k8s.Quantity  `json:"shm_size" yaml:"shm_size"`
+	EphemeralStorage  *k8s.Quantity  `json:"ephemeral_storage" yaml:"ephemeral_storage"`
+	GPU               int64          `json:"gpu" yaml:"gpu"`
+	Inf               int64          `json:"inf" yaml:"inf"`
+	Spot              *bool          `json:"spot" yaml:"spot"`
+	OnDemandFallback  bool           `json:"on_demand_fallback" yaml:"on_demand_fallback"`
+	NodeGroup         *string        `json:"node_group" yaml:"node_group"`
+	Priority          PriorityType   `json:"priority" yaml:"priority"`
+	MaxConcurrentJobs int64          `json:"max_concurrent_jobs" yaml:"max_concurrent_jobs"` // the API's batch jobs which may run at the same time
+	Parallelism       *Parallelism   `json:"parallelism" yaml:"parallelism"`
+	ScratchVolume     *ScratchVolume `json:"scratch_volume" yaml:"scratch_volume"`
+}
+
+// ScratchVolume is a persistent volume which is mounted into the API's replicas, and which outlives them (e.g. for on-disk indexes which are expensive to rebuild)
+type ScratchVolume struct {
+	Size         *k8s.Quantity  `json:"size" yaml:"size"`
+	StorageClass *string        `json:"storage_class" yaml:"storage_class"`
+	MountPath    string         `json:"mount_path" yaml:"mount_path"`
+	AccessMode   AccessModeType `json:"access_mode" yaml:"access_mode"`
+}
+
+// Parallelism shards the API's model across the GPUs of each replica (each rank uses one GPU); a replica may span
+// multiple nodes, in which case it is a group of pods (the leader, which serves requests, and the workers)
+type Parallelism struct {
+	Tensor   int64 `json:"tensor" yaml:"tensor"`
+	Pipeline int64 `json:"pipeline" yaml:"pipeline"`
+	Nodes    int64 `json:"nodes" yaml:"nodes"`
 }
 
 type Autoscaling struct {
-	MinReplicas                  int32         `json:"min_replicas" yaml:"min_replicas"`
-	MaxReplicas                  int32         `json:"max_replicas" yaml:"max_replicas"`
-	InitReplicas                 int32         `json:"init_replicas" yaml:"init_replicas"`
-	WorkersPerReplica            int32         `json:"workers_per_replica" yaml:"workers_per_replica"`
-	ThreadsPerWorker             int32         `json:"threads_per_worker" yaml:"threads_per_worker"`
-	TargetReplicaConcurrency     *float64      `json:"target_replica_concurrency" yaml:"target_replica_concurrency"`
-	MaxReplicaConcurrency        int64         `json:"max_replica_concurrency" yaml:"max_replica_concurrency"`
-	Window                       time.Duration `json:"window" yaml:"window"`
-	DownscaleStabilizationPeriod time.Duration `json:"downscale_stabilization_period" yaml:"downscale_stabilization_period"`
-	UpscaleStabilizationPeriod   time.Duration `json:"upscale_stabilization_period" yaml:"upscale_stabilization_period"`
-	MaxDownscaleFactor           float64       `json:"max_downscale_factor" yaml:"max_downscale_factor"`
-	MaxUpscaleFactor             float64       `json:"max_upscale_factor" yaml:"max_upscale_factor"`
-	DownscaleTolerance           float64       `json:"downscale_tolerance" yaml:"downscale_tolerance"`
-	UpscaleTolerance             float64       `json:"upscale_tolerance" yaml:"upscale_tolerance"`
+	MinReplicas                  int32                `json:"min_replicas" yaml:"min_replicas"`
+	MaxReplicas                  int32                `json:"max_replicas" yaml:"max_replicas"`
+	InitReplicas                 int32                `json:"init_replicas" yaml:"init_replicas"`
+	WorkersPerReplica            int32                `json:"workers_per_replica" yaml:"workers_per_replica"`
+	ThreadsPerWorker             int32                `json:"threads_per_worker" yaml:"threads_per_worker"`
+	TargetReplicaConcurrency     *float64             `json:"target_replica_concurrency" yaml:"target_replica_concurrency"`
+	MaxReplicaConcurrency        int64                `json:"max_replica_concurrency" yaml:"max_replica_concurrency"`
+	MaxQueueLength               *int64               `json:"max_queue_length" yaml:"max_queue_length"`
+	OverloadBehavior             OverloadBehaviorType `json:"overload_behavior" yaml:"overload_behavior"`
+	Window                       time.Duration        `json:"window" yaml:"window"`
+	DownscaleStabilizationPeriod time.Duration        `json:"downscale_stabilization_period" yaml:"downscale_stabilization_period"`
+	UpscaleStabilizationPeriod   time.Duration        `json:"upscale_stabilization_period" yaml:"upscale_stabilization_period"`
+	MaxDownscaleFactor           float64              `json:"max_downscale_factor" yaml:"max_downscale_factor"`
+	MaxUpscaleFactor             float64              `json:"max_upscale_factor" yaml:"max_upscale_factor"`
+	DownscaleTolerance           float64              `json:"downscale_tolerance" yaml:"downscale_tolerance"`
+	UpscaleTolerance             float64              `json:"upscale_tolerance" yaml:"upscale_tolerance"`
+	MaxDownscaleStep             *int32               `json:"max_downscale_step" yaml:"max_downscale_step"`
+	MaxUpscaleStep               *int32               `json:"max_upscale_step" yaml:"max_upscale_step"`
+	DownscaleCooldown            time.Duration        `json:"downscale_cooldown" yaml:"downscale_cooldown"`
+	UpscaleCooldown              time.Duration        `json:"upscale_cooldown" yaml:"upscale_cooldown"`
+	IdleTimeout                  *time.Duration       `json:"idle_timeout" yaml:"idle_timeout"`
+	Autoscaler                   AutoscalerType       `json:"autoscaler" yaml:"autoscaler"`
+	KEDATriggers                 []*KEDATrigger       `json:"keda_triggers" yaml:"keda_triggers"`
+}
+
+// KEDATrigger is a KEDA scaler (https://keda.sh/docs/scalers/), which is added to the API's ScaledObject
+type KEDATrigger struct {
+	Type     string            `json:"type" yaml:"type"`
+	Metadata map[string]string `json:"metadata" yaml:"metadata"`
 }
 
 type UpdateStrategy struct {
@@ -101,6 +284,118 @@ type UpdateStrategy struct {
 	MaxUnavailable string `json:"max_unavailable" yaml:"max_unavailable"`
 }
 
+type Notifications struct {
+	SlackWebhook *string `json:"slack_webhook" yaml:"slack_webhook"`
+	Webhook      *string `json:"webhook" yaml:"webhook"`
+	SNSTopic     *string `json:"sns_topic" yaml:"sns_topic"`
+}
+
+// RolloutPolicy configures when an update is automatically rolled back to the previous version of the API
+type RolloutPolicy struct {
+	BakeWindow           time.Duration `json:"bake_window" yaml:"bake_window"`
+	MaxErrorRateIncrease *float64      `json:"max_error_rate_increase" yaml:"max_error_rate_increase"`
+	MaxLatencyIncrease   *float64      `json:"max_latency_increase" yaml:"max_latency_increase"`
+	MinRequests          int64         `json:"min_requests" yaml:"min_requests"`
+}
+
+// SLO is the API's service level objective; the fraction of the window's requests which may fail (and of the window's
+// time which may exceed the latency target) is the API's error budget
+type SLO struct {
+	Availability    float64        `json:"availability" yaml:"availability"`
+	LatencyP99      *time.Duration `json:"latency_p99" yaml:"latency_p99"`
+	Window          time.Duration  `json:"window" yaml:"window"`
+	BlockPromotions bool           `json:"block_promotions" yaml:"block_promotions"` // whether experiment winners aren't promoted while the error budget is exhausted
+}
+
+// Experiment splits an API's traffic between the API (the control) and one or more variant APIs, so that their metrics can be compared
+type Experiment struct {
+	Variants    []*ExperimentVariant `json:"variants" yaml:"variants"`
+	Metric      ExperimentMetricType `json:"metric" yaml:"metric"`
+	Confidence  float64              `json:"confidence" yaml:"confidence"`
+	MinRequests int64                `json:"min_requests" yaml:"min_requests"`
+	AutoPromote bool                 `json:"auto_promote" yaml:"auto_promote"`
+	Bandit      *ExperimentBandit    `json:"bandit" yaml:"bandit"`
+}
+
+// ExperimentBandit configures the experiment's weights to be adjusted based on the metrics of the control and its variants
+// (the variants' configured weights are used until each API has received the experiment's min_requests)
+type ExperimentBandit struct {
+	MinWeight      int32         `json:"min_weight" yaml:"min_weight"` // percentage of traffic, for each API
+	MaxWeight      int32         `json:"max_weight" yaml:"max_weight"` // percentage of traffic, for each API
+	UpdateInterval time.Duration `json:"update_interval" yaml:"update_interval"`
+}
+
+type ExperimentVariant struct {
+	API    string `json:"api" yaml:"api"`
+	Weight int32  `json:"weight" yaml:"weight"` // percentage of traffic
+}
+
+// PayloadLogging configures the API's requests and responses to be captured to the cluster's bucket (e.g. so that they can be replayed)
+type PayloadLogging struct {
+	SampleRate float64 `json:"sample_rate" yaml:"sample_rate"` // the fraction of requests which are captured
+}
+
+// FeatureStore configures the Redis or Feast feature store which the API's predictor reads features from
+type FeatureStore struct {
+	Type      FeatureStoreType   `json:"type" yaml:"type"`
+	URL       string             `json:"url" yaml:"url"`               // redis://host:port/db for redis, or the host:port of the Feast serving service for feast
+	Project   *string            `json:"project" yaml:"project"`       // feast only
+	KeyPrefix *string            `json:"key_prefix" yaml:"key_prefix"` // redis only
+	Secret    *string            `json:"secret" yaml:"secret"`         // the secret in the API's namespace whose "password" key authenticates the connection
+	Cache     *FeatureStoreCache `json:"cache" yaml:"cache"`
+}
+
+// FeatureStoreCache adds a Redis container to each replica which caches the features that the predictor reads
+type FeatureStoreCache struct {
+	TTL time.Duration `json:"ttl" yaml:"ttl"`
+	Mem *k8s.Quantity `json:"mem" yaml:"mem"`
+}
+
+// Stream configures an API which consumes records from a Kafka topic, a Kinesis stream, or an SQS queue (rather than serving HTTP requests)
+type Stream struct {
+	Source           StreamSourceType `json:"source" yaml:"source"`
+	Brokers          []string         `json:"brokers" yaml:"brokers"`
+	Input            string           `json:"input" yaml:"input"`
+	Output           *string          `json:"output" yaml:"output"`
+	ConsumerGroup    *string          `json:"consumer_group" yaml:"consumer_group"`
+	BatchSize        int32            `json:"batch_size" yaml:"batch_size"`
+	TargetLag        *int64           `json:"target_lag" yaml:"target_lag"`
+	ShardsPerReplica *int64           `json:"shards_per_replica" yaml:"shards_per_replica"` // the number of Kinesis shards which each replica reads (the API is scaled to ceil(shards / shards_per_replica) replicas)
+	DeadLetterQueue  *string          `json:"dead_letter_queue" yaml:"dead_letter_queue"`
+	MaxReceiveCount  *int32           `json:"max_receive_count" yaml:"max_receive_count"`
+	Callback         *StreamCallback  `json:"callback" yaml:"callback"`
+	S3Trigger        *StreamS3Trigger `json:"s3_trigger" yaml:"s3_trigger"`
+	MaxRetries       int32            `json:"max_retries" yaml:"max_retries"` // the number of times a failed record is retried by the replica before it is considered failed
+	RecordTimeout    *time.Duration   `json:"record_timeout" yaml:"record_timeout"`
+	DeadLetterPrefix *string          `json:"dead_letter_prefix" yaml:"dead_letter_prefix"` // the S3 path which failed records are written to
+}
+
+// StreamS3Trigger subscribes an SQS stream's input queue to the ObjectCreated events of an S3 bucket, so that each new object is processed as a record
+type StreamS3Trigger struct {
+	Bucket string `json:"bucket" yaml:"bucket"`
+	Prefix string `json:"prefix" yaml:"prefix"`
+	Suffix string `json:"suffix" yaml:"suffix"`
+}
+
+// BatchTrigger submits a batch job for each object which is created in an S3 bucket, with the object (a JSON list of items) as the
+// job's items; the bucket's ObjectCreated events are sent to an SQS queue which the operator consumes
+type BatchTrigger struct {
+	Queue   string `json:"queue" yaml:"queue"`
+	Bucket  string `json:"bucket" yaml:"bucket"`
+	Prefix  string `json:"prefix" yaml:"prefix"`
+	Suffix  string `json:"suffix" yaml:"suffix"`
+	Workers int32  `json:"workers" yaml:"workers"`
+}
+
+// StreamCallback configures the result of each record to be sent to a URL and/or an SNS topic once the record has been processed
+// (Kafka records and SQS messages can also specify their own callback URL)
+type StreamCallback struct {
+	URL           *string `json:"url" yaml:"url"`
+	SNSTopic      *string `json:"sns_topic" yaml:"sns_topic"`
+	SigningKeyEnv *string `json:"signing_key_env" yaml:"signing_key_env"` // the environment variable which contains the key that URL callbacks are signed with
+	MaxRetries    int32   `json:"max_retries" yaml:"max_retries"`
+}
+
 func (api *API) Identify() string {
 	return IdentifyAPI(api.FilePath, api.Name, api.Index)
 }
@@ -116,6 +411,20 @@ func (api *API) ModelNames() []string {
 	return names
 }
 
+// Endpoints returns the API's endpoint followed by its additional endpoints
+func (api *API) Endpoints() []string {
+	var endpoints []string
+	if api.Endpoint != nil {
+		endpoints = append(endpoints, *api.Endpoint)
+	}
+	if api.Networking != nil {
+		for _, additionalEndpoint := range api.Networking.AdditionalEndpoints {
+			endpoints = append(endpoints, additionalEndpoint.Endpoint)
+		}
+	}
+	return endpoints
+}
+
 func (api *API) ApplyDefaultDockerPaths() {
 	usesGPU := api.Compute.GPU > 0
 	usesInf := api.Compute.Inf > 0
@@ -145,6 +454,18 @@ func (api *API) ApplyDefaultDockerPaths() {
 				predictor.TensorFlowServingImage = consts.DefaultImageTensorFlowServingCPU
 			}
 		}
+	case LLMPredictorType:
+		// the API container only forwards requests to the LLM server, which is allocated the GPUs
+		if predictor.Image == "" {
+			predictor.Image = consts.DefaultImagePythonPredictorCPU
+		}
+		if predictor.LLMServingConfig != nil && predictor.LLMServingConfig.Image == "" {
+			if predictor.LLMServingConfig.Server == TGIServerType {
+				predictor.LLMServingConfig.Image = consts.DefaultImageTGI
+			} else {
+				predictor.LLMServingConfig.Image = consts.DefaultImageVLLM
+			}
+		}
 	case ONNXPredictorType:
 		if predictor.Image == "" {
 			if usesGPU {
@@ -173,14 +494,16 @@ func IdentifyAPI(filePath string, name string, index int) string {
 
 // InitReplicas was left out deliberately
 func (api *API) ToK8sAnnotations() map[string]string {
-	return map[string]string{
+	annotations := map[string]string{
 		APIGatewayAnnotationKey:                   api.Networking.APIGateway.String(),
+		CompressionAnnotationKey:                  api.Networking.Compression.String(),
 		MinReplicasAnnotationKey:                  s.Int32(api.Autoscaling.MinReplicas),
 		MaxReplicasAnnotationKey:                  s.Int32(api.Autoscaling.MaxReplicas),
 		WorkersPerReplicaAnnotationKey:            s.Int32(api.Autoscaling.WorkersPerReplica),
 		ThreadsPerWorkerAnnotationKey:             s.Int32(api.Autoscaling.ThreadsPerWorker),
 		TargetReplicaConcurrencyAnnotationKey:     s.Float64(*api.Autoscaling.TargetReplicaConcurrency),
 		MaxReplicaConcurrencyAnnotationKey:        s.Int64(api.Autoscaling.MaxReplicaConcurrency),
+		OverloadBehaviorAnnotationKey:             api.Autoscaling.OverloadBehavior.String(),
 		WindowAnnotationKey:                       api.Autoscaling.Window.String(),
 		DownscaleStabilizationPeriodAnnotationKey: api.Autoscaling.DownscaleStabilizationPeriod.String(),
 		UpscaleStabilizationPeriodAnnotationKey:   api.Autoscaling.UpscaleStabilizationPeriod.String(),
@@ -189,6 +512,69 @@ func (api *API) ToK8sAnnotations() map[string]string {
 		DownscaleToleranceAnnotationKey:           s.Float64(api.Autoscaling.DownscaleTolerance),
 		UpscaleToleranceAnnotationKey:             s.Float64(api.Autoscaling.UpscaleTolerance),
 	}
+
+	if api.Compute.Spot != nil {
+		annotations[SpotAnnotationKey] = s.Bool(*api.Compute.Spot)
+		annotations[OnDemandFallbackAnnotationKey] = s.Bool(api.Compute.OnDemandFallback)
+	}
+	if api.Compute.NodeGroup != nil {
+		annotations[NodeGroupAnnotationKey] = *api.Compute.NodeGroup
+	}
+	if api.Autoscaling.IdleTimeout != nil {
+		annotations[IdleTimeoutAnnotationKey] = api.Autoscaling.IdleTimeout.String()
+	}
+	if api.Autoscaling.MaxQueueLength != nil {
+		annotations[MaxQueueLengthAnnotationKey] = s.Int64(*api.Autoscaling.MaxQueueLength)
+	}
+	if api.Autoscaling.MaxDownscaleStep != nil {
+		annotations[MaxDownscaleStepAnnotationKey] = s.Int32(*api.Autoscaling.MaxDownscaleStep)
+	}
+	if api.Autoscaling.MaxUpscaleStep != nil {
+		annotations[MaxUpscaleStepAnnotationKey] = s.Int32(*api.Autoscaling.MaxUpscaleStep)
+	}
+	if api.Autoscaling.DownscaleCooldown != 0 {
+		annotations[DownscaleCooldownAnnotationKey] = api.Autoscaling.DownscaleCooldown.String()
+	}
+	if api.Autoscaling.UpscaleCooldown != 0 {
+		annotations[UpscaleCooldownAnnotationKey] = api.Autoscaling.UpscaleCooldown.String()
+	}
+	if api.Networking.FallbackAPI != nil {
+		annotations[FallbackAPIAnnotationKey] = *api.Networking.FallbackAPI
+	}
+	if api.Networking.FallbackResponse != nil {
+		// read by the operator's maintenance envoy filter
+		fallbackResponse, _ := json.Marshal(api.Networking.FallbackResponse)
+		annotations[FallbackResponseAnnotationKey] = string(fallbackResponse)
+	}
+	if api.Networking.FallbackOnOverload {
+		annotations[FallbackOnOverloadAnnotationKey] = s.Bool(api.Networking.FallbackOnOverload)
+	}
+	if api.Networking.MaintenanceMessage != nil {
+		annotations[MaintenanceMessageAnnotationKey] = *api.Networking.MaintenanceMessage
+	}
+	if api.Networking.VersionPinning {
+		annotations[VersionPinningAnnotationKey] = s.Bool(api.Networking.VersionPinning)
+	}
+	if api.Experiment != nil {
+		// read by the operator's experiment cron, and so that changes to the experiment are detected when the API is updated
+		experiment, _ := json.Marshal(api.Experiment)
+		annotations[ExperimentAnnotationKey] = string(experiment)
+	}
+	if api.SLO != nil {
+		// read by the operator's SLO cron
+		slo, _ := json.Marshal(api.SLO)
+		annotations[SLOAnnotationKey] = string(slo)
+	}
+	if api.Autoscaling.Autoscaler == KEDAAutoscalerType {
+		annotations[AutoscalerAnnotationKey] = api.Autoscaling.Autoscaler.String()
+		if len(api.Autoscaling.KEDATriggers) > 0 {
+			// so that changes to the triggers are detected when the API is updated
+			kedaTriggers, _ := json.Marshal(api.Autoscaling.KEDATriggers)
+			annotations[KEDATriggersAnnotationKey] = string(kedaTriggers)
+		}
+	}
+
+	return annotations
 }
 
 func APIGatewayFromAnnotations(k8sObj kmeta.Object) (APIGatewayType, error) {
@@ -238,6 +624,16 @@ func AutoscalingFromAnnotations(k8sObj kmeta.Object) (*Autoscaling, error) {
 	}
 	a.MaxReplicaConcurrency = maxReplicaConcurrency
 
+	if _, ok := k8sObj.GetAnnotations()[MaxQueueLengthAnnotationKey]; ok {
+		maxQueueLength, err := k8s.ParseInt64Annotation(k8sObj, MaxQueueLengthAnnotationKey)
+		if err != nil {
+			return nil, err
+		}
+		a.MaxQueueLength = &maxQueueLength
+	}
+
+	a.OverloadBehavior = OverloadBehaviorTypeFromString(k8sObj.GetAnnotations()[OverloadBehaviorAnnotationKey])
+
 	window, err := k8s.ParseDurationAnnotation(k8sObj, WindowAnnotationKey)
 	if err != nil {
 		return nil, err
@@ -280,6 +676,57 @@ func AutoscalingFromAnnotations(k8sObj kmeta.Object) (*Autoscaling, error) {
 	}
 	a.UpscaleTolerance = upscaleTolerance
 
+	if _, ok := k8sObj.GetAnnotations()[IdleTimeoutAnnotationKey]; ok {
+		idleTimeout, err := k8s.ParseDurationAnnotation(k8sObj, IdleTimeoutAnnotationKey)
+		if err != nil {
+			return nil, err
+		}
+		a.IdleTimeout = &idleTimeout
+	}
+
+	if _, ok := k8sObj.GetAnnotations()[MaxDownscaleStepAnnotationKey]; ok {
+		maxDownscaleStep, err := k8s.ParseInt32Annotation(k8sObj, MaxDownscaleStepAnnotationKey)
+		if err != nil {
+			return nil, err
+		}
+		a.MaxDownscaleStep = &maxDownscaleStep
+	}
+
+	if _, ok := k8sObj.GetAnnotations()[MaxUpscaleStepAnnotationKey]; ok {
+		maxUpscaleStep, err := k8s.ParseInt32Annotation(k8sObj, MaxUpscaleStepAnnotationKey)
+		if err != nil {
+			return nil, err
+		}
+		a.MaxUpscaleStep = &maxUpscaleStep
+	}
+
+	if _, ok := k8sObj.GetAnnotations()[DownscaleCooldownAnnotationKey]; ok {
+		downscaleCooldown, err := k8s.ParseDurationAnnotation(k8sObj, DownscaleCooldownAnnotationKey)
+		if err != nil {
+			return nil, err
+		}
+		a.DownscaleCooldown = downscaleCooldown
+	}
+
+	if _, ok := k8sObj.GetAnnotations()[UpscaleCooldownAnnotationKey]; ok {
+		upscaleCooldown, err := k8s.ParseDurationAnnotation(k8sObj, UpscaleCooldownAnnotationKey)
+		if err != nil {
+			return nil, err
+		}
+		a.UpscaleCooldown = upscaleCooldown
+	}
+
+	a.Autoscaler = CortexAutoscalerType
+	if autoscaler, ok := k8sObj.GetAnnotations()[AutoscalerAnnotationKey]; ok {
+		a.Autoscaler = AutoscalerTypeFromString(autoscaler)
+	}
+
+	if kedaTriggers, ok := k8sObj.GetAnnotations()[KEDATriggersAnnotationKey]; ok {
+		if err := json.Unmarshal([]byte(kedaTriggers), &a.KEDATriggers); err != nil {
+			return nil, err
+		}
+	}
+
 	return &a, nil
 }
 
@@ -295,6 +742,28 @@ func (api *API) UserStr(provider types.ProviderType) string {
 		sb.WriteString(fmt.Sprintf("%s: %s\n", EndpointKey, *api.Endpoint))
 	}
 
+	if provider != types.LocalProviderType {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", NamespaceKey, api.Namespace))
+	}
+
+	if len(api.Labels) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", LabelsKey))
+		d, _ := yaml.Marshal(&api.Labels)
+		sb.WriteString(s.Indent(string(d), "  "))
+	}
+
+	if len(api.Annotations) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", AnnotationsKey))
+		d, _ := yaml.Marshal(&api.Annotations)
+		sb.WriteString(s.Indent(string(d), "  "))
+	}
+
+	if len(api.MetricTags) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", MetricTagsKey))
+		d, _ := yaml.Marshal(&api.MetricTags)
+		sb.WriteString(s.Indent(string(d), "  "))
+	}
+
 	sb.WriteString(fmt.Sprintf("%s:\n", PredictorKey))
 	sb.WriteString(s.Indent(api.Predictor.UserStr(), "  "))
 
@@ -323,6 +792,46 @@ func (api *API) UserStr(provider types.ProviderType) string {
 			sb.WriteString(fmt.Sprintf("%s:\n", UpdateStrategyKey))
 			sb.WriteString(s.Indent(api.UpdateStrategy.UserStr(), "  "))
 		}
+
+		if api.Notifications != nil {
+			sb.WriteString(fmt.Sprintf("%s:\n", NotificationsKey))
+			sb.WriteString(s.Indent(api.Notifications.UserStr(), "  "))
+		}
+
+		if api.Stream != nil {
+			sb.WriteString(fmt.Sprintf("%s:\n", StreamKey))
+			sb.WriteString(s.Indent(api.Stream.UserStr(), "  "))
+		}
+
+		if api.BatchTrigger != nil {
+			sb.WriteString(fmt.Sprintf("%s:\n", BatchTriggerKey))
+			sb.WriteString(s.Indent(api.BatchTrigger.UserStr(), "  "))
+		}
+
+		if api.RolloutPolicy != nil {
+			sb.WriteString(fmt.Sprintf("%s:\n", RolloutPolicyKey))
+			sb.WriteString(s.Indent(api.RolloutPolicy.UserStr(), "  "))
+		}
+
+		if api.Experiment != nil {
+			sb.WriteString(fmt.Sprintf("%s:\n", ExperimentKey))
+			sb.WriteString(s.Indent(api.Experiment.UserStr(), "  "))
+		}
+
+		if api.PayloadLogging != nil {
+			sb.WriteString(fmt.Sprintf("%s:\n", PayloadLoggingKey))
+			sb.WriteString(s.Indent(api.PayloadLogging.UserStr(), "  "))
+		}
+
+		if api.FeatureStore != nil {
+			sb.WriteString(fmt.Sprintf("%s:\n", FeatureStoreKey))
+			sb.WriteString(s.Indent(api.FeatureStore.UserStr(), "  "))
+		}
+
+		if api.SLO != nil {
+			sb.WriteString(fmt.Sprintf("%s:\n", SLOKey))
+			sb.WriteString(s.Indent(api.SLO.UserStr(), "  "))
+		}
 	}
 	return sb.String()
 }
@@ -350,6 +859,8 @@ func (predictor *Predictor) UserStr() string {
 	if predictor.TensorFlowServingImage != "" {
 		sb.WriteString(fmt.Sprintf("%s: %s\n", TensorFlowServingImageKey, predictor.TensorFlowServingImage))
 	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ImagePullPolicyKey, predictor.ImagePullPolicy.String()))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", PrebuildDependenciesKey, s.Bool(predictor.PrebuildDependencies)))
 	if len(predictor.Config) > 0 {
 		sb.WriteString(fmt.Sprintf("%s:\n", ConfigKey))
 		d, _ := yaml.Marshal(&predictor.Config)
@@ -360,6 +871,167 @@ func (predictor *Predictor) UserStr() string {
 		d, _ := yaml.Marshal(&predictor.Env)
 		sb.WriteString(s.Indent(string(d), "  "))
 	}
+	if predictor.EnvFrom != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", EnvFromKey))
+		sb.WriteString(s.Indent(predictor.EnvFrom.UserStr(), "  "))
+	}
+	if predictor.Batching != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", BatchingKey))
+		sb.WriteString(s.Indent(predictor.Batching.UserStr(), "  "))
+	}
+	if predictor.TensorFlowServingConfig != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", TensorFlowServingConfigKey))
+		sb.WriteString(s.Indent(predictor.TensorFlowServingConfig.UserStr(), "  "))
+	}
+	if predictor.ONNXRuntimeConfig != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", ONNXRuntimeConfigKey))
+		sb.WriteString(s.Indent(predictor.ONNXRuntimeConfig.UserStr(), "  "))
+	}
+	if predictor.ModelOptimization != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", ModelOptimizationKey))
+		sb.WriteString(s.Indent(predictor.ModelOptimization.UserStr(), "  "))
+	}
+	if predictor.LLMServingConfig != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", LLMServingConfigKey))
+		sb.WriteString(s.Indent(predictor.LLMServingConfig.UserStr(), "  "))
+	}
+	if len(predictor.InitContainers) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", InitContainersKey))
+		for _, initContainer := range predictor.InitContainers {
+			sb.WriteString(s.Indent(initContainer.UserStr(), "  "))
+		}
+	}
+	if predictor.OnShutdown != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", OnShutdownKey))
+		sb.WriteString(s.Indent(predictor.OnShutdown.UserStr(), "  "))
+	}
+	if predictor.Explainer != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", ExplainerKey))
+		sb.WriteString(s.Indent(predictor.Explainer.UserStr(), "  "))
+	}
+	return sb.String()
+}
+
+func (explainer *Explainer) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", PathKey, explainer.Path))
+	if explainer.CPU != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", CPUKey, explainer.CPU.UserString))
+	}
+	if explainer.Mem != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", MemKey, explainer.Mem.UserString))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ExplainerTimeoutKey, explainer.Timeout.String()))
+	return sb.String()
+}
+
+func (onShutdown *OnShutdown) UserStr() string {
+	var sb strings.Builder
+	if len(onShutdown.Command) > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", CommandKey, s.ObjFlatNoQuotes(onShutdown.Command)))
+	}
+	if onShutdown.HTTPPath != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", HTTPPathKey, *onShutdown.HTTPPath))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ShutdownTimeoutKey, onShutdown.Timeout.String()))
+	return sb.String()
+}
+
+func (initContainer *InitContainer) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("- %s: %s\n", NameKey, initContainer.Name))
+	if initContainer.Image != "" {
+		sb.WriteString(fmt.Sprintf(s.Indent("%s: %s\n", "  "), ImageKey, initContainer.Image))
+	}
+	sb.WriteString(fmt.Sprintf(s.Indent("%s: %s\n", "  "), CommandKey, s.ObjFlatNoQuotes(initContainer.Command)))
+	if len(initContainer.Env) > 0 {
+		sb.WriteString(fmt.Sprintf(s.Indent("%s:\n", "  "), EnvKey))
+		d, _ := yaml.Marshal(&initContainer.Env)
+		sb.WriteString(s.Indent(string(d), "    "))
+	}
+	if initContainer.CPU != nil {
+		sb.WriteString(fmt.Sprintf(s.Indent("%s: %s\n", "  "), CPUKey, initContainer.CPU.UserString))
+	}
+	if initContainer.Mem != nil {
+		sb.WriteString(fmt.Sprintf(s.Indent("%s: %s\n", "  "), MemKey, initContainer.Mem.UserString))
+	}
+	return sb.String()
+}
+
+func (llmConfig *LLMServingConfig) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ServerKey, llmConfig.Server.String()))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ImageKey, llmConfig.Image))
+	if llmConfig.MaxTokens != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", MaxTokensKey, s.Int64(*llmConfig.MaxTokens)))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", GPUMemoryUtilizationKey, s.Float64(llmConfig.GPUMemoryUtilization)))
+	if len(llmConfig.Args) > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ArgsKey, s.ObjFlatNoQuotes(llmConfig.Args)))
+	}
+	return sb.String()
+}
+
+func (envFrom *EnvFrom) UserStr() string {
+	var sb strings.Builder
+	if len(envFrom.ConfigMaps) > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ConfigMapsKey, s.ObjFlatNoQuotes(envFrom.ConfigMaps)))
+	}
+	if len(envFrom.Secrets) > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", SecretsKey, s.ObjFlatNoQuotes(envFrom.Secrets)))
+	}
+	return sb.String()
+}
+
+func (tfsConfig *TensorFlowServingConfig) UserStr() string {
+	var sb strings.Builder
+	if len(tfsConfig.Flags) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", FlagsKey))
+		d, _ := yaml.Marshal(&tfsConfig.Flags)
+		sb.WriteString(s.Indent(string(d), "  "))
+	}
+	if tfsConfig.ModelConfig != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ModelConfigKey, s.UserStr(*tfsConfig.ModelConfig)))
+	}
+	return sb.String()
+}
+
+func (onnxConfig *ONNXRuntimeConfig) UserStr() string {
+	var sb strings.Builder
+	if len(onnxConfig.ExecutionProviders) > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ExecutionProvidersKey, s.ObjFlatNoQuotes(onnxConfig.ExecutionProviders)))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", IntraOpNumThreadsKey, s.Int32(onnxConfig.IntraOpNumThreads)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", InterOpNumThreadsKey, s.Int32(onnxConfig.InterOpNumThreads)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", GraphOptimizationLevelKey, onnxConfig.GraphOptimizationLevel.String()))
+	return sb.String()
+}
+
+func (optimization *ModelOptimization) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", PrecisionKey, optimization.Precision.String()))
+	return sb.String()
+}
+
+// FlagArgs returns the flags as tensorflow_model_server command-line arguments, sorted by flag name
+func (tfsConfig *TensorFlowServingConfig) FlagArgs() []string {
+	flagNames := make([]string, 0, len(tfsConfig.Flags))
+	for flagName := range tfsConfig.Flags {
+		flagNames = append(flagNames, flagName)
+	}
+	sort.Strings(flagNames)
+
+	args := make([]string, len(flagNames))
+	for i, flagName := range flagNames {
+		args[i] = fmt.Sprintf("--%s=%s", flagName, tfsConfig.Flags[flagName])
+	}
+	return args
+}
+
+func (batching *Batching) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MaxBatchSizeKey, s.Int32(batching.MaxBatchSize)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", BatchIntervalKey, batching.BatchInterval.String()))
 	return sb.String()
 }
 
@@ -382,9 +1054,118 @@ func (monitoring *Monitoring) UserStr() string {
 	return sb.String()
 }
 
+func (fallbackResponse *FallbackResponse) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", StatusCodeKey, s.Int32(fallbackResponse.StatusCode)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ContentTypeKey, fallbackResponse.ContentType))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", BodyKey, fallbackResponse.Body))
+	return sb.String()
+}
+
 func (networking *Networking) UserStr() string {
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("%s: %s\n", APIGatewayKey, networking.APIGateway))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", CompressionKey, networking.Compression))
+	if networking.FallbackAPI != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", FallbackAPIKey, *networking.FallbackAPI))
+	}
+	if networking.FallbackResponse != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", FallbackResponseKey))
+		sb.WriteString(s.Indent(networking.FallbackResponse.UserStr(), "  "))
+	}
+	if networking.FallbackOnOverload {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", FallbackOnOverloadKey, s.Bool(networking.FallbackOnOverload)))
+	}
+	if networking.MaintenanceMessage != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", MaintenanceMessageKey, *networking.MaintenanceMessage))
+	}
+	if networking.VersionPinning {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", VersionPinningKey, s.Bool(networking.VersionPinning)))
+	}
+	if networking.Mesh != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", MeshKey))
+		sb.WriteString(s.Indent(networking.Mesh.UserStr(), "  "))
+	}
+	if networking.CORS != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", CORSKey))
+		sb.WriteString(s.Indent(networking.CORS.UserStr(), "  "))
+	}
+	if len(networking.AdditionalEndpoints) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", AdditionalEndpointsKey))
+		for _, additionalEndpoint := range networking.AdditionalEndpoints {
+			sb.WriteString(s.Indent(additionalEndpoint.UserStr(), "  "))
+		}
+	}
+	if networking.Headers != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", HeadersKey))
+		sb.WriteString(s.Indent(networking.Headers.UserStr(), "  "))
+	}
+	return sb.String()
+}
+
+func (headers *Headers) UserStr() string {
+	var sb strings.Builder
+	if headers.Request != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", RequestKey))
+		sb.WriteString(s.Indent(headers.Request.UserStr(), "  "))
+	}
+	if headers.Response != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", ResponseKey))
+		sb.WriteString(s.Indent(headers.Response.UserStr(), "  "))
+	}
+	if len(headers.Propagate) > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", PropagateKey, s.ObjFlatNoQuotes(headers.Propagate)))
+	}
+	return sb.String()
+}
+
+func (headerOperations *HeaderOperations) UserStr() string {
+	var sb strings.Builder
+	if len(headerOperations.Set) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", SetKey))
+		d, _ := yaml.Marshal(&headerOperations.Set)
+		sb.WriteString(s.Indent(string(d), "  "))
+	}
+	if len(headerOperations.Add) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", AddKey))
+		d, _ := yaml.Marshal(&headerOperations.Add)
+		sb.WriteString(s.Indent(string(d), "  "))
+	}
+	if len(headerOperations.Remove) > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", RemoveKey, s.ObjFlatNoQuotes(headerOperations.Remove)))
+	}
+	return sb.String()
+}
+
+func (additionalEndpoint *AdditionalEndpoint) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("- %s: %s\n", EndpointKey, additionalEndpoint.Endpoint))
+	if len(additionalEndpoint.Methods) > 0 {
+		sb.WriteString(fmt.Sprintf(s.Indent("%s: %s\n", "  "), MethodsKey, s.ObjFlatNoQuotes(additionalEndpoint.Methods)))
+	}
+	sb.WriteString(fmt.Sprintf(s.Indent("%s: %s\n", "  "), RewriteKey, additionalEndpoint.Rewrite))
+	return sb.String()
+}
+
+func (cors *CORS) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", AllowOriginsKey, s.ObjFlatNoQuotes(cors.AllowOrigins)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", AllowMethodsKey, s.ObjFlatNoQuotes(cors.AllowMethods)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", AllowHeadersKey, s.ObjFlatNoQuotes(cors.AllowHeaders)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MaxAgeKey, cors.MaxAge.String()))
+	return sb.String()
+}
+
+func (mesh *Mesh) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", EnabledKey, s.Bool(mesh.Enabled)))
+	if mesh.MTLS == nil {
+		sb.WriteString(fmt.Sprintf("%s: null  # cluster default\n", MTLSKey))
+	} else {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", MTLSKey, s.Bool(*mesh.MTLS)))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", SidecarCPUKey, mesh.SidecarCPU.UserString))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", SidecarMemKey, mesh.SidecarMem.UserString))
 	return sb.String()
 }
 
@@ -406,6 +1187,31 @@ func (compute *Compute) UserStr() string {
 	} else {
 		sb.WriteString(fmt.Sprintf("%s: %s\n", MemKey, compute.Mem.UserString))
 	}
+	if compute.ShmSize != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ShmSizeKey, compute.ShmSize.UserString))
+	}
+	if compute.EphemeralStorage != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", EphemeralStorageKey, compute.EphemeralStorage.UserString))
+	}
+	if compute.Spot != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", SpotKey, s.Bool(*compute.Spot)))
+		if *compute.Spot {
+			sb.WriteString(fmt.Sprintf("%s: %s\n", OnDemandFallbackKey, s.Bool(compute.OnDemandFallback)))
+		}
+	}
+	if compute.NodeGroup != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", NodeGroupKey, *compute.NodeGroup))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", PriorityKey, compute.Priority.String()))
+	sb.WriteString(fmt.Sprintf("%s: %d\n", MaxConcurrentJobsKey, compute.MaxConcurrentJobs))
+	if compute.Parallelism != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", ParallelismKey))
+		sb.WriteString(s.Indent(compute.Parallelism.UserStr(), "  "))
+	}
+	if compute.ScratchVolume != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", ScratchVolumeKey))
+		sb.WriteString(s.Indent(compute.ScratchVolume.UserStr(), "  "))
+	}
 	return sb.String()
 }
 
@@ -430,10 +1236,46 @@ func (compute Compute) Equals(c2 *Compute) bool {
 		return false
 	}
 
+	if compute.ShmSize == nil && c2.ShmSize != nil || compute.ShmSize != nil && c2.ShmSize == nil {
+		return false
+	}
+
+	if compute.ShmSize != nil && c2.ShmSize != nil && !compute.ShmSize.Equal(*c2.ShmSize) {
+		return false
+	}
+
+	if compute.EphemeralStorage == nil && c2.EphemeralStorage != nil || compute.EphemeralStorage != nil && c2.EphemeralStorage == nil {
+		return false
+	}
+
+	if compute.EphemeralStorage != nil && c2.EphemeralStorage != nil && !compute.EphemeralStorage.Equal(*c2.EphemeralStorage) {
+		return false
+	}
+
 	if compute.GPU != c2.GPU {
 		return false
 	}
 
+	if compute.Spot == nil && c2.Spot != nil || compute.Spot != nil && c2.Spot == nil {
+		return false
+	}
+
+	if compute.Spot != nil && c2.Spot != nil && *compute.Spot != *c2.Spot {
+		return false
+	}
+
+	if compute.OnDemandFallback != c2.OnDemandFallback {
+		return false
+	}
+
+	if compute.NodeGroup == nil && c2.NodeGroup != nil || compute.NodeGroup != nil && c2.NodeGroup == nil {
+		return false
+	}
+
+	if compute.NodeGroup != nil && c2.NodeGroup != nil && *compute.NodeGroup != *c2.NodeGroup {
+		return false
+	}
+
 	return true
 }
 
@@ -446,6 +1288,10 @@ func (autoscaling *Autoscaling) UserStr() string {
 	sb.WriteString(fmt.Sprintf("%s: %s\n", ThreadsPerWorkerKey, s.Int32(autoscaling.ThreadsPerWorker)))
 	sb.WriteString(fmt.Sprintf("%s: %s\n", TargetReplicaConcurrencyKey, s.Float64(*autoscaling.TargetReplicaConcurrency)))
 	sb.WriteString(fmt.Sprintf("%s: %s\n", MaxReplicaConcurrencyKey, s.Int64(autoscaling.MaxReplicaConcurrency)))
+	if autoscaling.MaxQueueLength != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", MaxQueueLengthKey, s.Int64(*autoscaling.MaxQueueLength)))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", OverloadBehaviorKey, autoscaling.OverloadBehavior.String()))
 	sb.WriteString(fmt.Sprintf("%s: %s\n", WindowKey, autoscaling.Window.String()))
 	sb.WriteString(fmt.Sprintf("%s: %s\n", DownscaleStabilizationPeriodKey, autoscaling.DownscaleStabilizationPeriod.String()))
 	sb.WriteString(fmt.Sprintf("%s: %s\n", UpscaleStabilizationPeriodKey, autoscaling.UpscaleStabilizationPeriod.String()))
@@ -453,12 +1299,278 @@ func (autoscaling *Autoscaling) UserStr() string {
 	sb.WriteString(fmt.Sprintf("%s: %s\n", MaxUpscaleFactorKey, s.Float64(autoscaling.MaxUpscaleFactor)))
 	sb.WriteString(fmt.Sprintf("%s: %s\n", DownscaleToleranceKey, s.Float64(autoscaling.DownscaleTolerance)))
 	sb.WriteString(fmt.Sprintf("%s: %s\n", UpscaleToleranceKey, s.Float64(autoscaling.UpscaleTolerance)))
+	if autoscaling.MaxDownscaleStep != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", MaxDownscaleStepKey, s.Int32(*autoscaling.MaxDownscaleStep)))
+	}
+	if autoscaling.MaxUpscaleStep != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", MaxUpscaleStepKey, s.Int32(*autoscaling.MaxUpscaleStep)))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", DownscaleCooldownKey, autoscaling.DownscaleCooldown.String()))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", UpscaleCooldownKey, autoscaling.UpscaleCooldown.String()))
+	if autoscaling.IdleTimeout != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", IdleTimeoutKey, autoscaling.IdleTimeout.String()))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", AutoscalerKey, autoscaling.Autoscaler.String()))
+	if len(autoscaling.KEDATriggers) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", KEDATriggersKey))
+		d, _ := yaml.Marshal(&autoscaling.KEDATriggers)
+		sb.WriteString(s.Indent(string(d), "  "))
+	}
 	return sb.String()
 }
 
+// ReplicaConcurrencyLimit returns the maximum number of in-flight requests (processing and queued) that a replica accepts
+func (autoscaling *Autoscaling) ReplicaConcurrencyLimit() int64 {
+	if autoscaling.OverloadBehavior != ShedOverloadBehaviorType {
+		return autoscaling.MaxReplicaConcurrency
+	}
+
+	limit := int64(autoscaling.WorkersPerReplica) * int64(autoscaling.ThreadsPerWorker)
+	if autoscaling.MaxQueueLength != nil {
+		limit += *autoscaling.MaxQueueLength
+	}
+	if limit > autoscaling.MaxReplicaConcurrency {
+		return autoscaling.MaxReplicaConcurrency
+	}
+	return limit
+}
+
 func (updateStrategy *UpdateStrategy) UserStr() string {
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("%s: %s\n", MaxSurgeKey, updateStrategy.MaxSurge))
 	sb.WriteString(fmt.Sprintf("%s: %s\n", MaxUnavailableKey, updateStrategy.MaxUnavailable))
 	return sb.String()
 }
+
+func (notifications *Notifications) UserStr() string {
+	var sb strings.Builder
+	if notifications.SlackWebhook != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", SlackWebhookKey, *notifications.SlackWebhook))
+	}
+	if notifications.Webhook != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", WebhookKey, *notifications.Webhook))
+	}
+	if notifications.SNSTopic != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", SNSTopicKey, *notifications.SNSTopic))
+	}
+	return sb.String()
+}
+
+func (rolloutPolicy *RolloutPolicy) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", BakeWindowKey, rolloutPolicy.BakeWindow.String()))
+	if rolloutPolicy.MaxErrorRateIncrease != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", MaxErrorRateIncreaseKey, s.Float64(*rolloutPolicy.MaxErrorRateIncrease)))
+	}
+	if rolloutPolicy.MaxLatencyIncrease != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", MaxLatencyIncreaseKey, s.Float64(*rolloutPolicy.MaxLatencyIncrease)))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MinRequestsKey, s.Int64(rolloutPolicy.MinRequests)))
+	return sb.String()
+}
+
+func (slo *SLO) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", AvailabilityKey, s.Float64(slo.Availability)))
+	if slo.LatencyP99 != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", LatencyP99Key, slo.LatencyP99.String()))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", WindowKey, slo.Window.String()))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", BlockPromotionsKey, s.Bool(slo.BlockPromotions)))
+	return sb.String()
+}
+
+func (experiment *Experiment) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s:\n", VariantsKey))
+	for _, variant := range experiment.Variants {
+		sb.WriteString(s.Indent(variant.UserStr(), "  "))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ExperimentMetricKey, experiment.Metric.String()))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ConfidenceKey, s.Float64(experiment.Confidence)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MinRequestsKey, s.Int64(experiment.MinRequests)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", AutoPromoteKey, s.Bool(experiment.AutoPromote)))
+	if experiment.Bandit != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", BanditKey))
+		sb.WriteString(s.Indent(experiment.Bandit.UserStr(), "  "))
+	}
+	return sb.String()
+}
+
+func (bandit *ExperimentBandit) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MinWeightKey, s.Int32(bandit.MinWeight)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MaxWeightKey, s.Int32(bandit.MaxWeight)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", UpdateIntervalKey, bandit.UpdateInterval.String()))
+	return sb.String()
+}
+
+func (payloadLogging *PayloadLogging) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", SampleRateKey, s.Float64(payloadLogging.SampleRate)))
+	return sb.String()
+}
+
+func (featureStore *FeatureStore) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", FeatureStoreTypeKey, featureStore.Type))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", FeatureStoreURLKey, featureStore.URL))
+	if featureStore.Project != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ProjectKey, *featureStore.Project))
+	}
+	if featureStore.KeyPrefix != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", KeyPrefixKey, *featureStore.KeyPrefix))
+	}
+	if featureStore.Secret != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", FeatureStoreSecretKey, *featureStore.Secret))
+	}
+	if featureStore.Cache != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", CacheKey))
+		sb.WriteString(s.Indent(featureStore.Cache.UserStr(), "  "))
+	}
+	return sb.String()
+}
+
+func (cache *FeatureStoreCache) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", TTLKey, cache.TTL.String()))
+	if cache.Mem != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", MemKey, cache.Mem.UserString))
+	}
+	return sb.String()
+}
+
+func (variant *ExperimentVariant) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("- %s: %s\n", VariantAPIKey, variant.API))
+	sb.WriteString(fmt.Sprintf(s.Indent("%s: %s\n", "  "), VariantWeightKey, s.Int32(variant.Weight)))
+	return sb.String()
+}
+
+// ControlWeight is the percentage of traffic which is served by the API itself
+func (experiment *Experiment) ControlWeight() int32 {
+	weight := int32(100)
+	for _, variant := range experiment.Variants {
+		weight -= variant.Weight
+	}
+	return weight
+}
+
+func (stream *Stream) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", StreamSourceKey, stream.Source.String()))
+	if len(stream.Brokers) > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", BrokersKey, s.ObjFlatNoQuotes(stream.Brokers)))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", InputKey, stream.Input))
+	if stream.Output != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", OutputKey, *stream.Output))
+	}
+	if stream.ConsumerGroup != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ConsumerGroupKey, *stream.ConsumerGroup))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", StreamBatchSizeKey, s.Int32(stream.BatchSize)))
+	if stream.TargetLag != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", TargetLagKey, s.Int64(*stream.TargetLag)))
+	}
+	if stream.ShardsPerReplica != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ShardsPerReplicaKey, s.Int64(*stream.ShardsPerReplica)))
+	}
+	if stream.DeadLetterQueue != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", DeadLetterQueueKey, *stream.DeadLetterQueue))
+	}
+	if stream.MaxReceiveCount != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", MaxReceiveCountKey, s.Int32(*stream.MaxReceiveCount)))
+	}
+	if stream.Callback != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", CallbackKey))
+		sb.WriteString(s.Indent(stream.Callback.UserStr(), "  "))
+	}
+	if stream.S3Trigger != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", S3TriggerKey))
+		sb.WriteString(s.Indent(stream.S3Trigger.UserStr(), "  "))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MaxRetriesKey, s.Int32(stream.MaxRetries)))
+	if stream.RecordTimeout != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", RecordTimeoutKey, stream.RecordTimeout.String()))
+	}
+	if stream.DeadLetterPrefix != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", DeadLetterPrefixKey, *stream.DeadLetterPrefix))
+	}
+	return sb.String()
+}
+
+func (parallelism *Parallelism) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", TensorParallelismKey, s.Int64(parallelism.Tensor)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", PipelineParallelismKey, s.Int64(parallelism.Pipeline)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", NodesParallelismKey, s.Int64(parallelism.Nodes)))
+	return sb.String()
+}
+
+// Degree returns the number of ranks (i.e. GPUs) which the model is sharded across
+func (parallelism *Parallelism) Degree() int64 {
+	if parallelism == nil {
+		return 1
+	}
+	return parallelism.Tensor * parallelism.Pipeline
+}
+
+// IsMultiNode returns true if each replica is a group of pods
+func (parallelism *Parallelism) IsMultiNode() bool {
+	return parallelism != nil && parallelism.Nodes > 1
+}
+
+func (scratchVolume *ScratchVolume) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", SizeKey, scratchVolume.Size.UserString))
+	if scratchVolume.StorageClass == nil {
+		sb.WriteString(fmt.Sprintf("%s: null  # cluster default\n", StorageClassKey))
+	} else {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", StorageClassKey, *scratchVolume.StorageClass))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MountPathKey, scratchVolume.MountPath))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", AccessModeKey, scratchVolume.AccessMode.String()))
+	return sb.String()
+}
+
+func (s3Trigger *StreamS3Trigger) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", S3TriggerBucketKey, s3Trigger.Bucket))
+	if s3Trigger.Prefix != "" {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", S3TriggerPrefixKey, s3Trigger.Prefix))
+	}
+	if s3Trigger.Suffix != "" {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", S3TriggerSuffixKey, s3Trigger.Suffix))
+	}
+	return sb.String()
+}
+
+func (batchTrigger *BatchTrigger) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", BatchTriggerQueueKey, batchTrigger.Queue))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", S3TriggerBucketKey, batchTrigger.Bucket))
+	if batchTrigger.Prefix != "" {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", S3TriggerPrefixKey, batchTrigger.Prefix))
+	}
+	if batchTrigger.Suffix != "" {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", S3TriggerSuffixKey, batchTrigger.Suffix))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", BatchTriggerWorkersKey, s.Int32(batchTrigger.Workers)))
+	return sb.String()
+}
+
+func (callback *StreamCallback) UserStr() string {
+	var sb strings.Builder
+	if callback.URL != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", CallbackURLKey, *callback.URL))
+	}
+	if callback.SNSTopic != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", SNSTopicKey, *callback.SNSTopic))
+	}
+	if callback.SigningKeyEnv != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", SigningKeyEnvKey, *callback.SigningKeyEnv))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MaxRetriesKey, s.Int32(callback.MaxRetries)))
+	return sb.String()
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+type AutoscalerType int
+
+const (
+	UnknownAutoscalerType AutoscalerType = iota
+	CortexAutoscalerType
+	KEDAAutoscalerType
+)
+
+var _autoscalerTypes = []string{
+	"unknown",
+	"cortex",
+	"keda",
+}
+
+func AutoscalerTypeFromString(s string) AutoscalerType {
+	for i := 0; i < len(_autoscalerTypes); i++ {
+		if s == _autoscalerTypes[i] {
+			return AutoscalerType(i)
+		}
+	}
+	return UnknownAutoscalerType
+}
+
+func AutoscalerTypeStrings() []string {
+	return _autoscalerTypes[1:]
+}
+
+func (t AutoscalerType) String() string {
+	return _autoscalerTypes[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t AutoscalerType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *AutoscalerType) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(_autoscalerTypes); i++ {
+		if enum == _autoscalerTypes[i] {
+			*t = AutoscalerType(i)
+			return nil
+		}
+	}
+
+	*t = UnknownAutoscalerType
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *AutoscalerType) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t AutoscalerType) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
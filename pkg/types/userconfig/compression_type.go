@@ -0,0 +1,78 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+type CompressionType int
+
+const (
+	UnknownCompressionType CompressionType = iota
+	NoneCompressionType
+	GzipCompressionType
+)
+
+var _compressionTypes = []string{
+	"unknown",
+	"none",
+	"gzip",
+}
+
+func CompressionTypeFromString(s string) CompressionType {
+	for i := 0; i < len(_compressionTypes); i++ {
+		if s == _compressionTypes[i] {
+			return CompressionType(i)
+		}
+	}
+	return UnknownCompressionType
+}
+
+func CompressionTypeStrings() []string {
+	return _compressionTypes[1:]
+}
+
+func (t CompressionType) String() string {
+	return _compressionTypes[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t CompressionType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *CompressionType) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(_compressionTypes); i++ {
+		if enum == _compressionTypes[i] {
+			*t = CompressionType(i)
+			return nil
+		}
+	}
+
+	*t = UnknownCompressionType
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *CompressionType) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t CompressionType) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
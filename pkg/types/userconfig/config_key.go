@@ -19,7 +19,14 @@ package userconfig
 const (
 	// API
 	NameKey           = "name"
+	TemplateKey       = "template"
+	BaseKey           = "base"
+	OverridesKey      = "overrides"
 	EndpointKey       = "endpoint"
+	NamespaceKey      = "namespace"
+	LabelsKey         = "labels"
+	AnnotationsKey    = "annotations"
+	MetricTagsKey     = "metric_tags"
 	LocalPortKey      = "local_port"
 	PredictorKey      = "predictor"
 	MonitoringKey     = "monitoring"
@@ -27,18 +34,74 @@ const (
 	ComputeKey        = "compute"
 	AutoscalingKey    = "autoscaling"
 	UpdateStrategyKey = "update_strategy"
+	NotificationsKey  = "notifications"
+	StreamKey         = "stream"
+	BatchTriggerKey   = "batch_trigger"
+	RolloutPolicyKey  = "rollout_policy"
+	ExperimentKey     = "experiment"
+	PayloadLoggingKey = "payload_logging"
+	FeatureStoreKey   = "feature_store"
+	SLOKey            = "slo"
 
 	// Predictor
-	TypeKey                   = "type"
-	PathKey                   = "path"
-	ModelKey                  = "model"
-	ModelsKey                 = "models"
-	PythonPathKey             = "python_path"
-	ImageKey                  = "image"
-	TensorFlowServingImageKey = "tensorflow_serving_image"
-	ConfigKey                 = "config"
-	EnvKey                    = "env"
-	SignatureKeyKey           = "signature_key"
+	TypeKey                    = "type"
+	PathKey                    = "path"
+	ModelKey                   = "model"
+	ModelsKey                  = "models"
+	PythonPathKey              = "python_path"
+	ImageKey                   = "image"
+	TensorFlowServingImageKey  = "tensorflow_serving_image"
+	ImagePullPolicyKey         = "image_pull_policy"
+	PrebuildDependenciesKey    = "prebuild_dependencies"
+	ConfigKey                  = "config"
+	EnvKey                     = "env"
+	EnvFromKey                 = "env_from"
+	SignatureKeyKey            = "signature_key"
+	BatchingKey                = "batching"
+	TensorFlowServingConfigKey = "tensorflow_serving_config"
+	ONNXRuntimeConfigKey       = "onnx_runtime_config"
+	ModelOptimizationKey       = "model_optimization"
+	LLMServingConfigKey        = "llm_serving_config"
+	InitContainersKey          = "init_containers"
+	OnShutdownKey              = "on_shutdown"
+	ExplainerKey               = "explainer"
+
+	// TensorFlowServingConfig
+	FlagsKey       = "flags"
+	ModelConfigKey = "model_config"
+
+	// LLMServingConfig
+	ServerKey               = "server"
+	MaxTokensKey            = "max_tokens"
+	GPUMemoryUtilizationKey = "gpu_memory_utilization"
+	ArgsKey                 = "args"
+
+	// InitContainer
+	CommandKey = "command"
+
+	// OnShutdown
+	HTTPPathKey        = "http_path"
+	ShutdownTimeoutKey = "timeout"
+
+	// Explainer
+	ExplainerTimeoutKey = "timeout"
+
+	// ONNXRuntimeConfig
+	ExecutionProvidersKey     = "execution_providers"
+	IntraOpNumThreadsKey      = "intra_op_num_threads"
+	InterOpNumThreadsKey      = "inter_op_num_threads"
+	GraphOptimizationLevelKey = "graph_optimization_level"
+
+	// ModelOptimization
+	PrecisionKey = "precision"
+
+	// EnvFrom
+	ConfigMapsKey = "config_maps"
+	SecretsKey    = "secrets"
+
+	// Batching
+	MaxBatchSizeKey  = "max_batch_size"
+	BatchIntervalKey = "batch_interval"
 
 	// ModelResource
 	ModelsNameKey = "name"
@@ -48,13 +111,72 @@ const (
 	ModelTypeKey = "model_type"
 
 	// Networking
-	APIGatewayKey = "api_gateway"
+	APIGatewayKey          = "api_gateway"
+	CompressionKey         = "compression"
+	FallbackAPIKey         = "fallback_api"
+	FallbackResponseKey    = "fallback_response"
+	FallbackOnOverloadKey  = "fallback_on_overload"
+	MaintenanceMessageKey  = "maintenance_message"
+	VersionPinningKey      = "version_pinning"
+	MeshKey                = "mesh"
+	CORSKey                = "cors"
+	AdditionalEndpointsKey = "additional_endpoints"
+	HeadersKey             = "headers"
+
+	// FallbackResponse
+	StatusCodeKey  = "status_code"
+	ContentTypeKey = "content_type"
+	BodyKey        = "body"
+
+	// Mesh
+	EnabledKey    = "enabled"
+	MTLSKey       = "mtls"
+	SidecarCPUKey = "sidecar_cpu"
+	SidecarMemKey = "sidecar_mem"
+
+	// CORS
+	AllowOriginsKey = "allow_origins"
+	AllowMethodsKey = "allow_methods"
+	AllowHeadersKey = "allow_headers"
+	MaxAgeKey       = "max_age"
+
+	// AdditionalEndpoints
+	MethodsKey = "methods"
+	RewriteKey = "rewrite"
+
+	// Headers
+	RequestKey   = "request"
+	ResponseKey  = "response"
+	PropagateKey = "propagate"
+	SetKey       = "set"
+	AddKey       = "add"
+	RemoveKey    = "remove"
 
 	// Compute
-	CPUKey = "cpu"
-	MemKey = "mem"
-	GPUKey = "gpu"
-	InfKey = "inf"
+	CPUKey               = "cpu"
+	MemKey               = "mem"
+	ShmSizeKey           = "shm_size"
+	EphemeralStorageKey  = "ephemeral_storage"
+	GPUKey               = "gpu"
+	InfKey               = "inf"
+	SpotKey              = "spot"
+	OnDemandFallbackKey  = "on_demand_fallback"
+	NodeGroupKey         = "node_group"
+	PriorityKey          = "priority"
+	MaxConcurrentJobsKey = "max_concurrent_jobs"
+	ParallelismKey       = "parallelism"
+	ScratchVolumeKey     = "scratch_volume"
+
+	// Parallelism
+	TensorParallelismKey   = "tensor"
+	PipelineParallelismKey = "pipeline"
+	NodesParallelismKey    = "nodes"
+
+	// ScratchVolume
+	SizeKey         = "size"
+	StorageClassKey = "storage_class"
+	MountPathKey    = "mount_path"
+	AccessModeKey   = "access_mode"
 
 	// Autoscaling
 	MinReplicasKey                  = "min_replicas"
@@ -64,6 +186,8 @@ const (
 	ThreadsPerWorkerKey             = "threads_per_worker"
 	TargetReplicaConcurrencyKey     = "target_replica_concurrency"
 	MaxReplicaConcurrencyKey        = "max_replica_concurrency"
+	MaxQueueLengthKey               = "max_queue_length"
+	OverloadBehaviorKey             = "overload_behavior"
 	WindowKey                       = "window"
 	DownscaleStabilizationPeriodKey = "downscale_stabilization_period"
 	UpscaleStabilizationPeriodKey   = "upscale_stabilization_period"
@@ -71,19 +195,114 @@ const (
 	MaxUpscaleFactorKey             = "max_upscale_factor"
 	DownscaleToleranceKey           = "downscale_tolerance"
 	UpscaleToleranceKey             = "upscale_tolerance"
+	IdleTimeoutKey                  = "idle_timeout"
+	MaxDownscaleStepKey             = "max_downscale_step"
+	MaxUpscaleStepKey               = "max_upscale_step"
+	DownscaleCooldownKey            = "downscale_cooldown"
+	UpscaleCooldownKey              = "upscale_cooldown"
+	AutoscalerKey                   = "autoscaler"
+	KEDATriggersKey                 = "keda_triggers"
+	KEDATriggerTypeKey              = "type"
+	KEDATriggerMetadataKey          = "metadata"
 
 	// UpdateStrategy
 	MaxSurgeKey       = "max_surge"
 	MaxUnavailableKey = "max_unavailable"
 
+	// Notifications
+	SlackWebhookKey = "slack_webhook"
+	WebhookKey      = "webhook"
+	SNSTopicKey     = "sns_topic"
+
+	// RolloutPolicy
+	BakeWindowKey           = "bake_window"
+	MaxErrorRateIncreaseKey = "max_error_rate_increase"
+	MaxLatencyIncreaseKey   = "max_latency_increase"
+	MinRequestsKey          = "min_requests"
+
+	// SLO
+	AvailabilityKey    = "availability"
+	LatencyP99Key      = "latency_p99"
+	BlockPromotionsKey = "block_promotions"
+
+	// Experiment
+	VariantsKey         = "variants"
+	VariantAPIKey       = "api"
+	VariantWeightKey    = "weight"
+	ExperimentMetricKey = "metric"
+	ConfidenceKey       = "confidence"
+	AutoPromoteKey      = "auto_promote"
+	BanditKey           = "bandit"
+	MinWeightKey        = "min_weight"
+	MaxWeightKey        = "max_weight"
+	UpdateIntervalKey   = "update_interval"
+
+	// PayloadLogging
+	SampleRateKey = "sample_rate"
+
+	// Stream
+	StreamSourceKey     = "source"
+	BrokersKey          = "brokers"
+	InputKey            = "input"
+	OutputKey           = "output"
+	ConsumerGroupKey    = "consumer_group"
+	StreamBatchSizeKey  = "batch_size"
+	TargetLagKey        = "target_lag"
+	ShardsPerReplicaKey = "shards_per_replica"
+	DeadLetterQueueKey  = "dead_letter_queue"
+	MaxReceiveCountKey  = "max_receive_count"
+	CallbackKey         = "callback"
+	S3TriggerKey        = "s3_trigger"
+	RecordTimeoutKey    = "record_timeout"
+	DeadLetterPrefixKey = "dead_letter_prefix"
+
+	// StreamCallback
+	CallbackURLKey   = "url"
+	SigningKeyEnvKey = "signing_key_env"
+	MaxRetriesKey    = "max_retries"
+
+	// StreamS3Trigger (and BatchTrigger)
+	S3TriggerBucketKey = "bucket"
+	S3TriggerPrefixKey = "prefix"
+	S3TriggerSuffixKey = "suffix"
+
+	// BatchTrigger
+	BatchTriggerQueueKey   = "queue"
+	BatchTriggerWorkersKey = "workers"
+
+	// FeatureStore
+	FeatureStoreTypeKey   = "type"
+	FeatureStoreURLKey    = "url"
+	ProjectKey            = "project"
+	KeyPrefixKey          = "key_prefix"
+	FeatureStoreSecretKey = "secret"
+	CacheKey              = "cache"
+
+	// FeatureStoreCache
+	TTLKey = "ttl"
+
 	// K8s annotation
 	APIGatewayAnnotationKey                   = "networking.cortex.dev/api-gateway"
+	CompressionAnnotationKey                  = "networking.cortex.dev/compression"
+	FallbackAPIAnnotationKey                  = "networking.cortex.dev/fallback-api"
+	FallbackResponseAnnotationKey             = "networking.cortex.dev/fallback-response"
+	FallbackOnOverloadAnnotationKey           = "networking.cortex.dev/fallback-on-overload"
+	FallbackWeightAnnotationKey               = "networking.cortex.dev/fallback-weight"
+	MaintenanceMessageAnnotationKey           = "networking.cortex.dev/maintenance-message"
+	ExperimentAnnotationKey                   = "networking.cortex.dev/experiment"
+	SLOAnnotationKey                          = "monitoring.cortex.dev/slo"
+	VersionPinningAnnotationKey               = "networking.cortex.dev/version-pinning"
+	SpotAnnotationKey                         = "compute.cortex.dev/spot"
+	OnDemandFallbackAnnotationKey             = "compute.cortex.dev/on-demand-fallback"
+	NodeGroupAnnotationKey                    = "compute.cortex.dev/node-group"
 	MinReplicasAnnotationKey                  = "autoscaling.cortex.dev/min-replicas"
 	MaxReplicasAnnotationKey                  = "autoscaling.cortex.dev/max-replicas"
 	WorkersPerReplicaAnnotationKey            = "autoscaling.cortex.dev/workers-per-replica"
 	ThreadsPerWorkerAnnotationKey             = "autoscaling.cortex.dev/threads-per-worker"
 	TargetReplicaConcurrencyAnnotationKey     = "autoscaling.cortex.dev/target-replica-concurrency"
 	MaxReplicaConcurrencyAnnotationKey        = "autoscaling.cortex.dev/max-replica-concurrency"
+	MaxQueueLengthAnnotationKey               = "autoscaling.cortex.dev/max-queue-length"
+	OverloadBehaviorAnnotationKey             = "autoscaling.cortex.dev/overload-behavior"
 	WindowAnnotationKey                       = "autoscaling.cortex.dev/window"
 	DownscaleStabilizationPeriodAnnotationKey = "autoscaling.cortex.dev/downscale-stabilization-period"
 	UpscaleStabilizationPeriodAnnotationKey   = "autoscaling.cortex.dev/upscale-stabilization-period"
@@ -91,4 +310,11 @@ const (
 	MaxUpscaleFactorAnnotationKey             = "autoscaling.cortex.dev/max-upscale-factor"
 	DownscaleToleranceAnnotationKey           = "autoscaling.cortex.dev/downscale-tolerance"
 	UpscaleToleranceAnnotationKey             = "autoscaling.cortex.dev/upscale-tolerance"
+	IdleTimeoutAnnotationKey                  = "autoscaling.cortex.dev/idle-timeout"
+	MaxDownscaleStepAnnotationKey             = "autoscaling.cortex.dev/max-downscale-step"
+	MaxUpscaleStepAnnotationKey               = "autoscaling.cortex.dev/max-upscale-step"
+	DownscaleCooldownAnnotationKey            = "autoscaling.cortex.dev/downscale-cooldown"
+	UpscaleCooldownAnnotationKey              = "autoscaling.cortex.dev/upscale-cooldown"
+	AutoscalerAnnotationKey                   = "autoscaling.cortex.dev/autoscaler"
+	KEDATriggersAnnotationKey                 = "autoscaling.cortex.dev/keda-triggers"
 )
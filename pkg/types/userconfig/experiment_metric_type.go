@@ -0,0 +1,78 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+type ExperimentMetricType int
+
+const (
+	UnknownExperimentMetricType ExperimentMetricType = iota
+	ErrorRateExperimentMetricType
+	RewardExperimentMetricType
+)
+
+var _experimentMetricTypes = []string{
+	"unknown",
+	"error_rate",
+	"reward",
+}
+
+func ExperimentMetricTypeFromString(s string) ExperimentMetricType {
+	for i := 0; i < len(_experimentMetricTypes); i++ {
+		if s == _experimentMetricTypes[i] {
+			return ExperimentMetricType(i)
+		}
+	}
+	return UnknownExperimentMetricType
+}
+
+func ExperimentMetricTypeStrings() []string {
+	return _experimentMetricTypes[1:]
+}
+
+func (t ExperimentMetricType) String() string {
+	return _experimentMetricTypes[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t ExperimentMetricType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *ExperimentMetricType) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(_experimentMetricTypes); i++ {
+		if enum == _experimentMetricTypes[i] {
+			*t = ExperimentMetricType(i)
+			return nil
+		}
+	}
+
+	*t = UnknownExperimentMetricType
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *ExperimentMetricType) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t ExperimentMetricType) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
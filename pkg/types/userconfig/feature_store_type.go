@@ -0,0 +1,78 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+type FeatureStoreType int
+
+const (
+	UnknownFeatureStoreType FeatureStoreType = iota
+	RedisFeatureStoreType
+	FeastFeatureStoreType
+)
+
+var _featureStoreTypes = []string{
+	"unknown",
+	"redis",
+	"feast",
+}
+
+func FeatureStoreTypeFromString(s string) FeatureStoreType {
+	for i := 0; i < len(_featureStoreTypes); i++ {
+		if s == _featureStoreTypes[i] {
+			return FeatureStoreType(i)
+		}
+	}
+	return UnknownFeatureStoreType
+}
+
+func FeatureStoreTypeStrings() []string {
+	return _featureStoreTypes[1:]
+}
+
+func (t FeatureStoreType) String() string {
+	return _featureStoreTypes[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t FeatureStoreType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *FeatureStoreType) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(_featureStoreTypes); i++ {
+		if enum == _featureStoreTypes[i] {
+			*t = FeatureStoreType(i)
+			return nil
+		}
+	}
+
+	*t = UnknownFeatureStoreType
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *FeatureStoreType) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t FeatureStoreType) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+type GraphOptimizationLevelType int
+
+const (
+	UnknownGraphOptimizationLevelType GraphOptimizationLevelType = iota
+	DisabledGraphOptimizationLevelType
+	BasicGraphOptimizationLevelType
+	ExtendedGraphOptimizationLevelType
+	AllGraphOptimizationLevelType
+)
+
+var _graphOptimizationLevelTypes = []string{
+	"unknown",
+	"disabled",
+	"basic",
+	"extended",
+	"all",
+}
+
+func GraphOptimizationLevelTypeFromString(s string) GraphOptimizationLevelType {
+	for i := 0; i < len(_graphOptimizationLevelTypes); i++ {
+		if s == _graphOptimizationLevelTypes[i] {
+			return GraphOptimizationLevelType(i)
+		}
+	}
+	return UnknownGraphOptimizationLevelType
+}
+
+func GraphOptimizationLevelTypeStrings() []string {
+	return _graphOptimizationLevelTypes[1:]
+}
+
+func (t GraphOptimizationLevelType) String() string {
+	return _graphOptimizationLevelTypes[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t GraphOptimizationLevelType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *GraphOptimizationLevelType) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(_graphOptimizationLevelTypes); i++ {
+		if enum == _graphOptimizationLevelTypes[i] {
+			*t = GraphOptimizationLevelType(i)
+			return nil
+		}
+	}
+
+	*t = UnknownGraphOptimizationLevelType
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *GraphOptimizationLevelType) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t GraphOptimizationLevelType) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
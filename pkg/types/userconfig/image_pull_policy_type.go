@@ -0,0 +1,80 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+type ImagePullPolicyType int
+
+const (
+	UnknownImagePullPolicyType ImagePullPolicyType = iota
+	AlwaysImagePullPolicyType
+	IfNotPresentImagePullPolicyType
+	NeverImagePullPolicyType
+)
+
+var _imagePullPolicyTypes = []string{
+	"unknown",
+	"Always",
+	"IfNotPresent",
+	"Never",
+}
+
+func ImagePullPolicyTypeFromString(s string) ImagePullPolicyType {
+	for i := 0; i < len(_imagePullPolicyTypes); i++ {
+		if s == _imagePullPolicyTypes[i] {
+			return ImagePullPolicyType(i)
+		}
+	}
+	return UnknownImagePullPolicyType
+}
+
+func ImagePullPolicyTypeStrings() []string {
+	return _imagePullPolicyTypes[1:]
+}
+
+func (t ImagePullPolicyType) String() string {
+	return _imagePullPolicyTypes[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t ImagePullPolicyType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *ImagePullPolicyType) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(_imagePullPolicyTypes); i++ {
+		if enum == _imagePullPolicyTypes[i] {
+			*t = ImagePullPolicyType(i)
+			return nil
+		}
+	}
+
+	*t = UnknownImagePullPolicyType
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *ImagePullPolicyType) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t ImagePullPolicyType) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+type LLMServerType int
+
+const (
+	UnknownLLMServerType LLMServerType = iota
+	VLLMServerType
+	TGIServerType
+)
+
+var _llmServerTypes = []string{
+	"unknown",
+	"vllm",
+	"tgi",
+}
+
+func LLMServerTypeFromString(s string) LLMServerType {
+	for i := 0; i < len(_llmServerTypes); i++ {
+		if s == _llmServerTypes[i] {
+			return LLMServerType(i)
+		}
+	}
+	return UnknownLLMServerType
+}
+
+func LLMServerTypeStrings() []string {
+	return _llmServerTypes[1:]
+}
+
+func (t LLMServerType) String() string {
+	return _llmServerTypes[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t LLMServerType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *LLMServerType) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(_llmServerTypes); i++ {
+		if enum == _llmServerTypes[i] {
+			*t = LLMServerType(i)
+			return nil
+		}
+	}
+
+	*t = UnknownLLMServerType
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *LLMServerType) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t LLMServerType) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+type OverloadBehaviorType int
+
+const (
+	UnknownOverloadBehaviorType OverloadBehaviorType = iota
+	QueueOverloadBehaviorType
+	ShedOverloadBehaviorType
+)
+
+var _overloadBehaviorTypes = []string{
+	"unknown",
+	"queue",
+	"shed",
+}
+
+func OverloadBehaviorTypeFromString(s string) OverloadBehaviorType {
+	for i := 0; i < len(_overloadBehaviorTypes); i++ {
+		if s == _overloadBehaviorTypes[i] {
+			return OverloadBehaviorType(i)
+		}
+	}
+	return UnknownOverloadBehaviorType
+}
+
+func OverloadBehaviorTypeStrings() []string {
+	return _overloadBehaviorTypes[1:]
+}
+
+func (t OverloadBehaviorType) String() string {
+	return _overloadBehaviorTypes[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t OverloadBehaviorType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *OverloadBehaviorType) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(_overloadBehaviorTypes); i++ {
+		if enum == _overloadBehaviorTypes[i] {
+			*t = OverloadBehaviorType(i)
+			return nil
+		}
+	}
+
+	*t = UnknownOverloadBehaviorType
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *OverloadBehaviorType) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t OverloadBehaviorType) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
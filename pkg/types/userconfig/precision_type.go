@@ -0,0 +1,78 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+type PrecisionType int
+
+const (
+	UnknownPrecisionType PrecisionType = iota
+	FP32PrecisionType
+	FP16PrecisionType
+)
+
+var _precisionTypes = []string{
+	"unknown",
+	"fp32",
+	"fp16",
+}
+
+func PrecisionTypeFromString(s string) PrecisionType {
+	for i := 0; i < len(_precisionTypes); i++ {
+		if s == _precisionTypes[i] {
+			return PrecisionType(i)
+		}
+	}
+	return UnknownPrecisionType
+}
+
+func PrecisionTypeStrings() []string {
+	return _precisionTypes[1:]
+}
+
+func (t PrecisionType) String() string {
+	return _precisionTypes[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t PrecisionType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *PrecisionType) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(_precisionTypes); i++ {
+		if enum == _precisionTypes[i] {
+			*t = PrecisionType(i)
+			return nil
+		}
+	}
+
+	*t = UnknownPrecisionType
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *PrecisionType) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t PrecisionType) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
@@ -23,6 +23,7 @@ const (
 	PythonPredictorType
 	TensorFlowPredictorType
 	ONNXPredictorType
+	LLMPredictorType
 )
 
 var _predictorTypes = []string{
@@ -30,6 +31,7 @@ var _predictorTypes = []string{
 	"python",
 	"tensorflow",
 	"onnx",
+	"llm",
 }
 
 func PredictorTypeFromString(s string) PredictorType {
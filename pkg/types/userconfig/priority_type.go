@@ -0,0 +1,80 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+type PriorityType int
+
+const (
+	UnknownPriorityType PriorityType = iota
+	LowPriorityType
+	DefaultPriorityType
+	HighPriorityType
+)
+
+var _priorityTypes = []string{
+	"unknown",
+	"low",
+	"default",
+	"high",
+}
+
+func PriorityTypeFromString(s string) PriorityType {
+	for i := 0; i < len(_priorityTypes); i++ {
+		if s == _priorityTypes[i] {
+			return PriorityType(i)
+		}
+	}
+	return UnknownPriorityType
+}
+
+func PriorityTypeStrings() []string {
+	return _priorityTypes[1:]
+}
+
+func (t PriorityType) String() string {
+	return _priorityTypes[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t PriorityType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *PriorityType) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(_priorityTypes); i++ {
+		if enum == _priorityTypes[i] {
+			*t = PriorityType(i)
+			return nil
+		}
+	}
+
+	*t = UnknownPriorityType
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *PriorityType) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t PriorityType) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
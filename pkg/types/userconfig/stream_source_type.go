@@ -0,0 +1,80 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+type StreamSourceType int
+
+const (
+	UnknownStreamSourceType StreamSourceType = iota
+	KafkaStreamSourceType
+	KinesisStreamSourceType
+	SQSStreamSourceType
+)
+
+var _streamSourceTypes = []string{
+	"unknown",
+	"kafka",
+	"kinesis",
+	"sqs",
+}
+
+func StreamSourceTypeFromString(s string) StreamSourceType {
+	for i := 0; i < len(_streamSourceTypes); i++ {
+		if s == _streamSourceTypes[i] {
+			return StreamSourceType(i)
+		}
+	}
+	return UnknownStreamSourceType
+}
+
+func StreamSourceTypeStrings() []string {
+	return _streamSourceTypes[1:]
+}
+
+func (t StreamSourceType) String() string {
+	return _streamSourceTypes[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t StreamSourceType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *StreamSourceType) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(_streamSourceTypes); i++ {
+		if enum == _streamSourceTypes[i] {
+			*t = StreamSourceType(i)
+			return nil
+		}
+	}
+
+	*t = UnknownStreamSourceType
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *StreamSourceType) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t StreamSourceType) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
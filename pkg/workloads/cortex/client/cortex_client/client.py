@@ -0,0 +1,395 @@
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import fnmatch
+import io
+import os
+import time
+import zipfile
+
+import requests
+import urllib3
+import yaml
+
+CORTEX_VERSION = "master"
+
+_STATUS_CODES = [
+    "status_unknown",
+    "status_stalled",
+    "status_error",
+    "status_oom",
+    "status_live",
+    "status_updating",
+    "status_paused",
+]
+_FAILED_STATUSES = {"status_stalled", "status_error", "status_oom"}
+_RETRY_STATUS_CODES = {502, 503, 504}
+
+urllib3.disable_warnings(urllib3.exceptions.InsecureRequestWarning)
+
+
+class CortexException(Exception):
+    def __init__(self, message, kind=None):
+        super().__init__(message)
+        self.kind = kind
+        self.message = message
+
+
+class Client:
+    def __init__(
+        self,
+        operator_endpoint,
+        aws_access_key_id,
+        aws_secret_access_key,
+        timeout=600,
+        max_retries=3,
+        retry_interval=1,
+    ):
+        """Create a client for the Cortex operator.
+
+        Args:
+            operator_endpoint: The operator's endpoint (e.g. the output of `cortex cluster info`).
+            aws_access_key_id: The AWS access key ID used to authenticate with the operator.
+            aws_secret_access_key: The AWS secret access key used to authenticate with the operator.
+            timeout: Timeout (in seconds) for each request to the operator.
+            max_retries: Number of times GET and DELETE requests are retried on connection errors
+                and 502/503/504 responses.
+            retry_interval: Initial delay (in seconds) between retries; doubled after each attempt.
+        """
+        self.operator_endpoint = operator_endpoint.rstrip("/")
+        self.timeout = timeout
+        self.max_retries = max_retries
+        self.retry_interval = retry_interval
+        self.headers = {
+            "Authorization": f"CortexAWS {aws_access_key_id}|{aws_secret_access_key}",
+            "CortexAPIVersion": CORTEX_VERSION,
+        }
+
+    def deploy(self, api_spec, project_dir=".", force=False):
+        """Deploy one or more APIs.
+
+        Args:
+            api_spec: An API configuration (a dictionary with the same fields as cortex.yaml),
+                or a list of them.
+            project_dir: Path to the directory containing the predictor implementation
+                and its dependencies.
+            force: Override any in-progress API updates.
+
+        Returns:
+            A list of deployment results (one per API), each containing `api`, `message`,
+            and `error`.
+        """
+        if isinstance(api_spec, dict):
+            api_spec = [api_spec]
+        config = yaml.safe_dump(api_spec, default_flow_style=False, sort_keys=False)
+        return self._deploy(config, _zip_project(project_dir), "cortex.yaml", force)
+
+    def deploy_config(self, config_path, force=False):
+        """Deploy the APIs in a configuration file (e.g. cortex.yaml).
+
+        The directory which contains the configuration file is zipped and uploaded as the project.
+
+        Args:
+            config_path: Path to the API configuration file.
+            force: Override any in-progress API updates.
+
+        Returns:
+            A list of deployment results (one per API), each containing `api`, `message`,
+            and `error`.
+        """
+        with open(config_path, "r") as f:
+            config = f.read()
+        project_dir = os.path.dirname(os.path.abspath(config_path))
+        project_zip = _zip_project(project_dir, ignore_paths=[os.path.abspath(config_path)])
+        return self._deploy(config, project_zip, os.path.basename(config_path), force)
+
+    def _deploy(self, config, project_zip, config_path, force):
+        files = {"config": ("config", config), "project.zip": ("project.zip", project_zip)}
+        params = {"configPath": config_path, "force": str(force).lower()}
+        response = self._request("POST", "/deploy", params=params, files=files)
+        return [
+            {"api": result["API"], "message": result["Message"], "error": result["Error"]}
+            for result in response["results"]
+        ]
+
+    def get_apis(self):
+        """Get the configuration, status, and metrics of all APIs.
+
+        Returns:
+            A list of dictionaries, each containing `api`, `status`, and `metrics`.
+        """
+        response = self._request("GET", "/get")
+        return [
+            {"api": api, "status": _with_status_name(status), "metrics": metrics}
+            for api, status, metrics in zip(
+                response["apis"], response["statuses"], response["all_metrics"]
+            )
+        ]
+
+    def get_api(self, name):
+        """Get the configuration, status, metrics, and endpoint of an API.
+
+        Returns:
+            A dictionary containing `api`, `status`, `metrics`, `endpoint`, and `dashboard_url`.
+        """
+        response = self._request("GET", f"/get/{name}")
+        return {
+            "api": response["api"],
+            "status": _with_status_name(response["status"]),
+            "metrics": response["metrics"],
+            "endpoint": response["base_url"] + response["api"]["endpoint"],
+            "dashboard_url": response["dashboard_url"],
+        }
+
+    def get_endpoint(self, name):
+        """Get the URL which serves an API's predictions."""
+        return self.get_api(name)["endpoint"]
+
+    def wait_for_api(self, name, timeout=1200, poll_interval=10):
+        """Block until all of an API's replicas are up to date and live.
+
+        Args:
+            name: Name of the API.
+            timeout: Maximum number of seconds to wait.
+            poll_interval: Number of seconds between status checks.
+
+        Returns:
+            The API's endpoint.
+
+        Raises:
+            CortexException: The API failed (e.g. it errored, ran out of memory, or is stalled),
+                or the timeout was reached.
+        """
+        deadline = time.time() + timeout
+        while True:
+            api = self.get_api(name)
+            status = api["status"]["status"]
+            if status == "status_live":
+                return api["endpoint"]
+            if status in _FAILED_STATUSES:
+                raise CortexException(
+                    f"api {name} failed ({status}); run `cortex logs {name}` for more details",
+                    kind="client.api_failed",
+                )
+            if time.time() + poll_interval > deadline:
+                raise CortexException(
+                    f"timed out after {timeout} seconds waiting for api {name} to become live "
+                    + f"(current status: {status})",
+                    kind="client.timeout",
+                )
+            time.sleep(poll_interval)
+
+    def refresh(self, name, force=False):
+        """Restart all replicas of an API without downtime."""
+        params = {"force": str(force).lower()}
+        return self._request("POST", f"/refresh/{name}", params=params)["message"]
+
+    def delete(self, name, keep_cache=False):
+        """Delete an API."""
+        params = {"apiName": name, "keepCache": str(keep_cache).lower()}
+        return self._request("DELETE", f"/delete/{name}", params=params)["message"]
+
+    def get_metrics(self, name, start=None, end=None, period=None, api_id=None, model=None):
+        """Get an API's metrics over a time range.
+
+        Args:
+            name: Name of the API.
+            start: Start of the range, as an RFC3339 timestamp or a duration relative to now
+                (e.g. "1h").
+            end: End of the range, as an RFC3339 timestamp or a duration relative to now.
+            period: Number of seconds per datapoint (default: chosen based on the time range).
+            api_id: Only include metrics for a specific deployment of the API.
+            model: Only include metrics for a specific model (for multi-model APIs).
+        """
+        params = {"start": start, "end": end, "period": period, "apiID": api_id, "model": model}
+        params = {k: v for k, v in params.items() if v is not None}
+        return self._request("GET", f"/metrics/{name}", params=params)
+
+    def get_audit_log(self, name=None, start=None, end=None):
+        """Get the audit log of an API (or of all APIs if name is None).
+
+        Args:
+            name: Name of the API.
+            start: Start of the range, as an RFC3339 timestamp or a duration relative to now
+                (e.g. "24h").
+            end: End of the range, as an RFC3339 timestamp or a duration relative to now.
+
+        Returns:
+            A list of audit events, in chronological order.
+        """
+        params = {"apiName": name, "start": start, "end": end}
+        params = {k: v for k, v in params.items() if v is not None}
+        return self._request("GET", "/audit", params=params)["events"]
+
+    def _request(self, method, path, params=None, files=None):
+        retryable = method in ("GET", "DELETE")
+        retries = self.max_retries if retryable else 0
+        interval = self.retry_interval
+
+        for attempt in range(retries + 1):
+            try:
+                response = requests.request(
+                    method,
+                    self.operator_endpoint + path,
+                    params=params,
+                    files=files,
+                    headers=self.headers,
+                    timeout=self.timeout,
+                    verify=False,
+                )
+            except requests.exceptions.ConnectionError as e:
+                if attempt < retries:
+                    time.sleep(interval)
+                    interval *= 2
+                    continue
+                raise CortexException(
+                    f"unable to connect to the operator at {self.operator_endpoint}: {e}",
+                    kind="client.operator_connection",
+                ) from e
+
+            if response.status_code in _RETRY_STATUS_CODES and attempt < retries:
+                time.sleep(interval)
+                interval *= 2
+                continue
+
+            if response.status_code != 200:
+                raise _response_error(response)
+
+            return response.json()
+
+
+def _response_error(response):
+    try:
+        error = response.json()
+        return CortexException(error["message"], kind=error.get("kind"))
+    except (ValueError, KeyError, TypeError):
+        return CortexException(
+            f"operator response status code {response.status_code}: {response.text}",
+            kind="client.operator_response",
+        )
+
+
+def _with_status_name(status):
+    status = dict(status)
+    code = status.get("status_code", 0)
+    status["status"] = _STATUS_CODES[code] if 0 <= code < len(_STATUS_CODES) else "status_unknown"
+    return status
+
+
+# files with these extensions which are larger than _MAX_DATASET_BYTES are assumed to be datasets
+_DATASET_EXTENSIONS = {
+    ".csv",
+    ".tsv",
+    ".json",
+    ".jsonl",
+    ".parquet",
+    ".avro",
+    ".orc",
+    ".arrow",
+    ".feather",
+    ".tfrecord",
+    ".tfrecords",
+    ".h5",
+    ".hdf5",
+    ".npy",
+    ".npz",
+}
+_MAX_DATASET_BYTES = 1024 * 1024 * 10
+
+
+def _check_project_dir(path, rel_path):
+    """Reject python (or node) environments, which shouldn't be uploaded with the project."""
+    contents = None
+    if os.path.isfile(os.path.join(path, "pyvenv.cfg")) or os.path.isdir(
+        os.path.join(path, "conda-meta")
+    ):
+        contents = "a python environment"
+    elif os.path.basename(path) == "site-packages":
+        contents = "installed python packages"
+    elif os.path.basename(path) == "node_modules":
+        contents = "installed node packages"
+    if contents is not None:
+        raise CortexException(
+            f"{rel_path}: this directory contains {contents}, which should not be uploaded with "
+            "your project; list your api's dependencies in requirements.txt (or "
+            "conda-packages.txt) so that they are installed in its containers, and add "
+            f"{rel_path}/ to your .cortexignore file",
+            kind="files.contains_environment",
+        )
+
+
+def _check_project_file(path, rel_path):
+    """Reject datasets, which would be uploaded on every deploy."""
+    if os.path.splitext(path)[1].lower() not in _DATASET_EXTENSIONS:
+        return
+    size = os.path.getsize(path)
+    if size > _MAX_DATASET_BYTES:
+        raise CortexException(
+            f"{rel_path}: this file ({size // (1024 * 1024)} MiB) appears to be a dataset, which "
+            "would be uploaded on every deploy; upload it to s3 and read it from your predictor "
+            f"instead, and add {rel_path} to your .cortexignore file",
+            kind="files.contains_dataset",
+        )
+
+
+def _zip_project(project_dir, ignore_paths=None):
+    """Zip a project directory in memory, excluding the same files as `cortex deploy`."""
+    project_dir = os.path.abspath(project_dir)
+    ignore_paths = set(ignore_paths or [])
+
+    ignore_patterns = []
+    cortex_ignore_path = os.path.join(project_dir, ".cortexignore")
+    if os.path.isfile(cortex_ignore_path):
+        with open(cortex_ignore_path, "r") as f:
+            for line in f:
+                line = line.strip()
+                if line != "" and not line.startswith("#"):
+                    ignore_patterns.append(line.rstrip("/"))
+
+    def is_ignored(rel_path):
+        name = os.path.basename(rel_path)
+        for pattern in ignore_patterns:
+            if fnmatch.fnmatch(rel_path, pattern) or fnmatch.fnmatch(name, pattern):
+                return True
+        return False
+
+    buf = io.BytesIO()
+    with zipfile.ZipFile(buf, "w", zipfile.ZIP_DEFLATED) as zf:
+        for root, dirs, filenames in os.walk(project_dir):
+            rel_root = os.path.relpath(root, project_dir)
+            dirs[:] = [
+                d
+                for d in dirs
+                if not d.startswith(".")
+                and d != "__pycache__"
+                and not is_ignored(os.path.normpath(os.path.join(rel_root, d)))
+            ]
+            for d in dirs:
+                rel_dir = os.path.normpath(os.path.join(rel_root, d))
+                _check_project_dir(os.path.join(root, d), rel_dir)
+            for filename in filenames:
+                path = os.path.join(root, filename)
+                rel_path = os.path.normpath(os.path.join(rel_root, filename))
+                if (
+                    filename.startswith(".")
+                    or filename.endswith((".pyc", ".pyo", ".pyd"))
+                    or path in ignore_paths
+                    or is_ignored(rel_path)
+                ):
+                    continue
+                _check_project_file(path, rel_path)
+                zf.write(path, rel_path)
+
+    return buf.getvalue()
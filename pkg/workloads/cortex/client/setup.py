@@ -0,0 +1,28 @@
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+from setuptools import setup
+
+setup(
+    name="cortex-client",
+    version="master",  # CORTEX_VERSION (the client must match the cluster's version)
+    description="Python client for deploying and managing APIs on a Cortex cluster",
+    author="Cortex Labs",
+    url="https://github.com/cortexlabs/cortex",
+    license="Apache License 2.0",
+    packages=["cortex_client"],
+    python_requires=">=3.6",
+    install_requires=["requests>=2.20.0", "pyyaml>=5.1"],
+)
@@ -15,43 +15,252 @@
 import argparse
 import os
 import base64
+import hashlib
 import json
+import time
 
 from cortex.lib import util
-from cortex.lib.storage import S3
+from cortex.lib.exceptions import CortexException, UserException
+from cortex.lib.storage import S3, GCS, node_cache
+from cortex.lib.storage.oci import OCIRegistry
+from cortex.lib.storage.p2p import P2PDownloader
 from cortex.lib.log import cx_logger
 
+AWAIT_POLL_INTERVAL = 10  # seconds
+
+# source type -> function which downloads download_arg["from"] into the to_path directory (a
+# single file must keep its base name, so that it can be unzipped); source types and the options
+# which they accept (download_arg["options"]) must also be registered in the operator (see
+# download_sources.go)
+FETCHERS = {}
+
+# source type -> function which returns a string that identifies the contents of
+# download_arg["from"] (or None if they can't be identified without downloading them), so that
+# downloads which have the cache_dir option can be reused by other replicas on the node
+CONTENT_KEYS = {}
+
+
+def register_fetcher(source_type):
+    def decorator(fetch):
+        FETCHERS[source_type] = fetch
+        return fetch
+
+    return decorator
+
+
+def register_content_key(source_type):
+    def decorator(content_key):
+        CONTENT_KEYS[source_type] = content_key
+        return content_key
+
+    return decorator
+
+
+@register_fetcher("s3")
+def fetch_s3(download_arg, to_path):
+    bucket_name, prefix = S3.deconstruct_s3_path(download_arg["from"])
+    s3_client = S3(bucket_name, client_config={})
+
+    version_id = download_arg.get("version_id", "")
+    options = download_arg.get("options", {})
+    if options.get("peers", "") != "":
+        p2p_downloader = P2PDownloader(
+            options["peers"],
+            options["cache_dir"],
+            cache_max_bytes=int(options.get("cache_max_bytes", 0)),
+        )
+        p2p_downloader.download(s3_client, prefix, to_path, version_id=version_id)
+    elif version_id != "":
+        s3_client.download_file_to_dir(prefix, to_path, version_id=version_id)
+    else:
+        s3_client.download(prefix, to_path)
+
+
+@register_content_key("s3")
+def s3_content_key(download_arg):
+    bucket_name, prefix = S3.deconstruct_s3_path(download_arg["from"])
+    s3_client = S3(bucket_name, client_config={})
+
+    # the files' names (relative to the download directory) and their ETags
+    version_id = download_arg.get("version_id", "")
+    if version_id == "" and s3_client._is_s3_dir(prefix):
+        prefix = util.ensure_suffix(prefix, "/")
+        dir_name = util.trim_suffix(prefix, "/").split("/")[-1]
+        files = [
+            [os.path.join(dir_name, util.trim_prefix(obj["Key"], prefix)), obj["ETag"]]
+            for obj in s3_client._get_matching_s3_objects_generator(prefix)
+            if not obj["Key"].endswith("/")
+        ]
+    else:
+        head_args = {"Bucket": bucket_name, "Key": prefix}
+        if version_id != "":
+            head_args["VersionId"] = version_id
+        files = [[os.path.basename(prefix), s3_client.s3.head_object(**head_args)["ETag"]]]
+
+    return json.dumps(sorted(files))
+
+
+@register_fetcher("gcs")
+def fetch_gcs(download_arg, to_path):
+    bucket_name, prefix = GCS.deconstruct_gcs_path(download_arg["from"])
+    GCS(bucket_name).download(prefix, to_path)
+
+
+@register_content_key("gcs")
+def gcs_content_key(download_arg):
+    bucket_name, prefix = GCS.deconstruct_gcs_path(download_arg["from"])
+    gcs_client = GCS(bucket_name)
+
+    # the files' names (relative to the download directory) and their generations
+    if gcs_client._is_gcs_dir(prefix):
+        prefix = util.ensure_suffix(prefix, "/")
+        dir_name = util.trim_suffix(prefix, "/").split("/")[-1]
+        files = [
+            [os.path.join(dir_name, util.trim_prefix(blob.name, prefix)), str(blob.generation)]
+            for blob in gcs_client._get_matching_gcs_blobs_generator(prefix)
+            if not blob.name.endswith("/")
+        ]
+    else:
+        blob = gcs_client.gcs.bucket(bucket_name).get_blob(prefix)
+        files = [[os.path.basename(prefix), str(blob.generation)]]
+
+    return json.dumps(sorted(files))
+
+
+@register_fetcher("oci")
+def fetch_oci(download_arg, to_path):
+    registry, repository, reference = OCIRegistry.deconstruct_oci_path(download_arg["from"])
+    options = download_arg.get("options", {})
+    OCIRegistry(registry).pull(
+        repository,
+        reference,
+        to_path,
+        cache_dir=options.get("cache_dir"),
+        cache_max_bytes=int(options.get("cache_max_bytes", 0)),
+    )
+
+
+@register_content_key("oci")
+def oci_content_key(download_arg):
+    # artifacts are pinned to their digest when the API is deployed (unless the operator can't
+    # access the registry)
+    _, _, reference = OCIRegistry.deconstruct_oci_path(download_arg["from"])
+    if not reference.startswith("sha256:"):
+        return None
+    return reference
+
+
+# waits for success_path to exist, or raises the contents of failure_path if it appears first (the
+# paths are in the cluster's bucket, so they're either both in S3 or both in GCS)
+def await_bucket_file(success_path, failure_path, await_log):
+    if success_path.startswith("gs://"):
+        bucket_name, success_key = GCS.deconstruct_gcs_path(success_path)
+        _, failure_key = GCS.deconstruct_gcs_path(failure_path)
+        storage = GCS(bucket_name)
+        read_bytes = storage._read_bytes_from_gcs
+    else:
+        bucket_name, success_key = S3.deconstruct_s3_path(success_path)
+        _, failure_key = S3.deconstruct_s3_path(failure_path)
+        storage = S3(bucket_name, client_config={})
+        read_bytes = storage._read_bytes_from_s3
+
+    logged = False
+    while not storage._file_exists(success_key):
+        if storage._file_exists(failure_key):
+            error_str = read_bytes(failure_key).decode("utf-8")
+            raise UserException(error_str)
+        if not logged and await_log != "":
+            cx_logger().info(await_log)
+            logged = True
+        time.sleep(AWAIT_POLL_INTERVAL)
+
+
+# the content key of a download in the node cache, which includes how the files are processed after
+# they are downloaded
+def cached_content_key(download_arg, source_content_key):
+    if source_content_key is None:
+        return None
+    processing = {
+        "source": source_content_key,
+        "unzip": download_arg.get("unzip", False),
+        "tf_model_version_rename": relative_tf_model_version_rename(download_arg),
+    }
+    return hashlib.sha256(json.dumps(processing, sort_keys=True).encode("utf-8")).hexdigest()
+
+
+def relative_tf_model_version_rename(download_arg):
+    if download_arg.get("tf_model_version_rename", "") == "":
+        return ""
+    return os.path.relpath(
+        util.trim_suffix(download_arg["tf_model_version_rename"], "/"), download_arg["to"]
+    )
+
+
+# downloads download_arg["from"] into to_path (which is download_arg["to"], or a directory in the
+# node cache) and processes it
+def download(download_arg, source_type, to_path):
+    from_path = download_arg["from"]
+    item_name = download_arg.get("item_name", "")
+
+    if item_name != "":
+        if download_arg.get("hide_from_log", False):
+            cx_logger().info("downloading {}".format(item_name))
+        else:
+            cx_logger().info("downloading {} from {}".format(item_name, from_path))
+    FETCHERS[source_type](download_arg, to_path)
+
+    if download_arg.get("unzip", False):
+        if item_name != "" and not download_arg.get("hide_unzipping_log", False):
+            cx_logger().info("unzipping {}".format(item_name))
+        util.extract_zip(os.path.join(to_path, os.path.basename(from_path)), delete_zip_file=True)
+
+    if relative_tf_model_version_rename(download_arg) != "":
+        dest = os.path.join(to_path, relative_tf_model_version_rename(download_arg))
+        dir_path = os.path.dirname(dest)
+        entries = os.listdir(dir_path)
+        if len(entries) == 1:
+            src = os.path.join(dir_path, entries[0])
+            os.rename(src, dest)
+
 
 def start(args):
     download_config = json.loads(base64.urlsafe_b64decode(args.download))
     for download_arg in download_config["download_args"]:
-        from_path = download_arg["from"]
         to_path = download_arg["to"]
         item_name = download_arg.get("item_name", "")
-        bucket_name, prefix = S3.deconstruct_s3_path(from_path)
-        s3_client = S3(bucket_name, client_config={})
-
-        if item_name != "":
-            if download_arg.get("hide_from_log", False):
-                cx_logger().info("downloading {}".format(item_name))
-            else:
-                cx_logger().info("downloading {} from {}".format(item_name, from_path))
-        s3_client.download(prefix, to_path)
 
-        if download_arg.get("unzip", False):
-            if item_name != "" and not download_arg.get("hide_unzipping_log", False):
-                cx_logger().info("unzipping {}".format(item_name))
-            util.extract_zip(
-                os.path.join(to_path, os.path.basename(from_path)), delete_zip_file=True
+        source_type = download_arg.get("type") or "s3"  # the type isn't set by older operators
+        if source_type not in FETCHERS:
+            raise CortexException("unsupported download source type: {}".format(source_type))
+
+        if download_arg.get("await_success_path", "") != "":
+            await_bucket_file(
+                download_arg["await_success_path"],
+                download_arg["await_failure_path"],
+                download_arg.get("await_log", ""),
             )
 
-        if download_arg.get("tf_model_version_rename", "") != "":
-            dest = util.trim_suffix(download_arg["tf_model_version_rename"], "/")
-            dir_path = os.path.dirname(dest)
-            entries = os.listdir(dir_path)
-            if len(entries) == 1:
-                src = os.path.join(dir_path, entries[0])
-                os.rename(src, dest)
+        options = download_arg.get("options", {})
+        content_key = None
+        if options.get("cache_dir") is not None and source_type in CONTENT_KEYS:
+            content_key = cached_content_key(download_arg, CONTENT_KEYS[source_type](download_arg))
+
+        if content_key is None:
+            download(download_arg, source_type, to_path)
+            continue
+
+        # another replica on the node may have already downloaded the same files
+        cache_dir = options["cache_dir"]
+        if item_name != "" and os.path.isdir(node_cache.model_path(cache_dir, content_key)):
+            cx_logger().info("copying {} from the node's model cache".format(item_name))
+        cache_path = node_cache.get_model(
+            cache_dir, content_key, lambda dir_path: download(download_arg, source_type, dir_path)
+        )
+        node_cache.copy_dir_contents(cache_path, to_path)
+
+        cache_max_bytes = int(options.get("cache_max_bytes", 0))
+        if cache_max_bytes > 0:
+            node_cache.prune(cache_dir, cache_max_bytes)
 
     if download_config.get("last_log", "") != "":
         cx_logger().info(download_config["last_log"])
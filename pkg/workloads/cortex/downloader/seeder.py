@@ -0,0 +1,165 @@
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import argparse
+import os
+import shutil
+import socketserver
+import threading
+import urllib.parse
+from http.server import BaseHTTPRequestHandler, HTTPServer
+
+from cortex.lib.log import cx_logger
+from cortex.lib.storage import S3, node_cache
+from cortex.lib.storage.p2p import s3_object_digest
+
+# the model seeder runs on each node when p2p_model_distribution is enabled (see
+# manager/manifests/model-seeder.yaml.j2); it serves the files in the node's model cache to the
+# downloaders of other nodes, and downloads the objects which are assigned to it from S3
+
+
+class Seeder(object):
+    def __init__(self, cache_dir, cache_max_bytes):
+        self.cache_dir = cache_dir
+        self.cache_max_bytes = cache_max_bytes
+        self._lock = threading.Lock()
+        self._downloads = {}  # digest -> None while downloading, or the exception if it failed
+
+    def prepare(self, bucket, key, etag, version_id):
+        """
+        Returns the path of the object in the cache, or None if it is being downloaded (in which
+        case the download is started if necessary). Raises the download's error if it failed.
+        """
+        digest = s3_object_digest(bucket, key, etag)
+        cache_path = node_cache.blob_path(self.cache_dir, digest)
+        if os.path.isfile(cache_path):
+            os.utime(cache_path)
+            return cache_path
+
+        with self._lock:
+            if digest in self._downloads:
+                error = self._downloads[digest]
+                if error is not None:
+                    del self._downloads[digest]  # the next request retries the download
+                    raise error
+                return None
+            self._downloads[digest] = None
+
+        thread = threading.Thread(
+            target=self._download, args=(digest, bucket, key, etag, version_id), daemon=True
+        )
+        thread.start()
+        return None
+
+    def _download(self, digest, bucket, key, etag, version_id):
+        try:
+            s3_client = S3(bucket, client_config={})
+            head_args = {"Bucket": bucket, "Key": key}
+            if version_id:
+                head_args["VersionId"] = version_id
+            current_etag = s3_client.s3.head_object(**head_args)["ETag"].strip('"')
+            if current_etag != etag:
+                raise ValueError(
+                    "s3://{}/{} has been modified (its etag is {}, not {})".format(
+                        bucket, key, current_etag, etag
+                    )
+                )
+
+            cx_logger().info("downloading s3://{}/{}".format(bucket, key))
+            node_cache.get_blob(
+                self.cache_dir, digest, lambda path: s3_client.download_file(key, path, version_id)
+            )
+            if self.cache_max_bytes > 0:
+                node_cache.prune(self.cache_dir, self.cache_max_bytes)
+
+            with self._lock:
+                del self._downloads[digest]
+        except Exception as e:
+            cx_logger().error("failed to download s3://{}/{}: {}".format(bucket, key, e))
+            with self._lock:
+                self._downloads[digest] = e
+
+
+class ThreadingHTTPServer(socketserver.ThreadingMixIn, HTTPServer):
+    daemon_threads = True
+
+
+def handler(seeder):
+    class Handler(BaseHTTPRequestHandler):
+        def do_GET(self):
+            url = urllib.parse.urlparse(self.path)
+            if url.path == "/healthz":
+                self._respond(200, b"ok")
+                return
+
+            if not url.path.startswith("/s3/"):
+                self._respond(404, b"not found")
+                return
+            bucket, _, key = urllib.parse.unquote(url.path[len("/s3/") :]).partition("/")
+            query = urllib.parse.parse_qs(url.query)
+            etag = query.get("etag", [""])[0]
+            version_id = query.get("version_id", [None])[0]
+            if bucket == "" or key == "" or etag == "":
+                self._respond(400, b"a bucket, key, and etag are required")
+                return
+
+            try:
+                cache_path = seeder.prepare(bucket, key, etag, version_id)
+            except Exception as e:
+                self._respond(500, str(e).encode("utf-8"))
+                return
+            if cache_path is None:
+                self._respond(202, b"downloading")
+                return
+
+            try:
+                f = open(cache_path, "rb")
+            except FileNotFoundError:
+                self._respond(202, b"downloading")  # pruned since it was prepared
+                return
+            with f:
+                self.send_response(200)
+                self.send_header("Content-Type", "application/octet-stream")
+                self.send_header("Content-Length", str(os.fstat(f.fileno()).st_size))
+                self.end_headers()
+                shutil.copyfileobj(f, self.wfile, 1024 * 1024)
+
+        def _respond(self, status, body):
+            self.send_response(status)
+            self.send_header("Content-Type", "text/plain")
+            self.send_header("Content-Length", str(len(body)))
+            self.end_headers()
+            self.wfile.write(body)
+
+        def log_message(self, format, *args):
+            pass  # requests are frequent while replicas start, and errors are logged separately
+
+    return Handler
+
+
+def main():
+    parser = argparse.ArgumentParser()
+    parser.add_argument("--port", type=int, default=8890)
+    parser.add_argument("--cache-dir", required=True)
+    parser.add_argument("--cache-max-bytes", type=int, default=0)
+    args = parser.parse_args()
+
+    seeder = Seeder(args.cache_dir, args.cache_max_bytes)
+    server = ThreadingHTTPServer(("", args.port), handler(seeder))
+    cx_logger().info("serving the model cache on port {}".format(args.port))
+    server.serve_forever()
+
+
+if __name__ == "__main__":
+    main()
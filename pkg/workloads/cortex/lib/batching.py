@@ -0,0 +1,92 @@
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import itertools
+import threading
+import time
+
+from cortex.lib.exceptions import UserRuntimeException
+from cortex.lib.log import cx_logger
+
+
+class DynamicBatcher:
+    def __init__(self, predictor_impl, max_batch_size, batch_interval):
+        """
+        Groups the payloads of concurrent requests into batches for the predictor.
+
+        predictor_impl - The predictor, whose predict() function receives a list of payloads and must return a list of predictions of the same length.
+        max_batch_size - The maximum number of payloads in a batch.
+        batch_interval - The maximum time to wait for a batch to fill up, measured in seconds.
+        """
+        self.predictor_impl = predictor_impl
+        self.max_batch_size = max_batch_size
+        self.batch_interval = batch_interval
+
+        self._cv = threading.Condition()
+        self._sample_ids = itertools.count()
+        self._samples = []  # (sample_id, payload) pairs waiting to be batched
+        self._predictions = {}  # sample_id -> prediction (or the exception raised for its batch)
+
+        threading.Thread(target=self._batch_engine, daemon=True).start()
+
+    def predict(self, payload):
+        with self._cv:
+            sample_id = next(self._sample_ids)
+            self._samples.append((sample_id, payload))
+            self._cv.notify_all()
+            self._cv.wait_for(lambda: sample_id in self._predictions)
+            prediction = self._predictions.pop(sample_id)
+
+        if isinstance(prediction, Exception):
+            raise prediction
+        return prediction
+
+    def _batch_engine(self):
+        while True:
+            with self._cv:
+                self._cv.wait_for(lambda: len(self._samples) > 0)
+                deadline = time.time() + self.batch_interval
+                while len(self._samples) < self.max_batch_size:
+                    remaining = deadline - time.time()
+                    if remaining <= 0:
+                        break
+                    self._cv.wait(remaining)
+                batch = self._samples[: self.max_batch_size]
+                self._samples = self._samples[self.max_batch_size :]
+
+            predictions = self._predict_batch([payload for _, payload in batch])
+
+            with self._cv:
+                for (sample_id, _), prediction in zip(batch, predictions):
+                    self._predictions[sample_id] = prediction
+                self._cv.notify_all()
+
+    def _predict_batch(self, payloads):
+        try:
+            predictions = self.predictor_impl.predict(payload=payloads)
+        except Exception as e:
+            return [e] * len(payloads)
+
+        if not isinstance(predictions, list) or len(predictions) != len(payloads):
+            cx_logger().error(
+                "predict() returned {} for a batch of {} payloads".format(
+                    type(predictions).__name__, len(payloads)
+                )
+            )
+            e = UserRuntimeException(
+                "please return a list with one prediction per payload when batching is enabled"
+            )
+            return [e] * len(payloads)
+
+        return predictions
@@ -0,0 +1,125 @@
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import json
+
+import redis
+
+from cortex.lib.exceptions import UserException, UserRuntimeException
+from cortex.lib.log import cx_logger
+
+
+class FeatureStoreClient:
+    def __init__(
+        self,
+        store_type,
+        url,
+        project=None,
+        key_prefix=None,
+        password=None,
+        cache_url=None,
+        cache_ttl=None,
+    ):
+        """Setup the connection to the API's feature store (and to its cache, if configured).
+
+        Args:
+            store_type (string): Feature store type (redis or feast).
+            url        (string): URL of the Redis server, or host:port of the Feast serving service.
+            project    (string): Feast project (feast only).
+            key_prefix (string): Prefix of the Redis keys of each entity's features (redis only).
+            password   (string): Password of the feature store.
+            cache_url  (string): URL of the replica's Redis cache.
+            cache_ttl     (int): Number of seconds for which features are cached.
+        """
+        self._type = store_type
+        self._key_prefix = key_prefix or ""
+        self._cache_ttl = cache_ttl
+
+        if store_type == "redis":
+            self._redis = redis.Redis.from_url(url, password=password, decode_responses=True)
+        elif store_type == "feast":
+            try:
+                import feast
+            except ImportError as e:
+                raise UserException(
+                    "the feast package must be installed to use a feast feature store (e.g. by adding it to your requirements.txt)"
+                ) from e
+
+            self._feast = feast.Client(serving_url=url, project=project)
+
+        self._cache = None
+        if cache_url is not None:
+            self._cache = redis.Redis.from_url(cache_url, decode_responses=True)
+
+    @property
+    def type(self):
+        return self._type
+
+    def get(self, entity, features=None):
+        """Read an entity's features from the feature store (or from the replica's cache).
+
+        Args:
+            entity (string or dict): For redis, the entity's key (which is appended to key_prefix,
+                and holds a hash of the entity's features). For feast, a dictionary of entity names
+                to values (e.g. {"driver_id": 1001}).
+            features ([string]): Names of the features to read; for redis, all of the entity's
+                features are read if not specified. For feast, feature references
+                (e.g. ["driver_hourly_stats:conv_rate"]).
+
+        Returns:
+            dict: Feature names to values (values are None for features which aren't set).
+        """
+        cache_key = None
+        if self._cache is not None:
+            cache_key = "cortex:" + json.dumps([entity, features], sort_keys=True)
+            try:
+                cached = self._cache.get(cache_key)
+                if cached is not None:
+                    return json.loads(cached)
+            except:
+                cx_logger().warn("unable to read from the feature store cache", exc_info=True)
+
+        try:
+            if self._type == "feast":
+                values = self._get_feast(entity, features)
+            else:
+                values = self._get_redis(entity, features)
+        except Exception as e:
+            raise UserRuntimeException("unable to read features from the feature store") from e
+
+        if cache_key is not None:
+            try:
+                self._cache.setex(cache_key, self._cache_ttl, json.dumps(values))
+            except:
+                cx_logger().warn("unable to write to the feature store cache", exc_info=True)
+
+        return values
+
+    def _get_redis(self, entity, features):
+        key = self._key_prefix + entity
+        if features is None:
+            return self._redis.hgetall(key)
+        return dict(zip(features, self._redis.hmget(key, features)))
+
+    def _get_feast(self, entity, features):
+        if not features:
+            raise UserException(
+                "features must be specified when reading from a feast feature store"
+            )
+
+        response = self._feast.get_online_features(feature_refs=features, entity_rows=[entity])
+        # the response also contains the entity's own columns
+        return {
+            name: values[0] for name, values in response.to_dict().items() if name not in entity
+        }
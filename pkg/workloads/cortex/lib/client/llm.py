@@ -0,0 +1,136 @@
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import time
+import json
+
+import requests
+
+from cortex.lib.exceptions import UserRuntimeException
+from cortex.lib.log import cx_logger
+
+
+class LLMClient:
+    def __init__(self, server, url):
+        """Setup HTTP connection to the LLM server container.
+
+        Args:
+            server (string): LLM server type (vllm or tgi).
+            url    (string): Localhost URL to the LLM server container.
+        """
+        self._server = server
+        self._url = url
+
+        self._wait_for_server()
+
+    @property
+    def server(self):
+        return self._server
+
+    def generate(self, payload):
+        """Run a generation request against the LLM server.
+
+        The payload is forwarded as-is, so it must follow the server's own API: the OpenAI
+        completions API for vLLM (/v1/completions) and the /generate API for TGI.
+
+        Args:
+            payload (dict): Generation request.
+
+        Returns:
+            dict: The server's JSON response.
+        """
+        if self._server == "tgi":
+            return self.post("/generate", payload)
+        return self.post("/v1/completions", payload)
+
+    def generate_stream(self, payload):
+        """Run a generation request against the LLM server, and yield its output as it is generated.
+
+        The payload is forwarded to the server's streaming API (/v1/completions with "stream"
+        set for vLLM, and /generate_stream for TGI).
+
+        Args:
+            payload (dict): Generation request.
+
+        Yields:
+            dict: Each event sent by the server (typically one per token).
+        """
+        if self._server == "tgi":
+            return self.post_stream("/generate_stream", payload)
+        return self.post_stream("/v1/completions", dict(payload, stream=True))
+
+    def post_stream(self, path, payload):
+        """Send a JSON request to an arbitrary streaming endpoint of the LLM server.
+
+        Args:
+            path   (string): Endpoint path (e.g. /v1/chat/completions).
+            payload  (dict): Request body.
+
+        Yields:
+            dict: Each server-sent event's data.
+        """
+        try:
+            response = requests.post(self._url + path, json=payload, stream=True)
+        except Exception as e:
+            raise UserRuntimeException("unable to reach the {} server".format(self._server)) from e
+
+        with response:
+            if response.status_code != 200:
+                raise UserRuntimeException(
+                    "{} server responded with status {}: {}".format(
+                        self._server, response.status_code, response.text
+                    )
+                )
+
+            for line in response.iter_lines(decode_unicode=True):
+                if not line or not line.startswith("data:"):
+                    continue
+                data = line[len("data:") :].strip()
+                if data == "[DONE]":
+                    return
+                yield json.loads(data)
+
+    def post(self, path, payload):
+        """Send a JSON request to an arbitrary endpoint of the LLM server.
+
+        Args:
+            path   (string): Endpoint path (e.g. /v1/chat/completions).
+            payload  (dict): Request body.
+
+        Returns:
+            dict: The server's JSON response.
+        """
+        try:
+            response = requests.post(self._url + path, json=payload)
+        except Exception as e:
+            raise UserRuntimeException("unable to reach the {} server".format(self._server)) from e
+
+        if response.status_code != 200:
+            raise UserRuntimeException(
+                "{} server responded with status {}: {}".format(
+                    self._server, response.status_code, response.text
+                )
+            )
+
+        return response.json()
+
+    def _wait_for_server(self):
+        cx_logger().info("waiting for the {} server to load the model".format(self._server))
+        while True:
+            try:
+                if requests.get(self._url + "/health").status_code == 200:
+                    return
+            except requests.exceptions.ConnectionError:
+                pass
+            time.sleep(1)
@@ -0,0 +1,98 @@
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import re
+import threading
+
+from cortex.lib.exceptions import UserException
+from cortex.lib.log import cx_logger
+
+# the characters which cloudwatch and statsd both accept in metric names
+_metric_name_regex = re.compile(r"^[a-zA-Z][a-zA-Z0-9_\-.]{0,127}$")
+
+
+class MetricsClient:
+    def __init__(self, api, max_metrics):
+        """Setup the client for the custom metrics which the predictor emits.
+
+        Custom metrics are shipped to the API's metric sinks (with the "custom." prefix and the
+        APIName dimension), and are aggregated per API by the operator (e.g. in `cortex get`).
+
+        Args:
+            api         (API): The API whose predictor emits the metrics.
+            max_metrics (int): Number of distinct metrics which are emitted (others are dropped).
+        """
+        self._api = api
+        self._max_metrics = max_metrics
+        self._metric_types = {}  # metric name -> "counter" or "histogram"
+        self._dropped_metrics = set()
+        self._lock = threading.Lock()
+
+    def increment(self, name, value=1):
+        """Increment a counter (e.g. the number of low-confidence predictions).
+
+        Args:
+            name (string): Name of the counter.
+            value (number): Amount to increment the counter by.
+        """
+        if self._register(name, "counter"):
+            self._post(name, value, "Count")
+
+    def histogram(self, name, value):
+        """Record a value in a histogram (e.g. the confidence of each prediction).
+
+        Args:
+            name (string): Name of the histogram.
+            value (number): Value to record.
+        """
+        if self._register(name, "histogram"):
+            self._post(name, value, None)
+
+    def _register(self, name, metric_type):
+        if not isinstance(name, str) or not _metric_name_regex.match(name):
+            raise UserException(
+                f"{name} is not a valid metric name (names must start with a letter, can only contain alphanumeric characters, underscores, dashes, and periods, and must be at most 128 characters long)"
+            )
+
+        with self._lock:
+            registered_type = self._metric_types.get(name)
+            if registered_type is None:
+                if len(self._metric_types) >= self._max_metrics:
+                    if name not in self._dropped_metrics:
+                        self._dropped_metrics.add(name)
+                        cx_logger().warn(
+                            f"dropping custom metric {name} because at most {self._max_metrics} custom metrics can be emitted"
+                        )
+                    return False
+                self._metric_types[name] = metric_type
+                return True
+
+        if registered_type != metric_type:
+            raise UserException(
+                f"custom metric {name} is a {registered_type}, so it can't be recorded as a {metric_type}"
+            )
+        return True
+
+    def _post(self, name, value, unit):
+        if self._api.provider == "local":
+            return
+
+        metric = {
+            "MetricName": "custom." + name,
+            "Dimensions": self._api.metric_dimensions(),
+            "Value": value,
+        }
+        if unit is not None:
+            metric["Unit"] = unit
+        self._api.post_metrics([metric])
@@ -12,13 +12,14 @@
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
+import os
 import onnxruntime as rt
 import numpy as np
 
 from cortex.lib.log import cx_logger
 from cortex.lib import util
 from cortex.lib.exceptions import UserRuntimeException, CortexException, UserException
-from cortex.lib.type.model import Model, get_model_names
+from cortex.lib.type.model import Model, get_model_names, record_used_model
 from cortex import consts
 
 
@@ -35,8 +36,12 @@ class ONNXClient:
         self._sessions = {}
         self._signatures = {}
         self._input_signatures = {}
+        sess_options = get_session_options()
+        providers = get_execution_providers()
         for model in models:
-            self._sessions[model.name] = rt.InferenceSession(model.base_path)
+            self._sessions[model.name] = rt.InferenceSession(model.base_path, sess_options)
+            if providers is not None:
+                self._sessions[model.name].set_providers(providers)
             self._signatures[model.name] = self._sessions[model.name].get_inputs()
 
             metadata = {}
@@ -72,6 +77,7 @@ class ONNXClient:
                 )
             )
 
+        record_used_model(model_name)
         return self._run_inference(model_input, model_name)
 
     def _run_inference(self, model_input, model_name):
@@ -105,6 +111,59 @@ ONNX_TO_NP_TYPE = {
 }
 
 
+EXECUTION_PROVIDERS = {
+    "cuda": "CUDAExecutionProvider",
+    "tensorrt": "TensorrtExecutionProvider",
+    "openvino": "OpenVINOExecutionProvider",
+    "cpu": "CPUExecutionProvider",
+}
+
+GRAPH_OPTIMIZATION_LEVELS = {
+    "disabled": rt.GraphOptimizationLevel.ORT_DISABLE_ALL,
+    "basic": rt.GraphOptimizationLevel.ORT_ENABLE_BASIC,
+    "extended": rt.GraphOptimizationLevel.ORT_ENABLE_EXTENDED,
+    "all": rt.GraphOptimizationLevel.ORT_ENABLE_ALL,
+}
+
+
+def get_session_options():
+    sess_options = rt.SessionOptions()
+
+    intra_op_num_threads = int(os.getenv("CORTEX_ONNX_INTRA_OP_NUM_THREADS", "0"))
+    if intra_op_num_threads > 0:
+        sess_options.intra_op_num_threads = intra_op_num_threads
+
+    inter_op_num_threads = int(os.getenv("CORTEX_ONNX_INTER_OP_NUM_THREADS", "0"))
+    if inter_op_num_threads > 0:
+        sess_options.inter_op_num_threads = inter_op_num_threads
+
+    graph_optimization_level = os.getenv("CORTEX_ONNX_GRAPH_OPTIMIZATION_LEVEL")
+    if graph_optimization_level is not None:
+        sess_options.graph_optimization_level = GRAPH_OPTIMIZATION_LEVELS[graph_optimization_level]
+
+    return sess_options
+
+
+def get_execution_providers():
+    # returns None to use onnxruntime's default providers
+    execution_providers = os.getenv("CORTEX_ONNX_EXECUTION_PROVIDERS", "")
+    if execution_providers == "":
+        return None
+
+    available_providers = rt.get_available_providers()
+    providers = []
+    for provider in execution_providers.split(","):
+        ort_provider = EXECUTION_PROVIDERS[provider]
+        if ort_provider not in available_providers:
+            raise UserException(
+                "the {} execution provider is not available in this image (available providers: {})".format(
+                    provider, ", ".join(available_providers)
+                )
+            )
+        providers.append(ort_provider)
+    return providers
+
+
 def transform_to_numpy(input_pyobj, input_metadata, model_name):
     target_dtype = ONNX_TO_NP_TYPE[input_metadata.type]
     target_shape = input_metadata.shape
@@ -24,7 +24,12 @@ from google.protobuf import json_format
 
 from cortex.lib.exceptions import UserRuntimeException, UserException, CortexException
 from cortex.lib.log import cx_logger
-from cortex.lib.type.model import Model, get_model_signature_map, get_model_names
+from cortex.lib.type.model import (
+    Model,
+    get_model_signature_map,
+    get_model_names,
+    record_used_model,
+)
 from cortex import consts
 
 
@@ -77,6 +82,7 @@ class TensorFlowClient:
                 )
             )
 
+        record_used_model(model_name)
         return self._run_inference(model_input, model_name)
 
     def _run_inference(self, model_input, model_name):
@@ -47,6 +47,12 @@ class CortexAccessFormatter(CortexFormatter):
 
         return status_and_phrase
 
+    def get_models(self, scope):
+        model_names = scope.get("state", {}).get("model_names")
+        if not model_names:
+            return ""
+        return " (model: {})".format(", ".join(model_names))
+
     def formatMessage(self, record):
         scope = record.__dict__["scope"]
         record.__dict__.update(
@@ -54,6 +60,7 @@ class CortexAccessFormatter(CortexFormatter):
                 "status_code": self.get_status_code(record),
                 "method": scope["method"],
                 "path": self.get_path(scope),
+                "models": self.get_models(scope),
             }
         )
         return super().formatMessage(record)
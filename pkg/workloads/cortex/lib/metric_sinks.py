@@ -0,0 +1,70 @@
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import os
+
+from datadog.dogstatsd import DogStatsd
+
+
+class _DogStatsdSink:
+    def __init__(self, statsd):
+        self._statsd = statsd
+
+    def post(self, metric):
+        tags = ["{}:{}".format(dim["Name"], dim["Value"]) for dim in metric["Dimensions"]]
+        if metric.get("Unit") == "Count":
+            self._statsd.increment(metric["MetricName"], value=metric["Value"], tags=tags)
+        else:
+            self._statsd.histogram(metric["MetricName"], value=metric["Value"], tags=tags)
+
+
+class CloudWatchSink(_DogStatsdSink):
+    """
+    Ships metrics to the cloudwatch agent on the replica's node, which publishes them to the
+    cluster's cloudwatch namespace with the metrics' dimensions.
+    """
+
+    def __init__(self, host_ip):
+        super().__init__(DogStatsd(host=host_ip, port=8125))
+
+
+class StatsDSink(_DogStatsdSink):
+    """
+    Ships metrics to a statsd server (e.g. the datadog agent's DogStatsD), prefixed with "cortex."
+    and tagged with the API's metric_tags in addition to the metrics' dimensions.
+    """
+
+    def __init__(self, host, port, metric_tags):
+        constant_tags = ["{}:{}".format(key, value) for key, value in sorted(metric_tags.items())]
+        super().__init__(
+            DogStatsd(host=host, port=port, namespace="cortex", constant_tags=constant_tags)
+        )
+
+
+def get_metric_sinks(metric_tags):
+    """
+    Returns the sinks which the cluster ships request metrics to (the cloudwatch agent, unless
+    CORTEX_METRIC_SINKS is set).
+    """
+    host_ip = os.environ["HOST_IP"]
+
+    sinks = []
+    for sink_name in os.getenv("CORTEX_METRIC_SINKS", "cloudwatch").split(","):
+        if sink_name == "cloudwatch":
+            sinks.append(CloudWatchSink(host_ip))
+        elif sink_name == "statsd":
+            host = os.getenv("CORTEX_STATSD_HOST", host_ip)
+            port = int(os.getenv("CORTEX_STATSD_PORT", "8125"))
+            sinks.append(StatsDSink(host, port, metric_tags))
+    return sinks
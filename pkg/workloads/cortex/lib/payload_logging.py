@@ -0,0 +1,96 @@
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import base64
+import json
+import os
+import random
+import threading
+import time
+import uuid
+
+from cortex.lib.log import cx_logger
+
+
+class PayloadLogger:
+    def __init__(self, storage, root, sample_rate, flush_interval=60, flush_size=1000):
+        """
+        Captures requests and their responses to the cluster's bucket, so that they can be replayed by the operator.
+
+        storage - The storage client which the captured records are uploaded with.
+        root - The prefix of the uploaded files; each file contains newline-delimited JSON records, and is stored under <root>/<YYYY-MM-DD>/<HH>/ (UTC).
+        sample_rate - The fraction of requests which are captured.
+        flush_interval - The maximum time to buffer records before they are uploaded, measured in seconds.
+        flush_size - The maximum number of records to buffer before they are uploaded.
+        """
+        self.storage = storage
+        self.root = root
+        self.sample_rate = sample_rate
+        self.flush_interval = flush_interval
+        self.flush_size = flush_size
+
+        self._cv = threading.Condition()
+        self._records = []
+
+        threading.Thread(target=self._flush_engine, daemon=True).start()
+
+    def log(self, request_id, content_type, body, status_code, response_content_type, response):
+        if random.random() >= self.sample_rate:
+            return
+
+        record = {
+            "timestamp": time.time(),
+            "request_id": request_id,
+            "content_type": content_type,
+            "body": base64.b64encode(body).decode(),
+            "status_code": status_code,
+            "response_content_type": response_content_type,
+            "response": base64.b64encode(response).decode(),
+        }
+
+        with self._cv:
+            self._records.append(record)
+            if len(self._records) >= self.flush_size:
+                self._cv.notify_all()
+
+    def flush(self):
+        with self._cv:
+            records = self._records
+            self._records = []
+
+        if len(records) == 0:
+            return
+
+        # files are grouped by the hour in which their first record was received,
+        # so that the operator can list them by time range
+        now = time.gmtime(records[0]["timestamp"])
+        key = os.path.join(
+            self.root,
+            time.strftime("%Y-%m-%d", now),
+            time.strftime("%H", now),
+            f"{int(time.time() * 1000)}-{uuid.uuid4().hex[:8]}.jsonl",
+        )
+
+        try:
+            self.storage.put_str("\n".join(json.dumps(record) for record in records), key)
+        except:
+            cx_logger().warn(f"failed to upload {len(records)} captured payloads", exc_info=True)
+
+    def _flush_engine(self):
+        while True:
+            with self._cv:
+                self._cv.wait_for(
+                    lambda: len(self._records) >= self.flush_size, self.flush_interval
+                )
+            self.flush()
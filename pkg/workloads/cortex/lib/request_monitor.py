@@ -0,0 +1,124 @@
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import os
+import threading
+import time
+
+import boto3
+
+from cortex.lib.log import cx_logger
+
+
+class RequestMonitor:
+    def __init__(
+        self,
+        api_name,
+        cluster_name,
+        region,
+        requests_dir="/mnt/requests",
+        readiness_file="/mnt/workspace/api_readiness.txt",
+        sample_interval=1,
+        publish_interval=10,
+    ):
+        """
+        Reports the replica's in-flight requests to CloudWatch from within the API container
+        (the same metric as the request-monitor sidecar, which isn't added to the pod when the
+        cluster's request_monitor is in_process).
+
+        api_name - The name of the API, which the metric is reported for.
+        cluster_name - The name of the cluster, which is the metric's namespace.
+        region - The region of the cluster.
+        requests_dir - The directory which contains a file for each in-flight request.
+        readiness_file - The file which is created once the replica is ready (nothing is reported
+            until then).
+        sample_interval - The time between samples of the in-flight requests, measured in seconds.
+        publish_interval - The time between reports of the samples' average, measured in seconds.
+        """
+        self.api_name = api_name
+        self.cluster_name = cluster_name
+        self.requests_dir = requests_dir
+        self.readiness_file = readiness_file
+        self.sample_interval = sample_interval
+        self.publish_interval = publish_interval
+
+        self._cloudwatch = boto3.client("cloudwatch", region_name=region)
+        self._lock = threading.Lock()
+        self._samples = []
+
+    def start(self):
+        threading.Thread(target=self._sample_engine, daemon=True).start()
+        threading.Thread(target=self._publish_engine, daemon=True).start()
+
+    def _sample_engine(self):
+        while True:
+            try:
+                count = len(os.listdir(self.requests_dir))
+                with self._lock:
+                    self._samples.append(count)
+            except:
+                cx_logger().warn("unable to count in-flight requests", exc_info=True)
+            time.sleep(self.sample_interval)
+
+    def _publish_engine(self):
+        while not os.path.exists(self.readiness_file):
+            time.sleep(self.publish_interval)
+
+        # like the sidecar, publish 1 second after each multiple of the interval, so that all
+        # replicas report at the same times
+        while True:
+            now = time.time()
+            next_tick = (now // self.publish_interval + 1) * self.publish_interval + 1
+            time.sleep(next_tick - now)
+            self._publish()
+
+    def _publish(self):
+        with self._lock:
+            samples = self._samples
+            self._samples = []
+
+        in_flight = sum(samples) / len(samples) if len(samples) > 0 else 0.0
+
+        try:
+            self._cloudwatch.put_metric_data(
+                Namespace=self.cluster_name,
+                MetricData=[
+                    {
+                        "MetricName": "in-flight",
+                        "Dimensions": [{"Name": "apiName", "Value": self.api_name}],
+                        "Timestamp": time.time(),
+                        "Value": in_flight,
+                        "Unit": "Count",
+                        "StorageResolution": 1,
+                    }
+                ],
+            )
+        except:
+            cx_logger().warn("unable to publish in-flight requests", exc_info=True)
+
+
+def start_request_monitor(api_name):
+    """
+    Starts reporting the replica's in-flight requests if the cluster is configured to do so from
+    within the API container (otherwise the request-monitor sidecar reports them).
+    """
+    if os.getenv("CORTEX_REQUEST_MONITOR") != "in_process":
+        return
+
+    RequestMonitor(
+        api_name=api_name,
+        cluster_name=os.environ["CORTEX_CLUSTER_NAME"],
+        region=os.environ["AWS_REGION"],
+        publish_interval=int(os.getenv("CORTEX_REQUEST_MONITOR_FLUSH_INTERVAL", "10")),
+    ).start()
@@ -16,17 +16,23 @@ import grpc
 import time
 import threading
 
+from google.protobuf import text_format
 from tensorflow_serving.apis import model_service_pb2_grpc
 from tensorflow_serving.apis import model_management_pb2
 from tensorflow_serving.config import model_server_config_pb2
 
-from cortex.lib.exceptions import CortexException
+from cortex.lib.exceptions import CortexException, UserException
 from cortex.lib.log import cx_logger
 
 
 class TensorFlowServing:
-    def __init__(self, address):
+    def __init__(self, address, model_config=None):
+        """
+        address - The address of the TensorFlow Serving server.
+        model_config - ModelConfig fields in protobuf text format, which are applied to every model (e.g. "model_version_policy { all {} }").
+        """
         self.address = address
+        self.model_config = model_config
         self.model_platform = "tensorflow"
         self.channel = grpc.insecure_channel(self.address)
         self.stub = model_service_pb2_grpc.ModelServiceStub(self.channel)
@@ -40,6 +46,13 @@ class TensorFlowServing:
         config_list = model_server_config_pb2.ModelConfigList()
         for i, name in enumerate(names):
             model_config = config_list.config.add()
+            if self.model_config:
+                try:
+                    text_format.Merge(self.model_config, model_config)
+                except text_format.ParseError as e:
+                    raise UserException(
+                        "predictor.tensorflow_serving_config.model_config", str(e)
+                    ) from e
             model_config.name = name
             model_config.base_path = base_paths[i]
             model_config.model_platform = self.model_platform
@@ -14,4 +14,6 @@
 
 from cortex.lib.storage.local import LocalStorage
 from cortex.lib.storage.s3 import S3
+from cortex.lib.storage.gcs import GCS
+from cortex.lib.storage.cluster import cluster_storage, deconstruct_bucket_path
 from cortex.lib.storage.concurrency import FileLock
@@ -0,0 +1,36 @@
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import os
+
+from cortex.lib.storage.local import LocalStorage
+from cortex.lib.storage.s3 import S3
+from cortex.lib.storage.gcs import GCS
+
+
+# the storage of the cluster's bucket (or of the local cache when running locally), which holds the
+# APIs' specs, payload logs, and dead letters
+def cluster_storage(provider, cache_dir=None):
+    if provider == "local":
+        return LocalStorage(cache_dir)
+    if provider == "gcp":
+        return GCS(bucket=os.environ["CORTEX_BUCKET"])
+    return S3(bucket=os.environ["CORTEX_BUCKET"], region=os.environ["AWS_REGION"])
+
+
+# returns the bucket and key of a path in the cluster's bucket (s3://bucket/key or gs://bucket/key)
+def deconstruct_bucket_path(bucket_path):
+    if bucket_path.startswith("gs://"):
+        return GCS.deconstruct_gcs_path(bucket_path)
+    return S3.deconstruct_s3_path(bucket_path)
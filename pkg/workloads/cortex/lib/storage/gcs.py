@@ -0,0 +1,161 @@
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import os
+import json
+import time
+import msgpack
+from google.api_core import exceptions as gexceptions
+from google.cloud import storage as gcs
+
+from cortex.lib import util
+from cortex.lib.exceptions import CortexException
+
+
+# the cluster's bucket on gcp; authenticates as the google service account which the pod's
+# kubernetes service account is bound to with Workload Identity
+class GCS(object):
+    def __init__(self, bucket=None, project=None):
+        self.bucket = bucket
+        self.gcs = gcs.Client(project=project)
+
+    @staticmethod
+    def deconstruct_gcs_path(gcs_path):
+        path = util.trim_prefix(gcs_path, "gs://")
+        bucket = path.split("/")[0]
+        key = os.path.join(*path.split("/")[1:])
+        return (bucket, key)
+
+    def blob_path(self, key):
+        return os.path.join("gs://", self.bucket, key)
+
+    def _blob(self, key):
+        return self.gcs.bucket(self.bucket).blob(key)
+
+    def _file_exists(self, key):
+        return self._blob(key).exists()
+
+    def _is_gcs_dir(self, dir_path):
+        prefix = util.ensure_suffix(dir_path, "/")
+        for _ in self.gcs.list_blobs(self.bucket, prefix=prefix, max_results=1):
+            return True
+        return False
+
+    def _get_matching_gcs_blobs_generator(self, prefix="", suffix=""):
+        for blob in self.gcs.list_blobs(self.bucket, prefix=prefix):
+            if blob.name.endswith(suffix):
+                yield blob
+
+    def _read_bytes_from_gcs(self, key, allow_missing=False, num_retries=0, retry_delay_sec=2):
+        while True:
+            try:
+                return self._read_bytes_from_gcs_single(key, allow_missing=allow_missing)
+            except:
+                if num_retries <= 0:
+                    raise
+                num_retries -= 1
+                time.sleep(retry_delay_sec)
+
+    def _read_bytes_from_gcs_single(self, key, allow_missing=False):
+        try:
+            try:
+                byte_array = self._blob(key).download_as_bytes()
+            except gexceptions.NotFound:
+                if allow_missing:
+                    return None
+                raise
+        except Exception as e:
+            raise CortexException(
+                'key "{}" in bucket "{}" could not be accessed; '.format(key, self.bucket)
+                + "it may not exist, or you may not have sufficient permissions"
+            ) from e
+
+        return byte_array.strip()
+
+    def search(self, prefix="", suffix=""):
+        return [blob.name for blob in self._get_matching_gcs_blobs_generator(prefix, suffix)]
+
+    def put_str(self, str_val, key):
+        self._blob(key).upload_from_string(str_val)
+
+    def put_json(self, obj, key):
+        self._blob(key).upload_from_string(json.dumps(obj), content_type="application/json")
+
+    def get_json(self, key, allow_missing=False, num_retries=0, retry_delay_sec=2):
+        obj = self._read_bytes_from_gcs(
+            key,
+            allow_missing=allow_missing,
+            num_retries=num_retries,
+            retry_delay_sec=retry_delay_sec,
+        )
+        if obj is None:
+            return None
+        return json.loads(obj.decode("utf-8"))
+
+    def put_msgpack(self, obj, key):
+        self._blob(key).upload_from_string(msgpack.dumps(obj))
+
+    def get_msgpack(self, key, allow_missing=False, num_retries=0, retry_delay_sec=2):
+        obj = self._read_bytes_from_gcs(
+            key,
+            allow_missing=allow_missing,
+            num_retries=num_retries,
+            retry_delay_sec=retry_delay_sec,
+        )
+        if obj is None:
+            return None
+        return msgpack.loads(obj, raw=False)
+
+    def upload_file(self, local_path, key):
+        self._blob(key).upload_from_filename(local_path)
+
+    def download_file_to_dir(self, key, local_dir_path):
+        filename = os.path.basename(key)
+        return self.download_file(key, os.path.join(local_dir_path, filename))
+
+    def download_file(self, key, local_path):
+        util.mkdir_p(os.path.dirname(local_path))
+        try:
+            self._blob(key).download_to_filename(local_path)
+            return local_path
+        except Exception as e:
+            raise CortexException(
+                'key "{}" in bucket "{}" could not be accessed; '.format(key, self.bucket)
+                + "it may not exist, or you may not have sufficient permissions"
+            ) from e
+
+    def download_dir(self, prefix, local_dir):
+        dir_name = util.trim_suffix(prefix, "/").split("/")[-1]
+        return self.download_dir_contents(prefix, os.path.join(local_dir, dir_name))
+
+    def download_dir_contents(self, prefix, local_dir):
+        util.mkdir_p(local_dir)
+        prefix = util.ensure_suffix(prefix, "/")
+        for blob in self._get_matching_gcs_blobs_generator(prefix):
+            if blob.name.endswith("/"):
+                continue
+            rel_path = util.trim_prefix(blob.name, prefix)
+            self.download_file(blob.name, os.path.join(local_dir, rel_path))
+
+    def download_and_unzip(self, key, local_dir):
+        util.mkdir_p(local_dir)
+        local_zip = os.path.join(local_dir, "zip.zip")
+        self.download_file(key, local_zip)
+        util.extract_zip(local_zip, delete_zip_file=True)
+
+    def download(self, prefix, local_dir):
+        if self._is_gcs_dir(prefix):
+            self.download_dir(prefix, local_dir)
+        else:
+            self.download_file_to_dir(prefix, local_dir)
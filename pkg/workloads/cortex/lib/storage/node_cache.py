@@ -0,0 +1,171 @@
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import os
+import shutil
+import tempfile
+import time
+
+# model files are cached on each node in a hostPath directory (see download_sources.go), which is
+# shared by the replicas on the node and by the node's model seeder (if p2p_model_distribution is
+# enabled):
+#   blobs/<algorithm>/<hex digest>: downloaded files, by their digest
+#   models/<content key>: downloaded (and unzipped) model directories, by the hash of their
+#     contents, which are copied into the replicas' emptyDir volumes (rather than mounted, so that
+#     pruning the cache doesn't affect running replicas)
+#   tmp: files and directories which are being downloaded
+
+STALE_TMP_AGE = 24 * 60 * 60  # seconds
+
+
+def blob_path(cache_dir, digest):
+    algorithm, hex_digest = digest.split(":", 1)
+    return os.path.join(cache_dir, "blobs", algorithm, hex_digest)
+
+
+def model_path(cache_dir, content_key):
+    return os.path.join(cache_dir, "models", content_key)
+
+
+def get_blob(cache_dir, digest, download):
+    """
+    Returns the path of the digest's file in the cache, calling download(path) to add it to the
+    cache if it isn't cached already.
+    """
+    cache_path = blob_path(cache_dir, digest)
+    if os.path.isfile(cache_path):
+        os.utime(cache_path)  # the cache is pruned by last use
+        return cache_path
+
+    # multiple replicas on the node may download the same file concurrently, so each one downloads
+    # to a temporary file (outside of the blobs directory, so that it isn't pruned), which is
+    # renamed atomically
+    os.makedirs(os.path.dirname(cache_path), exist_ok=True)
+    fd, tmp_path = tempfile.mkstemp(dir=_tmp_dir(cache_dir))
+    os.close(fd)
+    try:
+        download(tmp_path)
+        os.rename(tmp_path, cache_path)
+    finally:
+        if os.path.exists(tmp_path):
+            os.remove(tmp_path)
+
+    return cache_path
+
+
+def get_model(cache_dir, content_key, download):
+    """
+    Returns the path of the model directory with the content key in the cache, calling
+    download(dir_path) to add it to the cache if it isn't cached already.
+    """
+    cache_path = model_path(cache_dir, content_key)
+    if os.path.isdir(cache_path):
+        os.utime(cache_path)
+        return cache_path
+
+    os.makedirs(os.path.dirname(cache_path), exist_ok=True)
+    tmp_path = tempfile.mkdtemp(dir=_tmp_dir(cache_dir))
+    try:
+        download(tmp_path)
+        try:
+            os.rename(tmp_path, cache_path)
+        except OSError:
+            if not os.path.isdir(cache_path):
+                raise
+            # another replica on the node added the same model first
+    finally:
+        shutil.rmtree(tmp_path, ignore_errors=True)
+
+    return cache_path
+
+
+def copy_dir_contents(src_dir, dest_dir):
+    os.makedirs(dest_dir, exist_ok=True)
+    for name in os.listdir(src_dir):
+        src_path = os.path.join(src_dir, name)
+        dest_path = os.path.join(dest_dir, name)
+        if os.path.isdir(src_path):
+            copy_dir_contents(src_path, dest_path)
+        else:
+            shutil.copyfile(src_path, dest_path)
+
+
+def prune(cache_dir, max_bytes):
+    """
+    Removes the least recently used files and model directories until the cache's size is at most
+    max_bytes, and removes temporary files which were abandoned (e.g. by replicas which were
+    terminated while downloading).
+    """
+    entries = []
+    for root, _, files in os.walk(os.path.join(cache_dir, "blobs")):
+        for name in files:
+            path = os.path.join(root, name)
+            try:
+                stat = os.stat(path)
+            except FileNotFoundError:
+                continue  # removed by another replica
+            entries.append((stat.st_mtime, stat.st_size, path))
+
+    models_dir = os.path.join(cache_dir, "models")
+    if os.path.isdir(models_dir):
+        for name in os.listdir(models_dir):
+            path = os.path.join(models_dir, name)
+            try:
+                entries.append((os.stat(path).st_mtime, _dir_size(path), path))
+            except FileNotFoundError:
+                continue
+
+    total_bytes = sum(size for _, size, _ in entries)
+    for _, size, path in sorted(entries):
+        if total_bytes <= max_bytes:
+            break
+        _remove(path)
+        total_bytes -= size
+
+    tmp_dir = os.path.join(cache_dir, "tmp")
+    if os.path.isdir(tmp_dir):
+        for name in os.listdir(tmp_dir):
+            path = os.path.join(tmp_dir, name)
+            try:
+                if time.time() - os.stat(path).st_mtime > STALE_TMP_AGE:
+                    _remove(path)
+            except FileNotFoundError:
+                continue
+
+
+def _tmp_dir(cache_dir):
+    tmp_dir = os.path.join(cache_dir, "tmp")
+    os.makedirs(tmp_dir, exist_ok=True)
+    return tmp_dir
+
+
+def _dir_size(dir_path):
+    size = 0
+    for root, _, files in os.walk(dir_path):
+        for name in files:
+            try:
+                size += os.stat(os.path.join(root, name)).st_size
+            except FileNotFoundError:
+                continue
+    return size
+
+
+def _remove(path):
+    try:
+        if os.path.isdir(path):
+            shutil.rmtree(path, ignore_errors=True)
+        else:
+            os.remove(path)
+    except FileNotFoundError:
+        pass
@@ -0,0 +1,207 @@
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import base64
+import hashlib
+import json
+import os
+import re
+import shutil
+import tarfile
+import tempfile
+import urllib.error
+import urllib.parse
+import urllib.request
+
+import boto3
+import google.auth
+import google.auth.transport.requests
+
+from cortex.lib import util
+from cortex.lib.storage import node_cache
+from cortex.lib.exceptions import CortexException, UserException
+
+MANIFEST_MEDIA_TYPES = [
+    "application/vnd.oci.image.manifest.v1+json",
+    "application/vnd.docker.distribution.manifest.v2+json",
+]
+
+# ORAS sets the file name of each layer with this annotation, and marks directories (which it pushes
+# as tarballs) with the unpack annotation
+TITLE_ANNOTATION = "org.opencontainers.image.title"
+UNPACK_ANNOTATION = "io.deis.oras.content.unpack"
+
+ECR_REGISTRY_PATTERN = re.compile(
+    r"^([0-9]+)\.dkr\.ecr(?:-fips)?\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?$"
+)
+
+# Container Registry and Artifact Registry hosts
+GCR_REGISTRY_PATTERN = re.compile(r"^((?:[a-z]+\.)?gcr\.io|[a-z0-9][a-z0-9-]*-docker\.pkg\.dev)$")
+
+CHUNK_SIZE = 1024 * 1024
+
+
+class _RedirectHandler(urllib.request.HTTPRedirectHandler):
+    # registries redirect blob downloads to storage (e.g. S3 presigned URLs), which reject the
+    # registry's credentials
+    def redirect_request(self, req, fp, code, msg, headers, newurl):
+        new_req = super().redirect_request(req, fp, code, msg, headers, newurl)
+        if new_req is not None:
+            if urllib.parse.urlparse(newurl).netloc != urllib.parse.urlparse(req.full_url).netloc:
+                new_req.remove_header("Authorization")
+        return new_req
+
+
+class OCIRegistry(object):
+    def __init__(self, registry):
+        """
+        Pulls the files of OCI artifacts (e.g. which were pushed with ORAS) from a registry.
+
+        ECR registries are authenticated with the AWS credentials of the container, Container
+        Registry and Artifact Registry registries are authenticated with the google service account
+        of the pod (via Workload Identity), and other registries must allow anonymous pulls.
+        """
+        self.registry = registry
+        self._opener = urllib.request.build_opener(_RedirectHandler())
+        self._auth_header = None
+
+        ecr_match = ECR_REGISTRY_PATTERN.match(registry)
+        if ecr_match is not None:
+            ecr = boto3.client("ecr", region_name=ecr_match.group(2))
+            auth = ecr.get_authorization_token(registryIds=[ecr_match.group(1)])
+            self._auth_header = "Basic " + auth["authorizationData"][0]["authorizationToken"]
+        elif GCR_REGISTRY_PATTERN.match(registry) is not None:
+            credentials, _ = google.auth.default(
+                scopes=["https://www.googleapis.com/auth/cloud-platform"]
+            )
+            credentials.refresh(google.auth.transport.requests.Request())
+            basic_auth = "oauth2accesstoken:{}".format(credentials.token)
+            self._auth_header = "Basic " + base64.b64encode(basic_auth.encode("utf-8")).decode()
+
+    @staticmethod
+    def deconstruct_oci_path(oci_path):
+        """
+        Returns the registry, repository, and reference (a tag or a digest) of an oci:// path.
+        """
+        path = util.trim_prefix(oci_path, "oci://")
+        registry, repository = path.split("/", 1)
+        if "@" in repository:
+            repository, reference = repository.split("@", 1)
+        elif ":" in repository.split("/")[-1]:
+            repository, reference = repository.rsplit(":", 1)
+        else:
+            reference = "latest"
+        return registry, repository, reference
+
+    def pull(self, repository, reference, dest_dir, cache_dir=None, cache_max_bytes=0):
+        """
+        Downloads the artifact's files into dest_dir. If cache_dir is set, blobs are cached there by
+        their digest (and the least recently used blobs are removed once the cache exceeds
+        cache_max_bytes).
+        """
+        manifest_url = "{}/manifests/{}".format(repository, reference)
+        with self._request(manifest_url, accept=MANIFEST_MEDIA_TYPES) as response:
+            manifest = json.loads(response.read().decode("utf-8"))
+
+        dest_dir = os.path.abspath(dest_dir)
+        os.makedirs(dest_dir, exist_ok=True)
+        for layer in manifest.get("layers", []):
+            annotations = layer.get("annotations", {})
+            title = annotations.get(TITLE_ANNOTATION)
+            if not title:
+                raise UserException(
+                    "layer {} of {}/{} doesn't have a file name ({} annotation); push the "
+                    "model's files with oras".format(
+                        layer["digest"], self.registry, repository, TITLE_ANNOTATION
+                    )
+                )
+            dest_path = os.path.abspath(os.path.join(dest_dir, title))
+            if os.path.commonpath([dest_dir, dest_path]) != dest_dir:
+                raise UserException(
+                    "invalid file name in {}/{}: {}".format(self.registry, repository, title)
+                )
+
+            if annotations.get(UNPACK_ANNOTATION) == "true":
+                with tempfile.TemporaryDirectory() as tmp_dir:
+                    tarball_path = os.path.join(tmp_dir, "layer.tar.gz")
+                    self._get_blob(repository, layer["digest"], tarball_path, cache_dir)
+                    with tarfile.open(tarball_path) as tarball:
+                        tarball.extractall(dest_dir)
+            else:
+                os.makedirs(os.path.dirname(dest_path), exist_ok=True)
+                self._get_blob(repository, layer["digest"], dest_path, cache_dir)
+
+        if cache_dir is not None and cache_max_bytes > 0:
+            node_cache.prune(cache_dir, cache_max_bytes)
+
+    def _get_blob(self, repository, digest, dest_path, cache_dir):
+        if cache_dir is None:
+            self._download_blob(repository, digest, dest_path)
+            return
+
+        cache_path = node_cache.get_blob(
+            cache_dir, digest, lambda path: self._download_blob(repository, digest, path)
+        )
+        shutil.copyfile(cache_path, dest_path)
+
+    def _download_blob(self, repository, digest, dest_path):
+        algorithm, expected = digest.split(":", 1)
+        if algorithm != "sha256":
+            raise CortexException("unsupported digest algorithm: {}".format(digest))
+
+        sha256 = hashlib.sha256()
+        with self._request("{}/blobs/{}".format(repository, digest)) as response:
+            with open(dest_path, "wb") as f:
+                for chunk in iter(lambda: response.read(CHUNK_SIZE), b""):
+                    sha256.update(chunk)
+                    f.write(chunk)
+
+        if sha256.hexdigest() != expected:
+            raise CortexException(
+                "the contents of {}/{}@{} don't match its digest".format(
+                    self.registry, repository, digest
+                )
+            )
+
+    def _request(self, path, accept=None):
+        url = "https://{}/v2/{}".format(self.registry, path)
+        try:
+            return self._opener.open(self._new_request(url, accept))
+        except urllib.error.HTTPError as e:
+            if e.code != 401 or self._auth_header is not None:
+                raise
+            # registries which allow anonymous pulls issue tokens for the scope in the challenge
+            self._auth_header = self._anonymous_auth_header(e.headers.get("WWW-Authenticate", ""))
+            return self._opener.open(self._new_request(url, accept))
+
+    def _new_request(self, url, accept):
+        req = urllib.request.Request(url)
+        if accept is not None:
+            req.add_header("Accept", ", ".join(accept))
+        if self._auth_header is not None:
+            req.add_header("Authorization", self._auth_header)
+        return req
+
+    def _anonymous_auth_header(self, challenge):
+        if not challenge.startswith("Bearer "):
+            raise UserException(
+                "unable to pull from {}: only ECR and registries which allow anonymous pulls are "
+                "supported".format(self.registry)
+            )
+        params = dict(re.findall(r'(\w+)="([^"]*)"', challenge))
+        query = urllib.parse.urlencode({k: v for k, v in params.items() if k != "realm"})
+        with self._opener.open(params["realm"] + "?" + query) as response:
+            token = json.loads(response.read().decode("utf-8"))
+        return "Bearer " + token.get("token", token.get("access_token", ""))
+
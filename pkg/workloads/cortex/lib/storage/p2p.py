@@ -0,0 +1,153 @@
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import hashlib
+import os
+import shutil
+import socket
+import time
+import urllib.parse
+import urllib.request
+
+from cortex.lib import util
+from cortex.lib.log import cx_logger
+from cortex.lib.storage import node_cache
+
+PEER_TIMEOUT = 30  # seconds
+PEER_POLL_INTERVAL = 5  # seconds
+
+
+def s3_object_digest(bucket, key, etag):
+    """
+    Returns the digest which identifies an S3 object's contents in the node cache (S3 ETags aren't
+    content hashes for multipart uploads, so the digest is derived from the object's path and ETag).
+    """
+    path = "{}/{}@{}".format(bucket, key, etag.strip('"'))
+    return "s3:" + hashlib.sha256(path.encode("utf-8")).hexdigest()
+
+
+def s3_object_url(peer, bucket, key, etag, version_id=None):
+    query = {"etag": etag.strip('"')}
+    if version_id:
+        query["version_id"] = version_id
+    return "http://{}/s3/{}/{}?{}".format(
+        peer, bucket, urllib.parse.quote(key), urllib.parse.urlencode(query)
+    )
+
+
+class P2PDownloader(object):
+    def __init__(self, peers, cache_dir, cache_max_bytes=0):
+        """
+        Downloads S3 objects from the cluster's model seeders (peers is the host:port of their
+        headless service). Each object is assigned to one seeder, which downloads it from S3 and
+        serves it to the other nodes; objects are downloaded from S3 directly if their seeder
+        isn't available.
+        """
+        self.peers = peers
+        self.cache_dir = cache_dir
+        self.cache_max_bytes = cache_max_bytes
+        self._peer_addresses = None
+
+    def download(self, s3_client, prefix, local_dir, version_id=None):
+        """
+        Downloads an S3 file or directory into local_dir (with the same layout as S3.download()).
+        """
+        if not version_id and s3_client._is_s3_dir(prefix):
+            prefix = util.ensure_suffix(prefix, "/")
+            dir_name = util.trim_suffix(prefix, "/").split("/")[-1]
+            for obj in s3_client._get_matching_s3_objects_generator(prefix):
+                if obj["Key"].endswith("/"):
+                    continue
+                rel_path = util.trim_prefix(obj["Key"], prefix)
+                local_path = os.path.join(local_dir, dir_name, rel_path)
+                self.download_object(s3_client, obj["Key"], obj["ETag"], local_path)
+        else:
+            head_args = {"Bucket": s3_client.bucket, "Key": prefix}
+            if version_id:
+                head_args["VersionId"] = version_id
+            etag = s3_client.s3.head_object(**head_args)["ETag"]
+            local_path = os.path.join(local_dir, os.path.basename(prefix))
+            self.download_object(s3_client, prefix, etag, local_path, version_id)
+
+        if self.cache_max_bytes > 0:
+            node_cache.prune(self.cache_dir, self.cache_max_bytes)
+
+    def download_object(self, s3_client, key, etag, local_path, version_id=None):
+        digest = s3_object_digest(s3_client.bucket, key, etag)
+
+        def download(path):
+            seeder = self._seeder(digest)
+            if seeder is not None:
+                try:
+                    self._download_from_seeder(
+                        seeder, s3_client.bucket, key, etag, version_id, path
+                    )
+                    return
+                except Exception as e:
+                    cx_logger().warning(
+                        "unable to download {} from the model seeder at {} ({}); downloading it "
+                        "from s3".format(key, seeder, e)
+                    )
+            s3_client.download_file(key, path, version_id)
+
+        cache_path = node_cache.get_blob(self.cache_dir, digest, download)
+        util.mkdir_p(os.path.dirname(local_path))
+        shutil.copyfile(cache_path, local_path)
+
+    def _seeder(self, digest):
+        """
+        Returns the address of the seeder which is assigned the object (with rendezvous hashing, so
+        that every node picks the same seeder, and objects are spread across the seeders).
+        """
+        addresses = self._addresses()
+        if len(addresses) == 0:
+            return None
+        return max(
+            addresses,
+            key=lambda address: hashlib.sha256((digest + address).encode("utf-8")).hexdigest(),
+        )
+
+    def _addresses(self):
+        if self._peer_addresses is None:
+            host, port = self.peers.rsplit(":", 1)
+            try:
+                infos = socket.getaddrinfo(host, port, proto=socket.IPPROTO_TCP)
+                self._peer_addresses = sorted(
+                    set("{}:{}".format(info[4][0], port) for info in infos)
+                )
+            except socket.gaierror as e:
+                cx_logger().warning("unable to find the model seeders ({})".format(e))
+                self._peer_addresses = []
+        return self._peer_addresses
+
+    def _download_from_seeder(self, seeder, bucket, key, etag, version_id, path):
+        url = s3_object_url(seeder, bucket, key, etag, version_id)
+
+        # the seeder responds with 202 until it has downloaded the object from s3
+        logged = False
+        while True:
+            with urllib.request.urlopen(url, timeout=PEER_TIMEOUT) as response:
+                if response.status == 200:
+                    with open(path, "wb") as f:
+                        shutil.copyfileobj(response, f, 1024 * 1024)
+                    expected_size = int(response.headers["Content-Length"])
+                    if os.path.getsize(path) != expected_size:
+                        raise ConnectionError("the download was interrupted")
+                    return
+            if not logged:
+                cx_logger().info(
+                    "waiting for the model seeder at {} to download {}".format(seeder, key)
+                )
+                logged = True
+            time.sleep(PEER_POLL_INTERVAL)
@@ -19,6 +19,7 @@ import pickle
 import json
 import msgpack
 import time
+from botocore.config import Config
 
 from cortex.lib import util
 from cortex.lib.exceptions import CortexException
@@ -35,6 +36,12 @@ class S3(object):
         if region is not None:
             client_config["region_name"] = region
 
+        # S3-compatible object storage (e.g. MinIO), configured via the cluster's s3_endpoint
+        s3_endpoint = os.environ.get("CORTEX_S3_ENDPOINT")
+        if s3_endpoint:
+            client_config["endpoint_url"] = s3_endpoint
+            client_config["config"] = Config(s3={"addressing_style": "path"})
+
         self.s3 = boto3.client("s3", **client_config)
 
     @staticmethod
@@ -163,14 +170,17 @@ class S3(object):
     def upload_file(self, local_path, key):
         self.s3.upload_file(local_path, self.bucket, key)
 
-    def download_file_to_dir(self, key, local_dir_path):
+    def download_file_to_dir(self, key, local_dir_path, version_id=None):
         filename = os.path.basename(key)
-        return self.download_file(key, os.path.join(local_dir_path, filename))
+        return self.download_file(key, os.path.join(local_dir_path, filename), version_id)
 
-    def download_file(self, key, local_path):
+    def download_file(self, key, local_path, version_id=None):
         util.mkdir_p(os.path.dirname(local_path))
+        extra_args = None
+        if version_id is not None:
+            extra_args = {"VersionId": version_id}
         try:
-            self.s3.download_file(self.bucket, key, local_path)
+            self.s3.download_file(self.bucket, key, local_path, ExtraArgs=extra_args)
             return local_path
         except Exception as e:
             raise CortexException(
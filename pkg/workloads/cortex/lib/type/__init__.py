@@ -13,10 +13,12 @@
 # limitations under the License.
 
 from cortex.lib.type.api import API, get_spec
-from cortex.lib.type.predictor import Predictor
+from cortex.lib.type.predictor import Predictor, call_on_shutdown
 from cortex.lib.type.monitoring import Monitoring
 from cortex.lib.type.model import (
     Model,
     get_model_signature_map,
     get_model_names,
+    record_used_model,
+    pop_used_models,
 )
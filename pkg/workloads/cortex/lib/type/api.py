@@ -19,13 +19,12 @@ from pathlib import Path
 import json
 import msgpack
 
-import datadog
-
 from cortex.lib.log import cx_logger
 from cortex.lib.exceptions import CortexException
 from cortex.lib.type.predictor import Predictor
 from cortex.lib.type.monitoring import Monitoring
-from cortex.lib.storage import S3
+from cortex.lib.storage import deconstruct_bucket_path
+from cortex.lib.metric_sinks import get_metric_sinks
 
 
 class API:
@@ -44,10 +43,9 @@ class API:
         self.cache_dir = cache_dir
         self.storage = storage
 
+        self.metric_sinks = None
         if provider != "local":
-            host_ip = os.environ["HOST_IP"]
-            datadog.initialize(statsd_host=host_ip, statsd_port="8125")
-            self.statsd = datadog.statsd
+            self.metric_sinks = get_metric_sinks(kwargs.get("metric_tags") or {})
 
     def get_cached_classes(self):
         prefix = os.path.join(self.metadata_root, "classes") + "/"
@@ -73,7 +71,10 @@ class API:
     def metric_dimensions(self):
         return [{"Name": "APIName", "Value": self.name}]
 
-    def post_request_metrics(self, status_code, total_time):
+    def metric_dimensions_with_model(self, model_name):
+        return [{"Name": "APIName", "Value": self.name}, {"Name": "ModelName", "Value": model_name}]
+
+    def post_request_metrics(self, status_code, total_time, model_names=None):
         total_time_ms = total_time * 1000
         if self.provider == "local":
             self.store_metrics_locally(status_code, total_time_ms)
@@ -84,6 +85,12 @@ class API:
                 self.latency_metric(self.metric_dimensions(), total_time_ms),
                 self.latency_metric(self.metric_dimensions_with_id(), total_time_ms),
             ]
+            for model_name in model_names or []:
+                model_dimensions = self.metric_dimensions_with_model(model_name)
+                metrics += [
+                    self.status_code_metric(model_dimensions, status_code),
+                    self.latency_metric(model_dimensions, total_time_ms),
+                ]
             self.post_metrics(metrics)
 
     def post_monitoring_metrics(self, prediction_value=None):
@@ -94,17 +101,50 @@ class API:
             ]
             self.post_metrics(metrics)
 
+    def post_reward_metrics(self, reward):
+        # the sum of squares is recorded so that the operator can compute the reward's variance
+        metrics = [
+            {"MetricName": "Reward", "Dimensions": self.metric_dimensions(), "Value": reward},
+            {
+                "MetricName": "RewardSquared",
+                "Dimensions": self.metric_dimensions(),
+                "Value": reward * reward,
+            },
+        ]
+        self.post_metrics(metrics)
+
+    def post_stream_metrics(self, time_to_first_token, tokens_per_second=None):
+        metrics = [
+            {
+                "MetricName": "TimeToFirstToken",
+                "Dimensions": self.metric_dimensions(),
+                "Value": time_to_first_token * 1000,  # milliseconds
+            }
+        ]
+        if tokens_per_second is not None:
+            metrics.append(
+                {
+                    "MetricName": "TokensPerSecond",
+                    "Dimensions": self.metric_dimensions(),
+                    "Value": tokens_per_second,
+                }
+            )
+        self.post_metrics(metrics)
+
+    def metrics_client(self):
+        from cortex.lib.client.metrics import MetricsClient
+
+        max_metrics = int(os.getenv("CORTEX_MAX_CUSTOM_METRICS", "20"))
+        return MetricsClient(self, max_metrics)
+
     def post_metrics(self, metrics):
         try:
-            if self.statsd is None:
-                raise CortexException("statsd client not initialized")  # unexpected
+            if self.metric_sinks is None:
+                raise CortexException("metric sinks not initialized")  # unexpected
 
             for metric in metrics:
-                tags = ["{}:{}".format(dim["Name"], dim["Value"]) for dim in metric["Dimensions"]]
-                if metric.get("Unit") == "Count":
-                    self.statsd.increment(metric["MetricName"], value=metric["Value"], tags=tags)
-                else:
-                    self.statsd.histogram(metric["MetricName"], value=metric["Value"], tags=tags)
+                for sink in self.metric_sinks:
+                    sink.post(metric)
         except:
             cx_logger().warn("failure encountered while publishing metrics", exc_info=True)
 
@@ -169,7 +209,7 @@ def get_spec(provider, storage, cache_dir, spec_path):
     local_spec_path = os.path.join(cache_dir, "api_spec.msgpack")
 
     if not os.path.isfile(local_spec_path):
-        _, key = S3.deconstruct_s3_path(spec_path)
+        _, key = deconstruct_bucket_path(spec_path)
         storage.download_file(key, local_spec_path)
 
     return read_msgpack(local_spec_path)
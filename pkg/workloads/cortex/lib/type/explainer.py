@@ -0,0 +1,56 @@
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import os
+import imp
+import inspect
+
+from cortex.lib.log import refresh_logger
+from cortex.lib.exceptions import CortexException, UserException, UserRuntimeException
+from cortex.lib.type.predictor import _validate_impl
+
+
+EXPLAINER_CLASS_VALIDATION = {
+    "required": [
+        {"name": "__init__", "required_args": ["self", "config"]},
+        {"name": "explain", "required_args": ["self", "payload", "prediction"]},
+    ],
+}
+
+
+def initialize_explainer(project_dir, path, config):
+    # the explainer is initialized with the predictor's config
+    try:
+        try:
+            impl = imp.load_source("cortex_explainer", os.path.join(project_dir, path))
+        except Exception as e:
+            raise UserException(str(e)) from e
+        finally:
+            refresh_logger()
+
+        explainer_class = getattr(impl, "Explainer", None)
+        if not inspect.isclass(explainer_class):
+            raise UserException("Explainer class is not defined")
+
+        _validate_impl(explainer_class, EXPLAINER_CLASS_VALIDATION)
+    except CortexException as e:
+        e.wrap("error in " + path)
+        raise
+
+    try:
+        return explainer_class(config=config)
+    except Exception as e:
+        raise UserRuntimeException(path, "__init__", str(e)) from e
+    finally:
+        refresh_logger()
@@ -12,6 +12,8 @@
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
+import threading
+
 
 class Model:
     def __init__(self, name, model, base_path, signature_key=None):
@@ -31,3 +33,20 @@ def get_model_signature_map(models):
 
 def get_model_names(models):
     return [model.name for model in models]
+
+
+# each request is handled in its own thread, so the models which a request used can be tracked per thread
+_used_models = threading.local()
+
+
+def record_used_model(model_name):
+    if not hasattr(_used_models, "names"):
+        _used_models.names = []
+    if model_name not in _used_models.names:
+        _used_models.names.append(model_name)
+
+
+def pop_used_models():
+    model_names = getattr(_used_models, "names", [])
+    _used_models.names = []
+    return model_names
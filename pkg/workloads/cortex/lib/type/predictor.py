@@ -15,6 +15,7 @@
 import os
 import imp
 import inspect
+import threading
 
 import dill
 
@@ -80,18 +81,33 @@ class Predictor:
                 )
             cx_logger().info(signature_message)
             return client
+        elif self.type == "llm":
+            from cortex.lib.client.llm import LLMClient
+
+            llm_server_url = "http://localhost:" + os.environ["CORTEX_LLM_SERVER_PORT"]
+            return LLMClient(os.environ["CORTEX_LLM_SERVER"], llm_server_url)
 
         return None
 
-    def initialize_impl(self, project_dir, client=None):
+    def initialize_impl(self, project_dir, client=None, metrics_client=None):
         class_impl = self.class_impl(project_dir)
+
+        kwargs = {}
+        init_args = inspect.getfullargspec(class_impl.__init__).args
+        if "feature_store" in init_args:
+            kwargs["feature_store"] = initialize_feature_store()
+        if "metrics_client" in init_args:
+            kwargs["metrics_client"] = metrics_client
+
         try:
             if self.type == "onnx":
-                return class_impl(onnx_client=client, config=self.config)
+                return class_impl(onnx_client=client, config=self.config, **kwargs)
             elif self.type == "tensorflow":
-                return class_impl(tensorflow_client=client, config=self.config)
+                return class_impl(tensorflow_client=client, config=self.config, **kwargs)
+            elif self.type == "llm":
+                return class_impl(llm_client=client, config=self.config, **kwargs)
             else:
-                return class_impl(config=self.config)
+                return class_impl(config=self.config, **kwargs)
         except Exception as e:
             raise UserRuntimeException(self.path, "__init__", str(e)) from e
         finally:
@@ -104,6 +120,9 @@ class Predictor:
         elif self.type == "onnx":
             target_class_name = "ONNXPredictor"
             validations = ONNX_CLASS_VALIDATION
+        elif self.type == "llm":
+            target_class_name = "LLMPredictor"
+            validations = LLM_CLASS_VALIDATION
         elif self.type == "python":
             target_class_name = "PythonPredictor"
             validations = PYTHON_CLASS_VALIDATION
@@ -165,37 +184,100 @@ class Predictor:
 
 PYTHON_CLASS_VALIDATION = {
     "required": [
-        {"name": "__init__", "required_args": ["self", "config"]},
+        {
+            "name": "__init__",
+            "required_args": ["self", "config"],
+            "optional_args": ["feature_store", "metrics_client"],
+        },
         {
             "name": "predict",
             "required_args": ["self"],
             "optional_args": ["payload", "query_params", "headers"],
         },
-    ]
+    ],
+    "optional": [
+        {"name": "reward", "required_args": ["self", "payload", "prediction"]},
+        {"name": "on_shutdown", "required_args": ["self"]},
+    ],
 }
 
 TENSORFLOW_CLASS_VALIDATION = {
     "required": [
-        {"name": "__init__", "required_args": ["self", "tensorflow_client", "config"]},
+        {
+            "name": "__init__",
+            "required_args": ["self", "tensorflow_client", "config"],
+            "optional_args": ["feature_store", "metrics_client"],
+        },
         {
             "name": "predict",
             "required_args": ["self"],
             "optional_args": ["payload", "query_params", "headers"],
         },
-    ]
+    ],
+    "optional": [
+        {"name": "reward", "required_args": ["self", "payload", "prediction"]},
+        {"name": "on_shutdown", "required_args": ["self"]},
+    ],
 }
 
 ONNX_CLASS_VALIDATION = {
     "required": [
-        {"name": "__init__", "required_args": ["self", "onnx_client", "config"]},
+        {
+            "name": "__init__",
+            "required_args": ["self", "onnx_client", "config"],
+            "optional_args": ["feature_store", "metrics_client"],
+        },
         {
             "name": "predict",
             "required_args": ["self"],
             "optional_args": ["payload", "query_params", "headers"],
         },
-    ]
+    ],
+    "optional": [
+        {"name": "reward", "required_args": ["self", "payload", "prediction"]},
+        {"name": "on_shutdown", "required_args": ["self"]},
+    ],
 }
 
+LLM_CLASS_VALIDATION = {
+    "required": [
+        {
+            "name": "__init__",
+            "required_args": ["self", "llm_client", "config"],
+            "optional_args": ["feature_store", "metrics_client"],
+        },
+        {
+            "name": "predict",
+            "required_args": ["self"],
+            "optional_args": ["payload", "query_params", "headers"],
+        },
+    ],
+    "optional": [
+        {"name": "reward", "required_args": ["self", "payload", "prediction"]},
+        {"name": "on_shutdown", "required_args": ["self"]},
+    ],
+}
+
+
+# the predictor's on_shutdown() runs in a separate thread, so that the replica isn't kept alive
+# once the timeout expires
+def call_on_shutdown(predictor_impl, timeout):
+    on_shutdown = getattr(predictor_impl, "on_shutdown", None)
+    if not callable(on_shutdown):
+        return
+
+    def run():
+        try:
+            on_shutdown()
+        except:
+            cx_logger().exception("an error occurred in the predictor's on_shutdown()")
+
+    thread = threading.Thread(target=run, daemon=True)
+    thread.start()
+    thread.join(timeout)
+    if thread.is_alive():
+        cx_logger().warn(f"the predictor's on_shutdown() did not finish within {timeout} seconds")
+
 
 def _validate_impl(impl, impl_req):
     for optional_func_signature in impl_req.get("optional", []):
@@ -251,6 +333,29 @@ def _validate_required_fn_args(impl, func_signature):
         seen_args.append(arg_name)
 
 
+def initialize_feature_store():
+    if os.getenv("CORTEX_FEATURE_STORE_TYPE") is None:
+        raise UserException(
+            "__init__() accepts a feature_store argument, but feature_store is not configured for this api"
+        )
+
+    from cortex.lib.client.feature_store import FeatureStoreClient
+
+    cache_ttl = None
+    if os.getenv("CORTEX_FEATURE_STORE_CACHE_TTL"):
+        cache_ttl = int(os.environ["CORTEX_FEATURE_STORE_CACHE_TTL"])
+
+    return FeatureStoreClient(
+        os.environ["CORTEX_FEATURE_STORE_TYPE"],
+        os.environ["CORTEX_FEATURE_STORE_URL"],
+        project=os.getenv("CORTEX_FEATURE_STORE_PROJECT"),
+        key_prefix=os.getenv("CORTEX_FEATURE_STORE_KEY_PREFIX"),
+        password=os.getenv("CORTEX_FEATURE_STORE_PASSWORD"),
+        cache_url=os.getenv("CORTEX_FEATURE_STORE_CACHE_URL"),
+        cache_ttl=cache_ttl,
+    )
+
+
 def uses_neuron_savedmodel():
     return os.getenv("CORTEX_ACTIVE_NEURON") != None
 
@@ -0,0 +1,154 @@
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import argparse
+import os
+import base64
+import json
+import sys
+
+from cortex.lib import util
+from cortex.lib.storage import S3
+from cortex.lib.log import cx_logger
+
+LOCAL_INPUT_DIR = "/mnt/model_optimizer/input"
+LOCAL_OUTPUT_DIR = "/mnt/model_optimizer/output"
+
+# written next to the optimized model, and awaited by the downloader (see k8s_specs.go)
+SUCCESS_FILE = "_SUCCESS"
+FAILURE_FILE = "_FAILED"
+
+
+def download_model(s3_path, version_id):
+    bucket_name, key = S3.deconstruct_s3_path(s3_path)
+    s3_client = S3(bucket_name, client_config={})
+
+    if key.endswith(".zip") or key.endswith(".onnx"):
+        local_path = s3_client.download_file_to_dir(key, LOCAL_INPUT_DIR, version_id=version_id)
+        if local_path.endswith(".zip"):
+            util.extract_zip(local_path, delete_zip_file=True)
+            return LOCAL_INPUT_DIR
+        return local_path
+
+    s3_client.download(key, LOCAL_INPUT_DIR)
+    return LOCAL_INPUT_DIR
+
+
+def find_saved_model_dir(model_dir):
+    for dir_path, _, file_names in os.walk(model_dir):
+        if "saved_model.pb" in file_names or "saved_model.pbtxt" in file_names:
+            return dir_path
+    raise ValueError("unable to find a SavedModel in the model directory")
+
+
+def optimize_tensorflow_model(model_dir, precision):
+    from tensorflow.python.compiler.tensorrt import trt_convert as trt
+
+    precision_mode = trt.TrtPrecisionMode.FP16 if precision == "fp16" else trt.TrtPrecisionMode.FP32
+    conversion_params = trt.DEFAULT_TRT_CONVERSION_PARAMS._replace(precision_mode=precision_mode)
+
+    converter = trt.TrtGraphConverterV2(
+        input_saved_model_dir=find_saved_model_dir(model_dir), conversion_params=conversion_params
+    )
+    converter.convert()
+
+    output_dir = os.path.join(LOCAL_OUTPUT_DIR, "model")
+    converter.save(output_dir)
+    return output_dir
+
+
+def optimize_onnx_model(model_path, precision):
+    import onnx
+    import onnxruntime as rt
+
+    if precision == "fp16":
+        from onnxmltools.utils.float16_converter import convert_float_to_float16
+
+        model = convert_float_to_float16(onnx.load(model_path))
+        model_path = os.path.join(LOCAL_INPUT_DIR, "model_fp16.onnx")
+        onnx.save(model, model_path)
+
+    util.mkdir_p(LOCAL_OUTPUT_DIR)
+    output_path = os.path.join(LOCAL_OUTPUT_DIR, "model.onnx")
+
+    # the optimized graph is written when the session is created; "extended" is the highest
+    # level whose optimizations can be serialized for models which run on the CUDA provider
+    sess_options = rt.SessionOptions()
+    sess_options.graph_optimization_level = rt.GraphOptimizationLevel.ORT_ENABLE_EXTENDED
+    sess_options.optimized_model_filepath = output_path
+    rt.InferenceSession(model_path, sess_options)
+
+    return output_path
+
+
+def upload(local_path, s3_client, key):
+    if os.path.isfile(local_path):
+        s3_client.upload_file(local_path, os.path.join(key, os.path.basename(local_path)))
+        return
+
+    for dir_path, _, file_names in os.walk(local_path):
+        for file_name in file_names:
+            file_path = os.path.join(dir_path, file_name)
+            rel_path = os.path.relpath(file_path, os.path.dirname(local_path))
+            s3_client.upload_file(file_path, os.path.join(key, rel_path))
+
+
+def start(args):
+    optimization_config = json.loads(base64.urlsafe_b64decode(args.optimize))
+    bucket_name, key = S3.deconstruct_s3_path(optimization_config["to"])
+    s3_client = S3(bucket_name, client_config={})
+
+    try:
+        cx_logger().info("downloading {}".format(optimization_config["from"]))
+        model_path = download_model(
+            optimization_config["from"], optimization_config.get("version_id") or None
+        )
+
+        cx_logger().info(
+            "optimizing the model ({} precision)".format(optimization_config["precision"])
+        )
+        if optimization_config["predictor_type"] == "tensorflow":
+            optimized_path = optimize_tensorflow_model(model_path, optimization_config["precision"])
+        else:
+            optimized_path = optimize_onnx_model(model_path, optimization_config["precision"])
+
+        cx_logger().info("uploading the optimized model to {}".format(optimization_config["to"]))
+        upload(optimized_path, s3_client, key)
+    except Exception as e:
+        cx_logger().exception("failed to optimize the model")
+        error_str = "the model could not be optimized: {}: {}".format(type(e).__name__, str(e))
+        s3_client.put_str(error_str, os.path.join(key, FAILURE_FILE))
+        sys.exit(1)
+
+    s3_client.put_str("", os.path.join(key, SUCCESS_FILE))
+    cx_logger().info("the model has been optimized")
+
+
+def main():
+    parser = argparse.ArgumentParser()
+    na = parser.add_argument_group("required named arguments")
+    na.add_argument(
+        "--optimize",
+        required=True,
+        help="a base64 encoded optimization config (see optimization.go for the structure)",
+    )
+    parser.set_defaults(func=start)
+
+    args = parser.parse_args()
+    args.func(args)
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,142 @@
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import sys
+import os
+import inspect
+import json
+import time
+import traceback
+
+from cortex import consts
+from cortex.lib.type import API, get_spec, pop_used_models
+from cortex.lib.log import cx_logger
+from cortex.lib.storage import S3, cluster_storage
+
+
+def to_json_value(prediction):
+    try:
+        json.dumps(prediction)
+        return prediction
+    except TypeError:
+        if isinstance(prediction, bytes):
+            return prediction.decode("utf-8", errors="replace")
+        return str(prediction)
+
+
+# writes the items which failed to the job's dead letter prefix, along with the context of their failure
+def write_dead_letters(path, api_name, job_id, partition, failed_items):
+    bucket, key = S3.deconstruct_s3_path(path)
+    dead_letter = {
+        "api_name": api_name,
+        "job_id": job_id,
+        "partition": partition,
+        "timestamp": time.time(),
+        "items": failed_items,
+    }
+    S3(bucket=bucket).put_json(dead_letter, key)
+
+
+# runs the predictor on each of the worker's items (which are stored in the cluster's bucket as a
+# list of {"index": ..., "item": ...}), and writes a result for each item to the bucket; items which
+# fail don't fail the worker (their errors are recorded in the results instead, and the items are
+# written to the job's dead letter prefix if it has one)
+def main():
+    if os.environ["CORTEX_VERSION"] != consts.CORTEX_VERSION:
+        errMsg = f"your Cortex operator version ({os.environ['CORTEX_VERSION']}) doesn't match your predictor image version ({consts.CORTEX_VERSION}); please update your predictor image by modifying the `image` field in your API configuration file (e.g. cortex.yaml) and re-running `cortex deploy`, or update your cluster by following the instructions at https://docs.cortex.dev/cluster-management/update"
+        raise ValueError(errMsg)
+
+    cache_dir = os.environ["CORTEX_CACHE_DIR"]
+    provider = os.environ["CORTEX_PROVIDER"]
+    spec_path = os.environ["CORTEX_API_SPEC"]
+    project_dir = os.environ["CORTEX_PROJECT_DIR"]
+    model_dir = os.getenv("CORTEX_MODEL_DIR")
+    job_id = os.environ["CORTEX_BATCH_JOB_ID"]
+    items_key = os.environ["CORTEX_BATCH_ITEMS_KEY"]
+    results_key = os.environ["CORTEX_BATCH_RESULTS_KEY"]
+    partition = int(os.environ["CORTEX_BATCH_PARTITION"])
+    dead_letter_path = os.getenv("CORTEX_BATCH_DEAD_LETTER_PATH")
+
+    storage = cluster_storage(provider, cache_dir)
+
+    try:
+        raw_api_spec = get_spec(provider, storage, cache_dir, spec_path)
+        api = API(
+            provider=provider,
+            storage=storage,
+            model_dir=model_dir,
+            cache_dir=cache_dir,
+            **raw_api_spec,
+        )
+        client = api.predictor.initialize_client()
+        cx_logger().info("loading the predictor from {}".format(api.predictor.path))
+        predictor_impl = api.predictor.initialize_impl(
+            project_dir, client, metrics_client=api.metrics_client()
+        )
+        predict_fn_args = inspect.getfullargspec(predictor_impl.predict).args
+        items = storage.get_json(items_key)
+    except:
+        cx_logger().exception(f"failed to start batch job {job_id}")
+        sys.exit(1)
+
+    cx_logger().info(f"processing {len(items)} items of batch job {job_id}")
+
+    results = []
+    failed_items = []
+    for item in items:
+        args = {}
+        if "payload" in predict_fn_args:
+            args["payload"] = item["item"]
+        if "headers" in predict_fn_args:
+            args["headers"] = {}
+        if "query_params" in predict_fn_args:
+            args["query_params"] = {}
+
+        pop_used_models()
+        try:
+            prediction = predictor_impl.predict(**args)
+            results.append({"index": item["index"], "result": to_json_value(prediction)})
+        except:
+            cx_logger().exception(f"failed to process item {item['index']}")
+            error = str(sys.exc_info()[1])
+            results.append({"index": item["index"], "error": error})
+            failed_items.append(
+                {
+                    "index": item["index"],
+                    "item": item["item"],
+                    "error": error,
+                    "traceback": traceback.format_exc(),
+                }
+            )
+
+    try:
+        storage.put_json(results, results_key)
+    except:
+        cx_logger().exception(f"failed to write the results of batch job {job_id}")
+        sys.exit(1)
+
+    # the worker fails if the failed items can't be written, so that the partition is retried
+    if dead_letter_path is not None and len(failed_items) > 0:
+        try:
+            write_dead_letters(dead_letter_path, api.name, job_id, partition, failed_items)
+        except:
+            cx_logger().exception(f"failed to write the failed items to {dead_letter_path}")
+            sys.exit(1)
+
+    cx_logger().info(f"processed {len(items)} items ({len(failed_items)} failed)")
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,90 @@
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import os
+import sys
+import json
+
+import uvicorn
+import yaml
+from fastapi import Body, FastAPI
+from starlette.responses import Response
+
+from cortex.lib.type import get_spec
+from cortex.lib.type.explainer import initialize_explainer
+from cortex.lib.storage import cluster_storage
+from cortex.lib.log import cx_logger
+
+app = FastAPI()
+
+local_cache = {"explainer_impl": None}
+
+
+# the API container posts the request's payload and the predictor's prediction (both as JSON)
+@app.post("/explain")
+def explain(body: dict = Body(...)):
+    try:
+        explanation = local_cache["explainer_impl"].explain(
+            payload=body.get("payload"), prediction=body.get("prediction")
+        )
+    except Exception as e:
+        cx_logger().exception("an error occurred in the explainer's explain()")
+        return Response(content=str(e), status_code=500)
+
+    try:
+        json_string = json.dumps(explanation)
+    except Exception as e:
+        cx_logger().exception(
+            "explain() must return an object that is JSON serializable (including its nested fields)"
+        )
+        return Response(content=str(e), status_code=500)
+
+    return Response(content=json_string, media_type="application/json")
+
+
+def main():
+    with open("/src/cortex/serve/log_config.yaml", "r") as f:
+        log_config = yaml.load(f, yaml.FullLoader)
+
+    cache_dir = os.environ["CORTEX_CACHE_DIR"]
+    storage = cluster_storage(os.environ["CORTEX_PROVIDER"], cache_dir)
+
+    try:
+        raw_api_spec = get_spec(
+            os.environ["CORTEX_PROVIDER"], storage, cache_dir, os.environ["CORTEX_API_SPEC"]
+        )
+        predictor = raw_api_spec["predictor"]
+        cx_logger().info("loading the explainer from {}".format(predictor["explainer"]["path"]))
+        local_cache["explainer_impl"] = initialize_explainer(
+            os.environ["CORTEX_PROJECT_DIR"],
+            predictor["explainer"]["path"],
+            predictor.get("config") or {},
+        )
+    except:
+        cx_logger().exception("failed to start the explainer")
+        sys.exit(1)
+
+    # a single worker, so that the explainer's models are only loaded into memory once
+    uvicorn.run(
+        app,
+        host="0.0.0.0",
+        port=int(os.environ["CORTEX_EXPLAINER_PORT"]),
+        workers=1,
+        log_config=log_config,
+        log_level="info",
+    )
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,67 @@
+#!/bin/bash
+
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+set -e
+
+# installs the project's dependencies; this runs when the API starts, or when the API's image is built if
+# prebuild_dependencies is set (see dependencies.go in the operator)
+
+cd /mnt/project
+
+# execute script if present in project's directory
+if [ -f "/mnt/project/dependencies.sh" ]; then
+    bash -e /mnt/project/dependencies.sh
+fi
+
+# the conda environment file, if present in the project's directory
+conda_env_file=""
+for file_name in environment.yml environment.yaml; do
+    if [ -f "/mnt/project/$file_name" ]; then
+        conda_env_file="/mnt/project/$file_name"
+        break
+    fi
+done
+
+# install conda packages
+if [ -n "$conda_env_file" ] || [ -f "/mnt/project/conda-packages.txt" ]; then
+    py_version_cmd='echo $(python -c "import sys; v=sys.version_info[:2]; print(\"{}.{}\".format(*v));")'
+    old_py_version=$(eval $py_version_cmd)
+
+    # the environment is applied to the predictor's environment (its name and prefix are ignored), so that the
+    # predictor's interpreter (/opt/conda/envs/env/bin/python) runs with the specified packages
+    if [ -n "$conda_env_file" ]; then
+        grep -v -E "^(name|prefix):" "$conda_env_file" > /tmp/environment.yml
+        conda env update -n env --file /tmp/environment.yml
+        rm /tmp/environment.yml
+    fi
+    if [ -f "/mnt/project/conda-packages.txt" ]; then
+        conda install -y --file /mnt/project/conda-packages.txt
+    fi
+    new_py_version=$(eval $py_version_cmd)
+
+    # reinstall core packages if Python version has changed
+    if [ $old_py_version != $new_py_version ]; then
+        echo "warning: you have changed the Python version from $old_py_version to $new_py_version; this may break Cortex's web server"
+        echo "reinstalling core packages ..."
+        pip --no-cache-dir install -r /src/cortex/serve/requirements.txt
+        rm -rf $CONDA_PREFIX/lib/python${old_py_version}  # previous python is no longer needed
+    fi
+fi
+
+# install pip packages
+if [ -f "/mnt/project/requirements.txt" ]; then
+    pip --no-cache-dir install -r /mnt/project/requirements.txt
+fi
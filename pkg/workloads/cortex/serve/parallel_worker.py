@@ -0,0 +1,123 @@
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import sys
+import os
+import time
+import math
+import socket
+import signal
+import threading
+
+from cortex import consts
+from cortex.lib.type import API, get_spec, call_on_shutdown
+from cortex.lib.log import cx_logger
+from cortex.lib.storage import cluster_storage
+
+
+API_LIVENESS_UPDATE_PERIOD = 5  # seconds
+LEADER_CHECK_PERIOD = 5  # seconds
+SHUTDOWN_TIMEOUT = float(os.getenv("CORTEX_SHUTDOWN_TIMEOUT", "10"))  # seconds
+
+
+def update_api_liveness():
+    threading.Timer(API_LIVENESS_UPDATE_PERIOD, update_api_liveness).start()
+    with open("/mnt/workspace/api_liveness.txt", "w") as f:
+        f.write(str(math.ceil(time.time())))
+
+
+# the ranks of the group can't recover from losing the leader, so the worker exits (and is
+# restarted, which makes it rejoin its group) once the leader has been replaced
+def watch_leader(leader_addr, leader_ip):
+    while True:
+        time.sleep(LEADER_CHECK_PERIOD)
+        try:
+            ip = socket.gethostbyname(leader_addr)
+        except socket.gaierror:
+            ip = None
+        if ip != leader_ip:
+            group = os.environ["CORTEX_PARALLEL_GROUP"]
+            cx_logger().info(f"the leader of group {group} was replaced")
+            os._exit(1)
+
+
+# the workers of a multi-node API load the predictor (so that its __init__() joins the group's
+# process group with the worker's rank), and then run its run_worker() method, if it has one
+# (which takes part in the predictions which the leader makes, e.g. by waiting for the inputs
+# which the leader broadcasts); the workers don't serve requests
+def main():
+    if os.environ["CORTEX_VERSION"] != consts.CORTEX_VERSION:
+        errMsg = f"your Cortex operator version ({os.environ['CORTEX_VERSION']}) doesn't match your predictor image version ({consts.CORTEX_VERSION}); please update your predictor image by modifying the `image` field in your API configuration file (e.g. cortex.yaml) and re-running `cortex deploy`, or update your cluster by following the instructions at https://docs.cortex.dev/cluster-management/update"
+        raise ValueError(errMsg)
+
+    cache_dir = os.environ["CORTEX_CACHE_DIR"]
+    provider = os.environ["CORTEX_PROVIDER"]
+    spec_path = os.environ["CORTEX_API_SPEC"]
+    project_dir = os.environ["CORTEX_PROJECT_DIR"]
+    model_dir = os.getenv("CORTEX_MODEL_DIR")
+    leader_addr = os.environ["MASTER_ADDR"]
+
+    storage = cluster_storage(provider, cache_dir)
+
+    try:
+        leader_ip = socket.gethostbyname(leader_addr)
+        threading.Thread(
+            target=watch_leader, args=(leader_addr, leader_ip), daemon=True
+        ).start()
+
+        raw_api_spec = get_spec(provider, storage, cache_dir, spec_path)
+        api = API(
+            provider=provider,
+            storage=storage,
+            model_dir=model_dir,
+            cache_dir=cache_dir,
+            **raw_api_spec,
+        )
+        client = api.predictor.initialize_client()
+        cx_logger().info(
+            "loading the predictor from {} (node {} of group {})".format(
+                api.predictor.path, os.environ["NODE_RANK"], os.environ["CORTEX_PARALLEL_GROUP"]
+            )
+        )
+        predictor_impl = api.predictor.initialize_impl(
+            project_dir, client, metrics_client=api.metrics_client()
+        )
+    except:
+        cx_logger().exception("failed to start the worker")
+        sys.exit(1)
+
+    def shutdown(signum, frame):
+        call_on_shutdown(predictor_impl, SHUTDOWN_TIMEOUT)
+        sys.exit(0)
+
+    signal.signal(signal.SIGTERM, shutdown)
+
+    open("/mnt/workspace/api_readiness.txt", "a").close()
+    update_api_liveness()
+
+    if hasattr(predictor_impl, "run_worker"):
+        try:
+            predictor_impl.run_worker()
+        except:
+            cx_logger().exception("the worker failed")
+            os._exit(1)
+        # the leader has stopped, so the worker is restarted to rejoin its group once it's replaced
+        os._exit(0)
+
+    while True:
+        time.sleep(60)
+
+
+if __name__ == "__main__":
+    main()
@@ -35,34 +35,46 @@ if [ "$CORTEX_PROVIDER" != "local" ]; then
     sysctl -w net.ipv4.tcp_fin_timeout=30 >/dev/null
 fi
 
-# execute script if present in project's directory
-if [ -f "/mnt/project/dependencies.sh" ]; then
-    bash -e /mnt/project/dependencies.sh
+# install the project's dependencies (unless they were installed when the API's image was built)
+if [ ! -f "/src/cortex/dependencies_installed.txt" ]; then
+    bash -e /src/cortex/serve/install_dependencies.sh
 fi
 
-# install from conda-packages.txt
-if [ -f "/mnt/project/conda-packages.txt" ]; then
-    py_version_cmd='echo $(python -c "import sys; v=sys.version_info[:2]; print(\"{}.{}\".format(*v));")'
-    old_py_version=$(eval $py_version_cmd)
-
-    conda install -y --file /mnt/project/conda-packages.txt
-    new_py_version=$(eval $py_version_cmd)
+# Ensure predictor print() statements are always flushed
+export PYTHONUNBUFFERED=TRUE
 
-    # reinstall core packages if Python version has changed
-    if [ $old_py_version != $new_py_version ]; then
-        echo "warning: you have changed the Python version from $old_py_version to $new_py_version; this may break Cortex's web server"
-        echo "reinstalling core packages ..."
-        pip --no-cache-dir install -r /src/cortex/serve/requirements.txt
-        rm -rf $CONDA_PREFIX/lib/python${old_py_version}  # previous python is no longer needed
+# the replicas of multi-node APIs are groups of CORTEX_PARALLEL_NODES pods: a leader (which serves the API, with node rank 0)
+# and its workers (the pods of the API's stateful set, whose ordinals determine their group and node rank)
+if [ -n "$CORTEX_PARALLEL_NODES" ]; then
+    if [ "$CORTEX_PARALLEL_ROLE" = "leader" ]; then
+        # the operator assigns each leader to a group once it has been scheduled (the labels file is updated shortly after)
+        until group="$(grep '^parallelGroup=' $CORTEX_PARALLEL_LABELS_FILE | cut -d '"' -f 2)" && [ -n "$group" ]; do
+            sleep 1
+        done
+        export NODE_RANK=0
+    else
+        ordinal="${HOSTNAME##*-}"
+        group=$((ordinal / (CORTEX_PARALLEL_NODES - 1)))
+        export NODE_RANK=$((ordinal % (CORTEX_PARALLEL_NODES - 1) + 1))
     fi
-fi
+    export CORTEX_PARALLEL_GROUP=$group
+    export MASTER_ADDR="${CORTEX_PARALLEL_LEADER_SERVICE_PREFIX}${group}.${CORTEX_PARALLEL_NAMESPACE}"
 
-# install pip packages
-if [ -f "/mnt/project/requirements.txt" ]; then
-    pip --no-cache-dir install -r /mnt/project/requirements.txt
+    # the leader's address resolves once the leader has been assigned to the group
+    until getent hosts "$MASTER_ADDR" >/dev/null; do
+        sleep 1
+    done
 fi
 
-# Ensure predictor print() statements are always flushed
-export PYTHONUNBUFFERED=TRUE
-
-/opt/conda/envs/env/bin/python /src/cortex/serve/start_uvicorn.py
+# batch job workers run the predictor on their share of the job's items, and then exit
+if [ -n "$CORTEX_BATCH_JOB_ID" ]; then
+    /opt/conda/envs/env/bin/python /src/cortex/serve/batch.py
+# the workers of multi-node APIs load the predictor, but don't serve requests
+elif [ "$CORTEX_PARALLEL_ROLE" = "worker" ]; then
+    /opt/conda/envs/env/bin/python /src/cortex/serve/parallel_worker.py
+# stream APIs consume records from a kafka topic, kinesis stream, or sqs queue instead of serving requests
+elif [ -n "$CORTEX_STREAM_SOURCE" ]; then
+    /opt/conda/envs/env/bin/python /src/cortex/serve/stream.py
+else
+    /opt/conda/envs/env/bin/python /src/cortex/serve/start_uvicorn.py
+fi
@@ -0,0 +1,33 @@
+#!/bin/bash
+
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+set -e
+
+mkdir -p /mnt/workspace
+
+cd /mnt/project
+
+export PYTHONPATH=$PYTHONPATH:$PYTHON_PATH
+
+# install the project's dependencies (unless they were installed when the API's image was built)
+if [ ! -f "/src/cortex/dependencies_installed.txt" ]; then
+    bash -e /src/cortex/serve/install_dependencies.sh
+fi
+
+# Ensure explainer print() statements are always flushed
+export PYTHONUNBUFFERED=TRUE
+
+/opt/conda/envs/env/bin/python /src/cortex/serve/explainer.py
@@ -24,20 +24,23 @@ import math
 import asyncio
 from typing import Any
 
+import requests
 from fastapi import Body, FastAPI
 from fastapi.exceptions import RequestValidationError
 from fastapi.middleware.cors import CORSMiddleware
 from starlette.requests import Request
-from starlette.responses import Response
+from starlette.responses import Response, StreamingResponse
 from starlette.background import BackgroundTasks
 from starlette.exceptions import HTTPException as StarletteHTTPException
 
 from cortex import consts
 from cortex.lib import util
-from cortex.lib.type import API, get_spec
+from cortex.lib.batching import DynamicBatcher
+from cortex.lib.payload_logging import PayloadLogger
+from cortex.lib.type import API, get_spec, pop_used_models, call_on_shutdown
 from cortex.lib.log import cx_logger
-from cortex.lib.storage import S3, LocalStorage, FileLock
-from cortex.lib.exceptions import UserRuntimeException
+from cortex.lib.storage import FileLock, cluster_storage
+from cortex.lib.exceptions import UserException, UserRuntimeException
 
 if os.environ["CORTEX_VERSION"] != consts.CORTEX_VERSION:
     errMsg = f"your Cortex operator version ({os.environ['CORTEX_VERSION']}) doesn't match your predictor image version ({consts.CORTEX_VERSION}); please update your predictor image by modifying the `image` field in your API configuration file (e.g. cortex.yaml) and re-running `cortex deploy`, or update your cluster by following the instructions at https://docs.cortex.dev/cluster-management/update"
@@ -50,6 +53,10 @@ API_SUMMARY_MESSAGE = (
 
 API_LIVENESS_UPDATE_PERIOD = 5  # seconds
 
+SHUTDOWN_TIMEOUT = float(os.getenv("CORTEX_SHUTDOWN_TIMEOUT", "10"))  # seconds
+
+EXPLAINER_TIMEOUT = float(os.getenv("CORTEX_EXPLAINER_TIMEOUT", "30"))  # seconds
+
 
 loop = asyncio.get_event_loop()
 loop.set_default_executor(
@@ -66,13 +73,25 @@ app.add_middleware(
     allow_headers=["*"],
 )
 
+# per-worker limit on in-flight prediction requests when shedding load (None when requests are queued)
+max_worker_in_flight = None
+if os.getenv("CORTEX_OVERLOAD_BEHAVIOR") == "shed":
+    max_worker_in_flight = math.ceil(
+        int(os.environ["CORTEX_MAX_REPLICA_CONCURRENCY"])
+        / int(os.environ["CORTEX_WORKERS_PER_REPLICA"])
+    )
+
 local_cache = {
     "api": None,
     "provider": None,
     "predictor_impl": None,
     "predict_route": None,
     "client": None,
+    "batcher": None,
+    "payload_logger": None,
+    "explainer_url": None,
     "class_set": set(),
+    "in_flight": 0,
 }
 
 
@@ -90,6 +109,12 @@ def startup():
 
 @app.on_event("shutdown")
 def shutdown():
+    if local_cache["predictor_impl"] is not None:
+        call_on_shutdown(local_cache["predictor_impl"], SHUTDOWN_TIMEOUT)
+
+    if local_cache["payload_logger"] is not None:
+        local_cache["payload_logger"].flush()
+
     try:
         os.remove("/mnt/workspace/api_readiness.txt")
     except:
@@ -138,28 +163,82 @@ async def register_request(request: Request, call_next):
 
         response = await call_next(request)
     finally:
-        if file_id is not None:
-            try:
-                os.remove(file_id)
-            except:
-                pass
+        # a streamed response is still in flight once its headers are sent, so it is recorded
+        # when its body ends
+        if response is None or not getattr(request.state, "streaming", False):
+            complete_request(request, file_id, response)
 
-        if is_prediction_request(request):
-            status_code = 500
-            if response is not None:
-                status_code = response.status_code
-            api = local_cache["api"]
-            api.post_request_metrics(status_code, time.time() - request.state.start_time)
+    if getattr(request.state, "streaming", False):
+        response.body_iterator = monitor_stream(request, file_id, response)
 
     return response
 
 
+def complete_request(request, file_id, response):
+    if file_id is not None:
+        try:
+            os.remove(file_id)
+        except:
+            pass
+
+    if is_prediction_request(request):
+        status_code = 500
+        if response is not None:
+            status_code = response.status_code
+        api = local_cache["api"]
+        api.post_request_metrics(
+            status_code,
+            time.time() - request.state.start_time,
+            getattr(request.state, "model_names", None),
+        )
+
+
+async def monitor_stream(request, file_id, response):
+    # each chunk yielded by the predictor is counted as a token
+    first_token_time = None
+    num_tokens = 0
+    try:
+        async for chunk in response.body_iterator:
+            if chunk:
+                if first_token_time is None:
+                    first_token_time = time.time()
+                num_tokens += 1
+            yield chunk
+    finally:
+        end_time = time.time()
+        complete_request(request, file_id, response)
+
+        if first_token_time is not None and local_cache["provider"] != "local":
+            tokens_per_second = None
+            if num_tokens > 1 and end_time > first_token_time:
+                tokens_per_second = (num_tokens - 1) / (end_time - first_token_time)
+            local_cache["api"].post_stream_metrics(
+                first_token_time - request.state.start_time, tokens_per_second
+            )
+
+
+async def release_on_stream_end(body_iterator, release):
+    try:
+        async for chunk in body_iterator:
+            yield chunk
+    finally:
+        release()
+
+
 @app.middleware("http")
 async def parse_payload(request: Request, call_next):
     if not is_prediction_request(request):
         return await call_next(request)
 
-    if "payload" not in local_cache["predict_fn_args"]:
+    if local_cache["payload_logger"] is not None:
+        request.state.body = await request.body()
+
+    # the explainer sidecar is sent the payload along with the prediction
+    if (
+        "payload" not in local_cache["predict_fn_args"]
+        and not local_cache["has_reward_fn"]
+        and local_cache["explainer_url"] is None
+    ):
         return await call_next(request)
 
     content_type = request.headers.get("content-type", "").lower()
@@ -176,14 +255,54 @@ async def parse_payload(request: Request, call_next):
     return await call_next(request)
 
 
+# registered last so that it runs first (before the payload is parsed)
+@app.middleware("http")
+async def shed_load(request: Request, call_next):
+    request_start_time = time.time()
+
+    if max_worker_in_flight is None or not is_prediction_request(request):
+        return await call_next(request)
+
+    # the event loop is single-threaded, so the counter doesn't need a lock
+    if local_cache["in_flight"] >= max_worker_in_flight:
+        local_cache["api"].post_request_metrics(429, time.time() - request_start_time, None)
+        return Response(content="too many requests", status_code=429)
+
+    def release():
+        local_cache["in_flight"] -= 1
+
+    local_cache["in_flight"] += 1
+    try:
+        response = await call_next(request)
+    except:
+        release()
+        raise
+
+    if getattr(request.state, "streaming", False):
+        response.body_iterator = release_on_stream_end(response.body_iterator, release)
+    else:
+        release()
+    return response
+
+
 def predict(request: Request):
     api = local_cache["api"]
     predictor_impl = local_cache["predictor_impl"]
     args = build_predict_args(request)
 
-    prediction = predictor_impl.predict(**args)
+    pop_used_models()  # discard any models left over from a request which was interrupted
+    try:
+        if local_cache["batcher"] is not None:
+            prediction = local_cache["batcher"].predict(args["payload"])
+        else:
+            prediction = predictor_impl.predict(**args)
+    finally:
+        # read by the request metrics middleware and the access log formatter
+        request.state.model_names = pop_used_models()
 
-    if isinstance(prediction, bytes):
+    if inspect.isgenerator(prediction):
+        response = StreamingResponse(content=prediction, media_type="text/plain")
+    elif isinstance(prediction, bytes):
         response = Response(content=prediction, media_type="application/octet-stream")
     elif isinstance(prediction, str):
         response = Response(content=prediction, media_type="text/plain")
@@ -197,9 +316,33 @@ def predict(request: Request):
                 str(e),
                 "please return an object that is JSON serializable (including its nested fields), a bytes object, a string, or a starlette.response.Response object",
             ) from e
+        # explanations are requested with ?explain=true
+        explain_requested = request.query_params.get("explain") == "true"
+        if explain_requested and local_cache["explainer_url"] is not None:
+            json_string = json.dumps(
+                {
+                    "prediction": prediction,
+                    "explanation": explain(request.state.payload, prediction),
+                }
+            )
         response = Response(content=json_string, media_type="application/json")
 
-    if local_cache["provider"] != "local" and api.monitoring is not None:
+    # streamed responses are passed through the middlewares chunk by chunk, and no-transform
+    # prevents envoy's gzip filter from buffering them
+    if isinstance(response, StreamingResponse):
+        request.state.streaming = True
+        response.headers["cache-control"] = "no-transform"
+
+    # so that clients can pin subsequent requests to the version which served this one
+    if os.getenv("CORTEX_VERSION_PINNING") == "true":
+        response.headers["x-cortex-api-id"] = api.id
+
+    # prediction monitoring and rewards need the entire prediction, so they don't apply to streams
+    if (
+        local_cache["provider"] != "local"
+        and api.monitoring is not None
+        and not isinstance(response, StreamingResponse)
+    ):
         try:
             predicted_value = api.monitoring.extract_predicted_value(prediction)
             api.post_monitoring_metrics(predicted_value)
@@ -214,9 +357,66 @@ def predict(request: Request):
         except:
             cx_logger().warn("unable to record prediction metric", exc_info=True)
 
+    # replayed requests aren't captured, so that replays don't feed back into the payload log;
+    # streaming responses don't have a body to capture
+    payload_logger = local_cache["payload_logger"]
+    if (
+        payload_logger is not None
+        and "x-cortex-replay" not in request.headers
+        and hasattr(response, "body")
+    ):
+        try:
+            payload_logger.log(
+                request_id=request.headers.get("x-request-id"),
+                content_type=request.headers.get("content-type"),
+                body=request.state.body,
+                status_code=response.status_code,
+                response_content_type=response.headers.get("content-type"),
+                response=response.body,
+            )
+        except:
+            cx_logger().warn("unable to capture payload", exc_info=True)
+
+    if (
+        local_cache["provider"] != "local"
+        and local_cache["has_reward_fn"]
+        and not isinstance(response, StreamingResponse)
+    ):
+        try:
+            reward = predictor_impl.reward(payload=request.state.payload, prediction=prediction)
+            if reward is not None:
+                api.post_reward_metrics(float(reward))
+        except:
+            cx_logger().warn("unable to record reward metric", exc_info=True)
+
     return response
 
 
+# returns the explainer sidecar's explanation of the prediction
+def explain(payload, prediction):
+    try:
+        body = json.dumps({"payload": payload, "prediction": prediction})
+    except TypeError:
+        raise StarletteHTTPException(400, "explanations are only supported for json payloads")
+
+    try:
+        response = requests.post(
+            local_cache["explainer_url"],
+            data=body,
+            headers={"content-type": "application/json"},
+            timeout=EXPLAINER_TIMEOUT,
+        )
+    except requests.exceptions.Timeout:
+        raise StarletteHTTPException(504, "the explanation timed out")
+    except requests.exceptions.ConnectionError:
+        raise StarletteHTTPException(503, "the explainer is unavailable")
+
+    if response.status_code != 200:
+        raise StarletteHTTPException(500, "failed to explain the prediction: " + response.text)
+
+    return response.json()
+
+
 def build_predict_args(request: Request):
     args = {}
 
@@ -249,10 +449,7 @@ def start():
     tf_serving_port = os.getenv("CORTEX_TF_BASE_SERVING_PORT", "9000")
     tf_serving_host = os.getenv("CORTEX_TF_SERVING_HOST", "localhost")
 
-    if provider == "local":
-        storage = LocalStorage(os.getenv("CORTEX_CACHE_DIR"))
-    else:
-        storage = S3(bucket=os.environ["CORTEX_BUCKET"], region=os.environ["AWS_REGION"])
+    storage = cluster_storage(provider, cache_dir)
 
     has_multiple_servers = os.getenv("CORTEX_MULTIPLE_TF_SERVERS")
     if has_multiple_servers:
@@ -281,13 +478,36 @@ def start():
             tf_serving_host=tf_serving_host, tf_serving_port=tf_serving_port,
         )
         cx_logger().info("loading the predictor from {}".format(api.predictor.path))
-        predictor_impl = api.predictor.initialize_impl(project_dir, client)
+        predictor_impl = api.predictor.initialize_impl(
+            project_dir, client, metrics_client=api.metrics_client()
+        )
 
         local_cache["api"] = api
         local_cache["provider"] = provider
         local_cache["client"] = client
         local_cache["predictor_impl"] = predictor_impl
         local_cache["predict_fn_args"] = inspect.getfullargspec(predictor_impl.predict).args
+        local_cache["has_reward_fn"] = callable(getattr(predictor_impl, "reward", None))
+        local_cache["explainer_url"] = os.getenv("CORTEX_EXPLAINER_URL")
+
+        if os.getenv("CORTEX_PAYLOAD_LOG_ROOT"):
+            local_cache["payload_logger"] = PayloadLogger(
+                storage,
+                root=os.environ["CORTEX_PAYLOAD_LOG_ROOT"],
+                sample_rate=float(os.environ["CORTEX_PAYLOAD_LOG_SAMPLE_RATE"]),
+            )
+
+        # TensorFlow Serving batches requests itself, so only the Python predictor is batched here
+        if os.getenv("CORTEX_MAX_BATCH_SIZE") and api.predictor.type == "python":
+            if local_cache["predict_fn_args"] != ["self", "payload"]:
+                raise UserException(
+                    "predict() must only accept a payload argument when batching is enabled"
+                )
+            local_cache["batcher"] = DynamicBatcher(
+                predictor_impl,
+                max_batch_size=int(os.environ["CORTEX_MAX_BATCH_SIZE"]),
+                batch_interval=float(os.environ["CORTEX_BATCH_INTERVAL"]),
+            )
         predict_route = "/"
         if provider != "local":
             predict_route = "/predict"
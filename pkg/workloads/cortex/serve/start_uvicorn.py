@@ -18,8 +18,9 @@ import os
 import json
 
 from cortex.lib.type import get_spec
-from cortex.lib.storage import S3, LocalStorage
+from cortex.lib.storage import cluster_storage
 from cortex.lib.checkers.pod import wait_neuron_rtd
+from cortex.lib.request_monitor import start_request_monitor
 
 
 def load_tensorflow_serving_models():
@@ -43,7 +44,10 @@ def load_tensorflow_serving_models():
     # initialize models for each TF worker
     base_paths = [os.path.join(model_dir, name) for name in models]
     for w in range(int(num_workers)):
-        tfs = TensorFlowServing(f"{tf_serving_host}:{tf_base_serving_port+w}")
+        tfs = TensorFlowServing(
+            f"{tf_serving_host}:{tf_base_serving_port+w}",
+            model_config=os.getenv("CORTEX_TF_MODEL_CONFIG"),
+        )
         tfs.add_models_config(models, base_paths, replace_models=False)
 
 
@@ -71,23 +75,28 @@ def main():
     cache_dir = os.environ["CORTEX_CACHE_DIR"]
     provider = os.environ["CORTEX_PROVIDER"]
     spec_path = os.environ["CORTEX_API_SPEC"]
-    if provider == "local":
-        storage = LocalStorage(os.getenv("CORTEX_CACHE_DIR"))
-    else:
-        storage = S3(bucket=os.environ["CORTEX_BUCKET"], region=os.environ["AWS_REGION"])
+    storage = cluster_storage(provider, cache_dir)
     raw_api_spec = get_spec(provider, storage, cache_dir, spec_path)
 
+    # the uvicorn workers share the replica's in-flight requests, so they're reported by this process
+    start_request_monitor(raw_api_spec["name"])
+
     # load tensorflow models into TFS
     if raw_api_spec["predictor"]["type"] == "tensorflow":
         load_tensorflow_serving_models()
 
+    # when shedding load, the serving app rejects excess requests with 429 before uvicorn's limit is reached
+    limit_concurrency = int(os.environ["CORTEX_MAX_WORKER_CONCURRENCY"])
+    if os.getenv("CORTEX_OVERLOAD_BEHAVIOR") == "shed":
+        limit_concurrency = int(os.environ["CORTEX_SO_MAX_CONN"])
+
     # https://github.com/encode/uvicorn/blob/master/uvicorn/config.py
     uvicorn.run(
         "cortex.serve.wsgi:app",
         host="0.0.0.0",
         port=int(os.environ["CORTEX_SERVING_PORT"]),
         workers=int(os.environ["CORTEX_WORKERS_PER_REPLICA"]),
-        limit_concurrency=int(os.environ["CORTEX_MAX_WORKER_CONCURRENCY"]),
+        limit_concurrency=limit_concurrency,
         backlog=int(os.environ["CORTEX_SO_MAX_CONN"]),
         log_config=log_config,
         log_level="info",
@@ -0,0 +1,938 @@
+# Copyright 2020 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import sys
+import os
+import inspect
+import time
+import json
+import math
+import hmac
+import hashlib
+import base64
+import signal
+import threading
+import traceback
+import uuid
+from collections import namedtuple, Counter
+from concurrent.futures import ThreadPoolExecutor, wait
+from urllib.parse import unquote_plus
+
+import boto3
+import requests
+
+from cortex import consts
+from cortex.lib.type import API, get_spec, pop_used_models, call_on_shutdown
+from cortex.lib.log import cx_logger
+from cortex.lib.storage import S3, cluster_storage
+from cortex.lib.exceptions import UserRuntimeException
+from cortex.lib.checkers.pod import wait_neuron_rtd
+from cortex.lib.request_monitor import start_request_monitor
+from cortex.serve.start_uvicorn import load_tensorflow_serving_models
+
+
+API_LIVENESS_UPDATE_PERIOD = 5  # seconds
+KINESIS_POLL_PERIOD = 1  # seconds (each shard supports up to 5 reads per second)
+KINESIS_LEASE_DURATION = 30  # seconds (a replica's shards are taken by other replicas if it doesn't renew its leases)
+KINESIS_LEASE_REFRESH_PERIOD = 10  # seconds (how often leases are renewed, and shards are balanced between replicas)
+KINESIS_SHARD_END = "SHARD_END"  # the checkpoint of a shard which was read to its end
+SQS_WAIT_TIME = 20  # seconds (the maximum long polling duration)
+CALLBACK_TIMEOUT = 10  # seconds
+CALLBACK_BACKOFF = 1  # seconds (doubled after each failed attempt)
+CALLBACK_CONCURRENCY = 10
+CALLBACK_URL_ATTRIBUTE = "cortex-callback-url"  # kafka header or sqs message attribute
+SHUTDOWN_TIMEOUT = float(os.getenv("CORTEX_SHUTDOWN_TIMEOUT", "10"))  # seconds
+
+# handle identifies the record to the stream when it's committed (the receipt handle for sqs, or the shard id for kinesis);
+# id uniquely identifies the record in callbacks, and callback_url overrides the API's callback url
+Record = namedtuple("Record", ["key", "value", "handle", "id", "callback_url"])
+
+
+class RecordTimeoutError(Exception):
+    pass
+
+
+class KafkaStream:
+    def __init__(self, brokers, input, output, consumer_group, batch_size):
+        from kafka import KafkaConsumer, KafkaProducer
+
+        self.batch_size = batch_size
+        self.output = output
+        self.consumer = KafkaConsumer(
+            input,
+            bootstrap_servers=brokers,
+            group_id=consumer_group,
+            enable_auto_commit=False,
+            max_poll_records=batch_size,
+        )
+        self.producer = None
+        if output is not None:
+            self.producer = KafkaProducer(bootstrap_servers=brokers)
+
+    def poll(self):
+        records = []
+        for partition_records in self.consumer.poll(timeout_ms=1000).values():
+            records += [
+                Record(
+                    record.key,
+                    record.value,
+                    None,
+                    f"{record.topic}/{record.partition}/{record.offset}",
+                    kafka_callback_url(record),
+                )
+                for record in partition_records
+            ]
+        return records
+
+    def write(self, results):
+        for key, value in results:
+            self.producer.send(self.output, key=key, value=value)
+        self.producer.flush()
+
+    def commit(self, processed_records):
+        # offsets are committed after the results are written (records are processed at least once)
+        self.consumer.commit()
+
+    def close(self):
+        # leaving the consumer group rebalances the partitions without waiting for the session to time out
+        self.consumer.close(autocommit=False)
+
+
+class KinesisShardLeases:
+    def __init__(self, table, consumer, owner):
+        """
+        Stores which replica reads each shard of a Kinesis stream, and the last record which was processed in each shard.
+
+        table - The DynamoDB table which the leases are stored in (its hash key is "consumer", and its range key is "shard_id").
+        consumer - Identifies the API and its input stream (an API's leases and checkpoints are not shared with other APIs).
+        owner - Identifies the replica.
+        """
+        self.table = boto3.resource("dynamodb", region_name=os.environ["AWS_REGION"]).Table(table)
+        self.consumer = consumer
+        self.owner = owner
+        self.conditional_check_failed = (
+            self.table.meta.client.exceptions.ConditionalCheckFailedException
+        )
+
+    # returns the consumer's leases, keyed by shard id
+    def list(self):
+        leases = {}
+        kwargs = {
+            "KeyConditionExpression": "#consumer = :consumer",
+            "ExpressionAttributeNames": {"#consumer": "consumer"},
+            "ExpressionAttributeValues": {":consumer": self.consumer},
+            "ConsistentRead": True,
+        }
+        while True:
+            response = self.table.query(**kwargs)
+            for lease in response["Items"]:
+                leases[lease["shard_id"]] = lease
+            if "LastEvaluatedKey" not in response:
+                return leases
+            kwargs["ExclusiveStartKey"] = response["LastEvaluatedKey"]
+
+    # takes the shard's lease, unless it was changed (e.g. taken by another replica) after it was read
+    # (lease is None if the shard didn't have a lease); returns the new lease, or None if it wasn't taken
+    def take(self, shard_id, lease):
+        values = {
+            ":owner": self.owner,
+            ":expires_at": int(time.time()) + KINESIS_LEASE_DURATION,
+            ":zero": 0,
+            ":one": 1,
+        }
+        if lease is None:
+            condition = "attribute_not_exists(shard_id)"
+        else:
+            condition = "lease_counter = :lease_counter"
+            values[":lease_counter"] = lease["lease_counter"]
+
+        try:
+            return self.table.update_item(
+                Key={"consumer": self.consumer, "shard_id": shard_id},
+                UpdateExpression="SET #owner = :owner, expires_at = :expires_at, lease_counter = if_not_exists(lease_counter, :zero) + :one",
+                ConditionExpression=condition,
+                ExpressionAttributeNames={"#owner": "owner"},
+                ExpressionAttributeValues=values,
+                ReturnValues="ALL_NEW",
+            )["Attributes"]
+        except self.conditional_check_failed:
+            return None
+
+    # extends the lease, and updates its checkpoint (if it's not None); returns False if the lease was taken by another replica
+    def renew(self, shard_id, checkpoint=None):
+        update = "SET expires_at = :expires_at"
+        names = {"#owner": "owner"}
+        values = {
+            ":owner": self.owner,
+            ":expires_at": int(time.time()) + KINESIS_LEASE_DURATION,
+        }
+        if checkpoint is not None:
+            update += ", #checkpoint = :checkpoint"
+            names["#checkpoint"] = "checkpoint"
+            values[":checkpoint"] = checkpoint
+
+        try:
+            self.table.update_item(
+                Key={"consumer": self.consumer, "shard_id": shard_id},
+                UpdateExpression=update,
+                ConditionExpression="#owner = :owner",
+                ExpressionAttributeNames=names,
+                ExpressionAttributeValues=values,
+            )
+            return True
+        except self.conditional_check_failed:
+            return False
+
+    # expires the lease so that another replica can take it without waiting for it to expire
+    def release(self, shard_id):
+        try:
+            self.table.update_item(
+                Key={"consumer": self.consumer, "shard_id": shard_id},
+                UpdateExpression="SET expires_at = :zero",
+                ConditionExpression="#owner = :owner",
+                ExpressionAttributeNames={"#owner": "owner"},
+                ExpressionAttributeValues={":owner": self.owner, ":zero": 0},
+            )
+        except self.conditional_check_failed:
+            pass
+
+
+class KinesisStream:
+    def __init__(self, input, output, batch_size, lease_table, consumer):
+        """
+        Reads the shards of a Kinesis stream which the replica holds leases on. Each replica takes an equal share
+        of the shards (leases which aren't renewed are taken by the other replicas), and checkpoints the last record
+        of each batch, so that a shard is read from its checkpoint when it moves to another replica or the API is
+        restarted. A shard which was created by resharding is only read once its parents have been read to their end.
+
+        input - The name of the stream which records are read from.
+        output - The name of the stream which predictions are written to (may be None).
+        batch_size - The maximum number of records which are read from each shard per poll.
+        lease_table - The DynamoDB table which the shards' leases are stored in.
+        consumer - Identifies the API and its input stream in the lease table.
+        """
+        self.client = boto3.client("kinesis", region_name=os.environ["AWS_REGION"])
+        self.input = input
+        self.output = output
+        self.batch_size = batch_size
+        self.leases = KinesisShardLeases(lease_table, consumer, owner=os.environ["HOSTNAME"])
+        self.last_refresh = 0
+
+        # the state of the shards which the replica holds leases on, keyed by shard id
+        self.shard_iterators = {}
+        self.positions = {}  # where each shard's iterator starts (i.e. after its last checkpoint)
+        self.pending_checkpoints = {}  # the sequence number of the last record which was polled
+        self.closed_shards = set()  # shards which were read to their end (e.g. after resharding)
+
+    def poll(self):
+        # the records of closed shards were committed before the next poll
+        for shard_id in list(self.closed_shards):
+            if self.leases.renew(shard_id, KINESIS_SHARD_END):
+                cx_logger().info(f"finished reading shard {shard_id}")
+            self.drop(shard_id)
+
+        if time.time() - self.last_refresh >= KINESIS_LEASE_REFRESH_PERIOD:
+            self.refresh_leases()
+
+        records = []
+        for shard_id, shard_iterator in list(self.shard_iterators.items()):
+            try:
+                response = self.client.get_records(
+                    ShardIterator=shard_iterator, Limit=self.batch_size
+                )
+            except self.client.exceptions.ExpiredIteratorException:
+                self.shard_iterators[shard_id] = self.get_shard_iterator(shard_id)
+                continue
+            except self.client.exceptions.ProvisionedThroughputExceededException:
+                continue
+
+            if response.get("NextShardIterator") is None:
+                self.closed_shards.add(shard_id)
+            else:
+                self.shard_iterators[shard_id] = response["NextShardIterator"]
+            if len(response["Records"]) > 0:
+                self.pending_checkpoints[shard_id] = response["Records"][-1]["SequenceNumber"]
+            records += [
+                Record(
+                    record["PartitionKey"],
+                    record["Data"],
+                    shard_id,
+                    record["SequenceNumber"],
+                    None,
+                )
+                for record in response["Records"]
+            ]
+
+        if len(records) == 0:
+            time.sleep(KINESIS_POLL_PERIOD)
+        return records
+
+    def write(self, results):
+        entries = [{"PartitionKey": key, "Data": value} for key, value in results]
+        for i in range(0, len(entries), 500):  # put_records accepts up to 500 records per call
+            self.client.put_records(StreamName=self.output, Records=entries[i : i + 500])
+
+    def commit(self, processed_records):
+        # like kafka offsets, the last record of each shard is checkpointed after the results are written
+        # (records are processed at least once, and records which failed are not retried)
+        for shard_id, sequence_number in self.pending_checkpoints.items():
+            if shard_id not in self.shard_iterators:
+                continue
+            if not self.leases.renew(shard_id, sequence_number):
+                cx_logger().info(f"shard {shard_id} was taken by another replica")
+                self.drop(shard_id)
+                continue
+            self.positions[shard_id] = {
+                "ShardIteratorType": "AFTER_SEQUENCE_NUMBER",
+                "StartingSequenceNumber": sequence_number,
+            }
+        self.pending_checkpoints = {}
+
+    def close(self):
+        for shard_id in list(self.shard_iterators):
+            self.leases.release(shard_id)
+
+    def refresh_leases(self):
+        self.last_refresh = time.time()
+
+        for shard_id in list(self.shard_iterators):
+            if shard_id not in self.closed_shards and not self.leases.renew(shard_id):
+                cx_logger().info(f"shard {shard_id} was taken by another replica")
+                self.drop(shard_id)
+
+        shards = self.list_shards()
+        leases = self.leases.list()
+        now = time.time()
+
+        finished = {
+            shard_id
+            for shard_id, lease in leases.items()
+            if lease.get("checkpoint") == KINESIS_SHARD_END
+        }
+        # a shard's parents are read before it, unless they have expired from the stream
+        available = {
+            shard["ShardId"]: shard
+            for shard in shards.values()
+            if shard["ShardId"] not in finished
+            and all(
+                parent_id not in shards or parent_id in finished
+                for parent_id in [shard.get("ParentShardId"), shard.get("AdjacentParentShardId")]
+                if parent_id is not None
+            )
+        }
+        owners = {
+            lease["owner"]
+            for shard_id, lease in leases.items()
+            if shard_id in available and lease["expires_at"] > now
+        }
+        owners.add(self.leases.owner)
+        target = math.ceil(len(available) / len(owners))
+
+        # shards without a lease (or whose lease expired) are taken first
+        for shard_id, shard in available.items():
+            if len(self.shard_iterators) >= target:
+                return
+            lease = leases.get(shard_id)
+            if shard_id in self.shard_iterators:
+                continue
+            if (
+                lease is not None
+                and lease["expires_at"] > now
+                and lease["owner"] != self.leases.owner
+            ):
+                continue
+            self.take(shard, lease, leases)
+
+        # then a shard is taken from the replica which holds the most, so the leases are balanced over time
+        if len(self.shard_iterators) >= target:
+            return
+        held = Counter(
+            lease["owner"]
+            for shard_id, lease in leases.items()
+            if shard_id in available
+            and lease["expires_at"] > now
+            and lease["owner"] != self.leases.owner
+        )
+        if len(held) == 0:
+            return
+        owner, count = held.most_common(1)[0]
+        if count <= target:
+            return
+        for shard_id, lease in leases.items():
+            if shard_id in available and lease["owner"] == owner and lease["expires_at"] > now:
+                self.take(available[shard_id], lease, leases)
+                return
+
+    def take(self, shard, lease, leases):
+        shard_id = shard["ShardId"]
+        lease = self.leases.take(shard_id, lease)
+        if lease is None:
+            return
+
+        if lease.get("checkpoint") is not None:
+            self.positions[shard_id] = {
+                "ShardIteratorType": "AFTER_SEQUENCE_NUMBER",
+                "StartingSequenceNumber": lease["checkpoint"],
+            }
+        elif shard.get("ParentShardId") in leases or shard.get("AdjacentParentShardId") in leases:
+            # the shard was created by resharding a shard which the API read, so it's read from its first record
+            self.positions[shard_id] = {"ShardIteratorType": "TRIM_HORIZON"}
+        else:
+            self.positions[shard_id] = {"ShardIteratorType": "LATEST"}
+
+        self.shard_iterators[shard_id] = self.get_shard_iterator(shard_id)
+        cx_logger().info(f"reading shard {shard_id}")
+
+    # shard iterators expire after 5 minutes, so they are recreated from the shard's last checkpoint
+    def get_shard_iterator(self, shard_id):
+        return self.client.get_shard_iterator(
+            StreamName=self.input, ShardId=shard_id, **self.positions[shard_id]
+        )["ShardIterator"]
+
+    def drop(self, shard_id):
+        self.shard_iterators.pop(shard_id, None)
+        self.positions.pop(shard_id, None)
+        self.pending_checkpoints.pop(shard_id, None)
+        self.closed_shards.discard(shard_id)
+
+    # returns the stream's shards (including closed shards which haven't expired), keyed by shard id
+    def list_shards(self):
+        shards = {}
+        kwargs = {"StreamName": self.input}
+        while True:
+            response = self.client.list_shards(**kwargs)
+            for shard in response["Shards"]:
+                shards[shard["ShardId"]] = shard
+            if response.get("NextToken") is None:
+                return shards
+            kwargs = {"NextToken": response["NextToken"]}
+
+
+class SQSStream:
+    def __init__(self, input, output, batch_size, s3_trigger=False):
+        self.client = boto3.client("sqs", region_name=os.environ["AWS_REGION"])
+        self.queue_url = self.client.get_queue_url(QueueName=input)["QueueUrl"]
+        self.output = output
+        self.output_url = None
+        if output is not None:
+            self.output_url = self.client.get_queue_url(QueueName=output)["QueueUrl"]
+        self.batch_size = batch_size
+        self.s3_trigger = s3_trigger
+        self.records_per_message = {}  # receipt handle -> number of records
+
+    def poll(self):
+        response = self.client.receive_message(
+            QueueUrl=self.queue_url,
+            MaxNumberOfMessages=self.batch_size,
+            WaitTimeSeconds=SQS_WAIT_TIME,
+            MessageAttributeNames=["All"],
+        )
+
+        records = []
+        for message in response.get("Messages", []):
+            callback_url = (
+                message.get("MessageAttributes", {})
+                .get(CALLBACK_URL_ATTRIBUTE, {})
+                .get("StringValue")
+            )
+            if self.s3_trigger:
+                message_records = self.s3_event_records(message, callback_url)
+            else:
+                message_records = [
+                    Record(
+                        message["MessageId"],
+                        message["Body"],
+                        message["ReceiptHandle"],
+                        message["MessageId"],
+                        callback_url,
+                    )
+                ]
+            if len(message_records) == 0:
+                # e.g. the test event which S3 sends when the notification is configured
+                self.client.delete_message(
+                    QueueUrl=self.queue_url, ReceiptHandle=message["ReceiptHandle"]
+                )
+                continue
+            self.records_per_message[message["ReceiptHandle"]] = len(message_records)
+            records += message_records
+
+        return records
+
+    # S3 event notifications can describe multiple objects, each of which is processed as a record
+    def s3_event_records(self, message, callback_url):
+        event = json.loads(message["Body"])
+        records = []
+        for i, event_record in enumerate(event.get("Records", [])):
+            payload = {
+                "bucket": event_record["s3"]["bucket"]["name"],
+                "key": unquote_plus(event_record["s3"]["object"]["key"]),
+                "size": event_record["s3"]["object"].get("size"),
+                "event_name": event_record["eventName"],
+                "event_time": event_record["eventTime"],
+            }
+            record_id = message["MessageId"]
+            if i > 0:
+                record_id = f"{message['MessageId']}/{i}"
+            records.append(
+                Record(
+                    payload["key"],
+                    json.dumps(payload),
+                    message["ReceiptHandle"],
+                    record_id,
+                    callback_url,
+                )
+            )
+        return records
+
+    def write(self, results):
+        entries = [
+            {"Id": str(i), "MessageBody": value.decode("utf-8")}
+            for i, (key, value) in enumerate(results)
+        ]
+        # send_message_batch accepts up to 10 messages per call
+        for i in range(0, len(entries), 10):
+            self.client.send_message_batch(QueueUrl=self.output_url, Entries=entries[i : i + 10])
+
+    def commit(self, processed_records):
+        # messages which failed (and weren't written to the dead letter prefix) are not deleted, so
+        # they are received again once their visibility timeout expires (and are moved to the dead
+        # letter queue after max_receive_count attempts); a message is only deleted if all of its
+        # records were processed
+        processed_per_message = Counter(record.handle for record in processed_records)
+        handles = [
+            handle
+            for handle, num_records in self.records_per_message.items()
+            if processed_per_message[handle] == num_records
+        ]
+        self.records_per_message = {}
+
+        entries = [{"Id": str(i), "ReceiptHandle": handle} for i, handle in enumerate(handles)]
+        # delete_message_batch accepts up to 10 messages per call
+        for i in range(0, len(entries), 10):
+            self.client.delete_message_batch(QueueUrl=self.queue_url, Entries=entries[i : i + 10])
+
+    def close(self):
+        pass  # messages which weren't deleted are received again once their visibility timeout expires
+
+
+def kafka_callback_url(record):
+    for key, value in record.headers or []:
+        if key == CALLBACK_URL_ATTRIBUTE:
+            return value.decode("utf-8")
+    return None
+
+
+class Callbacks:
+    def __init__(self, api_name, url, sns_topic, signing_key, max_retries):
+        """
+        Sends the result of each record to a URL and/or an SNS topic.
+
+        api_name - The name of the API, which is included in each callback.
+        url - The URL which results are posted to, unless the record specifies its own (may be None).
+        sns_topic - The ARN of the SNS topic which results are published to (may be None).
+        signing_key - The key which URL callbacks are signed with, using HMAC-SHA256 (may be None).
+        max_retries - The number of times a callback is retried after it fails.
+        """
+        self.api_name = api_name
+        self.url = url
+        self.sns_topic = sns_topic
+        self.signing_key = signing_key
+        self.max_retries = max_retries
+
+        self.session = requests.Session()
+        self.sns_client = None
+        if sns_topic is not None:
+            self.sns_client = boto3.client("sns", region_name=os.environ["AWS_REGION"])
+        self.executor = ThreadPoolExecutor(max_workers=CALLBACK_CONCURRENCY)
+
+    def send(self, outcomes):
+        futures = []
+        for record, prediction, error in outcomes:
+            body = self.callback_body(record, prediction, error)
+            url = record.callback_url or self.url
+            if url is not None:
+                futures.append(self.executor.submit(self.post, url, body))
+            if self.sns_topic is not None:
+                futures.append(self.executor.submit(self.publish, body))
+
+        # callbacks are sent before the records are committed,
+        # so each record's callback is sent at least once
+        wait(futures)
+
+    def callback_body(self, record, prediction, error):
+        key = record.key
+        if isinstance(key, bytes):
+            key = key.decode("utf-8", errors="replace")
+
+        body = {
+            "api_name": self.api_name,
+            "id": record.id,
+            "key": key,
+            "status": "succeeded" if error is None else "failed",
+        }
+        if error is None:
+            result = decode_record(prediction)
+            if isinstance(result, bytes):
+                result = result.decode("utf-8", errors="replace")
+            body["result"] = result
+        else:
+            body["error"] = error
+        return json.dumps(body).encode("utf-8")
+
+    def post(self, url, body):
+        def attempt():
+            headers = {"Content-Type": "application/json"}
+            if self.signing_key is not None:
+                # the timestamp is signed along with the body,
+                # so that receivers can reject old (e.g. replayed) callbacks
+                timestamp = str(int(time.time()))
+                signature = hmac.new(
+                    self.signing_key, timestamp.encode("utf-8") + b"." + body, hashlib.sha256
+                )
+                headers["X-Cortex-Timestamp"] = timestamp
+                headers["X-Cortex-Signature"] = "sha256=" + signature.hexdigest()
+            response = self.session.post(url, data=body, headers=headers, timeout=CALLBACK_TIMEOUT)
+            # client errors (other than rate limiting) won't succeed if they are retried
+            if response.status_code >= 500 or response.status_code == 429:
+                raise Exception(f"received status code {response.status_code}")
+            if response.status_code >= 400:
+                cx_logger().error(f"callback to {url} received status code {response.status_code}")
+
+        self.with_retries(attempt, f"callback to {url}")
+
+    def publish(self, body):
+        def attempt():
+            self.sns_client.publish(TopicArn=self.sns_topic, Message=body.decode("utf-8"))
+
+        self.with_retries(attempt, f"callback to {self.sns_topic}")
+
+    def with_retries(self, fn, description):
+        for i in range(self.max_retries + 1):
+            if i > 0:
+                time.sleep(CALLBACK_BACKOFF * 2 ** (i - 1))
+            try:
+                fn()
+                return
+            except:
+                if i == self.max_retries:
+                    cx_logger().exception(f"{description} failed after {i + 1} attempts")
+
+
+class DeadLetters:
+    def __init__(self, api_name, source, input, s3_path):
+        """
+        Writes the records which could not be processed to S3, along with the context of their failure.
+
+        api_name - The name of the API, which is included in each file.
+        source - The stream source which the records were consumed from (kafka, kinesis, or sqs).
+        input - The topic, stream, or queue which the records were consumed from.
+        s3_path - The S3 path which the records are written to; each record is written to its own JSON file under <s3_path><api_name>/<YYYY-MM-DD>/ (UTC).
+        """
+        self.api_name = api_name
+        self.source = source
+        self.input = input
+
+        bucket, self.prefix = S3.deconstruct_s3_path(s3_path)
+        self.storage = S3(bucket=bucket)
+
+    def write(self, record, error, error_traceback, attempts):
+        now = time.time()
+        key = os.path.join(
+            self.prefix,
+            self.api_name,
+            time.strftime("%Y-%m-%d", time.gmtime(now)),
+            f"{int(now * 1000)}-{uuid.uuid4().hex[:8]}.json",  # sorts chronologically
+        )
+
+        dead_letter = {
+            "api_name": self.api_name,
+            "source": self.source,
+            "input": self.input,
+            "id": record.id,
+            "timestamp": now,
+            "attempts": attempts,
+            "error": error,
+            "traceback": error_traceback,
+        }
+        dead_letter["key"], dead_letter["key_encoding"] = encode_dead_letter_field(record.key)
+        dead_letter["value"], dead_letter["value_encoding"] = encode_dead_letter_field(record.value)
+
+        self.storage.put_json(dead_letter, key)
+
+
+# returns the field as a string, and its encoding ("utf-8" or "base64"); records are written as
+# text when possible (so that they are readable), but binary values must be preserved exactly
+def encode_dead_letter_field(value):
+    if value is None or isinstance(value, str):
+        return value, "utf-8"
+    try:
+        return value.decode("utf-8"), "utf-8"
+    except UnicodeDecodeError:
+        return base64.b64encode(value).decode(), "base64"
+
+
+def get_dead_letters(api_name):
+    if os.getenv("CORTEX_STREAM_DEAD_LETTER_PREFIX") is None:
+        return None
+
+    return DeadLetters(
+        api_name=api_name,
+        source=os.environ["CORTEX_STREAM_SOURCE"],
+        input=os.environ["CORTEX_STREAM_INPUT"],
+        s3_path=os.environ["CORTEX_STREAM_DEAD_LETTER_PREFIX"],
+    )
+
+
+def get_callbacks(api_name):
+    if os.getenv("CORTEX_STREAM_CALLBACK_MAX_RETRIES") is None:
+        return None
+
+    signing_key = None
+    if os.getenv("CORTEX_STREAM_CALLBACK_SIGNING_KEY_ENV"):
+        signing_key = os.environ[os.environ["CORTEX_STREAM_CALLBACK_SIGNING_KEY_ENV"]].encode()
+
+    return Callbacks(
+        api_name=api_name,
+        url=os.getenv("CORTEX_STREAM_CALLBACK_URL"),
+        sns_topic=os.getenv("CORTEX_STREAM_CALLBACK_SNS_TOPIC"),
+        signing_key=signing_key,
+        max_retries=int(os.environ["CORTEX_STREAM_CALLBACK_MAX_RETRIES"]),
+    )
+
+
+def get_stream(api_name):
+    source = os.environ["CORTEX_STREAM_SOURCE"]
+    input = os.environ["CORTEX_STREAM_INPUT"]
+    output = os.getenv("CORTEX_STREAM_OUTPUT")
+    batch_size = int(os.environ["CORTEX_STREAM_BATCH_SIZE"])
+
+    if source == "kafka":
+        return KafkaStream(
+            brokers=os.environ["CORTEX_STREAM_BROKERS"].split(","),
+            input=input,
+            output=output,
+            consumer_group=os.environ["CORTEX_STREAM_CONSUMER_GROUP"],
+            batch_size=batch_size,
+        )
+    if source == "sqs":
+        return SQSStream(
+            input=input,
+            output=output,
+            batch_size=batch_size,
+            s3_trigger=os.getenv("CORTEX_STREAM_S3_TRIGGER") == "true",
+        )
+    return KinesisStream(
+        input=input,
+        output=output,
+        batch_size=batch_size,
+        lease_table=os.environ["CORTEX_STREAM_LEASE_TABLE"],
+        consumer=f"{api_name}/{input}",
+    )
+
+
+def decode_record(value):
+    try:
+        return json.loads(value)
+    except:
+        return value
+
+
+def encode_prediction(prediction):
+    if isinstance(prediction, bytes):
+        return prediction
+    if isinstance(prediction, str):
+        return prediction.encode("utf-8")
+    try:
+        return json.dumps(prediction).encode("utf-8")
+    except Exception as e:
+        raise UserRuntimeException(
+            str(e),
+            "please return an object that is JSON serializable (including its nested fields), a bytes object, or a string",
+        ) from e
+
+
+def update_api_liveness():
+    threading.Timer(API_LIVENESS_UPDATE_PERIOD, update_api_liveness).start()
+    with open("/mnt/workspace/api_liveness.txt", "w") as f:
+        f.write(str(math.ceil(time.time())))
+
+
+# the predictor runs in the main thread, so SIGALRM interrupts it once the timeout expires
+# (calls into native code are only interrupted once they return to the interpreter)
+def predict_with_timeout(predictor_impl, args, timeout):
+    if timeout is None:
+        return predictor_impl.predict(**args)
+
+    def raise_timeout(signum, frame):
+        raise RecordTimeoutError(f"the record was not processed within {timeout} seconds")
+
+    signal.signal(signal.SIGALRM, raise_timeout)
+    signal.setitimer(signal.ITIMER_REAL, timeout)
+    try:
+        return predictor_impl.predict(**args)
+    finally:
+        signal.setitimer(signal.ITIMER_REAL, 0)
+
+
+# returns the encoded predictions (keyed like their records), the records which can be committed
+# (i.e. the records which succeeded or were written to the dead letter prefix),
+# and the outcome of each record: (record, encoded prediction, error message)
+def process_records(
+    api, predictor_impl, predict_fn_args, records, max_retries, record_timeout, dead_letters
+):
+    results = []
+    processed_records = []
+    outcomes = []
+    for record in records:
+        args = {}
+        if "payload" in predict_fn_args:
+            args["payload"] = decode_record(record.value)
+        if "headers" in predict_fn_args:
+            args["headers"] = {}
+        if "query_params" in predict_fn_args:
+            args["query_params"] = {}
+
+        prediction = None
+        error = None
+        error_traceback = None
+        for attempt in range(1, max_retries + 2):
+            start_time = time.time()
+            pop_used_models()
+            status_code = 500
+            try:
+                prediction = encode_prediction(
+                    predict_with_timeout(predictor_impl, args, record_timeout)
+                )
+                status_code = 200
+                break
+            except:
+                cx_logger().exception(
+                    f"failed to process record {record.id} (attempt {attempt} of {max_retries + 1})"
+                )
+                error = str(sys.exc_info()[1])
+                error_traceback = traceback.format_exc()
+            finally:
+                api.post_request_metrics(status_code, time.time() - start_time, pop_used_models())
+
+        if status_code == 200:
+            results.append((record.key, prediction))
+            processed_records.append(record)
+            outcomes.append((record, prediction, None))
+            continue
+
+        outcomes.append((record, None, error))
+        if dead_letters is not None:
+            try:
+                dead_letters.write(record, error, error_traceback, attempt)
+                processed_records.append(record)
+            except:
+                # the record isn't committed, so sqs messages will be received again
+                cx_logger().exception(
+                    f"failed to write record {record.id} to the dead letter prefix"
+                )
+
+    return results, processed_records, outcomes
+
+
+def main():
+    if os.environ["CORTEX_VERSION"] != consts.CORTEX_VERSION:
+        errMsg = f"your Cortex operator version ({os.environ['CORTEX_VERSION']}) doesn't match your predictor image version ({consts.CORTEX_VERSION}); please update your predictor image by modifying the `image` field in your API configuration file (e.g. cortex.yaml) and re-running `cortex deploy`, or update your cluster by following the instructions at https://docs.cortex.dev/cluster-management/update"
+        raise ValueError(errMsg)
+
+    cache_dir = os.environ["CORTEX_CACHE_DIR"]
+    provider = os.environ["CORTEX_PROVIDER"]
+    spec_path = os.environ["CORTEX_API_SPEC"]
+    project_dir = os.environ["CORTEX_PROJECT_DIR"]
+
+    model_dir = os.getenv("CORTEX_MODEL_DIR")
+    tf_serving_port = os.getenv("CORTEX_TF_BASE_SERVING_PORT", "9000")
+    tf_serving_host = os.getenv("CORTEX_TF_SERVING_HOST", "localhost")
+
+    storage = cluster_storage(provider, cache_dir)
+
+    # wait until neuron-rtd sidecar is ready
+    if os.getenv("CORTEX_ACTIVE_NEURON"):
+        wait_neuron_rtd()
+
+    try:
+        raw_api_spec = get_spec(provider, storage, cache_dir, spec_path)
+        start_request_monitor(raw_api_spec["name"])
+        if raw_api_spec["predictor"]["type"] == "tensorflow":
+            load_tensorflow_serving_models()
+        api = API(
+            provider=provider,
+            storage=storage,
+            model_dir=model_dir,
+            cache_dir=cache_dir,
+            **raw_api_spec,
+        )
+        client = api.predictor.initialize_client(
+            tf_serving_host=tf_serving_host, tf_serving_port=tf_serving_port
+        )
+        cx_logger().info("loading the predictor from {}".format(api.predictor.path))
+        predictor_impl = api.predictor.initialize_impl(
+            project_dir, client, metrics_client=api.metrics_client()
+        )
+        predict_fn_args = inspect.getfullargspec(predictor_impl.predict).args
+        stream = get_stream(api.name)
+        callbacks = get_callbacks(api.name)
+        dead_letters = get_dead_letters(api.name)
+        max_retries = int(os.environ["CORTEX_STREAM_MAX_RETRIES"])
+        record_timeout = None
+        if os.getenv("CORTEX_STREAM_RECORD_TIMEOUT") is not None:
+            record_timeout = float(os.environ["CORTEX_STREAM_RECORD_TIMEOUT"])
+    except:
+        cx_logger().exception("failed to start api")
+        sys.exit(1)
+
+    # records which are being processed when the replica is stopped aren't committed,
+    # so they are consumed again
+    def shutdown(signum, frame):
+        try:
+            stream.close()
+        except:
+            cx_logger().exception("failed to close the stream")
+        call_on_shutdown(predictor_impl, SHUTDOWN_TIMEOUT)
+        sys.exit(0)
+
+    signal.signal(signal.SIGTERM, shutdown)
+
+    open("/mnt/workspace/api_readiness.txt", "a").close()
+    update_api_liveness()
+    cx_logger().info("consuming from {}".format(os.environ["CORTEX_STREAM_INPUT"]))
+
+    while True:
+        records = stream.poll()
+        if len(records) == 0:
+            continue
+
+        results, processed_records, outcomes = process_records(
+            api,
+            predictor_impl,
+            predict_fn_args,
+            records,
+            max_retries=max_retries,
+            record_timeout=record_timeout,
+            dead_letters=dead_letters,
+        )
+        if stream.output is not None and len(results) > 0:
+            stream.write(results)
+        if callbacks is not None:
+            callbacks.send(outcomes)
+        stream.commit(processed_records)
+
+
+if __name__ == "__main__":
+    main()